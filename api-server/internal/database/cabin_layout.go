@@ -0,0 +1,427 @@
+package database
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+var ErrAlreadyExists = errors.New("already exists")
+
+// AircraftType represents an aircraft model that cabin layouts belong to.
+// IATACode is the type's IATA code, which schedules in SSIM files use.
+type AircraftType struct {
+	Code         string    `json:"code"`
+	IATACode     string    `json:"iataCode,omitempty"`
+	Manufacturer string    `json:"manufacturer"`
+	Model        string    `json:"model"`
+	CreatedAt    time.Time `json:"createdAt"`
+}
+
+// Cabin is a block of consecutive rows sharing a seat class and seat columns.
+// ColumnGroups lists the seat columns between aisles, e.g. ["ABC", "DEF"] for
+// 3-3 seating.
+type Cabin struct {
+	Class           string   `json:"class"`
+	FirstRow        int      `json:"firstRow"`
+	LastRow         int      `json:"lastRow"`
+	ColumnGroups    []string `json:"columnGroups"`
+	PriceMultiplier float64  `json:"priceMultiplier"`
+	SkipRows        []int    `json:"skipRows,omitempty"`     // row numbers that do not exist, e.g. 13
+	ExitRows        []int    `json:"exitRows,omitempty"`     // rows next to an emergency exit
+	MissingSeats    []string `json:"missingSeats,omitempty"` // positions without a seat, e.g. "41A"
+	// ExtraLegroomRows and LavatoryRows mark rows with extra legroom and
+	// rows next to a lavatory
+	ExtraLegroomRows []int `json:"extraLegroomRows,omitempty"`
+	LavatoryRows     []int `json:"lavatoryRows,omitempty"`
+}
+
+// Columns returns every seat column of the cabin from left to right
+func (c Cabin) Columns() string {
+	return strings.Join(c.ColumnGroups, "")
+}
+
+// Rows returns the row numbers that exist in the cabin
+func (c Cabin) Rows() []int {
+	var rows []int
+	for row := c.FirstRow; row <= c.LastRow; row++ {
+		if !containsInt(c.SkipRows, row) {
+			rows = append(rows, row)
+		}
+	}
+	return rows
+}
+
+// IsExitRow reports whether row is an exit row
+func (c Cabin) IsExitRow(row int) bool {
+	return containsInt(c.ExitRows, row)
+}
+
+// SeatAttributes returns the attributes of the seat at a row and column:
+// window seats are in the outermost columns, aisle seats at the inner edges
+// of the column groups
+func (c Cabin) SeatAttributes(row int, column rune) []string {
+	attributes := []string{}
+	last := len(c.ColumnGroups) - 1
+	for i, group := range c.ColumnGroups {
+		pos := strings.IndexRune(group, column)
+		if pos < 0 {
+			continue
+		}
+		first, end := pos == 0, pos == len(group)-1
+		if (i == 0 && first) || (i == last && end) {
+			attributes = append(attributes, SeatAttributeWindow)
+		}
+		if (i > 0 && first) || (i < last && end) {
+			attributes = append(attributes, SeatAttributeAisle)
+		}
+		break
+	}
+	if containsInt(c.ExtraLegroomRows, row) {
+		attributes = append(attributes, SeatAttributeExtraLegroom)
+	}
+	if c.IsExitRow(row) {
+		attributes = append(attributes, SeatAttributeExitRow)
+	}
+	if containsInt(c.LavatoryRows, row) {
+		attributes = append(attributes, SeatAttributeNearLavatory)
+	}
+	return attributes
+}
+
+// HasSeat reports whether a seat is installed at the given position
+func (c Cabin) HasSeat(seatNumber string) bool {
+	for _, missing := range c.MissingSeats {
+		if missing == seatNumber {
+			return false
+		}
+	}
+	return true
+}
+
+// CabinLayout is a seat map template for an aircraft type
+type CabinLayout struct {
+	Name         string    `json:"name"`
+	AircraftType string    `json:"aircraftType"`
+	Description  string    `json:"description"`
+	Cabins       []Cabin   `json:"cabins"`
+	SeatCount    int       `json:"seatCount"`
+	CreatedAt    time.Time `json:"createdAt"`
+}
+
+// GenerateSeats builds the seat inventory for a flight with the given base
+// fare. Seat prices are the base fare times the cabin's multiplier.
+func (l *CabinLayout) GenerateSeats(basePrice float64) []Seat {
+	var seats []Seat
+	for _, cabin := range l.Cabins {
+		price := math.Round(basePrice*cabin.PriceMultiplier*100) / 100
+		for _, row := range cabin.Rows() {
+			for _, col := range cabin.Columns() {
+				number := fmt.Sprintf("%d%c", row, col)
+				if !cabin.HasSeat(number) {
+					continue
+				}
+				seats = append(seats, Seat{
+					SeatNumber:   number,
+					RowNumber:    row,
+					ColumnLetter: string(col),
+					Class:        cabin.Class,
+					Attributes:   cabin.SeatAttributes(row, col),
+					Status:       SeatStatusAvailable,
+					Price:        price,
+				})
+			}
+		}
+	}
+	return seats
+}
+
+// SeatMap is the structured seat map of a flight. Cabins describe the
+// physical layout and reference seats by ID; Seats holds their current state.
+type SeatMap struct {
+	FlightID     uuid.UUID      `json:"flightId"`
+	CabinLayout  string         `json:"cabinLayout"`
+	AircraftType *AircraftType  `json:"aircraftType,omitempty"`
+	Cabins       []SeatMapCabin `json:"cabins"`
+	Seats        []Seat         `json:"seats"`
+}
+
+// SeatMapCabin is one cabin of a seat map
+type SeatMapCabin struct {
+	Class        string       `json:"class"`
+	ColumnGroups []string     `json:"columnGroups"`
+	Rows         []SeatMapRow `json:"rows"`
+}
+
+// SeatMapRow is one row of a cabin. Seats is aligned with the cabin's columns
+// and holds the seat ID at each position, or null where there is no seat.
+type SeatMapRow struct {
+	Row     int          `json:"row"`
+	ExitRow bool         `json:"exitRow,omitempty"`
+	Seats   []*uuid.UUID `json:"seats"`
+}
+
+// BuildSeatMap arranges a flight's seats according to its cabin layout.
+// Seats the layout does not cover (or all seats, when layout is nil) are
+// grouped into cabins by class with a single column group per cabin.
+func BuildSeatMap(flightID uuid.UUID, layout *CabinLayout, seats []Seat) *SeatMap {
+	m := &SeatMap{FlightID: flightID, Cabins: []SeatMapCabin{}, Seats: seats}
+	if m.Seats == nil {
+		m.Seats = []Seat{}
+	}
+
+	byNumber := make(map[string]*Seat, len(seats))
+	for i := range seats {
+		byNumber[seats[i].SeatNumber] = &seats[i]
+	}
+	placed := make(map[string]bool, len(seats))
+
+	if layout != nil {
+		m.CabinLayout = layout.Name
+		for _, cabin := range layout.Cabins {
+			mc := SeatMapCabin{Class: cabin.Class, ColumnGroups: cabin.ColumnGroups, Rows: []SeatMapRow{}}
+			for _, row := range cabin.Rows() {
+				mr := SeatMapRow{Row: row, ExitRow: cabin.IsExitRow(row)}
+				for _, col := range cabin.Columns() {
+					number := fmt.Sprintf("%d%c", row, col)
+					if s, ok := byNumber[number]; ok && !placed[number] {
+						mr.Seats = append(mr.Seats, &s.ID)
+						placed[number] = true
+					} else {
+						mr.Seats = append(mr.Seats, nil)
+					}
+				}
+				mc.Rows = append(mc.Rows, mr)
+			}
+			m.Cabins = append(m.Cabins, mc)
+		}
+	}
+
+	var unplaced []Seat
+	for _, s := range seats {
+		if !placed[s.SeatNumber] {
+			unplaced = append(unplaced, s)
+		}
+	}
+	m.Cabins = append(m.Cabins, inferCabins(unplaced)...)
+
+	return m
+}
+
+// inferCabins groups seats without a layout into one cabin per class, in
+// order of their first row
+func inferCabins(seats []Seat) []SeatMapCabin {
+	if len(seats) == 0 {
+		return nil
+	}
+
+	type classSeats struct {
+		class    string
+		firstRow int
+		columns  map[string]bool
+		rows     map[int]map[string]uuid.UUID
+	}
+	var classes []*classSeats
+	byClass := make(map[string]*classSeats)
+	for _, s := range seats {
+		c, ok := byClass[s.Class]
+		if !ok {
+			c = &classSeats{class: s.Class, firstRow: s.RowNumber, columns: map[string]bool{}, rows: map[int]map[string]uuid.UUID{}}
+			byClass[s.Class] = c
+			classes = append(classes, c)
+		}
+		c.firstRow = min(c.firstRow, s.RowNumber)
+		c.columns[s.ColumnLetter] = true
+		if c.rows[s.RowNumber] == nil {
+			c.rows[s.RowNumber] = map[string]uuid.UUID{}
+		}
+		c.rows[s.RowNumber][s.ColumnLetter] = s.ID
+	}
+	sort.SliceStable(classes, func(i, j int) bool { return classes[i].firstRow < classes[j].firstRow })
+
+	cabins := make([]SeatMapCabin, 0, len(classes))
+	for _, c := range classes {
+		columns := make([]string, 0, len(c.columns))
+		for col := range c.columns {
+			columns = append(columns, col)
+		}
+		sort.Strings(columns)
+
+		rows := make([]int, 0, len(c.rows))
+		for row := range c.rows {
+			rows = append(rows, row)
+		}
+		sort.Ints(rows)
+
+		mc := SeatMapCabin{Class: c.class, ColumnGroups: []string{strings.Join(columns, "")}}
+		for _, row := range rows {
+			mr := SeatMapRow{Row: row}
+			for _, col := range columns {
+				if id, ok := c.rows[row][col]; ok {
+					mr.Seats = append(mr.Seats, &id)
+				} else {
+					mr.Seats = append(mr.Seats, nil)
+				}
+			}
+			mc.Rows = append(mc.Rows, mr)
+		}
+		cabins = append(cabins, mc)
+	}
+	return cabins
+}
+
+func containsInt(values []int, v int) bool {
+	for _, x := range values {
+		if x == v {
+			return true
+		}
+	}
+	return false
+}
+
+// --- Aircraft and Cabin Layout Operations ---
+
+// GetAircraftTypes returns all aircraft types
+func (r *Repository) GetAircraftTypes(ctx context.Context) ([]AircraftType, error) {
+	rows, err := r.pool.Query(ctx, `
+		SELECT code, COALESCE(iata_code, ''), manufacturer, model, created_at
+		FROM aircraft_types
+		ORDER BY code
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query aircraft types: %w", err)
+	}
+	defer rows.Close()
+
+	types := []AircraftType{}
+	for rows.Next() {
+		var t AircraftType
+		if err := rows.Scan(&t.Code, &t.IATACode, &t.Manufacturer, &t.Model, &t.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan aircraft type: %w", err)
+		}
+		types = append(types, t)
+	}
+
+	return types, rows.Err()
+}
+
+// GetAircraftType returns an aircraft type by code
+func (r *Repository) GetAircraftType(ctx context.Context, code string) (*AircraftType, error) {
+	return r.getAircraftType(ctx, `code = $1`, code)
+}
+
+// GetAircraftTypeByIATACode returns an aircraft type by IATA code
+func (r *Repository) GetAircraftTypeByIATACode(ctx context.Context, iataCode string) (*AircraftType, error) {
+	return r.getAircraftType(ctx, `iata_code = $1`, iataCode)
+}
+
+func (r *Repository) getAircraftType(ctx context.Context, where string, code string) (*AircraftType, error) {
+	var t AircraftType
+	err := r.pool.QueryRow(ctx, `
+		SELECT code, COALESCE(iata_code, ''), manufacturer, model, created_at
+		FROM aircraft_types
+		WHERE `+where, code).Scan(&t.Code, &t.IATACode, &t.Manufacturer, &t.Model, &t.CreatedAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("failed to get aircraft type: %w", err)
+	}
+	return &t, nil
+}
+
+// CreateAircraftType inserts an aircraft type
+func (r *Repository) CreateAircraftType(ctx context.Context, t *AircraftType) error {
+	err := r.pool.QueryRow(ctx, `
+		INSERT INTO aircraft_types (code, iata_code, manufacturer, model)
+		VALUES ($1, NULLIF($2, ''), $3, $4)
+		RETURNING created_at
+	`, t.Code, t.IATACode, t.Manufacturer, t.Model).Scan(&t.CreatedAt)
+	if err != nil {
+		if isUniqueViolation(err) {
+			return fmt.Errorf("%w: aircraft type %s", ErrAlreadyExists, t.Code)
+		}
+		return fmt.Errorf("failed to create aircraft type: %w", err)
+	}
+	return nil
+}
+
+const cabinLayoutSelect = `
+	SELECT name, aircraft_type, description, cabins, created_at
+	FROM cabin_layouts
+`
+
+func scanCabinLayout(row pgx.Row) (*CabinLayout, error) {
+	var l CabinLayout
+	var cabins []byte
+	if err := row.Scan(&l.Name, &l.AircraftType, &l.Description, &cabins, &l.CreatedAt); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(cabins, &l.Cabins); err != nil {
+		return nil, fmt.Errorf("invalid cabins for layout %s: %w", l.Name, err)
+	}
+	l.SeatCount = len(l.GenerateSeats(0))
+	return &l, nil
+}
+
+// GetCabinLayouts returns all cabin layouts
+func (r *Repository) GetCabinLayouts(ctx context.Context) ([]CabinLayout, error) {
+	rows, err := r.pool.Query(ctx, cabinLayoutSelect+` ORDER BY name`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query cabin layouts: %w", err)
+	}
+	defer rows.Close()
+
+	layouts := []CabinLayout{}
+	for rows.Next() {
+		l, err := scanCabinLayout(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan cabin layout: %w", err)
+		}
+		layouts = append(layouts, *l)
+	}
+
+	return layouts, rows.Err()
+}
+
+// GetCabinLayout returns a cabin layout by name
+func (r *Repository) GetCabinLayout(ctx context.Context, name string) (*CabinLayout, error) {
+	l, err := scanCabinLayout(r.pool.QueryRow(ctx, cabinLayoutSelect+` WHERE name = $1`, name))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("failed to get cabin layout: %w", err)
+	}
+	return l, nil
+}
+
+// CreateCabinLayout inserts a cabin layout
+func (r *Repository) CreateCabinLayout(ctx context.Context, l *CabinLayout) error {
+	cabins, err := json.Marshal(l.Cabins)
+	if err != nil {
+		return fmt.Errorf("failed to encode cabins: %w", err)
+	}
+
+	err = r.pool.QueryRow(ctx, `
+		INSERT INTO cabin_layouts (name, aircraft_type, description, cabins)
+		VALUES ($1, $2, $3, $4)
+		RETURNING created_at
+	`, l.Name, l.AircraftType, l.Description, cabins).Scan(&l.CreatedAt)
+	if err != nil {
+		if isUniqueViolation(err) {
+			return fmt.Errorf("%w: cabin layout %s", ErrAlreadyExists, l.Name)
+		}
+		return fmt.Errorf("failed to create cabin layout: %w", err)
+	}
+	l.SeatCount = len(l.GenerateSeats(0))
+	return nil
+}
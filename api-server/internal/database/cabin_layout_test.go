@@ -0,0 +1,215 @@
+package database
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// seededLayouts mirrors the cabins of the layouts in 006_cabin_layouts.sql,
+// with the rows added in 010_seat_attributes.sql
+var seededLayouts = map[string]string{
+	"narrowbody-standard": `[
+		{"class": "business", "firstRow": 1, "lastRow": 5, "columnGroups": ["ABC", "DEF"], "priceMultiplier": 1.5,
+		 "lavatoryRows": [1]},
+		{"class": "premium", "firstRow": 6, "lastRow": 10, "columnGroups": ["ABC", "DEF"], "priceMultiplier": 1.2},
+		{"class": "economy", "firstRow": 11, "lastRow": 30, "columnGroups": ["ABC", "DEF"], "priceMultiplier": 1,
+		 "exitRows": [11, 12], "extraLegroomRows": [11, 12], "lavatoryRows": [30]}
+	]`,
+	"regional": `[
+		{"class": "business", "firstRow": 1, "lastRow": 3, "columnGroups": ["A", "CD"], "priceMultiplier": 1.5,
+		 "lavatoryRows": [1]},
+		{"class": "economy", "firstRow": 4, "lastRow": 20, "columnGroups": ["AB", "CD"], "priceMultiplier": 1,
+		 "exitRows": [10], "extraLegroomRows": [4, 10], "lavatoryRows": [20]}
+	]`,
+	"widebody": `[
+		{"class": "first", "firstRow": 1, "lastRow": 2, "columnGroups": ["A", "EF", "K"], "priceMultiplier": 3,
+		 "lavatoryRows": [1]},
+		{"class": "business", "firstRow": 3, "lastRow": 8, "columnGroups": ["AC", "DG", "HK"], "priceMultiplier": 2},
+		{"class": "premium", "firstRow": 9, "lastRow": 12, "columnGroups": ["AC", "DEFG", "HK"], "priceMultiplier": 1.3,
+		 "extraLegroomRows": [9]},
+		{"class": "economy", "firstRow": 13, "lastRow": 41, "columnGroups": ["ABC", "DEF", "HJK"], "priceMultiplier": 1,
+		 "skipRows": [13], "exitRows": [14, 28], "missingSeats": ["41A", "41K"],
+		 "extraLegroomRows": [14, 28], "lavatoryRows": [27, 41]}
+	]`,
+}
+
+func seededLayout(t *testing.T, name string) *CabinLayout {
+	t.Helper()
+	l := &CabinLayout{Name: name}
+	require.NoError(t, json.Unmarshal([]byte(seededLayouts[name]), &l.Cabins))
+	return l
+}
+
+func TestCabinLayout_GenerateSeats(t *testing.T) {
+	seats := seededLayout(t, "narrowbody-standard").GenerateSeats(100)
+	assert.Len(t, seats, 180)
+
+	first := seats[0]
+	assert.Equal(t, "1A", first.SeatNumber)
+	assert.Equal(t, 1, first.RowNumber)
+	assert.Equal(t, "A", first.ColumnLetter)
+	assert.Equal(t, "business", first.Class)
+	assert.Equal(t, 150.0, first.Price)
+
+	last := seats[len(seats)-1]
+	assert.Equal(t, "30F", last.SeatNumber)
+	assert.Equal(t, "economy", last.Class)
+	assert.Equal(t, 100.0, last.Price)
+
+	numbers := make(map[string]bool)
+	for _, s := range seats {
+		assert.False(t, numbers[s.SeatNumber], "duplicate seat %s", s.SeatNumber)
+		numbers[s.SeatNumber] = true
+	}
+}
+
+func TestCabin_SeatAttributes(t *testing.T) {
+	tests := []struct {
+		layout     string
+		seat       string
+		attributes []string
+	}{
+		{layout: "narrowbody-standard", seat: "1A", attributes: []string{SeatAttributeWindow, SeatAttributeNearLavatory}},
+		{layout: "narrowbody-standard", seat: "5B", attributes: []string{}},
+		{layout: "narrowbody-standard", seat: "5C", attributes: []string{SeatAttributeAisle}},
+		{layout: "narrowbody-standard", seat: "5D", attributes: []string{SeatAttributeAisle}},
+		{layout: "narrowbody-standard", seat: "11F", attributes: []string{SeatAttributeWindow, SeatAttributeExtraLegroom, SeatAttributeExitRow}},
+		{layout: "narrowbody-standard", seat: "30C", attributes: []string{SeatAttributeAisle, SeatAttributeNearLavatory}},
+		// A single seat between the window and the aisle is both
+		{layout: "regional", seat: "2A", attributes: []string{SeatAttributeWindow, SeatAttributeAisle}},
+		{layout: "widebody", seat: "20E", attributes: []string{}},
+		{layout: "widebody", seat: "20F", attributes: []string{SeatAttributeAisle}},
+		{layout: "widebody", seat: "28K", attributes: []string{SeatAttributeWindow, SeatAttributeExtraLegroom, SeatAttributeExitRow}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.layout+"/"+tt.seat, func(t *testing.T) {
+			var found bool
+			for _, s := range seededLayout(t, tt.layout).GenerateSeats(100) {
+				if s.SeatNumber == tt.seat {
+					found = true
+					assert.Equal(t, tt.attributes, s.Attributes)
+				}
+			}
+			assert.True(t, found, "seat %s not generated", tt.seat)
+		})
+	}
+}
+
+func TestCabinLayout_SeatCounts(t *testing.T) {
+	counts := map[string]int{}
+	for name := range seededLayouts {
+		counts[name] = len(seededLayout(t, name).GenerateSeats(99.99))
+	}
+
+	assert.Equal(t, map[string]int{
+		"narrowbody-standard": 180,
+		"regional":            77,
+		"widebody":            326,
+	}, counts)
+}
+
+func TestCabinLayout_GenerateSeats_SkipsRowsAndMissingSeats(t *testing.T) {
+	numbers := make(map[string]bool)
+	for _, s := range seededLayout(t, "widebody").GenerateSeats(100) {
+		numbers[s.SeatNumber] = true
+	}
+
+	assert.False(t, numbers["13A"], "row 13 is skipped")
+	assert.True(t, numbers["14A"])
+	assert.False(t, numbers["41A"])
+	assert.True(t, numbers["41B"])
+	assert.False(t, numbers["41K"])
+	assert.False(t, numbers["1B"], "first class has no B seats")
+}
+
+func TestBuildSeatMap(t *testing.T) {
+	layout := seededLayout(t, "regional")
+	seats := layout.GenerateSeats(100)
+	for i := range seats {
+		seats[i].ID = uuid.New()
+	}
+
+	m := BuildSeatMap(uuid.New(), layout, seats)
+	assert.Equal(t, "regional", m.CabinLayout)
+	require.Len(t, m.Cabins, 2)
+
+	business := m.Cabins[0]
+	assert.Equal(t, "business", business.Class)
+	assert.Equal(t, []string{"A", "CD"}, business.ColumnGroups)
+	require.Len(t, business.Rows, 3)
+	assert.Equal(t, 1, business.Rows[0].Row)
+	require.Len(t, business.Rows[0].Seats, 3)
+	assert.Equal(t, seats[0].ID, *business.Rows[0].Seats[0])
+
+	economy := m.Cabins[1]
+	assert.Len(t, economy.Rows, 17)
+	for _, row := range economy.Rows {
+		assert.Equal(t, row.Row == 10, row.ExitRow, "row %d", row.Row)
+	}
+}
+
+func TestBuildSeatMap_MissingSeatsAreNull(t *testing.T) {
+	layout := seededLayout(t, "widebody")
+	var seats []Seat
+	for _, s := range layout.GenerateSeats(100) {
+		// Simulate an inventory that predates the layout's row 41
+		if s.RowNumber == 41 {
+			continue
+		}
+		s.ID = uuid.New()
+		seats = append(seats, s)
+	}
+
+	m := BuildSeatMap(uuid.New(), layout, seats)
+	economy := m.Cabins[3]
+	last := economy.Rows[len(economy.Rows)-1]
+	assert.Equal(t, 41, last.Row)
+	for _, id := range last.Seats {
+		assert.Nil(t, id)
+	}
+	assert.Equal(t, 14, economy.Rows[0].Row, "row 13 is skipped")
+	assert.True(t, economy.Rows[0].ExitRow)
+}
+
+func TestBuildSeatMap_WithoutLayout(t *testing.T) {
+	seats := []Seat{
+		{ID: uuid.New(), SeatNumber: "2A", RowNumber: 2, ColumnLetter: "A", Class: "economy"},
+		{ID: uuid.New(), SeatNumber: "1A", RowNumber: 1, ColumnLetter: "A", Class: "business"},
+		{ID: uuid.New(), SeatNumber: "1B", RowNumber: 1, ColumnLetter: "B", Class: "business"},
+		{ID: uuid.New(), SeatNumber: "2C", RowNumber: 2, ColumnLetter: "C", Class: "economy"},
+	}
+
+	m := BuildSeatMap(uuid.New(), nil, seats)
+	assert.Empty(t, m.CabinLayout)
+	assert.Len(t, m.Seats, 4)
+	require.Len(t, m.Cabins, 2)
+
+	assert.Equal(t, "business", m.Cabins[0].Class)
+	assert.Equal(t, []string{"AB"}, m.Cabins[0].ColumnGroups)
+
+	economy := m.Cabins[1]
+	assert.Equal(t, []string{"AC"}, economy.ColumnGroups)
+	require.Len(t, economy.Rows, 1)
+	assert.Equal(t, seats[0].ID, *economy.Rows[0].Seats[0])
+	assert.Equal(t, seats[3].ID, *economy.Rows[0].Seats[1])
+}
+
+func TestBuildSeatMap_PlacesSeatsOutsideLayout(t *testing.T) {
+	layout := &CabinLayout{Name: "tiny", Cabins: []Cabin{
+		{Class: "economy", FirstRow: 1, LastRow: 1, ColumnGroups: []string{"AB"}, PriceMultiplier: 1},
+	}}
+	seats := []Seat{
+		{ID: uuid.New(), SeatNumber: "1A", RowNumber: 1, ColumnLetter: "A", Class: "economy"},
+		{ID: uuid.New(), SeatNumber: "9F", RowNumber: 9, ColumnLetter: "F", Class: "economy"},
+	}
+
+	m := BuildSeatMap(uuid.New(), layout, seats)
+	require.Len(t, m.Cabins, 2)
+	assert.Equal(t, []*uuid.UUID{&seats[0].ID, nil}, m.Cabins[0].Rows[0].Seats)
+	assert.Equal(t, 9, m.Cabins[1].Rows[0].Row)
+}
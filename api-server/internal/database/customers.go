@@ -0,0 +1,213 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// Customer is a customer account. PasswordHash is a bcrypt hash and is never
+// serialized.
+type Customer struct {
+	ID           uuid.UUID `json:"id"`
+	Email        string    `json:"email"`
+	Name         string    `json:"name"`
+	PasswordHash string    `json:"-"`
+	CreatedAt    time.Time `json:"createdAt"`
+}
+
+// CustomerOrderFilter narrows down the orders of a customer. CreatedFrom is
+// inclusive and CreatedTo exclusive.
+type CustomerOrderFilter struct {
+	Statuses    []OrderStatus
+	CreatedFrom *time.Time
+	CreatedTo   *time.Time
+}
+
+// CreateCustomer creates a customer account. ErrAlreadyExists is returned
+// when an account with the same email, ignoring case, exists.
+func (r *Repository) CreateCustomer(ctx context.Context, c *Customer) error {
+	if c.ID == uuid.Nil {
+		c.ID = uuid.New()
+	}
+	err := r.pool.QueryRow(ctx, `
+		INSERT INTO customers (id, email, name, password_hash)
+		VALUES ($1, $2, $3, $4)
+		RETURNING created_at
+	`, c.ID, c.Email, c.Name, c.PasswordHash).Scan(&c.CreatedAt)
+	if err != nil {
+		if isUniqueViolation(err) {
+			return ErrAlreadyExists
+		}
+		return fmt.Errorf("failed to create customer: %w", err)
+	}
+	return nil
+}
+
+// GetCustomerByEmail returns the customer account with an email, ignoring
+// case
+func (r *Repository) GetCustomerByEmail(ctx context.Context, email string) (*Customer, error) {
+	var c Customer
+	err := r.pool.QueryRow(ctx, `
+		SELECT id, email, name, password_hash, created_at
+		FROM customers
+		WHERE LOWER(email) = LOWER($1)
+	`, email).Scan(&c.ID, &c.Email, &c.Name, &c.PasswordHash, &c.CreatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get customer: %w", err)
+	}
+	return &c, nil
+}
+
+// CreateCustomerSession starts a session of a customer, identified by the
+// hash of its token, and removes the customer's expired sessions
+func (r *Repository) CreateCustomerSession(ctx context.Context, customerID uuid.UUID, tokenHash string, expiresAt time.Time) error {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	_, err = tx.Exec(ctx, `DELETE FROM customer_sessions WHERE customer_id = $1 AND expires_at <= NOW()`, customerID)
+	if err != nil {
+		return fmt.Errorf("failed to remove expired sessions: %w", err)
+	}
+
+	_, err = tx.Exec(ctx, `
+		INSERT INTO customer_sessions (token_hash, customer_id, expires_at) VALUES ($1, $2, $3)
+	`, tokenHash, customerID, expiresAt)
+	if err != nil {
+		return fmt.Errorf("failed to create session: %w", err)
+	}
+	return tx.Commit(ctx)
+}
+
+// GetCustomerBySession returns the customer of an unexpired session
+func (r *Repository) GetCustomerBySession(ctx context.Context, tokenHash string) (*Customer, error) {
+	var c Customer
+	err := r.pool.QueryRow(ctx, `
+		SELECT c.id, c.email, c.name, c.password_hash, c.created_at
+		FROM customer_sessions cs
+		JOIN customers c ON c.id = cs.customer_id
+		WHERE cs.token_hash = $1 AND cs.expires_at > NOW()
+	`, tokenHash).Scan(&c.ID, &c.Email, &c.Name, &c.PasswordHash, &c.CreatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get session: %w", err)
+	}
+	return &c, nil
+}
+
+// DeleteCustomerSession ends a session
+func (r *Repository) DeleteCustomerSession(ctx context.Context, tokenHash string) error {
+	if _, err := r.pool.Exec(ctx, `DELETE FROM customer_sessions WHERE token_hash = $1`, tokenHash); err != nil {
+		return fmt.Errorf("failed to delete session: %w", err)
+	}
+	return nil
+}
+
+// GetOrderCustomerID returns the account an order belongs to, or nil for a
+// guest order
+func (r *Repository) GetOrderCustomerID(ctx context.Context, orderID uuid.UUID) (*uuid.UUID, error) {
+	var customerID *uuid.UUID
+	err := r.pool.QueryRow(ctx, `SELECT customer_id FROM orders WHERE id = $1`, orderID).Scan(&customerID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get order: %w", err)
+	}
+	return customerID, nil
+}
+
+// GetCustomerOrders returns the orders of a customer, newest first, with
+// their flight segments
+func (r *Repository) GetCustomerOrders(ctx context.Context, customerID uuid.UUID, filter CustomerOrderFilter) ([]Order, error) {
+	args := []interface{}{customerID}
+	arg := func(v interface{}) string {
+		args = append(args, v)
+		return fmt.Sprintf("$%d", len(args))
+	}
+
+	conditions := []string{"customer_id = $1"}
+	if len(filter.Statuses) > 0 {
+		statuses := make([]string, len(filter.Statuses))
+		for i, s := range filter.Statuses {
+			statuses[i] = string(s)
+		}
+		conditions = append(conditions, "status::text = ANY("+arg(statuses)+")")
+	}
+	if filter.CreatedFrom != nil {
+		conditions = append(conditions, "created_at >= "+arg(*filter.CreatedFrom))
+	}
+	if filter.CreatedTo != nil {
+		conditions = append(conditions, "created_at < "+arg(*filter.CreatedTo))
+	}
+
+	rows, err := r.pool.Query(ctx, fmt.Sprintf(`
+		SELECT id, booking_reference, customer_id, flight_id, customer_name, customer_email, status,
+		       total_amount, payment_attempts, failure_reason, reservation_expires_at, created_at, updated_at
+		FROM orders
+		WHERE %s
+		ORDER BY created_at DESC, id
+	`, strings.Join(conditions, " AND ")), args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query customer orders: %w", err)
+	}
+	defer rows.Close()
+
+	var orders []Order
+	index := make(map[uuid.UUID]int)
+	var ids []uuid.UUID
+	for rows.Next() {
+		var o Order
+		if err := rows.Scan(&o.ID, &o.BookingReference, &o.CustomerID, &o.FlightID, &o.CustomerName,
+			&o.CustomerEmail, &o.Status, &o.TotalAmount, &o.PaymentAttempts, &o.FailureReason,
+			&o.ReservationExpiresAt, &o.CreatedAt, &o.UpdatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan order: %w", err)
+		}
+		index[o.ID] = len(orders)
+		ids = append(ids, o.ID)
+		orders = append(orders, o)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to query customer orders: %w", err)
+	}
+	if len(orders) == 0 {
+		return orders, nil
+	}
+
+	rows, err = r.pool.Query(ctx, `
+		SELECT order_id, flight_id, segment_index
+		FROM order_segments
+		WHERE order_id = ANY($1)
+		ORDER BY order_id, segment_index
+	`, ids)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query order segments: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var orderID uuid.UUID
+		var seg OrderSegment
+		if err := rows.Scan(&orderID, &seg.FlightID, &seg.SegmentIndex); err != nil {
+			return nil, fmt.Errorf("failed to scan order segment: %w", err)
+		}
+		if i, ok := index[orderID]; ok {
+			orders[i].Segments = append(orders[i].Segments, seg)
+		}
+	}
+	return orders, rows.Err()
+}
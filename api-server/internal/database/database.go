@@ -15,6 +15,9 @@ type Config struct {
 	MinConns        int32
 	MaxConnLifetime time.Duration
 	MaxConnIdleTime time.Duration
+	// ApplicationName identifies the connections to the database; order
+	// events record it as their actor
+	ApplicationName string
 }
 
 // DefaultConfig returns default database configuration
@@ -39,6 +42,9 @@ func Connect(ctx context.Context, cfg *Config) (*pgxpool.Pool, error) {
 	poolConfig.MinConns = cfg.MinConns
 	poolConfig.MaxConnLifetime = cfg.MaxConnLifetime
 	poolConfig.MaxConnIdleTime = cfg.MaxConnIdleTime
+	if cfg.ApplicationName != "" {
+		poolConfig.ConnConfig.RuntimeParams["application_name"] = cfg.ApplicationName
+	}
 
 	pool, err := pgxpool.NewWithConfig(ctx, poolConfig)
 	if err != nil {
@@ -0,0 +1,286 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+var (
+	ErrDuplicateFlightNumber = errors.New("flight number already operates on that date")
+	ErrFlightInUse           = errors.New("flight has orders or reserved seats")
+	ErrSeatsInUse            = errors.New("held or booked seats would be removed")
+)
+
+// isUniqueViolation reports whether err is a PostgreSQL unique constraint violation
+func isUniqueViolation(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == "23505"
+}
+
+// CreateFlight inserts a flight together with its seat inventory
+func (r *Repository) CreateFlight(ctx context.Context, f *Flight, seats []Seat) error {
+	if f.ID == uuid.Nil {
+		f.ID = uuid.New()
+	}
+	f.TotalSeats = len(seats)
+	f.AvailableSeats = len(seats)
+
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	err = tx.QueryRow(ctx, `
+		INSERT INTO flights (id, flight_number, origin, destination, departure_time, arrival_time,
+		                     total_seats, available_seats, price_per_seat, cabin_layout)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		RETURNING created_at, updated_at
+	`, f.ID, f.FlightNumber, f.Origin, f.Destination, f.DepartureTime, f.ArrivalTime,
+		f.TotalSeats, f.AvailableSeats, f.PricePerSeat, f.CabinLayout,
+	).Scan(&f.CreatedAt, &f.UpdatedAt)
+	if err != nil {
+		if isUniqueViolation(err) {
+			return ErrDuplicateFlightNumber
+		}
+		return fmt.Errorf("failed to create flight: %w", err)
+	}
+
+	if err := insertSeats(ctx, tx, f.ID, seats); err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+// GetFlightsByNumberAndDate returns the flights operating the given flight
+// numbers on the given (UTC) departure dates; numbers and dates are pairs
+func (r *Repository) GetFlightsByNumberAndDate(ctx context.Context, numbers []string, dates []time.Time) ([]Flight, error) {
+	query := flightSelect + `
+		WHERE (f.flight_number, (f.departure_time AT TIME ZONE 'UTC')::date) IN (
+			SELECT * FROM unnest($1::text[], $2::date[])
+		)
+		ORDER BY f.departure_time
+	`
+
+	rows, err := r.pool.Query(ctx, query, numbers, dates)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query flights: %w", err)
+	}
+	defer rows.Close()
+
+	var flights []Flight
+	for rows.Next() {
+		f, err := scanFlight(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan flight: %w", err)
+		}
+		flights = append(flights, f)
+	}
+
+	return flights, rows.Err()
+}
+
+// UpdateFlight saves a flight's details. When seats is not nil the seat
+// inventory is brought in line with it: available seats are added, removed or
+// re-priced, while held and booked seats are left untouched. The update is
+// rejected with ErrSeatsInUse if a held or booked seat is not in seats.
+func (r *Repository) UpdateFlight(ctx context.Context, f *Flight, seats []Seat) error {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	err = tx.QueryRow(ctx, `
+		UPDATE flights
+		SET flight_number = $2, origin = $3, destination = $4, departure_time = $5,
+		    arrival_time = $6, price_per_seat = $7, cabin_layout = $8
+		WHERE id = $1
+		RETURNING updated_at
+	`, f.ID, f.FlightNumber, f.Origin, f.Destination, f.DepartureTime,
+		f.ArrivalTime, f.PricePerSeat, f.CabinLayout,
+	).Scan(&f.UpdatedAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return ErrNotFound
+		}
+		if isUniqueViolation(err) {
+			return ErrDuplicateFlightNumber
+		}
+		return fmt.Errorf("failed to update flight: %w", err)
+	}
+
+	if seats != nil {
+		if err := syncFlightSeats(ctx, tx, f.ID, seats); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit(ctx)
+}
+
+// DeleteFlight deletes a flight and its seats. Flights that are part of any
+// order or have held or booked seats cannot be deleted.
+func (r *Repository) DeleteFlight(ctx context.Context, id uuid.UUID) error {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	var inUse bool
+	err = tx.QueryRow(ctx, `
+		SELECT EXISTS (SELECT 1 FROM orders WHERE flight_id = f.id)
+		    OR EXISTS (SELECT 1 FROM order_segments WHERE flight_id = f.id)
+		    OR EXISTS (SELECT 1 FROM seats WHERE flight_id = f.id AND status <> 'available')
+		FROM flights f
+		WHERE f.id = $1
+		FOR UPDATE
+	`, id).Scan(&inUse)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return ErrNotFound
+		}
+		return fmt.Errorf("failed to check flight: %w", err)
+	}
+	if inUse {
+		return ErrFlightInUse
+	}
+
+	if _, err := tx.Exec(ctx, `DELETE FROM flights WHERE id = $1`, id); err != nil {
+		return fmt.Errorf("failed to delete flight: %w", err)
+	}
+
+	return tx.Commit(ctx)
+}
+
+// insertSeats bulk-inserts available seats for a flight
+func insertSeats(ctx context.Context, tx pgx.Tx, flightID uuid.UUID, seats []Seat) error {
+	_, err := tx.CopyFrom(ctx,
+		pgx.Identifier{"seats"},
+		[]string{"flight_id", "seat_number", "row_number", "column_letter", "class", "attributes", "price"},
+		pgx.CopyFromSlice(len(seats), func(i int) ([]interface{}, error) {
+			s := seats[i]
+			return []interface{}{flightID, s.SeatNumber, s.RowNumber, s.ColumnLetter, s.Class, seatAttributes(s), s.Price}, nil
+		}),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create seats: %w", err)
+	}
+	return nil
+}
+
+// seatAttributes returns the attributes of s, never nil as the column is
+// NOT NULL
+func seatAttributes(s Seat) []string {
+	if s.Attributes == nil {
+		return []string{}
+	}
+	return s.Attributes
+}
+
+// syncFlightSeats replaces a flight's available seat inventory with seats
+func syncFlightSeats(ctx context.Context, tx pgx.Tx, flightID uuid.UUID, seats []Seat) error {
+	rows, err := tx.Query(ctx, `
+		SELECT seat_number, status FROM seats
+		WHERE flight_id = $1
+		FOR UPDATE
+	`, flightID)
+	if err != nil {
+		return fmt.Errorf("failed to query seats: %w", err)
+	}
+	existing := make(map[string]SeatStatus)
+	for rows.Next() {
+		var number string
+		var status SeatStatus
+		if err := rows.Scan(&number, &status); err != nil {
+			rows.Close()
+			return fmt.Errorf("failed to scan seat: %w", err)
+		}
+		existing[number] = status
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("failed to query seats: %w", err)
+	}
+
+	wanted := make(map[string]bool, len(seats))
+	for _, s := range seats {
+		wanted[s.SeatNumber] = true
+	}
+
+	var orphaned []string
+	for number, status := range existing {
+		if status != SeatStatusAvailable && !wanted[number] {
+			orphaned = append(orphaned, number)
+		}
+	}
+	if len(orphaned) > 0 {
+		sort.Strings(orphaned)
+		return fmt.Errorf("%w: %s", ErrSeatsInUse, strings.Join(orphaned, ", "))
+	}
+
+	// Seats that already exist are kept (and re-priced if still available).
+	// Attributes describe the seat's position so they follow the layout
+	// whatever the seat's status.
+	numbers := make([]string, 0, len(seats))
+	classes := make([]string, 0, len(seats))
+	attributes := make([]string, 0, len(seats))
+	prices := make([]float64, 0, len(seats))
+	var added []Seat
+	for _, s := range seats {
+		if _, ok := existing[s.SeatNumber]; ok {
+			numbers = append(numbers, s.SeatNumber)
+			classes = append(classes, s.Class)
+			attributes = append(attributes, strings.Join(s.Attributes, ","))
+			prices = append(prices, s.Price)
+		} else {
+			added = append(added, s)
+		}
+	}
+
+	_, err = tx.Exec(ctx, `
+		DELETE FROM seats
+		WHERE flight_id = $1 AND status = 'available' AND NOT (seat_number = ANY($2))
+	`, flightID, numbers)
+	if err != nil {
+		return fmt.Errorf("failed to remove seats: %w", err)
+	}
+
+	_, err = tx.Exec(ctx, `
+		UPDATE seats s
+		SET class = CASE WHEN s.status = 'available' THEN v.class ELSE s.class END,
+		    price = CASE WHEN s.status = 'available' THEN v.price ELSE s.price END,
+		    attributes = COALESCE(string_to_array(NULLIF(v.attributes, ''), ','), '{}')
+		FROM unnest($2::text[], $3::text[], $4::text[], $5::numeric[]) AS v(seat_number, class, attributes, price)
+		WHERE s.flight_id = $1 AND s.seat_number = v.seat_number
+	`, flightID, numbers, classes, attributes, prices)
+	if err != nil {
+		return fmt.Errorf("failed to update seats: %w", err)
+	}
+
+	if err := insertSeats(ctx, tx, flightID, added); err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(ctx, `
+		UPDATE flights f
+		SET total_seats = (SELECT COUNT(*) FROM seats s WHERE s.flight_id = f.id),
+		    available_seats = (SELECT COUNT(*) FROM seats s WHERE s.flight_id = f.id AND s.status = 'available')
+		WHERE f.id = $1
+	`, flightID)
+	if err != nil {
+		return fmt.Errorf("failed to update seat counts: %w", err)
+	}
+
+	return nil
+}
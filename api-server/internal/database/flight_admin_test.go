@@ -0,0 +1,71 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestUpdateFlight_RepricesNarrowbodySeats changes the fare of a flight whose
+// layout has middle seats, which have no attributes. It needs a database with
+// the schema applied:
+//
+//	TEST_DATABASE_URL=postgres://... go test -run UpdateFlight ./internal/database
+func TestUpdateFlight_RepricesNarrowbodySeats(t *testing.T) {
+	url := os.Getenv("TEST_DATABASE_URL")
+	if url == "" {
+		t.Skip("TEST_DATABASE_URL is not set")
+	}
+
+	ctx := context.Background()
+	pool, err := Connect(ctx, DefaultConfig(url))
+	require.NoError(t, err)
+	defer pool.Close()
+	repo := NewRepository(pool)
+
+	layout, err := repo.GetCabinLayout(ctx, "narrowbody-standard")
+	require.NoError(t, err)
+	departure := time.Now().Add(30 * 24 * time.Hour).Truncate(time.Minute)
+	flight := &Flight{
+		FlightNumber:  fmt.Sprintf("ZZ%04d", rand.Intn(10000)),
+		Origin:        "JFK",
+		Destination:   "LAX",
+		DepartureTime: departure,
+		ArrivalTime:   departure.Add(6 * time.Hour),
+		PricePerSeat:  100,
+		CabinLayout:   layout.Name,
+	}
+	require.NoError(t, repo.CreateFlight(ctx, flight, layout.GenerateSeats(100)))
+	defer func() {
+		pool.Exec(ctx, `DELETE FROM seats WHERE flight_id = $1`, flight.ID)
+		pool.Exec(ctx, `DELETE FROM flights WHERE id = $1`, flight.ID)
+	}()
+
+	flight.PricePerSeat = 150
+	wanted := layout.GenerateSeats(150)
+	require.NoError(t, repo.UpdateFlight(ctx, flight, wanted))
+
+	seats, err := repo.GetFlightSeats(ctx, flight.ID)
+	require.NoError(t, err)
+	require.Len(t, seats, len(wanted))
+
+	prices := make(map[string]float64, len(wanted))
+	for _, s := range wanted {
+		prices[s.SeatNumber] = s.Price
+	}
+	var middle int
+	for _, s := range seats {
+		assert.Equal(t, prices[s.SeatNumber], s.Price, s.SeatNumber)
+		assert.NotNil(t, s.Attributes, s.SeatNumber)
+		if len(s.Attributes) == 0 {
+			middle++
+		}
+	}
+	assert.Positive(t, middle, "narrowbody-standard should have seats without attributes")
+}
@@ -0,0 +1,182 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// FlightExchangeStatus is the state of a flight change of a confirmed order
+type FlightExchangeStatus string
+
+const (
+	// FlightExchangePending means seats on the new flight are being held
+	FlightExchangePending FlightExchangeStatus = "pending"
+	// FlightExchangeHeld means the new seats are held while the amount due
+	// is settled
+	FlightExchangeHeld FlightExchangeStatus = "held"
+	// FlightExchangeConfirmed means the order is on the new flight and its
+	// old seats are being released
+	FlightExchangeConfirmed FlightExchangeStatus = "confirmed"
+	// FlightExchangeCompleted means the order is on the new flight
+	FlightExchangeCompleted FlightExchangeStatus = "completed"
+	// FlightExchangeFailed means the order kept its flight
+	FlightExchangeFailed FlightExchangeStatus = "failed"
+)
+
+// FlightExchange moves a confirmed order from one of its flights to another
+// flight on the same route. FareDifference and ChangeFee are set once the
+// seats on the new flight are held; a positive total is charged
+// (TransactionID), a negative one refunded (RefundID).
+type FlightExchange struct {
+	ID             uuid.UUID            `json:"id"`
+	OrderID        uuid.UUID            `json:"orderId"`
+	FromFlightID   uuid.UUID            `json:"fromFlightId"`
+	ToFlightID     uuid.UUID            `json:"toFlightId"`
+	OldSeats       []string             `json:"oldSeats"`
+	NewSeats       []string             `json:"newSeats"`
+	FareDifference *float64             `json:"fareDifference,omitempty"`
+	ChangeFee      *float64             `json:"changeFee,omitempty"`
+	Status         FlightExchangeStatus `json:"status"`
+	WorkflowID     string               `json:"workflowId"`
+	TransactionID  *string              `json:"transactionId,omitempty"`
+	RefundID       *string              `json:"refundId,omitempty"`
+	FailureReason  *string              `json:"failureReason,omitempty"`
+	HoldExpiresAt  time.Time            `json:"holdExpiresAt"`
+	CreatedAt      time.Time            `json:"createdAt"`
+	CompletedAt    *time.Time           `json:"completedAt,omitempty"`
+}
+
+// flightExchangeSelect selects flight exchanges (aliased e) with the numbers
+// of their seats, in the order of the seat IDs
+const flightExchangeSelect = `
+	SELECT e.id, e.order_id, e.from_flight_id, e.to_flight_id,
+	       ARRAY(SELECT s.seat_number FROM unnest(e.old_seat_ids) WITH ORDINALITY u(id, n)
+	             JOIN seats s ON s.id = u.id ORDER BY u.n),
+	       ARRAY(SELECT s.seat_number FROM unnest(e.new_seat_ids) WITH ORDINALITY u(id, n)
+	             JOIN seats s ON s.id = u.id ORDER BY u.n),
+	       e.fare_difference, e.change_fee, e.status, e.workflow_id, e.transaction_id, e.refund_id,
+	       e.failure_reason, e.hold_expires_at, e.created_at, e.completed_at
+	FROM flight_exchanges e`
+
+func scanFlightExchanges(rows pgx.Rows) ([]FlightExchange, error) {
+	defer rows.Close()
+
+	exchanges := []FlightExchange{}
+	for rows.Next() {
+		var e FlightExchange
+		err := rows.Scan(&e.ID, &e.OrderID, &e.FromFlightID, &e.ToFlightID, &e.OldSeats, &e.NewSeats,
+			&e.FareDifference, &e.ChangeFee, &e.Status, &e.WorkflowID, &e.TransactionID, &e.RefundID,
+			&e.FailureReason, &e.HoldExpiresAt, &e.CreatedAt, &e.CompletedAt)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan flight exchange: %w", err)
+		}
+		exchanges = append(exchanges, e)
+	}
+	return exchanges, rows.Err()
+}
+
+// GetOrderFlightExchanges returns the flight changes of an order, oldest
+// first
+func (r *Repository) GetOrderFlightExchanges(ctx context.Context, orderID uuid.UUID) ([]FlightExchange, error) {
+	rows, err := r.pool.Query(ctx, flightExchangeSelect+`
+		WHERE e.order_id = $1
+		ORDER BY e.created_at, e.id
+	`, orderID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query flight exchanges: %w", err)
+	}
+	return scanFlightExchanges(rows)
+}
+
+// GetFlightExchange returns a flight change by ID
+func (r *Repository) GetFlightExchange(ctx context.Context, id uuid.UUID) (*FlightExchange, error) {
+	rows, err := r.pool.Query(ctx, flightExchangeSelect+` WHERE e.id = $1`, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query flight exchange: %w", err)
+	}
+	exchanges, err := scanFlightExchanges(rows)
+	if err != nil {
+		return nil, err
+	}
+	if len(exchanges) == 0 {
+		return nil, ErrNotFound
+	}
+	return &exchanges[0], nil
+}
+
+// CreateFlightExchange records a pending flight change of a confirmed order.
+// The order must still be confirmed, be booked on ex.FromFlightID and not on
+// ex.ToFlightID, and have no seat or flight change in progress; otherwise
+// ErrOrderNotModifiable is returned. The seats are held by the
+// FlightExchangeWorkflow.
+func (r *Repository) CreateFlightExchange(ctx context.Context, ex *FlightExchange) (*FlightExchange, error) {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	// Lock the order so it changes flights one request at a time
+	var status OrderStatus
+	err = tx.QueryRow(ctx, `SELECT status FROM orders WHERE id = $1 FOR UPDATE`, ex.OrderID).Scan(&status)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("failed to get order: %w", err)
+	}
+	if status != OrderStatusConfirmed {
+		return nil, fmt.Errorf("%w: order is %s", ErrOrderNotModifiable, status)
+	}
+
+	var onFrom, onTo, changing bool
+	err = tx.QueryRow(ctx, `
+		SELECT EXISTS (SELECT 1 FROM order_segments WHERE order_id = $1 AND flight_id = $2),
+		       EXISTS (SELECT 1 FROM order_segments WHERE order_id = $1 AND flight_id = $3),
+		       EXISTS (SELECT 1 FROM seat_exchanges WHERE order_id = $1 AND status = 'pending')
+		       OR EXISTS (SELECT 1 FROM flight_exchanges WHERE order_id = $1 AND status IN ('pending', 'held', 'confirmed'))
+	`, ex.OrderID, ex.FromFlightID, ex.ToFlightID).Scan(&onFrom, &onTo, &changing)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check order segments: %w", err)
+	}
+	switch {
+	case !onFrom:
+		return nil, fmt.Errorf("%w: the order is not booked on flight %s", ErrOrderNotModifiable, ex.FromFlightID)
+	case onTo:
+		return nil, fmt.Errorf("%w: the order is already booked on flight %s", ErrOrderNotModifiable, ex.ToFlightID)
+	case changing:
+		return nil, fmt.Errorf("%w: a seat or flight change is already in progress", ErrOrderNotModifiable)
+	}
+
+	_, err = tx.Exec(ctx, `
+		INSERT INTO flight_exchanges (id, order_id, from_flight_id, to_flight_id, workflow_id, hold_expires_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+	`, ex.ID, ex.OrderID, ex.FromFlightID, ex.ToFlightID, ex.WorkflowID, ex.HoldExpiresAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create flight exchange: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit flight exchange: %w", err)
+	}
+	return r.GetFlightExchange(ctx, ex.ID)
+}
+
+// FailFlightExchange marks a flight change that has not held seats yet
+// failed. Exchanges past that point are left to their workflow.
+func (r *Repository) FailFlightExchange(ctx context.Context, id uuid.UUID, reason string) error {
+	_, err := r.pool.Exec(ctx, `
+		UPDATE flight_exchanges
+		SET status = 'failed', failure_reason = $2, completed_at = NOW()
+		WHERE id = $1 AND status = 'pending'
+	`, id, reason)
+	if err != nil {
+		return fmt.Errorf("failed to fail flight exchange: %w", err)
+	}
+	return nil
+}
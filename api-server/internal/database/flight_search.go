@@ -0,0 +1,216 @@
+package database
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// FlightSort represents a sort key for flight search
+type FlightSort string
+
+const (
+	FlightSortDeparture FlightSort = "departure"
+	FlightSortArrival   FlightSort = "arrival"
+	FlightSortPrice     FlightSort = "price"
+	FlightSortDuration  FlightSort = "duration"
+)
+
+// FlightSearchParams holds filters, sorting and pagination for flight search
+type FlightSearchParams struct {
+	Origin            string
+	Destination       string
+	DepartureFrom     *time.Time
+	DepartureTo       *time.Time
+	MaxPrice          *float64
+	MinAvailableSeats int
+	SeatClass         string
+	Sort              FlightSort
+	Descending        bool
+	Cursor            *FlightCursor
+	Limit             int
+}
+
+// FlightCursor marks the position of the last flight returned in a page
+type FlightCursor struct {
+	Sort  FlightSort `json:"s"`
+	Desc  bool       `json:"d,omitempty"`
+	Time  time.Time  `json:"t,omitempty"`
+	Value float64    `json:"v,omitempty"`
+	ID    uuid.UUID  `json:"id"`
+}
+
+// Encode returns an opaque, URL-safe representation of the cursor
+func (c *FlightCursor) Encode() string {
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// DecodeFlightCursor parses a cursor produced by FlightCursor.Encode
+func DecodeFlightCursor(s string) (*FlightCursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	var c FlightCursor
+	if err := json.Unmarshal(data, &c); err != nil || c.ID == uuid.Nil {
+		return nil, ErrInvalidCursor
+	}
+	return &c, nil
+}
+
+// FlightPage is a page of flight search results
+type FlightPage struct {
+	Flights    []Flight
+	NextCursor *FlightCursor
+}
+
+// flightSearchRow is a flight together with the value it was sorted by
+type flightSearchRow struct {
+	flight    Flight
+	sortTime  time.Time
+	sortValue float64
+}
+
+// SearchFlights returns future flights matching the given filters, ordered by
+// the requested sort key and paginated with a keyset cursor. Route and
+// departure filters are plain comparisons so idx_flights_route and
+// idx_flights_departure can be used.
+func (r *Repository) SearchFlights(ctx context.Context, p FlightSearchParams) (*FlightPage, error) {
+	var args []interface{}
+	arg := func(v interface{}) string {
+		args = append(args, v)
+		return fmt.Sprintf("$%d", len(args))
+	}
+
+	conditions := []string{"f.departure_time > NOW()", bookableFlight}
+
+	if p.Origin != "" {
+		conditions = append(conditions, "f.origin = "+arg(p.Origin))
+	}
+	if p.Destination != "" {
+		conditions = append(conditions, "f.destination = "+arg(p.Destination))
+	}
+	if p.DepartureFrom != nil {
+		conditions = append(conditions, "f.departure_time >= "+arg(*p.DepartureFrom))
+	}
+	if p.DepartureTo != nil {
+		conditions = append(conditions, "f.departure_time < "+arg(*p.DepartureTo))
+	}
+
+	// With a seat class, availability and price are taken from that cabin's
+	// available seats instead of the flight-level totals.
+	fareExpr := "f.price_per_seat"
+	if p.SeatClass != "" {
+		class := arg(p.SeatClass)
+		fareExpr = fmt.Sprintf(`(
+			SELECT MIN(s.price) FROM seats s
+			WHERE s.flight_id = f.id AND s.class = %s AND s.status = 'available'
+		)`, class)
+		conditions = append(conditions, fmt.Sprintf(`(
+			SELECT COUNT(*) FROM seats s
+			WHERE s.flight_id = f.id AND s.class = %s AND s.status = 'available'
+		) >= %s`, class, arg(max(p.MinAvailableSeats, 1))))
+	} else if p.MinAvailableSeats > 0 {
+		conditions = append(conditions, "f.available_seats >= "+arg(p.MinAvailableSeats))
+	}
+	if p.MaxPrice != nil {
+		conditions = append(conditions, fareExpr+" <= "+arg(*p.MaxPrice))
+	}
+
+	var sortExpr string
+	timeSort := false
+	switch p.Sort {
+	case FlightSortArrival:
+		sortExpr, timeSort = "f.arrival_time", true
+	case FlightSortPrice:
+		sortExpr = fareExpr + "::float8"
+	case FlightSortDuration:
+		sortExpr = "EXTRACT(EPOCH FROM f.arrival_time - f.departure_time)::float8"
+	default:
+		sortExpr, timeSort = "f.departure_time", true
+	}
+
+	direction, cmp := "ASC", ">"
+	if p.Descending {
+		direction, cmp = "DESC", "<"
+	}
+
+	if c := p.Cursor; c != nil {
+		var value string
+		if timeSort {
+			value = arg(c.Time)
+		} else {
+			value = arg(c.Value)
+		}
+		conditions = append(conditions, fmt.Sprintf("(%s, f.id) %s (%s, %s)", sortExpr, cmp, value, arg(c.ID)))
+	}
+
+	limit := p.Limit
+	if limit <= 0 {
+		limit = 20
+	}
+
+	var sortTimeCol, sortValueCol string
+	if timeSort {
+		sortTimeCol, sortValueCol = sortExpr, "0::float8"
+	} else {
+		sortTimeCol, sortValueCol = "f.departure_time", sortExpr
+	}
+
+	query := fmt.Sprintf(`
+		SELECT %s,
+		       %s, %s
+		%s
+		WHERE %s
+		ORDER BY %s %s, f.id %s
+		LIMIT %s
+	`, flightColumns, sortTimeCol, sortValueCol, flightJoins,
+		strings.Join(conditions, " AND "), sortExpr, direction, direction, arg(limit+1))
+
+	rows, err := r.pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search flights: %w", err)
+	}
+	defer rows.Close()
+
+	var results []flightSearchRow
+	for rows.Next() {
+		var row flightSearchRow
+		var err error
+		row.flight, err = scanFlight(rows, &row.sortTime, &row.sortValue)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan flight: %w", err)
+		}
+		results = append(results, row)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to search flights: %w", err)
+	}
+
+	page := &FlightPage{Flights: make([]Flight, 0, limit)}
+	for i, row := range results {
+		if i == limit {
+			last := results[i-1]
+			page.NextCursor = &FlightCursor{
+				Sort:  p.Sort,
+				Desc:  p.Descending,
+				Time:  last.sortTime,
+				Value: last.sortValue,
+				ID:    last.flight.ID,
+			}
+			break
+		}
+		page.Flights = append(page.Flights, row.flight)
+	}
+
+	return page, nil
+}
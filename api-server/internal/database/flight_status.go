@@ -0,0 +1,55 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// updateFlightStatusQuery saves a flight's status, status reason and
+// estimated times
+const updateFlightStatusQuery = `
+	UPDATE flights
+	SET status = $2, status_reason = $3, estimated_departure_time = $4, estimated_arrival_time = $5
+	WHERE id = $1
+	RETURNING updated_at`
+
+// UpdateFlightStatus saves a flight's status, status reason and estimated
+// times
+func (r *Repository) UpdateFlightStatus(ctx context.Context, f *Flight) error {
+	err := r.pool.QueryRow(ctx, updateFlightStatusQuery,
+		f.ID, f.Status, f.StatusReason, f.EstimatedDepartureTime, f.EstimatedArrivalTime).Scan(&f.UpdatedAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return ErrNotFound
+		}
+		return fmt.Errorf("failed to update flight status: %w", err)
+	}
+	return nil
+}
+
+// GetRebookingOffer returns the most recent rebooking offer made to an order
+func (r *Repository) GetRebookingOffer(ctx context.Context, orderID uuid.UUID) (*RebookingOffer, error) {
+	var o RebookingOffer
+	err := r.pool.QueryRow(ctx, `
+		SELECT id, order_id, flight_id, workflow_id, alternative_flight_ids, status,
+		       rebooked_flight_id, failure_reason, expires_at, responded_at, created_at
+		FROM rebooking_offers
+		WHERE order_id = $1
+		ORDER BY created_at DESC
+		LIMIT 1
+	`, orderID).Scan(
+		&o.ID, &o.OrderID, &o.FlightID, &o.WorkflowID, &o.AlternativeFlightIDs, &o.Status,
+		&o.RebookedFlightID, &o.FailureReason, &o.ExpiresAt, &o.RespondedAt, &o.CreatedAt,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("failed to get rebooking offer: %w", err)
+	}
+	return &o, nil
+}
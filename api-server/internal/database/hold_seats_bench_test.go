@@ -0,0 +1,103 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"os"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// BenchmarkHoldSeats_Contention measures HoldSeats throughput while many
+// orders compete for the same few rows of a flight, as in a flash sale. Each
+// goroutine repeatedly holds a random party-sized run of seats from the hot
+// rows, so selections overlap constantly. It needs a database with the schema
+// applied:
+//
+//	TEST_DATABASE_URL=postgres://... go test -run '^$' -bench HoldSeats ./internal/database
+func BenchmarkHoldSeats_Contention(b *testing.B) {
+	url := os.Getenv("TEST_DATABASE_URL")
+	if url == "" {
+		b.Skip("TEST_DATABASE_URL is not set")
+	}
+
+	ctx := context.Background()
+	pool, err := Connect(ctx, DefaultConfig(url))
+	if err != nil {
+		b.Fatal(err)
+	}
+	defer pool.Close()
+	repo := NewRepository(pool)
+
+	layout, err := repo.GetCabinLayout(ctx, "narrowbody-standard")
+	if err != nil {
+		b.Fatal(err)
+	}
+	seats := layout.GenerateSeats(100)
+	departure := time.Now().Add(30 * 24 * time.Hour).Truncate(time.Minute)
+	flight := &Flight{
+		FlightNumber:  fmt.Sprintf("ZZ%04d", rand.Intn(10000)),
+		Origin:        "JFK",
+		Destination:   "LAX",
+		DepartureTime: departure,
+		ArrivalTime:   departure.Add(6 * time.Hour),
+		PricePerSeat:  100,
+		CabinLayout:   layout.Name,
+	}
+	if err := repo.CreateFlight(ctx, flight, seats); err != nil {
+		b.Fatal(err)
+	}
+	defer func() {
+		pool.Exec(ctx, `DELETE FROM seats WHERE flight_id = $1`, flight.ID)
+		pool.Exec(ctx, `DELETE FROM orders WHERE flight_id = $1`, flight.ID)
+		pool.Exec(ctx, `DELETE FROM flights WHERE id = $1`, flight.ID)
+	}()
+
+	flightSeats, err := repo.GetFlightSeats(ctx, flight.ID)
+	if err != nil {
+		b.Fatal(err)
+	}
+	// The first 30 seats are contended by every goroutine
+	hot := flightSeats[:30]
+
+	var held, conflicts atomic.Int64
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		order := &Order{FlightID: flight.ID, CustomerName: "Bench", CustomerEmail: "bench@example.com", Status: OrderStatusPending}
+		if err := repo.CreateOrder(ctx, order); err != nil {
+			b.Error(err)
+			return
+		}
+		rng := rand.New(rand.NewSource(time.Now().UnixNano()))
+
+		for pb.Next() {
+			size := 1 + rng.Intn(4)
+			start := rng.Intn(len(hot) - size + 1)
+			seatIDs := make([]uuid.UUID, size)
+			for i := range seatIDs {
+				seatIDs[i] = hot[start+i].ID
+			}
+
+			err := repo.HoldSeats(ctx, order.ID, seatIDs)
+			var unavailable *SeatsUnavailableError
+			switch {
+			case err == nil:
+				held.Add(1)
+			case errors.As(err, &unavailable):
+				conflicts.Add(1)
+			default:
+				b.Error(err)
+				return
+			}
+		}
+	})
+	b.StopTimer()
+
+	b.ReportMetric(float64(held.Load()+conflicts.Load())/b.Elapsed().Seconds(), "selections/s")
+	b.ReportMetric(float64(conflicts.Load())/float64(b.N), "conflicts/op")
+}
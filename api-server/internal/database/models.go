@@ -6,19 +6,93 @@ import (
 	"github.com/google/uuid"
 )
 
-// Flight represents a flight in the database
+// Airport represents an airport in the reference data
+type Airport struct {
+	IATACode  string  `json:"iataCode"`
+	ICAOCode  string  `json:"icaoCode"`
+	Name      string  `json:"name"`
+	City      string  `json:"city"`
+	Country   string  `json:"country"`
+	TimeZone  string  `json:"timeZone"`
+	Latitude  float64 `json:"latitude"`
+	Longitude float64 `json:"longitude"`
+}
+
+// Location returns the airport's IANA time zone, falling back to UTC if the
+// zone is unknown to the host
+func (a *Airport) Location() *time.Location {
+	loc, err := time.LoadLocation(a.TimeZone)
+	if err != nil {
+		return time.UTC
+	}
+	return loc
+}
+
+// Flight represents a flight in the database. Origin and Destination are
+// IATA airport codes; departure and arrival times are stored in UTC and
+// also returned in the local time of the respective airport. The estimated
+// times are set while a flight is delayed. ScheduleID is set on flights
+// materialized from a recurring schedule.
 type Flight struct {
-	ID             uuid.UUID `json:"id"`
-	FlightNumber   string    `json:"flightNumber"`
-	Origin         string    `json:"origin"`
-	Destination    string    `json:"destination"`
-	DepartureTime  time.Time `json:"departureTime"`
-	ArrivalTime    time.Time `json:"arrivalTime"`
-	TotalSeats     int       `json:"totalSeats"`
-	AvailableSeats int       `json:"availableSeats"`
-	PricePerSeat   float64   `json:"pricePerSeat"`
-	CreatedAt      time.Time `json:"createdAt"`
-	UpdatedAt      time.Time `json:"updatedAt"`
+	ID                     uuid.UUID    `json:"id"`
+	FlightNumber           string       `json:"flightNumber"`
+	Origin                 string       `json:"origin"`
+	Destination            string       `json:"destination"`
+	OriginAirport          *Airport     `json:"originAirport,omitempty"`
+	DestinationAirport     *Airport     `json:"destinationAirport,omitempty"`
+	DepartureTime          time.Time    `json:"departureTime"`
+	ArrivalTime            time.Time    `json:"arrivalTime"`
+	DepartureTimeLocal     string       `json:"departureTimeLocal,omitempty"`
+	ArrivalTimeLocal       string       `json:"arrivalTimeLocal,omitempty"`
+	TotalSeats             int          `json:"totalSeats"`
+	AvailableSeats         int          `json:"availableSeats"`
+	PricePerSeat           float64      `json:"pricePerSeat"`
+	CabinLayout            string       `json:"cabinLayout"`
+	Status                 FlightStatus `json:"status"`
+	StatusReason           *string      `json:"statusReason,omitempty"`
+	EstimatedDepartureTime *time.Time   `json:"estimatedDepartureTime,omitempty"`
+	EstimatedArrivalTime   *time.Time   `json:"estimatedArrivalTime,omitempty"`
+	ScheduleID             *uuid.UUID   `json:"scheduleId,omitempty"`
+	CreatedAt              time.Time    `json:"createdAt"`
+	UpdatedAt              time.Time    `json:"updatedAt"`
+}
+
+// FlightStatus represents the operational status of a flight
+type FlightStatus string
+
+const (
+	FlightStatusScheduled FlightStatus = "scheduled"
+	FlightStatusDelayed   FlightStatus = "delayed"
+	FlightStatusCancelled FlightStatus = "cancelled"
+	FlightStatusBoarding  FlightStatus = "boarding"
+	FlightStatusDeparted  FlightStatus = "departed"
+	FlightStatusArrived   FlightStatus = "arrived"
+)
+
+// IsBookable reports whether seats on a flight with this status can be sold
+func (s FlightStatus) IsBookable() bool {
+	return s == FlightStatusScheduled || s == FlightStatusDelayed
+}
+
+// SetLocalTimes fills the local departure and arrival times from the time
+// zones of the flight's airports
+func (f *Flight) SetLocalTimes() {
+	f.DepartureTime = f.DepartureTime.UTC()
+	f.ArrivalTime = f.ArrivalTime.UTC()
+	if f.EstimatedDepartureTime != nil {
+		t := f.EstimatedDepartureTime.UTC()
+		f.EstimatedDepartureTime = &t
+	}
+	if f.EstimatedArrivalTime != nil {
+		t := f.EstimatedArrivalTime.UTC()
+		f.EstimatedArrivalTime = &t
+	}
+	if f.OriginAirport != nil {
+		f.DepartureTimeLocal = f.DepartureTime.In(f.OriginAirport.Location()).Format(time.RFC3339)
+	}
+	if f.DestinationAirport != nil {
+		f.ArrivalTimeLocal = f.ArrivalTime.In(f.DestinationAirport.Location()).Format(time.RFC3339)
+	}
 }
 
 // SeatStatus represents the status of a seat
@@ -28,22 +102,61 @@ const (
 	SeatStatusAvailable SeatStatus = "available"
 	SeatStatusHeld      SeatStatus = "held"
 	SeatStatusBooked    SeatStatus = "booked"
+	SeatStatusBlocked   SeatStatus = "blocked"
+)
+
+// Seat attributes describe where a seat is in the cabin
+const (
+	SeatAttributeWindow       = "window"
+	SeatAttributeAisle        = "aisle"
+	SeatAttributeExtraLegroom = "extra_legroom"
+	SeatAttributeExitRow      = "exit_row"
+	SeatAttributeNearLavatory = "near_lavatory"
 )
 
-// Seat represents a seat in the database
+// SeatAttributes lists every seat attribute
+var SeatAttributes = []string{
+	SeatAttributeWindow,
+	SeatAttributeAisle,
+	SeatAttributeExtraLegroom,
+	SeatAttributeExitRow,
+	SeatAttributeNearLavatory,
+}
+
+// Seat represents a seat in the database. Price is the fare of the seat's
+// class; Surcharge is added for its attributes.
 type Seat struct {
-	ID           uuid.UUID   `json:"id"`
-	FlightID     uuid.UUID   `json:"flightId"`
-	SeatNumber   string      `json:"seatNumber"`
-	RowNumber    int         `json:"row"`
-	ColumnLetter string      `json:"column"`
-	Class        string      `json:"class"`
-	Status       SeatStatus  `json:"status"`
-	Price        float64     `json:"price"`
-	HeldUntil    *time.Time  `json:"heldUntil,omitempty"`
-	HeldByOrder  *uuid.UUID  `json:"heldByOrder,omitempty"`
-	CreatedAt    time.Time   `json:"createdAt"`
-	UpdatedAt    time.Time   `json:"updatedAt"`
+	ID           uuid.UUID  `json:"id"`
+	FlightID     uuid.UUID  `json:"flightId"`
+	SeatNumber   string     `json:"seatNumber"`
+	RowNumber    int        `json:"row"`
+	ColumnLetter string     `json:"column"`
+	Class        string     `json:"class"`
+	Attributes   []string   `json:"attributes"`
+	Status       SeatStatus `json:"status"`
+	Price        float64    `json:"price"`
+	Surcharge    float64    `json:"surcharge"`
+	HeldUntil    *time.Time `json:"heldUntil,omitempty"`
+	HeldByOrder  *uuid.UUID `json:"heldByOrder,omitempty"`
+	CreatedAt    time.Time  `json:"createdAt"`
+	UpdatedAt    time.Time  `json:"updatedAt"`
+}
+
+// HasAttributes reports whether the seat has all the attributes
+func (s *Seat) HasAttributes(attributes ...string) bool {
+	for _, want := range attributes {
+		found := false
+		for _, attr := range s.Attributes {
+			if attr == want {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
 }
 
 // OrderStatus represents the status of an order
@@ -58,24 +171,49 @@ const (
 	OrderStatusFailed          OrderStatus = "failed"
 	OrderStatusCancelled       OrderStatus = "cancelled"
 	OrderStatusExpired         OrderStatus = "expired"
+	OrderStatusRefunded        OrderStatus = "refunded"
 )
 
-// Order represents an order in the database
+// Order represents an order in the database. BookingReference is the
+// record locator customers find a confirmed order by; CustomerID is the
+// account the order was placed with, nil for guest orders.
 type Order struct {
-	ID                   uuid.UUID   `json:"id"`
-	FlightID             uuid.UUID   `json:"flightId"`
-	CustomerName         string      `json:"customerName"`
-	CustomerEmail        string      `json:"customerEmail"`
-	Status               OrderStatus `json:"status"`
-	TotalAmount          float64     `json:"totalAmount"`
-	PaymentAttempts      int         `json:"paymentAttempts"`
-	FailureReason        *string     `json:"failureReason,omitempty"`
-	WorkflowID           *string     `json:"workflowId,omitempty"`
-	WorkflowRunID        *string     `json:"workflowRunId,omitempty"`
-	ReservationExpiresAt *time.Time  `json:"reservationExpiresAt,omitempty"`
-	CreatedAt            time.Time   `json:"createdAt"`
-	UpdatedAt            time.Time   `json:"updatedAt"`
-	Seats                []string    `json:"seats,omitempty"`
+	ID                   uuid.UUID      `json:"id"`
+	BookingReference     *string        `json:"bookingReference,omitempty"`
+	CustomerID           *uuid.UUID     `json:"customerId,omitempty"`
+	FlightID             uuid.UUID      `json:"flightId"`
+	CustomerName         string         `json:"customerName"`
+	CustomerEmail        string         `json:"customerEmail"`
+	Status               OrderStatus    `json:"status"`
+	TotalAmount          float64        `json:"totalAmount"`
+	RefundedAmount       float64        `json:"refundedAmount"`
+	PaymentAttempts      int            `json:"paymentAttempts"`
+	FailureReason        *string        `json:"failureReason,omitempty"`
+	WorkflowID           *string        `json:"workflowId,omitempty"`
+	WorkflowRunID        *string        `json:"workflowRunId,omitempty"`
+	ReservationExpiresAt *time.Time     `json:"reservationExpiresAt,omitempty"`
+	CreatedAt            time.Time      `json:"createdAt"`
+	UpdatedAt            time.Time      `json:"updatedAt"`
+	Seats                []string       `json:"seats,omitempty"`
+	Segments             []OrderSegment `json:"segments,omitempty"`
+	// Passengers are the travelers of the order and the seats they sit in
+	Passengers []Passenger `json:"passengers,omitempty"`
+	// OverbookedSeats are the travelers booked without a physical seat
+	OverbookedSeats []OverbookedSeat `json:"overbookedSeats,omitempty"`
+	// SeatExchanges are the seat changes made after the order was confirmed
+	SeatExchanges []SeatExchange `json:"seatExchanges,omitempty"`
+	// FlightExchanges are the flight changes made after the order was
+	// confirmed
+	FlightExchanges []FlightExchange `json:"flightExchanges,omitempty"`
+	// Refunds are the refunds of cancellations after the order was confirmed
+	Refunds []OrderRefund `json:"refunds,omitempty"`
+}
+
+// OrderSegment is one flight of a (possibly multi-flight) order
+type OrderSegment struct {
+	FlightID     uuid.UUID `json:"flightId"`
+	SegmentIndex int       `json:"segmentIndex"`
+	Seats        []string  `json:"seats,omitempty"`
 }
 
 // OrderSeat represents the junction between orders and seats
@@ -87,3 +225,31 @@ type OrderSeat struct {
 	CreatedAt time.Time `json:"createdAt"`
 }
 
+// RebookingOfferStatus represents the state of a rebooking offer
+type RebookingOfferStatus string
+
+const (
+	RebookingOfferPending        RebookingOfferStatus = "pending"
+	RebookingOfferAccepted       RebookingOfferStatus = "accepted"
+	RebookingOfferDeclined       RebookingOfferStatus = "declined"
+	RebookingOfferExpired        RebookingOfferStatus = "expired"
+	RebookingOfferNoAlternatives RebookingOfferStatus = "no_alternatives"
+)
+
+// RebookingOffer is made to a confirmed order when one of its flights is
+// cancelled. The order can be moved to one of the alternative flights, or is
+// refunded if the offer is declined or expires.
+type RebookingOffer struct {
+	ID                   uuid.UUID            `json:"id"`
+	OrderID              uuid.UUID            `json:"orderId"`
+	FlightID             uuid.UUID            `json:"flightId"`
+	WorkflowID           string               `json:"-"`
+	AlternativeFlightIDs []uuid.UUID          `json:"alternativeFlightIds"`
+	Alternatives         []Flight             `json:"alternatives,omitempty"`
+	Status               RebookingOfferStatus `json:"status"`
+	RebookedFlightID     *uuid.UUID           `json:"rebookedFlightId,omitempty"`
+	FailureReason        *string              `json:"failureReason,omitempty"`
+	ExpiresAt            time.Time            `json:"expiresAt"`
+	RespondedAt          *time.Time           `json:"respondedAt,omitempty"`
+	CreatedAt            time.Time            `json:"createdAt"`
+}
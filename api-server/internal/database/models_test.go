@@ -0,0 +1,63 @@
+package database
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFlight_SetLocalTimes(t *testing.T) {
+	f := Flight{
+		OriginAirport:      &Airport{IATACode: "JFK", TimeZone: "America/New_York"},
+		DestinationAirport: &Airport{IATACode: "LHR", TimeZone: "Europe/London"},
+		DepartureTime:      time.Date(2024, 7, 1, 22, 30, 0, 0, time.UTC),
+		ArrivalTime:        time.Date(2024, 7, 2, 5, 45, 0, 0, time.UTC),
+	}
+
+	f.SetLocalTimes()
+
+	assert.Equal(t, "2024-07-01T18:30:00-04:00", f.DepartureTimeLocal)
+	assert.Equal(t, "2024-07-02T06:45:00+01:00", f.ArrivalTimeLocal)
+}
+
+func TestFlight_SetLocalTimes_UnknownZone(t *testing.T) {
+	f := Flight{
+		OriginAirport: &Airport{IATACode: "XXX", TimeZone: "Nowhere/Invalid"},
+		DepartureTime: time.Date(2024, 1, 15, 12, 0, 0, 0, time.FixedZone("EST", -5*3600)),
+	}
+
+	f.SetLocalTimes()
+
+	assert.Equal(t, time.UTC, f.DepartureTime.Location())
+	assert.Equal(t, "2024-01-15T17:00:00Z", f.DepartureTimeLocal)
+	assert.Empty(t, f.ArrivalTimeLocal)
+}
+
+func TestFlightStatus_IsBookable(t *testing.T) {
+	assert.True(t, FlightStatusScheduled.IsBookable())
+	assert.True(t, FlightStatusDelayed.IsBookable())
+	assert.False(t, FlightStatusCancelled.IsBookable())
+	assert.False(t, FlightStatusBoarding.IsBookable())
+	assert.False(t, FlightStatusDeparted.IsBookable())
+	assert.False(t, FlightStatusArrived.IsBookable())
+}
+
+func TestSeat_HasAttributes(t *testing.T) {
+	s := Seat{Attributes: []string{SeatAttributeWindow, SeatAttributeExitRow}}
+
+	assert.True(t, s.HasAttributes())
+	assert.True(t, s.HasAttributes(SeatAttributeWindow))
+	assert.True(t, s.HasAttributes(SeatAttributeExitRow, SeatAttributeWindow))
+	assert.False(t, s.HasAttributes(SeatAttributeWindow, SeatAttributeAisle))
+	assert.False(t, (&Seat{}).HasAttributes(SeatAttributeNearLavatory))
+}
+
+func TestOverbookingPolicy_Limit(t *testing.T) {
+	percentage := OverbookingPolicy{Mode: OverbookingPercentage, Value: 5}
+	assert.Equal(t, 7, percentage.Limit(150))
+	assert.Equal(t, 0, percentage.Limit(12))
+
+	fixed := OverbookingPolicy{Mode: OverbookingFixed, Value: 4}
+	assert.Equal(t, 4, fixed.Limit(150))
+}
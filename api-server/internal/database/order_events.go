@@ -0,0 +1,87 @@
+package database
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// OrderEventType is the kind of change recorded in an order's history
+type OrderEventType string
+
+const (
+	// OrderEventCreated is recorded when an order is created
+	OrderEventCreated OrderEventType = "created"
+	// OrderEventStatusChanged is recorded on every status transition
+	OrderEventStatusChanged OrderEventType = "status_changed"
+	// OrderEventFlightChanged is recorded when an order moves to another
+	// flight
+	OrderEventFlightChanged OrderEventType = "flight_changed"
+	// OrderEventSeatsAdded is recorded when seats are added to an order
+	OrderEventSeatsAdded OrderEventType = "seats_added"
+	// OrderEventSeatsRemoved is recorded when seats are removed from an order
+	OrderEventSeatsRemoved OrderEventType = "seats_removed"
+	// OrderEventPaymentAttempted is recorded for every charge of the order's
+	// customer, successful or not
+	OrderEventPaymentAttempted OrderEventType = "payment_attempted"
+	// OrderEventRefundIssued is recorded when money is returned to the
+	// order's customer
+	OrderEventRefundIssued OrderEventType = "refund_issued"
+	// OrderEventSignalSent is recorded when a workflow is signalled about
+	// the order
+	OrderEventSignalSent OrderEventType = "signal_sent"
+)
+
+// OrderEvent is an entry in an order's history. Actor is the service that
+// made the change (api-server, temporal-worker) or the database user for
+// manual changes; Payload depends on Type.
+type OrderEvent struct {
+	ID        int64           `json:"id"`
+	OrderID   uuid.UUID       `json:"orderId"`
+	Type      OrderEventType  `json:"type"`
+	Actor     string          `json:"actor"`
+	Payload   json.RawMessage `json:"payload"`
+	CreatedAt time.Time       `json:"createdAt"`
+}
+
+// GetOrderEvents returns the history of an order, oldest first. Status,
+// flight and seat changes are recorded by database triggers.
+func (r *Repository) GetOrderEvents(ctx context.Context, orderID uuid.UUID) ([]OrderEvent, error) {
+	rows, err := r.pool.Query(ctx, `
+		SELECT id, order_id, event_type, actor, payload, created_at
+		FROM order_events
+		WHERE order_id = $1
+		ORDER BY id
+	`, orderID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query order events: %w", err)
+	}
+	defer rows.Close()
+
+	events := []OrderEvent{}
+	for rows.Next() {
+		var e OrderEvent
+		if err := rows.Scan(&e.ID, &e.OrderID, &e.Type, &e.Actor, &e.Payload, &e.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan order event: %w", err)
+		}
+		events = append(events, e)
+	}
+	return events, rows.Err()
+}
+
+// RecordOrderEvent adds an event with payload to an order's history
+func (r *Repository) RecordOrderEvent(ctx context.Context, orderID uuid.UUID, eventType OrderEventType, payload map[string]interface{}) error {
+	if payload == nil {
+		payload = map[string]interface{}{}
+	}
+	_, err := r.pool.Exec(ctx, `
+		INSERT INTO order_events (order_id, event_type, payload) VALUES ($1, $2, $3)
+	`, orderID, eventType, payload)
+	if err != nil {
+		return fmt.Errorf("failed to record order event: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,171 @@
+package database
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// OrderSearchParams holds filters and pagination for the admin order search.
+// CreatedFrom is inclusive and CreatedTo exclusive; amounts are inclusive.
+type OrderSearchParams struct {
+	Statuses      []OrderStatus
+	FlightID      *uuid.UUID
+	CustomerEmail string
+	CreatedFrom   *time.Time
+	CreatedTo     *time.Time
+	MinAmount     *float64
+	MaxAmount     *float64
+	Cursor        *OrderCursor
+	Limit         int
+}
+
+// OrderCursor marks the position of the last order returned in a page
+type OrderCursor struct {
+	CreatedAt time.Time `json:"t"`
+	ID        uuid.UUID `json:"id"`
+}
+
+// Encode returns an opaque, URL-safe representation of the cursor
+func (c *OrderCursor) Encode() string {
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// DecodeOrderCursor parses a cursor produced by OrderCursor.Encode
+func DecodeOrderCursor(s string) (*OrderCursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	var c OrderCursor
+	if err := json.Unmarshal(data, &c); err != nil || c.ID == uuid.Nil {
+		return nil, ErrInvalidCursor
+	}
+	return &c, nil
+}
+
+// OrderSummary is an order as listed by the admin order search, with the
+// flight numbers of its segments in travel order and its number of seats
+type OrderSummary struct {
+	ID               uuid.UUID   `json:"id"`
+	BookingReference *string     `json:"bookingReference,omitempty"`
+	CustomerID       *uuid.UUID  `json:"customerId,omitempty"`
+	CustomerName     string      `json:"customerName"`
+	CustomerEmail    string      `json:"customerEmail"`
+	Status           OrderStatus `json:"status"`
+	TotalAmount      float64     `json:"totalAmount"`
+	PaymentAttempts  int         `json:"paymentAttempts"`
+	FlightID         uuid.UUID   `json:"flightId"`
+	FlightNumbers    []string    `json:"flightNumbers"`
+	SeatCount        int         `json:"seatCount"`
+	CreatedAt        time.Time   `json:"createdAt"`
+	UpdatedAt        time.Time   `json:"updatedAt"`
+}
+
+// OrderPage is a page of order search results
+type OrderPage struct {
+	Orders     []OrderSummary
+	NextCursor *OrderCursor
+}
+
+// SearchOrders returns the orders matching the given filters, newest first,
+// paginated with a keyset cursor. Status and email filters are plain
+// comparisons so idx_orders_status and idx_orders_customer_email can be used;
+// a flight matches any segment of an order through idx_order_segments_flight.
+func (r *Repository) SearchOrders(ctx context.Context, p OrderSearchParams) (*OrderPage, error) {
+	var args []interface{}
+	arg := func(v interface{}) string {
+		args = append(args, v)
+		return fmt.Sprintf("$%d", len(args))
+	}
+
+	var conditions []string
+	if len(p.Statuses) > 0 {
+		statuses := make([]string, len(p.Statuses))
+		for i, s := range p.Statuses {
+			statuses[i] = string(s)
+		}
+		conditions = append(conditions, "o.status = ANY("+arg(statuses)+"::order_status[])")
+	}
+	if p.FlightID != nil {
+		conditions = append(conditions, "o.id IN (SELECT order_id FROM order_segments WHERE flight_id = "+arg(*p.FlightID)+")")
+	}
+	if p.CustomerEmail != "" {
+		conditions = append(conditions, "LOWER(o.customer_email) = LOWER("+arg(p.CustomerEmail)+")")
+	}
+	if p.CreatedFrom != nil {
+		conditions = append(conditions, "o.created_at >= "+arg(*p.CreatedFrom))
+	}
+	if p.CreatedTo != nil {
+		conditions = append(conditions, "o.created_at < "+arg(*p.CreatedTo))
+	}
+	if p.MinAmount != nil {
+		conditions = append(conditions, "o.total_amount >= "+arg(*p.MinAmount))
+	}
+	if p.MaxAmount != nil {
+		conditions = append(conditions, "o.total_amount <= "+arg(*p.MaxAmount))
+	}
+	if c := p.Cursor; c != nil {
+		conditions = append(conditions, fmt.Sprintf("(o.created_at, o.id) < (%s, %s)", arg(c.CreatedAt), arg(c.ID)))
+	}
+
+	limit := p.Limit
+	if limit <= 0 {
+		limit = 50
+	}
+
+	where := ""
+	if len(conditions) > 0 {
+		where = "WHERE " + strings.Join(conditions, " AND ")
+	}
+
+	query := fmt.Sprintf(`
+		SELECT o.id, o.booking_reference, o.customer_id, o.customer_name, o.customer_email, o.status,
+		       o.total_amount, o.payment_attempts, o.flight_id,
+		       ARRAY(
+		           SELECT f.flight_number
+		           FROM order_segments os
+		           JOIN flights f ON f.id = os.flight_id
+		           WHERE os.order_id = o.id
+		           ORDER BY os.segment_index
+		       ),
+		       (SELECT COUNT(*) FROM order_seats WHERE order_id = o.id),
+		       o.created_at, o.updated_at
+		FROM orders o
+		%s
+		ORDER BY o.created_at DESC, o.id DESC
+		LIMIT %s
+	`, where, arg(limit+1))
+
+	rows, err := r.pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search orders: %w", err)
+	}
+	defer rows.Close()
+
+	page := &OrderPage{Orders: make([]OrderSummary, 0, limit)}
+	for rows.Next() {
+		var o OrderSummary
+		if err := rows.Scan(&o.ID, &o.BookingReference, &o.CustomerID, &o.CustomerName, &o.CustomerEmail,
+			&o.Status, &o.TotalAmount, &o.PaymentAttempts, &o.FlightID, &o.FlightNumbers, &o.SeatCount,
+			&o.CreatedAt, &o.UpdatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan order: %w", err)
+		}
+		if len(page.Orders) == limit {
+			last := page.Orders[limit-1]
+			page.NextCursor = &OrderCursor{CreatedAt: last.CreatedAt, ID: last.ID}
+			break
+		}
+		page.Orders = append(page.Orders, o)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to search orders: %w", err)
+	}
+	return page, nil
+}
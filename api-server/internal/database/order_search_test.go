@@ -0,0 +1,24 @@
+package database
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOrderCursor_RoundTrip(t *testing.T) {
+	c := &OrderCursor{CreatedAt: time.Date(2026, 6, 1, 12, 30, 0, 123456000, time.UTC), ID: uuid.New()}
+
+	decoded, err := DecodeOrderCursor(c.Encode())
+	require.NoError(t, err)
+	assert.Equal(t, c.ID, decoded.ID)
+	assert.True(t, c.CreatedAt.Equal(decoded.CreatedAt))
+
+	_, err = DecodeOrderCursor("not a cursor")
+	assert.ErrorIs(t, err, ErrInvalidCursor)
+	_, err = DecodeOrderCursor((&OrderCursor{}).Encode())
+	assert.ErrorIs(t, err, ErrInvalidCursor)
+}
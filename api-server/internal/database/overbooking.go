@@ -0,0 +1,610 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// OverbookingMode says how an overbooking policy's value is applied
+type OverbookingMode string
+
+const (
+	// OverbookingPercentage allows a percentage of a class's seats to be oversold
+	OverbookingPercentage OverbookingMode = "percentage"
+	// OverbookingFixed allows a fixed number of seats of a class to be oversold
+	OverbookingFixed OverbookingMode = "fixed"
+)
+
+// OverbookingPolicy says how far the classes of a flight, or of every flight
+// on a route, may be oversold. A policy without a class applies to each
+// class separately.
+type OverbookingPolicy struct {
+	ID          uuid.UUID       `json:"id"`
+	FlightID    *uuid.UUID      `json:"flightId,omitempty"`
+	Origin      *string         `json:"origin,omitempty"`
+	Destination *string         `json:"destination,omitempty"`
+	Class       *string         `json:"class,omitempty"`
+	Mode        OverbookingMode `json:"mode"`
+	Value       float64         `json:"value"`
+	CreatedAt   time.Time       `json:"createdAt"`
+	UpdatedAt   time.Time       `json:"updatedAt"`
+}
+
+// Limit returns how many travelers of a class with capacity seats may be
+// booked without a seat
+func (p *OverbookingPolicy) Limit(capacity int) int {
+	if p.Mode == OverbookingPercentage {
+		return int(math.Floor(float64(capacity) * p.Value / 100))
+	}
+	return int(p.Value)
+}
+
+// OverbookedSeatStatus represents the state of a seat sold beyond capacity
+type OverbookedSeatStatus string
+
+const (
+	OverbookedSeatUnassigned OverbookedSeatStatus = "unassigned"
+	OverbookedSeatAssigned   OverbookedSeatStatus = "assigned"
+	OverbookedSeatDenied     OverbookedSeatStatus = "denied"
+)
+
+// OverbookedSeat is a traveler of an order booked without a physical seat.
+// SeatID and SeatNumber are set once a seat is assigned at check-in or when
+// the flight closes.
+type OverbookedSeat struct {
+	ID         uuid.UUID            `json:"id"`
+	FlightID   uuid.UUID            `json:"flightId"`
+	Class      string               `json:"class"`
+	Price      float64              `json:"price"`
+	Status     OverbookedSeatStatus `json:"status"`
+	SeatID     *uuid.UUID           `json:"seatId,omitempty"`
+	SeatNumber *string              `json:"seatNumber,omitempty"`
+	AssignedAt *time.Time           `json:"assignedAt,omitempty"`
+}
+
+// OverbookingClass is the overbooking state of a class on a flight. Limit is
+// the number of travelers that may be without a seat; Unassigned counts the
+// ones that currently are.
+type OverbookingClass struct {
+	Class      string `json:"class"`
+	Seats      int    `json:"seats"`
+	Available  int    `json:"available"`
+	Limit      int    `json:"limit"`
+	Unassigned int    `json:"unassigned"`
+	Assigned   int    `json:"assigned"`
+	Denied     int    `json:"denied"`
+}
+
+// DeniedBoarding is an order whose travelers did not get a seat when the
+// flight closed
+type DeniedBoarding struct {
+	OrderID       uuid.UUID `json:"orderId"`
+	CustomerName  string    `json:"customerName"`
+	CustomerEmail string    `json:"customerEmail"`
+	Class         string    `json:"class"`
+	Travelers     int       `json:"travelers"`
+	Amount        float64   `json:"amount"`
+}
+
+// ErrOverbookingLimit is returned when a class cannot be oversold any further
+var ErrOverbookingLimit = errors.New("overbooking limit reached")
+
+// activeOrder matches the orders that still count towards a flight's sales
+const activeOrder = `o.status NOT IN ('failed', 'cancelled', 'expired', 'refunded')`
+
+const overbookingPolicyColumns = `
+	p.id, p.flight_id, p.origin, p.destination, p.class, p.mode, p.value, p.created_at, p.updated_at`
+
+func scanOverbookingPolicy(row pgx.Row) (OverbookingPolicy, error) {
+	var p OverbookingPolicy
+	err := row.Scan(&p.ID, &p.FlightID, &p.Origin, &p.Destination, &p.Class, &p.Mode, &p.Value, &p.CreatedAt, &p.UpdatedAt)
+	return p, err
+}
+
+// GetOverbookingPolicies returns every overbooking policy, flight policies
+// first
+func (r *Repository) GetOverbookingPolicies(ctx context.Context) ([]OverbookingPolicy, error) {
+	rows, err := r.pool.Query(ctx, `
+		SELECT `+overbookingPolicyColumns+`
+		FROM overbooking_policies p
+		ORDER BY p.flight_id IS NULL, p.flight_id, p.origin, p.destination, p.class NULLS FIRST
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query overbooking policies: %w", err)
+	}
+	defer rows.Close()
+
+	policies := []OverbookingPolicy{}
+	for rows.Next() {
+		p, err := scanOverbookingPolicy(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan overbooking policy: %w", err)
+		}
+		policies = append(policies, p)
+	}
+	return policies, rows.Err()
+}
+
+// SaveOverbookingPolicy creates the policy of a flight or route and class,
+// or replaces the mode and value of the existing one
+func (r *Repository) SaveOverbookingPolicy(ctx context.Context, p *OverbookingPolicy) error {
+	if p.ID == uuid.Nil {
+		p.ID = uuid.New()
+	}
+	conflict := `(origin, destination, COALESCE(class, '')) WHERE flight_id IS NULL`
+	if p.FlightID != nil {
+		conflict = `(flight_id, COALESCE(class, '')) WHERE flight_id IS NOT NULL`
+	}
+
+	row := r.pool.QueryRow(ctx, `
+		INSERT INTO overbooking_policies AS p (id, flight_id, origin, destination, class, mode, value)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		ON CONFLICT `+conflict+` DO UPDATE SET mode = EXCLUDED.mode, value = EXCLUDED.value
+		RETURNING `+overbookingPolicyColumns,
+		p.ID, p.FlightID, p.Origin, p.Destination, p.Class, p.Mode, p.Value)
+	saved, err := scanOverbookingPolicy(row)
+	if err != nil {
+		return fmt.Errorf("failed to save overbooking policy: %w", err)
+	}
+	*p = saved
+	return nil
+}
+
+// DeleteOverbookingPolicy deletes an overbooking policy. Travelers already
+// booked without a seat keep their bookings.
+func (r *Repository) DeleteOverbookingPolicy(ctx context.Context, id uuid.UUID) error {
+	tag, err := r.pool.Exec(ctx, `DELETE FROM overbooking_policies WHERE id = $1`, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete overbooking policy: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// overbookingPolicyFor returns the policy that applies to a class on a
+// flight, or nil if it may not be oversold
+func overbookingPolicyFor(ctx context.Context, tx pgx.Tx, flightID uuid.UUID, class string) (*OverbookingPolicy, error) {
+	row := tx.QueryRow(ctx, `
+		SELECT `+overbookingPolicyColumns+`
+		FROM overbooking_policies p
+		JOIN flights f ON f.id = $1
+		WHERE (p.flight_id = f.id OR (p.flight_id IS NULL AND p.origin = f.origin AND p.destination = f.destination))
+		  AND (p.class = $2 OR p.class IS NULL)
+		ORDER BY p.flight_id IS NULL, p.class IS NULL
+		LIMIT 1
+	`, flightID, class)
+	p, err := scanOverbookingPolicy(row)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get overbooking policy: %w", err)
+	}
+	return &p, nil
+}
+
+// OverbookOrder books count travelers of an order in a class without
+// physical seats, replacing the seats the order held before. The flight is
+// locked so concurrent orders cannot exceed the class's overbooking limit
+// together; ErrOverbookingLimit is returned when they would. Each traveler
+// is charged the lowest fare of the class. It returns the seats the order
+// no longer holds.
+func (r *Repository) OverbookOrder(ctx context.Context, orderID, flightID uuid.UUID, class string, count int, expiresAt time.Time) ([]uuid.UUID, error) {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err := tx.Exec(ctx, `SELECT 1 FROM flights WHERE id = $1 FOR UPDATE`, flightID); err != nil {
+		return nil, fmt.Errorf("failed to lock flight: %w", err)
+	}
+
+	var status OrderStatus
+	err = tx.QueryRow(ctx, `SELECT status FROM orders WHERE id = $1 FOR UPDATE`, orderID).Scan(&status)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("failed to get order: %w", err)
+	}
+	switch status {
+	case OrderStatusPending, OrderStatusSeatsSelected, OrderStatusAwaitingPayment:
+	default:
+		return nil, fmt.Errorf("%w: order is %s", ErrOrderNotModifiable, status)
+	}
+
+	policy, err := overbookingPolicyFor(ctx, tx, flightID, class)
+	if err != nil {
+		return nil, err
+	}
+	if policy == nil {
+		return nil, fmt.Errorf("%w: %s seats cannot be overbooked", ErrOverbookingLimit, class)
+	}
+
+	var capacity, unassigned int
+	var fare *float64
+	err = tx.QueryRow(ctx, `
+		SELECT
+			(SELECT COUNT(*) FROM seats WHERE flight_id = $1 AND class = $2),
+			(SELECT MIN(price) FROM seats WHERE flight_id = $1 AND class = $2),
+			(SELECT COUNT(*) FROM overbooked_seats ob JOIN orders o ON o.id = ob.order_id
+			 WHERE ob.flight_id = $1 AND ob.class = $2 AND ob.status = 'unassigned'
+			   AND ob.order_id <> $3 AND `+activeOrder+`)
+	`, flightID, class, orderID).Scan(&capacity, &fare, &unassigned)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count overbooked seats: %w", err)
+	}
+	if fare == nil {
+		return nil, fmt.Errorf("%w: flight has no %s seats", ErrOverbookingLimit, class)
+	}
+	if limit := policy.Limit(capacity); unassigned+count > limit {
+		return nil, fmt.Errorf("%w: %d of %d %s seats left", ErrOverbookingLimit, max(limit-unassigned, 0), limit, class)
+	}
+
+	rows, err := tx.Query(ctx, `
+		UPDATE seats SET status = 'available', held_until = NULL, held_by_order = NULL
+		WHERE held_by_order = $1 AND status = 'held'
+		RETURNING id
+	`, orderID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to release seats: %w", err)
+	}
+	released, err := pgx.CollectRows(rows, pgx.RowTo[uuid.UUID])
+	if err != nil {
+		return nil, fmt.Errorf("failed to release seats: %w", err)
+	}
+
+	if _, err := tx.Exec(ctx, `DELETE FROM order_seats WHERE order_id = $1`, orderID); err != nil {
+		return nil, fmt.Errorf("failed to remove order seats: %w", err)
+	}
+	if _, err := tx.Exec(ctx, `DELETE FROM overbooked_seats WHERE order_id = $1`, orderID); err != nil {
+		return nil, fmt.Errorf("failed to remove overbooked seats: %w", err)
+	}
+
+	_, err = tx.Exec(ctx, `
+		INSERT INTO overbooked_seats (order_id, flight_id, class, price)
+		SELECT $1, $2, $3, $4 FROM generate_series(1, $5)
+	`, orderID, flightID, class, *fare, count)
+	if err != nil {
+		return nil, fmt.Errorf("failed to add overbooked seats: %w", err)
+	}
+
+	_, err = tx.Exec(ctx, `
+		UPDATE orders
+		SET total_amount = $2, status = 'seats_selected', reservation_expires_at = $3
+		WHERE id = $1
+	`, orderID, *fare*float64(count), expiresAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update order: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to overbook order: %w", err)
+	}
+	return released, nil
+}
+
+// GetOrderOverbookedSeats returns the travelers of an order booked without
+// a physical seat
+func (r *Repository) GetOrderOverbookedSeats(ctx context.Context, orderID uuid.UUID) ([]OverbookedSeat, error) {
+	rows, err := r.pool.Query(ctx, `
+		SELECT ob.id, ob.flight_id, ob.class, ob.price, ob.status, ob.seat_id, s.seat_number, ob.assigned_at
+		FROM overbooked_seats ob
+		LEFT JOIN seats s ON s.id = ob.seat_id
+		WHERE ob.order_id = $1
+		ORDER BY ob.created_at, ob.id
+	`, orderID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query overbooked seats: %w", err)
+	}
+	defer rows.Close()
+
+	var seats []OverbookedSeat
+	for rows.Next() {
+		var s OverbookedSeat
+		if err := rows.Scan(&s.ID, &s.FlightID, &s.Class, &s.Price, &s.Status, &s.SeatID, &s.SeatNumber, &s.AssignedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan overbooked seat: %w", err)
+		}
+		seats = append(seats, s)
+	}
+	return seats, rows.Err()
+}
+
+// AssignOverbookedSeats gives every traveler of a confirmed order booked
+// without a seat one of the available seats of their class, at the price
+// they paid. The party gets seats together or not at all:
+// ErrSeatNotAvailable is returned when a class has too few seats left. It
+// returns the seats assigned.
+func (r *Repository) AssignOverbookedSeats(ctx context.Context, orderID uuid.UUID) ([]uuid.UUID, error) {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	rows, err := tx.Query(ctx, `
+		SELECT id, flight_id, class FROM overbooked_seats
+		WHERE order_id = $1 AND status = 'unassigned'
+		ORDER BY created_at, id
+		FOR UPDATE
+	`, orderID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to lock overbooked seats: %w", err)
+	}
+	type travelers struct {
+		flightID uuid.UUID
+		class    string
+		ids      []uuid.UUID
+	}
+	var groups []*travelers
+	for rows.Next() {
+		var id, flightID uuid.UUID
+		var class string
+		if err := rows.Scan(&id, &flightID, &class); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("failed to scan overbooked seat: %w", err)
+		}
+		var g *travelers
+		for _, existing := range groups {
+			if existing.flightID == flightID && existing.class == class {
+				g = existing
+			}
+		}
+		if g == nil {
+			g = &travelers{flightID: flightID, class: class}
+			groups = append(groups, g)
+		}
+		g.ids = append(g.ids, id)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to lock overbooked seats: %w", err)
+	}
+
+	var assigned []uuid.UUID
+	for _, g := range groups {
+		seatIDs, err := assignSeats(ctx, tx, orderID, g.flightID, g.class, g.ids)
+		if err != nil {
+			return nil, err
+		}
+		if len(seatIDs) < len(g.ids) {
+			return nil, fmt.Errorf("%w: %d of %d %s seats available", ErrSeatNotAvailable, len(seatIDs), len(g.ids), g.class)
+		}
+		assigned = append(assigned, seatIDs...)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to assign seats: %w", err)
+	}
+	return assigned, nil
+}
+
+// assignSeats books available seats of a class, front to back, for as many
+// of the given overbooked travelers as it can and returns the seats. The
+// seats are added to the order at the traveler's price.
+func assignSeats(ctx context.Context, tx pgx.Tx, orderID, flightID uuid.UUID, class string, travelerIDs []uuid.UUID) ([]uuid.UUID, error) {
+	rows, err := tx.Query(ctx, `
+		SELECT id FROM seats
+		WHERE flight_id = $1 AND class = $2 AND status = 'available'
+		ORDER BY row_number, column_letter
+		LIMIT $3
+		FOR UPDATE SKIP LOCKED
+	`, flightID, class, len(travelerIDs))
+	if err != nil {
+		return nil, fmt.Errorf("failed to find seats: %w", err)
+	}
+	seatIDs, err := pgx.CollectRows(rows, pgx.RowTo[uuid.UUID])
+	if err != nil {
+		return nil, fmt.Errorf("failed to find seats: %w", err)
+	}
+	if len(seatIDs) == 0 {
+		return nil, nil
+	}
+	travelerIDs = travelerIDs[:len(seatIDs)]
+
+	_, err = tx.Exec(ctx, `
+		UPDATE seats SET status = 'booked', held_by_order = $1, held_until = NULL WHERE id = ANY($2)
+	`, orderID, seatIDs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to book seats: %w", err)
+	}
+
+	_, err = tx.Exec(ctx, `
+		UPDATE overbooked_seats ob
+		SET status = 'assigned', seat_id = a.seat_id, assigned_at = NOW()
+		FROM (SELECT unnest($1::uuid[]) AS id, unnest($2::uuid[]) AS seat_id) a
+		WHERE ob.id = a.id
+	`, travelerIDs, seatIDs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to assign seats: %w", err)
+	}
+
+	_, err = tx.Exec(ctx, `
+		INSERT INTO order_seats (order_id, seat_id, price, passenger_id)
+		SELECT $1, seat_id, price, passenger_id FROM overbooked_seats WHERE id = ANY($2)
+	`, orderID, travelerIDs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to add order seats: %w", err)
+	}
+
+	_, err = tx.Exec(ctx, `
+		UPDATE flights f
+		SET available_seats = (SELECT COUNT(*) FROM seats s WHERE s.flight_id = f.id AND s.status = 'available')
+		WHERE f.id = $1
+	`, flightID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update seat counts: %w", err)
+	}
+
+	return seatIDs, nil
+}
+
+// BoardFlight saves the boarding status of f and settles its overbooked
+// travelers in one transaction, so the flight only starts boarding once they
+// are settled. Travelers of confirmed orders still without a seat get the
+// seats left, first booked first served; the others are denied boarding and
+// listed in the denied-boarding report. It returns the seats assigned.
+func (r *Repository) BoardFlight(ctx context.Context, f *Flight) ([]uuid.UUID, error) {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	err = tx.QueryRow(ctx, updateFlightStatusQuery,
+		f.ID, f.Status, f.StatusReason, f.EstimatedDepartureTime, f.EstimatedArrivalTime).Scan(&f.UpdatedAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("failed to update flight status: %w", err)
+	}
+
+	rows, err := tx.Query(ctx, `
+		SELECT ob.id, ob.order_id, ob.class
+		FROM overbooked_seats ob
+		JOIN orders o ON o.id = ob.order_id
+		WHERE ob.flight_id = $1 AND ob.status = 'unassigned' AND o.status = 'confirmed'
+		ORDER BY ob.created_at, ob.id
+		FOR UPDATE OF ob
+	`, f.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to lock overbooked seats: %w", err)
+	}
+	type traveler struct {
+		id, orderID uuid.UUID
+		class       string
+	}
+	var waiting []traveler
+	for rows.Next() {
+		var t traveler
+		if err := rows.Scan(&t.id, &t.orderID, &t.class); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("failed to scan overbooked seat: %w", err)
+		}
+		waiting = append(waiting, t)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to lock overbooked seats: %w", err)
+	}
+
+	var assigned, denied []uuid.UUID
+	full := make(map[string]bool)
+	for _, t := range waiting {
+		if !full[t.class] {
+			seatIDs, err := assignSeats(ctx, tx, t.orderID, f.ID, t.class, []uuid.UUID{t.id})
+			if err != nil {
+				return nil, err
+			}
+			if len(seatIDs) > 0 {
+				assigned = append(assigned, seatIDs...)
+				continue
+			}
+			full[t.class] = true
+		}
+		denied = append(denied, t.id)
+	}
+
+	if len(denied) > 0 {
+		_, err := tx.Exec(ctx, `UPDATE overbooked_seats SET status = 'denied' WHERE id = ANY($1)`, denied)
+		if err != nil {
+			return nil, fmt.Errorf("failed to deny boarding: %w", err)
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to close overbooking: %w", err)
+	}
+	return assigned, nil
+}
+
+// GetOverbookingClasses returns the overbooking state of every class of a
+// flight
+func (r *Repository) GetOverbookingClasses(ctx context.Context, flightID uuid.UUID) ([]OverbookingClass, error) {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	rows, err := tx.Query(ctx, `
+		SELECT s.class, COUNT(*), COUNT(*) FILTER (WHERE s.status = 'available'),
+		       (SELECT COUNT(*) FILTER (WHERE ob.status = 'unassigned' AND `+activeOrder+`)
+		        FROM overbooked_seats ob JOIN orders o ON o.id = ob.order_id
+		        WHERE ob.flight_id = $1 AND ob.class = s.class),
+		       (SELECT COUNT(*) FILTER (WHERE ob.status = 'assigned')
+		        FROM overbooked_seats ob JOIN orders o ON o.id = ob.order_id
+		        WHERE ob.flight_id = $1 AND ob.class = s.class AND `+activeOrder+`),
+		       (SELECT COUNT(*) FILTER (WHERE ob.status = 'denied')
+		        FROM overbooked_seats ob
+		        WHERE ob.flight_id = $1 AND ob.class = s.class)
+		FROM seats s
+		WHERE s.flight_id = $1
+		GROUP BY s.class
+		ORDER BY MIN(s.row_number)
+	`, flightID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query overbooking: %w", err)
+	}
+	var classes []OverbookingClass
+	for rows.Next() {
+		var c OverbookingClass
+		if err := rows.Scan(&c.Class, &c.Seats, &c.Available, &c.Unassigned, &c.Assigned, &c.Denied); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("failed to scan overbooking: %w", err)
+		}
+		classes = append(classes, c)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to query overbooking: %w", err)
+	}
+
+	for i := range classes {
+		policy, err := overbookingPolicyFor(ctx, tx, flightID, classes[i].Class)
+		if err != nil {
+			return nil, err
+		}
+		if policy != nil {
+			classes[i].Limit = policy.Limit(classes[i].Seats)
+		}
+	}
+	return classes, nil
+}
+
+// GetDeniedBoardings returns the orders with travelers denied boarding on a
+// flight, in the order they were booked
+func (r *Repository) GetDeniedBoardings(ctx context.Context, flightID uuid.UUID) ([]DeniedBoarding, error) {
+	rows, err := r.pool.Query(ctx, `
+		SELECT o.id, o.customer_name, o.customer_email, ob.class, COUNT(*), SUM(ob.price)
+		FROM overbooked_seats ob
+		JOIN orders o ON o.id = ob.order_id
+		WHERE ob.flight_id = $1 AND ob.status = 'denied'
+		GROUP BY o.id, ob.class
+		ORDER BY MIN(ob.created_at), o.id
+	`, flightID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query denied boardings: %w", err)
+	}
+	defer rows.Close()
+
+	denied := []DeniedBoarding{}
+	for rows.Next() {
+		var d DeniedBoarding
+		if err := rows.Scan(&d.OrderID, &d.CustomerName, &d.CustomerEmail, &d.Class, &d.Travelers, &d.Amount); err != nil {
+			return nil, fmt.Errorf("failed to scan denied boarding: %w", err)
+		}
+		denied = append(denied, d)
+	}
+	return denied, rows.Err()
+}
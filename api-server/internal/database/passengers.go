@@ -0,0 +1,191 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// PassengerType is the fare category of a passenger, by age at departure
+type PassengerType string
+
+const (
+	PassengerAdult  PassengerType = "adult"
+	PassengerChild  PassengerType = "child"
+	PassengerInfant PassengerType = "infant"
+)
+
+// TravelDocument identifies a passenger, e.g. a passport. Country is an ISO
+// 3166-1 alpha-2 code; ExpiresOn is formatted "2006-01-02".
+type TravelDocument struct {
+	Type      string  `json:"type"`
+	Number    string  `json:"number"`
+	Country   string  `json:"country"`
+	ExpiresOn *string `json:"expiresOn,omitempty"`
+}
+
+// PassengerSeat is a seat of an order a passenger sits in. For travelers
+// booked without a seat, ID is the order's overbooked seat and SeatNumber is
+// empty until a seat is assigned.
+type PassengerSeat struct {
+	ID         uuid.UUID `json:"id"`
+	FlightID   uuid.UUID `json:"flightId"`
+	SeatNumber string    `json:"seatNumber,omitempty"`
+}
+
+// Passenger is a traveler of an order. DateOfBirth is formatted
+// "2006-01-02". Adults and children have a seat on each flight of the order;
+// infants sit on an adult's lap.
+type Passenger struct {
+	ID          uuid.UUID       `json:"id"`
+	FirstName   string          `json:"firstName"`
+	LastName    string          `json:"lastName"`
+	DateOfBirth string          `json:"dateOfBirth"`
+	Type        PassengerType   `json:"type"`
+	Document    *TravelDocument `json:"document,omitempty"`
+	Seats       []PassengerSeat `json:"seats,omitempty"`
+	// CancelledAt is set when the passenger was cancelled from a confirmed
+	// order
+	CancelledAt *time.Time `json:"cancelledAt,omitempty"`
+}
+
+// GetOrderPassengers returns the passengers of an order with their seats
+func (r *Repository) GetOrderPassengers(ctx context.Context, orderID uuid.UUID) ([]Passenger, error) {
+	rows, err := r.pool.Query(ctx, `
+		SELECT id, first_name, last_name, to_char(date_of_birth, 'YYYY-MM-DD'), passenger_type,
+		       document_type, document_number, document_country, to_char(document_expires_on, 'YYYY-MM-DD'),
+		       cancelled_at
+		FROM passengers
+		WHERE order_id = $1
+		ORDER BY created_at, id
+	`, orderID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query passengers: %w", err)
+	}
+	defer rows.Close()
+
+	var passengers []Passenger
+	index := make(map[uuid.UUID]int)
+	for rows.Next() {
+		var p Passenger
+		var docType, docNumber, docCountry, docExpiresOn *string
+		if err := rows.Scan(&p.ID, &p.FirstName, &p.LastName, &p.DateOfBirth, &p.Type,
+			&docType, &docNumber, &docCountry, &docExpiresOn, &p.CancelledAt); err != nil {
+			return nil, fmt.Errorf("failed to scan passenger: %w", err)
+		}
+		if docNumber != nil {
+			p.Document = &TravelDocument{Type: *docType, Number: *docNumber, Country: *docCountry, ExpiresOn: docExpiresOn}
+		}
+		index[p.ID] = len(passengers)
+		passengers = append(passengers, p)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to query passengers: %w", err)
+	}
+	if len(passengers) == 0 {
+		return nil, nil
+	}
+
+	// Assigned travelers booked without a seat are listed by their seat
+	rows, err = r.pool.Query(ctx, `
+		SELECT os.passenger_id, os.seat_id, s.flight_id, s.seat_number
+		FROM order_seats os
+		JOIN seats s ON s.id = os.seat_id
+		WHERE os.order_id = $1 AND os.passenger_id IS NOT NULL
+		UNION ALL
+		SELECT ob.passenger_id, ob.id, ob.flight_id, ''
+		FROM overbooked_seats ob
+		WHERE ob.order_id = $1 AND ob.passenger_id IS NOT NULL AND ob.status <> 'assigned'
+	`, orderID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query passenger seats: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var passengerID uuid.UUID
+		var seat PassengerSeat
+		if err := rows.Scan(&passengerID, &seat.ID, &seat.FlightID, &seat.SeatNumber); err != nil {
+			return nil, fmt.Errorf("failed to scan passenger seat: %w", err)
+		}
+		if i, ok := index[passengerID]; ok {
+			passengers[i].Seats = append(passengers[i].Seats, seat)
+		}
+	}
+	return passengers, rows.Err()
+}
+
+// SetOrderPassengers replaces the passengers of an order that has not been
+// paid for yet and assigns them their seats. ErrOrderNotModifiable is
+// returned once payment is being processed, or if a seat is no longer part
+// of the order.
+func (r *Repository) SetOrderPassengers(ctx context.Context, orderID uuid.UUID, passengers []Passenger) error {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	var status OrderStatus
+	err = tx.QueryRow(ctx, `SELECT status FROM orders WHERE id = $1 FOR UPDATE`, orderID).Scan(&status)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return ErrNotFound
+	}
+	if err != nil {
+		return fmt.Errorf("failed to lock order: %w", err)
+	}
+	switch status {
+	case OrderStatusPending, OrderStatusSeatsSelected, OrderStatusAwaitingPayment:
+	default:
+		return fmt.Errorf("%w: order is %s", ErrOrderNotModifiable, status)
+	}
+
+	// Seats of the previous passengers are unassigned by ON DELETE SET NULL
+	if _, err := tx.Exec(ctx, `DELETE FROM passengers WHERE order_id = $1`, orderID); err != nil {
+		return fmt.Errorf("failed to clear passengers: %w", err)
+	}
+
+	for _, p := range passengers {
+		var docType, docNumber, docCountry, docExpiresOn *string
+		if p.Document != nil {
+			docType, docNumber, docCountry, docExpiresOn = &p.Document.Type, &p.Document.Number, &p.Document.Country, p.Document.ExpiresOn
+		}
+		_, err := tx.Exec(ctx, `
+			INSERT INTO passengers (id, order_id, first_name, last_name, date_of_birth, passenger_type,
+			                        document_type, document_number, document_country, document_expires_on)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		`, p.ID, orderID, p.FirstName, p.LastName, p.DateOfBirth, p.Type,
+			docType, docNumber, docCountry, docExpiresOn)
+		if err != nil {
+			return fmt.Errorf("failed to add passenger: %w", err)
+		}
+
+		for _, seat := range p.Seats {
+			tag, err := tx.Exec(ctx, `
+				UPDATE order_seats SET passenger_id = $3 WHERE order_id = $1 AND seat_id = $2
+			`, orderID, seat.ID, p.ID)
+			if err != nil {
+				return fmt.Errorf("failed to assign passenger seat: %w", err)
+			}
+			if tag.RowsAffected() == 1 {
+				continue
+			}
+			tag, err = tx.Exec(ctx, `
+				UPDATE overbooked_seats SET passenger_id = $3 WHERE order_id = $1 AND id = $2
+			`, orderID, seat.ID, p.ID)
+			if err != nil {
+				return fmt.Errorf("failed to assign passenger seat: %w", err)
+			}
+			if tag.RowsAffected() == 0 {
+				return fmt.Errorf("%w: seat %s is no longer part of the order", ErrOrderNotModifiable, seat.ID)
+			}
+		}
+	}
+
+	return tx.Commit(ctx)
+}
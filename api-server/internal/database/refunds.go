@@ -0,0 +1,271 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// RefundStatus is the state of the refund of a cancelled booking
+type RefundStatus string
+
+const (
+	// RefundPending means the refund is waiting for the payment provider
+	RefundPending RefundStatus = "pending"
+	// RefundCompleted means the money was returned, or nothing was due
+	RefundCompleted RefundStatus = "completed"
+	// RefundFailed means the payment provider did not accept the refund
+	RefundFailed RefundStatus = "failed"
+)
+
+// OrderRefund is the refund of a cancellation of a confirmed order: of the
+// passengers in PassengerIDs, or of the whole order when it is empty. Amount
+// is RefundPercent of FareAmount, what was paid for the cancelled seats.
+type OrderRefund struct {
+	ID               uuid.UUID    `json:"id"`
+	OrderID          uuid.UUID    `json:"orderId"`
+	PassengerIDs     []uuid.UUID  `json:"passengerIds"`
+	FareAmount       float64      `json:"fareAmount"`
+	RefundPercent    int          `json:"refundPercent"`
+	Amount           float64      `json:"amount"`
+	Status           RefundStatus `json:"status"`
+	WorkflowID       *string      `json:"workflowId,omitempty"`
+	ProviderRefundID *string      `json:"providerRefundId,omitempty"`
+	FailureReason    *string      `json:"failureReason,omitempty"`
+	CreatedAt        time.Time    `json:"createdAt"`
+	CompletedAt      *time.Time   `json:"completedAt,omitempty"`
+}
+
+// orderRefundSelect selects order refunds
+const orderRefundSelect = `
+	SELECT id, order_id, passenger_ids, fare_amount, refund_percent, amount, status, workflow_id,
+	       provider_refund_id, failure_reason, created_at, completed_at
+	FROM order_refunds`
+
+func scanOrderRefunds(rows pgx.Rows) ([]OrderRefund, error) {
+	defer rows.Close()
+
+	refunds := []OrderRefund{}
+	for rows.Next() {
+		var f OrderRefund
+		err := rows.Scan(&f.ID, &f.OrderID, &f.PassengerIDs, &f.FareAmount, &f.RefundPercent, &f.Amount,
+			&f.Status, &f.WorkflowID, &f.ProviderRefundID, &f.FailureReason, &f.CreatedAt, &f.CompletedAt)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan order refund: %w", err)
+		}
+		refunds = append(refunds, f)
+	}
+	return refunds, rows.Err()
+}
+
+// GetOrderRefunds returns the refunds of an order, oldest first
+func (r *Repository) GetOrderRefunds(ctx context.Context, orderID uuid.UUID) ([]OrderRefund, error) {
+	rows, err := r.pool.Query(ctx, orderRefundSelect+`
+		WHERE order_id = $1
+		ORDER BY created_at, id
+	`, orderID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query order refunds: %w", err)
+	}
+	return scanOrderRefunds(rows)
+}
+
+// GetOrderRefund returns an order refund by ID
+func (r *Repository) GetOrderRefund(ctx context.Context, id uuid.UUID) (*OrderRefund, error) {
+	rows, err := r.pool.Query(ctx, orderRefundSelect+` WHERE id = $1`, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query order refund: %w", err)
+	}
+	refunds, err := scanOrderRefunds(rows)
+	if err != nil {
+		return nil, err
+	}
+	if len(refunds) == 0 {
+		return nil, ErrNotFound
+	}
+	return &refunds[0], nil
+}
+
+// CancelBooking cancels the passengers in ref.PassengerIDs of a confirmed
+// order, or the whole order when it is empty, in one transaction. Their seats
+// are released, travelers booked without a seat removed and the passengers
+// marked cancelled; a whole order becomes cancelled, otherwise its total
+// drops by the cancelled fares. The refund is recorded at ref.RefundPercent
+// of those fares, already completed when nothing is due; ref.WorkflowID is
+// only kept for refunds to pay. ErrOrderNotModifiable is returned if the
+// order is no longer confirmed, has a seat or flight change in progress, or
+// a passenger is not an active passenger of the order. It returns the
+// refund and the released seats.
+func (r *Repository) CancelBooking(ctx context.Context, ref *OrderRefund) (*OrderRefund, []Seat, error) {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	var status OrderStatus
+	err = tx.QueryRow(ctx, `SELECT status FROM orders WHERE id = $1 FOR UPDATE`, ref.OrderID).Scan(&status)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil, ErrNotFound
+		}
+		return nil, nil, fmt.Errorf("failed to get order: %w", err)
+	}
+	if status != OrderStatusConfirmed {
+		return nil, nil, fmt.Errorf("%w: order is %s", ErrOrderNotModifiable, status)
+	}
+
+	passengerIDs := ref.PassengerIDs
+	if passengerIDs == nil {
+		passengerIDs = []uuid.UUID{}
+	}
+	var active int
+	var changing bool
+	err = tx.QueryRow(ctx, `
+		SELECT (SELECT COUNT(*) FROM passengers WHERE order_id = $1 AND id = ANY($2) AND cancelled_at IS NULL),
+		       EXISTS (SELECT 1 FROM seat_exchanges WHERE order_id = $1 AND status = 'pending')
+		       OR EXISTS (SELECT 1 FROM flight_exchanges WHERE order_id = $1 AND status IN ('pending', 'held', 'confirmed'))
+	`, ref.OrderID, passengerIDs).Scan(&active, &changing)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to check passengers: %w", err)
+	}
+	if changing {
+		return nil, nil, fmt.Errorf("%w: a seat or flight change is in progress", ErrOrderNotModifiable)
+	}
+	if active != len(passengerIDs) {
+		return nil, nil, fmt.Errorf("%w: a passenger is not an active passenger of the order", ErrOrderNotModifiable)
+	}
+	full := len(passengerIDs) == 0
+
+	// The seats of the cancelled passengers, or all of the order's
+	rows, err := tx.Query(ctx, `
+		SELECT s.id, s.flight_id, s.seat_number, os.price
+		FROM order_seats os
+		JOIN seats s ON s.id = os.seat_id
+		WHERE os.order_id = $1 AND (cardinality($2::uuid[]) = 0 OR os.passenger_id = ANY($2))
+		ORDER BY s.id
+		FOR UPDATE OF s
+	`, ref.OrderID, passengerIDs)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to lock order seats: %w", err)
+	}
+	var released []Seat
+	var seatIDs []uuid.UUID
+	var fare float64
+	for rows.Next() {
+		var seat Seat
+		var price float64
+		if err := rows.Scan(&seat.ID, &seat.FlightID, &seat.SeatNumber, &price); err != nil {
+			rows.Close()
+			return nil, nil, fmt.Errorf("failed to scan seat: %w", err)
+		}
+		released = append(released, seat)
+		seatIDs = append(seatIDs, seat.ID)
+		fare += price
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, nil, fmt.Errorf("failed to lock order seats: %w", err)
+	}
+
+	// Travelers booked without a seat paid for one too
+	var overbookedFare float64
+	err = tx.QueryRow(ctx, `
+		SELECT COALESCE(SUM(price), 0) FROM overbooked_seats
+		WHERE order_id = $1 AND status <> 'assigned' AND (cardinality($2::uuid[]) = 0 OR passenger_id = ANY($2))
+	`, ref.OrderID, passengerIDs).Scan(&overbookedFare)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to price overbooked seats: %w", err)
+	}
+	fare = math.Round((fare+overbookedFare)*100) / 100
+	amount := math.Round(fare*float64(ref.RefundPercent)) / 100
+
+	refundStatus := RefundPending
+	workflowID := ref.WorkflowID
+	if amount == 0 {
+		refundStatus = RefundCompleted
+		workflowID = nil
+	}
+
+	_, err = tx.Exec(ctx, `
+		UPDATE seats SET status = 'available', held_by_order = NULL, held_until = NULL WHERE id = ANY($1)
+	`, seatIDs)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to release seats: %w", err)
+	}
+
+	_, err = tx.Exec(ctx, `DELETE FROM order_seats WHERE order_id = $1 AND seat_id = ANY($2)`, ref.OrderID, seatIDs)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to remove order seats: %w", err)
+	}
+
+	_, err = tx.Exec(ctx, `
+		DELETE FROM overbooked_seats
+		WHERE order_id = $1 AND status <> 'assigned' AND (cardinality($2::uuid[]) = 0 OR passenger_id = ANY($2))
+	`, ref.OrderID, passengerIDs)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to remove overbooked seats: %w", err)
+	}
+
+	_, err = tx.Exec(ctx, `
+		UPDATE passengers SET cancelled_at = NOW()
+		WHERE order_id = $1 AND cancelled_at IS NULL AND (cardinality($2::uuid[]) = 0 OR id = ANY($2))
+	`, ref.OrderID, passengerIDs)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to cancel passengers: %w", err)
+	}
+
+	if full {
+		_, err = tx.Exec(ctx, `UPDATE orders SET status = 'cancelled' WHERE id = $1`, ref.OrderID)
+	} else {
+		_, err = tx.Exec(ctx, `UPDATE orders SET total_amount = total_amount - $2 WHERE id = $1`, ref.OrderID, fare)
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to update order: %w", err)
+	}
+
+	_, err = tx.Exec(ctx, `
+		UPDATE flights f
+		SET available_seats = (SELECT COUNT(*) FROM seats s WHERE s.flight_id = f.id AND s.status = 'available')
+		WHERE f.id IN (SELECT flight_id FROM order_segments WHERE order_id = $1)
+	`, ref.OrderID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to update seat counts: %w", err)
+	}
+
+	_, err = tx.Exec(ctx, `
+		INSERT INTO order_refunds (id, order_id, passenger_ids, fare_amount, refund_percent, amount, status, workflow_id, completed_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, CASE WHEN $7::refund_status = 'completed' THEN NOW() END)
+	`, ref.ID, ref.OrderID, passengerIDs, fare, ref.RefundPercent, amount, refundStatus, workflowID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create refund: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, nil, fmt.Errorf("failed to commit cancellation: %w", err)
+	}
+
+	refund, err := r.GetOrderRefund(ctx, ref.ID)
+	if err != nil {
+		return nil, nil, err
+	}
+	return refund, released, nil
+}
+
+// FailOrderRefund marks a pending refund failed with reason
+func (r *Repository) FailOrderRefund(ctx context.Context, id uuid.UUID, reason string) error {
+	_, err := r.pool.Exec(ctx, `
+		UPDATE order_refunds
+		SET status = 'failed', failure_reason = $2, completed_at = NOW()
+		WHERE id = $1 AND status = 'pending'
+	`, id, reason)
+	if err != nil {
+		return fmt.Errorf("failed to fail order refund: %w", err)
+	}
+	return nil
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
@@ -29,17 +30,58 @@ func NewRepository(pool *pgxpool.Pool) *Repository {
 
 // --- Flight Operations ---
 
-// GetAllFlights returns all flights with available seats
-func (r *Repository) GetAllFlights(ctx context.Context) ([]Flight, error) {
-	query := `
-		SELECT id, flight_number, origin, destination, departure_time, arrival_time,
-		       total_seats, available_seats, price_per_seat, created_at, updated_at
-		FROM flights
-		WHERE departure_time > NOW()
-		ORDER BY departure_time ASC
+// flightColumns and flightJoins select a flight together with both of its
+// airports; rows are read by scanFlight
+const (
+	flightColumns = `
+		f.id, f.flight_number, f.origin, f.destination, f.departure_time, f.arrival_time,
+		f.total_seats, f.available_seats, f.price_per_seat, f.cabin_layout,
+		f.status, f.status_reason, f.estimated_departure_time, f.estimated_arrival_time, f.schedule_id,
+		f.created_at, f.updated_at,
+		oa.iata_code, oa.icao_code, oa.name, oa.city, oa.country, oa.time_zone, oa.latitude, oa.longitude,
+		da.iata_code, da.icao_code, da.name, da.city, da.country, da.time_zone, da.latitude, da.longitude`
+	flightJoins = `
+		FROM flights f
+		JOIN airports oa ON oa.iata_code = f.origin
+		JOIN airports da ON da.iata_code = f.destination`
+	flightSelect = "SELECT" + flightColumns + flightJoins
+)
+
+// scanFlight scans a row selected with flightColumns, followed by any extra
+// columns, and fills the local times
+func scanFlight(row pgx.Row, extra ...interface{}) (Flight, error) {
+	var f Flight
+	oa, da := &Airport{}, &Airport{}
+	dest := []interface{}{
+		&f.ID, &f.FlightNumber, &f.Origin, &f.Destination,
+		&f.DepartureTime, &f.ArrivalTime, &f.TotalSeats, &f.AvailableSeats,
+		&f.PricePerSeat, &f.CabinLayout,
+		&f.Status, &f.StatusReason, &f.EstimatedDepartureTime, &f.EstimatedArrivalTime, &f.ScheduleID,
+		&f.CreatedAt, &f.UpdatedAt,
+		&oa.IATACode, &oa.ICAOCode, &oa.Name, &oa.City, &oa.Country, &oa.TimeZone, &oa.Latitude, &oa.Longitude,
+		&da.IATACode, &da.ICAOCode, &da.Name, &da.City, &da.Country, &da.TimeZone, &da.Latitude, &da.Longitude,
+	}
+	if err := row.Scan(append(dest, extra...)...); err != nil {
+		return f, err
+	}
+	f.OriginAirport, f.DestinationAirport = oa, da
+	f.SetLocalTimes()
+	return f, nil
+}
+
+// bookableFlight restricts a query to flights whose seats are being sold
+const bookableFlight = `f.status IN ('scheduled', 'delayed')`
+
+// GetFlightsDepartingBetween returns flights departing in [from, until) with
+// at least minSeats available seats
+func (r *Repository) GetFlightsDepartingBetween(ctx context.Context, from, until time.Time, minSeats int) ([]Flight, error) {
+	query := flightSelect + `
+		WHERE f.departure_time >= $1 AND f.departure_time < $2 AND f.available_seats >= $3
+		  AND ` + bookableFlight + `
+		ORDER BY f.departure_time ASC
 	`
 
-	rows, err := r.pool.Query(ctx, query)
+	rows, err := r.pool.Query(ctx, query, from, until, minSeats)
 	if err != nil {
 		return nil, fmt.Errorf("failed to query flights: %w", err)
 	}
@@ -47,12 +89,7 @@ func (r *Repository) GetAllFlights(ctx context.Context) ([]Flight, error) {
 
 	var flights []Flight
 	for rows.Next() {
-		var f Flight
-		err := rows.Scan(
-			&f.ID, &f.FlightNumber, &f.Origin, &f.Destination,
-			&f.DepartureTime, &f.ArrivalTime, &f.TotalSeats, &f.AvailableSeats,
-			&f.PricePerSeat, &f.CreatedAt, &f.UpdatedAt,
-		)
+		f, err := scanFlight(rows)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan flight: %w", err)
 		}
@@ -64,19 +101,11 @@ func (r *Repository) GetAllFlights(ctx context.Context) ([]Flight, error) {
 
 // GetFlightByID returns a flight by ID
 func (r *Repository) GetFlightByID(ctx context.Context, id uuid.UUID) (*Flight, error) {
-	query := `
-		SELECT id, flight_number, origin, destination, departure_time, arrival_time,
-		       total_seats, available_seats, price_per_seat, created_at, updated_at
-		FROM flights
-		WHERE id = $1
+	query := flightSelect + `
+		WHERE f.id = $1
 	`
 
-	var f Flight
-	err := r.pool.QueryRow(ctx, query, id).Scan(
-		&f.ID, &f.FlightNumber, &f.Origin, &f.Destination,
-		&f.DepartureTime, &f.ArrivalTime, &f.TotalSeats, &f.AvailableSeats,
-		&f.PricePerSeat, &f.CreatedAt, &f.UpdatedAt,
-	)
+	f, err := scanFlight(r.pool.QueryRow(ctx, query, id))
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
 			return nil, ErrNotFound
@@ -87,19 +116,95 @@ func (r *Repository) GetFlightByID(ctx context.Context, id uuid.UUID) (*Flight,
 	return &f, nil
 }
 
+// --- Airport Operations ---
+
+const airportSelect = `
+	SELECT iata_code, icao_code, name, city, country, time_zone, latitude, longitude
+	FROM airports
+`
+
+// likeEscaper escapes the wildcards of a LIKE pattern matched with ESCAPE '\'
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// SearchAirports returns airports whose code, city or name matches the query,
+// or all airports when the query is empty
+func (r *Repository) SearchAirports(ctx context.Context, query string) ([]Airport, error) {
+	sql := airportSelect + `
+		WHERE $1 = ''
+		   OR iata_code = UPPER($1) OR icao_code = UPPER($1)
+		   OR city ILIKE '%' || $2 || '%' ESCAPE '\' OR name ILIKE '%' || $2 || '%' ESCAPE '\'
+		ORDER BY iata_code
+	`
+
+	rows, err := r.pool.Query(ctx, sql, query, likeEscaper.Replace(query))
+	if err != nil {
+		return nil, fmt.Errorf("failed to query airports: %w", err)
+	}
+	defer rows.Close()
+
+	airports := []Airport{}
+	for rows.Next() {
+		var a Airport
+		err := rows.Scan(&a.IATACode, &a.ICAOCode, &a.Name, &a.City, &a.Country, &a.TimeZone, &a.Latitude, &a.Longitude)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan airport: %w", err)
+		}
+		airports = append(airports, a)
+	}
+
+	return airports, rows.Err()
+}
+
+// GetAirportByCode returns an airport by its IATA or ICAO code
+func (r *Repository) GetAirportByCode(ctx context.Context, code string) (*Airport, error) {
+	query := airportSelect + `
+		WHERE iata_code = UPPER($1) OR icao_code = UPPER($1)
+	`
+
+	var a Airport
+	err := r.pool.QueryRow(ctx, query, code).Scan(
+		&a.IATACode, &a.ICAOCode, &a.Name, &a.City, &a.Country, &a.TimeZone, &a.Latitude, &a.Longitude,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("failed to get airport: %w", err)
+	}
+
+	return &a, nil
+}
+
 // --- Seat Operations ---
 
+// seatSurcharge is the sum of the surcharges of the attributes of seat s
+const seatSurcharge = `
+	(SELECT COALESCE(SUM(surcharge), 0) FROM seat_attribute_surcharges WHERE attribute = ANY(s.attributes))`
+
+// seatSelect selects seats (aliased s) with their surcharge; rows are read
+// by scanSeat
+const seatSelect = `
+	SELECT s.id, s.flight_id, s.seat_number, s.row_number, s.column_letter, s.class, s.attributes,
+	       s.status, s.price, ` + seatSurcharge + `,
+	       s.held_until, s.held_by_order, s.created_at, s.updated_at
+	FROM seats s
+`
+
+func scanSeat(row pgx.Row) (Seat, error) {
+	var s Seat
+	err := row.Scan(
+		&s.ID, &s.FlightID, &s.SeatNumber, &s.RowNumber, &s.ColumnLetter,
+		&s.Class, &s.Attributes, &s.Status, &s.Price, &s.Surcharge, &s.HeldUntil, &s.HeldByOrder,
+		&s.CreatedAt, &s.UpdatedAt,
+	)
+	return s, err
+}
+
 // GetFlightSeats returns all seats for a flight
 func (r *Repository) GetFlightSeats(ctx context.Context, flightID uuid.UUID) ([]Seat, error) {
-	// First release any expired holds
-	_, _ = r.pool.Exec(ctx, "SELECT release_expired_holds()")
-
-	query := `
-		SELECT id, flight_id, seat_number, row_number, column_letter, class,
-		       status, price, held_until, held_by_order, created_at, updated_at
-		FROM seats
-		WHERE flight_id = $1
-		ORDER BY row_number, column_letter
+	query := seatSelect + `
+		WHERE s.flight_id = $1
+		ORDER BY s.row_number, s.column_letter
 	`
 
 	rows, err := r.pool.Query(ctx, query, flightID)
@@ -110,12 +215,7 @@ func (r *Repository) GetFlightSeats(ctx context.Context, flightID uuid.UUID) ([]
 
 	var seats []Seat
 	for rows.Next() {
-		var s Seat
-		err := rows.Scan(
-			&s.ID, &s.FlightID, &s.SeatNumber, &s.RowNumber, &s.ColumnLetter,
-			&s.Class, &s.Status, &s.Price, &s.HeldUntil, &s.HeldByOrder,
-			&s.CreatedAt, &s.UpdatedAt,
-		)
+		s, err := scanSeat(rows)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan seat: %w", err)
 		}
@@ -127,19 +227,11 @@ func (r *Repository) GetFlightSeats(ctx context.Context, flightID uuid.UUID) ([]
 
 // GetSeatByID returns a seat by ID
 func (r *Repository) GetSeatByID(ctx context.Context, id uuid.UUID) (*Seat, error) {
-	query := `
-		SELECT id, flight_id, seat_number, row_number, column_letter, class,
-		       status, price, held_until, held_by_order, created_at, updated_at
-		FROM seats
-		WHERE id = $1
+	query := seatSelect + `
+		WHERE s.id = $1
 	`
 
-	var s Seat
-	err := r.pool.QueryRow(ctx, query, id).Scan(
-		&s.ID, &s.FlightID, &s.SeatNumber, &s.RowNumber, &s.ColumnLetter,
-		&s.Class, &s.Status, &s.Price, &s.HeldUntil, &s.HeldByOrder,
-		&s.CreatedAt, &s.UpdatedAt,
-	)
+	s, err := scanSeat(r.pool.QueryRow(ctx, query, id))
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
 			return nil, ErrNotFound
@@ -150,7 +242,66 @@ func (r *Repository) GetSeatByID(ctx context.Context, id uuid.UUID) (*Seat, erro
 	return &s, nil
 }
 
-// HoldSeats holds seats for an order with a 15-minute timer
+// SeatsUnavailableError is returned by HoldSeats when some of the requested
+// seats cannot be held. It matches ErrSeatNotAvailable with errors.Is.
+type SeatsUnavailableError struct {
+	// SeatIDs are the requested seats that are held or booked by another
+	// order, or that do not exist on the order's flights
+	SeatIDs []uuid.UUID
+}
+
+func (e *SeatsUnavailableError) Error() string {
+	return fmt.Sprintf("%s: %d of the requested seats", ErrSeatNotAvailable, len(e.SeatIDs))
+}
+
+func (e *SeatsUnavailableError) Unwrap() error {
+	return ErrSeatNotAvailable
+}
+
+// holdSeatsQuery holds the requested seats ($1) for an order ($2) until $3
+// and releases the other seats the order held, in a single statement.
+//
+// The locked CTE locks every seat involved in ID order, so overlapping
+// selections always lock in the same order and wait for each other instead
+// of deadlocking. Seats are only updated when none of the requested seats is
+// unavailable; the unavailable ones are returned. Blocked seats are
+// unavailable until their block expires; an expired block is lifted when the
+// seat is held.
+const holdSeatsQuery = `
+	WITH locked AS (
+		SELECT id, flight_id, held_by_order,
+		       CASE WHEN status = 'blocked' AND blocked_until <= NOW() THEN 'available' ELSE status END AS status
+		FROM seats
+		WHERE id = ANY($1) OR held_by_order = $2
+		ORDER BY id
+		FOR UPDATE
+	),
+	unavailable AS (
+		SELECT r.id
+		FROM unnest($1::uuid[]) AS r(id)
+		LEFT JOIN locked l ON l.id = r.id
+		WHERE l.id IS NULL
+		   OR NOT (l.status = 'available' OR l.held_by_order = $2)
+		   OR l.flight_id NOT IN (SELECT flight_id FROM order_segments WHERE order_id = $2)
+	),
+	held AS (
+		UPDATE seats s
+		SET status = CASE WHEN s.id = ANY($1) THEN 'held' ELSE 'available' END,
+		    held_until = CASE WHEN s.id = ANY($1) THEN $3::timestamptz END,
+		    held_by_order = CASE WHEN s.id = ANY($1) THEN $2::uuid END,
+		    block_reason = NULL, block_note = NULL, blocked_by = NULL, blocked_at = NULL, blocked_until = NULL
+		FROM locked l
+		WHERE s.id = l.id AND NOT EXISTS (SELECT 1 FROM unavailable)
+		RETURNING s.id
+	)
+	SELECT id FROM unavailable ORDER BY id
+`
+
+// HoldSeats holds seats for an order with a 15-minute timer, replacing the
+// seats it held before. Seats on every segment of the order are held
+// together: if any seat is unavailable none are held and a
+// *SeatsUnavailableError lists them. ErrOrderNotModifiable is returned once
+// the order is no longer being booked.
 func (r *Repository) HoldSeats(ctx context.Context, orderID uuid.UUID, seatIDs []uuid.UUID) error {
 	tx, err := r.pool.Begin(ctx)
 	if err != nil {
@@ -158,31 +309,33 @@ func (r *Repository) HoldSeats(ctx context.Context, orderID uuid.UUID, seatIDs [
 	}
 	defer tx.Rollback(ctx)
 
+	// Lock the order so seats are only held for orders still being booked
+	var status OrderStatus
+	err = tx.QueryRow(ctx, `SELECT status FROM orders WHERE id = $1 FOR UPDATE`, orderID).Scan(&status)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return ErrNotFound
+		}
+		return fmt.Errorf("failed to get order: %w", err)
+	}
+	switch status {
+	case OrderStatusPending, OrderStatusSeatsSelected, OrderStatusAwaitingPayment:
+	default:
+		return fmt.Errorf("%w: order is %s", ErrOrderNotModifiable, status)
+	}
+
 	holdUntil := time.Now().Add(15 * time.Minute)
 
-	// First, release any seats previously held by this order
-	_, err = tx.Exec(ctx, `
-		UPDATE seats
-		SET status = 'available', held_until = NULL, held_by_order = NULL
-		WHERE held_by_order = $1
-	`, orderID)
+	rows, err := tx.Query(ctx, holdSeatsQuery, seatIDs, orderID, holdUntil)
 	if err != nil {
-		return fmt.Errorf("failed to release previous holds: %w", err)
+		return fmt.Errorf("failed to hold seats: %w", err)
 	}
-
-	// Hold new seats
-	for _, seatID := range seatIDs {
-		result, err := tx.Exec(ctx, `
-			UPDATE seats
-			SET status = 'held', held_until = $1, held_by_order = $2
-			WHERE id = $3 AND (status = 'available' OR held_by_order = $2)
-		`, holdUntil, orderID, seatID)
-		if err != nil {
-			return fmt.Errorf("failed to hold seat: %w", err)
-		}
-		if result.RowsAffected() == 0 {
-			return ErrSeatNotAvailable
-		}
+	unavailable, err := pgx.CollectRows(rows, pgx.RowTo[uuid.UUID])
+	if err != nil {
+		return fmt.Errorf("failed to hold seats: %w", err)
+	}
+	if len(unavailable) > 0 {
+		return &SeatsUnavailableError{SeatIDs: unavailable}
 	}
 
 	// Update order with new expiration time
@@ -223,7 +376,7 @@ func (r *Repository) BookSeats(ctx context.Context, orderID uuid.UUID) error {
 			SELECT COUNT(*) FROM seats s
 			WHERE s.flight_id = f.id AND s.status = 'available'
 		)
-		WHERE id = (SELECT flight_id FROM orders WHERE id = $1)
+		WHERE id IN (SELECT flight_id FROM order_segments WHERE order_id = $1)
 	`, orderID)
 	if err != nil {
 		return fmt.Errorf("failed to update available seats: %w", err)
@@ -247,35 +400,55 @@ func (r *Repository) ReleaseSeats(ctx context.Context, orderID uuid.UUID) error
 
 // --- Order Operations ---
 
-// CreateOrder creates a new order
+// CreateOrder creates a new order with its flight segments. An order without
+// segments gets a single segment for order.FlightID.
 func (r *Repository) CreateOrder(ctx context.Context, order *Order) error {
 	query := `
-		INSERT INTO orders (id, flight_id, customer_name, customer_email, status, workflow_id, workflow_run_id)
-		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		INSERT INTO orders (id, flight_id, customer_name, customer_email, status, workflow_id, workflow_run_id, customer_id)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
 		RETURNING created_at, updated_at
 	`
 
 	if order.ID == uuid.Nil {
 		order.ID = uuid.New()
 	}
+	if len(order.Segments) == 0 {
+		order.Segments = []OrderSegment{{FlightID: order.FlightID}}
+	}
 
-	err := r.pool.QueryRow(ctx, query,
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	err = tx.QueryRow(ctx, query,
 		order.ID, order.FlightID, order.CustomerName, order.CustomerEmail,
-		order.Status, order.WorkflowID, order.WorkflowRunID,
+		order.Status, order.WorkflowID, order.WorkflowRunID, order.CustomerID,
 	).Scan(&order.CreatedAt, &order.UpdatedAt)
-
 	if err != nil {
 		return fmt.Errorf("failed to create order: %w", err)
 	}
 
-	return nil
+	for i := range order.Segments {
+		order.Segments[i].SegmentIndex = i
+		_, err = tx.Exec(ctx, `
+			INSERT INTO order_segments (order_id, flight_id, segment_index)
+			VALUES ($1, $2, $3)
+		`, order.ID, order.Segments[i].FlightID, i)
+		if err != nil {
+			return fmt.Errorf("failed to create order segment: %w", err)
+		}
+	}
+
+	return tx.Commit(ctx)
 }
 
 // GetOrderByID returns an order by ID with its seats
 func (r *Repository) GetOrderByID(ctx context.Context, id uuid.UUID) (*Order, error) {
 	query := `
-		SELECT id, flight_id, customer_name, customer_email, status, total_amount,
-		       payment_attempts, failure_reason, workflow_id, workflow_run_id,
+		SELECT id, booking_reference, customer_id, flight_id, customer_name, customer_email, status, total_amount,
+		       refunded_amount, payment_attempts, failure_reason, workflow_id, workflow_run_id,
 		       reservation_expires_at, created_at, updated_at
 		FROM orders
 		WHERE id = $1
@@ -283,8 +456,8 @@ func (r *Repository) GetOrderByID(ctx context.Context, id uuid.UUID) (*Order, er
 
 	var o Order
 	err := r.pool.QueryRow(ctx, query, id).Scan(
-		&o.ID, &o.FlightID, &o.CustomerName, &o.CustomerEmail, &o.Status,
-		&o.TotalAmount, &o.PaymentAttempts, &o.FailureReason, &o.WorkflowID,
+		&o.ID, &o.BookingReference, &o.CustomerID, &o.FlightID, &o.CustomerName, &o.CustomerEmail, &o.Status,
+		&o.TotalAmount, &o.RefundedAmount, &o.PaymentAttempts, &o.FailureReason, &o.WorkflowID,
 		&o.WorkflowRunID, &o.ReservationExpiresAt, &o.CreatedAt, &o.UpdatedAt,
 	)
 	if err != nil {
@@ -294,12 +467,36 @@ func (r *Repository) GetOrderByID(ctx context.Context, id uuid.UUID) (*Order, er
 		return nil, fmt.Errorf("failed to get order: %w", err)
 	}
 
+	// Get flight segments
+	segmentRows, err := r.pool.Query(ctx, `
+		SELECT flight_id, segment_index
+		FROM order_segments
+		WHERE order_id = $1
+		ORDER BY segment_index
+	`, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query order segments: %w", err)
+	}
+	defer segmentRows.Close()
+
+	segmentByFlight := make(map[uuid.UUID]int)
+	for segmentRows.Next() {
+		var seg OrderSegment
+		if err := segmentRows.Scan(&seg.FlightID, &seg.SegmentIndex); err != nil {
+			return nil, fmt.Errorf("failed to scan order segment: %w", err)
+		}
+		segmentByFlight[seg.FlightID] = len(o.Segments)
+		o.Segments = append(o.Segments, seg)
+	}
+	segmentRows.Close()
+
 	// Get associated seats
 	seatQuery := `
-		SELECT s.seat_number
+		SELECT s.flight_id, s.seat_number
 		FROM order_seats os
 		JOIN seats s ON s.id = os.seat_id
 		WHERE os.order_id = $1
+		ORDER BY s.row_number, s.column_letter
 	`
 	rows, err := r.pool.Query(ctx, seatQuery, id)
 	if err != nil {
@@ -308,16 +505,55 @@ func (r *Repository) GetOrderByID(ctx context.Context, id uuid.UUID) (*Order, er
 	defer rows.Close()
 
 	for rows.Next() {
+		var flightID uuid.UUID
 		var seatNumber string
-		if err := rows.Scan(&seatNumber); err != nil {
+		if err := rows.Scan(&flightID, &seatNumber); err != nil {
 			return nil, fmt.Errorf("failed to scan seat number: %w", err)
 		}
 		o.Seats = append(o.Seats, seatNumber)
+		if i, ok := segmentByFlight[flightID]; ok {
+			o.Segments[i].Seats = append(o.Segments[i].Seats, seatNumber)
+		}
+	}
+	rows.Close()
+
+	o.OverbookedSeats, err = r.GetOrderOverbookedSeats(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	o.SeatExchanges, err = r.GetOrderSeatExchanges(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	o.FlightExchanges, err = r.GetOrderFlightExchanges(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	o.Refunds, err = r.GetOrderRefunds(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	o.Passengers, err = r.GetOrderPassengers(ctx, id)
+	if err != nil {
+		return nil, err
 	}
 
 	return &o, nil
 }
 
+// GetOrderByBookingReference returns the order with a booking reference
+func (r *Repository) GetOrderByBookingReference(ctx context.Context, reference string) (*Order, error) {
+	var id uuid.UUID
+	err := r.pool.QueryRow(ctx, `SELECT id FROM orders WHERE booking_reference = $1`, reference).Scan(&id)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get order: %w", err)
+	}
+	return r.GetOrderByID(ctx, id)
+}
+
 // UpdateOrderStatus updates the status of an order
 func (r *Repository) UpdateOrderStatus(ctx context.Context, id uuid.UUID, status OrderStatus) error {
 	_, err := r.pool.Exec(ctx, `
@@ -348,30 +584,36 @@ func (r *Repository) SetOrderSeats(ctx context.Context, orderID uuid.UUID, seatI
 	}
 	defer tx.Rollback(ctx)
 
-	// Clear existing order seats
+	// Clear existing order seats, including travelers booked without one
 	_, err = tx.Exec(ctx, `DELETE FROM order_seats WHERE order_id = $1`, orderID)
 	if err != nil {
 		return fmt.Errorf("failed to clear order seats: %w", err)
 	}
+	_, err = tx.Exec(ctx, `DELETE FROM overbooked_seats WHERE order_id = $1`, orderID)
+	if err != nil {
+		return fmt.Errorf("failed to clear overbooked seats: %w", err)
+	}
 
-	// Add new seats and calculate total
+	// Add new seats and calculate total, in one statement so the order's
+	// history records them as one change. Seats are charged their class
+	// fare plus the surcharges of their attributes.
+	var added int
 	var totalAmount float64
-	for _, seatID := range seatIDs {
-		var price float64
-		err := tx.QueryRow(ctx, `SELECT price FROM seats WHERE id = $1`, seatID).Scan(&price)
-		if err != nil {
-			return fmt.Errorf("failed to get seat price: %w", err)
-		}
-
-		_, err = tx.Exec(ctx, `
+	err = tx.QueryRow(ctx, `
+		WITH added AS (
 			INSERT INTO order_seats (order_id, seat_id, price)
-			VALUES ($1, $2, $3)
-		`, orderID, seatID, price)
-		if err != nil {
-			return fmt.Errorf("failed to add order seat: %w", err)
-		}
-
-		totalAmount += price
+			SELECT $1, s.id, s.price + `+seatSurcharge+`
+			FROM seats s
+			WHERE s.id = ANY($2)
+			RETURNING price
+		)
+		SELECT COUNT(*), COALESCE(SUM(price), 0) FROM added
+	`, orderID, seatIDs).Scan(&added, &totalAmount)
+	if err != nil {
+		return fmt.Errorf("failed to add order seats: %w", err)
+	}
+	if added != len(seatIDs) {
+		return fmt.Errorf("failed to add order seats: %w", ErrNotFound)
 	}
 
 	// Update order total
@@ -429,6 +671,40 @@ func (r *Repository) GetSeatIDsByFlightAndNumbers(ctx context.Context, flightID
 	return ids, nil
 }
 
+// GetSeatsByIDs returns the seats with the given IDs
+func (r *Repository) GetSeatsByIDs(ctx context.Context, ids []uuid.UUID) ([]Seat, error) {
+	query := seatSelect + `
+		WHERE s.id = ANY($1)
+		ORDER BY s.row_number, s.column_letter
+	`
+
+	rows, err := r.pool.Query(ctx, query, ids)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query seats: %w", err)
+	}
+	defer rows.Close()
+
+	var seats []Seat
+	for rows.Next() {
+		s, err := scanSeat(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan seat: %w", err)
+		}
+		seats = append(seats, s)
+	}
+
+	return seats, nil
+}
+
+// GetOrderSeats returns the seats associated with an order
+func (r *Repository) GetOrderSeats(ctx context.Context, orderID uuid.UUID) ([]Seat, error) {
+	ids, err := r.GetOrderSeatIDs(ctx, orderID)
+	if err != nil || len(ids) == 0 {
+		return nil, err
+	}
+	return r.GetSeatsByIDs(ctx, ids)
+}
+
 // GetOrderSeatIDs returns the seat IDs associated with an order
 func (r *Repository) GetOrderSeatIDs(ctx context.Context, orderID uuid.UUID) ([]uuid.UUID, error) {
 	query := `
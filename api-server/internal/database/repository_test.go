@@ -0,0 +1,24 @@
+package database
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLikeEscaper(t *testing.T) {
+	tests := []struct {
+		query string
+		want  string
+	}{
+		{"Tel Aviv", "Tel Aviv"},
+		{"100%", `100\%`},
+		{"JFK_", `JFK\_`},
+		{`C:\`, `C:\\`},
+		{`%_\`, `\%\_\\`},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, likeEscaper.Replace(tt.query), tt.query)
+	}
+}
@@ -0,0 +1,173 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// ErrScheduleOverlap is returned when a schedule would operate a flight
+// number on a day another schedule of it already covers
+var ErrScheduleOverlap = errors.New("schedule overlaps an existing schedule of the flight number")
+
+// FlightSchedule operates a flight number on a weekly pattern. Times are
+// local to the origin and destination airports ("15:04"); dates are
+// "2006-01-02". DaysOfWeek are ISO weekdays, 1 = Monday to 7 = Sunday.
+// The worker materializes its flights a rolling number of days ahead.
+type FlightSchedule struct {
+	ID                 uuid.UUID `json:"id"`
+	FlightNumber       string    `json:"flightNumber"`
+	Origin             string    `json:"origin"`
+	Destination        string    `json:"destination"`
+	DaysOfWeek         []int     `json:"daysOfWeek"`
+	DepartureTimeLocal string    `json:"departureTimeLocal"`
+	ArrivalTimeLocal   string    `json:"arrivalTimeLocal"`
+	ArrivalDayOffset   int       `json:"arrivalDayOffset"`
+	EffectiveFrom      string    `json:"effectiveFrom"`
+	EffectiveTo        *string   `json:"effectiveTo,omitempty"`
+	AircraftType       string    `json:"aircraftType"`
+	CabinLayout        string    `json:"cabinLayout"`
+	PricePerSeat       float64   `json:"pricePerSeat"`
+	SeatCount          int       `json:"seatCount"`
+	CreatedAt          time.Time `json:"createdAt"`
+	UpdatedAt          time.Time `json:"updatedAt"`
+}
+
+const flightScheduleSelect = `
+	SELECT s.id, s.flight_number, s.origin, s.destination, s.days_of_week,
+	       to_char(s.departure_time_local, 'HH24:MI'), to_char(s.arrival_time_local, 'HH24:MI'),
+	       s.arrival_day_offset, to_char(s.effective_from, 'YYYY-MM-DD'), to_char(s.effective_to, 'YYYY-MM-DD'),
+	       s.aircraft_type, s.cabin_layout, s.price_per_seat,
+	       (SELECT COUNT(*) FROM flight_schedule_seats t WHERE t.schedule_id = s.id),
+	       s.created_at, s.updated_at
+	FROM flight_schedules s
+`
+
+func scanFlightSchedule(row pgx.Row) (*FlightSchedule, error) {
+	var s FlightSchedule
+	err := row.Scan(
+		&s.ID, &s.FlightNumber, &s.Origin, &s.Destination, &s.DaysOfWeek,
+		&s.DepartureTimeLocal, &s.ArrivalTimeLocal,
+		&s.ArrivalDayOffset, &s.EffectiveFrom, &s.EffectiveTo,
+		&s.AircraftType, &s.CabinLayout, &s.PricePerSeat,
+		&s.SeatCount,
+		&s.CreatedAt, &s.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+// GetFlightSchedules returns all flight schedules
+func (r *Repository) GetFlightSchedules(ctx context.Context) ([]FlightSchedule, error) {
+	rows, err := r.pool.Query(ctx, flightScheduleSelect+` ORDER BY s.flight_number, s.effective_from`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query flight schedules: %w", err)
+	}
+	defer rows.Close()
+
+	schedules := []FlightSchedule{}
+	for rows.Next() {
+		s, err := scanFlightSchedule(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan flight schedule: %w", err)
+		}
+		schedules = append(schedules, *s)
+	}
+
+	return schedules, rows.Err()
+}
+
+// GetFlightSchedule returns a flight schedule by ID
+func (r *Repository) GetFlightSchedule(ctx context.Context, id uuid.UUID) (*FlightSchedule, error) {
+	s, err := scanFlightSchedule(r.pool.QueryRow(ctx, flightScheduleSelect+` WHERE s.id = $1`, id))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("failed to get flight schedule: %w", err)
+	}
+	return s, nil
+}
+
+// CreateFlightSchedule inserts a schedule together with the seats its
+// flights are created with. It fails with ErrScheduleOverlap if another
+// schedule of the flight number operates on any of the same days.
+func (r *Repository) CreateFlightSchedule(ctx context.Context, s *FlightSchedule, seats []Seat) error {
+	if s.ID == uuid.Nil {
+		s.ID = uuid.New()
+	}
+
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	// Serialize schedule changes per flight number so overlaps cannot slip in
+	if _, err := tx.Exec(ctx, `SELECT pg_advisory_xact_lock(hashtext($1))`, s.FlightNumber); err != nil {
+		return fmt.Errorf("failed to lock flight number: %w", err)
+	}
+
+	var overlapping uuid.UUID
+	err = tx.QueryRow(ctx, `
+		SELECT id FROM flight_schedules
+		WHERE flight_number = $1
+		  AND days_of_week && $2::integer[]
+		  AND daterange(effective_from, effective_to, '[]') && daterange($3::date, $4::date, '[]')
+		LIMIT 1
+	`, s.FlightNumber, s.DaysOfWeek, s.EffectiveFrom, s.EffectiveTo).Scan(&overlapping)
+	if err == nil {
+		return fmt.Errorf("%w: %s", ErrScheduleOverlap, overlapping)
+	}
+	if !errors.Is(err, pgx.ErrNoRows) {
+		return fmt.Errorf("failed to check schedule overlap: %w", err)
+	}
+
+	err = tx.QueryRow(ctx, `
+		INSERT INTO flight_schedules (id, flight_number, origin, destination, days_of_week,
+		                              departure_time_local, arrival_time_local, arrival_day_offset,
+		                              effective_from, effective_to, aircraft_type, cabin_layout, price_per_seat)
+		VALUES ($1, $2, $3, $4, $5, $6::time, $7::time, $8, $9::date, $10::date, $11, $12, $13)
+		RETURNING created_at, updated_at
+	`, s.ID, s.FlightNumber, s.Origin, s.Destination, s.DaysOfWeek,
+		s.DepartureTimeLocal, s.ArrivalTimeLocal, s.ArrivalDayOffset,
+		s.EffectiveFrom, s.EffectiveTo, s.AircraftType, s.CabinLayout, s.PricePerSeat,
+	).Scan(&s.CreatedAt, &s.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create flight schedule: %w", err)
+	}
+
+	_, err = tx.CopyFrom(ctx,
+		pgx.Identifier{"flight_schedule_seats"},
+		[]string{"schedule_id", "seat_number", "row_number", "column_letter", "class", "attributes", "price"},
+		pgx.CopyFromSlice(len(seats), func(i int) ([]interface{}, error) {
+			seat := seats[i]
+			return []interface{}{s.ID, seat.SeatNumber, seat.RowNumber, seat.ColumnLetter, seat.Class, seatAttributes(seat), seat.Price}, nil
+		}),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create schedule seats: %w", err)
+	}
+	s.SeatCount = len(seats)
+
+	return tx.Commit(ctx)
+}
+
+// DeleteFlightSchedule deletes a schedule so no more flights are created from
+// it. Flights it already created are kept.
+func (r *Repository) DeleteFlightSchedule(ctx context.Context, id uuid.UUID) error {
+	tag, err := r.pool.Exec(ctx, `DELETE FROM flight_schedules WHERE id = $1`, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete flight schedule: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
@@ -0,0 +1,55 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// SeatAttributeSurcharge is the amount added to the price of seats with an
+// attribute
+type SeatAttributeSurcharge struct {
+	Attribute string    `json:"attribute"`
+	Surcharge float64   `json:"surcharge"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+// GetSeatAttributeSurcharges returns the surcharge of every seat attribute
+func (r *Repository) GetSeatAttributeSurcharges(ctx context.Context) ([]SeatAttributeSurcharge, error) {
+	rows, err := r.pool.Query(ctx, `
+		SELECT attribute, surcharge, updated_at
+		FROM seat_attribute_surcharges
+		ORDER BY attribute
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query seat attribute surcharges: %w", err)
+	}
+	defer rows.Close()
+
+	surcharges := []SeatAttributeSurcharge{}
+	for rows.Next() {
+		var s SeatAttributeSurcharge
+		if err := rows.Scan(&s.Attribute, &s.Surcharge, &s.UpdatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan seat attribute surcharge: %w", err)
+		}
+		surcharges = append(surcharges, s)
+	}
+
+	return surcharges, rows.Err()
+}
+
+// UpdateSeatAttributeSurcharge sets the surcharge of a seat attribute. It
+// applies to seats selected from then on; orders keep the price they were
+// given.
+func (r *Repository) UpdateSeatAttributeSurcharge(ctx context.Context, s *SeatAttributeSurcharge) error {
+	err := r.pool.QueryRow(ctx, `
+		INSERT INTO seat_attribute_surcharges (attribute, surcharge)
+		VALUES ($1, $2)
+		ON CONFLICT (attribute) DO UPDATE SET surcharge = EXCLUDED.surcharge
+		RETURNING updated_at
+	`, s.Attribute, s.Surcharge).Scan(&s.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to update seat attribute surcharge: %w", err)
+	}
+	return nil
+}
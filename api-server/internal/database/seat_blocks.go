@@ -0,0 +1,167 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// SeatBlockReason says why a seat was taken out of sale
+type SeatBlockReason string
+
+const (
+	SeatBlockCrew        SeatBlockReason = "crew"
+	SeatBlockMaintenance SeatBlockReason = "maintenance"
+	SeatBlockOperational SeatBlockReason = "operational"
+)
+
+// SeatBlock is a seat taken out of sale by an admin. A block without
+// BlockedUntil lasts until the seat is unblocked.
+type SeatBlock struct {
+	SeatID       uuid.UUID       `json:"seatId"`
+	FlightID     uuid.UUID       `json:"flightId"`
+	SeatNumber   string          `json:"seatNumber"`
+	Class        string          `json:"class"`
+	Reason       SeatBlockReason `json:"reason"`
+	Note         *string         `json:"note,omitempty"`
+	BlockedBy    string          `json:"blockedBy"`
+	BlockedAt    time.Time       `json:"blockedAt"`
+	BlockedUntil *time.Time      `json:"blockedUntil,omitempty"`
+}
+
+const seatBlockColumns = `
+	s.id, s.flight_id, s.seat_number, s.class, s.block_reason, s.block_note, s.blocked_by, s.blocked_at, s.blocked_until`
+
+func scanSeatBlocks(rows pgx.Rows) ([]SeatBlock, error) {
+	defer rows.Close()
+
+	blocks := []SeatBlock{}
+	for rows.Next() {
+		var b SeatBlock
+		err := rows.Scan(&b.SeatID, &b.FlightID, &b.SeatNumber, &b.Class, &b.Reason, &b.Note, &b.BlockedBy, &b.BlockedAt, &b.BlockedUntil)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan seat block: %w", err)
+		}
+		blocks = append(blocks, b)
+	}
+	return blocks, rows.Err()
+}
+
+// GetSeatBlocks returns the blocked seats of a flight
+func (r *Repository) GetSeatBlocks(ctx context.Context, flightID uuid.UUID) ([]SeatBlock, error) {
+	rows, err := r.pool.Query(ctx, `
+		SELECT `+seatBlockColumns+`
+		FROM seats s
+		WHERE s.flight_id = $1 AND s.status = 'blocked'
+		ORDER BY s.row_number, s.column_letter
+	`, flightID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query seat blocks: %w", err)
+	}
+	return scanSeatBlocks(rows)
+}
+
+// BlockSeats takes seats of a flight out of sale. The seats are locked in ID
+// order, like HoldSeats, and blocked only if none of them is held or booked;
+// otherwise a SeatsUnavailableError lists the ones that are. Seats already
+// blocked get the new block. The flight's available seat count is updated.
+func (r *Repository) BlockSeats(ctx context.Context, flightID uuid.UUID, seatIDs []uuid.UUID, block SeatBlock) ([]SeatBlock, error) {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	rows, err := tx.Query(ctx, `
+		SELECT id FROM (
+			SELECT id, flight_id, status FROM seats
+			WHERE id = ANY($1)
+			ORDER BY id
+			FOR UPDATE
+		) l
+		WHERE l.flight_id <> $2 OR l.status NOT IN ('available', 'blocked')
+		ORDER BY id
+	`, seatIDs, flightID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to lock seats: %w", err)
+	}
+	unavailable, err := pgx.CollectRows(rows, pgx.RowTo[uuid.UUID])
+	if err != nil {
+		return nil, fmt.Errorf("failed to lock seats: %w", err)
+	}
+	if len(unavailable) > 0 {
+		return nil, &SeatsUnavailableError{SeatIDs: unavailable}
+	}
+
+	rows, err = tx.Query(ctx, `
+		UPDATE seats s
+		SET status = 'blocked', block_reason = $3, block_note = $4, blocked_by = $5,
+		    blocked_at = NOW(), blocked_until = $6
+		WHERE s.id = ANY($1) AND s.flight_id = $2
+		RETURNING `+seatBlockColumns,
+		seatIDs, flightID, block.Reason, block.Note, block.BlockedBy, block.BlockedUntil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to block seats: %w", err)
+	}
+	blocks, err := scanSeatBlocks(rows)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := updateAvailableSeats(ctx, tx, flightID); err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit seat blocks: %w", err)
+	}
+	return blocks, nil
+}
+
+// UnblockSeats puts blocked seats of a flight back on sale and returns the
+// ones that were blocked. Seats that are not blocked are left as they are.
+func (r *Repository) UnblockSeats(ctx context.Context, flightID uuid.UUID, seatIDs []uuid.UUID) ([]uuid.UUID, error) {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	rows, err := tx.Query(ctx, `
+		UPDATE seats
+		SET status = 'available', block_reason = NULL, block_note = NULL, blocked_by = NULL,
+		    blocked_at = NULL, blocked_until = NULL
+		WHERE id = ANY($1) AND flight_id = $2 AND status = 'blocked'
+		RETURNING id
+	`, seatIDs, flightID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unblock seats: %w", err)
+	}
+	unblocked, err := pgx.CollectRows(rows, pgx.RowTo[uuid.UUID])
+	if err != nil {
+		return nil, fmt.Errorf("failed to unblock seats: %w", err)
+	}
+
+	if err := updateAvailableSeats(ctx, tx, flightID); err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit seat unblocks: %w", err)
+	}
+	return unblocked, nil
+}
+
+// updateAvailableSeats recounts the available seats of a flight
+func updateAvailableSeats(ctx context.Context, tx pgx.Tx, flightID uuid.UUID) error {
+	_, err := tx.Exec(ctx, `
+		UPDATE flights f
+		SET available_seats = (SELECT COUNT(*) FROM seats s WHERE s.flight_id = f.id AND s.status = 'available')
+		WHERE f.id = $1
+	`, flightID)
+	if err != nil {
+		return fmt.Errorf("failed to update available seats: %w", err)
+	}
+	return nil
+}
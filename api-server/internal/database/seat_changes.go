@@ -0,0 +1,255 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// ErrOrderNotModifiable is returned when seats are changed on an order that
+// is being paid for or has finished
+var ErrOrderNotModifiable = errors.New("order seats can no longer be changed")
+
+// SeatChangeStatus is the outcome of adding a seat to or removing a seat from
+// an order
+type SeatChangeStatus string
+
+const (
+	// SeatChangeHeld means the seat was added and is now held by the order
+	SeatChangeHeld SeatChangeStatus = "held"
+	// SeatChangeReleased means the seat was removed and is available again
+	SeatChangeReleased SeatChangeStatus = "released"
+	// SeatChangeUnchanged means the seat was added but the order already held it
+	SeatChangeUnchanged SeatChangeStatus = "unchanged"
+	// SeatChangeUnavailable means the seat was added but is taken
+	SeatChangeUnavailable SeatChangeStatus = "unavailable"
+	// SeatChangeBlocked means the seat was added but is out of sale
+	SeatChangeBlocked SeatChangeStatus = "blocked"
+	// SeatChangeNotHeld means the seat was removed but the order did not hold it
+	SeatChangeNotHeld SeatChangeStatus = "not_held"
+	// SeatChangeWrongFlight means the seat was added but is not on a flight of the order
+	SeatChangeWrongFlight SeatChangeStatus = "wrong_flight"
+	// SeatChangeNotFound means the seat does not exist
+	SeatChangeNotFound SeatChangeStatus = "not_found"
+)
+
+// SeatChange is the result of adding or removing one seat
+type SeatChange struct {
+	SeatID     uuid.UUID        `json:"seatId"`
+	FlightID   uuid.UUID        `json:"flightId"`
+	SeatNumber string           `json:"seatNumber,omitempty"`
+	Operation  string           `json:"operation"` // "add" or "remove"
+	Status     SeatChangeStatus `json:"status"`
+}
+
+// Changed reports whether the seat was held or released
+func (c SeatChange) Changed() bool {
+	return c.Status == SeatChangeHeld || c.Status == SeatChangeReleased
+}
+
+// lockedSeat is the state of a seat locked for a seat change
+type lockedSeat struct {
+	ID          uuid.UUID
+	FlightID    uuid.UUID
+	SeatNumber  string
+	Status      SeatStatus
+	HeldByOrder *uuid.UUID
+}
+
+// ChangeOrderSeats adds seats to and removes seats from an order without
+// touching its other seats. Each seat succeeds or fails on its own: added
+// seats are held if they are available, removed seats are released if the
+// order held them. New holds expire with the order's existing reservation, or
+// in 15 minutes if it has none. The order's total is recalculated; an order
+// left without seats goes back to pending.
+func (r *Repository) ChangeOrderSeats(ctx context.Context, orderID uuid.UUID, add, remove []uuid.UUID) ([]SeatChange, error) {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	// Lock the order so concurrent changes to it are applied one at a time
+	var status OrderStatus
+	var expiresAt *time.Time
+	err = tx.QueryRow(ctx, `
+		SELECT status, reservation_expires_at FROM orders WHERE id = $1 FOR UPDATE
+	`, orderID).Scan(&status, &expiresAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("failed to get order: %w", err)
+	}
+	switch status {
+	case OrderStatusPending, OrderStatusSeatsSelected, OrderStatusAwaitingPayment:
+	default:
+		return nil, fmt.Errorf("%w: order is %s", ErrOrderNotModifiable, status)
+	}
+
+	flights := make(map[uuid.UUID]bool)
+	rows, err := tx.Query(ctx, `SELECT flight_id FROM order_segments WHERE order_id = $1`, orderID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query order segments: %w", err)
+	}
+	flightIDs, err := pgx.CollectRows(rows, pgx.RowTo[uuid.UUID])
+	if err != nil {
+		return nil, fmt.Errorf("failed to query order segments: %w", err)
+	}
+	for _, id := range flightIDs {
+		flights[id] = true
+	}
+
+	// Lock the seats in ID order, like HoldSeats, so overlapping changes and
+	// selections cannot deadlock
+	rows, err = tx.Query(ctx, `
+		SELECT id, flight_id, seat_number,
+		       CASE WHEN status = 'blocked' AND blocked_until <= NOW() THEN 'available' ELSE status END,
+		       held_by_order
+		FROM seats
+		WHERE id = ANY($1) OR id = ANY($2)
+		ORDER BY id
+		FOR UPDATE
+	`, add, remove)
+	if err != nil {
+		return nil, fmt.Errorf("failed to lock seats: %w", err)
+	}
+	locked, err := pgx.CollectRows(rows, pgx.RowToStructByPos[lockedSeat])
+	if err != nil {
+		return nil, fmt.Errorf("failed to lock seats: %w", err)
+	}
+
+	changes := decideSeatChanges(orderID, flights, locked, add, remove)
+
+	var hold, release []uuid.UUID
+	for _, c := range changes {
+		switch c.Status {
+		case SeatChangeHeld:
+			hold = append(hold, c.SeatID)
+		case SeatChangeReleased:
+			release = append(release, c.SeatID)
+		}
+	}
+
+	holdUntil := time.Now().Add(15 * time.Minute)
+	if expiresAt != nil && expiresAt.After(time.Now()) {
+		holdUntil = *expiresAt
+	}
+
+	if len(release) > 0 {
+		_, err = tx.Exec(ctx, `
+			UPDATE seats
+			SET status = 'available', held_until = NULL, held_by_order = NULL
+			WHERE id = ANY($1)
+		`, release)
+		if err != nil {
+			return nil, fmt.Errorf("failed to release seats: %w", err)
+		}
+		_, err = tx.Exec(ctx, `
+			DELETE FROM order_seats WHERE order_id = $1 AND seat_id = ANY($2)
+		`, orderID, release)
+		if err != nil {
+			return nil, fmt.Errorf("failed to remove order seats: %w", err)
+		}
+	}
+
+	if len(hold) > 0 {
+		_, err = tx.Exec(ctx, `
+			UPDATE seats
+			SET status = 'held', held_until = $2, held_by_order = $3,
+			    block_reason = NULL, block_note = NULL, blocked_by = NULL, blocked_at = NULL, blocked_until = NULL
+			WHERE id = ANY($1)
+		`, hold, holdUntil, orderID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to hold seats: %w", err)
+		}
+		_, err = tx.Exec(ctx, `
+			INSERT INTO order_seats (order_id, seat_id, price)
+			SELECT $1, s.id, s.price + `+seatSurcharge+`
+			FROM seats s
+			WHERE s.id = ANY($2)
+		`, orderID, hold)
+		if err != nil {
+			return nil, fmt.Errorf("failed to add order seats: %w", err)
+		}
+	}
+
+	_, err = tx.Exec(ctx, `
+		UPDATE orders o
+		SET total_amount = t.total,
+		    status = CASE WHEN t.seats > 0 THEN 'seats_selected' ELSE 'pending' END,
+		    reservation_expires_at = CASE WHEN t.seats > 0 THEN $2::timestamptz END
+		FROM (
+			SELECT COUNT(*) AS seats, COALESCE(SUM(price), 0) AS total
+			FROM order_seats WHERE order_id = $1
+		) t
+		WHERE o.id = $1
+	`, orderID, holdUntil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update order: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit seat changes: %w", err)
+	}
+	return changes, nil
+}
+
+// decideSeatChanges works out the outcome of every added and removed seat,
+// given the locked state of the seats and the flights of the order
+func decideSeatChanges(orderID uuid.UUID, flights map[uuid.UUID]bool, locked []lockedSeat, add, remove []uuid.UUID) []SeatChange {
+	seats := make(map[uuid.UUID]lockedSeat, len(locked))
+	for _, s := range locked {
+		seats[s.ID] = s
+	}
+	heldByOrder := func(s lockedSeat) bool {
+		return s.Status == SeatStatusHeld && s.HeldByOrder != nil && *s.HeldByOrder == orderID
+	}
+
+	changes := make([]SeatChange, 0, len(add)+len(remove))
+	seen := make(map[uuid.UUID]bool, len(add)+len(remove))
+	result := func(id uuid.UUID, op string, decide func(s lockedSeat) SeatChangeStatus) {
+		if seen[id] {
+			return
+		}
+		seen[id] = true
+		s, ok := seats[id]
+		if !ok {
+			changes = append(changes, SeatChange{SeatID: id, Operation: op, Status: SeatChangeNotFound})
+			return
+		}
+		changes = append(changes, SeatChange{
+			SeatID: id, FlightID: s.FlightID, SeatNumber: s.SeatNumber, Operation: op, Status: decide(s),
+		})
+	}
+
+	for _, id := range remove {
+		result(id, "remove", func(s lockedSeat) SeatChangeStatus {
+			if heldByOrder(s) {
+				return SeatChangeReleased
+			}
+			return SeatChangeNotHeld
+		})
+	}
+	for _, id := range add {
+		result(id, "add", func(s lockedSeat) SeatChangeStatus {
+			switch {
+			case !flights[s.FlightID]:
+				return SeatChangeWrongFlight
+			case heldByOrder(s):
+				return SeatChangeUnchanged
+			case s.Status == SeatStatusAvailable:
+				return SeatChangeHeld
+			case s.Status == SeatStatusBlocked:
+				return SeatChangeBlocked
+			default:
+				return SeatChangeUnavailable
+			}
+		})
+	}
+	return changes
+}
@@ -0,0 +1,54 @@
+package database
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDecideSeatChanges(t *testing.T) {
+	orderID := uuid.New()
+	otherOrder := uuid.New()
+	flightID := uuid.New()
+	otherFlight := uuid.New()
+
+	seat := func(number string, flight uuid.UUID, status SeatStatus, heldBy *uuid.UUID) lockedSeat {
+		return lockedSeat{ID: uuid.New(), FlightID: flight, SeatNumber: number, Status: status, HeldByOrder: heldBy}
+	}
+	free := seat("1A", flightID, SeatStatusAvailable, nil)
+	mine := seat("1B", flightID, SeatStatusHeld, &orderID)
+	theirs := seat("1C", flightID, SeatStatusHeld, &otherOrder)
+	booked := seat("1D", flightID, SeatStatusBooked, nil)
+	blocked := seat("1E", flightID, SeatStatusBlocked, nil)
+	elsewhere := seat("1A", otherFlight, SeatStatusAvailable, nil)
+	mineToRemove := seat("2A", flightID, SeatStatusHeld, &orderID)
+	missing := uuid.New()
+
+	changes := decideSeatChanges(orderID, map[uuid.UUID]bool{flightID: true},
+		[]lockedSeat{free, mine, theirs, booked, blocked, elsewhere, mineToRemove},
+		[]uuid.UUID{free.ID, mine.ID, theirs.ID, booked.ID, blocked.ID, elsewhere.ID, missing, free.ID},
+		[]uuid.UUID{mineToRemove.ID, theirs.ID},
+	)
+
+	got := make(map[uuid.UUID]SeatChangeStatus)
+	for _, c := range changes {
+		got[c.SeatID] = c.Status
+	}
+	assert.Len(t, changes, 8, "each seat is reported once")
+	assert.Equal(t, map[uuid.UUID]SeatChangeStatus{
+		mineToRemove.ID: SeatChangeReleased,
+		theirs.ID:       SeatChangeNotHeld,
+		free.ID:         SeatChangeHeld,
+		mine.ID:         SeatChangeUnchanged,
+		booked.ID:       SeatChangeUnavailable,
+		blocked.ID:      SeatChangeBlocked,
+		elsewhere.ID:    SeatChangeWrongFlight,
+		missing:         SeatChangeNotFound,
+	}, got)
+
+	assert.Equal(t, "remove", changes[0].Operation)
+	assert.Equal(t, "2A", changes[0].SeatNumber)
+	assert.True(t, changes[0].Changed())
+	assert.False(t, changes[1].Changed())
+}
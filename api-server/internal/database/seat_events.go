@@ -0,0 +1,72 @@
+package database
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+)
+
+// SeatEventsChannel is the PostgreSQL notification channel the worker
+// publishes seat changes on, e.g. holds released by the hold reaper or
+// seats offered to the waitlist
+const SeatEventsChannel = "seat_events"
+
+const (
+	// SeatEventSeatsReleased is published when seats become available again
+	SeatEventSeatsReleased = "seats_released"
+	// SeatEventSeatsHeld is published when the worker holds seats for an
+	// order, e.g. seats offered to a waitlisted customer
+	SeatEventSeatsHeld = "seats_held"
+	// SeatEventSeatsBooked is published when the worker books seats for an
+	// order, e.g. the new seats of a seat change
+	SeatEventSeatsBooked = "seats_booked"
+)
+
+// SeatEvent is a seat change published by the worker, for the seats of one
+// order on one flight
+type SeatEvent struct {
+	Type     string   `json:"type"`
+	FlightID string   `json:"flightId"`
+	OrderID  string   `json:"orderId,omitempty"`
+	SeatIDs  []string `json:"seatIds"`
+}
+
+// ListenSeatEvents calls handle for every seat event published on
+// SeatEventsChannel. It blocks until ctx is done or the connection fails.
+func (r *Repository) ListenSeatEvents(ctx context.Context, handle func(SeatEvent)) error {
+	pooled, err := r.pool.Acquire(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to acquire connection: %w", err)
+	}
+	// The connection keeps listening, so it is not returned to the pool
+	conn := pooled.Hijack()
+	defer conn.Close(context.Background())
+
+	if _, err := conn.Exec(ctx, "LISTEN "+SeatEventsChannel); err != nil {
+		return fmt.Errorf("failed to listen for seat events: %w", err)
+	}
+
+	for {
+		n, err := conn.WaitForNotification(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to wait for seat events: %w", err)
+		}
+		event, err := parseSeatEvent(n.Payload)
+		if err != nil {
+			fmt.Printf("Warning: ignoring seat event: %v\n", err)
+			continue
+		}
+		handle(event)
+	}
+}
+
+func parseSeatEvent(payload string) (SeatEvent, error) {
+	var event SeatEvent
+	if err := json.Unmarshal([]byte(payload), &event); err != nil {
+		return event, fmt.Errorf("invalid payload %q: %w", payload, err)
+	}
+	if event.Type == "" || event.FlightID == "" {
+		return event, fmt.Errorf("incomplete payload %q", payload)
+	}
+	return event, nil
+}
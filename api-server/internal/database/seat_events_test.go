@@ -0,0 +1,20 @@
+package database
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseSeatEvent(t *testing.T) {
+	event, err := parseSeatEvent(`{"type":"seats_released","flightId":"f1","orderId":"o1","seatIds":["s1","s2"]}`)
+	require.NoError(t, err)
+	assert.Equal(t, SeatEvent{Type: SeatEventSeatsReleased, FlightID: "f1", OrderID: "o1", SeatIDs: []string{"s1", "s2"}}, event)
+
+	_, err = parseSeatEvent(`not json`)
+	assert.Error(t, err)
+
+	_, err = parseSeatEvent(`{"type":"seats_released"}`)
+	assert.Error(t, err, "missing flight")
+}
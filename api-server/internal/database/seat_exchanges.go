@@ -0,0 +1,287 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// ErrPaymentRequired is returned when a seat change costs more than the seats
+// it replaces and no payment was offered for the difference
+var ErrPaymentRequired = errors.New("payment required")
+
+// SeatExchangeStatus is the state of a seat change of a confirmed order
+type SeatExchangeStatus string
+
+const (
+	// SeatExchangePending means the new seats are held while the fare
+	// difference is settled
+	SeatExchangePending SeatExchangeStatus = "pending"
+	// SeatExchangeCompleted means the order holds the new seats
+	SeatExchangeCompleted SeatExchangeStatus = "completed"
+	// SeatExchangeFailed means the order kept its old seats
+	SeatExchangeFailed SeatExchangeStatus = "failed"
+)
+
+// SeatExchange is a seat change of a confirmed order: each old seat is
+// swapped for the new seat at the same position. A positive PriceDifference
+// is charged (TransactionID), a negative one credited (CreditID).
+type SeatExchange struct {
+	ID              uuid.UUID          `json:"id"`
+	OrderID         uuid.UUID          `json:"orderId"`
+	OldSeatIDs      []uuid.UUID        `json:"oldSeatIds"`
+	NewSeatIDs      []uuid.UUID        `json:"newSeatIds"`
+	OldSeats        []string           `json:"oldSeats"`
+	NewSeats        []string           `json:"newSeats"`
+	PriceDifference float64            `json:"priceDifference"`
+	Status          SeatExchangeStatus `json:"status"`
+	WorkflowID      string             `json:"workflowId"`
+	TransactionID   *string            `json:"transactionId,omitempty"`
+	CreditID        *string            `json:"creditId,omitempty"`
+	FailureReason   *string            `json:"failureReason,omitempty"`
+	HoldExpiresAt   time.Time          `json:"holdExpiresAt"`
+	CreatedAt       time.Time          `json:"createdAt"`
+	CompletedAt     *time.Time         `json:"completedAt,omitempty"`
+}
+
+// seatExchangeSelect selects seat exchanges (aliased e) with the numbers of
+// their seats, in the order of the seat IDs
+const seatExchangeSelect = `
+	SELECT e.id, e.order_id, e.old_seat_ids, e.new_seat_ids,
+	       ARRAY(SELECT s.seat_number FROM unnest(e.old_seat_ids) WITH ORDINALITY u(id, n)
+	             JOIN seats s ON s.id = u.id ORDER BY u.n),
+	       ARRAY(SELECT s.seat_number FROM unnest(e.new_seat_ids) WITH ORDINALITY u(id, n)
+	             JOIN seats s ON s.id = u.id ORDER BY u.n),
+	       e.price_difference, e.status, e.workflow_id, e.transaction_id, e.credit_id,
+	       e.failure_reason, e.hold_expires_at, e.created_at, e.completed_at
+	FROM seat_exchanges e`
+
+func scanSeatExchanges(rows pgx.Rows) ([]SeatExchange, error) {
+	defer rows.Close()
+
+	exchanges := []SeatExchange{}
+	for rows.Next() {
+		var e SeatExchange
+		err := rows.Scan(&e.ID, &e.OrderID, &e.OldSeatIDs, &e.NewSeatIDs, &e.OldSeats, &e.NewSeats,
+			&e.PriceDifference, &e.Status, &e.WorkflowID, &e.TransactionID, &e.CreditID,
+			&e.FailureReason, &e.HoldExpiresAt, &e.CreatedAt, &e.CompletedAt)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan seat exchange: %w", err)
+		}
+		exchanges = append(exchanges, e)
+	}
+	return exchanges, rows.Err()
+}
+
+// GetOrderSeatExchanges returns the seat changes of an order, oldest first
+func (r *Repository) GetOrderSeatExchanges(ctx context.Context, orderID uuid.UUID) ([]SeatExchange, error) {
+	rows, err := r.pool.Query(ctx, seatExchangeSelect+`
+		WHERE e.order_id = $1
+		ORDER BY e.created_at, e.id
+	`, orderID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query seat exchanges: %w", err)
+	}
+	return scanSeatExchanges(rows)
+}
+
+// GetSeatExchange returns a seat change by ID
+func (r *Repository) GetSeatExchange(ctx context.Context, id uuid.UUID) (*SeatExchange, error) {
+	rows, err := r.pool.Query(ctx, seatExchangeSelect+` WHERE e.id = $1`, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query seat exchange: %w", err)
+	}
+	exchanges, err := scanSeatExchanges(rows)
+	if err != nil {
+		return nil, err
+	}
+	if len(exchanges) == 0 {
+		return nil, ErrNotFound
+	}
+	return &exchanges[0], nil
+}
+
+// CreateSeatExchange starts a seat change of a confirmed order: the new seats
+// of ex are held for the order until ex.HoldExpiresAt and the exchange is
+// recorded as pending with the fare difference. The seats are locked in ID
+// order, like HoldSeats. Every old seat must be booked by the order and every
+// new seat available on the same flight as the old seat it replaces;
+// otherwise nothing is held and a *SeatsUnavailableError lists the new seats
+// that are not. A difference to pay is only accepted with allowCharge.
+func (r *Repository) CreateSeatExchange(ctx context.Context, ex *SeatExchange, allowCharge bool) (*SeatExchange, error) {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	// Lock the order so it changes seats one request at a time
+	var status OrderStatus
+	err = tx.QueryRow(ctx, `SELECT status FROM orders WHERE id = $1 FOR UPDATE`, ex.OrderID).Scan(&status)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("failed to get order: %w", err)
+	}
+	if status != OrderStatusConfirmed {
+		return nil, fmt.Errorf("%w: order is %s", ErrOrderNotModifiable, status)
+	}
+
+	var pending bool
+	err = tx.QueryRow(ctx, `
+		SELECT EXISTS (SELECT 1 FROM seat_exchanges WHERE order_id = $1 AND status = 'pending')
+		       OR EXISTS (SELECT 1 FROM flight_exchanges WHERE order_id = $1 AND status IN ('pending', 'held', 'confirmed'))
+	`, ex.OrderID).Scan(&pending)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check seat exchanges: %w", err)
+	}
+	if pending {
+		return nil, fmt.Errorf("%w: a seat or flight change is already in progress", ErrOrderNotModifiable)
+	}
+
+	rows, err := tx.Query(ctx, `
+		SELECT id, flight_id, seat_number,
+		       CASE WHEN status = 'blocked' AND blocked_until <= NOW() THEN 'available' ELSE status END,
+		       held_by_order
+		FROM seats
+		WHERE id = ANY($1) OR id = ANY($2)
+		ORDER BY id
+		FOR UPDATE
+	`, ex.OldSeatIDs, ex.NewSeatIDs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to lock seats: %w", err)
+	}
+	locked, err := pgx.CollectRows(rows, pgx.RowToStructByPos[lockedSeat])
+	if err != nil {
+		return nil, fmt.Errorf("failed to lock seats: %w", err)
+	}
+	unavailable, err := checkSeatExchange(ex.OrderID, locked, ex.OldSeatIDs, ex.NewSeatIDs)
+	if err != nil {
+		return nil, err
+	}
+	if len(unavailable) > 0 {
+		return nil, &SeatsUnavailableError{SeatIDs: unavailable}
+	}
+
+	// The old seats are worth what was paid for them, the new ones their
+	// current fare and surcharges
+	var difference float64
+	err = tx.QueryRow(ctx, `
+		SELECT (SELECT COALESCE(SUM(s.price + `+seatSurcharge+`), 0) FROM seats s WHERE s.id = ANY($2))
+		     - (SELECT COALESCE(SUM(price), 0) FROM order_seats WHERE order_id = $1 AND seat_id = ANY($3))
+	`, ex.OrderID, ex.NewSeatIDs, ex.OldSeatIDs).Scan(&difference)
+	if err != nil {
+		return nil, fmt.Errorf("failed to price seat exchange: %w", err)
+	}
+	if difference > 0 && !allowCharge {
+		return nil, fmt.Errorf("%w: the new seats cost %.2f more", ErrPaymentRequired, difference)
+	}
+
+	_, err = tx.Exec(ctx, `
+		UPDATE seats
+		SET status = 'held', held_by_order = $1, held_until = $2,
+		    block_reason = NULL, block_note = NULL, blocked_by = NULL, blocked_at = NULL, blocked_until = NULL
+		WHERE id = ANY($3)
+	`, ex.OrderID, ex.HoldExpiresAt, ex.NewSeatIDs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to hold exchange seats: %w", err)
+	}
+
+	_, err = tx.Exec(ctx, `
+		UPDATE flights f
+		SET available_seats = (SELECT COUNT(*) FROM seats s WHERE s.flight_id = f.id AND s.status = 'available')
+		WHERE f.id IN (SELECT flight_id FROM seats WHERE id = ANY($1))
+	`, ex.NewSeatIDs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update seat counts: %w", err)
+	}
+
+	_, err = tx.Exec(ctx, `
+		INSERT INTO seat_exchanges (id, order_id, old_seat_ids, new_seat_ids, price_difference, workflow_id, hold_expires_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+	`, ex.ID, ex.OrderID, ex.OldSeatIDs, ex.NewSeatIDs, difference, ex.WorkflowID, ex.HoldExpiresAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create seat exchange: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit seat exchange: %w", err)
+	}
+	return r.GetSeatExchange(ctx, ex.ID)
+}
+
+// FailSeatExchange marks a pending seat change failed and releases the new
+// seats still held for it. Exchanges that are no longer pending are left as
+// they are.
+func (r *Repository) FailSeatExchange(ctx context.Context, id uuid.UUID, reason string) error {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	var orderID uuid.UUID
+	var newSeats []uuid.UUID
+	err = tx.QueryRow(ctx, `
+		UPDATE seat_exchanges
+		SET status = 'failed', failure_reason = $2, completed_at = NOW()
+		WHERE id = $1 AND status = 'pending'
+		RETURNING order_id, new_seat_ids
+	`, id, reason).Scan(&orderID, &newSeats)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to fail seat exchange: %w", err)
+	}
+
+	_, err = tx.Exec(ctx, `
+		UPDATE seats SET status = 'available', held_by_order = NULL, held_until = NULL
+		WHERE id = ANY($1) AND held_by_order = $2 AND status = 'held'
+	`, newSeats, orderID)
+	if err != nil {
+		return fmt.Errorf("failed to release exchange seats: %w", err)
+	}
+
+	_, err = tx.Exec(ctx, `
+		UPDATE flights f
+		SET available_seats = (SELECT COUNT(*) FROM seats s WHERE s.flight_id = f.id AND s.status = 'available')
+		WHERE f.id IN (SELECT flight_id FROM seats WHERE id = ANY($1))
+	`, newSeats)
+	if err != nil {
+		return fmt.Errorf("failed to update seat counts: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit seat exchange: %w", err)
+	}
+	return nil
+}
+
+// checkSeatExchange checks the locked seats of a seat change. It fails if an
+// old seat is not booked by the order, and returns the new seats that are
+// missing, not available or not on the flight of the seat they replace.
+func checkSeatExchange(orderID uuid.UUID, locked []lockedSeat, oldSeats, newSeats []uuid.UUID) ([]uuid.UUID, error) {
+	byID := make(map[uuid.UUID]lockedSeat, len(locked))
+	for _, s := range locked {
+		byID[s.ID] = s
+	}
+
+	var unavailable []uuid.UUID
+	for i, oldID := range oldSeats {
+		old, ok := byID[oldID]
+		if !ok || old.Status != SeatStatusBooked || old.HeldByOrder == nil || *old.HeldByOrder != orderID {
+			return nil, fmt.Errorf("%w: seat %s is not booked by the order", ErrOrderNotModifiable, oldID)
+		}
+		seat, ok := byID[newSeats[i]]
+		if !ok || seat.Status != SeatStatusAvailable || seat.FlightID != old.FlightID {
+			unavailable = append(unavailable, newSeats[i])
+		}
+	}
+	return unavailable, nil
+}
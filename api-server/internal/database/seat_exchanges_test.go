@@ -0,0 +1,47 @@
+package database
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckSeatExchange(t *testing.T) {
+	orderID := uuid.New()
+	otherOrder := uuid.New()
+	flightID := uuid.New()
+	otherFlight := uuid.New()
+
+	seat := func(flight uuid.UUID, status SeatStatus, heldBy *uuid.UUID) lockedSeat {
+		return lockedSeat{ID: uuid.New(), FlightID: flight, Status: status, HeldByOrder: heldBy}
+	}
+	mine := seat(flightID, SeatStatusBooked, &orderID)
+	mine2 := seat(flightID, SeatStatusBooked, &orderID)
+	mine3 := seat(flightID, SeatStatusBooked, &orderID)
+	theirs := seat(flightID, SeatStatusBooked, &otherOrder)
+	free := seat(flightID, SeatStatusAvailable, nil)
+	held := seat(flightID, SeatStatusHeld, &otherOrder)
+	elsewhere := seat(otherFlight, SeatStatusAvailable, nil)
+	locked := []lockedSeat{mine, mine2, mine3, theirs, free, held, elsewhere}
+
+	t.Run("available", func(t *testing.T) {
+		unavailable, err := checkSeatExchange(orderID, locked, []uuid.UUID{mine.ID}, []uuid.UUID{free.ID})
+		require.NoError(t, err)
+		assert.Empty(t, unavailable)
+	})
+
+	t.Run("unavailable new seats", func(t *testing.T) {
+		missing := uuid.New()
+		unavailable, err := checkSeatExchange(orderID, locked,
+			[]uuid.UUID{mine.ID, mine2.ID, mine3.ID}, []uuid.UUID{held.ID, elsewhere.ID, missing})
+		require.NoError(t, err)
+		assert.Equal(t, []uuid.UUID{held.ID, elsewhere.ID, missing}, unavailable)
+	})
+
+	t.Run("old seat of another order", func(t *testing.T) {
+		_, err := checkSeatExchange(orderID, locked, []uuid.UUID{theirs.ID}, []uuid.UUID{free.ID})
+		assert.ErrorIs(t, err, ErrOrderNotModifiable)
+	})
+}
@@ -0,0 +1,143 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// WaitlistStatus represents the state of a waitlist entry
+type WaitlistStatus string
+
+const (
+	WaitlistWaiting   WaitlistStatus = "waiting"
+	WaitlistOffered   WaitlistStatus = "offered"
+	WaitlistAccepted  WaitlistStatus = "accepted"
+	WaitlistExpired   WaitlistStatus = "expired"
+	WaitlistCancelled WaitlistStatus = "cancelled"
+)
+
+// WaitlistEntry is a customer waiting for seats of a class on a sold-out
+// flight. When seats are released the first waiting customer is offered
+// them: OrderID is an order holding the seats until OfferExpiresAt.
+// Position is the place of a waiting entry in the queue, starting at 1.
+type WaitlistEntry struct {
+	ID             uuid.UUID      `json:"id"`
+	FlightID       uuid.UUID      `json:"flightId"`
+	Class          string         `json:"class"`
+	CustomerName   string         `json:"customerName"`
+	CustomerEmail  string         `json:"customerEmail"`
+	PartySize      int            `json:"partySize"`
+	Status         WaitlistStatus `json:"status"`
+	Position       int            `json:"position,omitempty"`
+	OrderID        *uuid.UUID     `json:"orderId,omitempty"`
+	OfferedAt      *time.Time     `json:"offeredAt,omitempty"`
+	OfferExpiresAt *time.Time     `json:"offerExpiresAt,omitempty"`
+	CreatedAt      time.Time      `json:"createdAt"`
+	UpdatedAt      time.Time      `json:"updatedAt"`
+}
+
+// WaitlistKey identifies the waitlist of a class on a flight
+type WaitlistKey struct {
+	FlightID uuid.UUID
+	Class    string
+}
+
+// ErrWaitlistEntryClosed is returned when an entry that is no longer waiting
+// is cancelled
+var ErrWaitlistEntryClosed = errors.New("waitlist entry is no longer waiting")
+
+// CreateWaitlistEntry adds a customer to the end of a waitlist. A customer
+// already waiting for the class returns ErrAlreadyExists.
+func (r *Repository) CreateWaitlistEntry(ctx context.Context, e *WaitlistEntry) error {
+	if e.ID == uuid.Nil {
+		e.ID = uuid.New()
+	}
+	e.Status = WaitlistWaiting
+
+	err := r.pool.QueryRow(ctx, `
+		INSERT INTO waitlist_entries (id, flight_id, class, customer_name, customer_email, party_size)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING created_at, updated_at
+	`, e.ID, e.FlightID, e.Class, e.CustomerName, e.CustomerEmail, e.PartySize,
+	).Scan(&e.CreatedAt, &e.UpdatedAt)
+	if err != nil {
+		if isUniqueViolation(err) {
+			return ErrAlreadyExists
+		}
+		return fmt.Errorf("failed to create waitlist entry: %w", err)
+	}
+	return nil
+}
+
+// GetWaitlistEntry returns a waitlist entry with its queue position
+func (r *Repository) GetWaitlistEntry(ctx context.Context, id uuid.UUID) (*WaitlistEntry, error) {
+	var e WaitlistEntry
+	err := r.pool.QueryRow(ctx, `
+		SELECT w.id, w.flight_id, w.class, w.customer_name, w.customer_email, w.party_size,
+		       w.status, w.order_id, w.offered_at, w.offer_expires_at, w.created_at, w.updated_at,
+		       CASE WHEN w.status = 'waiting' THEN (
+		           SELECT COUNT(*) FROM waitlist_entries a
+		           WHERE a.flight_id = w.flight_id AND a.class = w.class AND a.status = 'waiting'
+		             AND (a.created_at, a.id) <= (w.created_at, w.id)
+		       ) ELSE 0 END
+		FROM waitlist_entries w
+		WHERE w.id = $1
+	`, id).Scan(
+		&e.ID, &e.FlightID, &e.Class, &e.CustomerName, &e.CustomerEmail, &e.PartySize,
+		&e.Status, &e.OrderID, &e.OfferedAt, &e.OfferExpiresAt, &e.CreatedAt, &e.UpdatedAt,
+		&e.Position,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("failed to get waitlist entry: %w", err)
+	}
+	return &e, nil
+}
+
+// CancelWaitlistEntry takes a waiting customer off the waitlist. Offered
+// seats are given up by cancelling the offer's order instead.
+func (r *Repository) CancelWaitlistEntry(ctx context.Context, id uuid.UUID) error {
+	tag, err := r.pool.Exec(ctx, `
+		UPDATE waitlist_entries SET status = 'cancelled'
+		WHERE id = $1 AND status = 'waiting'
+	`, id)
+	if err != nil {
+		return fmt.Errorf("failed to cancel waitlist entry: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		if _, err := r.GetWaitlistEntry(ctx, id); err != nil {
+			return err
+		}
+		return ErrWaitlistEntryClosed
+	}
+	return nil
+}
+
+// GetWaitlistsForSeats returns the waitlists with waiting customers for the
+// flights and classes of the given seats
+func (r *Repository) GetWaitlistsForSeats(ctx context.Context, seatIDs []uuid.UUID) ([]WaitlistKey, error) {
+	rows, err := r.pool.Query(ctx, `
+		SELECT DISTINCT s.flight_id, s.class
+		FROM seats s
+		WHERE s.id = ANY($1)
+		  AND EXISTS (
+			SELECT 1 FROM waitlist_entries w
+			WHERE w.flight_id = s.flight_id AND w.class = s.class AND w.status = 'waiting'
+		  )
+	`, seatIDs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query waitlists: %w", err)
+	}
+	keys, err := pgx.CollectRows(rows, pgx.RowToStructByPos[WaitlistKey])
+	if err != nil {
+		return nil, fmt.Errorf("failed to query waitlists: %w", err)
+	}
+	return keys, nil
+}
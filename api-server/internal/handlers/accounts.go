@@ -0,0 +1,181 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/cx-tal-miterani/flight-booking-system/api-server/internal/database"
+	"github.com/cx-tal-miterani/flight-booking-system/api-server/internal/service"
+	"github.com/gorilla/mux"
+)
+
+// bearerToken returns the token of an "Authorization: Bearer <token>" header
+func bearerToken(r *http.Request) string {
+	token, _ := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	return strings.TrimSpace(token)
+}
+
+// respondUnauthorized asks the client to sign in
+func respondUnauthorized(w http.ResponseWriter, message string) {
+	w.Header().Set("WWW-Authenticate", `Bearer realm="customer"`)
+	respondError(w, http.StatusUnauthorized, message)
+}
+
+// currentCustomer returns the customer signed in with the request's session
+// token, or nil for guests. ErrUnauthorized is returned for an invalid or
+// expired token.
+func (h *Handler) currentCustomer(r *http.Request) (*database.Customer, error) {
+	if r.Header.Get("Authorization") == "" {
+		return nil, nil
+	}
+	return h.service.Authenticate(r.Context(), bearerToken(r))
+}
+
+// Register handles POST /api/auth/register
+func (h *Handler) Register(w http.ResponseWriter, r *http.Request) {
+	var req service.RegisterRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	session, err := h.service.Register(r.Context(), req)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrInvalidInput):
+			respondError(w, http.StatusBadRequest, err.Error())
+		case errors.Is(err, database.ErrAlreadyExists):
+			respondError(w, http.StatusConflict, "An account with this email already exists")
+		default:
+			respondError(w, http.StatusInternalServerError, err.Error())
+		}
+		return
+	}
+	respondJSON(w, http.StatusCreated, session)
+}
+
+// Login handles POST /api/auth/login
+func (h *Handler) Login(w http.ResponseWriter, r *http.Request) {
+	var req service.LoginRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	session, err := h.service.Login(r.Context(), req)
+	if err != nil {
+		if errors.Is(err, service.ErrUnauthorized) {
+			respondUnauthorized(w, "Invalid email or password")
+			return
+		}
+		respondError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	respondJSON(w, http.StatusOK, session)
+}
+
+// Logout handles POST /api/auth/logout
+func (h *Handler) Logout(w http.ResponseWriter, r *http.Request) {
+	token := bearerToken(r)
+	if token == "" {
+		respondUnauthorized(w, "Not signed in")
+		return
+	}
+	if err := h.service.Logout(r.Context(), token); err != nil {
+		respondError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// GetMe handles GET /api/me
+func (h *Handler) GetMe(w http.ResponseWriter, r *http.Request) {
+	customer, ok := h.requireCustomer(w, r)
+	if !ok {
+		return
+	}
+	respondJSON(w, http.StatusOK, customer)
+}
+
+// GetMyOrders handles GET /api/me/orders
+//
+// Supported query parameters: status (comma-separated order statuses), from
+// and to (RFC 3339 or YYYY-MM-DD, on the order's creation time; to is
+// inclusive for dates).
+func (h *Handler) GetMyOrders(w http.ResponseWriter, r *http.Request) {
+	customer, ok := h.requireCustomer(w, r)
+	if !ok {
+		return
+	}
+
+	q := r.URL.Query()
+	var req service.CustomerOrdersRequest
+	if v := q.Get("status"); v != "" {
+		req.Statuses = strings.Split(v, ",")
+	}
+	var err error
+	if req.CreatedFrom, err = parseTimeParam(q.Get("from"), false); err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid from")
+		return
+	}
+	if req.CreatedTo, err = parseTimeParam(q.Get("to"), true); err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid to")
+		return
+	}
+
+	orders, err := h.service.GetCustomerOrders(r.Context(), customer.ID, req)
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidInput) {
+			respondError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		respondError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if orders == nil {
+		orders = []database.Order{}
+	}
+	respondJSON(w, http.StatusOK, orders)
+}
+
+// requireCustomer returns the signed-in customer, or responds with 401
+// Unauthorized when no one is signed in
+func (h *Handler) requireCustomer(w http.ResponseWriter, r *http.Request) (*database.Customer, bool) {
+	customer, err := h.currentCustomer(r)
+	if err != nil && !errors.Is(err, service.ErrUnauthorized) {
+		respondError(w, http.StatusInternalServerError, err.Error())
+		return nil, false
+	}
+	if customer == nil {
+		respondUnauthorized(w, "Sign in required")
+		return nil, false
+	}
+	return customer, true
+}
+
+// OrderAccess only lets requests for an order through from the account the
+// order was placed with; guest orders stay accessible by their ID, also with
+// an expired session token
+func (h *Handler) OrderAccess(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		customer, err := h.currentCustomer(r)
+		if err != nil && !errors.Is(err, service.ErrUnauthorized) {
+			respondError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+
+		err = h.service.AuthorizeOrder(r.Context(), mux.Vars(r)["id"], customer)
+		switch {
+		case err == nil:
+			next.ServeHTTP(w, r)
+		case errors.Is(err, service.ErrUnauthorized):
+			respondUnauthorized(w, "Sign in to access this order")
+		case errors.Is(err, database.ErrNotFound):
+			respondError(w, http.StatusNotFound, "Order not found")
+		default:
+			respondError(w, http.StatusInternalServerError, err.Error())
+		}
+	})
+}
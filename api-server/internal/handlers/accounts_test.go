@@ -0,0 +1,292 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/cx-tal-miterani/flight-booking-system/api-server/internal/database"
+	"github.com/cx-tal-miterani/flight-booking-system/api-server/internal/service"
+	"github.com/cx-tal-miterani/flight-booking-system/api-server/internal/service/mocks"
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandler_Register(t *testing.T) {
+	registerReq := service.RegisterRequest{Email: "ada@example.com", Password: "correct horse", Name: "Ada Lovelace"}
+
+	tests := []struct {
+		name           string
+		mockReturn     *service.Session
+		mockError      error
+		expectedStatus int
+	}{
+		{
+			name: "registered",
+			mockReturn: &service.Session{
+				Token: "token", ExpiresAt: time.Now().Add(service.SessionTTL),
+				Customer: &database.Customer{ID: uuid.New(), Email: "ada@example.com", Name: "Ada Lovelace", PasswordHash: "hash"},
+			},
+			expectedStatus: http.StatusCreated,
+		},
+		{
+			name:           "short password",
+			mockError:      fmt.Errorf("%w: password must be 8 to 72 characters", service.ErrInvalidInput),
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "email taken",
+			mockError:      database.ErrAlreadyExists,
+			expectedStatus: http.StatusConflict,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(mocks.MockService)
+			handler := NewHandler(mockService)
+			router := setupTestRouter(handler)
+
+			mockService.On("Register", mock.Anything, registerReq).Return(tt.mockReturn, tt.mockError)
+
+			body, _ := json.Marshal(registerReq)
+			req := httptest.NewRequest(http.MethodPost, "/api/auth/register", bytes.NewReader(body))
+			rec := httptest.NewRecorder()
+
+			router.ServeHTTP(rec, req)
+
+			assert.Equal(t, tt.expectedStatus, rec.Code)
+			if tt.mockReturn != nil {
+				assert.Contains(t, rec.Body.String(), `"token":"token"`)
+				assert.NotContains(t, rec.Body.String(), "hash")
+			}
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestHandler_Login(t *testing.T) {
+	loginReq := service.LoginRequest{Email: "ada@example.com", Password: "correct horse"}
+
+	tests := []struct {
+		name           string
+		mockReturn     *service.Session
+		mockError      error
+		expectedStatus int
+	}{
+		{
+			name:           "signed in",
+			mockReturn:     &service.Session{Token: "token", Customer: &database.Customer{ID: uuid.New()}},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "wrong password",
+			mockError:      service.ErrUnauthorized,
+			expectedStatus: http.StatusUnauthorized,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(mocks.MockService)
+			handler := NewHandler(mockService)
+			router := setupTestRouter(handler)
+
+			mockService.On("Login", mock.Anything, loginReq).Return(tt.mockReturn, tt.mockError)
+
+			body, _ := json.Marshal(loginReq)
+			req := httptest.NewRequest(http.MethodPost, "/api/auth/login", bytes.NewReader(body))
+			rec := httptest.NewRecorder()
+
+			router.ServeHTTP(rec, req)
+
+			assert.Equal(t, tt.expectedStatus, rec.Code)
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestHandler_Logout(t *testing.T) {
+	mockService := new(mocks.MockService)
+	router := setupTestRouter(NewHandler(mockService))
+	mockService.On("Logout", mock.Anything, "token").Return(nil)
+
+	req := httptest.NewRequest(http.MethodPost, "/api/auth/logout", nil)
+	req.Header.Set("Authorization", "Bearer token")
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusNoContent, rec.Code)
+
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/api/auth/logout", nil))
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+	mockService.AssertExpectations(t)
+}
+
+func TestHandler_GetMyOrders(t *testing.T) {
+	customer := &database.Customer{ID: uuid.New(), Email: "ada@example.com", Name: "Ada Lovelace"}
+	from := time.Date(2026, 6, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2026, 7, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name           string
+		query          string
+		authorization  string
+		sessionError   error
+		expectedReq    *service.CustomerOrdersRequest
+		mockReturn     []database.Order
+		mockError      error
+		expectedStatus int
+	}{
+		{
+			name:           "filtered",
+			query:          "?status=confirmed,cancelled&from=2026-06-01&to=2026-06-30",
+			authorization:  "Bearer token",
+			expectedReq:    &service.CustomerOrdersRequest{Statuses: []string{"confirmed", "cancelled"}, CreatedFrom: &from, CreatedTo: &to},
+			mockReturn:     []database.Order{{ID: uuid.New(), CustomerID: &customer.ID, Status: database.OrderStatusConfirmed}},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "unknown status",
+			query:          "?status=shipped",
+			authorization:  "Bearer token",
+			expectedReq:    &service.CustomerOrdersRequest{Statuses: []string{"shipped"}},
+			mockError:      fmt.Errorf("%w: unknown order status %q", service.ErrInvalidInput, "shipped"),
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "invalid date",
+			query:          "?from=June",
+			authorization:  "Bearer token",
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "not signed in",
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			name:           "session expired",
+			authorization:  "Bearer expired",
+			sessionError:   service.ErrUnauthorized,
+			expectedStatus: http.StatusUnauthorized,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(mocks.MockService)
+			handler := NewHandler(mockService)
+			router := setupTestRouter(handler)
+
+			if tt.authorization != "" {
+				if tt.sessionError != nil {
+					mockService.On("Authenticate", mock.Anything, "expired").Return(nil, tt.sessionError)
+				} else {
+					mockService.On("Authenticate", mock.Anything, "token").Return(customer, nil)
+				}
+			}
+			if tt.expectedReq != nil {
+				mockService.On("GetCustomerOrders", mock.Anything, customer.ID, *tt.expectedReq).Return(tt.mockReturn, tt.mockError)
+			}
+
+			req := httptest.NewRequest(http.MethodGet, "/api/me/orders"+tt.query, nil)
+			if tt.authorization != "" {
+				req.Header.Set("Authorization", tt.authorization)
+			}
+			rec := httptest.NewRecorder()
+
+			router.ServeHTTP(rec, req)
+
+			assert.Equal(t, tt.expectedStatus, rec.Code)
+			if tt.expectedStatus == http.StatusOK {
+				var orders []database.Order
+				require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &orders))
+				assert.Len(t, orders, len(tt.mockReturn))
+			}
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestHandler_CreateOrder_SignedIn(t *testing.T) {
+	mockService := new(mocks.MockService)
+	router := setupTestRouter(NewHandler(mockService))
+
+	customer := &database.Customer{ID: uuid.New(), Email: "ada@example.com", Name: "Ada Lovelace"}
+	flightID := uuid.New().String()
+	mockService.On("Authenticate", mock.Anything, "token").Return(customer, nil)
+	mockService.On("CreateOrder", mock.Anything, service.CreateOrderRequest{
+		FlightID: flightID, CustomerName: "Ada Lovelace", CustomerEmail: "ada@example.com", CustomerID: &customer.ID,
+	}).Return(&database.Order{ID: uuid.New(), CustomerID: &customer.ID}, nil)
+
+	body, _ := json.Marshal(map[string]string{"flightId": flightID})
+	req := httptest.NewRequest(http.MethodPost, "/api/orders", bytes.NewReader(body))
+	req.Header.Set("Authorization", "Bearer token")
+	rec := httptest.NewRecorder()
+
+	router.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusCreated, rec.Code)
+	mockService.AssertExpectations(t)
+}
+
+func TestHandler_OrderAccess(t *testing.T) {
+	customer := &database.Customer{ID: uuid.New()}
+	orderID := uuid.New().String()
+
+	tests := []struct {
+		name           string
+		authorization  string
+		customer       *database.Customer
+		sessionError   error
+		authorizeError error
+		expectedStatus int
+	}{
+		{name: "guest order", expectedStatus: http.StatusOK},
+		{name: "own order", authorization: "Bearer token", customer: customer, expectedStatus: http.StatusOK},
+		{name: "guest order with expired session", authorization: "Bearer token", sessionError: service.ErrUnauthorized, expectedStatus: http.StatusOK},
+		{name: "account order without session", authorizeError: service.ErrUnauthorized, expectedStatus: http.StatusUnauthorized},
+		{name: "another account's order", authorization: "Bearer token", customer: customer, authorizeError: database.ErrNotFound, expectedStatus: http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(mocks.MockService)
+			handler := NewHandler(mockService)
+
+			r := mux.NewRouter()
+			order := r.PathPrefix("/api/orders/{id}").Subrouter()
+			order.Use(handler.OrderAccess)
+			order.HandleFunc("", func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusOK)
+			})
+
+			if tt.authorization != "" {
+				if tt.sessionError != nil {
+					mockService.On("Authenticate", mock.Anything, "token").Return(nil, tt.sessionError)
+				} else {
+					mockService.On("Authenticate", mock.Anything, "token").Return(tt.customer, nil)
+				}
+			}
+			mockService.On("AuthorizeOrder", mock.Anything, orderID, tt.customer).Return(tt.authorizeError)
+
+			req := httptest.NewRequest(http.MethodGet, "/api/orders/"+orderID, nil)
+			if tt.authorization != "" {
+				req.Header.Set("Authorization", tt.authorization)
+			}
+			rec := httptest.NewRecorder()
+
+			r.ServeHTTP(rec, req)
+
+			assert.Equal(t, tt.expectedStatus, rec.Code)
+			mockService.AssertExpectations(t)
+		})
+	}
+}
@@ -0,0 +1,239 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/cx-tal-miterani/flight-booking-system/api-server/internal/database"
+	"github.com/cx-tal-miterani/flight-booking-system/api-server/internal/service"
+	"github.com/gorilla/mux"
+)
+
+// respondFlightAdminError maps flight management errors to HTTP responses
+func respondFlightAdminError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, service.ErrInvalidInput):
+		respondError(w, http.StatusBadRequest, err.Error())
+	case errors.Is(err, database.ErrNotFound):
+		respondError(w, http.StatusNotFound, "Flight not found")
+	case errors.Is(err, database.ErrDuplicateFlightNumber),
+		errors.Is(err, database.ErrFlightInUse),
+		errors.Is(err, database.ErrSeatsInUse),
+		errors.Is(err, database.ErrAlreadyExists),
+		errors.Is(err, database.ErrScheduleOverlap):
+		respondError(w, http.StatusConflict, err.Error())
+	default:
+		respondError(w, http.StatusInternalServerError, err.Error())
+	}
+}
+
+// AdminCreateFlight handles POST /api/admin/flights
+func (h *Handler) AdminCreateFlight(w http.ResponseWriter, r *http.Request) {
+	var req service.CreateFlightRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	flight, err := h.service.CreateFlight(r.Context(), req)
+	if err != nil {
+		respondFlightAdminError(w, err)
+		return
+	}
+	respondJSON(w, http.StatusCreated, flight)
+}
+
+// AdminUpdateFlight handles PATCH /api/admin/flights/{id}
+func (h *Handler) AdminUpdateFlight(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+
+	var req service.UpdateFlightRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	flight, err := h.service.UpdateFlight(r.Context(), id, req)
+	if err != nil {
+		respondFlightAdminError(w, err)
+		return
+	}
+	respondJSON(w, http.StatusOK, flight)
+}
+
+// AdminDeleteFlight handles DELETE /api/admin/flights/{id}
+func (h *Handler) AdminDeleteFlight(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+
+	if err := h.service.DeleteFlight(r.Context(), id); err != nil {
+		respondFlightAdminError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// AdminUpdateFlightStatus handles PUT /api/admin/flights/{id}/status
+func (h *Handler) AdminUpdateFlightStatus(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+
+	var req service.UpdateFlightStatusRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	flight, err := h.service.UpdateFlightStatus(r.Context(), id, req)
+	if err != nil {
+		respondFlightAdminError(w, err)
+		return
+	}
+	respondJSON(w, http.StatusOK, flight)
+}
+
+// AdminGetCabinLayouts handles GET /api/admin/cabin-layouts
+func (h *Handler) AdminGetCabinLayouts(w http.ResponseWriter, r *http.Request) {
+	layouts, err := h.service.GetCabinLayouts(r.Context())
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	respondJSON(w, http.StatusOK, layouts)
+}
+
+// AdminCreateCabinLayout handles POST /api/admin/cabin-layouts
+func (h *Handler) AdminCreateCabinLayout(w http.ResponseWriter, r *http.Request) {
+	var req service.CreateCabinLayoutRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	layout, err := h.service.CreateCabinLayout(r.Context(), req)
+	if err != nil {
+		respondFlightAdminError(w, err)
+		return
+	}
+	respondJSON(w, http.StatusCreated, layout)
+}
+
+// AdminGetAircraftTypes handles GET /api/admin/aircraft-types
+func (h *Handler) AdminGetAircraftTypes(w http.ResponseWriter, r *http.Request) {
+	types, err := h.service.GetAircraftTypes(r.Context())
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	respondJSON(w, http.StatusOK, types)
+}
+
+// AdminCreateAircraftType handles POST /api/admin/aircraft-types
+func (h *Handler) AdminCreateAircraftType(w http.ResponseWriter, r *http.Request) {
+	var req service.CreateAircraftTypeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	aircraftType, err := h.service.CreateAircraftType(r.Context(), req)
+	if err != nil {
+		respondFlightAdminError(w, err)
+		return
+	}
+	respondJSON(w, http.StatusCreated, aircraftType)
+}
+
+// AdminUpdateSeatAttributeSurcharge handles PUT /api/admin/seat-attributes/{attribute}
+func (h *Handler) AdminUpdateSeatAttributeSurcharge(w http.ResponseWriter, r *http.Request) {
+	attribute := mux.Vars(r)["attribute"]
+
+	var req service.UpdateSeatAttributeSurchargeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	surcharge, err := h.service.UpdateSeatAttributeSurcharge(r.Context(), attribute, req)
+	if err != nil {
+		respondFlightAdminError(w, err)
+		return
+	}
+	respondJSON(w, http.StatusOK, surcharge)
+}
+
+// AdminGetFlightSchedules handles GET /api/admin/schedules
+func (h *Handler) AdminGetFlightSchedules(w http.ResponseWriter, r *http.Request) {
+	schedules, err := h.service.GetFlightSchedules(r.Context())
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	respondJSON(w, http.StatusOK, schedules)
+}
+
+// AdminCreateFlightSchedule handles POST /api/admin/schedules
+func (h *Handler) AdminCreateFlightSchedule(w http.ResponseWriter, r *http.Request) {
+	var req service.CreateFlightScheduleRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	schedule, err := h.service.CreateFlightSchedule(r.Context(), req)
+	if err != nil {
+		respondFlightAdminError(w, err)
+		return
+	}
+	respondJSON(w, http.StatusCreated, schedule)
+}
+
+// AdminDeleteFlightSchedule handles DELETE /api/admin/schedules/{id}
+func (h *Handler) AdminDeleteFlightSchedule(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+
+	err := h.service.DeleteFlightSchedule(r.Context(), id)
+	if errors.Is(err, database.ErrNotFound) {
+		respondError(w, http.StatusNotFound, "Schedule not found")
+		return
+	}
+	if err != nil {
+		respondFlightAdminError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// maxSSIMUploadBytes limits the size of an uploaded SSIM file
+const maxSSIMUploadBytes = 10 << 20
+
+// AdminImportSSIM handles POST /api/admin/imports/ssim
+//
+// The request body is a SSIM file. Query parameters: pricePerSeat (the fare
+// of new flights, required) and dryRun=true to only report the changes.
+func (h *Handler) AdminImportSSIM(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	price, err := strconv.ParseFloat(q.Get("pricePerSeat"), 64)
+	if err != nil || price <= 0 {
+		respondError(w, http.StatusBadRequest, "Invalid pricePerSeat")
+		return
+	}
+	dryRun := false
+	if v := q.Get("dryRun"); v != "" {
+		if dryRun, err = strconv.ParseBool(v); err != nil {
+			respondError(w, http.StatusBadRequest, "Invalid dryRun")
+			return
+		}
+	}
+
+	body := http.MaxBytesReader(w, r.Body, maxSSIMUploadBytes)
+	result, err := h.service.ImportSSIM(r.Context(), body, service.SSIMImportRequest{
+		PricePerSeat: price,
+		DryRun:       dryRun,
+	})
+	if err != nil {
+		respondFlightAdminError(w, err)
+		return
+	}
+	respondJSON(w, http.StatusOK, result)
+}
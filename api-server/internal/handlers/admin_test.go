@@ -0,0 +1,504 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/cx-tal-miterani/flight-booking-system/api-server/internal/database"
+	"github.com/cx-tal-miterani/flight-booking-system/api-server/internal/service"
+	"github.com/cx-tal-miterani/flight-booking-system/api-server/internal/service/mocks"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestHandler_AdminCreateFlight(t *testing.T) {
+	departure := time.Date(2030, 1, 10, 8, 0, 0, 0, time.UTC)
+	validReq := service.CreateFlightRequest{
+		FlightNumber:  "AA900",
+		Origin:        "JFK",
+		Destination:   "LAX",
+		DepartureTime: departure,
+		ArrivalTime:   departure.Add(6 * time.Hour),
+		PricePerSeat:  250,
+		CabinLayout:   "regional",
+	}
+
+	tests := []struct {
+		name           string
+		body           interface{}
+		mockReturn     *database.Flight
+		mockError      error
+		expectedStatus int
+	}{
+		{
+			name:           "flight created",
+			body:           validReq,
+			mockReturn:     &database.Flight{ID: uuid.New(), FlightNumber: "AA900", TotalSeats: 77},
+			expectedStatus: http.StatusCreated,
+		},
+		{
+			name:           "validation error",
+			body:           validReq,
+			mockError:      fmt.Errorf("%w: unknown cabin layout", service.ErrInvalidInput),
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "duplicate flight number",
+			body:           validReq,
+			mockError:      database.ErrDuplicateFlightNumber,
+			expectedStatus: http.StatusConflict,
+		},
+		{
+			name:           "invalid body",
+			body:           "not json",
+			expectedStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(mocks.MockService)
+			handler := NewHandler(mockService)
+			router := setupTestRouter(handler)
+
+			if tt.mockReturn != nil || tt.mockError != nil {
+				mockService.On("CreateFlight", mock.Anything, validReq).Return(tt.mockReturn, tt.mockError)
+			}
+
+			var body []byte
+			if s, ok := tt.body.(string); ok {
+				body = []byte(s)
+			} else {
+				body, _ = json.Marshal(tt.body)
+			}
+			req := httptest.NewRequest(http.MethodPost, "/api/admin/flights", bytes.NewReader(body))
+			rec := httptest.NewRecorder()
+
+			router.ServeHTTP(rec, req)
+
+			assert.Equal(t, tt.expectedStatus, rec.Code)
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestHandler_AdminUpdateFlight(t *testing.T) {
+	flightID := uuid.New().String()
+	departure := time.Date(2030, 1, 10, 9, 30, 0, 0, time.UTC)
+	layout := "widebody"
+	req := service.UpdateFlightRequest{DepartureTime: &departure, CabinLayout: &layout}
+
+	tests := []struct {
+		name           string
+		mockReturn     *database.Flight
+		mockError      error
+		expectedStatus int
+	}{
+		{
+			name:           "flight rescheduled",
+			mockReturn:     &database.Flight{FlightNumber: "AA900", DepartureTime: departure},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "flight not found",
+			mockError:      database.ErrNotFound,
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			name:           "would orphan reserved seats",
+			mockError:      fmt.Errorf("%w: 30F", database.ErrSeatsInUse),
+			expectedStatus: http.StatusConflict,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(mocks.MockService)
+			handler := NewHandler(mockService)
+			router := setupTestRouter(handler)
+
+			mockService.On("UpdateFlight", mock.Anything, flightID, req).Return(tt.mockReturn, tt.mockError)
+
+			body, _ := json.Marshal(req)
+			httpReq := httptest.NewRequest(http.MethodPatch, "/api/admin/flights/"+flightID, bytes.NewReader(body))
+			rec := httptest.NewRecorder()
+
+			router.ServeHTTP(rec, httpReq)
+
+			assert.Equal(t, tt.expectedStatus, rec.Code)
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestHandler_AdminDeleteFlight(t *testing.T) {
+	tests := []struct {
+		name           string
+		mockError      error
+		expectedStatus int
+	}{
+		{name: "flight deleted", expectedStatus: http.StatusNoContent},
+		{name: "flight not found", mockError: database.ErrNotFound, expectedStatus: http.StatusNotFound},
+		{name: "flight has orders", mockError: database.ErrFlightInUse, expectedStatus: http.StatusConflict},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(mocks.MockService)
+			handler := NewHandler(mockService)
+			router := setupTestRouter(handler)
+
+			flightID := uuid.New().String()
+			mockService.On("DeleteFlight", mock.Anything, flightID).Return(tt.mockError)
+
+			req := httptest.NewRequest(http.MethodDelete, "/api/admin/flights/"+flightID, nil)
+			rec := httptest.NewRecorder()
+
+			router.ServeHTTP(rec, req)
+
+			assert.Equal(t, tt.expectedStatus, rec.Code)
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestHandler_AdminCreateCabinLayout(t *testing.T) {
+	req := service.CreateCabinLayoutRequest{
+		Name:         "narrowbody-dense",
+		AircraftType: "A320",
+		Cabins: []database.Cabin{
+			{Class: "economy", FirstRow: 1, LastRow: 31, ColumnGroups: []string{"ABC", "DEF"}, PriceMultiplier: 1},
+		},
+	}
+
+	tests := []struct {
+		name           string
+		mockReturn     *database.CabinLayout
+		mockError      error
+		expectedStatus int
+	}{
+		{
+			name:           "layout created",
+			mockReturn:     &database.CabinLayout{Name: req.Name, AircraftType: "A320", Cabins: req.Cabins, SeatCount: 186},
+			expectedStatus: http.StatusCreated,
+		},
+		{
+			name:           "unknown aircraft type",
+			mockError:      fmt.Errorf("%w: unknown aircraft type", service.ErrInvalidInput),
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "layout exists",
+			mockError:      fmt.Errorf("%w: cabin layout narrowbody-dense", database.ErrAlreadyExists),
+			expectedStatus: http.StatusConflict,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(mocks.MockService)
+			handler := NewHandler(mockService)
+			router := setupTestRouter(handler)
+
+			mockService.On("CreateCabinLayout", mock.Anything, req).Return(tt.mockReturn, tt.mockError)
+
+			body, _ := json.Marshal(req)
+			httpReq := httptest.NewRequest(http.MethodPost, "/api/admin/cabin-layouts", bytes.NewReader(body))
+			rec := httptest.NewRecorder()
+
+			router.ServeHTTP(rec, httpReq)
+
+			assert.Equal(t, tt.expectedStatus, rec.Code)
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestHandler_AdminCreateAircraftType(t *testing.T) {
+	req := service.CreateAircraftTypeRequest{Code: "A21N", Manufacturer: "Airbus", Model: "A321neo"}
+
+	tests := []struct {
+		name           string
+		mockReturn     *database.AircraftType
+		mockError      error
+		expectedStatus int
+	}{
+		{
+			name:           "aircraft type created",
+			mockReturn:     &database.AircraftType{Code: "A21N", Manufacturer: "Airbus", Model: "A321neo"},
+			expectedStatus: http.StatusCreated,
+		},
+		{
+			name:           "aircraft type exists",
+			mockError:      database.ErrAlreadyExists,
+			expectedStatus: http.StatusConflict,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(mocks.MockService)
+			handler := NewHandler(mockService)
+			router := setupTestRouter(handler)
+
+			mockService.On("CreateAircraftType", mock.Anything, req).Return(tt.mockReturn, tt.mockError)
+
+			body, _ := json.Marshal(req)
+			httpReq := httptest.NewRequest(http.MethodPost, "/api/admin/aircraft-types", bytes.NewReader(body))
+			rec := httptest.NewRecorder()
+
+			router.ServeHTTP(rec, httpReq)
+
+			assert.Equal(t, tt.expectedStatus, rec.Code)
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestHandler_AdminUpdateSeatAttributeSurcharge(t *testing.T) {
+	req := service.UpdateSeatAttributeSurchargeRequest{Surcharge: 25}
+
+	tests := []struct {
+		name           string
+		attribute      string
+		mockReturn     *database.SeatAttributeSurcharge
+		mockError      error
+		expectedStatus int
+	}{
+		{
+			name:           "surcharge updated",
+			attribute:      "exit_row",
+			mockReturn:     &database.SeatAttributeSurcharge{Attribute: "exit_row", Surcharge: 25},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "unknown attribute",
+			attribute:      "bulkhead",
+			mockError:      fmt.Errorf("%w: unknown seat attribute", service.ErrInvalidInput),
+			expectedStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(mocks.MockService)
+			handler := NewHandler(mockService)
+			router := setupTestRouter(handler)
+
+			mockService.On("UpdateSeatAttributeSurcharge", mock.Anything, tt.attribute, req).Return(tt.mockReturn, tt.mockError)
+
+			body, _ := json.Marshal(req)
+			httpReq := httptest.NewRequest(http.MethodPut, "/api/admin/seat-attributes/"+tt.attribute, bytes.NewReader(body))
+			rec := httptest.NewRecorder()
+
+			router.ServeHTTP(rec, httpReq)
+
+			assert.Equal(t, tt.expectedStatus, rec.Code)
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestHandler_AdminUpdateFlightStatus(t *testing.T) {
+	flightID := uuid.New().String()
+	req := service.UpdateFlightStatusRequest{Status: database.FlightStatusCancelled, Reason: "Crew shortage"}
+
+	tests := []struct {
+		name           string
+		mockReturn     *database.Flight
+		mockError      error
+		expectedStatus int
+	}{
+		{
+			name:           "flight cancelled",
+			mockReturn:     &database.Flight{FlightNumber: "AA900", Status: database.FlightStatusCancelled},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "invalid transition",
+			mockError:      fmt.Errorf("%w: cannot change flight status from arrived to \"cancelled\"", service.ErrInvalidInput),
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "flight not found",
+			mockError:      database.ErrNotFound,
+			expectedStatus: http.StatusNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(mocks.MockService)
+			handler := NewHandler(mockService)
+			router := setupTestRouter(handler)
+
+			mockService.On("UpdateFlightStatus", mock.Anything, flightID, req).Return(tt.mockReturn, tt.mockError)
+
+			body, _ := json.Marshal(req)
+			httpReq := httptest.NewRequest(http.MethodPut, "/api/admin/flights/"+flightID+"/status", bytes.NewReader(body))
+			rec := httptest.NewRecorder()
+
+			router.ServeHTTP(rec, httpReq)
+
+			assert.Equal(t, tt.expectedStatus, rec.Code)
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestHandler_AdminCreateFlightSchedule(t *testing.T) {
+	req := service.CreateFlightScheduleRequest{
+		FlightNumber:       "AA101",
+		Origin:             "JFK",
+		Destination:        "LAX",
+		DaysOfWeek:         []int{1, 3, 5},
+		DepartureTimeLocal: "08:00",
+		ArrivalTimeLocal:   "11:30",
+		EffectiveFrom:      "2024-06-01",
+		AircraftType:       "A320",
+		PricePerSeat:       199.99,
+	}
+
+	tests := []struct {
+		name           string
+		mockReturn     *database.FlightSchedule
+		mockError      error
+		expectedStatus int
+	}{
+		{
+			name:           "schedule created",
+			mockReturn:     &database.FlightSchedule{ID: uuid.New(), FlightNumber: "AA101", SeatCount: 150},
+			expectedStatus: http.StatusCreated,
+		},
+		{
+			name:           "invalid schedule",
+			mockError:      fmt.Errorf("%w: invalid day of the week 8", service.ErrInvalidInput),
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "overlapping schedule",
+			mockError:      database.ErrScheduleOverlap,
+			expectedStatus: http.StatusConflict,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(mocks.MockService)
+			handler := NewHandler(mockService)
+			router := setupTestRouter(handler)
+
+			mockService.On("CreateFlightSchedule", mock.Anything, req).Return(tt.mockReturn, tt.mockError)
+
+			body, _ := json.Marshal(req)
+			httpReq := httptest.NewRequest(http.MethodPost, "/api/admin/schedules", bytes.NewReader(body))
+			rec := httptest.NewRecorder()
+
+			router.ServeHTTP(rec, httpReq)
+
+			assert.Equal(t, tt.expectedStatus, rec.Code)
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestHandler_AdminDeleteFlightSchedule(t *testing.T) {
+	tests := []struct {
+		name           string
+		mockError      error
+		expectedStatus int
+	}{
+		{name: "schedule deleted", expectedStatus: http.StatusNoContent},
+		{name: "schedule not found", mockError: database.ErrNotFound, expectedStatus: http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(mocks.MockService)
+			handler := NewHandler(mockService)
+			router := setupTestRouter(handler)
+
+			scheduleID := uuid.New().String()
+			mockService.On("DeleteFlightSchedule", mock.Anything, scheduleID).Return(tt.mockError)
+
+			req := httptest.NewRequest(http.MethodDelete, "/api/admin/schedules/"+scheduleID, nil)
+			rec := httptest.NewRecorder()
+
+			router.ServeHTTP(rec, req)
+
+			assert.Equal(t, tt.expectedStatus, rec.Code)
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestHandler_AdminImportSSIM(t *testing.T) {
+	tests := []struct {
+		name           string
+		query          string
+		expectedReq    *service.SSIMImportRequest
+		mockError      error
+		expectedStatus int
+	}{
+		{
+			name:           "dry run",
+			query:          "?pricePerSeat=199.99&dryRun=true",
+			expectedReq:    &service.SSIMImportRequest{PricePerSeat: 199.99, DryRun: true},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "import",
+			query:          "?pricePerSeat=150",
+			expectedReq:    &service.SSIMImportRequest{PricePerSeat: 150},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "missing price",
+			query:          "?dryRun=true",
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "invalid dry run",
+			query:          "?pricePerSeat=150&dryRun=maybe",
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "unreadable file",
+			query:          "?pricePerSeat=150",
+			expectedReq:    &service.SSIMImportRequest{PricePerSeat: 150},
+			mockError:      fmt.Errorf("%w: failed to read SSIM file", service.ErrInvalidInput),
+			expectedStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(mocks.MockService)
+			handler := NewHandler(mockService)
+			router := setupTestRouter(handler)
+
+			if tt.expectedReq != nil {
+				var result *service.SSIMImportResult
+				if tt.mockError == nil {
+					result = &service.SSIMImportResult{DryRun: tt.expectedReq.DryRun, Records: 1}
+				}
+				mockService.On("ImportSSIM", mock.Anything, mock.Anything, *tt.expectedReq).Return(result, tt.mockError)
+			}
+
+			body := bytes.NewBufferString("3 AA  1010101J01JUN2430JUN241 3 5   JFK08000800-0400  LAX11301130-0700  320")
+			req := httptest.NewRequest(http.MethodPost, "/api/admin/imports/ssim"+tt.query, body)
+			rec := httptest.NewRecorder()
+
+			router.ServeHTTP(rec, req)
+
+			assert.Equal(t, tt.expectedStatus, rec.Code)
+			mockService.AssertExpectations(t)
+		})
+	}
+}
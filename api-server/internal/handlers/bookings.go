@@ -0,0 +1,27 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/cx-tal-miterani/flight-booking-system/api-server/internal/database"
+	"github.com/cx-tal-miterani/flight-booking-system/api-server/internal/service"
+	"github.com/gorilla/mux"
+)
+
+// GetBooking handles GET /api/bookings/{reference}?lastName=
+func (h *Handler) GetBooking(w http.ResponseWriter, r *http.Request) {
+	booking, err := h.service.GetBooking(r.Context(), mux.Vars(r)["reference"], r.URL.Query().Get("lastName"))
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrInvalidInput):
+			respondError(w, http.StatusBadRequest, err.Error())
+		case errors.Is(err, database.ErrNotFound):
+			respondError(w, http.StatusNotFound, "Booking not found")
+		default:
+			respondError(w, http.StatusInternalServerError, err.Error())
+		}
+		return
+	}
+	respondJSON(w, http.StatusOK, booking)
+}
@@ -0,0 +1,69 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/cx-tal-miterani/flight-booking-system/api-server/internal/database"
+	"github.com/cx-tal-miterani/flight-booking-system/api-server/internal/service"
+	"github.com/cx-tal-miterani/flight-booking-system/api-server/internal/service/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandler_GetBooking(t *testing.T) {
+	tests := []struct {
+		name           string
+		mockReturn     *service.Booking
+		mockError      error
+		expectedStatus int
+	}{
+		{
+			name: "found",
+			mockReturn: &service.Booking{
+				Reference: "K7QM2X", Status: database.OrderStatusConfirmed, CustomerName: "Ada Lovelace",
+				Flights: []service.BookingFlight{{FlightNumber: "FB101", Seats: []string{"12A"}}},
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "last name missing",
+			mockError:      fmt.Errorf("%w: last name is required", service.ErrInvalidInput),
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "not found",
+			mockError:      database.ErrNotFound,
+			expectedStatus: http.StatusNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(mocks.MockService)
+			handler := NewHandler(mockService)
+			router := setupTestRouter(handler)
+
+			mockService.On("GetBooking", mock.Anything, "k7qm2x", "Lovelace").Return(tt.mockReturn, tt.mockError)
+
+			req := httptest.NewRequest(http.MethodGet, "/api/bookings/k7qm2x?lastName=Lovelace", nil)
+			rec := httptest.NewRecorder()
+
+			router.ServeHTTP(rec, req)
+
+			assert.Equal(t, tt.expectedStatus, rec.Code)
+			if tt.mockReturn != nil {
+				var body map[string]interface{}
+				require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+				assert.Equal(t, "K7QM2X", body["reference"])
+				assert.NotContains(t, body, "id")
+				assert.NotContains(t, body, "customerEmail")
+			}
+			mockService.AssertExpectations(t)
+		})
+	}
+}
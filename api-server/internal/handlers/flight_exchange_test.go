@@ -0,0 +1,79 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/cx-tal-miterani/flight-booking-system/api-server/internal/database"
+	"github.com/cx-tal-miterani/flight-booking-system/api-server/internal/service"
+	"github.com/cx-tal-miterani/flight-booking-system/api-server/internal/service/mocks"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestHandler_ExchangeFlight(t *testing.T) {
+	orderID := uuid.New().String()
+	exchangeReq := service.FlightExchangeRequest{
+		FromFlightID: uuid.New().String(),
+		ToFlightID:   uuid.New().String(),
+		PaymentCode:  "12345",
+	}
+
+	tests := []struct {
+		name           string
+		mockReturn     *database.FlightExchange
+		mockError      error
+		expectedStatus int
+	}{
+		{
+			name:           "started",
+			mockReturn:     &database.FlightExchange{ID: uuid.New(), Status: database.FlightExchangePending},
+			expectedStatus: http.StatusAccepted,
+		},
+		{
+			name:           "change in progress",
+			mockError:      fmt.Errorf("%w: a seat or flight change is already in progress", database.ErrOrderNotModifiable),
+			expectedStatus: http.StatusConflict,
+		},
+		{
+			name:           "different route",
+			mockError:      fmt.Errorf("%w: flight FL200 does not fly JFK to LAX", service.ErrInvalidInput),
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "order not found",
+			mockError:      database.ErrNotFound,
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			name:           "workflow not started",
+			mockError:      errors.New("failed to start flight exchange workflow"),
+			expectedStatus: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(mocks.MockService)
+			handler := NewHandler(mockService)
+			router := setupTestRouter(handler)
+
+			mockService.On("ExchangeFlight", mock.Anything, orderID, exchangeReq).Return(tt.mockReturn, tt.mockError)
+
+			body, _ := json.Marshal(exchangeReq)
+			req := httptest.NewRequest(http.MethodPost, "/api/orders/"+orderID+"/flight-exchanges", bytes.NewReader(body))
+			rec := httptest.NewRecorder()
+
+			router.ServeHTTP(rec, req)
+
+			assert.Equal(t, tt.expectedStatus, rec.Code)
+			mockService.AssertExpectations(t)
+		})
+	}
+}
@@ -4,9 +4,13 @@ import (
 	"encoding/json"
 	"errors"
 	"net/http"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/cx-tal-miterani/flight-booking-system/api-server/internal/database"
 	"github.com/cx-tal-miterani/flight-booking-system/api-server/internal/service"
+	"github.com/google/uuid"
 	"github.com/gorilla/mux"
 )
 
@@ -35,13 +39,157 @@ func respondError(w http.ResponseWriter, status int, message string) {
 }
 
 // GetFlights handles GET /api/flights
+//
+// Supported query parameters: origin, destination, departureFrom, departureTo
+// (RFC 3339 or YYYY-MM-DD), maxPrice, minSeats, seatClass, sort (departure,
+// arrival, price, duration), order (asc, desc), cursor and limit. When more
+// results are available the cursor for the next page is returned in the
+// X-Next-Cursor header.
 func (h *Handler) GetFlights(w http.ResponseWriter, r *http.Request) {
-	flights, err := h.service.GetFlights(r.Context())
+	q := r.URL.Query()
+	req := service.FlightSearchRequest{
+		Origin:      q.Get("origin"),
+		Destination: q.Get("destination"),
+		SeatClass:   q.Get("seatClass"),
+		Sort:        q.Get("sort"),
+		Order:       q.Get("order"),
+		Cursor:      q.Get("cursor"),
+	}
+
+	var err error
+	if req.DepartureFrom, err = parseTimeParam(q.Get("departureFrom"), false); err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid departureFrom")
+		return
+	}
+	if req.DepartureTo, err = parseTimeParam(q.Get("departureTo"), true); err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid departureTo")
+		return
+	}
+	if v := q.Get("maxPrice"); v != "" {
+		price, err := strconv.ParseFloat(v, 64)
+		if err != nil || price < 0 {
+			respondError(w, http.StatusBadRequest, "Invalid maxPrice")
+			return
+		}
+		req.MaxPrice = &price
+	}
+	if req.MinSeats, err = parseIntParam(q.Get("minSeats")); err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid minSeats")
+		return
+	}
+	if req.Limit, err = parseIntParam(q.Get("limit")); err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid limit")
+		return
+	}
+
+	result, err := h.service.GetFlights(r.Context(), req)
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidInput) {
+			respondError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		respondError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if result.NextCursor != "" {
+		w.Header().Set("X-Next-Cursor", result.NextCursor)
+	}
+	respondJSON(w, http.StatusOK, result.Flights)
+}
+
+// SearchItineraries handles GET /api/itineraries
+//
+// Supported query parameters: origin, destination, departureFrom, departureTo,
+// maxStops (0-2), minConnectionMinutes, maxConnectionMinutes, minSeats,
+// sort (duration, price) and limit.
+func (h *Handler) SearchItineraries(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	req := service.ItinerarySearchRequest{
+		Origin:      q.Get("origin"),
+		Destination: q.Get("destination"),
+		Sort:        q.Get("sort"),
+	}
+
+	var err error
+	if req.DepartureFrom, err = parseTimeParam(q.Get("departureFrom"), false); err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid departureFrom")
+		return
+	}
+	if req.DepartureTo, err = parseTimeParam(q.Get("departureTo"), true); err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid departureTo")
+		return
+	}
+	if v := q.Get("maxStops"); v != "" {
+		stops, err := parseIntParam(v)
+		if err != nil {
+			respondError(w, http.StatusBadRequest, "Invalid maxStops")
+			return
+		}
+		req.MaxStops = &stops
+	}
+	minConnection, err := parseIntParam(q.Get("minConnectionMinutes"))
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid minConnectionMinutes")
+		return
+	}
+	maxConnection, err := parseIntParam(q.Get("maxConnectionMinutes"))
 	if err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid maxConnectionMinutes")
+		return
+	}
+	req.MinConnection = time.Duration(minConnection) * time.Minute
+	req.MaxConnection = time.Duration(maxConnection) * time.Minute
+	if req.MinSeats, err = parseIntParam(q.Get("minSeats")); err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid minSeats")
+		return
+	}
+	if req.Limit, err = parseIntParam(q.Get("limit")); err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid limit")
+		return
+	}
+
+	itineraries, err := h.service.SearchItineraries(r.Context(), req)
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidInput) {
+			respondError(w, http.StatusBadRequest, err.Error())
+			return
+		}
 		respondError(w, http.StatusInternalServerError, err.Error())
 		return
 	}
-	respondJSON(w, http.StatusOK, flights)
+	respondJSON(w, http.StatusOK, itineraries)
+}
+
+// parseTimeParam parses an RFC 3339 timestamp or a YYYY-MM-DD date. A bare
+// date used as an upper bound is moved to the start of the following day so
+// the whole day is included.
+func parseTimeParam(value string, upperBound bool) (*time.Time, error) {
+	if value == "" {
+		return nil, nil
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return &t, nil
+	}
+	t, err := time.Parse("2006-01-02", value)
+	if err != nil {
+		return nil, err
+	}
+	if upperBound {
+		t = t.AddDate(0, 0, 1)
+	}
+	return &t, nil
+}
+
+// parseIntParam parses an optional non-negative integer query parameter
+func parseIntParam(value string) (int, error) {
+	if value == "" {
+		return 0, nil
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil || n < 0 {
+		return 0, errors.New("invalid integer")
+	}
+	return n, nil
 }
 
 // GetFlight handles GET /api/flights/{id}
@@ -62,16 +210,75 @@ func (h *Handler) GetFlight(w http.ResponseWriter, r *http.Request) {
 }
 
 // GetFlightSeats handles GET /api/flights/{id}/seats
+//
+// The optional attributes parameter is a comma-separated list of seat
+// attributes (e.g. window,extra_legroom); only seats with all of them are
+// returned.
 func (h *Handler) GetFlightSeats(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	flightID := vars["id"]
 
-	seats, err := h.service.GetFlightSeats(r.Context(), flightID)
+	var attributes []string
+	if v := r.URL.Query().Get("attributes"); v != "" {
+		attributes = strings.Split(v, ",")
+	}
+
+	seatMap, err := h.service.GetSeatMap(r.Context(), flightID, attributes)
 	if err != nil {
+		if errors.Is(err, database.ErrNotFound) {
+			respondError(w, http.StatusNotFound, "Flight not found")
+			return
+		}
+		if errors.Is(err, service.ErrInvalidInput) {
+			respondError(w, http.StatusBadRequest, err.Error())
+			return
+		}
 		respondError(w, http.StatusInternalServerError, err.Error())
 		return
 	}
-	respondJSON(w, http.StatusOK, seats)
+	respondJSON(w, http.StatusOK, seatMap)
+}
+
+// GetSeatAttributes handles GET /api/seat-attributes
+func (h *Handler) GetSeatAttributes(w http.ResponseWriter, r *http.Request) {
+	surcharges, err := h.service.GetSeatAttributeSurcharges(r.Context())
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	respondJSON(w, http.StatusOK, surcharges)
+}
+
+// GetAirports handles GET /api/airports
+//
+// The optional q parameter matches IATA/ICAO codes exactly and city or
+// airport names partially.
+func (h *Handler) GetAirports(w http.ResponseWriter, r *http.Request) {
+	airports, err := h.service.GetAirports(r.Context(), r.URL.Query().Get("q"))
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	respondJSON(w, http.StatusOK, airports)
+}
+
+// GetAirport handles GET /api/airports/{code}
+func (h *Handler) GetAirport(w http.ResponseWriter, r *http.Request) {
+	code := mux.Vars(r)["code"]
+
+	airport, err := h.service.GetAirport(r.Context(), code)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrInvalidInput):
+			respondError(w, http.StatusBadRequest, err.Error())
+		case errors.Is(err, database.ErrNotFound):
+			respondError(w, http.StatusNotFound, "Airport not found")
+		default:
+			respondError(w, http.StatusInternalServerError, err.Error())
+		}
+		return
+	}
+	respondJSON(w, http.StatusOK, airport)
 }
 
 // CreateOrder handles POST /api/orders
@@ -82,13 +289,38 @@ func (h *Handler) CreateOrder(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if req.FlightID == "" || req.CustomerName == "" || req.CustomerEmail == "" {
+	// Orders placed while signed in belong to the account and default to
+	// its name and email; guests give their own
+	customer, err := h.currentCustomer(r)
+	if err != nil {
+		if errors.Is(err, service.ErrUnauthorized) {
+			respondUnauthorized(w, "Session expired, please sign in again")
+			return
+		}
+		respondError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if customer != nil {
+		req.CustomerID = &customer.ID
+		if req.CustomerName == "" {
+			req.CustomerName = customer.Name
+		}
+		if req.CustomerEmail == "" {
+			req.CustomerEmail = customer.Email
+		}
+	}
+
+	if (req.FlightID == "" && len(req.FlightIDs) == 0) || req.CustomerName == "" || req.CustomerEmail == "" {
 		respondError(w, http.StatusBadRequest, "Missing required fields")
 		return
 	}
 
 	order, err := h.service.CreateOrder(r.Context(), req)
 	if err != nil {
+		if errors.Is(err, service.ErrInvalidInput) {
+			respondError(w, http.StatusBadRequest, err.Error())
+			return
+		}
 		respondError(w, http.StatusInternalServerError, err.Error())
 		return
 	}
@@ -112,9 +344,13 @@ func (h *Handler) GetOrder(w http.ResponseWriter, r *http.Request) {
 	respondJSON(w, http.StatusOK, status)
 }
 
-// SelectSeatsRequest represents the request body for seat selection
+// SelectSeatsRequest represents the request body for seat selection. One of
+// SeatIDs, AutoAssign and Overbook is given; with AutoAssign the seats are
+// picked for the party, with Overbook the party is booked without seats.
 type SelectSeatsRequest struct {
-	SeatIDs []string `json:"seatIds"`
+	SeatIDs    []string                   `json:"seatIds"`
+	AutoAssign *service.AutoAssignRequest `json:"autoAssign,omitempty"`
+	Overbook   *service.OverbookRequest   `json:"overbook,omitempty"`
 }
 
 // SelectSeats handles POST /api/orders/{id}/seats
@@ -128,15 +364,44 @@ func (h *Handler) SelectSeats(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if len(req.SeatIDs) == 0 {
+	var status *service.OrderStatusResponse
+	var err error
+	switch {
+	case req.Overbook != nil && (req.AutoAssign != nil || len(req.SeatIDs) > 0):
+		respondError(w, http.StatusBadRequest, "Specify only one of seatIds, autoAssign or overbook")
+		return
+	case req.Overbook != nil:
+		status, err = h.service.OverbookSeats(r.Context(), orderID, *req.Overbook)
+		if err != nil {
+			respondOverbookingError(w, err)
+			return
+		}
+	case req.AutoAssign != nil && len(req.SeatIDs) > 0:
+		respondError(w, http.StatusBadRequest, "Specify either seatIds or autoAssign")
+		return
+	case req.AutoAssign != nil:
+		status, err = h.service.AutoAssignSeats(r.Context(), orderID, *req.AutoAssign)
+		if errors.Is(err, database.ErrSeatNotAvailable) {
+			respondSeatsUnavailable(w, err, err.Error())
+			return
+		}
+	case len(req.SeatIDs) == 0:
 		respondError(w, http.StatusBadRequest, "No seats selected")
 		return
+	default:
+		status, err = h.service.SelectSeats(r.Context(), orderID, req.SeatIDs)
 	}
-
-	status, err := h.service.SelectSeats(r.Context(), orderID, req.SeatIDs)
 	if err != nil {
 		if errors.Is(err, database.ErrSeatNotAvailable) {
-			respondError(w, http.StatusConflict, "One or more seats are not available")
+			respondSeatsUnavailable(w, err, "One or more seats are not available")
+			return
+		}
+		if errors.Is(err, database.ErrOrderNotModifiable) {
+			respondError(w, http.StatusConflict, err.Error())
+			return
+		}
+		if errors.Is(err, service.ErrInvalidInput) {
+			respondError(w, http.StatusBadRequest, err.Error())
 			return
 		}
 		respondError(w, http.StatusInternalServerError, err.Error())
@@ -145,6 +410,51 @@ func (h *Handler) SelectSeats(w http.ResponseWriter, r *http.Request) {
 	respondJSON(w, http.StatusOK, status)
 }
 
+// ChangeSeats handles PATCH /api/orders/{id}/seats
+func (h *Handler) ChangeSeats(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	orderID := vars["id"]
+
+	var req service.ChangeSeatsRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	resp, err := h.service.ChangeSeats(r.Context(), orderID, req)
+	if err != nil {
+		switch {
+		case errors.Is(err, database.ErrNotFound):
+			respondError(w, http.StatusNotFound, "Order not found")
+		case errors.Is(err, database.ErrOrderNotModifiable):
+			respondError(w, http.StatusConflict, err.Error())
+		case errors.Is(err, service.ErrInvalidInput):
+			respondError(w, http.StatusBadRequest, err.Error())
+		default:
+			respondError(w, http.StatusInternalServerError, err.Error())
+		}
+		return
+	}
+	respondJSON(w, http.StatusOK, resp)
+}
+
+// seatsUnavailableResponse is the body of a 409 Conflict response to a seat
+// selection, listing the requested seats that could not be held
+type seatsUnavailableResponse struct {
+	Error              string      `json:"error"`
+	UnavailableSeatIDs []uuid.UUID `json:"unavailableSeatIds,omitempty"`
+}
+
+// respondSeatsUnavailable responds 409 Conflict to a seat selection
+func respondSeatsUnavailable(w http.ResponseWriter, err error, message string) {
+	resp := seatsUnavailableResponse{Error: message}
+	var unavailable *database.SeatsUnavailableError
+	if errors.As(err, &unavailable) {
+		resp.UnavailableSeatIDs = unavailable.SeatIDs
+	}
+	respondJSON(w, http.StatusConflict, resp)
+}
+
 // PaymentRequest represents the request body for payment
 type PaymentRequest struct {
 	PaymentCode string `json:"paymentCode"`
@@ -172,6 +482,10 @@ func (h *Handler) SubmitPayment(w http.ResponseWriter, r *http.Request) {
 			respondError(w, http.StatusGone, "Reservation has expired")
 			return
 		}
+		if errors.Is(err, service.ErrInvalidInput) {
+			respondError(w, http.StatusBadRequest, err.Error())
+			return
+		}
 		respondError(w, http.StatusInternalServerError, err.Error())
 		return
 	}
@@ -189,6 +503,10 @@ func (h *Handler) CancelOrder(w http.ResponseWriter, r *http.Request) {
 			respondError(w, http.StatusNotFound, "Order not found")
 			return
 		}
+		if errors.Is(err, database.ErrOrderNotModifiable) {
+			respondError(w, http.StatusConflict, err.Error())
+			return
+		}
 		respondError(w, http.StatusInternalServerError, err.Error())
 		return
 	}
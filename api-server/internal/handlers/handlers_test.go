@@ -3,9 +3,12 @@ package handlers
 import (
 	"bytes"
 	"encoding/json"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
 	"testing"
+	"time"
 
 	"github.com/cx-tal-miterani/flight-booking-system/api-server/internal/database"
 	"github.com/cx-tal-miterani/flight-booking-system/api-server/internal/service"
@@ -23,11 +26,54 @@ func setupTestRouter(h *Handler) *mux.Router {
 	api.HandleFunc("/flights", h.GetFlights).Methods(http.MethodGet)
 	api.HandleFunc("/flights/{id}", h.GetFlight).Methods(http.MethodGet)
 	api.HandleFunc("/flights/{id}/seats", h.GetFlightSeats).Methods(http.MethodGet)
+	api.HandleFunc("/seat-attributes", h.GetSeatAttributes).Methods(http.MethodGet)
+	api.HandleFunc("/flights/{id}/waitlist", h.JoinWaitlist).Methods(http.MethodPost)
+	api.HandleFunc("/waitlist/{id}", h.GetWaitlistEntry).Methods(http.MethodGet)
+	api.HandleFunc("/waitlist/{id}", h.LeaveWaitlist).Methods(http.MethodDelete)
+	api.HandleFunc("/itineraries", h.SearchItineraries).Methods(http.MethodGet)
+	api.HandleFunc("/airports", h.GetAirports).Methods(http.MethodGet)
+	api.HandleFunc("/airports/{code}", h.GetAirport).Methods(http.MethodGet)
+	api.HandleFunc("/auth/register", h.Register).Methods(http.MethodPost)
+	api.HandleFunc("/auth/login", h.Login).Methods(http.MethodPost)
+	api.HandleFunc("/auth/logout", h.Logout).Methods(http.MethodPost)
+	api.HandleFunc("/me", h.GetMe).Methods(http.MethodGet)
+	api.HandleFunc("/me/orders", h.GetMyOrders).Methods(http.MethodGet)
 	api.HandleFunc("/orders", h.CreateOrder).Methods(http.MethodPost)
 	api.HandleFunc("/orders/{id}", h.GetOrder).Methods(http.MethodGet)
 	api.HandleFunc("/orders/{id}", h.CancelOrder).Methods(http.MethodDelete)
 	api.HandleFunc("/orders/{id}/seats", h.SelectSeats).Methods(http.MethodPost)
+	api.HandleFunc("/orders/{id}/seats", h.ChangeSeats).Methods(http.MethodPatch)
 	api.HandleFunc("/orders/{id}/pay", h.SubmitPayment).Methods(http.MethodPost)
+	api.HandleFunc("/orders/{id}/passengers", h.SetPassengers).Methods(http.MethodPut)
+	api.HandleFunc("/orders/{id}/check-in", h.CheckIn).Methods(http.MethodPost)
+	api.HandleFunc("/orders/{id}/seat-exchanges", h.ExchangeSeats).Methods(http.MethodPost)
+	api.HandleFunc("/orders/{id}/flight-exchanges", h.ExchangeFlight).Methods(http.MethodPost)
+	api.HandleFunc("/orders/{id}/cancellation", h.CancelBooking).Methods(http.MethodPost)
+	api.HandleFunc("/orders/{id}/rebooking", h.GetRebookingOffer).Methods(http.MethodGet)
+	api.HandleFunc("/orders/{id}/rebooking", h.RespondToRebooking).Methods(http.MethodPost)
+	api.HandleFunc("/bookings/{reference}", h.GetBooking).Methods(http.MethodGet)
+	api.HandleFunc("/admin/flights", h.AdminCreateFlight).Methods(http.MethodPost)
+	api.HandleFunc("/admin/flights/{id}", h.AdminUpdateFlight).Methods(http.MethodPatch)
+	api.HandleFunc("/admin/flights/{id}", h.AdminDeleteFlight).Methods(http.MethodDelete)
+	api.HandleFunc("/admin/flights/{id}/status", h.AdminUpdateFlightStatus).Methods(http.MethodPut)
+	api.HandleFunc("/admin/orders", h.AdminSearchOrders).Methods(http.MethodGet)
+	api.HandleFunc("/admin/orders/{id}/events", h.AdminGetOrderEvents).Methods(http.MethodGet)
+	api.HandleFunc("/admin/flights/{id}/denied-boarding", h.AdminGetDeniedBoardingReport).Methods(http.MethodGet)
+	api.HandleFunc("/admin/flights/{id}/seat-blocks", h.AdminGetSeatBlocks).Methods(http.MethodGet)
+	api.HandleFunc("/admin/flights/{id}/seat-blocks", h.AdminBlockSeats).Methods(http.MethodPost)
+	api.HandleFunc("/admin/flights/{id}/seat-blocks", h.AdminUnblockSeats).Methods(http.MethodDelete)
+	api.HandleFunc("/admin/overbooking-policies", h.AdminGetOverbookingPolicies).Methods(http.MethodGet)
+	api.HandleFunc("/admin/overbooking-policies", h.AdminSaveOverbookingPolicy).Methods(http.MethodPut)
+	api.HandleFunc("/admin/overbooking-policies/{id}", h.AdminDeleteOverbookingPolicy).Methods(http.MethodDelete)
+	api.HandleFunc("/admin/cabin-layouts", h.AdminGetCabinLayouts).Methods(http.MethodGet)
+	api.HandleFunc("/admin/cabin-layouts", h.AdminCreateCabinLayout).Methods(http.MethodPost)
+	api.HandleFunc("/admin/aircraft-types", h.AdminGetAircraftTypes).Methods(http.MethodGet)
+	api.HandleFunc("/admin/aircraft-types", h.AdminCreateAircraftType).Methods(http.MethodPost)
+	api.HandleFunc("/admin/seat-attributes/{attribute}", h.AdminUpdateSeatAttributeSurcharge).Methods(http.MethodPut)
+	api.HandleFunc("/admin/schedules", h.AdminGetFlightSchedules).Methods(http.MethodGet)
+	api.HandleFunc("/admin/schedules", h.AdminCreateFlightSchedule).Methods(http.MethodPost)
+	api.HandleFunc("/admin/schedules/{id}", h.AdminDeleteFlightSchedule).Methods(http.MethodDelete)
+	api.HandleFunc("/admin/imports/ssim", h.AdminImportSSIM).Methods(http.MethodPost)
 	return r
 }
 
@@ -48,7 +94,8 @@ func TestHandler_GetFlights(t *testing.T) {
 		},
 	}
 
-	mockService.On("GetFlights", mock.Anything).Return(expectedFlights, nil)
+	mockService.On("GetFlights", mock.Anything, service.FlightSearchRequest{}).
+		Return(&service.FlightSearchResponse{Flights: expectedFlights}, nil)
 
 	req := httptest.NewRequest(http.MethodGet, "/api/flights", nil)
 	rec := httptest.NewRecorder()
@@ -62,10 +109,232 @@ func TestHandler_GetFlights(t *testing.T) {
 	require.NoError(t, err)
 	assert.Len(t, response, 1)
 	assert.Equal(t, "AA123", response[0].FlightNumber)
+	assert.Empty(t, rec.Header().Get("X-Next-Cursor"))
 
 	mockService.AssertExpectations(t)
 }
 
+func TestHandler_GetFlights_Search(t *testing.T) {
+	mockService := new(mocks.MockService)
+	handler := NewHandler(mockService)
+	router := setupTestRouter(handler)
+
+	from := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2024, 6, 3, 0, 0, 0, 0, time.UTC)
+	maxPrice := 300.0
+	expectedReq := service.FlightSearchRequest{
+		Origin:        "JFK",
+		Destination:   "LAX",
+		DepartureFrom: &from,
+		DepartureTo:   &to,
+		MaxPrice:      &maxPrice,
+		MinSeats:      2,
+		SeatClass:     "business",
+		Sort:          "price",
+		Order:         "desc",
+		Cursor:        "abc",
+		Limit:         10,
+	}
+
+	mockService.On("GetFlights", mock.Anything, expectedReq).
+		Return(&service.FlightSearchResponse{Flights: []database.Flight{}, NextCursor: "next-page"}, nil)
+
+	query := url.Values{
+		"origin":        {"JFK"},
+		"destination":   {"LAX"},
+		"departureFrom": {"2024-06-01"},
+		"departureTo":   {"2024-06-02"},
+		"maxPrice":      {"300"},
+		"minSeats":      {"2"},
+		"seatClass":     {"business"},
+		"sort":          {"price"},
+		"order":         {"desc"},
+		"cursor":        {"abc"},
+		"limit":         {"10"},
+	}
+	req := httptest.NewRequest(http.MethodGet, "/api/flights?"+query.Encode(), nil)
+	rec := httptest.NewRecorder()
+
+	router.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "next-page", rec.Header().Get("X-Next-Cursor"))
+	mockService.AssertExpectations(t)
+}
+
+func TestHandler_GetFlights_InvalidParams(t *testing.T) {
+	tests := []struct {
+		name           string
+		query          string
+		mockError      error
+		shouldCallMock bool
+	}{
+		{name: "invalid departure date", query: "departureFrom=tomorrow"},
+		{name: "invalid max price", query: "maxPrice=cheap"},
+		{name: "negative min seats", query: "minSeats=-1"},
+		{name: "invalid limit", query: "limit=ten"},
+		{
+			name:           "rejected by service",
+			query:          "sort=altitude",
+			mockError:      fmt.Errorf("%w: unknown sort", service.ErrInvalidInput),
+			shouldCallMock: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(mocks.MockService)
+			handler := NewHandler(mockService)
+			router := setupTestRouter(handler)
+
+			if tt.shouldCallMock {
+				mockService.On("GetFlights", mock.Anything, mock.Anything).Return(nil, tt.mockError)
+			}
+
+			req := httptest.NewRequest(http.MethodGet, "/api/flights?"+tt.query, nil)
+			rec := httptest.NewRecorder()
+
+			router.ServeHTTP(rec, req)
+
+			assert.Equal(t, http.StatusBadRequest, rec.Code)
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestHandler_SearchItineraries(t *testing.T) {
+	oneStop := 1
+	tests := []struct {
+		name           string
+		query          string
+		expectedReq    *service.ItinerarySearchRequest
+		mockError      error
+		expectedStatus int
+	}{
+		{
+			name:  "valid search",
+			query: "origin=BOS&destination=LAX&maxStops=1&minConnectionMinutes=60&maxConnectionMinutes=240&sort=price",
+			expectedReq: &service.ItinerarySearchRequest{
+				Origin:        "BOS",
+				Destination:   "LAX",
+				MaxStops:      &oneStop,
+				MinConnection: time.Hour,
+				MaxConnection: 4 * time.Hour,
+				Sort:          "price",
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "invalid max stops",
+			query:          "origin=BOS&destination=LAX&maxStops=many",
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "rejected by service",
+			query:          "origin=BOS",
+			expectedReq:    &service.ItinerarySearchRequest{Origin: "BOS"},
+			mockError:      fmt.Errorf("%w: origin and destination are required", service.ErrInvalidInput),
+			expectedStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(mocks.MockService)
+			handler := NewHandler(mockService)
+			router := setupTestRouter(handler)
+
+			if tt.expectedReq != nil {
+				var result []service.Itinerary
+				if tt.mockError == nil {
+					result = []service.Itinerary{}
+				}
+				mockService.On("SearchItineraries", mock.Anything, *tt.expectedReq).Return(result, tt.mockError)
+			}
+
+			req := httptest.NewRequest(http.MethodGet, "/api/itineraries?"+tt.query, nil)
+			rec := httptest.NewRecorder()
+
+			router.ServeHTTP(rec, req)
+
+			assert.Equal(t, tt.expectedStatus, rec.Code)
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestHandler_GetAirports(t *testing.T) {
+	mockService := new(mocks.MockService)
+	handler := NewHandler(mockService)
+	router := setupTestRouter(handler)
+
+	airports := []database.Airport{
+		{IATACode: "JFK", ICAOCode: "KJFK", City: "New York", Country: "US", TimeZone: "America/New_York"},
+	}
+	mockService.On("GetAirports", mock.Anything, "new york").Return(airports, nil)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/airports?q=new+york", nil)
+	rec := httptest.NewRecorder()
+
+	router.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	var response []database.Airport
+	err := json.Unmarshal(rec.Body.Bytes(), &response)
+	assert.NoError(t, err)
+	assert.Equal(t, airports, response)
+
+	mockService.AssertExpectations(t)
+}
+
+func TestHandler_GetAirport(t *testing.T) {
+	tests := []struct {
+		name           string
+		code           string
+		mockReturn     *database.Airport
+		mockError      error
+		expectedStatus int
+	}{
+		{
+			name:           "airport found",
+			code:           "JFK",
+			mockReturn:     &database.Airport{IATACode: "JFK", ICAOCode: "KJFK", TimeZone: "America/New_York"},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "airport not found",
+			code:           "XXX",
+			mockError:      database.ErrNotFound,
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			name:           "invalid code",
+			code:           "J1",
+			mockError:      fmt.Errorf("%w: invalid airport code", service.ErrInvalidInput),
+			expectedStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(mocks.MockService)
+			handler := NewHandler(mockService)
+			router := setupTestRouter(handler)
+
+			mockService.On("GetAirport", mock.Anything, tt.code).Return(tt.mockReturn, tt.mockError)
+
+			req := httptest.NewRequest(http.MethodGet, "/api/airports/"+tt.code, nil)
+			rec := httptest.NewRecorder()
+
+			router.ServeHTTP(rec, req)
+
+			assert.Equal(t, tt.expectedStatus, rec.Code)
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
 func TestHandler_GetFlight(t *testing.T) {
 	flightID := uuid.New()
 
@@ -114,6 +383,115 @@ func TestHandler_GetFlight(t *testing.T) {
 	}
 }
 
+func TestHandler_GetFlightSeats(t *testing.T) {
+	flightID := uuid.New()
+	seatID := uuid.New()
+	seatMap := &database.SeatMap{
+		FlightID:    flightID,
+		CabinLayout: "regional",
+		Cabins: []database.SeatMapCabin{{
+			Class:        "business",
+			ColumnGroups: []string{"A", "CD"},
+			Rows:         []database.SeatMapRow{{Row: 1, Seats: []*uuid.UUID{&seatID, nil, nil}}},
+		}},
+		Seats: []database.Seat{{ID: seatID, SeatNumber: "1A", RowNumber: 1, ColumnLetter: "A", Class: "business"}},
+	}
+
+	t.Run("seat map", func(t *testing.T) {
+		mockService := new(mocks.MockService)
+		router := setupTestRouter(NewHandler(mockService))
+		mockService.On("GetSeatMap", mock.Anything, flightID.String(), []string(nil)).Return(seatMap, nil)
+
+		req := httptest.NewRequest(http.MethodGet, "/api/flights/"+flightID.String()+"/seats", nil)
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+
+		require.Equal(t, http.StatusOK, rec.Code)
+		var body struct {
+			CabinLayout string `json:"cabinLayout"`
+			Cabins      []struct {
+				ColumnGroups []string `json:"columnGroups"`
+				Rows         []struct {
+					Row   int       `json:"row"`
+					Seats []*string `json:"seats"`
+				} `json:"rows"`
+			} `json:"cabins"`
+			Seats []database.Seat `json:"seats"`
+		}
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+		assert.Equal(t, "regional", body.CabinLayout)
+		require.Len(t, body.Cabins, 1)
+		assert.Equal(t, []string{"A", "CD"}, body.Cabins[0].ColumnGroups)
+		row := body.Cabins[0].Rows[0]
+		require.Len(t, row.Seats, 3)
+		assert.Equal(t, seatID.String(), *row.Seats[0])
+		assert.Nil(t, row.Seats[1])
+		assert.Len(t, body.Seats, 1)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("attribute filter", func(t *testing.T) {
+		mockService := new(mocks.MockService)
+		router := setupTestRouter(NewHandler(mockService))
+		mockService.On("GetSeatMap", mock.Anything, flightID.String(), []string{"window", "exit_row"}).Return(seatMap, nil)
+
+		req := httptest.NewRequest(http.MethodGet, "/api/flights/"+flightID.String()+"/seats?attributes=window,exit_row", nil)
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("unknown attribute", func(t *testing.T) {
+		mockService := new(mocks.MockService)
+		router := setupTestRouter(NewHandler(mockService))
+		mockService.On("GetSeatMap", mock.Anything, flightID.String(), []string{"bulkhead"}).
+			Return(nil, fmt.Errorf("%w: unknown seat attribute", service.ErrInvalidInput))
+
+		req := httptest.NewRequest(http.MethodGet, "/api/flights/"+flightID.String()+"/seats?attributes=bulkhead", nil)
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("flight not found", func(t *testing.T) {
+		mockService := new(mocks.MockService)
+		router := setupTestRouter(NewHandler(mockService))
+		mockService.On("GetSeatMap", mock.Anything, flightID.String(), []string(nil)).Return(nil, database.ErrNotFound)
+
+		req := httptest.NewRequest(http.MethodGet, "/api/flights/"+flightID.String()+"/seats", nil)
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+		mockService.AssertExpectations(t)
+	})
+}
+
+func TestHandler_GetSeatAttributes(t *testing.T) {
+	mockService := new(mocks.MockService)
+	router := setupTestRouter(NewHandler(mockService))
+	mockService.On("GetSeatAttributeSurcharges", mock.Anything).Return([]database.SeatAttributeSurcharge{
+		{Attribute: "exit_row", Surcharge: 20},
+		{Attribute: "window", Surcharge: 0},
+	}, nil)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/seat-attributes", nil)
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	require.Equal(t, http.StatusOK, rec.Code)
+	var body []database.SeatAttributeSurcharge
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+	require.Len(t, body, 2)
+	assert.Equal(t, "exit_row", body[0].Attribute)
+	assert.Equal(t, 20.0, body[0].Surcharge)
+	mockService.AssertExpectations(t)
+}
+
 func TestHandler_CreateOrder(t *testing.T) {
 	flightID := uuid.New()
 	orderID := uuid.New()
@@ -144,6 +522,34 @@ func TestHandler_CreateOrder(t *testing.T) {
 			expectedStatus: http.StatusCreated,
 			shouldCallMock: true,
 		},
+		{
+			name: "valid round trip order",
+			requestBody: service.CreateOrderRequest{
+				FlightIDs:     []string{flightID.String(), uuid.New().String()},
+				CustomerEmail: "test@example.com",
+				CustomerName:  "John Doe",
+			},
+			mockReturn: &database.Order{
+				ID:       orderID,
+				FlightID: flightID,
+				Status:   database.OrderStatusPending,
+			},
+			mockError:      nil,
+			expectedStatus: http.StatusCreated,
+			shouldCallMock: true,
+		},
+		{
+			name: "flights out of order",
+			requestBody: service.CreateOrderRequest{
+				FlightIDs:     []string{flightID.String(), uuid.New().String()},
+				CustomerEmail: "test@example.com",
+				CustomerName:  "John Doe",
+			},
+			mockReturn:     nil,
+			mockError:      fmt.Errorf("%w: flight departs before previous flight arrives", service.ErrInvalidInput),
+			expectedStatus: http.StatusBadRequest,
+			shouldCallMock: true,
+		},
 		{
 			name: "missing flight ID",
 			requestBody: service.CreateOrderRequest{
@@ -193,6 +599,7 @@ func TestHandler_CreateOrder(t *testing.T) {
 
 func TestHandler_SelectSeats(t *testing.T) {
 	orderID := uuid.New()
+	takenSeatID := uuid.New()
 
 	tests := []struct {
 		name           string
@@ -201,6 +608,7 @@ func TestHandler_SelectSeats(t *testing.T) {
 		mockReturn     *service.OrderStatusResponse
 		mockError      error
 		expectedStatus int
+		expectedTaken  []uuid.UUID
 		shouldCallMock bool
 	}{
 		{
@@ -217,6 +625,27 @@ func TestHandler_SelectSeats(t *testing.T) {
 			expectedStatus: http.StatusOK,
 			shouldCallMock: true,
 		},
+		{
+			name:    "seats taken",
+			orderID: orderID.String(),
+			requestBody: SelectSeatsRequest{
+				SeatIDs: []string{"seat-1", "seat-2"},
+			},
+			mockError:      fmt.Errorf("failed to hold seats: %w", &database.SeatsUnavailableError{SeatIDs: []uuid.UUID{takenSeatID}}),
+			expectedStatus: http.StatusConflict,
+			expectedTaken:  []uuid.UUID{takenSeatID},
+			shouldCallMock: true,
+		},
+		{
+			name:    "order already confirmed",
+			orderID: orderID.String(),
+			requestBody: SelectSeatsRequest{
+				SeatIDs: []string{"seat-1"},
+			},
+			mockError:      fmt.Errorf("failed to hold seats: %w: order is confirmed", database.ErrOrderNotModifiable),
+			expectedStatus: http.StatusConflict,
+			shouldCallMock: true,
+		},
 		{
 			name:    "no seats selected",
 			orderID: orderID.String(),
@@ -249,6 +678,81 @@ func TestHandler_SelectSeats(t *testing.T) {
 			router.ServeHTTP(rec, req)
 
 			assert.Equal(t, tt.expectedStatus, rec.Code)
+			if tt.expectedTaken != nil {
+				var resp seatsUnavailableResponse
+				require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+				assert.Equal(t, tt.expectedTaken, resp.UnavailableSeatIDs)
+			}
+		})
+	}
+}
+
+func TestHandler_SelectSeats_AutoAssign(t *testing.T) {
+	orderID := uuid.New()
+	autoAssign := &service.AutoAssignRequest{PartySize: 4, Class: "economy", Preferences: []string{"window"}}
+
+	tests := []struct {
+		name           string
+		requestBody    SelectSeatsRequest
+		mockReturn     *service.OrderStatusResponse
+		mockError      error
+		expectedStatus int
+		shouldCallMock bool
+	}{
+		{
+			name:        "seats assigned",
+			requestBody: SelectSeatsRequest{AutoAssign: autoAssign},
+			mockReturn: &service.OrderStatusResponse{
+				Order:            &database.Order{ID: orderID, Status: database.OrderStatusSeatsSelected},
+				RemainingSeconds: 900,
+			},
+			expectedStatus: http.StatusOK,
+			shouldCallMock: true,
+		},
+		{
+			name:           "no block available",
+			requestBody:    SelectSeatsRequest{AutoAssign: autoAssign},
+			mockError:      fmt.Errorf("%w: no 4 economy seats together", database.ErrSeatNotAvailable),
+			expectedStatus: http.StatusConflict,
+			shouldCallMock: true,
+		},
+		{
+			name:           "order already confirmed",
+			requestBody:    SelectSeatsRequest{AutoAssign: autoAssign},
+			mockError:      fmt.Errorf("%w: order is confirmed", database.ErrOrderNotModifiable),
+			expectedStatus: http.StatusConflict,
+			shouldCallMock: true,
+		},
+		{
+			name:           "invalid party size",
+			requestBody:    SelectSeatsRequest{AutoAssign: autoAssign},
+			mockError:      fmt.Errorf("%w: party size must be between 1 and 9", service.ErrInvalidInput),
+			expectedStatus: http.StatusBadRequest,
+			shouldCallMock: true,
+		},
+		{
+			name:           "seat IDs and auto-assign",
+			requestBody:    SelectSeatsRequest{SeatIDs: []string{"seat-1"}, AutoAssign: autoAssign},
+			expectedStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(mocks.MockService)
+			router := setupTestRouter(NewHandler(mockService))
+
+			if tt.shouldCallMock {
+				mockService.On("AutoAssignSeats", mock.Anything, orderID.String(), *autoAssign).Return(tt.mockReturn, tt.mockError)
+			}
+
+			body, _ := json.Marshal(tt.requestBody)
+			req := httptest.NewRequest(http.MethodPost, "/api/orders/"+orderID.String()+"/seats", bytes.NewReader(body))
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+
+			assert.Equal(t, tt.expectedStatus, rec.Code)
+			mockService.AssertExpectations(t)
 		})
 	}
 }
@@ -316,6 +820,86 @@ func TestHandler_SubmitPayment(t *testing.T) {
 	}
 }
 
+func TestHandler_ChangeSeats(t *testing.T) {
+	orderID := uuid.New()
+	added := database.SeatChange{SeatID: uuid.New(), SeatNumber: "12A", Operation: "add", Status: database.SeatChangeHeld}
+	taken := database.SeatChange{SeatID: uuid.New(), SeatNumber: "12B", Operation: "add", Status: database.SeatChangeUnavailable}
+
+	tests := []struct {
+		name           string
+		body           string
+		mockReturn     *service.ChangeSeatsResponse
+		mockError      error
+		expectedStatus int
+		expectedCount  int
+	}{
+		{
+			name: "partial success",
+			body: `{"add": ["12A", "12B"]}`,
+			mockReturn: &service.ChangeSeatsResponse{
+				OrderStatusResponse: &service.OrderStatusResponse{Order: &database.Order{ID: orderID}},
+				Results:             []database.SeatChange{added, taken},
+			},
+			expectedStatus: http.StatusOK,
+			expectedCount:  2,
+		},
+		{
+			name:           "order being paid",
+			body:           `{"remove": ["12A"]}`,
+			mockError:      fmt.Errorf("%w: order is processing", database.ErrOrderNotModifiable),
+			expectedStatus: http.StatusConflict,
+		},
+		{
+			name:           "nothing to change",
+			body:           `{}`,
+			mockError:      fmt.Errorf("%w: no seats to add or remove", service.ErrInvalidInput),
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "order not found",
+			body:           `{"add": ["12A"]}`,
+			mockError:      database.ErrNotFound,
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			name:           "invalid body",
+			body:           `{"add": "12A"}`,
+			expectedStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(mocks.MockService)
+			handler := NewHandler(mockService)
+			router := setupTestRouter(handler)
+
+			var req service.ChangeSeatsRequest
+			if json.Unmarshal([]byte(tt.body), &req) == nil {
+				mockService.On("ChangeSeats", mock.Anything, orderID.String(), req).Return(tt.mockReturn, tt.mockError)
+			}
+
+			httpReq := httptest.NewRequest(http.MethodPatch, "/api/orders/"+orderID.String()+"/seats", bytes.NewBufferString(tt.body))
+			rec := httptest.NewRecorder()
+
+			router.ServeHTTP(rec, httpReq)
+
+			assert.Equal(t, tt.expectedStatus, rec.Code)
+			if tt.expectedCount > 0 {
+				var resp struct {
+					Order   *database.Order       `json:"order"`
+					Results []database.SeatChange `json:"results"`
+				}
+				require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+				assert.Equal(t, orderID, resp.Order.ID)
+				assert.Len(t, resp.Results, tt.expectedCount)
+				assert.Equal(t, database.SeatChangeUnavailable, resp.Results[1].Status)
+			}
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
 func TestHandler_CancelOrder(t *testing.T) {
 	orderID := uuid.New()
 
@@ -337,6 +921,12 @@ func TestHandler_CancelOrder(t *testing.T) {
 			mockError:      database.ErrNotFound,
 			expectedStatus: http.StatusNotFound,
 		},
+		{
+			name:           "confirmed order",
+			orderID:        orderID.String(),
+			mockError:      database.ErrOrderNotModifiable,
+			expectedStatus: http.StatusConflict,
+		},
 	}
 
 	for _, tt := range tests {
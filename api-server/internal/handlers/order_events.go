@@ -0,0 +1,30 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/cx-tal-miterani/flight-booking-system/api-server/internal/database"
+	"github.com/cx-tal-miterani/flight-booking-system/api-server/internal/service"
+	"github.com/gorilla/mux"
+)
+
+// AdminGetOrderEvents handles GET /api/admin/orders/{id}/events
+//
+// Returns the order's history for support: status transitions, flight and
+// seat changes, payment attempts and workflow signals, oldest first.
+func (h *Handler) AdminGetOrderEvents(w http.ResponseWriter, r *http.Request) {
+	events, err := h.service.GetOrderEvents(r.Context(), mux.Vars(r)["id"])
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrInvalidInput):
+			respondError(w, http.StatusBadRequest, err.Error())
+		case errors.Is(err, database.ErrNotFound):
+			respondError(w, http.StatusNotFound, "Order not found")
+		default:
+			respondError(w, http.StatusInternalServerError, err.Error())
+		}
+		return
+	}
+	respondJSON(w, http.StatusOK, events)
+}
@@ -0,0 +1,85 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/cx-tal-miterani/flight-booking-system/api-server/internal/database"
+	"github.com/cx-tal-miterani/flight-booking-system/api-server/internal/service"
+	"github.com/cx-tal-miterani/flight-booking-system/api-server/internal/service/mocks"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestHandler_AdminGetOrderEvents(t *testing.T) {
+	orderID := uuid.New()
+	events := []database.OrderEvent{
+		{ID: 1, OrderID: orderID, Type: database.OrderEventCreated, Actor: "api-server",
+			Payload: json.RawMessage(`{"status":"pending"}`)},
+		{ID: 2, OrderID: orderID, Type: database.OrderEventStatusChanged, Actor: "temporal-worker",
+			Payload: json.RawMessage(`{"from":"processing","to":"confirmed"}`)},
+	}
+
+	tests := []struct {
+		name           string
+		orderID        string
+		mockReturn     []database.OrderEvent
+		mockError      error
+		expectedStatus int
+	}{
+		{
+			name:           "history",
+			orderID:        orderID.String(),
+			mockReturn:     events,
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "invalid order ID",
+			orderID:        "not-a-uuid",
+			mockError:      fmt.Errorf("%w: invalid order ID", service.ErrInvalidInput),
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "order not found",
+			orderID:        uuid.New().String(),
+			mockError:      database.ErrNotFound,
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			name:           "database error",
+			orderID:        orderID.String(),
+			mockError:      errors.New("failed to query order events"),
+			expectedStatus: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(mocks.MockService)
+			handler := NewHandler(mockService)
+			router := setupTestRouter(handler)
+
+			mockService.On("GetOrderEvents", mock.Anything, tt.orderID).Return(tt.mockReturn, tt.mockError)
+
+			req := httptest.NewRequest(http.MethodGet, "/api/admin/orders/"+tt.orderID+"/events", nil)
+			rec := httptest.NewRecorder()
+
+			router.ServeHTTP(rec, req)
+
+			assert.Equal(t, tt.expectedStatus, rec.Code)
+			if tt.expectedStatus == http.StatusOK {
+				var got []map[string]interface{}
+				assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &got))
+				assert.Len(t, got, 2)
+				assert.Equal(t, "status_changed", got[1]["type"])
+				assert.Equal(t, map[string]interface{}{"from": "processing", "to": "confirmed"}, got[1]["payload"])
+			}
+			mockService.AssertExpectations(t)
+		})
+	}
+}
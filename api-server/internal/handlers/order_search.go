@@ -0,0 +1,138 @@
+package handlers
+
+import (
+	"encoding/csv"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/cx-tal-miterani/flight-booking-system/api-server/internal/database"
+	"github.com/cx-tal-miterani/flight-booking-system/api-server/internal/service"
+)
+
+// orderCSVHeader lists the columns of the order CSV export
+var orderCSVHeader = []string{
+	"id", "booking_reference", "status", "customer_name", "customer_email", "customer_id",
+	"flights", "seats", "total_amount", "payment_attempts", "created_at", "updated_at",
+}
+
+// AdminSearchOrders handles GET /api/admin/orders
+//
+// Supported query parameters: status (comma-separated order statuses),
+// flightId (any flight of the order), customerEmail (ignoring case), from and
+// to (RFC 3339 or YYYY-MM-DD, on the order's creation time), minAmount,
+// maxAmount, cursor and limit. When more results are available the cursor for
+// the next page is returned in the X-Next-Cursor header. With format=csv all
+// matching orders are exported as CSV instead.
+func (h *Handler) AdminSearchOrders(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	req := service.OrderSearchRequest{
+		FlightID:      q.Get("flightId"),
+		CustomerEmail: q.Get("customerEmail"),
+		Cursor:        q.Get("cursor"),
+	}
+	if v := q.Get("status"); v != "" {
+		req.Statuses = strings.Split(v, ",")
+	}
+
+	var err error
+	if req.CreatedFrom, err = parseTimeParam(q.Get("from"), false); err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid from")
+		return
+	}
+	if req.CreatedTo, err = parseTimeParam(q.Get("to"), true); err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid to")
+		return
+	}
+	if req.MinAmount, err = parseAmountParam(q.Get("minAmount")); err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid minAmount")
+		return
+	}
+	if req.MaxAmount, err = parseAmountParam(q.Get("maxAmount")); err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid maxAmount")
+		return
+	}
+	if req.Limit, err = parseIntParam(q.Get("limit")); err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid limit")
+		return
+	}
+
+	switch q.Get("format") {
+	case "", "json":
+		result, err := h.service.SearchOrders(r.Context(), req)
+		if err != nil {
+			respondOrderSearchError(w, err)
+			return
+		}
+		if result.NextCursor != "" {
+			w.Header().Set("X-Next-Cursor", result.NextCursor)
+		}
+		respondJSON(w, http.StatusOK, result.Orders)
+	case "csv":
+		orders, err := h.service.ExportOrders(r.Context(), req)
+		if err != nil {
+			respondOrderSearchError(w, err)
+			return
+		}
+		respondOrdersCSV(w, orders)
+	default:
+		respondError(w, http.StatusBadRequest, "Invalid format")
+	}
+}
+
+func respondOrderSearchError(w http.ResponseWriter, err error) {
+	if errors.Is(err, service.ErrInvalidInput) {
+		respondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	respondError(w, http.StatusInternalServerError, err.Error())
+}
+
+// respondOrdersCSV sends orders as a CSV attachment
+func respondOrdersCSV(w http.ResponseWriter, orders []database.OrderSummary) {
+	w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+	w.Header().Set("Content-Disposition", `attachment; filename="orders.csv"`)
+	w.WriteHeader(http.StatusOK)
+
+	cw := csv.NewWriter(w)
+	cw.Write(orderCSVHeader)
+	for _, o := range orders {
+		var reference, customerID string
+		if o.BookingReference != nil {
+			reference = *o.BookingReference
+		}
+		if o.CustomerID != nil {
+			customerID = o.CustomerID.String()
+		}
+		cw.Write([]string{
+			o.ID.String(), reference, string(o.Status), csvText(o.CustomerName), csvText(o.CustomerEmail), customerID,
+			strings.Join(o.FlightNumbers, " "), strconv.Itoa(o.SeatCount),
+			strconv.FormatFloat(o.TotalAmount, 'f', 2, 64), strconv.Itoa(o.PaymentAttempts),
+			o.CreatedAt.UTC().Format(time.RFC3339), o.UpdatedAt.UTC().Format(time.RFC3339),
+		})
+	}
+	cw.Flush()
+}
+
+// csvText returns a customer-supplied CSV cell that spreadsheets read as
+// text. Cells that could start a formula are prefixed with a quote.
+func csvText(value string) string {
+	if value != "" && strings.ContainsRune("=+-@\t\r", rune(value[0])) {
+		return "'" + value
+	}
+	return value
+}
+
+// parseAmountParam parses an optional non-negative amount query parameter
+func parseAmountParam(value string) (*float64, error) {
+	if value == "" {
+		return nil, nil
+	}
+	amount, err := strconv.ParseFloat(value, 64)
+	if err != nil || amount < 0 {
+		return nil, errors.New("invalid amount")
+	}
+	return &amount, nil
+}
@@ -0,0 +1,160 @@
+package handlers
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cx-tal-miterani/flight-booking-system/api-server/internal/database"
+	"github.com/cx-tal-miterani/flight-booking-system/api-server/internal/service"
+	"github.com/cx-tal-miterani/flight-booking-system/api-server/internal/service/mocks"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandler_AdminSearchOrders(t *testing.T) {
+	flightID := uuid.New().String()
+	from := time.Date(2026, 6, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2026, 7, 1, 0, 0, 0, 0, time.UTC)
+	minAmount := 100.0
+
+	tests := []struct {
+		name           string
+		query          string
+		expectedReq    *service.OrderSearchRequest
+		mockReturn     *service.OrderSearchResponse
+		mockError      error
+		expectedStatus int
+		expectedCursor string
+	}{
+		{
+			name:  "filtered",
+			query: "?status=confirmed,cancelled&flightId=" + flightID + "&customerEmail=ada@example.com&from=2026-06-01&to=2026-06-30&minAmount=100&limit=10",
+			expectedReq: &service.OrderSearchRequest{
+				Statuses: []string{"confirmed", "cancelled"}, FlightID: flightID, CustomerEmail: "ada@example.com",
+				CreatedFrom: &from, CreatedTo: &to, MinAmount: &minAmount, Limit: 10,
+			},
+			mockReturn: &service.OrderSearchResponse{
+				Orders:     []database.OrderSummary{{ID: uuid.New(), Status: database.OrderStatusConfirmed}},
+				NextCursor: "next",
+			},
+			expectedStatus: http.StatusOK,
+			expectedCursor: "next",
+		},
+		{
+			name:           "last page",
+			query:          "?cursor=abc",
+			expectedReq:    &service.OrderSearchRequest{Cursor: "abc"},
+			mockReturn:     &service.OrderSearchResponse{Orders: []database.OrderSummary{}},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "invalid filter",
+			query:          "?status=shipped",
+			expectedReq:    &service.OrderSearchRequest{Statuses: []string{"shipped"}},
+			mockError:      fmt.Errorf("%w: unknown order status %q", service.ErrInvalidInput, "shipped"),
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "invalid amount",
+			query:          "?maxAmount=lots",
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "invalid format",
+			query:          "?format=xml",
+			expectedStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(mocks.MockService)
+			handler := NewHandler(mockService)
+			router := setupTestRouter(handler)
+
+			if tt.expectedReq != nil {
+				mockService.On("SearchOrders", mock.Anything, *tt.expectedReq).Return(tt.mockReturn, tt.mockError)
+			}
+
+			req := httptest.NewRequest(http.MethodGet, "/api/admin/orders"+tt.query, nil)
+			rec := httptest.NewRecorder()
+
+			router.ServeHTTP(rec, req)
+
+			assert.Equal(t, tt.expectedStatus, rec.Code)
+			assert.Equal(t, tt.expectedCursor, rec.Header().Get("X-Next-Cursor"))
+			if tt.mockReturn != nil {
+				var orders []database.OrderSummary
+				require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &orders))
+				assert.Len(t, orders, len(tt.mockReturn.Orders))
+			}
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestHandler_AdminSearchOrders_CSV(t *testing.T) {
+	mockService := new(mocks.MockService)
+	router := setupTestRouter(NewHandler(mockService))
+
+	reference := "K7QM2X"
+	created := time.Date(2026, 6, 1, 12, 0, 0, 0, time.UTC)
+	orders := []database.OrderSummary{
+		{
+			ID: uuid.New(), BookingReference: &reference, CustomerName: "Lovelace, Ada", CustomerEmail: "ada@example.com",
+			Status: database.OrderStatusConfirmed, TotalAmount: 420.5, FlightNumbers: []string{"FB101", "FB102"},
+			SeatCount: 4, CreatedAt: created, UpdatedAt: created,
+		},
+		{ID: uuid.New(), CustomerName: "Grace Hopper", Status: database.OrderStatusPending, CreatedAt: created, UpdatedAt: created},
+	}
+	mockService.On("ExportOrders", mock.Anything, service.OrderSearchRequest{Statuses: []string{"confirmed", "pending"}}).Return(orders, nil)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/admin/orders?status=confirmed,pending&format=csv", nil)
+	rec := httptest.NewRecorder()
+
+	router.ServeHTTP(rec, req)
+
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "text/csv; charset=utf-8", rec.Header().Get("Content-Type"))
+	assert.Contains(t, rec.Header().Get("Content-Disposition"), "orders.csv")
+
+	records, err := csv.NewReader(strings.NewReader(rec.Body.String())).ReadAll()
+	require.NoError(t, err)
+	require.Len(t, records, 3)
+	assert.Equal(t, orderCSVHeader, records[0])
+	assert.Equal(t, []string{
+		orders[0].ID.String(), "K7QM2X", "confirmed", "Lovelace, Ada", "ada@example.com", "",
+		"FB101 FB102", "4", "420.50", "0", "2026-06-01T12:00:00Z", "2026-06-01T12:00:00Z",
+	}, records[1])
+	assert.Equal(t, "", records[2][1])
+	mockService.AssertExpectations(t)
+}
+
+func TestCSVText(t *testing.T) {
+	tests := []struct {
+		value string
+		want  string
+	}{
+		{"Ada Lovelace", "Ada Lovelace"},
+		{"", ""},
+		{"=HYPERLINK(\"http://evil.example\")", "'=HYPERLINK(\"http://evil.example\")"},
+		{"+1 555 0100", "'+1 555 0100"},
+		{"-2+3", "'-2+3"},
+		{"@SUM(A1)", "'@SUM(A1)"},
+		{"\tcmd", "'\tcmd"},
+		{"\rcmd", "'\rcmd"},
+		{"ada=lovelace@example.com", "ada=lovelace@example.com"},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, csvText(tt.value), tt.value)
+	}
+}
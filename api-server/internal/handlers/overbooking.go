@@ -0,0 +1,83 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/cx-tal-miterani/flight-booking-system/api-server/internal/database"
+	"github.com/cx-tal-miterani/flight-booking-system/api-server/internal/service"
+	"github.com/gorilla/mux"
+)
+
+// respondOverbookingError maps overbooking and check-in errors to HTTP
+// responses
+func respondOverbookingError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, service.ErrInvalidInput):
+		respondError(w, http.StatusBadRequest, err.Error())
+	case errors.Is(err, database.ErrNotFound):
+		respondError(w, http.StatusNotFound, "Not found")
+	case errors.Is(err, database.ErrOverbookingLimit),
+		errors.Is(err, database.ErrOrderNotModifiable),
+		errors.Is(err, database.ErrSeatNotAvailable):
+		respondError(w, http.StatusConflict, err.Error())
+	default:
+		respondError(w, http.StatusInternalServerError, err.Error())
+	}
+}
+
+// CheckIn handles POST /api/orders/{id}/check-in
+func (h *Handler) CheckIn(w http.ResponseWriter, r *http.Request) {
+	status, err := h.service.CheckIn(r.Context(), mux.Vars(r)["id"])
+	if err != nil {
+		respondOverbookingError(w, err)
+		return
+	}
+	respondJSON(w, http.StatusOK, status)
+}
+
+// AdminGetOverbookingPolicies handles GET /api/admin/overbooking-policies
+func (h *Handler) AdminGetOverbookingPolicies(w http.ResponseWriter, r *http.Request) {
+	policies, err := h.service.GetOverbookingPolicies(r.Context())
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	respondJSON(w, http.StatusOK, policies)
+}
+
+// AdminSaveOverbookingPolicy handles PUT /api/admin/overbooking-policies
+func (h *Handler) AdminSaveOverbookingPolicy(w http.ResponseWriter, r *http.Request) {
+	var req service.OverbookingPolicyRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	policy, err := h.service.SaveOverbookingPolicy(r.Context(), req)
+	if err != nil {
+		respondOverbookingError(w, err)
+		return
+	}
+	respondJSON(w, http.StatusOK, policy)
+}
+
+// AdminDeleteOverbookingPolicy handles DELETE /api/admin/overbooking-policies/{id}
+func (h *Handler) AdminDeleteOverbookingPolicy(w http.ResponseWriter, r *http.Request) {
+	if err := h.service.DeleteOverbookingPolicy(r.Context(), mux.Vars(r)["id"]); err != nil {
+		respondOverbookingError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// AdminGetDeniedBoardingReport handles GET /api/admin/flights/{id}/denied-boarding
+func (h *Handler) AdminGetDeniedBoardingReport(w http.ResponseWriter, r *http.Request) {
+	report, err := h.service.GetDeniedBoardingReport(r.Context(), mux.Vars(r)["id"])
+	if err != nil {
+		respondOverbookingError(w, err)
+		return
+	}
+	respondJSON(w, http.StatusOK, report)
+}
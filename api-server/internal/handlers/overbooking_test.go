@@ -0,0 +1,232 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/cx-tal-miterani/flight-booking-system/api-server/internal/database"
+	"github.com/cx-tal-miterani/flight-booking-system/api-server/internal/service"
+	"github.com/cx-tal-miterani/flight-booking-system/api-server/internal/service/mocks"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestHandler_SelectSeats_Overbook(t *testing.T) {
+	orderID := uuid.New().String()
+	overbook := service.OverbookRequest{Class: "economy", PartySize: 2}
+
+	tests := []struct {
+		name           string
+		body           interface{}
+		mockReturn     *service.OrderStatusResponse
+		mockError      error
+		expectedStatus int
+	}{
+		{
+			name: "overbooked",
+			body: SelectSeatsRequest{Overbook: &overbook},
+			mockReturn: &service.OrderStatusResponse{Order: &database.Order{
+				Status: database.OrderStatusSeatsSelected,
+				OverbookedSeats: []database.OverbookedSeat{
+					{Class: "economy", Status: database.OverbookedSeatUnassigned},
+					{Class: "economy", Status: database.OverbookedSeatUnassigned},
+				},
+			}},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "limit reached",
+			body:           SelectSeatsRequest{Overbook: &overbook},
+			mockError:      fmt.Errorf("%w: 1 of 3 economy seats left", database.ErrOverbookingLimit),
+			expectedStatus: http.StatusConflict,
+		},
+		{
+			name:           "seats still available",
+			body:           SelectSeatsRequest{Overbook: &overbook},
+			mockError:      fmt.Errorf("%w: 4 economy seats are available, select them instead", service.ErrInvalidInput),
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "combined with seat IDs",
+			body:           SelectSeatsRequest{SeatIDs: []string{"1A"}, Overbook: &overbook},
+			expectedStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(mocks.MockService)
+			handler := NewHandler(mockService)
+			router := setupTestRouter(handler)
+
+			if tt.mockReturn != nil || tt.mockError != nil {
+				mockService.On("OverbookSeats", mock.Anything, orderID, overbook).Return(tt.mockReturn, tt.mockError)
+			}
+
+			body, _ := json.Marshal(tt.body)
+			req := httptest.NewRequest(http.MethodPost, "/api/orders/"+orderID+"/seats", bytes.NewReader(body))
+			rec := httptest.NewRecorder()
+
+			router.ServeHTTP(rec, req)
+
+			assert.Equal(t, tt.expectedStatus, rec.Code)
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestHandler_CheckIn(t *testing.T) {
+	orderID := uuid.New().String()
+
+	tests := []struct {
+		name           string
+		mockReturn     *service.OrderStatusResponse
+		mockError      error
+		expectedStatus int
+	}{
+		{
+			name:           "checked in",
+			mockReturn:     &service.OrderStatusResponse{Order: &database.Order{Status: database.OrderStatusConfirmed}},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "not confirmed",
+			mockError:      fmt.Errorf("%w: only confirmed orders can be checked in", service.ErrInvalidInput),
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "no seats left",
+			mockError:      fmt.Errorf("%w: 1 of 2 economy seats available", database.ErrSeatNotAvailable),
+			expectedStatus: http.StatusConflict,
+		},
+		{
+			name:           "check-in closed",
+			mockError:      fmt.Errorf("%w: check-in for flight AA123 is closed", database.ErrOrderNotModifiable),
+			expectedStatus: http.StatusConflict,
+		},
+		{
+			name:           "order not found",
+			mockError:      database.ErrNotFound,
+			expectedStatus: http.StatusNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(mocks.MockService)
+			handler := NewHandler(mockService)
+			router := setupTestRouter(handler)
+
+			mockService.On("CheckIn", mock.Anything, orderID).Return(tt.mockReturn, tt.mockError)
+
+			req := httptest.NewRequest(http.MethodPost, "/api/orders/"+orderID+"/check-in", nil)
+			rec := httptest.NewRecorder()
+
+			router.ServeHTTP(rec, req)
+
+			assert.Equal(t, tt.expectedStatus, rec.Code)
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestHandler_AdminSaveOverbookingPolicy(t *testing.T) {
+	policyReq := service.OverbookingPolicyRequest{
+		Origin:      "JFK",
+		Destination: "LAX",
+		Class:       "economy",
+		Mode:        database.OverbookingPercentage,
+		Value:       5,
+	}
+
+	tests := []struct {
+		name           string
+		mockReturn     *database.OverbookingPolicy
+		mockError      error
+		expectedStatus int
+	}{
+		{
+			name:           "saved",
+			mockReturn:     &database.OverbookingPolicy{ID: uuid.New(), Mode: database.OverbookingPercentage, Value: 5},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "invalid",
+			mockError:      fmt.Errorf("%w: percentage must be at most 100", service.ErrInvalidInput),
+			expectedStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(mocks.MockService)
+			handler := NewHandler(mockService)
+			router := setupTestRouter(handler)
+
+			mockService.On("SaveOverbookingPolicy", mock.Anything, policyReq).Return(tt.mockReturn, tt.mockError)
+
+			body, _ := json.Marshal(policyReq)
+			req := httptest.NewRequest(http.MethodPut, "/api/admin/overbooking-policies", bytes.NewReader(body))
+			rec := httptest.NewRecorder()
+
+			router.ServeHTTP(rec, req)
+
+			assert.Equal(t, tt.expectedStatus, rec.Code)
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestHandler_AdminGetDeniedBoardingReport(t *testing.T) {
+	flightID := uuid.New()
+
+	mockService := new(mocks.MockService)
+	handler := NewHandler(mockService)
+	router := setupTestRouter(handler)
+
+	report := &service.DeniedBoardingReport{
+		FlightID:     flightID,
+		FlightNumber: "AA123",
+		Status:       database.FlightStatusBoarding,
+		Classes:      []database.OverbookingClass{{Class: "economy", Seats: 150, Limit: 7, Assigned: 5, Denied: 2}},
+		Denied: []database.DeniedBoarding{
+			{OrderID: uuid.New(), CustomerName: "Ada Lovelace", CustomerEmail: "ada@example.com", Class: "economy", Travelers: 2, Amount: 300},
+		},
+	}
+	mockService.On("GetDeniedBoardingReport", mock.Anything, flightID.String()).Return(report, nil)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/admin/flights/"+flightID.String()+"/denied-boarding", nil)
+	rec := httptest.NewRecorder()
+
+	router.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	var got service.DeniedBoardingReport
+	assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &got))
+	assert.Len(t, got.Denied, 1)
+	assert.Equal(t, 2, got.Denied[0].Travelers)
+	mockService.AssertExpectations(t)
+}
+
+func TestHandler_AdminDeleteOverbookingPolicy_NotFound(t *testing.T) {
+	policyID := uuid.New().String()
+
+	mockService := new(mocks.MockService)
+	handler := NewHandler(mockService)
+	router := setupTestRouter(handler)
+
+	mockService.On("DeleteOverbookingPolicy", mock.Anything, policyID).Return(database.ErrNotFound)
+
+	req := httptest.NewRequest(http.MethodDelete, "/api/admin/overbooking-policies/"+policyID, nil)
+	rec := httptest.NewRecorder()
+
+	router.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+	mockService.AssertExpectations(t)
+}
@@ -0,0 +1,36 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/cx-tal-miterani/flight-booking-system/api-server/internal/database"
+	"github.com/cx-tal-miterani/flight-booking-system/api-server/internal/service"
+	"github.com/gorilla/mux"
+)
+
+// SetPassengers handles PUT /api/orders/{id}/passengers
+func (h *Handler) SetPassengers(w http.ResponseWriter, r *http.Request) {
+	var req service.SetPassengersRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	status, err := h.service.SetPassengers(r.Context(), mux.Vars(r)["id"], req)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrInvalidInput):
+			respondError(w, http.StatusBadRequest, err.Error())
+		case errors.Is(err, database.ErrNotFound):
+			respondError(w, http.StatusNotFound, "Order not found")
+		case errors.Is(err, database.ErrOrderNotModifiable):
+			respondError(w, http.StatusConflict, err.Error())
+		default:
+			respondError(w, http.StatusInternalServerError, err.Error())
+		}
+		return
+	}
+	respondJSON(w, http.StatusOK, status)
+}
@@ -0,0 +1,77 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/cx-tal-miterani/flight-booking-system/api-server/internal/database"
+	"github.com/cx-tal-miterani/flight-booking-system/api-server/internal/service"
+	"github.com/cx-tal-miterani/flight-booking-system/api-server/internal/service/mocks"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestHandler_SetPassengers(t *testing.T) {
+	orderID := uuid.New().String()
+	passengersReq := service.SetPassengersRequest{
+		Passengers: []service.PassengerRequest{{
+			FirstName:   "Ada",
+			LastName:    "Lovelace",
+			DateOfBirth: "1990-12-10",
+			Type:        database.PassengerAdult,
+			SeatIDs:     []string{uuid.New().String()},
+		}},
+	}
+
+	tests := []struct {
+		name           string
+		mockReturn     *service.OrderStatusResponse
+		mockError      error
+		expectedStatus int
+	}{
+		{
+			name:           "saved",
+			mockReturn:     &service.OrderStatusResponse{Order: &database.Order{ID: uuid.MustParse(orderID)}},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "invalid passenger",
+			mockError:      fmt.Errorf("%w: passenger is child at departure, not adult", service.ErrInvalidInput),
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "payment processing",
+			mockError:      fmt.Errorf("%w: order is processing", database.ErrOrderNotModifiable),
+			expectedStatus: http.StatusConflict,
+		},
+		{
+			name:           "order not found",
+			mockError:      database.ErrNotFound,
+			expectedStatus: http.StatusNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(mocks.MockService)
+			handler := NewHandler(mockService)
+			router := setupTestRouter(handler)
+
+			mockService.On("SetPassengers", mock.Anything, orderID, passengersReq).Return(tt.mockReturn, tt.mockError)
+
+			body, _ := json.Marshal(passengersReq)
+			req := httptest.NewRequest(http.MethodPut, "/api/orders/"+orderID+"/passengers", bytes.NewReader(body))
+			rec := httptest.NewRecorder()
+
+			router.ServeHTTP(rec, req)
+
+			assert.Equal(t, tt.expectedStatus, rec.Code)
+			mockService.AssertExpectations(t)
+		})
+	}
+}
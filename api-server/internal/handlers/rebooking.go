@@ -0,0 +1,56 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/cx-tal-miterani/flight-booking-system/api-server/internal/database"
+	"github.com/cx-tal-miterani/flight-booking-system/api-server/internal/service"
+	"github.com/gorilla/mux"
+)
+
+// respondRebookingError maps rebooking errors to HTTP responses
+func respondRebookingError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, service.ErrInvalidInput):
+		respondError(w, http.StatusBadRequest, err.Error())
+	case errors.Is(err, database.ErrNotFound):
+		respondError(w, http.StatusNotFound, "Rebooking offer not found")
+	default:
+		respondError(w, http.StatusInternalServerError, err.Error())
+	}
+}
+
+// GetRebookingOffer handles GET /api/orders/{id}/rebooking
+func (h *Handler) GetRebookingOffer(w http.ResponseWriter, r *http.Request) {
+	orderID := mux.Vars(r)["id"]
+
+	offer, err := h.service.GetRebookingOffer(r.Context(), orderID)
+	if err != nil {
+		respondRebookingError(w, err)
+		return
+	}
+	respondJSON(w, http.StatusOK, offer)
+}
+
+// RespondToRebooking handles POST /api/orders/{id}/rebooking
+//
+// The answer is processed asynchronously by the disruption workflow, so the
+// response is 202 Accepted; the outcome is visible on the offer and order.
+func (h *Handler) RespondToRebooking(w http.ResponseWriter, r *http.Request) {
+	orderID := mux.Vars(r)["id"]
+
+	var req service.RebookingResponseRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	offer, err := h.service.RespondToRebooking(r.Context(), orderID, req)
+	if err != nil {
+		respondRebookingError(w, err)
+		return
+	}
+	respondJSON(w, http.StatusAccepted, offer)
+}
@@ -0,0 +1,109 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/cx-tal-miterani/flight-booking-system/api-server/internal/database"
+	"github.com/cx-tal-miterani/flight-booking-system/api-server/internal/service"
+	"github.com/cx-tal-miterani/flight-booking-system/api-server/internal/service/mocks"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestHandler_GetRebookingOffer(t *testing.T) {
+	orderID := uuid.New().String()
+
+	tests := []struct {
+		name           string
+		mockReturn     *database.RebookingOffer
+		mockError      error
+		expectedStatus int
+	}{
+		{
+			name: "offer found",
+			mockReturn: &database.RebookingOffer{
+				Status:               database.RebookingOfferPending,
+				AlternativeFlightIDs: []uuid.UUID{uuid.New()},
+				ExpiresAt:            time.Now().Add(time.Hour),
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "no offer",
+			mockError:      database.ErrNotFound,
+			expectedStatus: http.StatusNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(mocks.MockService)
+			handler := NewHandler(mockService)
+			router := setupTestRouter(handler)
+
+			mockService.On("GetRebookingOffer", mock.Anything, orderID).Return(tt.mockReturn, tt.mockError)
+
+			req := httptest.NewRequest(http.MethodGet, "/api/orders/"+orderID+"/rebooking", nil)
+			rec := httptest.NewRecorder()
+
+			router.ServeHTTP(rec, req)
+
+			assert.Equal(t, tt.expectedStatus, rec.Code)
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestHandler_RespondToRebooking(t *testing.T) {
+	orderID := uuid.New().String()
+	req := service.RebookingResponseRequest{Accept: true, FlightID: uuid.New().String()}
+
+	tests := []struct {
+		name           string
+		mockReturn     *database.RebookingOffer
+		mockError      error
+		expectedStatus int
+	}{
+		{
+			name:           "response accepted",
+			mockReturn:     &database.RebookingOffer{Status: database.RebookingOfferPending},
+			expectedStatus: http.StatusAccepted,
+		},
+		{
+			name:           "offer already answered",
+			mockError:      fmt.Errorf("%w: rebooking offer is accepted", service.ErrInvalidInput),
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "no offer",
+			mockError:      database.ErrNotFound,
+			expectedStatus: http.StatusNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(mocks.MockService)
+			handler := NewHandler(mockService)
+			router := setupTestRouter(handler)
+
+			mockService.On("RespondToRebooking", mock.Anything, orderID, req).Return(tt.mockReturn, tt.mockError)
+
+			body, _ := json.Marshal(req)
+			httpReq := httptest.NewRequest(http.MethodPost, "/api/orders/"+orderID+"/rebooking", bytes.NewReader(body))
+			rec := httptest.NewRecorder()
+
+			router.ServeHTTP(rec, httpReq)
+
+			assert.Equal(t, tt.expectedStatus, rec.Code)
+			mockService.AssertExpectations(t)
+		})
+	}
+}
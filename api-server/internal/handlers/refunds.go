@@ -0,0 +1,39 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/cx-tal-miterani/flight-booking-system/api-server/internal/database"
+	"github.com/cx-tal-miterani/flight-booking-system/api-server/internal/service"
+	"github.com/gorilla/mux"
+)
+
+// CancelBooking handles POST /api/orders/{id}/cancellation
+//
+// An empty passengerIds list cancels the whole order. The seats are released
+// right away; the order lists the refund with its outcome.
+func (h *Handler) CancelBooking(w http.ResponseWriter, r *http.Request) {
+	var req service.CancelBookingRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	refund, err := h.service.CancelBooking(r.Context(), mux.Vars(r)["id"], req)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrInvalidInput):
+			respondError(w, http.StatusBadRequest, err.Error())
+		case errors.Is(err, database.ErrNotFound):
+			respondError(w, http.StatusNotFound, "Order not found")
+		case errors.Is(err, database.ErrOrderNotModifiable):
+			respondError(w, http.StatusConflict, err.Error())
+		default:
+			respondError(w, http.StatusInternalServerError, err.Error())
+		}
+		return
+	}
+	respondJSON(w, http.StatusAccepted, refund)
+}
@@ -0,0 +1,75 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/cx-tal-miterani/flight-booking-system/api-server/internal/database"
+	"github.com/cx-tal-miterani/flight-booking-system/api-server/internal/service"
+	"github.com/cx-tal-miterani/flight-booking-system/api-server/internal/service/mocks"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestHandler_CancelBooking(t *testing.T) {
+	orderID := uuid.New().String()
+	cancelReq := service.CancelBookingRequest{PassengerIDs: []string{uuid.New().String()}}
+
+	tests := []struct {
+		name           string
+		mockReturn     *database.OrderRefund
+		mockError      error
+		expectedStatus int
+	}{
+		{
+			name:           "cancelled",
+			mockReturn:     &database.OrderRefund{ID: uuid.New(), Amount: 120, Status: database.RefundPending},
+			expectedStatus: http.StatusAccepted,
+		},
+		{
+			name:           "not confirmed",
+			mockError:      fmt.Errorf("%w: only confirmed orders can be cancelled with a refund", database.ErrOrderNotModifiable),
+			expectedStatus: http.StatusConflict,
+		},
+		{
+			name:           "unknown passenger",
+			mockError:      fmt.Errorf("%w: passenger is not an active passenger of this order", service.ErrInvalidInput),
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "order not found",
+			mockError:      database.ErrNotFound,
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			name:           "database error",
+			mockError:      errors.New("failed to cancel booking"),
+			expectedStatus: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(mocks.MockService)
+			handler := NewHandler(mockService)
+			router := setupTestRouter(handler)
+
+			mockService.On("CancelBooking", mock.Anything, orderID, cancelReq).Return(tt.mockReturn, tt.mockError)
+
+			body, _ := json.Marshal(cancelReq)
+			req := httptest.NewRequest(http.MethodPost, "/api/orders/"+orderID+"/cancellation", bytes.NewReader(body))
+			rec := httptest.NewRecorder()
+
+			router.ServeHTTP(rec, req)
+
+			assert.Equal(t, tt.expectedStatus, rec.Code)
+			mockService.AssertExpectations(t)
+		})
+	}
+}
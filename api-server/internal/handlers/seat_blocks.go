@@ -0,0 +1,88 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/cx-tal-miterani/flight-booking-system/api-server/internal/database"
+	"github.com/cx-tal-miterani/flight-booking-system/api-server/internal/service"
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+)
+
+// unblockSeatsResponse lists the seats that were put back on sale
+type unblockSeatsResponse struct {
+	UnblockedSeatIDs []uuid.UUID `json:"unblockedSeatIds"`
+}
+
+// respondSeatBlockError maps seat block errors to HTTP responses
+func respondSeatBlockError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, service.ErrInvalidInput):
+		respondError(w, http.StatusBadRequest, err.Error())
+	case errors.Is(err, database.ErrNotFound):
+		respondError(w, http.StatusNotFound, "Flight not found")
+	case errors.Is(err, database.ErrSeatNotAvailable):
+		respondSeatsUnavailable(w, err, "Held or booked seats cannot be blocked")
+	default:
+		respondError(w, http.StatusInternalServerError, err.Error())
+	}
+}
+
+// AdminGetSeatBlocks handles GET /api/admin/flights/{id}/seat-blocks
+func (h *Handler) AdminGetSeatBlocks(w http.ResponseWriter, r *http.Request) {
+	blocks, err := h.service.GetSeatBlocks(r.Context(), mux.Vars(r)["id"])
+	if err != nil {
+		respondSeatBlockError(w, err)
+		return
+	}
+	respondJSON(w, http.StatusOK, blocks)
+}
+
+// AdminBlockSeats handles POST /api/admin/flights/{id}/seat-blocks
+func (h *Handler) AdminBlockSeats(w http.ResponseWriter, r *http.Request) {
+	var req service.BlockSeatsRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	blocks, err := h.service.BlockSeats(r.Context(), mux.Vars(r)["id"], req)
+	if err != nil {
+		respondSeatBlockError(w, err)
+		return
+	}
+	respondJSON(w, http.StatusOK, blocks)
+}
+
+// AdminUnblockSeats handles DELETE /api/admin/flights/{id}/seat-blocks
+//
+// The seats to unblock are given by the comma-separated seats (seat IDs or
+// numbers) and rows query parameters.
+func (h *Handler) AdminUnblockSeats(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	var sel service.SeatSelection
+	if v := q.Get("seats"); v != "" {
+		sel.Seats = strings.Split(v, ",")
+	}
+	if v := q.Get("rows"); v != "" {
+		for _, row := range strings.Split(v, ",") {
+			n, err := strconv.Atoi(strings.TrimSpace(row))
+			if err != nil {
+				respondError(w, http.StatusBadRequest, "Invalid rows")
+				return
+			}
+			sel.Rows = append(sel.Rows, n)
+		}
+	}
+
+	unblocked, err := h.service.UnblockSeats(r.Context(), mux.Vars(r)["id"], sel)
+	if err != nil {
+		respondSeatBlockError(w, err)
+		return
+	}
+	respondJSON(w, http.StatusOK, unblockSeatsResponse{UnblockedSeatIDs: unblocked})
+}
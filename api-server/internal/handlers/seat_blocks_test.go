@@ -0,0 +1,122 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/cx-tal-miterani/flight-booking-system/api-server/internal/database"
+	"github.com/cx-tal-miterani/flight-booking-system/api-server/internal/service"
+	"github.com/cx-tal-miterani/flight-booking-system/api-server/internal/service/mocks"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestHandler_AdminBlockSeats(t *testing.T) {
+	flightID := uuid.New().String()
+	heldSeat := uuid.New()
+	blockReq := service.BlockSeatsRequest{
+		SeatSelection: service.SeatSelection{Seats: []string{"1A"}, Rows: []int{12}},
+		Reason:        database.SeatBlockCrew,
+		BlockedBy:     "ops@example.com",
+	}
+
+	tests := []struct {
+		name           string
+		mockReturn     []database.SeatBlock
+		mockError      error
+		expectedStatus int
+	}{
+		{
+			name:           "blocked",
+			mockReturn:     []database.SeatBlock{{SeatID: uuid.New(), SeatNumber: "1A", Reason: database.SeatBlockCrew, BlockedBy: "ops@example.com"}},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "seat held",
+			mockError:      &database.SeatsUnavailableError{SeatIDs: []uuid.UUID{heldSeat}},
+			expectedStatus: http.StatusConflict,
+		},
+		{
+			name:           "unknown row",
+			mockError:      fmt.Errorf("%w: row 12 has no seats", service.ErrInvalidInput),
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "flight not found",
+			mockError:      database.ErrNotFound,
+			expectedStatus: http.StatusNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(mocks.MockService)
+			handler := NewHandler(mockService)
+			router := setupTestRouter(handler)
+
+			mockService.On("BlockSeats", mock.Anything, flightID, blockReq).Return(tt.mockReturn, tt.mockError)
+
+			body, _ := json.Marshal(blockReq)
+			req := httptest.NewRequest(http.MethodPost, "/api/admin/flights/"+flightID+"/seat-blocks", bytes.NewReader(body))
+			rec := httptest.NewRecorder()
+
+			router.ServeHTTP(rec, req)
+
+			assert.Equal(t, tt.expectedStatus, rec.Code)
+			if tt.expectedStatus == http.StatusConflict {
+				var resp seatsUnavailableResponse
+				assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+				assert.Equal(t, []uuid.UUID{heldSeat}, resp.UnavailableSeatIDs)
+			}
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestHandler_AdminUnblockSeats(t *testing.T) {
+	flightID := uuid.New().String()
+
+	tests := []struct {
+		name           string
+		query          string
+		sel            *service.SeatSelection
+		expectedStatus int
+	}{
+		{
+			name:           "seats and rows",
+			query:          "?seats=1A,1B&rows=12,14",
+			sel:            &service.SeatSelection{Seats: []string{"1A", "1B"}, Rows: []int{12, 14}},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "invalid row",
+			query:          "?rows=twelve",
+			expectedStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(mocks.MockService)
+			handler := NewHandler(mockService)
+			router := setupTestRouter(handler)
+
+			if tt.sel != nil {
+				mockService.On("UnblockSeats", mock.Anything, flightID, *tt.sel).Return([]uuid.UUID{uuid.New()}, nil)
+			}
+
+			req := httptest.NewRequest(http.MethodDelete, "/api/admin/flights/"+flightID+"/seat-blocks"+tt.query, nil)
+			rec := httptest.NewRecorder()
+
+			router.ServeHTTP(rec, req)
+
+			assert.Equal(t, tt.expectedStatus, rec.Code)
+			mockService.AssertExpectations(t)
+		})
+	}
+}
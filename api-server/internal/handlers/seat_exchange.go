@@ -0,0 +1,48 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/cx-tal-miterani/flight-booking-system/api-server/internal/database"
+	"github.com/cx-tal-miterani/flight-booking-system/api-server/internal/service"
+	"github.com/gorilla/mux"
+)
+
+// respondSeatExchangeError maps seat change errors to HTTP responses
+func respondSeatExchangeError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, service.ErrInvalidInput):
+		respondError(w, http.StatusBadRequest, err.Error())
+	case errors.Is(err, database.ErrNotFound):
+		respondError(w, http.StatusNotFound, "Order not found")
+	case errors.Is(err, database.ErrPaymentRequired):
+		respondError(w, http.StatusPaymentRequired, err.Error())
+	case errors.Is(err, database.ErrSeatNotAvailable):
+		respondSeatsUnavailable(w, err, "One or more seats are not available")
+	case errors.Is(err, database.ErrOrderNotModifiable):
+		respondError(w, http.StatusConflict, err.Error())
+	default:
+		respondError(w, http.StatusInternalServerError, err.Error())
+	}
+}
+
+// ExchangeSeats handles POST /api/orders/{id}/seat-exchanges
+//
+// The seats are changed once the fare difference is settled; the order
+// lists the exchange with its outcome.
+func (h *Handler) ExchangeSeats(w http.ResponseWriter, r *http.Request) {
+	var req service.SeatExchangeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	exchange, err := h.service.ExchangeSeats(r.Context(), mux.Vars(r)["id"], req)
+	if err != nil {
+		respondSeatExchangeError(w, err)
+		return
+	}
+	respondJSON(w, http.StatusAccepted, exchange)
+}
@@ -0,0 +1,88 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/cx-tal-miterani/flight-booking-system/api-server/internal/database"
+	"github.com/cx-tal-miterani/flight-booking-system/api-server/internal/service"
+	"github.com/cx-tal-miterani/flight-booking-system/api-server/internal/service/mocks"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestHandler_ExchangeSeats(t *testing.T) {
+	orderID := uuid.New().String()
+	takenSeat := uuid.New()
+	exchangeReq := service.SeatExchangeRequest{
+		Swaps:       []service.SeatSwap{{FromSeatID: uuid.New().String(), ToSeatID: takenSeat.String()}},
+		PaymentCode: "12345",
+	}
+
+	tests := []struct {
+		name           string
+		mockReturn     *database.SeatExchange
+		mockError      error
+		expectedStatus int
+	}{
+		{
+			name:           "started",
+			mockReturn:     &database.SeatExchange{ID: uuid.New(), Status: database.SeatExchangePending, PriceDifference: 25},
+			expectedStatus: http.StatusAccepted,
+		},
+		{
+			name:           "seat taken",
+			mockError:      &database.SeatsUnavailableError{SeatIDs: []uuid.UUID{takenSeat}},
+			expectedStatus: http.StatusConflict,
+		},
+		{
+			name:           "payment required",
+			mockError:      fmt.Errorf("%w: the new seats cost 25.00 more", database.ErrPaymentRequired),
+			expectedStatus: http.StatusPaymentRequired,
+		},
+		{
+			name:           "not confirmed",
+			mockError:      fmt.Errorf("%w: only confirmed orders can change seats", database.ErrOrderNotModifiable),
+			expectedStatus: http.StatusConflict,
+		},
+		{
+			name:           "seat not in order",
+			mockError:      fmt.Errorf("%w: seat is not booked by this order", service.ErrInvalidInput),
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "order not found",
+			mockError:      database.ErrNotFound,
+			expectedStatus: http.StatusNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(mocks.MockService)
+			handler := NewHandler(mockService)
+			router := setupTestRouter(handler)
+
+			mockService.On("ExchangeSeats", mock.Anything, orderID, exchangeReq).Return(tt.mockReturn, tt.mockError)
+
+			body, _ := json.Marshal(exchangeReq)
+			req := httptest.NewRequest(http.MethodPost, "/api/orders/"+orderID+"/seat-exchanges", bytes.NewReader(body))
+			rec := httptest.NewRecorder()
+
+			router.ServeHTTP(rec, req)
+
+			assert.Equal(t, tt.expectedStatus, rec.Code)
+			if tt.name == "seat taken" {
+				var resp seatsUnavailableResponse
+				assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+				assert.Equal(t, []uuid.UUID{takenSeat}, resp.UnavailableSeatIDs)
+			}
+			mockService.AssertExpectations(t)
+		})
+	}
+}
@@ -0,0 +1,64 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/cx-tal-miterani/flight-booking-system/api-server/internal/database"
+	"github.com/cx-tal-miterani/flight-booking-system/api-server/internal/service"
+	"github.com/gorilla/mux"
+)
+
+// respondWaitlistError maps waitlist errors to HTTP responses
+func respondWaitlistError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, service.ErrInvalidInput):
+		respondError(w, http.StatusBadRequest, err.Error())
+	case errors.Is(err, database.ErrNotFound):
+		respondError(w, http.StatusNotFound, "Not found")
+	case errors.Is(err, database.ErrAlreadyExists):
+		respondError(w, http.StatusConflict, "Already on the waitlist")
+	case errors.Is(err, database.ErrWaitlistEntryClosed):
+		respondError(w, http.StatusConflict, err.Error())
+	default:
+		respondError(w, http.StatusInternalServerError, err.Error())
+	}
+}
+
+// JoinWaitlist handles POST /api/flights/{id}/waitlist
+func (h *Handler) JoinWaitlist(w http.ResponseWriter, r *http.Request) {
+	flightID := mux.Vars(r)["id"]
+
+	var req service.JoinWaitlistRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	entry, err := h.service.JoinWaitlist(r.Context(), flightID, req)
+	if err != nil {
+		respondWaitlistError(w, err)
+		return
+	}
+	respondJSON(w, http.StatusCreated, entry)
+}
+
+// GetWaitlistEntry handles GET /api/waitlist/{id}
+func (h *Handler) GetWaitlistEntry(w http.ResponseWriter, r *http.Request) {
+	entry, err := h.service.GetWaitlistEntry(r.Context(), mux.Vars(r)["id"])
+	if err != nil {
+		respondWaitlistError(w, err)
+		return
+	}
+	respondJSON(w, http.StatusOK, entry)
+}
+
+// LeaveWaitlist handles DELETE /api/waitlist/{id}
+func (h *Handler) LeaveWaitlist(w http.ResponseWriter, r *http.Request) {
+	if err := h.service.LeaveWaitlist(r.Context(), mux.Vars(r)["id"]); err != nil {
+		respondWaitlistError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
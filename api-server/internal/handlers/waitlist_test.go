@@ -0,0 +1,129 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/cx-tal-miterani/flight-booking-system/api-server/internal/database"
+	"github.com/cx-tal-miterani/flight-booking-system/api-server/internal/service"
+	"github.com/cx-tal-miterani/flight-booking-system/api-server/internal/service/mocks"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestHandler_JoinWaitlist(t *testing.T) {
+	flightID := uuid.New()
+	joinReq := service.JoinWaitlistRequest{
+		Class:         "economy",
+		CustomerName:  "Ada Lovelace",
+		CustomerEmail: "ada@example.com",
+		PartySize:     2,
+	}
+
+	tests := []struct {
+		name           string
+		mockReturn     *database.WaitlistEntry
+		mockError      error
+		expectedStatus int
+	}{
+		{
+			name: "joined",
+			mockReturn: &database.WaitlistEntry{
+				ID: uuid.New(), FlightID: flightID, Class: "economy", PartySize: 2,
+				Status: database.WaitlistWaiting, Position: 3,
+			},
+			expectedStatus: http.StatusCreated,
+		},
+		{
+			name:           "seats available",
+			mockError:      fmt.Errorf("%w: 4 economy seats are available", service.ErrInvalidInput),
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "already waiting",
+			mockError:      database.ErrAlreadyExists,
+			expectedStatus: http.StatusConflict,
+		},
+		{
+			name:           "flight not found",
+			mockError:      database.ErrNotFound,
+			expectedStatus: http.StatusNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(mocks.MockService)
+			handler := NewHandler(mockService)
+			router := setupTestRouter(handler)
+
+			mockService.On("JoinWaitlist", mock.Anything, flightID.String(), joinReq).Return(tt.mockReturn, tt.mockError)
+
+			body, _ := json.Marshal(joinReq)
+			req := httptest.NewRequest(http.MethodPost, "/api/flights/"+flightID.String()+"/waitlist", bytes.NewReader(body))
+			rec := httptest.NewRecorder()
+
+			router.ServeHTTP(rec, req)
+
+			assert.Equal(t, tt.expectedStatus, rec.Code)
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestHandler_GetWaitlistEntry(t *testing.T) {
+	entryID := uuid.New().String()
+	orderID := uuid.New()
+
+	mockService := new(mocks.MockService)
+	handler := NewHandler(mockService)
+	router := setupTestRouter(handler)
+
+	mockService.On("GetWaitlistEntry", mock.Anything, entryID).Return(&database.WaitlistEntry{
+		Status: database.WaitlistOffered, OrderID: &orderID,
+	}, nil)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/waitlist/"+entryID, nil)
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	var entry database.WaitlistEntry
+	assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &entry))
+	assert.Equal(t, &orderID, entry.OrderID)
+}
+
+func TestHandler_LeaveWaitlist(t *testing.T) {
+	tests := []struct {
+		name           string
+		mockError      error
+		expectedStatus int
+	}{
+		{name: "left", expectedStatus: http.StatusNoContent},
+		{name: "already offered", mockError: database.ErrWaitlistEntryClosed, expectedStatus: http.StatusConflict},
+		{name: "not found", mockError: database.ErrNotFound, expectedStatus: http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			entryID := uuid.New().String()
+			mockService := new(mocks.MockService)
+			handler := NewHandler(mockService)
+			router := setupTestRouter(handler)
+
+			mockService.On("LeaveWaitlist", mock.Anything, entryID).Return(tt.mockError)
+
+			req := httptest.NewRequest(http.MethodDelete, "/api/waitlist/"+entryID, nil)
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+
+			assert.Equal(t, tt.expectedStatus, rec.Code)
+			mockService.AssertExpectations(t)
+		})
+	}
+}
@@ -1,15 +1,18 @@
 package router
 
 import (
+	"crypto/subtle"
 	"net/http"
+	"strings"
 
 	"github.com/cx-tal-miterani/flight-booking-system/api-server/internal/handlers"
 	"github.com/cx-tal-miterani/flight-booking-system/api-server/internal/websocket"
 	"github.com/gorilla/mux"
 )
 
-// SetupRouter creates and configures the HTTP router
-func SetupRouter(h *handlers.Handler) *mux.Router {
+// SetupRouter creates and configures the HTTP router. Admin endpoints require
+// adminAPIKey as a bearer token; they are disabled when it is empty.
+func SetupRouter(h *handlers.Handler, adminAPIKey string) *mux.Router {
 	r := mux.NewRouter()
 
 	// CORS middleware
@@ -22,16 +25,75 @@ func SetupRouter(h *handlers.Handler) *mux.Router {
 	api.HandleFunc("/flights", h.GetFlights).Methods(http.MethodGet, http.MethodOptions)
 	api.HandleFunc("/flights/{id}", h.GetFlight).Methods(http.MethodGet, http.MethodOptions)
 	api.HandleFunc("/flights/{id}/seats", h.GetFlightSeats).Methods(http.MethodGet, http.MethodOptions)
+	api.HandleFunc("/seat-attributes", h.GetSeatAttributes).Methods(http.MethodGet, http.MethodOptions)
+
+	// Waitlist for sold-out flights
+	api.HandleFunc("/flights/{id}/waitlist", h.JoinWaitlist).Methods(http.MethodPost, http.MethodOptions)
+	api.HandleFunc("/waitlist/{id}", h.GetWaitlistEntry).Methods(http.MethodGet, http.MethodOptions)
+	api.HandleFunc("/waitlist/{id}", h.LeaveWaitlist).Methods(http.MethodDelete, http.MethodOptions)
+
+	// Itineraries (direct and connecting flights)
+	api.HandleFunc("/itineraries", h.SearchItineraries).Methods(http.MethodGet, http.MethodOptions)
+
+	// Airports
+	api.HandleFunc("/airports", h.GetAirports).Methods(http.MethodGet, http.MethodOptions)
+	api.HandleFunc("/airports/{code}", h.GetAirport).Methods(http.MethodGet, http.MethodOptions)
 
 	// WebSocket for real-time seat updates
 	api.HandleFunc("/flights/{flightId}/ws", websocket.HandleWebSocket)
 
-	// Orders
+	// Customer accounts
+	api.HandleFunc("/auth/register", h.Register).Methods(http.MethodPost, http.MethodOptions)
+	api.HandleFunc("/auth/login", h.Login).Methods(http.MethodPost, http.MethodOptions)
+	api.HandleFunc("/auth/logout", h.Logout).Methods(http.MethodPost, http.MethodOptions)
+	api.HandleFunc("/me", h.GetMe).Methods(http.MethodGet, http.MethodOptions)
+	api.HandleFunc("/me/orders", h.GetMyOrders).Methods(http.MethodGet, http.MethodOptions)
+
+	// Orders; those placed with an account can only be accessed by it
 	api.HandleFunc("/orders", h.CreateOrder).Methods(http.MethodPost, http.MethodOptions)
-	api.HandleFunc("/orders/{id}", h.GetOrder).Methods(http.MethodGet, http.MethodOptions)
-	api.HandleFunc("/orders/{id}", h.CancelOrder).Methods(http.MethodDelete, http.MethodOptions)
-	api.HandleFunc("/orders/{id}/seats", h.SelectSeats).Methods(http.MethodPost, http.MethodOptions)
-	api.HandleFunc("/orders/{id}/pay", h.SubmitPayment).Methods(http.MethodPost, http.MethodOptions)
+	order := api.PathPrefix("/orders/{id}").Subrouter()
+	order.Use(h.OrderAccess)
+	order.HandleFunc("", h.GetOrder).Methods(http.MethodGet, http.MethodOptions)
+	order.HandleFunc("", h.CancelOrder).Methods(http.MethodDelete, http.MethodOptions)
+	order.HandleFunc("/seats", h.SelectSeats).Methods(http.MethodPost, http.MethodOptions)
+	order.HandleFunc("/seats", h.ChangeSeats).Methods(http.MethodPatch, http.MethodOptions)
+	order.HandleFunc("/pay", h.SubmitPayment).Methods(http.MethodPost, http.MethodOptions)
+	order.HandleFunc("/passengers", h.SetPassengers).Methods(http.MethodPut, http.MethodOptions)
+	order.HandleFunc("/check-in", h.CheckIn).Methods(http.MethodPost, http.MethodOptions)
+	order.HandleFunc("/seat-exchanges", h.ExchangeSeats).Methods(http.MethodPost, http.MethodOptions)
+	order.HandleFunc("/flight-exchanges", h.ExchangeFlight).Methods(http.MethodPost, http.MethodOptions)
+	order.HandleFunc("/cancellation", h.CancelBooking).Methods(http.MethodPost, http.MethodOptions)
+	order.HandleFunc("/rebooking", h.GetRebookingOffer).Methods(http.MethodGet, http.MethodOptions)
+	order.HandleFunc("/rebooking", h.RespondToRebooking).Methods(http.MethodPost, http.MethodOptions)
+
+	// Bookings, looked up by booking reference and last name
+	api.HandleFunc("/bookings/{reference}", h.GetBooking).Methods(http.MethodGet, http.MethodOptions)
+
+	// Admin
+	admin := api.PathPrefix("/admin").Subrouter()
+	admin.Use(adminAuthMiddleware(adminAPIKey))
+	admin.HandleFunc("/flights", h.AdminCreateFlight).Methods(http.MethodPost, http.MethodOptions)
+	admin.HandleFunc("/flights/{id}", h.AdminUpdateFlight).Methods(http.MethodPatch, http.MethodOptions)
+	admin.HandleFunc("/flights/{id}", h.AdminDeleteFlight).Methods(http.MethodDelete, http.MethodOptions)
+	admin.HandleFunc("/flights/{id}/status", h.AdminUpdateFlightStatus).Methods(http.MethodPut, http.MethodOptions)
+	admin.HandleFunc("/flights/{id}/denied-boarding", h.AdminGetDeniedBoardingReport).Methods(http.MethodGet, http.MethodOptions)
+	admin.HandleFunc("/flights/{id}/seat-blocks", h.AdminGetSeatBlocks).Methods(http.MethodGet, http.MethodOptions)
+	admin.HandleFunc("/flights/{id}/seat-blocks", h.AdminBlockSeats).Methods(http.MethodPost, http.MethodOptions)
+	admin.HandleFunc("/flights/{id}/seat-blocks", h.AdminUnblockSeats).Methods(http.MethodDelete, http.MethodOptions)
+	admin.HandleFunc("/orders", h.AdminSearchOrders).Methods(http.MethodGet, http.MethodOptions)
+	admin.HandleFunc("/orders/{id}/events", h.AdminGetOrderEvents).Methods(http.MethodGet, http.MethodOptions)
+	admin.HandleFunc("/overbooking-policies", h.AdminGetOverbookingPolicies).Methods(http.MethodGet, http.MethodOptions)
+	admin.HandleFunc("/overbooking-policies", h.AdminSaveOverbookingPolicy).Methods(http.MethodPut, http.MethodOptions)
+	admin.HandleFunc("/overbooking-policies/{id}", h.AdminDeleteOverbookingPolicy).Methods(http.MethodDelete, http.MethodOptions)
+	admin.HandleFunc("/cabin-layouts", h.AdminGetCabinLayouts).Methods(http.MethodGet, http.MethodOptions)
+	admin.HandleFunc("/cabin-layouts", h.AdminCreateCabinLayout).Methods(http.MethodPost, http.MethodOptions)
+	admin.HandleFunc("/aircraft-types", h.AdminGetAircraftTypes).Methods(http.MethodGet, http.MethodOptions)
+	admin.HandleFunc("/aircraft-types", h.AdminCreateAircraftType).Methods(http.MethodPost, http.MethodOptions)
+	admin.HandleFunc("/seat-attributes/{attribute}", h.AdminUpdateSeatAttributeSurcharge).Methods(http.MethodPut, http.MethodOptions)
+	admin.HandleFunc("/schedules", h.AdminGetFlightSchedules).Methods(http.MethodGet, http.MethodOptions)
+	admin.HandleFunc("/schedules", h.AdminCreateFlightSchedule).Methods(http.MethodPost, http.MethodOptions)
+	admin.HandleFunc("/schedules/{id}", h.AdminDeleteFlightSchedule).Methods(http.MethodDelete, http.MethodOptions)
+	admin.HandleFunc("/imports/ssim", h.AdminImportSSIM).Methods(http.MethodPost, http.MethodOptions)
 
 	// Health check
 	r.HandleFunc("/health", healthCheck).Methods(http.MethodGet)
@@ -42,8 +104,9 @@ func SetupRouter(h *handlers.Handler) *mux.Router {
 func corsMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		w.Header().Set("Access-Control-Allow-Origin", "*")
-		w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
+		w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, PATCH, DELETE, OPTIONS")
 		w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization")
+		w.Header().Set("Access-Control-Expose-Headers", "X-Next-Cursor")
 
 		if r.Method == http.MethodOptions {
 			w.WriteHeader(http.StatusOK)
@@ -54,6 +117,24 @@ func corsMiddleware(next http.Handler) http.Handler {
 	})
 }
 
+// adminAuthMiddleware only lets requests through that carry the admin API key
+// in an "Authorization: Bearer <key>" header
+func adminAuthMiddleware(apiKey string) mux.MiddlewareFunc {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+			if apiKey == "" || !ok || subtle.ConstantTimeCompare([]byte(token), []byte(apiKey)) != 1 {
+				w.Header().Set("Content-Type", "application/json")
+				w.Header().Set("WWW-Authenticate", `Bearer realm="admin"`)
+				w.WriteHeader(http.StatusUnauthorized)
+				w.Write([]byte(`{"error":"unauthorized"}`))
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
 func healthCheck(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusOK)
@@ -0,0 +1,42 @@
+package router
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAdminAuthMiddleware(t *testing.T) {
+	ok := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+
+	tests := []struct {
+		name           string
+		apiKey         string
+		authorization  string
+		expectedStatus int
+	}{
+		{name: "valid key", apiKey: "secret", authorization: "Bearer secret", expectedStatus: http.StatusOK},
+		{name: "wrong key", apiKey: "secret", authorization: "Bearer guess", expectedStatus: http.StatusUnauthorized},
+		{name: "missing header", apiKey: "secret", expectedStatus: http.StatusUnauthorized},
+		{name: "not a bearer token", apiKey: "secret", authorization: "secret", expectedStatus: http.StatusUnauthorized},
+		{name: "admin disabled", apiKey: "", authorization: "Bearer ", expectedStatus: http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/api/admin/flights", nil)
+			if tt.authorization != "" {
+				req.Header.Set("Authorization", tt.authorization)
+			}
+			rec := httptest.NewRecorder()
+
+			adminAuthMiddleware(tt.apiKey)(ok).ServeHTTP(rec, req)
+
+			assert.Equal(t, tt.expectedStatus, rec.Code)
+		})
+	}
+}
@@ -0,0 +1,217 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/cx-tal-miterani/flight-booking-system/api-server/internal/database"
+	"github.com/google/uuid"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// ErrUnauthorized is returned when credentials or a session token are wrong
+// or missing
+var ErrUnauthorized = errors.New("unauthorized")
+
+const (
+	// SessionTTL is how long customers stay signed in
+	SessionTTL = 30 * 24 * time.Hour
+	// Passwords are hashed with bcrypt, which only uses the first 72 bytes
+	minPasswordLen     = 8
+	maxPasswordLen     = 72
+	maxCustomerNameLen = 100
+	maxEmailLen        = 255
+	sessionTokenBytes  = 32
+)
+
+// dummyPasswordHash is compared against when no account has the email given
+// at login, so that unknown emails take as long to reject as wrong passwords
+var dummyPasswordHash, _ = bcrypt.GenerateFromPassword([]byte("not a password"), bcrypt.DefaultCost)
+
+// RegisterRequest creates a customer account
+type RegisterRequest struct {
+	Email    string `json:"email"`
+	Password string `json:"password"`
+	Name     string `json:"name"`
+}
+
+// LoginRequest signs a customer in
+type LoginRequest struct {
+	Email    string `json:"email"`
+	Password string `json:"password"`
+}
+
+// Session is a signed-in session. Token is sent back as a bearer token in
+// the Authorization header.
+type Session struct {
+	Token     string             `json:"token"`
+	ExpiresAt time.Time          `json:"expiresAt"`
+	Customer  *database.Customer `json:"customer"`
+}
+
+// CustomerOrdersRequest filters the orders of a customer. CreatedFrom is
+// inclusive and CreatedTo exclusive.
+type CustomerOrdersRequest struct {
+	Statuses    []string
+	CreatedFrom *time.Time
+	CreatedTo   *time.Time
+}
+
+// Register creates a customer account and signs the customer in
+func (s *BookingService) Register(ctx context.Context, req RegisterRequest) (*Session, error) {
+	customer, err := newCustomer(req)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.repo.CreateCustomer(ctx, customer); err != nil {
+		return nil, err
+	}
+	return s.startSession(ctx, customer)
+}
+
+// Login signs a customer in with their email and password
+func (s *BookingService) Login(ctx context.Context, req LoginRequest) (*Session, error) {
+	customer, err := s.repo.GetCustomerByEmail(ctx, strings.TrimSpace(req.Email))
+	if errors.Is(err, database.ErrNotFound) {
+		bcrypt.CompareHashAndPassword(dummyPasswordHash, []byte(req.Password))
+		return nil, ErrUnauthorized
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := bcrypt.CompareHashAndPassword([]byte(customer.PasswordHash), []byte(req.Password)); err != nil {
+		return nil, ErrUnauthorized
+	}
+	return s.startSession(ctx, customer)
+}
+
+// Logout ends the session of a token
+func (s *BookingService) Logout(ctx context.Context, token string) error {
+	return s.repo.DeleteCustomerSession(ctx, hashSessionToken(token))
+}
+
+// Authenticate returns the customer signed in with a session token
+func (s *BookingService) Authenticate(ctx context.Context, token string) (*database.Customer, error) {
+	if token == "" {
+		return nil, ErrUnauthorized
+	}
+	customer, err := s.repo.GetCustomerBySession(ctx, hashSessionToken(token))
+	if errors.Is(err, database.ErrNotFound) {
+		return nil, ErrUnauthorized
+	}
+	return customer, err
+}
+
+// AuthorizeOrder checks that customer, nil when no one is signed in, may
+// access an order. Guest orders can be accessed by anyone with their ID;
+// orders placed with an account only by that account. ErrUnauthorized is
+// returned when no one is signed in and ErrNotFound when someone else is, so
+// order IDs cannot be probed. Invalid and unknown order IDs are left to the
+// caller to report.
+func (s *BookingService) AuthorizeOrder(ctx context.Context, orderID string, customer *database.Customer) error {
+	oid, err := uuid.Parse(orderID)
+	if err != nil {
+		return nil
+	}
+	owner, err := s.repo.GetOrderCustomerID(ctx, oid)
+	if errors.Is(err, database.ErrNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	return authorizeOrder(owner, customer)
+}
+
+// GetCustomerOrders returns the orders placed with a customer's account,
+// newest first
+func (s *BookingService) GetCustomerOrders(ctx context.Context, customerID uuid.UUID, req CustomerOrdersRequest) ([]database.Order, error) {
+	filter, err := customerOrderFilter(req)
+	if err != nil {
+		return nil, err
+	}
+	return s.repo.GetCustomerOrders(ctx, customerID, filter)
+}
+
+// startSession signs a customer in with a new session token
+func (s *BookingService) startSession(ctx context.Context, customer *database.Customer) (*Session, error) {
+	token, err := newSessionToken()
+	if err != nil {
+		return nil, err
+	}
+	expiresAt := time.Now().Add(SessionTTL)
+	if err := s.repo.CreateCustomerSession(ctx, customer.ID, hashSessionToken(token), expiresAt); err != nil {
+		return nil, err
+	}
+	return &Session{Token: token, ExpiresAt: expiresAt, Customer: customer}, nil
+}
+
+// newCustomer validates a registration and hashes its password
+func newCustomer(req RegisterRequest) (*database.Customer, error) {
+	email := strings.TrimSpace(req.Email)
+	name := strings.TrimSpace(req.Name)
+	if name == "" || len(name) > maxCustomerNameLen {
+		return nil, fmt.Errorf("%w: name must be 1 to %d characters", ErrInvalidInput, maxCustomerNameLen)
+	}
+	if !strings.Contains(email, "@") || len(email) > maxEmailLen {
+		return nil, fmt.Errorf("%w: a valid email is required", ErrInvalidInput)
+	}
+	if len(req.Password) < minPasswordLen || len(req.Password) > maxPasswordLen {
+		return nil, fmt.Errorf("%w: password must be %d to %d characters", ErrInvalidInput, minPasswordLen, maxPasswordLen)
+	}
+
+	hash, err := bcrypt.GenerateFromPassword([]byte(req.Password), bcrypt.DefaultCost)
+	if err != nil {
+		return nil, fmt.Errorf("failed to hash password: %w", err)
+	}
+	return &database.Customer{ID: uuid.New(), Email: email, Name: name, PasswordHash: string(hash)}, nil
+}
+
+// authorizeOrder checks that customer may access an order placed with the
+// owner account, nil for guest orders
+func authorizeOrder(owner *uuid.UUID, customer *database.Customer) error {
+	switch {
+	case owner == nil:
+		return nil
+	case customer == nil:
+		return ErrUnauthorized
+	case customer.ID != *owner:
+		return database.ErrNotFound
+	}
+	return nil
+}
+
+// customerOrderFilter validates the filters of a customer's orders
+func customerOrderFilter(req CustomerOrdersRequest) (database.CustomerOrderFilter, error) {
+	filter := database.CustomerOrderFilter{CreatedFrom: req.CreatedFrom, CreatedTo: req.CreatedTo}
+	var err error
+	if filter.Statuses, err = parseOrderStatuses(req.Statuses); err != nil {
+		return filter, err
+	}
+	if filter.CreatedFrom != nil && filter.CreatedTo != nil && !filter.CreatedFrom.Before(*filter.CreatedTo) {
+		return filter, fmt.Errorf("%w: from must be before to", ErrInvalidInput)
+	}
+	return filter, nil
+}
+
+// newSessionToken returns a random session token
+func newSessionToken() (string, error) {
+	b := make([]byte, sessionTokenBytes)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate session token: %w", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// hashSessionToken returns the hash a session token is stored by
+func hashSessionToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,92 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/cx-tal-miterani/flight-booking-system/api-server/internal/database"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/bcrypt"
+)
+
+func TestNewCustomer(t *testing.T) {
+	c, err := newCustomer(RegisterRequest{Email: " ada@example.com ", Password: "correct horse", Name: " Ada Lovelace "})
+	require.NoError(t, err)
+	assert.Equal(t, "ada@example.com", c.Email)
+	assert.Equal(t, "Ada Lovelace", c.Name)
+	assert.NotEqual(t, uuid.Nil, c.ID)
+	assert.NotContains(t, c.PasswordHash, "correct horse")
+	assert.NoError(t, bcrypt.CompareHashAndPassword([]byte(c.PasswordHash), []byte("correct horse")))
+
+	tests := []struct {
+		name string
+		req  RegisterRequest
+	}{
+		{name: "missing name", req: RegisterRequest{Email: "ada@example.com", Password: "correct horse"}},
+		{name: "invalid email", req: RegisterRequest{Email: "ada.example.com", Password: "correct horse", Name: "Ada"}},
+		{name: "short password", req: RegisterRequest{Email: "ada@example.com", Password: "horse", Name: "Ada"}},
+		{name: "long password", req: RegisterRequest{Email: "ada@example.com", Password: string(make([]byte, 73)), Name: "Ada"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := newCustomer(tt.req)
+			assert.ErrorIs(t, err, ErrInvalidInput)
+		})
+	}
+}
+
+func TestAuthorizeOrder(t *testing.T) {
+	owner := &database.Customer{ID: uuid.New()}
+	other := &database.Customer{ID: uuid.New()}
+
+	assert.NoError(t, authorizeOrder(nil, nil), "guest order without a session")
+	assert.NoError(t, authorizeOrder(nil, other), "guest order with a session")
+	assert.NoError(t, authorizeOrder(&owner.ID, owner))
+	assert.ErrorIs(t, authorizeOrder(&owner.ID, nil), ErrUnauthorized)
+	assert.ErrorIs(t, authorizeOrder(&owner.ID, other), database.ErrNotFound)
+}
+
+func TestAuthorizeOrder_InvalidIDLeftToCaller(t *testing.T) {
+	// The repository is never reached, so none is needed
+	s := &BookingService{}
+	assert.NoError(t, s.AuthorizeOrder(context.Background(), "not-a-uuid", nil))
+}
+
+func TestAuthenticate_EmptyToken(t *testing.T) {
+	s := &BookingService{}
+	_, err := s.Authenticate(context.Background(), "")
+	assert.ErrorIs(t, err, ErrUnauthorized)
+}
+
+func TestCustomerOrderFilter(t *testing.T) {
+	from := time.Date(2026, 6, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2026, 7, 1, 0, 0, 0, 0, time.UTC)
+
+	filter, err := customerOrderFilter(CustomerOrdersRequest{Statuses: []string{"confirmed", " cancelled"}, CreatedFrom: &from, CreatedTo: &to})
+	require.NoError(t, err)
+	assert.Equal(t, []database.OrderStatus{database.OrderStatusConfirmed, database.OrderStatusCancelled}, filter.Statuses)
+	assert.Equal(t, &from, filter.CreatedFrom)
+	assert.Equal(t, &to, filter.CreatedTo)
+
+	_, err = customerOrderFilter(CustomerOrdersRequest{Statuses: []string{"shipped"}})
+	assert.ErrorIs(t, err, ErrInvalidInput)
+
+	_, err = customerOrderFilter(CustomerOrdersRequest{CreatedFrom: &to, CreatedTo: &from})
+	assert.ErrorIs(t, err, ErrInvalidInput)
+}
+
+func TestSessionToken(t *testing.T) {
+	a, err := newSessionToken()
+	require.NoError(t, err)
+	b, err := newSessionToken()
+	require.NoError(t, err)
+
+	assert.NotEqual(t, a, b)
+	assert.Len(t, a, 43)
+	assert.Len(t, hashSessionToken(a), 64)
+	assert.Equal(t, hashSessionToken(a), hashSessionToken(a))
+	assert.NotEqual(t, hashSessionToken(a), hashSessionToken(b))
+}
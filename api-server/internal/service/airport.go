@@ -0,0 +1,67 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/cx-tal-miterani/flight-booking-system/api-server/internal/database"
+)
+
+// GetAirports returns airports matching a code, city or name query
+func (s *BookingService) GetAirports(ctx context.Context, query string) ([]database.Airport, error) {
+	return s.repo.SearchAirports(ctx, strings.TrimSpace(query))
+}
+
+// GetAirport returns an airport by IATA or ICAO code
+func (s *BookingService) GetAirport(ctx context.Context, code string) (*database.Airport, error) {
+	if !isAirportCode(code) {
+		return nil, fmt.Errorf("%w: invalid airport code %q", ErrInvalidInput, code)
+	}
+	return s.repo.GetAirportByCode(ctx, code)
+}
+
+// resolveAirport maps an IATA or ICAO code to the IATA code flights are
+// stored with. An empty code is passed through so it can act as "any".
+func (s *BookingService) resolveAirport(ctx context.Context, code string) (string, error) {
+	if strings.TrimSpace(code) == "" {
+		return "", nil
+	}
+	airport, err := s.lookupAirport(ctx, code)
+	if err != nil {
+		return "", err
+	}
+	return airport.IATACode, nil
+}
+
+// lookupAirport returns the airport with an IATA or ICAO code, reporting
+// unknown codes as invalid input
+func (s *BookingService) lookupAirport(ctx context.Context, code string) (*database.Airport, error) {
+	code = strings.ToUpper(strings.TrimSpace(code))
+	if !isAirportCode(code) {
+		return nil, fmt.Errorf("%w: invalid airport code %q", ErrInvalidInput, code)
+	}
+
+	airport, err := s.repo.GetAirportByCode(ctx, code)
+	if errors.Is(err, database.ErrNotFound) {
+		return nil, fmt.Errorf("%w: unknown airport %q", ErrInvalidInput, code)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return airport, nil
+}
+
+// isAirportCode reports whether code looks like a 3-letter IATA or 4-letter ICAO code
+func isAirportCode(code string) bool {
+	if len(code) != 3 && len(code) != 4 {
+		return false
+	}
+	for _, c := range code {
+		if (c < 'A' || c > 'Z') && (c < 'a' || c > 'z') {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,160 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/cx-tal-miterani/flight-booking-system/api-server/internal/database"
+	"github.com/google/uuid"
+)
+
+// bookingReferencePattern matches the booking references the worker gives
+// confirmed orders: six letters and digits, without 0, 1, I and O
+var bookingReferencePattern = regexp.MustCompile(`^[A-HJ-NP-Z2-9]{6}$`)
+
+// Booking is a confirmed order as shown to a customer who looks it up by its
+// booking reference. It leaves out internal IDs and contact details.
+type Booking struct {
+	Reference    string               `json:"reference"`
+	Status       database.OrderStatus `json:"status"`
+	CustomerName string               `json:"customerName"`
+	TotalAmount  float64              `json:"totalAmount"`
+	BookedAt     time.Time            `json:"bookedAt"`
+	Flights      []BookingFlight      `json:"flights"`
+	Passengers   []BookingPassenger   `json:"passengers,omitempty"`
+}
+
+// BookingFlight is a flight of a booking with the seats booked on it
+type BookingFlight struct {
+	FlightNumber       string                `json:"flightNumber"`
+	Origin             string                `json:"origin"`
+	Destination        string                `json:"destination"`
+	DepartureTime      time.Time             `json:"departureTime"`
+	ArrivalTime        time.Time             `json:"arrivalTime"`
+	DepartureTimeLocal string                `json:"departureTimeLocal,omitempty"`
+	ArrivalTimeLocal   string                `json:"arrivalTimeLocal,omitempty"`
+	Status             database.FlightStatus `json:"status"`
+	Seats              []string              `json:"seats,omitempty"`
+}
+
+// BookingPassenger is a passenger of a booking with their seat numbers, in
+// flight order
+type BookingPassenger struct {
+	FirstName string                 `json:"firstName"`
+	LastName  string                 `json:"lastName"`
+	Type      database.PassengerType `json:"type"`
+	Seats     []string               `json:"seats,omitempty"`
+}
+
+// GetBooking looks up a booking by its reference and the last name of one
+// of its passengers, or of the customer who booked it. ErrNotFound is
+// returned when either does not match, so references cannot be probed.
+func (s *BookingService) GetBooking(ctx context.Context, reference, lastName string) (*Booking, error) {
+	reference = strings.ToUpper(strings.TrimSpace(reference))
+	lastName = strings.TrimSpace(lastName)
+	if lastName == "" {
+		return nil, fmt.Errorf("%w: last name is required", ErrInvalidInput)
+	}
+	if !bookingReferencePattern.MatchString(reference) {
+		return nil, database.ErrNotFound
+	}
+
+	order, err := s.repo.GetOrderByBookingReference(ctx, reference)
+	if err != nil {
+		return nil, err
+	}
+	if !matchesLastName(order, lastName) {
+		return nil, database.ErrNotFound
+	}
+
+	var flights []database.Flight
+	for _, flightID := range orderFlightIDs(order) {
+		flight, err := s.repo.GetFlightByID(ctx, flightID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get flight: %w", err)
+		}
+		flights = append(flights, *flight)
+	}
+	return newBooking(order, flights), nil
+}
+
+// orderFlightIDs returns the flights of an order in travel order
+func orderFlightIDs(order *database.Order) []uuid.UUID {
+	if len(order.Segments) == 0 {
+		return []uuid.UUID{order.FlightID}
+	}
+	ids := make([]uuid.UUID, len(order.Segments))
+	for i, seg := range order.Segments {
+		ids[i] = seg.FlightID
+	}
+	return ids
+}
+
+// matchesLastName reports whether lastName is the last name of a passenger
+// of an order, or of the customer who booked it, ignoring case
+func matchesLastName(order *database.Order, lastName string) bool {
+	for _, p := range order.Passengers {
+		if strings.EqualFold(strings.TrimSpace(p.LastName), lastName) {
+			return true
+		}
+	}
+	names := strings.Fields(order.CustomerName)
+	return len(names) > 0 && strings.EqualFold(names[len(names)-1], lastName)
+}
+
+// newBooking returns the booking of an order on flights, given in travel
+// order
+func newBooking(order *database.Order, flights []database.Flight) *Booking {
+	b := &Booking{
+		Status:       order.Status,
+		CustomerName: order.CustomerName,
+		TotalAmount:  order.TotalAmount,
+		BookedAt:     order.CreatedAt,
+	}
+	if order.BookingReference != nil {
+		b.Reference = *order.BookingReference
+	}
+
+	seats := make(map[uuid.UUID][]string)
+	for _, seg := range order.Segments {
+		seats[seg.FlightID] = seg.Seats
+	}
+	if len(order.Segments) == 0 {
+		seats[order.FlightID] = order.Seats
+	}
+	position := make(map[uuid.UUID]int, len(flights))
+	for i, f := range flights {
+		position[f.ID] = i
+		b.Flights = append(b.Flights, BookingFlight{
+			FlightNumber:       f.FlightNumber,
+			Origin:             f.Origin,
+			Destination:        f.Destination,
+			DepartureTime:      f.DepartureTime,
+			ArrivalTime:        f.ArrivalTime,
+			DepartureTimeLocal: f.DepartureTimeLocal,
+			ArrivalTimeLocal:   f.ArrivalTimeLocal,
+			Status:             f.Status,
+			Seats:              seats[f.ID],
+		})
+	}
+
+	for _, p := range order.Passengers {
+		passenger := BookingPassenger{FirstName: p.FirstName, LastName: p.LastName, Type: p.Type}
+		byFlight := make([]string, len(flights))
+		for _, seat := range p.Seats {
+			if i, ok := position[seat.FlightID]; ok {
+				byFlight[i] = seat.SeatNumber
+			}
+		}
+		for _, number := range byFlight {
+			if number != "" {
+				passenger.Seats = append(passenger.Seats, number)
+			}
+		}
+		b.Passengers = append(b.Passengers, passenger)
+	}
+	return b
+}
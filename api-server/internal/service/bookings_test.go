@@ -0,0 +1,94 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/cx-tal-miterani/flight-booking-system/api-server/internal/database"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetBooking_RejectsBeforeLookup(t *testing.T) {
+	// The repository is never reached, so none is needed
+	s := &BookingService{}
+
+	_, err := s.GetBooking(context.Background(), "K7QM2X", "  ")
+	assert.ErrorIs(t, err, ErrInvalidInput)
+
+	for _, reference := range []string{"", "K7QM2", "K7QM2XX", "K7QM0X", "K7-M2X"} {
+		_, err := s.GetBooking(context.Background(), reference, "Lovelace")
+		assert.ErrorIs(t, err, database.ErrNotFound, reference)
+	}
+}
+
+func TestMatchesLastName(t *testing.T) {
+	order := &database.Order{
+		CustomerName: "Grace Brewster Hopper",
+		Passengers: []database.Passenger{
+			{FirstName: "Ada", LastName: "Lovelace"},
+			{FirstName: "Charles", LastName: "de Gaulle"},
+		},
+	}
+
+	assert.True(t, matchesLastName(order, "lovelace"))
+	assert.True(t, matchesLastName(order, "De Gaulle"))
+	assert.True(t, matchesLastName(order, "HOPPER"))
+	assert.False(t, matchesLastName(order, "Brewster"))
+	assert.False(t, matchesLastName(order, "Ada"))
+	assert.False(t, matchesLastName(&database.Order{}, "Hopper"))
+}
+
+func TestNewBooking(t *testing.T) {
+	reference := "K7QM2X"
+	outbound := database.Flight{ID: uuid.New(), FlightNumber: "FB101", Origin: "TLV", Destination: "ATH", Status: database.FlightStatusScheduled}
+	inbound := database.Flight{ID: uuid.New(), FlightNumber: "FB102", Origin: "ATH", Destination: "TLV", Status: database.FlightStatusScheduled}
+	order := &database.Order{
+		ID:               uuid.New(),
+		BookingReference: &reference,
+		FlightID:         outbound.ID,
+		CustomerName:     "Ada Lovelace",
+		CustomerEmail:    "ada@example.com",
+		Status:           database.OrderStatusConfirmed,
+		TotalAmount:      420,
+		CreatedAt:        time.Date(2026, 6, 1, 12, 0, 0, 0, time.UTC),
+		Segments: []database.OrderSegment{
+			{FlightID: outbound.ID, SegmentIndex: 0, Seats: []string{"12A", "12B"}},
+			{FlightID: inbound.ID, SegmentIndex: 1, Seats: []string{"3C", "3D"}},
+		},
+		Passengers: []database.Passenger{{
+			ID: uuid.New(), FirstName: "Ada", LastName: "Lovelace", Type: database.PassengerAdult,
+			// Listed out of flight order
+			Seats: []database.PassengerSeat{
+				{ID: uuid.New(), FlightID: inbound.ID, SeatNumber: "3C"},
+				{ID: uuid.New(), FlightID: outbound.ID, SeatNumber: "12A"},
+			},
+		}},
+	}
+
+	b := newBooking(order, []database.Flight{outbound, inbound})
+
+	assert.Equal(t, "K7QM2X", b.Reference)
+	assert.Equal(t, database.OrderStatusConfirmed, b.Status)
+	assert.Equal(t, order.CreatedAt, b.BookedAt)
+	require.Len(t, b.Flights, 2)
+	assert.Equal(t, "FB101", b.Flights[0].FlightNumber)
+	assert.Equal(t, []string{"12A", "12B"}, b.Flights[0].Seats)
+	assert.Equal(t, []string{"3C", "3D"}, b.Flights[1].Seats)
+	require.Len(t, b.Passengers, 1)
+	assert.Equal(t, []string{"12A", "3C"}, b.Passengers[0].Seats)
+}
+
+func TestNewBooking_WithoutSegments(t *testing.T) {
+	flight := database.Flight{ID: uuid.New(), FlightNumber: "FB101"}
+	order := &database.Order{FlightID: flight.ID, Seats: []string{"7F"}}
+
+	b := newBooking(order, []database.Flight{flight})
+
+	assert.Empty(t, b.Reference)
+	require.Len(t, b.Flights, 1)
+	assert.Equal(t, []string{"7F"}, b.Flights[0].Seats)
+	assert.Empty(t, b.Passengers)
+}
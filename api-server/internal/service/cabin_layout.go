@@ -0,0 +1,217 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/cx-tal-miterani/flight-booking-system/api-server/internal/database"
+)
+
+// DefaultCabinLayout is used when a flight is created without a layout. It
+// matches the seat inventory generated by the seed data.
+const DefaultCabinLayout = "narrowbody-standard"
+
+var (
+	// aircraftTypeCodePattern matches ICAO aircraft type designators
+	aircraftTypeCodePattern = regexp.MustCompile(`^[A-Z0-9]{2,4}$`)
+	// aircraftTypeIATACodePattern matches IATA aircraft type codes
+	aircraftTypeIATACodePattern = regexp.MustCompile(`^[A-Z0-9]{3}$`)
+	// cabinLayoutNamePattern matches lower-case, hyphenated layout names
+	cabinLayoutNamePattern = regexp.MustCompile(`^[a-z0-9]+(-[a-z0-9]+)*$`)
+	// seatColumnsPattern matches a group of seat column letters
+	seatColumnsPattern = regexp.MustCompile(`^[A-Z]+$`)
+)
+
+// CreateAircraftTypeRequest represents an admin request to add an aircraft type
+type CreateAircraftTypeRequest struct {
+	Code         string `json:"code"`
+	IATACode     string `json:"iataCode,omitempty"`
+	Manufacturer string `json:"manufacturer"`
+	Model        string `json:"model"`
+}
+
+// CreateCabinLayoutRequest represents an admin request to add a cabin layout
+type CreateCabinLayoutRequest struct {
+	Name         string           `json:"name"`
+	AircraftType string           `json:"aircraftType"`
+	Description  string           `json:"description"`
+	Cabins       []database.Cabin `json:"cabins"`
+}
+
+// GetAircraftTypes returns all aircraft types
+func (s *BookingService) GetAircraftTypes(ctx context.Context) ([]database.AircraftType, error) {
+	return s.repo.GetAircraftTypes(ctx)
+}
+
+// CreateAircraftType adds an aircraft type
+func (s *BookingService) CreateAircraftType(ctx context.Context, req CreateAircraftTypeRequest) (*database.AircraftType, error) {
+	t := &database.AircraftType{
+		Code:         strings.ToUpper(strings.TrimSpace(req.Code)),
+		IATACode:     strings.ToUpper(strings.TrimSpace(req.IATACode)),
+		Manufacturer: strings.TrimSpace(req.Manufacturer),
+		Model:        strings.TrimSpace(req.Model),
+	}
+	if !aircraftTypeCodePattern.MatchString(t.Code) {
+		return nil, fmt.Errorf("%w: invalid aircraft type code %q", ErrInvalidInput, t.Code)
+	}
+	if t.IATACode != "" && !aircraftTypeIATACodePattern.MatchString(t.IATACode) {
+		return nil, fmt.Errorf("%w: invalid IATA aircraft type code %q", ErrInvalidInput, t.IATACode)
+	}
+	if t.Manufacturer == "" || t.Model == "" {
+		return nil, fmt.Errorf("%w: manufacturer and model are required", ErrInvalidInput)
+	}
+
+	if err := s.repo.CreateAircraftType(ctx, t); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+// GetCabinLayouts returns the cabin layouts flights can be created with
+func (s *BookingService) GetCabinLayouts(ctx context.Context) ([]database.CabinLayout, error) {
+	return s.repo.GetCabinLayouts(ctx)
+}
+
+// CreateCabinLayout validates and adds a cabin layout template
+func (s *BookingService) CreateCabinLayout(ctx context.Context, req CreateCabinLayoutRequest) (*database.CabinLayout, error) {
+	l := &database.CabinLayout{
+		Name:         strings.TrimSpace(req.Name),
+		AircraftType: strings.ToUpper(strings.TrimSpace(req.AircraftType)),
+		Description:  strings.TrimSpace(req.Description),
+		Cabins:       req.Cabins,
+	}
+	if err := validateCabinLayout(l); err != nil {
+		return nil, err
+	}
+
+	if _, err := s.repo.GetAircraftType(ctx, l.AircraftType); err != nil {
+		if errors.Is(err, database.ErrNotFound) {
+			return nil, fmt.Errorf("%w: unknown aircraft type %q", ErrInvalidInput, l.AircraftType)
+		}
+		return nil, err
+	}
+
+	if err := s.repo.CreateCabinLayout(ctx, l); err != nil {
+		return nil, err
+	}
+	return l, nil
+}
+
+// aircraftCabinLayout returns the default layout of an aircraft type, its
+// first layout by name
+func (s *BookingService) aircraftCabinLayout(ctx context.Context, aircraftType string) (*database.CabinLayout, error) {
+	layouts, err := s.repo.GetCabinLayouts(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for i := range layouts {
+		if layouts[i].AircraftType == aircraftType {
+			return &layouts[i], nil
+		}
+	}
+	return nil, fmt.Errorf("%w: aircraft type %s has no cabin layout", ErrInvalidInput, aircraftType)
+}
+
+// cabinLayout loads the layout a flight's seats are generated from
+func (s *BookingService) cabinLayout(ctx context.Context, name string) (*database.CabinLayout, error) {
+	layout, err := s.repo.GetCabinLayout(ctx, name)
+	if err != nil {
+		if errors.Is(err, database.ErrNotFound) {
+			return nil, fmt.Errorf("%w: unknown cabin layout %q", ErrInvalidInput, name)
+		}
+		return nil, err
+	}
+	return layout, nil
+}
+
+// validateCabinLayout checks that a layout describes a physically sensible
+// cabin: cabins occupy distinct row ranges, columns are unique letters and
+// every referenced row or seat lies within its cabin.
+func validateCabinLayout(l *database.CabinLayout) error {
+	if !cabinLayoutNamePattern.MatchString(l.Name) {
+		return fmt.Errorf("%w: invalid cabin layout name %q", ErrInvalidInput, l.Name)
+	}
+	if l.AircraftType == "" {
+		return fmt.Errorf("%w: aircraft type is required", ErrInvalidInput)
+	}
+	if len(l.Cabins) == 0 {
+		return fmt.Errorf("%w: at least one cabin is required", ErrInvalidInput)
+	}
+
+	for i, cabin := range l.Cabins {
+		if !validSeatClasses[cabin.Class] {
+			return fmt.Errorf("%w: cabin %d: invalid class %q", ErrInvalidInput, i+1, cabin.Class)
+		}
+		if cabin.FirstRow < 1 || cabin.LastRow < cabin.FirstRow {
+			return fmt.Errorf("%w: cabin %d: invalid rows %d-%d", ErrInvalidInput, i+1, cabin.FirstRow, cabin.LastRow)
+		}
+		if i > 0 && cabin.FirstRow <= l.Cabins[i-1].LastRow {
+			return fmt.Errorf("%w: cabin %d: rows overlap the previous cabin", ErrInvalidInput, i+1)
+		}
+		if cabin.PriceMultiplier <= 0 {
+			return fmt.Errorf("%w: cabin %d: price multiplier must be positive", ErrInvalidInput, i+1)
+		}
+
+		if len(cabin.ColumnGroups) == 0 {
+			return fmt.Errorf("%w: cabin %d: at least one column group is required", ErrInvalidInput, i+1)
+		}
+		seen := make(map[rune]bool)
+		for _, group := range cabin.ColumnGroups {
+			if !seatColumnsPattern.MatchString(group) {
+				return fmt.Errorf("%w: cabin %d: invalid column group %q", ErrInvalidInput, i+1, group)
+			}
+			for _, col := range group {
+				if seen[col] {
+					return fmt.Errorf("%w: cabin %d: duplicate column %c", ErrInvalidInput, i+1, col)
+				}
+				seen[col] = true
+			}
+		}
+
+		for _, rows := range [][]int{cabin.SkipRows, cabin.ExitRows, cabin.ExtraLegroomRows, cabin.LavatoryRows} {
+			for _, row := range rows {
+				if row < cabin.FirstRow || row > cabin.LastRow {
+					return fmt.Errorf("%w: cabin %d: row %d is outside rows %d-%d", ErrInvalidInput, i+1, row, cabin.FirstRow, cabin.LastRow)
+				}
+			}
+		}
+		skipped := make(map[int]bool, len(cabin.SkipRows))
+		for _, row := range cabin.SkipRows {
+			skipped[row] = true
+		}
+		for _, rows := range []struct {
+			kind string
+			rows []int
+		}{
+			{"exit", cabin.ExitRows},
+			{"extra legroom", cabin.ExtraLegroomRows},
+			{"lavatory", cabin.LavatoryRows},
+		} {
+			for _, row := range rows.rows {
+				if skipped[row] {
+					return fmt.Errorf("%w: cabin %d: %s row %d is skipped", ErrInvalidInput, i+1, rows.kind, row)
+				}
+			}
+		}
+
+		positions := make(map[string]bool)
+		for _, row := range cabin.Rows() {
+			for _, col := range cabin.Columns() {
+				positions[fmt.Sprintf("%d%c", row, col)] = true
+			}
+		}
+		for _, seat := range cabin.MissingSeats {
+			if !positions[seat] {
+				return fmt.Errorf("%w: cabin %d: missing seat %q is not in the cabin", ErrInvalidInput, i+1, seat)
+			}
+		}
+	}
+
+	if len(l.GenerateSeats(0)) == 0 {
+		return fmt.Errorf("%w: layout has no seats", ErrInvalidInput)
+	}
+	return nil
+}
@@ -0,0 +1,75 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/cx-tal-miterani/flight-booking-system/api-server/internal/database"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateCabinLayout(t *testing.T) {
+	valid := func() *database.CabinLayout {
+		return &database.CabinLayout{
+			Name:         "narrowbody-dense",
+			AircraftType: "A320",
+			Cabins: []database.Cabin{
+				{Class: "business", FirstRow: 1, LastRow: 3, ColumnGroups: []string{"AC", "DF"}, PriceMultiplier: 1.5},
+				{Class: "economy", FirstRow: 5, LastRow: 32, ColumnGroups: []string{"ABC", "DEF"}, PriceMultiplier: 1,
+					SkipRows: []int{13}, ExitRows: []int{12, 14}, MissingSeats: []string{"32A"}},
+			},
+		}
+	}
+
+	tests := []struct {
+		name   string
+		modify func(l *database.CabinLayout)
+		valid  bool
+	}{
+		{name: "valid", modify: func(l *database.CabinLayout) {}, valid: true},
+		{name: "invalid name", modify: func(l *database.CabinLayout) { l.Name = "Dense Layout" }},
+		{name: "missing aircraft type", modify: func(l *database.CabinLayout) { l.AircraftType = "" }},
+		{name: "no cabins", modify: func(l *database.CabinLayout) { l.Cabins = nil }},
+		{name: "unknown class", modify: func(l *database.CabinLayout) { l.Cabins[0].Class = "steerage" }},
+		{name: "inverted rows", modify: func(l *database.CabinLayout) { l.Cabins[0].FirstRow = 4 }},
+		{name: "row zero", modify: func(l *database.CabinLayout) { l.Cabins[0].FirstRow = 0 }},
+		{name: "overlapping cabins", modify: func(l *database.CabinLayout) { l.Cabins[1].FirstRow = 3 }},
+		{name: "free seats", modify: func(l *database.CabinLayout) { l.Cabins[1].PriceMultiplier = 0 }},
+		{name: "no columns", modify: func(l *database.CabinLayout) { l.Cabins[0].ColumnGroups = nil }},
+		{name: "lower-case column", modify: func(l *database.CabinLayout) { l.Cabins[0].ColumnGroups = []string{"ac"} }},
+		{name: "empty column group", modify: func(l *database.CabinLayout) { l.Cabins[0].ColumnGroups = []string{"AC", ""} }},
+		{name: "duplicate column", modify: func(l *database.CabinLayout) { l.Cabins[0].ColumnGroups = []string{"AC", "CD"} }},
+		{name: "exit row outside cabin", modify: func(l *database.CabinLayout) { l.Cabins[1].ExitRows = []int{40} }},
+		{name: "skipped exit row", modify: func(l *database.CabinLayout) { l.Cabins[1].ExitRows = []int{13} }},
+		{name: "extra legroom row outside cabin", modify: func(l *database.CabinLayout) { l.Cabins[1].ExtraLegroomRows = []int{2} }},
+		{name: "skipped lavatory row", modify: func(l *database.CabinLayout) { l.Cabins[1].LavatoryRows = []int{13} }},
+		{name: "skip row outside cabin", modify: func(l *database.CabinLayout) { l.Cabins[0].SkipRows = []int{4} }},
+		{name: "missing seat outside cabin", modify: func(l *database.CabinLayout) { l.Cabins[1].MissingSeats = []string{"32G"} }},
+		{name: "missing seat in skipped row", modify: func(l *database.CabinLayout) { l.Cabins[1].MissingSeats = []string{"13A"} }},
+		{name: "no seats", modify: func(l *database.CabinLayout) {
+			l.Cabins = []database.Cabin{{Class: "economy", FirstRow: 1, LastRow: 1, ColumnGroups: []string{"A"},
+				PriceMultiplier: 1, MissingSeats: []string{"1A"}}}
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l := valid()
+			tt.modify(l)
+			err := validateCabinLayout(l)
+			if tt.valid {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorIs(t, err, ErrInvalidInput)
+			}
+		})
+	}
+}
+
+func TestAircraftTypeCodePattern(t *testing.T) {
+	for _, code := range []string{"A320", "B789", "E75", "A21N"} {
+		assert.True(t, aircraftTypeCodePattern.MatchString(code), code)
+	}
+	for _, code := range []string{"", "A", "A320NEO", "a320", "A-32"} {
+		assert.False(t, aircraftTypeCodePattern.MatchString(code), code)
+	}
+}
@@ -0,0 +1,186 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/cx-tal-miterani/flight-booking-system/api-server/internal/database"
+	"github.com/google/uuid"
+)
+
+// flightNumberPattern matches an airline designator followed by 1-4 digits
+var flightNumberPattern = regexp.MustCompile(`^[A-Z0-9]{2}[0-9]{1,4}[A-Z]?$`)
+
+// CreateFlightRequest represents an admin request to create a flight
+type CreateFlightRequest struct {
+	FlightNumber  string    `json:"flightNumber"`
+	Origin        string    `json:"origin"`
+	Destination   string    `json:"destination"`
+	DepartureTime time.Time `json:"departureTime"`
+	ArrivalTime   time.Time `json:"arrivalTime"`
+	PricePerSeat  float64   `json:"pricePerSeat"`
+	CabinLayout   string    `json:"cabinLayout,omitempty"`
+}
+
+// UpdateFlightRequest represents an admin request to edit or reschedule a
+// flight. Only the fields that are set are changed.
+type UpdateFlightRequest struct {
+	FlightNumber  *string    `json:"flightNumber,omitempty"`
+	Origin        *string    `json:"origin,omitempty"`
+	Destination   *string    `json:"destination,omitempty"`
+	DepartureTime *time.Time `json:"departureTime,omitempty"`
+	ArrivalTime   *time.Time `json:"arrivalTime,omitempty"`
+	PricePerSeat  *float64   `json:"pricePerSeat,omitempty"`
+	CabinLayout   *string    `json:"cabinLayout,omitempty"`
+}
+
+// CreateFlight creates a flight and generates its seats from a cabin layout
+func (s *BookingService) CreateFlight(ctx context.Context, req CreateFlightRequest) (*database.Flight, error) {
+	if req.CabinLayout == "" {
+		req.CabinLayout = DefaultCabinLayout
+	}
+
+	f := &database.Flight{
+		FlightNumber:  strings.ToUpper(strings.TrimSpace(req.FlightNumber)),
+		Origin:        req.Origin,
+		Destination:   req.Destination,
+		DepartureTime: req.DepartureTime,
+		ArrivalTime:   req.ArrivalTime,
+		PricePerSeat:  req.PricePerSeat,
+		CabinLayout:   req.CabinLayout,
+	}
+	if err := s.validateFlight(ctx, f); err != nil {
+		return nil, err
+	}
+
+	layout, err := s.cabinLayout(ctx, f.CabinLayout)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.repo.CreateFlight(ctx, f, layout.GenerateSeats(f.PricePerSeat)); err != nil {
+		return nil, err
+	}
+
+	return s.repo.GetFlightByID(ctx, f.ID)
+}
+
+// UpdateFlight edits or reschedules a flight. Changing the price or cabin
+// layout regenerates the available seats; it is rejected if a held or booked
+// seat is not part of the new layout.
+func (s *BookingService) UpdateFlight(ctx context.Context, id string, req UpdateFlightRequest) (*database.Flight, error) {
+	flightID, err := uuid.Parse(id)
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid flight ID", ErrInvalidInput)
+	}
+
+	f, err := s.repo.GetFlightByID(ctx, flightID)
+	if err != nil {
+		return nil, err
+	}
+	if !f.DepartureTime.After(time.Now()) {
+		return nil, fmt.Errorf("%w: flight %s has already departed", ErrInvalidInput, f.FlightNumber)
+	}
+	if !f.Status.IsBookable() {
+		return nil, fmt.Errorf("%w: flight %s is %s", ErrInvalidInput, f.FlightNumber, f.Status)
+	}
+
+	regenerateSeats := false
+	if req.FlightNumber != nil {
+		f.FlightNumber = strings.ToUpper(strings.TrimSpace(*req.FlightNumber))
+	}
+	if req.Origin != nil {
+		f.Origin = *req.Origin
+	}
+	if req.Destination != nil {
+		f.Destination = *req.Destination
+	}
+	if req.DepartureTime != nil {
+		f.DepartureTime = *req.DepartureTime
+	}
+	if req.ArrivalTime != nil {
+		f.ArrivalTime = *req.ArrivalTime
+	}
+	if req.PricePerSeat != nil && *req.PricePerSeat != f.PricePerSeat {
+		f.PricePerSeat = *req.PricePerSeat
+		regenerateSeats = true
+	}
+	if req.CabinLayout != nil && *req.CabinLayout != f.CabinLayout {
+		f.CabinLayout = *req.CabinLayout
+		regenerateSeats = true
+	}
+
+	if err := s.validateFlight(ctx, f); err != nil {
+		return nil, err
+	}
+
+	var seats []database.Seat
+	if regenerateSeats {
+		layout, err := s.cabinLayout(ctx, f.CabinLayout)
+		if err != nil {
+			return nil, err
+		}
+		seats = layout.GenerateSeats(f.PricePerSeat)
+	}
+	if err := s.repo.UpdateFlight(ctx, f, seats); err != nil {
+		return nil, err
+	}
+
+	return s.repo.GetFlightByID(ctx, f.ID)
+}
+
+// DeleteFlight deletes a flight that has no orders or reserved seats
+func (s *BookingService) DeleteFlight(ctx context.Context, id string) error {
+	flightID, err := uuid.Parse(id)
+	if err != nil {
+		return fmt.Errorf("%w: invalid flight ID", ErrInvalidInput)
+	}
+	return s.repo.DeleteFlight(ctx, flightID)
+}
+
+// validateFlight checks a flight's details and normalizes its airport codes
+func (s *BookingService) validateFlight(ctx context.Context, f *database.Flight) error {
+	if !flightNumberPattern.MatchString(f.FlightNumber) {
+		return fmt.Errorf("%w: invalid flight number %q", ErrInvalidInput, f.FlightNumber)
+	}
+
+	origin, err := s.resolveAirport(ctx, f.Origin)
+	if err != nil {
+		return err
+	}
+	destination, err := s.resolveAirport(ctx, f.Destination)
+	if err != nil {
+		return err
+	}
+	if origin == "" || destination == "" {
+		return fmt.Errorf("%w: origin and destination are required", ErrInvalidInput)
+	}
+	if origin == destination {
+		return fmt.Errorf("%w: origin and destination must differ", ErrInvalidInput)
+	}
+	f.Origin, f.Destination = origin, destination
+
+	return validateFlightDetails(f, time.Now())
+}
+
+// validateFlightDetails checks the schedule and fare of a flight
+func validateFlightDetails(f *database.Flight, now time.Time) error {
+	if f.DepartureTime.IsZero() || f.ArrivalTime.IsZero() {
+		return fmt.Errorf("%w: departure and arrival times are required", ErrInvalidInput)
+	}
+	if !f.DepartureTime.After(now) {
+		return fmt.Errorf("%w: departure time must be in the future", ErrInvalidInput)
+	}
+	if !f.ArrivalTime.After(f.DepartureTime) {
+		return fmt.Errorf("%w: arrival time must be after departure time", ErrInvalidInput)
+	}
+	if f.ArrivalTime.Sub(f.DepartureTime) > maxLegDuration {
+		return fmt.Errorf("%w: flight duration exceeds %s", ErrInvalidInput, maxLegDuration)
+	}
+	if f.PricePerSeat <= 0 {
+		return fmt.Errorf("%w: price per seat must be positive", ErrInvalidInput)
+	}
+	return nil
+}
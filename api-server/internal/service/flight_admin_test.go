@@ -0,0 +1,58 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/cx-tal-miterani/flight-booking-system/api-server/internal/database"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateFlightDetails(t *testing.T) {
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	valid := func() *database.Flight {
+		return &database.Flight{
+			FlightNumber:  "AA101",
+			DepartureTime: now.Add(24 * time.Hour),
+			ArrivalTime:   now.Add(30 * time.Hour),
+			PricePerSeat:  199.99,
+			CabinLayout:   DefaultCabinLayout,
+		}
+	}
+
+	tests := []struct {
+		name   string
+		modify func(f *database.Flight)
+		valid  bool
+	}{
+		{name: "valid", modify: func(f *database.Flight) {}, valid: true},
+		{name: "departed", modify: func(f *database.Flight) { f.DepartureTime = now.Add(-time.Hour) }},
+		{name: "arrives before departure", modify: func(f *database.Flight) { f.ArrivalTime = f.DepartureTime }},
+		{name: "too long", modify: func(f *database.Flight) { f.ArrivalTime = f.DepartureTime.Add(20 * time.Hour) }},
+		{name: "free", modify: func(f *database.Flight) { f.PricePerSeat = 0 }},
+		{name: "missing arrival", modify: func(f *database.Flight) { f.ArrivalTime = time.Time{} }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := valid()
+			tt.modify(f)
+
+			err := validateFlightDetails(f, now)
+			if tt.valid {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorIs(t, err, ErrInvalidInput)
+			}
+		})
+	}
+}
+
+func TestFlightNumberPattern(t *testing.T) {
+	for _, n := range []string{"AA101", "U21", "B61234", "DL303A"} {
+		assert.True(t, flightNumberPattern.MatchString(n), n)
+	}
+	for _, n := range []string{"", "A-101", "AA", "AA12345", "aa101"} {
+		assert.False(t, flightNumberPattern.MatchString(n), n)
+	}
+}
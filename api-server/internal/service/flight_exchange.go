@@ -0,0 +1,154 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/cx-tal-miterani/flight-booking-system/api-server/internal/database"
+	"github.com/google/uuid"
+	"go.temporal.io/sdk/client"
+)
+
+const (
+	// FlightChangeFee is charged per seat moved to another flight, on top of
+	// the fare difference
+	FlightChangeFee = 50.0
+	// flightExchangeHold is how long the seats on the new flight of a flight
+	// change are held while the amount due is settled
+	flightExchangeHold = 15 * time.Minute
+)
+
+// FlightExchangeRequest moves a confirmed order from one of its flights to
+// another flight on the same route. PaymentCode pays the fare difference and
+// change fee when they are owed.
+type FlightExchangeRequest struct {
+	FromFlightID string `json:"fromFlightId"`
+	ToFlightID   string `json:"toFlightId"`
+	PaymentCode  string `json:"paymentCode,omitempty"`
+}
+
+// ExchangeFlight starts a flight change of a confirmed order. A
+// FlightExchangeWorkflow holds seats on the new flight, charges or refunds
+// the fare difference and change fee, books the new seats and then releases
+// the old ones. The exchange is returned pending; the order lists it with
+// its outcome.
+func (s *BookingService) ExchangeFlight(ctx context.Context, orderID string, req FlightExchangeRequest) (*database.FlightExchange, error) {
+	oid, err := uuid.Parse(orderID)
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid order ID", ErrInvalidInput)
+	}
+	fromID, err := uuid.Parse(req.FromFlightID)
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid fromFlightId", ErrInvalidInput)
+	}
+	toID, err := uuid.Parse(req.ToFlightID)
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid toFlightId", ErrInvalidInput)
+	}
+	if fromID == toID {
+		return nil, fmt.Errorf("%w: the new flight must differ from the current one", ErrInvalidInput)
+	}
+
+	order, err := s.repo.GetOrderByID(ctx, oid)
+	if err != nil {
+		return nil, err
+	}
+	if order.Status != database.OrderStatusConfirmed {
+		return nil, fmt.Errorf("%w: only confirmed orders can change flights", database.ErrOrderNotModifiable)
+	}
+	segment := -1
+	for i, seg := range order.Segments {
+		if seg.FlightID == toID {
+			return nil, fmt.Errorf("%w: the order is already booked on the new flight", ErrInvalidInput)
+		}
+		if seg.FlightID == fromID {
+			segment = i
+		}
+	}
+	if segment < 0 {
+		return nil, fmt.Errorf("%w: the order is not booked on flight %s", ErrInvalidInput, fromID)
+	}
+
+	from, err := s.repo.GetFlightByID(ctx, fromID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get flight: %w", err)
+	}
+	to, err := s.repo.GetFlightByID(ctx, toID)
+	if errors.Is(err, database.ErrNotFound) {
+		return nil, fmt.Errorf("%w: flight %s does not exist", ErrInvalidInput, toID)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get flight: %w", err)
+	}
+	var prev, next *database.Flight
+	if segment > 0 {
+		if prev, err = s.repo.GetFlightByID(ctx, order.Segments[segment-1].FlightID); err != nil {
+			return nil, fmt.Errorf("failed to get flight: %w", err)
+		}
+	}
+	if segment < len(order.Segments)-1 {
+		if next, err = s.repo.GetFlightByID(ctx, order.Segments[segment+1].FlightID); err != nil {
+			return nil, fmt.Errorf("failed to get flight: %w", err)
+		}
+	}
+	if err := validateFlightExchange(from, to, prev, next, time.Now()); err != nil {
+		return nil, err
+	}
+
+	id := uuid.New()
+	exchange, err := s.repo.CreateFlightExchange(ctx, &database.FlightExchange{
+		ID:            id,
+		OrderID:       oid,
+		FromFlightID:  fromID,
+		ToFlightID:    toID,
+		WorkflowID:    fmt.Sprintf("flight-exchange-%s", id),
+		HoldExpiresAt: time.Now().Add(flightExchangeHold),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	workflowOptions := client.StartWorkflowOptions{
+		ID:        exchange.WorkflowID,
+		TaskQueue: "flight-booking-queue",
+	}
+	workflowInput := map[string]interface{}{
+		"exchangeId":  exchange.ID.String(),
+		"orderId":     orderID,
+		"changeFee":   FlightChangeFee,
+		"paymentCode": req.PaymentCode,
+	}
+	if _, err := s.temporalClient.ExecuteWorkflow(ctx, workflowOptions, "FlightExchangeWorkflow", workflowInput); err != nil {
+		if ferr := s.repo.FailFlightExchange(ctx, exchange.ID, "flight change could not be started"); ferr != nil {
+			fmt.Printf("Warning: failed to fail flight exchange %s: %v\n", exchange.ID, ferr)
+		}
+		return nil, fmt.Errorf("failed to start flight exchange workflow: %w", err)
+	}
+
+	return exchange, nil
+}
+
+// validateFlightExchange checks that an order can move from one flight to
+// another: the new flight must be bookable, depart in the future on the same
+// route and still connect with the order's previous and next flights, if
+// any
+func validateFlightExchange(from, to, prev, next *database.Flight, now time.Time) error {
+	if to.Origin != from.Origin || to.Destination != from.Destination {
+		return fmt.Errorf("%w: flight %s does not fly %s to %s", ErrInvalidInput, to.FlightNumber, from.Origin, from.Destination)
+	}
+	if !to.Status.IsBookable() {
+		return fmt.Errorf("%w: flight %s is %s", ErrInvalidInput, to.FlightNumber, to.Status)
+	}
+	if !to.DepartureTime.After(now) {
+		return fmt.Errorf("%w: flight %s has already departed", ErrInvalidInput, to.FlightNumber)
+	}
+	if prev != nil && !to.DepartureTime.After(prev.ArrivalTime) {
+		return fmt.Errorf("%w: flight %s departs before flight %s arrives", ErrInvalidInput, to.FlightNumber, prev.FlightNumber)
+	}
+	if next != nil && !to.ArrivalTime.Before(next.DepartureTime) {
+		return fmt.Errorf("%w: flight %s arrives after flight %s departs", ErrInvalidInput, to.FlightNumber, next.FlightNumber)
+	}
+	return nil
+}
@@ -0,0 +1,51 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/cx-tal-miterani/flight-booking-system/api-server/internal/database"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateFlightExchange(t *testing.T) {
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	flight := func(number, origin, destination string, departure time.Time) *database.Flight {
+		return &database.Flight{
+			FlightNumber:  number,
+			Origin:        origin,
+			Destination:   destination,
+			DepartureTime: departure,
+			ArrivalTime:   departure.Add(3 * time.Hour),
+			Status:        database.FlightStatusScheduled,
+		}
+	}
+	from := flight("FL100", "JFK", "LAX", now.Add(24*time.Hour))
+	later := flight("FL102", "JFK", "LAX", now.Add(30*time.Hour))
+
+	assert.NoError(t, validateFlightExchange(from, later, nil, nil, now))
+
+	cancelled := flight("FL104", "JFK", "LAX", now.Add(30*time.Hour))
+	cancelled.Status = database.FlightStatusCancelled
+
+	invalid := map[string]struct {
+		to, prev, next *database.Flight
+	}{
+		"other route":     {to: flight("FL200", "JFK", "SFO", now.Add(30*time.Hour))},
+		"not bookable":    {to: cancelled},
+		"departed":        {to: flight("FL106", "JFK", "LAX", now.Add(-time.Hour))},
+		"misses next":     {to: later, next: flight("FL300", "LAX", "SEA", now.Add(31*time.Hour))},
+		"before previous": {to: later, prev: flight("FL050", "BOS", "JFK", now.Add(28*time.Hour))},
+	}
+	for name, tt := range invalid {
+		t.Run(name, func(t *testing.T) {
+			assert.ErrorIs(t, validateFlightExchange(from, tt.to, tt.prev, tt.next, now), ErrInvalidInput)
+		})
+	}
+
+	t.Run("connects", func(t *testing.T) {
+		prev := flight("FL050", "BOS", "JFK", now.Add(20*time.Hour))
+		next := flight("FL300", "LAX", "SEA", now.Add(36*time.Hour))
+		assert.NoError(t, validateFlightExchange(from, later, prev, next, now))
+	})
+}
@@ -0,0 +1,141 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/cx-tal-miterani/flight-booking-system/api-server/internal/database"
+	"github.com/cx-tal-miterani/flight-booking-system/api-server/internal/websocket"
+	"github.com/google/uuid"
+	"go.temporal.io/api/serviceerror"
+	"go.temporal.io/sdk/client"
+)
+
+// UpdateFlightStatusRequest represents an admin request to change a flight's
+// operational status. Delays require an estimated departure time.
+type UpdateFlightStatusRequest struct {
+	Status                 database.FlightStatus `json:"status"`
+	Reason                 string                `json:"reason,omitempty"`
+	EstimatedDepartureTime *time.Time            `json:"estimatedDepartureTime,omitempty"`
+	EstimatedArrivalTime   *time.Time            `json:"estimatedArrivalTime,omitempty"`
+}
+
+// flightStatusTransitions lists the statuses each status can change to.
+// Cancelled and arrived flights are final; cancelling a cancelled flight
+// again restarts its disruption handling.
+var flightStatusTransitions = map[database.FlightStatus][]database.FlightStatus{
+	database.FlightStatusScheduled: {database.FlightStatusDelayed, database.FlightStatusCancelled, database.FlightStatusBoarding},
+	database.FlightStatusDelayed:   {database.FlightStatusScheduled, database.FlightStatusDelayed, database.FlightStatusCancelled, database.FlightStatusBoarding},
+	database.FlightStatusBoarding:  {database.FlightStatusDelayed, database.FlightStatusCancelled, database.FlightStatusDeparted},
+	database.FlightStatusDeparted:  {database.FlightStatusArrived},
+	database.FlightStatusCancelled: {database.FlightStatusCancelled},
+}
+
+// UpdateFlightStatus changes a flight's operational status. Cancelling a
+// flight starts the disruption workflow that offers its confirmed orders
+// rebooking or a refund; boarding settles its overbooked travelers.
+func (s *BookingService) UpdateFlightStatus(ctx context.Context, id string, req UpdateFlightStatusRequest) (*database.Flight, error) {
+	flightID, err := uuid.Parse(id)
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid flight ID", ErrInvalidInput)
+	}
+
+	f, err := s.repo.GetFlightByID(ctx, flightID)
+	if err != nil {
+		return nil, err
+	}
+	if err := applyFlightStatus(f, req); err != nil {
+		return nil, err
+	}
+
+	if f.Status == database.FlightStatusBoarding {
+		// Boarding and settling overbooked travelers succeed or fail
+		// together, so a failed close-out can be retried
+		if err := s.boardFlight(ctx, f); err != nil {
+			return nil, err
+		}
+	} else if err := s.repo.UpdateFlightStatus(ctx, f); err != nil {
+		return nil, err
+	}
+
+	if f.Status == database.FlightStatusCancelled {
+		if err := s.startDisruptionWorkflow(ctx, f); err != nil {
+			return nil, err
+		}
+	}
+	websocket.GetHub().BroadcastFlightStatus(f.ID.String(), string(f.Status))
+
+	return s.repo.GetFlightByID(ctx, f.ID)
+}
+
+// startDisruptionWorkflow starts handling the cancellation of a flight. The
+// workflow ID is derived from the flight so it runs at most once at a time.
+func (s *BookingService) startDisruptionWorkflow(ctx context.Context, f *database.Flight) error {
+	reason := ""
+	if f.StatusReason != nil {
+		reason = *f.StatusReason
+	}
+
+	workflowOptions := client.StartWorkflowOptions{
+		ID:        fmt.Sprintf("flight-disruption-%s", f.ID.String()),
+		TaskQueue: "flight-booking-queue",
+	}
+	workflowInput := map[string]interface{}{
+		"flightId":     f.ID.String(),
+		"flightNumber": f.FlightNumber,
+		"reason":       reason,
+	}
+
+	_, err := s.temporalClient.ExecuteWorkflow(ctx, workflowOptions, "FlightDisruptionWorkflow", workflowInput)
+	var alreadyStarted *serviceerror.WorkflowExecutionAlreadyStarted
+	if err != nil && !errors.As(err, &alreadyStarted) {
+		return fmt.Errorf("failed to start disruption workflow: %w", err)
+	}
+	return nil
+}
+
+// applyFlightStatus validates a status change and applies it to f
+func applyFlightStatus(f *database.Flight, req UpdateFlightStatusRequest) error {
+	allowed := false
+	for _, next := range flightStatusTransitions[f.Status] {
+		if next == req.Status {
+			allowed = true
+			break
+		}
+	}
+	if !allowed {
+		return fmt.Errorf("%w: cannot change flight status from %s to %q", ErrInvalidInput, f.Status, req.Status)
+	}
+
+	switch req.Status {
+	case database.FlightStatusDelayed:
+		if req.EstimatedDepartureTime == nil {
+			return fmt.Errorf("%w: a delay requires an estimated departure time", ErrInvalidInput)
+		}
+		departure := *req.EstimatedDepartureTime
+		if !departure.After(f.DepartureTime) {
+			return fmt.Errorf("%w: estimated departure time must be after the scheduled departure time", ErrInvalidInput)
+		}
+		arrival := departure.Add(f.ArrivalTime.Sub(f.DepartureTime))
+		if req.EstimatedArrivalTime != nil {
+			arrival = *req.EstimatedArrivalTime
+		}
+		if !arrival.After(departure) {
+			return fmt.Errorf("%w: estimated arrival time must be after estimated departure time", ErrInvalidInput)
+		}
+		f.EstimatedDepartureTime, f.EstimatedArrivalTime = &departure, &arrival
+	case database.FlightStatusScheduled:
+		// Back on schedule
+		f.EstimatedDepartureTime, f.EstimatedArrivalTime = nil, nil
+		f.StatusReason = nil
+	}
+
+	f.Status = req.Status
+	if reason := strings.TrimSpace(req.Reason); reason != "" {
+		f.StatusReason = &reason
+	}
+	return nil
+}
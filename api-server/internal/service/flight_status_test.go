@@ -0,0 +1,119 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/cx-tal-miterani/flight-booking-system/api-server/internal/database"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestApplyFlightStatus_Transitions(t *testing.T) {
+	departure := time.Date(2030, 3, 1, 10, 0, 0, 0, time.UTC)
+	delayedUntil := departure.Add(2 * time.Hour)
+
+	tests := []struct {
+		from  database.FlightStatus
+		to    database.FlightStatus
+		valid bool
+	}{
+		{database.FlightStatusScheduled, database.FlightStatusDelayed, true},
+		{database.FlightStatusScheduled, database.FlightStatusCancelled, true},
+		{database.FlightStatusScheduled, database.FlightStatusBoarding, true},
+		{database.FlightStatusScheduled, database.FlightStatusDeparted, false},
+		{database.FlightStatusScheduled, database.FlightStatusArrived, false},
+		{database.FlightStatusDelayed, database.FlightStatusDelayed, true},
+		{database.FlightStatusDelayed, database.FlightStatusScheduled, true},
+		{database.FlightStatusBoarding, database.FlightStatusDeparted, true},
+		{database.FlightStatusBoarding, database.FlightStatusScheduled, false},
+		{database.FlightStatusDeparted, database.FlightStatusArrived, true},
+		{database.FlightStatusDeparted, database.FlightStatusCancelled, false},
+		{database.FlightStatusArrived, database.FlightStatusDeparted, false},
+		{database.FlightStatusCancelled, database.FlightStatusScheduled, false},
+		{database.FlightStatusCancelled, database.FlightStatusCancelled, true},
+		{database.FlightStatusScheduled, "diverted", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.from)+"->"+string(tt.to), func(t *testing.T) {
+			f := &database.Flight{
+				Status:        tt.from,
+				DepartureTime: departure,
+				ArrivalTime:   departure.Add(5 * time.Hour),
+			}
+			err := applyFlightStatus(f, UpdateFlightStatusRequest{Status: tt.to, EstimatedDepartureTime: &delayedUntil})
+			if tt.valid {
+				require.NoError(t, err)
+				assert.Equal(t, tt.to, f.Status)
+			} else {
+				assert.ErrorIs(t, err, ErrInvalidInput)
+				assert.Equal(t, tt.from, f.Status)
+			}
+		})
+	}
+}
+
+func TestApplyFlightStatus_Delay(t *testing.T) {
+	departure := time.Date(2030, 3, 1, 10, 0, 0, 0, time.UTC)
+	newFlight := func() *database.Flight {
+		return &database.Flight{
+			Status:        database.FlightStatusScheduled,
+			DepartureTime: departure,
+			ArrivalTime:   departure.Add(5 * time.Hour),
+		}
+	}
+
+	t.Run("estimated arrival keeps the flight duration", func(t *testing.T) {
+		f := newFlight()
+		estimated := departure.Add(90 * time.Minute)
+		err := applyFlightStatus(f, UpdateFlightStatusRequest{
+			Status:                 database.FlightStatusDelayed,
+			Reason:                 " Late inbound aircraft ",
+			EstimatedDepartureTime: &estimated,
+		})
+		require.NoError(t, err)
+		assert.Equal(t, estimated, *f.EstimatedDepartureTime)
+		assert.Equal(t, estimated.Add(5*time.Hour), *f.EstimatedArrivalTime)
+		assert.Equal(t, "Late inbound aircraft", *f.StatusReason)
+	})
+
+	t.Run("requires an estimated departure", func(t *testing.T) {
+		err := applyFlightStatus(newFlight(), UpdateFlightStatusRequest{Status: database.FlightStatusDelayed})
+		assert.ErrorIs(t, err, ErrInvalidInput)
+	})
+
+	t.Run("estimated departure must be later", func(t *testing.T) {
+		early := departure.Add(-time.Hour)
+		err := applyFlightStatus(newFlight(), UpdateFlightStatusRequest{
+			Status:                 database.FlightStatusDelayed,
+			EstimatedDepartureTime: &early,
+		})
+		assert.ErrorIs(t, err, ErrInvalidInput)
+	})
+
+	t.Run("estimated arrival must follow departure", func(t *testing.T) {
+		estimated := departure.Add(time.Hour)
+		err := applyFlightStatus(newFlight(), UpdateFlightStatusRequest{
+			Status:                 database.FlightStatusDelayed,
+			EstimatedDepartureTime: &estimated,
+			EstimatedArrivalTime:   &estimated,
+		})
+		assert.ErrorIs(t, err, ErrInvalidInput)
+	})
+
+	t.Run("back on schedule clears the delay", func(t *testing.T) {
+		f := newFlight()
+		estimated := departure.Add(time.Hour)
+		require.NoError(t, applyFlightStatus(f, UpdateFlightStatusRequest{
+			Status:                 database.FlightStatusDelayed,
+			Reason:                 "Weather",
+			EstimatedDepartureTime: &estimated,
+		}))
+
+		require.NoError(t, applyFlightStatus(f, UpdateFlightStatusRequest{Status: database.FlightStatusScheduled}))
+		assert.Nil(t, f.EstimatedDepartureTime)
+		assert.Nil(t, f.EstimatedArrivalTime)
+		assert.Nil(t, f.StatusReason)
+	})
+}
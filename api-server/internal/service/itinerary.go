@@ -0,0 +1,230 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/cx-tal-miterani/flight-booking-system/api-server/internal/database"
+)
+
+const (
+	// DefaultMinConnection is the shortest layover offered by default
+	DefaultMinConnection = 45 * time.Minute
+	// DefaultMaxConnection is the longest layover offered by default
+	DefaultMaxConnection = 6 * time.Hour
+	// MaxItineraryStops is the maximum number of intermediate stops
+	MaxItineraryStops = 2
+
+	// maxLegDuration bounds how far past the departure window connecting legs are loaded
+	maxLegDuration        = 18 * time.Hour
+	defaultItineraryLimit = 20
+	maxItineraryLimit     = 100
+)
+
+// ItinerarySearchRequest represents a search for direct and connecting itineraries
+type ItinerarySearchRequest struct {
+	Origin        string
+	Destination   string
+	DepartureFrom *time.Time
+	DepartureTo   *time.Time
+	MaxStops      *int
+	MinConnection time.Duration
+	MaxConnection time.Duration
+	MinSeats      int
+	Sort          string
+	Limit         int
+}
+
+// Itinerary is a priced journey made of one or more flight segments
+type Itinerary struct {
+	Segments           []database.Flight `json:"segments"`
+	Stops              int               `json:"stops"`
+	DepartureTime      time.Time         `json:"departureTime"`
+	ArrivalTime        time.Time         `json:"arrivalTime"`
+	DurationMinutes    int               `json:"durationMinutes"`
+	ConnectionsMinutes []int             `json:"connectionsMinutes"`
+	TotalPrice         float64           `json:"totalPrice"`
+}
+
+// itineraryOptions controls how connections are built
+type itineraryOptions struct {
+	windowStart   time.Time
+	windowEnd     time.Time
+	maxStops      int
+	minConnection time.Duration
+	maxConnection time.Duration
+}
+
+// SearchItineraries returns direct and connecting itineraries between two airports
+func (s *BookingService) SearchItineraries(ctx context.Context, req ItinerarySearchRequest) ([]Itinerary, error) {
+	if req.Origin == "" || req.Destination == "" {
+		return nil, fmt.Errorf("%w: origin and destination are required", ErrInvalidInput)
+	}
+	origin, err := s.resolveAirport(ctx, req.Origin)
+	if err != nil {
+		return nil, err
+	}
+	destination, err := s.resolveAirport(ctx, req.Destination)
+	if err != nil {
+		return nil, err
+	}
+	if origin == destination {
+		return nil, fmt.Errorf("%w: origin and destination must differ", ErrInvalidInput)
+	}
+
+	opts := itineraryOptions{
+		maxStops:      1,
+		minConnection: DefaultMinConnection,
+		maxConnection: DefaultMaxConnection,
+	}
+	if req.MaxStops != nil {
+		if *req.MaxStops < 0 || *req.MaxStops > MaxItineraryStops {
+			return nil, fmt.Errorf("%w: maxStops must be between 0 and %d", ErrInvalidInput, MaxItineraryStops)
+		}
+		opts.maxStops = *req.MaxStops
+	}
+	if req.MinConnection > 0 {
+		opts.minConnection = req.MinConnection
+	}
+	if req.MaxConnection > 0 {
+		opts.maxConnection = req.MaxConnection
+	}
+	if opts.maxConnection < opts.minConnection {
+		return nil, fmt.Errorf("%w: maximum connection time is shorter than the minimum", ErrInvalidInput)
+	}
+
+	opts.windowStart = time.Now()
+	if req.DepartureFrom != nil && req.DepartureFrom.After(opts.windowStart) {
+		opts.windowStart = *req.DepartureFrom
+	}
+	opts.windowEnd = opts.windowStart.AddDate(0, 0, 1)
+	if req.DepartureTo != nil {
+		opts.windowEnd = *req.DepartureTo
+	}
+	if !opts.windowEnd.After(opts.windowStart) {
+		return nil, fmt.Errorf("%w: departureTo must be after departureFrom", ErrInvalidInput)
+	}
+
+	var less func(a, b *Itinerary) bool
+	switch req.Sort {
+	case "", "duration":
+		less = byDurationThenPrice
+	case "price":
+		less = byPriceThenDuration
+	default:
+		return nil, fmt.Errorf("%w: unknown sort %q", ErrInvalidInput, req.Sort)
+	}
+
+	limit := req.Limit
+	switch {
+	case limit <= 0:
+		limit = defaultItineraryLimit
+	case limit > maxItineraryLimit:
+		limit = maxItineraryLimit
+	}
+
+	// Load every leg that could be part of an itinerary starting in the window
+	until := opts.windowEnd.Add(time.Duration(opts.maxStops) * (opts.maxConnection + maxLegDuration))
+	flights, err := s.repo.GetFlightsDepartingBetween(ctx, opts.windowStart, until, req.MinSeats)
+	if err != nil {
+		return nil, err
+	}
+
+	itineraries := buildItineraries(flights, origin, destination, opts)
+	sort.SliceStable(itineraries, func(i, j int) bool {
+		return less(&itineraries[i], &itineraries[j])
+	})
+	if len(itineraries) > limit {
+		itineraries = itineraries[:limit]
+	}
+
+	return itineraries, nil
+}
+
+// buildItineraries finds every path from origin to destination whose first leg
+// departs inside the window, with at most maxStops connections that respect
+// the connection time limits and never revisit an airport.
+func buildItineraries(flights []database.Flight, origin, destination string, opts itineraryOptions) []Itinerary {
+	byOrigin := make(map[string][]database.Flight)
+	for _, f := range flights {
+		byOrigin[f.Origin] = append(byOrigin[f.Origin], f)
+	}
+
+	itineraries := []Itinerary{}
+	var path []database.Flight
+	visited := map[string]bool{origin: true}
+
+	var extend func(from string, earliest, latest time.Time)
+	extend = func(from string, earliest, latest time.Time) {
+		for _, f := range byOrigin[from] {
+			if f.DepartureTime.Before(earliest) || !f.DepartureTime.Before(latest) {
+				continue
+			}
+			if !f.ArrivalTime.After(f.DepartureTime) || visited[f.Destination] {
+				continue
+			}
+
+			path = append(path, f)
+			if f.Destination == destination {
+				itineraries = append(itineraries, newItinerary(path))
+			} else if len(path) <= opts.maxStops {
+				visited[f.Destination] = true
+				// The maximum connection time is inclusive
+				extend(f.Destination,
+					f.ArrivalTime.Add(opts.minConnection),
+					f.ArrivalTime.Add(opts.maxConnection).Add(time.Nanosecond))
+				delete(visited, f.Destination)
+			}
+			path = path[:len(path)-1]
+		}
+	}
+	extend(origin, opts.windowStart, opts.windowEnd)
+
+	return itineraries
+}
+
+// newItinerary prices and times a sequence of connecting flights
+func newItinerary(path []database.Flight) Itinerary {
+	segments := make([]database.Flight, len(path))
+	copy(segments, path)
+
+	first, last := segments[0], segments[len(segments)-1]
+	it := Itinerary{
+		Segments:           segments,
+		Stops:              len(segments) - 1,
+		DepartureTime:      first.DepartureTime,
+		ArrivalTime:        last.ArrivalTime,
+		DurationMinutes:    int(last.ArrivalTime.Sub(first.DepartureTime).Minutes()),
+		ConnectionsMinutes: []int{},
+	}
+	for i, f := range segments {
+		it.TotalPrice += f.PricePerSeat
+		if i > 0 {
+			layover := f.DepartureTime.Sub(segments[i-1].ArrivalTime)
+			it.ConnectionsMinutes = append(it.ConnectionsMinutes, int(layover.Minutes()))
+		}
+	}
+	return it
+}
+
+func byDurationThenPrice(a, b *Itinerary) bool {
+	if a.DurationMinutes != b.DurationMinutes {
+		return a.DurationMinutes < b.DurationMinutes
+	}
+	if a.TotalPrice != b.TotalPrice {
+		return a.TotalPrice < b.TotalPrice
+	}
+	return a.DepartureTime.Before(b.DepartureTime)
+}
+
+func byPriceThenDuration(a, b *Itinerary) bool {
+	if a.TotalPrice != b.TotalPrice {
+		return a.TotalPrice < b.TotalPrice
+	}
+	if a.DurationMinutes != b.DurationMinutes {
+		return a.DurationMinutes < b.DurationMinutes
+	}
+	return a.DepartureTime.Before(b.DepartureTime)
+}
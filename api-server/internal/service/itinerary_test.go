@@ -0,0 +1,99 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/cx-tal-miterani/flight-booking-system/api-server/internal/database"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testFlight(number, origin, destination string, departure time.Time, duration time.Duration, price float64) database.Flight {
+	return database.Flight{
+		ID:            uuid.New(),
+		FlightNumber:  number,
+		Origin:        origin,
+		Destination:   destination,
+		DepartureTime: departure,
+		ArrivalTime:   departure.Add(duration),
+		PricePerSeat:  price,
+	}
+}
+
+func TestBuildItineraries(t *testing.T) {
+	base := time.Date(2024, 6, 1, 8, 0, 0, 0, time.UTC)
+
+	flights := []database.Flight{
+		testFlight("D1", "BOS", "LAX", base, 6*time.Hour, 450),
+		testFlight("A1", "BOS", "ORD", base, 2*time.Hour, 120),
+		testFlight("B1", "ORD", "LAX", base.Add(3*time.Hour), 4*time.Hour, 180),
+		// Departs 30 minutes after A1 lands - too short a connection
+		testFlight("B2", "ORD", "LAX", base.Add(2*time.Hour+30*time.Minute), 4*time.Hour, 100),
+		// Departs 8 hours after A1 lands - too long a connection
+		testFlight("B3", "ORD", "LAX", base.Add(10*time.Hour), 4*time.Hour, 90),
+		testFlight("C1", "ORD", "DEN", base.Add(3*time.Hour), 2*time.Hour, 80),
+		testFlight("C2", "DEN", "LAX", base.Add(6*time.Hour), 2*time.Hour, 70),
+		// Would return to the origin
+		testFlight("X1", "ORD", "BOS", base.Add(3*time.Hour), 2*time.Hour, 50),
+		// First leg outside the departure window
+		testFlight("D2", "BOS", "LAX", base.AddDate(0, 0, 2), 6*time.Hour, 200),
+	}
+
+	opts := itineraryOptions{
+		windowStart:   base.Add(-time.Hour),
+		windowEnd:     base.AddDate(0, 0, 1),
+		maxStops:      2,
+		minConnection: DefaultMinConnection,
+		maxConnection: DefaultMaxConnection,
+	}
+
+	itineraries := buildItineraries(flights, "BOS", "LAX", opts)
+	require.Len(t, itineraries, 3)
+
+	routes := make(map[string]Itinerary)
+	for _, it := range itineraries {
+		var numbers string
+		for _, seg := range it.Segments {
+			numbers += seg.FlightNumber + " "
+		}
+		routes[numbers] = it
+	}
+
+	direct, ok := routes["D1 "]
+	require.True(t, ok)
+	assert.Equal(t, 0, direct.Stops)
+	assert.Equal(t, 360, direct.DurationMinutes)
+
+	oneStop, ok := routes["A1 B1 "]
+	require.True(t, ok)
+	assert.Equal(t, 1, oneStop.Stops)
+	assert.Equal(t, []int{60}, oneStop.ConnectionsMinutes)
+	assert.Equal(t, 300.0, oneStop.TotalPrice)
+	assert.Equal(t, 420, oneStop.DurationMinutes)
+
+	twoStop, ok := routes["A1 C1 C2 "]
+	require.True(t, ok)
+	assert.Equal(t, 2, twoStop.Stops)
+	assert.Equal(t, []int{60, 60}, twoStop.ConnectionsMinutes)
+	assert.Equal(t, 270.0, twoStop.TotalPrice)
+
+	opts.maxStops = 0
+	direct0 := buildItineraries(flights, "BOS", "LAX", opts)
+	require.Len(t, direct0, 1)
+	assert.Equal(t, "D1", direct0[0].Segments[0].FlightNumber)
+}
+
+func TestItineraryRanking(t *testing.T) {
+	base := time.Date(2024, 6, 1, 8, 0, 0, 0, time.UTC)
+
+	fast := Itinerary{DurationMinutes: 300, TotalPrice: 400, DepartureTime: base}
+	cheap := Itinerary{DurationMinutes: 480, TotalPrice: 200, DepartureTime: base}
+	fastCheaper := Itinerary{DurationMinutes: 300, TotalPrice: 350, DepartureTime: base}
+
+	assert.True(t, byDurationThenPrice(&fastCheaper, &fast))
+	assert.True(t, byDurationThenPrice(&fast, &cheap))
+	assert.True(t, byPriceThenDuration(&cheap, &fastCheaper))
+	assert.False(t, byPriceThenDuration(&fast, &fastCheaper))
+}
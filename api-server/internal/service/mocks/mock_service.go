@@ -2,9 +2,11 @@ package mocks
 
 import (
 	"context"
+	"io"
 
 	"github.com/cx-tal-miterani/flight-booking-system/api-server/internal/database"
 	"github.com/cx-tal-miterani/flight-booking-system/api-server/internal/service"
+	"github.com/google/uuid"
 	"github.com/stretchr/testify/mock"
 )
 
@@ -16,12 +18,12 @@ type MockService struct {
 // Ensure MockService implements service.Service
 var _ service.Service = (*MockService)(nil)
 
-func (m *MockService) GetFlights(ctx context.Context) ([]database.Flight, error) {
-	args := m.Called(ctx)
+func (m *MockService) GetFlights(ctx context.Context, req service.FlightSearchRequest) (*service.FlightSearchResponse, error) {
+	args := m.Called(ctx, req)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
-	return args.Get(0).([]database.Flight), args.Error(1)
+	return args.Get(0).(*service.FlightSearchResponse), args.Error(1)
 }
 
 func (m *MockService) GetFlight(ctx context.Context, id string) (*database.Flight, error) {
@@ -32,12 +34,36 @@ func (m *MockService) GetFlight(ctx context.Context, id string) (*database.Fligh
 	return args.Get(0).(*database.Flight), args.Error(1)
 }
 
-func (m *MockService) GetFlightSeats(ctx context.Context, flightID string) ([]database.Seat, error) {
-	args := m.Called(ctx, flightID)
+func (m *MockService) GetSeatMap(ctx context.Context, flightID string, attributes []string) (*database.SeatMap, error) {
+	args := m.Called(ctx, flightID, attributes)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*database.SeatMap), args.Error(1)
+}
+
+func (m *MockService) SearchItineraries(ctx context.Context, req service.ItinerarySearchRequest) ([]service.Itinerary, error) {
+	args := m.Called(ctx, req)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
-	return args.Get(0).([]database.Seat), args.Error(1)
+	return args.Get(0).([]service.Itinerary), args.Error(1)
+}
+
+func (m *MockService) GetAirports(ctx context.Context, query string) ([]database.Airport, error) {
+	args := m.Called(ctx, query)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]database.Airport), args.Error(1)
+}
+
+func (m *MockService) GetAirport(ctx context.Context, code string) (*database.Airport, error) {
+	args := m.Called(ctx, code)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*database.Airport), args.Error(1)
 }
 
 func (m *MockService) CreateOrder(ctx context.Context, req service.CreateOrderRequest) (*database.Order, error) {
@@ -56,6 +82,22 @@ func (m *MockService) GetOrder(ctx context.Context, id string) (*service.OrderSt
 	return args.Get(0).(*service.OrderStatusResponse), args.Error(1)
 }
 
+func (m *MockService) GetOrderEvents(ctx context.Context, orderID string) ([]database.OrderEvent, error) {
+	args := m.Called(ctx, orderID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]database.OrderEvent), args.Error(1)
+}
+
+func (m *MockService) GetBooking(ctx context.Context, reference, lastName string) (*service.Booking, error) {
+	args := m.Called(ctx, reference, lastName)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*service.Booking), args.Error(1)
+}
+
 func (m *MockService) SelectSeats(ctx context.Context, orderID string, seatIDs []string) (*service.OrderStatusResponse, error) {
 	args := m.Called(ctx, orderID, seatIDs)
 	if args.Get(0) == nil {
@@ -64,6 +106,109 @@ func (m *MockService) SelectSeats(ctx context.Context, orderID string, seatIDs [
 	return args.Get(0).(*service.OrderStatusResponse), args.Error(1)
 }
 
+func (m *MockService) AutoAssignSeats(ctx context.Context, orderID string, req service.AutoAssignRequest) (*service.OrderStatusResponse, error) {
+	args := m.Called(ctx, orderID, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*service.OrderStatusResponse), args.Error(1)
+}
+
+func (m *MockService) OverbookSeats(ctx context.Context, orderID string, req service.OverbookRequest) (*service.OrderStatusResponse, error) {
+	args := m.Called(ctx, orderID, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*service.OrderStatusResponse), args.Error(1)
+}
+
+func (m *MockService) SetPassengers(ctx context.Context, orderID string, req service.SetPassengersRequest) (*service.OrderStatusResponse, error) {
+	args := m.Called(ctx, orderID, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*service.OrderStatusResponse), args.Error(1)
+}
+
+func (m *MockService) CheckIn(ctx context.Context, orderID string) (*service.OrderStatusResponse, error) {
+	args := m.Called(ctx, orderID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*service.OrderStatusResponse), args.Error(1)
+}
+
+func (m *MockService) ChangeSeats(ctx context.Context, orderID string, req service.ChangeSeatsRequest) (*service.ChangeSeatsResponse, error) {
+	args := m.Called(ctx, orderID, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*service.ChangeSeatsResponse), args.Error(1)
+}
+
+func (m *MockService) Register(ctx context.Context, req service.RegisterRequest) (*service.Session, error) {
+	args := m.Called(ctx, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*service.Session), args.Error(1)
+}
+
+func (m *MockService) Login(ctx context.Context, req service.LoginRequest) (*service.Session, error) {
+	args := m.Called(ctx, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*service.Session), args.Error(1)
+}
+
+func (m *MockService) Logout(ctx context.Context, token string) error {
+	args := m.Called(ctx, token)
+	return args.Error(0)
+}
+
+func (m *MockService) Authenticate(ctx context.Context, token string) (*database.Customer, error) {
+	args := m.Called(ctx, token)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*database.Customer), args.Error(1)
+}
+
+func (m *MockService) AuthorizeOrder(ctx context.Context, orderID string, customer *database.Customer) error {
+	args := m.Called(ctx, orderID, customer)
+	return args.Error(0)
+}
+
+func (m *MockService) GetCustomerOrders(ctx context.Context, customerID uuid.UUID, req service.CustomerOrdersRequest) ([]database.Order, error) {
+	args := m.Called(ctx, customerID, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]database.Order), args.Error(1)
+}
+
+func (m *MockService) JoinWaitlist(ctx context.Context, flightID string, req service.JoinWaitlistRequest) (*database.WaitlistEntry, error) {
+	args := m.Called(ctx, flightID, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*database.WaitlistEntry), args.Error(1)
+}
+
+func (m *MockService) GetWaitlistEntry(ctx context.Context, id string) (*database.WaitlistEntry, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*database.WaitlistEntry), args.Error(1)
+}
+
+func (m *MockService) LeaveWaitlist(ctx context.Context, id string) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
 func (m *MockService) SubmitPayment(ctx context.Context, orderID string, paymentCode string) (*service.OrderStatusResponse, error) {
 	args := m.Called(ctx, orderID, paymentCode)
 	if args.Get(0) == nil {
@@ -76,3 +221,218 @@ func (m *MockService) CancelOrder(ctx context.Context, orderID string) error {
 	args := m.Called(ctx, orderID)
 	return args.Error(0)
 }
+
+func (m *MockService) CreateFlight(ctx context.Context, req service.CreateFlightRequest) (*database.Flight, error) {
+	args := m.Called(ctx, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*database.Flight), args.Error(1)
+}
+
+func (m *MockService) UpdateFlight(ctx context.Context, id string, req service.UpdateFlightRequest) (*database.Flight, error) {
+	args := m.Called(ctx, id, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*database.Flight), args.Error(1)
+}
+
+func (m *MockService) DeleteFlight(ctx context.Context, id string) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *MockService) GetAircraftTypes(ctx context.Context) ([]database.AircraftType, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]database.AircraftType), args.Error(1)
+}
+
+func (m *MockService) CreateAircraftType(ctx context.Context, req service.CreateAircraftTypeRequest) (*database.AircraftType, error) {
+	args := m.Called(ctx, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*database.AircraftType), args.Error(1)
+}
+
+func (m *MockService) GetCabinLayouts(ctx context.Context) ([]database.CabinLayout, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]database.CabinLayout), args.Error(1)
+}
+
+func (m *MockService) CreateCabinLayout(ctx context.Context, req service.CreateCabinLayoutRequest) (*database.CabinLayout, error) {
+	args := m.Called(ctx, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*database.CabinLayout), args.Error(1)
+}
+
+func (m *MockService) GetSeatAttributeSurcharges(ctx context.Context) ([]database.SeatAttributeSurcharge, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]database.SeatAttributeSurcharge), args.Error(1)
+}
+
+func (m *MockService) UpdateSeatAttributeSurcharge(ctx context.Context, attribute string, req service.UpdateSeatAttributeSurchargeRequest) (*database.SeatAttributeSurcharge, error) {
+	args := m.Called(ctx, attribute, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*database.SeatAttributeSurcharge), args.Error(1)
+}
+
+func (m *MockService) UpdateFlightStatus(ctx context.Context, id string, req service.UpdateFlightStatusRequest) (*database.Flight, error) {
+	args := m.Called(ctx, id, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*database.Flight), args.Error(1)
+}
+
+func (m *MockService) SearchOrders(ctx context.Context, req service.OrderSearchRequest) (*service.OrderSearchResponse, error) {
+	args := m.Called(ctx, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*service.OrderSearchResponse), args.Error(1)
+}
+
+func (m *MockService) ExportOrders(ctx context.Context, req service.OrderSearchRequest) ([]database.OrderSummary, error) {
+	args := m.Called(ctx, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]database.OrderSummary), args.Error(1)
+}
+
+func (m *MockService) GetOverbookingPolicies(ctx context.Context) ([]database.OverbookingPolicy, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]database.OverbookingPolicy), args.Error(1)
+}
+
+func (m *MockService) SaveOverbookingPolicy(ctx context.Context, req service.OverbookingPolicyRequest) (*database.OverbookingPolicy, error) {
+	args := m.Called(ctx, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*database.OverbookingPolicy), args.Error(1)
+}
+
+func (m *MockService) DeleteOverbookingPolicy(ctx context.Context, id string) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *MockService) GetDeniedBoardingReport(ctx context.Context, flightID string) (*service.DeniedBoardingReport, error) {
+	args := m.Called(ctx, flightID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*service.DeniedBoardingReport), args.Error(1)
+}
+
+func (m *MockService) GetSeatBlocks(ctx context.Context, flightID string) ([]database.SeatBlock, error) {
+	args := m.Called(ctx, flightID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]database.SeatBlock), args.Error(1)
+}
+
+func (m *MockService) BlockSeats(ctx context.Context, flightID string, req service.BlockSeatsRequest) ([]database.SeatBlock, error) {
+	args := m.Called(ctx, flightID, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]database.SeatBlock), args.Error(1)
+}
+
+func (m *MockService) UnblockSeats(ctx context.Context, flightID string, sel service.SeatSelection) ([]uuid.UUID, error) {
+	args := m.Called(ctx, flightID, sel)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]uuid.UUID), args.Error(1)
+}
+
+func (m *MockService) ExchangeSeats(ctx context.Context, orderID string, req service.SeatExchangeRequest) (*database.SeatExchange, error) {
+	args := m.Called(ctx, orderID, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*database.SeatExchange), args.Error(1)
+}
+
+func (m *MockService) ExchangeFlight(ctx context.Context, orderID string, req service.FlightExchangeRequest) (*database.FlightExchange, error) {
+	args := m.Called(ctx, orderID, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*database.FlightExchange), args.Error(1)
+}
+
+func (m *MockService) CancelBooking(ctx context.Context, orderID string, req service.CancelBookingRequest) (*database.OrderRefund, error) {
+	args := m.Called(ctx, orderID, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*database.OrderRefund), args.Error(1)
+}
+
+func (m *MockService) GetRebookingOffer(ctx context.Context, orderID string) (*database.RebookingOffer, error) {
+	args := m.Called(ctx, orderID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*database.RebookingOffer), args.Error(1)
+}
+
+func (m *MockService) RespondToRebooking(ctx context.Context, orderID string, req service.RebookingResponseRequest) (*database.RebookingOffer, error) {
+	args := m.Called(ctx, orderID, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*database.RebookingOffer), args.Error(1)
+}
+
+func (m *MockService) GetFlightSchedules(ctx context.Context) ([]database.FlightSchedule, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]database.FlightSchedule), args.Error(1)
+}
+
+func (m *MockService) CreateFlightSchedule(ctx context.Context, req service.CreateFlightScheduleRequest) (*database.FlightSchedule, error) {
+	args := m.Called(ctx, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*database.FlightSchedule), args.Error(1)
+}
+
+func (m *MockService) DeleteFlightSchedule(ctx context.Context, id string) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *MockService) ImportSSIM(ctx context.Context, r io.Reader, req service.SSIMImportRequest) (*service.SSIMImportResult, error) {
+	args := m.Called(ctx, r, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*service.SSIMImportResult), args.Error(1)
+}
@@ -0,0 +1,40 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/cx-tal-miterani/flight-booking-system/api-server/internal/database"
+	"github.com/google/uuid"
+)
+
+// GetOrderEvents returns the history of an order, oldest first
+func (s *BookingService) GetOrderEvents(ctx context.Context, orderID string) ([]database.OrderEvent, error) {
+	oid, err := uuid.Parse(orderID)
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid order ID", ErrInvalidInput)
+	}
+	if _, err := s.repo.GetOrderByID(ctx, oid); err != nil {
+		return nil, err
+	}
+	return s.repo.GetOrderEvents(ctx, oid)
+}
+
+// signalOrderWorkflow sends signal with arg to a workflow handling an order
+// and records it in the order's history. The argument is not recorded since
+// it can carry payment codes.
+func (s *BookingService) signalOrderWorkflow(ctx context.Context, orderID uuid.UUID, workflowID, signal string, arg interface{}) error {
+	err := s.temporalClient.SignalWorkflow(ctx, workflowID, "", signal, arg)
+
+	payload := map[string]interface{}{
+		"signal":     signal,
+		"workflowId": workflowID,
+	}
+	if err != nil {
+		payload["error"] = err.Error()
+	}
+	if rerr := s.repo.RecordOrderEvent(ctx, orderID, database.OrderEventSignalSent, payload); rerr != nil {
+		fmt.Printf("Warning: failed to record signal %s for order %s: %v\n", signal, orderID, rerr)
+	}
+	return err
+}
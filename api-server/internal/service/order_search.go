@@ -0,0 +1,150 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/cx-tal-miterani/flight-booking-system/api-server/internal/database"
+	"github.com/google/uuid"
+)
+
+const (
+	defaultOrderPageSize = 50
+	maxOrderPageSize     = 200
+	// MaxOrderExportRows is the most orders a CSV export can contain
+	MaxOrderExportRows = 10000
+)
+
+// OrderSearchRequest represents the filters and pagination of the admin order
+// search. CreatedFrom is inclusive and CreatedTo exclusive.
+type OrderSearchRequest struct {
+	Statuses      []string
+	FlightID      string
+	CustomerEmail string
+	CreatedFrom   *time.Time
+	CreatedTo     *time.Time
+	MinAmount     *float64
+	MaxAmount     *float64
+	Cursor        string
+	Limit         int
+}
+
+// OrderSearchResponse is a page of orders and the cursor of the next page,
+// empty on the last page
+type OrderSearchResponse struct {
+	Orders     []database.OrderSummary
+	NextCursor string
+}
+
+// SearchOrders returns a page of the orders matching the request, newest
+// first
+func (s *BookingService) SearchOrders(ctx context.Context, req OrderSearchRequest) (*OrderSearchResponse, error) {
+	params, err := orderSearchParams(req)
+	if err != nil {
+		return nil, err
+	}
+
+	page, err := s.repo.SearchOrders(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &OrderSearchResponse{Orders: page.Orders}
+	if page.NextCursor != nil {
+		resp.NextCursor = page.NextCursor.Encode()
+	}
+	return resp, nil
+}
+
+// ExportOrders returns all orders matching the request, newest first, from
+// the request's cursor on. Exports are limited to MaxOrderExportRows orders.
+func (s *BookingService) ExportOrders(ctx context.Context, req OrderSearchRequest) ([]database.OrderSummary, error) {
+	req.Limit = maxOrderPageSize
+	params, err := orderSearchParams(req)
+	if err != nil {
+		return nil, err
+	}
+
+	var orders []database.OrderSummary
+	for {
+		page, err := s.repo.SearchOrders(ctx, params)
+		if err != nil {
+			return nil, err
+		}
+		orders = append(orders, page.Orders...)
+		if len(orders) > MaxOrderExportRows {
+			return nil, fmt.Errorf("%w: more than %d orders match, narrow the filters", ErrInvalidInput, MaxOrderExportRows)
+		}
+		if page.NextCursor == nil {
+			return orders, nil
+		}
+		params.Cursor = page.NextCursor
+	}
+}
+
+// orderSearchParams validates an order search request
+func orderSearchParams(req OrderSearchRequest) (database.OrderSearchParams, error) {
+	params := database.OrderSearchParams{
+		CustomerEmail: strings.TrimSpace(req.CustomerEmail),
+		CreatedFrom:   req.CreatedFrom,
+		CreatedTo:     req.CreatedTo,
+		MinAmount:     req.MinAmount,
+		MaxAmount:     req.MaxAmount,
+		Limit:         req.Limit,
+	}
+
+	var err error
+	if params.Statuses, err = parseOrderStatuses(req.Statuses); err != nil {
+		return params, err
+	}
+	if req.FlightID != "" {
+		flightID, err := uuid.Parse(req.FlightID)
+		if err != nil {
+			return params, fmt.Errorf("%w: invalid flight ID", ErrInvalidInput)
+		}
+		params.FlightID = &flightID
+	}
+	if req.CreatedFrom != nil && req.CreatedTo != nil && !req.CreatedFrom.Before(*req.CreatedTo) {
+		return params, fmt.Errorf("%w: from must be before to", ErrInvalidInput)
+	}
+	if (req.MinAmount != nil && *req.MinAmount < 0) || (req.MaxAmount != nil && *req.MaxAmount < 0) {
+		return params, fmt.Errorf("%w: amounts must not be negative", ErrInvalidInput)
+	}
+	if req.MinAmount != nil && req.MaxAmount != nil && *req.MinAmount > *req.MaxAmount {
+		return params, fmt.Errorf("%w: minAmount must not exceed maxAmount", ErrInvalidInput)
+	}
+
+	switch {
+	case params.Limit <= 0:
+		params.Limit = defaultOrderPageSize
+	case params.Limit > maxOrderPageSize:
+		params.Limit = maxOrderPageSize
+	}
+
+	if req.Cursor != "" {
+		cursor, err := database.DecodeOrderCursor(req.Cursor)
+		if err != nil {
+			return params, fmt.Errorf("%w: %v", ErrInvalidInput, err)
+		}
+		params.Cursor = cursor
+	}
+	return params, nil
+}
+
+// parseOrderStatuses parses order statuses given as filters
+func parseOrderStatuses(values []string) ([]database.OrderStatus, error) {
+	var statuses []database.OrderStatus
+	for _, value := range values {
+		switch status := database.OrderStatus(strings.TrimSpace(value)); status {
+		case database.OrderStatusPending, database.OrderStatusSeatsSelected, database.OrderStatusAwaitingPayment,
+			database.OrderStatusProcessing, database.OrderStatusConfirmed, database.OrderStatusFailed,
+			database.OrderStatusCancelled, database.OrderStatusExpired, database.OrderStatusRefunded:
+			statuses = append(statuses, status)
+		default:
+			return nil, fmt.Errorf("%w: unknown order status %q", ErrInvalidInput, value)
+		}
+	}
+	return statuses, nil
+}
@@ -0,0 +1,68 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/cx-tal-miterani/flight-booking-system/api-server/internal/database"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOrderSearchParams(t *testing.T) {
+	flightID := uuid.New()
+	from := time.Date(2026, 6, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2026, 7, 1, 0, 0, 0, 0, time.UTC)
+	min, max := 100.0, 500.0
+	cursor := &database.OrderCursor{CreatedAt: from, ID: uuid.New()}
+
+	params, err := orderSearchParams(OrderSearchRequest{
+		Statuses:      []string{"confirmed", "refunded"},
+		FlightID:      flightID.String(),
+		CustomerEmail: " Ada@Example.com ",
+		CreatedFrom:   &from,
+		CreatedTo:     &to,
+		MinAmount:     &min,
+		MaxAmount:     &max,
+		Cursor:        cursor.Encode(),
+		Limit:         1000,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []database.OrderStatus{database.OrderStatusConfirmed, database.OrderStatusRefunded}, params.Statuses)
+	assert.Equal(t, &flightID, params.FlightID)
+	assert.Equal(t, "Ada@Example.com", params.CustomerEmail)
+	assert.Equal(t, maxOrderPageSize, params.Limit)
+	require.NotNil(t, params.Cursor)
+	assert.Equal(t, cursor.ID, params.Cursor.ID)
+	assert.True(t, cursor.CreatedAt.Equal(params.Cursor.CreatedAt))
+
+	params, err = orderSearchParams(OrderSearchRequest{})
+	require.NoError(t, err)
+	assert.Equal(t, defaultOrderPageSize, params.Limit)
+	assert.Nil(t, params.FlightID)
+	assert.Nil(t, params.Cursor)
+}
+
+func TestOrderSearchParams_Invalid(t *testing.T) {
+	from := time.Date(2026, 6, 1, 0, 0, 0, 0, time.UTC)
+	negative, low, high := -1.0, 100.0, 500.0
+
+	tests := []struct {
+		name string
+		req  OrderSearchRequest
+	}{
+		{name: "unknown status", req: OrderSearchRequest{Statuses: []string{"shipped"}}},
+		{name: "invalid flight ID", req: OrderSearchRequest{FlightID: "FB101"}},
+		{name: "empty date range", req: OrderSearchRequest{CreatedFrom: &from, CreatedTo: &from}},
+		{name: "negative amount", req: OrderSearchRequest{MinAmount: &negative}},
+		{name: "inverted amount range", req: OrderSearchRequest{MinAmount: &high, MaxAmount: &low}},
+		{name: "invalid cursor", req: OrderSearchRequest{Cursor: "not a cursor"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := orderSearchParams(tt.req)
+			assert.ErrorIs(t, err, ErrInvalidInput)
+		})
+	}
+}
@@ -0,0 +1,285 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/cx-tal-miterani/flight-booking-system/api-server/internal/database"
+	"github.com/cx-tal-miterani/flight-booking-system/api-server/internal/websocket"
+	"github.com/google/uuid"
+)
+
+// OverbookingPolicyRequest sets how far a flight, or every flight on a route,
+// may be oversold. Exactly one of FlightID and Origin/Destination is given;
+// without a class the policy applies to each class separately.
+type OverbookingPolicyRequest struct {
+	FlightID    string                   `json:"flightId,omitempty"`
+	Origin      string                   `json:"origin,omitempty"`
+	Destination string                   `json:"destination,omitempty"`
+	Class       string                   `json:"class,omitempty"`
+	Mode        database.OverbookingMode `json:"mode"`
+	Value       float64                  `json:"value"`
+}
+
+// OverbookRequest asks to book a party in a class without physical seats
+// because not enough of them are left. Seats are assigned at check-in.
+type OverbookRequest struct {
+	Class     string `json:"class"`
+	PartySize int    `json:"partySize"`
+}
+
+// DeniedBoardingReport is the overbooking state of a flight and the orders
+// denied boarding when it closed
+type DeniedBoardingReport struct {
+	FlightID     uuid.UUID                   `json:"flightId"`
+	FlightNumber string                      `json:"flightNumber"`
+	Status       database.FlightStatus       `json:"status"`
+	Classes      []database.OverbookingClass `json:"classes"`
+	Denied       []database.DeniedBoarding   `json:"denied"`
+}
+
+// GetOverbookingPolicies returns every overbooking policy
+func (s *BookingService) GetOverbookingPolicies(ctx context.Context) ([]database.OverbookingPolicy, error) {
+	return s.repo.GetOverbookingPolicies(ctx)
+}
+
+// SaveOverbookingPolicy creates or replaces the overbooking policy of a
+// flight or route and class
+func (s *BookingService) SaveOverbookingPolicy(ctx context.Context, req OverbookingPolicyRequest) (*database.OverbookingPolicy, error) {
+	if err := validateOverbookingPolicy(req); err != nil {
+		return nil, err
+	}
+
+	policy := &database.OverbookingPolicy{Mode: req.Mode, Value: req.Value}
+	if req.Class != "" {
+		policy.Class = &req.Class
+	}
+	if req.FlightID != "" {
+		flightID, err := uuid.Parse(req.FlightID)
+		if err != nil {
+			return nil, fmt.Errorf("%w: invalid flight ID", ErrInvalidInput)
+		}
+		if _, err := s.repo.GetFlightByID(ctx, flightID); err != nil {
+			return nil, err
+		}
+		policy.FlightID = &flightID
+	} else {
+		origin, err := s.resolveAirport(ctx, req.Origin)
+		if err != nil {
+			return nil, err
+		}
+		destination, err := s.resolveAirport(ctx, req.Destination)
+		if err != nil {
+			return nil, err
+		}
+		if origin == destination {
+			return nil, fmt.Errorf("%w: origin and destination must differ", ErrInvalidInput)
+		}
+		policy.Origin, policy.Destination = &origin, &destination
+	}
+
+	if err := s.repo.SaveOverbookingPolicy(ctx, policy); err != nil {
+		return nil, err
+	}
+	return policy, nil
+}
+
+// DeleteOverbookingPolicy deletes an overbooking policy
+func (s *BookingService) DeleteOverbookingPolicy(ctx context.Context, id string) error {
+	policyID, err := uuid.Parse(id)
+	if err != nil {
+		return fmt.Errorf("%w: invalid policy ID", ErrInvalidInput)
+	}
+	return s.repo.DeleteOverbookingPolicy(ctx, policyID)
+}
+
+func validateOverbookingPolicy(req OverbookingPolicyRequest) error {
+	route := req.Origin != "" || req.Destination != ""
+	switch {
+	case req.FlightID != "" && route:
+		return fmt.Errorf("%w: a policy is for a flight or a route, not both", ErrInvalidInput)
+	case req.FlightID == "" && (req.Origin == "" || req.Destination == ""):
+		return fmt.Errorf("%w: flightId or origin and destination are required", ErrInvalidInput)
+	}
+	if req.Class != "" && !validSeatClasses[req.Class] {
+		return fmt.Errorf("%w: invalid seat class %q", ErrInvalidInput, req.Class)
+	}
+	if req.Value < 0 || math.IsNaN(req.Value) || math.IsInf(req.Value, 0) {
+		return fmt.Errorf("%w: value must not be negative", ErrInvalidInput)
+	}
+	switch req.Mode {
+	case database.OverbookingPercentage:
+		if req.Value > 100 {
+			return fmt.Errorf("%w: percentage must be at most 100", ErrInvalidInput)
+		}
+	case database.OverbookingFixed:
+		if req.Value != math.Trunc(req.Value) {
+			return fmt.Errorf("%w: fixed value must be a whole number of seats", ErrInvalidInput)
+		}
+	default:
+		return fmt.Errorf("%w: mode must be percentage or fixed", ErrInvalidInput)
+	}
+	return nil
+}
+
+// OverbookSeats books a party in a class without physical seats when the
+// class has too few available seats left, replacing the seats the order held
+// before. The class's overbooking policy limits how many travelers may be
+// without a seat. Orders over several flights cannot be overbooked.
+func (s *BookingService) OverbookSeats(ctx context.Context, orderID string, req OverbookRequest) (*OrderStatusResponse, error) {
+	if req.PartySize < 1 || req.PartySize > MaxPartySize {
+		return nil, fmt.Errorf("%w: party size must be between 1 and %d", ErrInvalidInput, MaxPartySize)
+	}
+	if !validSeatClasses[req.Class] {
+		return nil, fmt.Errorf("%w: invalid seat class %q", ErrInvalidInput, req.Class)
+	}
+
+	oid, err := uuid.Parse(orderID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid order ID: %w", err)
+	}
+
+	order, err := s.repo.GetOrderByID(ctx, oid)
+	if err != nil {
+		return nil, err
+	}
+	if len(order.Segments) > 1 {
+		return nil, fmt.Errorf("%w: orders over several flights cannot be overbooked", ErrInvalidInput)
+	}
+	if err := s.checkOrderFlightsBookable(ctx, order); err != nil {
+		return nil, err
+	}
+
+	// Seats the order holds itself become available when it is overbooked
+	seats, err := s.repo.GetFlightSeats(ctx, order.FlightID)
+	if err != nil {
+		return nil, err
+	}
+	available := 0
+	for _, seat := range seats {
+		if seat.Class != req.Class {
+			continue
+		}
+		if seat.Status == database.SeatStatusAvailable || (seat.HeldByOrder != nil && *seat.HeldByOrder == oid) {
+			available++
+		}
+	}
+	if available >= req.PartySize {
+		return nil, fmt.Errorf("%w: %d %s seats are available, select them instead", ErrInvalidInput, available, req.Class)
+	}
+
+	expiresAt := time.Now().Add(15 * time.Minute)
+	released, err := s.repo.OverbookOrder(ctx, oid, order.FlightID, req.Class, req.PartySize, expiresAt)
+	if err != nil {
+		return nil, err
+	}
+
+	if order.WorkflowID != nil {
+		err = s.signalOrderWorkflow(ctx, oid, *order.WorkflowID, "seats-selected", map[string]interface{}{
+			"seatIds":   []string{},
+			"expiresAt": expiresAt,
+		})
+		if err != nil {
+			fmt.Printf("Warning: failed to signal workflow: %v\n", err)
+		}
+	}
+
+	if len(released) > 0 {
+		ids := make([]string, len(released))
+		for i, id := range released {
+			ids[i] = id.String()
+		}
+		websocket.GetHub().BroadcastSeatsReleased(order.FlightID.String(), ids, orderID)
+		s.notifyWaitlists(ctx, released)
+	}
+
+	return s.GetOrder(ctx, orderID)
+}
+
+// CheckIn checks in a confirmed order, giving its travelers booked without
+// a seat one of the seats left in their class. Check-in closes when the
+// flight starts boarding.
+func (s *BookingService) CheckIn(ctx context.Context, orderID string) (*OrderStatusResponse, error) {
+	oid, err := uuid.Parse(orderID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid order ID: %w", err)
+	}
+
+	order, err := s.repo.GetOrderByID(ctx, oid)
+	if err != nil {
+		return nil, err
+	}
+	if order.Status != database.OrderStatusConfirmed {
+		return nil, fmt.Errorf("%w: only confirmed orders can be checked in", ErrInvalidInput)
+	}
+	flight, err := s.repo.GetFlightByID(ctx, order.FlightID)
+	if err != nil {
+		return nil, err
+	}
+	if !flight.Status.IsBookable() {
+		return nil, fmt.Errorf("%w: check-in for flight %s is closed", database.ErrOrderNotModifiable, flight.FlightNumber)
+	}
+
+	assigned, err := s.repo.AssignOverbookedSeats(ctx, oid)
+	if err != nil {
+		return nil, err
+	}
+	if len(assigned) > 0 {
+		ids := make([]string, len(assigned))
+		for i, id := range assigned {
+			ids[i] = id.String()
+		}
+		websocket.GetHub().BroadcastOrderCompleted(order.FlightID.String(), ids, orderID)
+	}
+
+	return s.GetOrder(ctx, orderID)
+}
+
+// boardFlight starts boarding a flight, giving the seats left to its
+// travelers without one and denying boarding to the others
+func (s *BookingService) boardFlight(ctx context.Context, f *database.Flight) error {
+	assigned, err := s.repo.BoardFlight(ctx, f)
+	if err != nil {
+		return err
+	}
+	if len(assigned) > 0 {
+		ids := make([]string, len(assigned))
+		for i, id := range assigned {
+			ids[i] = id.String()
+		}
+		websocket.GetHub().BroadcastOrderCompleted(f.ID.String(), ids, "")
+	}
+	return nil
+}
+
+// GetDeniedBoardingReport returns how far each class of a flight is oversold
+// and the orders denied boarding when it closed
+func (s *BookingService) GetDeniedBoardingReport(ctx context.Context, flightID string) (*DeniedBoardingReport, error) {
+	fid, err := uuid.Parse(flightID)
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid flight ID", ErrInvalidInput)
+	}
+
+	flight, err := s.repo.GetFlightByID(ctx, fid)
+	if err != nil {
+		return nil, err
+	}
+	classes, err := s.repo.GetOverbookingClasses(ctx, fid)
+	if err != nil {
+		return nil, err
+	}
+	denied, err := s.repo.GetDeniedBoardings(ctx, fid)
+	if err != nil {
+		return nil, err
+	}
+
+	return &DeniedBoardingReport{
+		FlightID:     flight.ID,
+		FlightNumber: flight.FlightNumber,
+		Status:       flight.Status,
+		Classes:      classes,
+		Denied:       denied,
+	}, nil
+}
@@ -0,0 +1,66 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/cx-tal-miterani/flight-booking-system/api-server/internal/database"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateOverbookingPolicy(t *testing.T) {
+	tests := []struct {
+		name  string
+		req   OverbookingPolicyRequest
+		valid bool
+	}{
+		{
+			name:  "flight percentage",
+			req:   OverbookingPolicyRequest{FlightID: "f", Mode: database.OverbookingPercentage, Value: 5},
+			valid: true,
+		},
+		{
+			name:  "route class fixed",
+			req:   OverbookingPolicyRequest{Origin: "JFK", Destination: "LAX", Class: "economy", Mode: database.OverbookingFixed, Value: 4},
+			valid: true,
+		},
+		{
+			name: "flight and route",
+			req:  OverbookingPolicyRequest{FlightID: "f", Origin: "JFK", Destination: "LAX", Mode: database.OverbookingFixed, Value: 4},
+		},
+		{
+			name: "route without destination",
+			req:  OverbookingPolicyRequest{Origin: "JFK", Mode: database.OverbookingFixed, Value: 4},
+		},
+		{
+			name: "unknown class",
+			req:  OverbookingPolicyRequest{FlightID: "f", Class: "steerage", Mode: database.OverbookingFixed, Value: 4},
+		},
+		{
+			name: "percentage over 100",
+			req:  OverbookingPolicyRequest{FlightID: "f", Mode: database.OverbookingPercentage, Value: 120},
+		},
+		{
+			name: "fractional fixed",
+			req:  OverbookingPolicyRequest{FlightID: "f", Mode: database.OverbookingFixed, Value: 2.5},
+		},
+		{
+			name: "negative",
+			req:  OverbookingPolicyRequest{FlightID: "f", Mode: database.OverbookingFixed, Value: -1},
+		},
+		{
+			name: "unknown mode",
+			req:  OverbookingPolicyRequest{FlightID: "f", Mode: "ratio", Value: 1},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateOverbookingPolicy(tt.req)
+			if tt.valid {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorIs(t, err, ErrInvalidInput)
+			}
+		})
+	}
+}
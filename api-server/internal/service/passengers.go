@@ -0,0 +1,280 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/cx-tal-miterani/flight-booking-system/api-server/internal/database"
+	"github.com/google/uuid"
+)
+
+const (
+	passengerDateLayout  = "2006-01-02"
+	maxPassengerNameLen  = 100
+	maxDocumentNumberLen = 50
+	// Passengers are children from their 2nd and adults from their 12th
+	// birthday, at the first departure of the order
+	childMinAge = 2
+	adultMinAge = 12
+)
+
+// validDocumentTypes lists the travel documents passengers can give
+var validDocumentTypes = map[string]bool{
+	"passport":    true,
+	"national_id": true,
+}
+
+// PassengerRequest describes a passenger of an order. SeatIDs are the seats
+// of the order the passenger sits in, one per flight; travelers booked
+// without a seat are referred to by their overbooked seat ID. Infants have
+// no seat.
+type PassengerRequest struct {
+	FirstName   string                   `json:"firstName"`
+	LastName    string                   `json:"lastName"`
+	DateOfBirth string                   `json:"dateOfBirth"`
+	Type        database.PassengerType   `json:"type"`
+	Document    *database.TravelDocument `json:"document,omitempty"`
+	SeatIDs     []string                 `json:"seatIds"`
+}
+
+// SetPassengersRequest replaces the passengers of an order
+type SetPassengersRequest struct {
+	Passengers []PassengerRequest `json:"passengers"`
+}
+
+// SetPassengers replaces the passengers of an order that has not been paid
+// for yet. Every seat of the order needs a passenger before payment.
+// Selecting seats again unassigns the passengers from their seats.
+func (s *BookingService) SetPassengers(ctx context.Context, orderID string, req SetPassengersRequest) (*OrderStatusResponse, error) {
+	oid, err := uuid.Parse(orderID)
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid order ID", ErrInvalidInput)
+	}
+
+	order, err := s.repo.GetOrderByID(ctx, oid)
+	if err != nil {
+		return nil, err
+	}
+	seats, err := s.repo.GetOrderSeats(ctx, oid)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get order seats: %w", err)
+	}
+
+	// Ages and documents are checked against the first departure
+	firstFlightID := order.FlightID
+	if len(order.Segments) > 0 {
+		firstFlightID = order.Segments[0].FlightID
+	}
+	flight, err := s.repo.GetFlightByID(ctx, firstFlightID)
+	if err != nil {
+		return nil, err
+	}
+
+	passengers, err := buildPassengers(req.Passengers, orderSeats(order, seats), flight.DepartureTime)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.repo.SetOrderPassengers(ctx, oid, passengers); err != nil {
+		return nil, err
+	}
+	return s.GetOrder(ctx, orderID)
+}
+
+// orderSeats returns the seats of an order passengers can sit in: its held
+// seats and its travelers booked without a seat
+func orderSeats(order *database.Order, seats []database.Seat) []database.PassengerSeat {
+	var result []database.PassengerSeat
+	for _, seat := range seats {
+		result = append(result, database.PassengerSeat{ID: seat.ID, FlightID: seat.FlightID, SeatNumber: seat.SeatNumber})
+	}
+	for _, ob := range order.OverbookedSeats {
+		if ob.Status == database.OverbookedSeatUnassigned {
+			result = append(result, database.PassengerSeat{ID: ob.ID, FlightID: ob.FlightID})
+		}
+	}
+	return result
+}
+
+// buildPassengers validates the passengers of an order departing at
+// departure and resolves their seats among the order's seats. A seat can
+// have one passenger, and a passenger one seat per flight.
+func buildPassengers(reqs []PassengerRequest, seats []database.PassengerSeat, departure time.Time) ([]database.Passenger, error) {
+	if len(reqs) == 0 {
+		return nil, fmt.Errorf("%w: at least one passenger is required", ErrInvalidInput)
+	}
+
+	seatByID := make(map[uuid.UUID]database.PassengerSeat, len(seats))
+	for _, seat := range seats {
+		seatByID[seat.ID] = seat
+	}
+	taken := make(map[uuid.UUID]bool)
+
+	passengers := make([]database.Passenger, 0, len(reqs))
+	counts := make(map[database.PassengerType]int)
+	for i, req := range reqs {
+		p, err := buildPassenger(req, departure)
+		if err != nil {
+			return nil, fmt.Errorf("passenger %d: %w", i+1, err)
+		}
+		counts[p.Type]++
+
+		if p.Type == database.PassengerInfant && len(req.SeatIDs) > 0 {
+			return nil, fmt.Errorf("%w: passenger %d: infants travel without a seat", ErrInvalidInput, i+1)
+		}
+		flights := make(map[uuid.UUID]bool)
+		for _, ref := range req.SeatIDs {
+			id, err := uuid.Parse(ref)
+			if err != nil {
+				return nil, fmt.Errorf("%w: passenger %d: invalid seat ID %q", ErrInvalidInput, i+1, ref)
+			}
+			seat, ok := seatByID[id]
+			if !ok {
+				return nil, fmt.Errorf("%w: passenger %d: seat %s is not part of the order", ErrInvalidInput, i+1, id)
+			}
+			if taken[id] {
+				return nil, fmt.Errorf("%w: passenger %d: seat %s is assigned to another passenger", ErrInvalidInput, i+1, id)
+			}
+			if flights[seat.FlightID] {
+				return nil, fmt.Errorf("%w: passenger %d: more than one seat on flight %s", ErrInvalidInput, i+1, seat.FlightID)
+			}
+			taken[id] = true
+			flights[seat.FlightID] = true
+			p.Seats = append(p.Seats, seat)
+		}
+		passengers = append(passengers, p)
+	}
+
+	if counts[database.PassengerAdult] == 0 {
+		return nil, fmt.Errorf("%w: children and infants must travel with an adult", ErrInvalidInput)
+	}
+	if counts[database.PassengerInfant] > counts[database.PassengerAdult] {
+		return nil, fmt.Errorf("%w: each infant must travel on the lap of a different adult", ErrInvalidInput)
+	}
+	return passengers, nil
+}
+
+// buildPassenger validates the details of a passenger, without their seats
+func buildPassenger(req PassengerRequest, departure time.Time) (database.Passenger, error) {
+	p := database.Passenger{
+		ID:          uuid.New(),
+		FirstName:   strings.TrimSpace(req.FirstName),
+		LastName:    strings.TrimSpace(req.LastName),
+		DateOfBirth: strings.TrimSpace(req.DateOfBirth),
+		Type:        req.Type,
+	}
+	if p.FirstName == "" || p.LastName == "" {
+		return p, fmt.Errorf("%w: first and last name are required", ErrInvalidInput)
+	}
+	if len(p.FirstName) > maxPassengerNameLen || len(p.LastName) > maxPassengerNameLen {
+		return p, fmt.Errorf("%w: names can be at most %d characters", ErrInvalidInput, maxPassengerNameLen)
+	}
+
+	born, err := time.Parse(passengerDateLayout, p.DateOfBirth)
+	if err != nil {
+		return p, fmt.Errorf("%w: date of birth must be formatted YYYY-MM-DD", ErrInvalidInput)
+	}
+	if born.After(departure) {
+		return p, fmt.Errorf("%w: date of birth is after departure", ErrInvalidInput)
+	}
+	var want database.PassengerType
+	switch age := ageOn(born, departure); {
+	case age >= adultMinAge:
+		want = database.PassengerAdult
+	case age >= childMinAge:
+		want = database.PassengerChild
+	default:
+		want = database.PassengerInfant
+	}
+	switch p.Type {
+	case database.PassengerAdult, database.PassengerChild, database.PassengerInfant:
+	default:
+		return p, fmt.Errorf("%w: passenger type must be adult, child or infant", ErrInvalidInput)
+	}
+	if p.Type != want {
+		return p, fmt.Errorf("%w: passenger is %s at departure, not %s", ErrInvalidInput, want, p.Type)
+	}
+
+	if req.Document != nil {
+		doc := database.TravelDocument{
+			Type:    strings.TrimSpace(req.Document.Type),
+			Number:  strings.ToUpper(strings.TrimSpace(req.Document.Number)),
+			Country: strings.ToUpper(strings.TrimSpace(req.Document.Country)),
+		}
+		if !validDocumentTypes[doc.Type] {
+			return p, fmt.Errorf("%w: document type must be passport or national_id", ErrInvalidInput)
+		}
+		if doc.Number == "" || len(doc.Number) > maxDocumentNumberLen {
+			return p, fmt.Errorf("%w: document number must be 1 to %d characters", ErrInvalidInput, maxDocumentNumberLen)
+		}
+		if len(doc.Country) != 2 || !isUpperAlpha(doc.Country) {
+			return p, fmt.Errorf("%w: document country must be a two-letter country code", ErrInvalidInput)
+		}
+		if req.Document.ExpiresOn != nil {
+			expiresOn := strings.TrimSpace(*req.Document.ExpiresOn)
+			expires, err := time.Parse(passengerDateLayout, expiresOn)
+			if err != nil {
+				return p, fmt.Errorf("%w: document expiry must be formatted YYYY-MM-DD", ErrInvalidInput)
+			}
+			if expires.Before(departure.UTC().Truncate(24 * time.Hour)) {
+				return p, fmt.Errorf("%w: document expires before departure", ErrInvalidInput)
+			}
+			doc.ExpiresOn = &expiresOn
+		}
+		p.Document = &doc
+	}
+	return p, nil
+}
+
+// ageOn returns the age in whole years on day of someone born on born
+func ageOn(born, day time.Time) int {
+	day = day.UTC()
+	age := day.Year() - born.Year()
+	if day.Month() < born.Month() || (day.Month() == born.Month() && day.Day() < born.Day()) {
+		age--
+	}
+	return age
+}
+
+// isUpperAlpha reports whether s consists of letters A to Z only
+func isUpperAlpha(s string) bool {
+	for _, r := range s {
+		if r < 'A' || r > 'Z' {
+			return false
+		}
+	}
+	return true
+}
+
+// validateOrderPassengers checks that every seat of an order has a
+// passenger and that every adult and child has a seat on each of its
+// flights, as required before payment
+func validateOrderPassengers(order *database.Order, seats []database.Seat) error {
+	assigned := make(map[uuid.UUID]bool)
+	for _, p := range order.Passengers {
+		for _, seat := range p.Seats {
+			assigned[seat.ID] = true
+		}
+	}
+	for _, seat := range orderSeats(order, seats) {
+		if !assigned[seat.ID] {
+			name := seat.SeatNumber
+			if name == "" {
+				name = "booked without a seat"
+			}
+			return fmt.Errorf("%w: passenger details are required for every seat (seat %s has none)", ErrInvalidInput, name)
+		}
+	}
+
+	flights := len(order.Segments)
+	if flights == 0 {
+		flights = 1
+	}
+	for _, p := range order.Passengers {
+		if p.Type != database.PassengerInfant && len(p.Seats) != flights {
+			return fmt.Errorf("%w: passenger %s %s needs a seat on every flight of the order", ErrInvalidInput, p.FirstName, p.LastName)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,110 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/cx-tal-miterani/flight-booking-system/api-server/internal/database"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAgeOn(t *testing.T) {
+	born := time.Date(2014, 6, 15, 0, 0, 0, 0, time.UTC)
+	assert.Equal(t, 11, ageOn(born, time.Date(2026, 6, 14, 23, 0, 0, 0, time.UTC)))
+	assert.Equal(t, 12, ageOn(born, time.Date(2026, 6, 15, 8, 0, 0, 0, time.UTC)))
+}
+
+func TestBuildPassengers(t *testing.T) {
+	departure := time.Date(2026, 7, 1, 9, 30, 0, 0, time.UTC)
+	outbound, inbound := uuid.New(), uuid.New()
+	seats := []database.PassengerSeat{
+		{ID: uuid.New(), FlightID: outbound, SeatNumber: "12A"},
+		{ID: uuid.New(), FlightID: outbound, SeatNumber: "12B"},
+		{ID: uuid.New(), FlightID: inbound, SeatNumber: "3C"},
+	}
+	adult := func(seatIDs ...uuid.UUID) PassengerRequest {
+		req := PassengerRequest{FirstName: " Ada ", LastName: "Lovelace", DateOfBirth: "1990-12-10", Type: database.PassengerAdult}
+		for _, id := range seatIDs {
+			req.SeatIDs = append(req.SeatIDs, id.String())
+		}
+		return req
+	}
+	child := PassengerRequest{FirstName: "Byron", LastName: "King", DateOfBirth: "2018-01-01", Type: database.PassengerChild, SeatIDs: []string{seats[1].ID.String()}}
+	infant := PassengerRequest{FirstName: "Ann", LastName: "King", DateOfBirth: "2025-09-30", Type: database.PassengerInfant}
+
+	passengers, err := buildPassengers([]PassengerRequest{adult(seats[0].ID, seats[2].ID), child, infant}, seats, departure)
+	require.NoError(t, err)
+	require.Len(t, passengers, 3)
+	assert.Equal(t, "Ada", passengers[0].FirstName)
+	assert.Equal(t, []database.PassengerSeat{seats[0], seats[2]}, passengers[0].Seats)
+	assert.Equal(t, []database.PassengerSeat{seats[1]}, passengers[1].Seats)
+	assert.Empty(t, passengers[2].Seats)
+
+	withDocument := adult(seats[0].ID)
+	expires := "2030-01-31"
+	withDocument.Document = &database.TravelDocument{Type: "passport", Number: "x1234567", Country: "gb", ExpiresOn: &expires}
+	passengers, err = buildPassengers([]PassengerRequest{withDocument}, seats, departure)
+	require.NoError(t, err)
+	assert.Equal(t, &database.TravelDocument{Type: "passport", Number: "X1234567", Country: "GB", ExpiresOn: &expires}, passengers[0].Document)
+
+	expired := "2026-06-30"
+	invalid := map[string][]PassengerRequest{
+		"no passengers":         nil,
+		"missing name":          {{LastName: "Lovelace", DateOfBirth: "1990-12-10", Type: database.PassengerAdult}},
+		"bad date of birth":     {{FirstName: "Ada", LastName: "Lovelace", DateOfBirth: "10/12/1990", Type: database.PassengerAdult}},
+		"child typed as adult":  {adult(seats[0].ID), {FirstName: "Byron", LastName: "King", DateOfBirth: "2018-01-01", Type: database.PassengerAdult}},
+		"unknown type":          {{FirstName: "Ada", LastName: "Lovelace", DateOfBirth: "1990-12-10", Type: "senior"}},
+		"infant with seat":      {adult(seats[0].ID), {FirstName: "Ann", LastName: "King", DateOfBirth: "2025-09-30", Type: database.PassengerInfant, SeatIDs: []string{seats[1].ID.String()}}},
+		"seat not in order":     {adult(uuid.New())},
+		"seat twice":            {adult(seats[0].ID), {FirstName: "Bob", LastName: "Lovelace", DateOfBirth: "1980-01-01", Type: database.PassengerAdult, SeatIDs: []string{seats[0].ID.String()}}},
+		"two seats on a flight": {adult(seats[0].ID, seats[1].ID)},
+		"no adult":              {child},
+		"more infants":          {adult(seats[0].ID), infant, infant},
+		"expired document": {{FirstName: "Ada", LastName: "Lovelace", DateOfBirth: "1990-12-10", Type: database.PassengerAdult,
+			Document: &database.TravelDocument{Type: "passport", Number: "X1", Country: "GB", ExpiresOn: &expired}}},
+		"bad document country": {{FirstName: "Ada", LastName: "Lovelace", DateOfBirth: "1990-12-10", Type: database.PassengerAdult,
+			Document: &database.TravelDocument{Type: "passport", Number: "X1", Country: "GBR"}}},
+	}
+	for name, reqs := range invalid {
+		t.Run(name, func(t *testing.T) {
+			_, err := buildPassengers(reqs, seats, departure)
+			assert.ErrorIs(t, err, ErrInvalidInput)
+		})
+	}
+}
+
+func TestValidateOrderPassengers(t *testing.T) {
+	flightID := uuid.New()
+	seatA := database.Seat{ID: uuid.New(), FlightID: flightID, SeatNumber: "1A"}
+	seatB := database.Seat{ID: uuid.New(), FlightID: flightID, SeatNumber: "1B"}
+	seats := []database.Seat{seatA, seatB}
+	passenger := func(seat database.Seat) database.Passenger {
+		return database.Passenger{
+			FirstName: "Ada",
+			LastName:  "Lovelace",
+			Type:      database.PassengerAdult,
+			Seats:     []database.PassengerSeat{{ID: seat.ID, FlightID: seat.FlightID, SeatNumber: seat.SeatNumber}},
+		}
+	}
+
+	order := &database.Order{FlightID: flightID, Passengers: []database.Passenger{passenger(seatA), passenger(seatB)}}
+	assert.NoError(t, validateOrderPassengers(order, seats))
+
+	order.Passengers = order.Passengers[:1]
+	assert.ErrorIs(t, validateOrderPassengers(order, seats), ErrInvalidInput)
+
+	overbooked := &database.Order{
+		FlightID:        flightID,
+		OverbookedSeats: []database.OverbookedSeat{{ID: uuid.New(), FlightID: flightID, Status: database.OverbookedSeatUnassigned}},
+	}
+	assert.ErrorIs(t, validateOrderPassengers(overbooked, nil), ErrInvalidInput)
+
+	roundTrip := &database.Order{
+		FlightID:   flightID,
+		Segments:   []database.OrderSegment{{FlightID: flightID}, {FlightID: uuid.New()}},
+		Passengers: []database.Passenger{passenger(seatA)},
+	}
+	assert.ErrorIs(t, validateOrderPassengers(roundTrip, []database.Seat{seatA}), ErrInvalidInput)
+}
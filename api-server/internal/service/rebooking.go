@@ -0,0 +1,94 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/cx-tal-miterani/flight-booking-system/api-server/internal/database"
+	"github.com/google/uuid"
+)
+
+// RebookingResponseRequest is a customer's answer to a rebooking offer.
+// Accepting requires choosing one of the offered alternative flights.
+type RebookingResponseRequest struct {
+	Accept   bool   `json:"accept"`
+	FlightID string `json:"flightId,omitempty"`
+}
+
+// GetRebookingOffer returns the rebooking offer made to an order together
+// with its alternative flights
+func (s *BookingService) GetRebookingOffer(ctx context.Context, orderID string) (*database.RebookingOffer, error) {
+	oid, err := uuid.Parse(orderID)
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid order ID", ErrInvalidInput)
+	}
+
+	offer, err := s.repo.GetRebookingOffer(ctx, oid)
+	if err != nil {
+		return nil, err
+	}
+
+	offer.Alternatives = []database.Flight{}
+	for _, id := range offer.AlternativeFlightIDs {
+		flight, err := s.repo.GetFlightByID(ctx, id)
+		if errors.Is(err, database.ErrNotFound) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		offer.Alternatives = append(offer.Alternatives, *flight)
+	}
+
+	return offer, nil
+}
+
+// RespondToRebooking passes a customer's answer to a pending rebooking offer
+// on to the disruption workflow, which rebooks or refunds the order
+func (s *BookingService) RespondToRebooking(ctx context.Context, orderID string, req RebookingResponseRequest) (*database.RebookingOffer, error) {
+	offer, err := s.GetRebookingOffer(ctx, orderID)
+	if err != nil {
+		return nil, err
+	}
+	if err := validateRebookingResponse(offer, req, time.Now()); err != nil {
+		return nil, err
+	}
+
+	err = s.signalOrderWorkflow(ctx, offer.OrderID, offer.WorkflowID, "rebooking-response", map[string]interface{}{
+		"orderId":  offer.OrderID.String(),
+		"accept":   req.Accept,
+		"flightId": req.FlightID,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to signal rebooking response: %w", err)
+	}
+
+	return offer, nil
+}
+
+// validateRebookingResponse checks that an offer can still be answered and
+// that an accepted offer names one of its alternatives
+func validateRebookingResponse(offer *database.RebookingOffer, req RebookingResponseRequest, now time.Time) error {
+	if offer.Status != database.RebookingOfferPending {
+		return fmt.Errorf("%w: rebooking offer is %s", ErrInvalidInput, offer.Status)
+	}
+	if now.After(offer.ExpiresAt) {
+		return fmt.Errorf("%w: rebooking offer has expired", ErrInvalidInput)
+	}
+	if !req.Accept {
+		return nil
+	}
+
+	flightID, err := uuid.Parse(req.FlightID)
+	if err != nil {
+		return fmt.Errorf("%w: flightId must be one of the alternative flights", ErrInvalidInput)
+	}
+	for _, id := range offer.AlternativeFlightIDs {
+		if id == flightID {
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: flight %s is not an alternative for this order", ErrInvalidInput, req.FlightID)
+}
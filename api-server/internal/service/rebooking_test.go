@@ -0,0 +1,59 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/cx-tal-miterani/flight-booking-system/api-server/internal/database"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateRebookingResponse(t *testing.T) {
+	now := time.Date(2030, 3, 1, 12, 0, 0, 0, time.UTC)
+	alternative := uuid.New()
+	pending := func() *database.RebookingOffer {
+		return &database.RebookingOffer{
+			Status:               database.RebookingOfferPending,
+			AlternativeFlightIDs: []uuid.UUID{uuid.New(), alternative},
+			ExpiresAt:            now.Add(time.Hour),
+		}
+	}
+
+	tests := []struct {
+		name   string
+		modify func(o *database.RebookingOffer)
+		req    RebookingResponseRequest
+		valid  bool
+	}{
+		{name: "accept alternative", req: RebookingResponseRequest{Accept: true, FlightID: alternative.String()}, valid: true},
+		{name: "decline", req: RebookingResponseRequest{Accept: false}, valid: true},
+		{name: "accept without flight", req: RebookingResponseRequest{Accept: true}},
+		{name: "accept other flight", req: RebookingResponseRequest{Accept: true, FlightID: uuid.NewString()}},
+		{
+			name:   "already answered",
+			modify: func(o *database.RebookingOffer) { o.Status = database.RebookingOfferAccepted },
+			req:    RebookingResponseRequest{Accept: false},
+		},
+		{
+			name:   "expired",
+			modify: func(o *database.RebookingOffer) { o.ExpiresAt = now.Add(-time.Minute) },
+			req:    RebookingResponseRequest{Accept: true, FlightID: alternative.String()},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			offer := pending()
+			if tt.modify != nil {
+				tt.modify(offer)
+			}
+			err := validateRebookingResponse(offer, tt.req, now)
+			if tt.valid {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorIs(t, err, ErrInvalidInput)
+			}
+		})
+	}
+}
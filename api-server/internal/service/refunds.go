@@ -0,0 +1,191 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/cx-tal-miterani/flight-booking-system/api-server/internal/database"
+	"github.com/cx-tal-miterani/flight-booking-system/api-server/internal/websocket"
+	"github.com/google/uuid"
+	"go.temporal.io/sdk/client"
+)
+
+// RefundRule refunds Percent of the fares of a cancelled booking when it is
+// cancelled at least MinNotice before the order's first departure
+type RefundRule struct {
+	MinNotice time.Duration `json:"minNotice"`
+	Percent   int           `json:"percent"`
+}
+
+// RefundRules are the refund rules for cancelled bookings, longest notice
+// first. Bookings cancelled with less notice than the last rule are not
+// refunded.
+var RefundRules = []RefundRule{
+	{MinNotice: 7 * 24 * time.Hour, Percent: 100},
+	{MinNotice: 24 * time.Hour, Percent: 50},
+}
+
+// CancelBookingRequest cancels passengers of a confirmed order, or the whole
+// order when PassengerIDs is empty
+type CancelBookingRequest struct {
+	PassengerIDs []string `json:"passengerIds,omitempty"`
+}
+
+// CancelBooking cancels a confirmed order, or some of its passengers, and
+// refunds the share of their fares the refund rules allow. The seats are
+// released right away; a RefundWorkflow returns the money through the
+// payment provider. The refund is returned pending, or completed when
+// nothing is due; the order lists it with its outcome.
+func (s *BookingService) CancelBooking(ctx context.Context, orderID string, req CancelBookingRequest) (*database.OrderRefund, error) {
+	oid, err := uuid.Parse(orderID)
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid order ID", ErrInvalidInput)
+	}
+
+	order, err := s.repo.GetOrderByID(ctx, oid)
+	if err != nil {
+		return nil, err
+	}
+	if order.Status != database.OrderStatusConfirmed {
+		return nil, fmt.Errorf("%w: only confirmed orders can be cancelled with a refund", database.ErrOrderNotModifiable)
+	}
+	passengerIDs, err := cancelledPassengers(order.Passengers, req.PassengerIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	flightIDs := []uuid.UUID{order.FlightID}
+	if len(order.Segments) > 0 {
+		flightIDs = flightIDs[:0]
+		for _, seg := range order.Segments {
+			flightIDs = append(flightIDs, seg.FlightID)
+		}
+	}
+	var departure time.Time
+	for i, id := range flightIDs {
+		flight, err := s.repo.GetFlightByID(ctx, id)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get flight: %w", err)
+		}
+		if flight.Status == database.FlightStatusCancelled {
+			return nil, fmt.Errorf("%w: flight %s is cancelled; see the rebooking offer", database.ErrOrderNotModifiable, flight.FlightNumber)
+		}
+		if i == 0 {
+			departure = flight.DepartureTime
+		}
+	}
+	now := time.Now()
+	if !departure.After(now) {
+		return nil, fmt.Errorf("%w: the booking cannot be cancelled after departure", database.ErrOrderNotModifiable)
+	}
+
+	id := uuid.New()
+	workflowID := fmt.Sprintf("refund-%s", id)
+	refund, released, err := s.repo.CancelBooking(ctx, &database.OrderRefund{
+		ID:            id,
+		OrderID:       oid,
+		PassengerIDs:  passengerIDs,
+		RefundPercent: refundPercent(departure.Sub(now)),
+		WorkflowID:    &workflowID,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	hub := websocket.GetHub()
+	for flightID, ids := range seatIDsByFlight(released) {
+		hub.BroadcastSeatsReleased(flightID, ids, orderID)
+	}
+	seatIDs := make([]uuid.UUID, len(released))
+	for i, seat := range released {
+		seatIDs[i] = seat.ID
+	}
+	s.notifyWaitlists(ctx, seatIDs)
+
+	if refund.Status != database.RefundPending {
+		return refund, nil
+	}
+
+	workflowOptions := client.StartWorkflowOptions{
+		ID:        workflowID,
+		TaskQueue: "flight-booking-queue",
+	}
+	workflowInput := map[string]interface{}{
+		"refundId": refund.ID.String(),
+		"orderId":  orderID,
+		"amount":   refund.Amount,
+	}
+	if _, err := s.temporalClient.ExecuteWorkflow(ctx, workflowOptions, "RefundWorkflow", workflowInput); err != nil {
+		// The booking is cancelled either way; the failed refund is left
+		// for support to pay out
+		fmt.Printf("Warning: failed to start refund workflow for refund %s: %v\n", refund.ID, err)
+		if ferr := s.repo.FailOrderRefund(ctx, refund.ID, "refund could not be started"); ferr != nil {
+			fmt.Printf("Warning: failed to fail refund %s: %v\n", refund.ID, ferr)
+		}
+		return s.repo.GetOrderRefund(ctx, refund.ID)
+	}
+
+	return refund, nil
+}
+
+// refundPercent returns the share of the fares refunded for a booking
+// cancelled notice before departure
+func refundPercent(notice time.Duration) int {
+	for _, rule := range RefundRules {
+		if notice >= rule.MinNotice {
+			return rule.Percent
+		}
+	}
+	return 0
+}
+
+// cancelledPassengers resolves the passengers to cancel from an order's
+// passengers. It returns nil when every active passenger is cancelled, i.e.
+// the whole order. The passengers who remain must still include an adult for
+// each infant.
+func cancelledPassengers(passengers []database.Passenger, refs []string) ([]uuid.UUID, error) {
+	if len(refs) == 0 {
+		return nil, nil
+	}
+
+	active := make(map[uuid.UUID]database.Passenger, len(passengers))
+	for _, p := range passengers {
+		if p.CancelledAt == nil {
+			active[p.ID] = p
+		}
+	}
+	ids := make([]uuid.UUID, 0, len(refs))
+	cancelled := make(map[uuid.UUID]bool, len(refs))
+	for _, ref := range refs {
+		id, err := uuid.Parse(ref)
+		if err != nil {
+			return nil, fmt.Errorf("%w: invalid passenger ID %q", ErrInvalidInput, ref)
+		}
+		if cancelled[id] {
+			return nil, fmt.Errorf("%w: passenger %s is listed twice", ErrInvalidInput, id)
+		}
+		if _, ok := active[id]; !ok {
+			return nil, fmt.Errorf("%w: passenger %s is not an active passenger of this order", ErrInvalidInput, id)
+		}
+		cancelled[id] = true
+		ids = append(ids, id)
+	}
+	if len(ids) == len(active) {
+		return nil, nil
+	}
+
+	counts := make(map[database.PassengerType]int)
+	for id, p := range active {
+		if !cancelled[id] {
+			counts[p.Type]++
+		}
+	}
+	if counts[database.PassengerAdult] == 0 {
+		return nil, fmt.Errorf("%w: children and infants must travel with an adult", ErrInvalidInput)
+	}
+	if counts[database.PassengerInfant] > counts[database.PassengerAdult] {
+		return nil, fmt.Errorf("%w: each infant must travel on the lap of a different adult", ErrInvalidInput)
+	}
+	return ids, nil
+}
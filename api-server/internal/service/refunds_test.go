@@ -0,0 +1,77 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/cx-tal-miterani/flight-booking-system/api-server/internal/database"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRefundPercent(t *testing.T) {
+	tests := []struct {
+		notice time.Duration
+		want   int
+	}{
+		{30 * 24 * time.Hour, 100},
+		{7 * 24 * time.Hour, 100},
+		{7*24*time.Hour - time.Minute, 50},
+		{24 * time.Hour, 50},
+		{23 * time.Hour, 0},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, refundPercent(tt.notice), tt.notice.String())
+	}
+}
+
+func TestCancelledPassengers(t *testing.T) {
+	cancelledAt := time.Now()
+	passenger := func(typ database.PassengerType) database.Passenger {
+		return database.Passenger{ID: uuid.New(), Type: typ}
+	}
+	adult, adult2, infant, child := passenger(database.PassengerAdult), passenger(database.PassengerAdult),
+		passenger(database.PassengerInfant), passenger(database.PassengerChild)
+	gone := passenger(database.PassengerAdult)
+	gone.CancelledAt = &cancelledAt
+	passengers := []database.Passenger{adult, adult2, infant, child, gone}
+
+	t.Run("whole order", func(t *testing.T) {
+		ids, err := cancelledPassengers(passengers, nil)
+		require.NoError(t, err)
+		assert.Nil(t, ids)
+
+		ids, err = cancelledPassengers(passengers, []string{
+			adult.ID.String(), adult2.ID.String(), infant.ID.String(), child.ID.String(),
+		})
+		require.NoError(t, err)
+		assert.Nil(t, ids)
+	})
+
+	t.Run("some passengers", func(t *testing.T) {
+		ids, err := cancelledPassengers(passengers, []string{adult2.ID.String(), child.ID.String()})
+		require.NoError(t, err)
+		assert.Equal(t, []uuid.UUID{adult2.ID, child.ID}, ids)
+	})
+
+	invalid := map[string][]string{
+		"invalid ID":        {"not-a-uuid"},
+		"listed twice":      {child.ID.String(), child.ID.String()},
+		"not in order":      {uuid.New().String()},
+		"already cancelled": {gone.ID.String()},
+		"leaves no adult":   {adult.ID.String(), adult2.ID.String()},
+	}
+	for name, refs := range invalid {
+		t.Run(name, func(t *testing.T) {
+			_, err := cancelledPassengers(passengers, refs)
+			assert.ErrorIs(t, err, ErrInvalidInput)
+		})
+	}
+
+	t.Run("infant without a lap", func(t *testing.T) {
+		twoInfants := []database.Passenger{adult, adult2, infant, passenger(database.PassengerInfant)}
+		_, err := cancelledPassengers(twoInfants, []string{adult2.ID.String()})
+		assert.ErrorIs(t, err, ErrInvalidInput)
+	})
+}
@@ -0,0 +1,215 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/cx-tal-miterani/flight-booking-system/api-server/internal/database"
+	"github.com/google/uuid"
+	"go.temporal.io/sdk/client"
+)
+
+const (
+	scheduleTimeLayout = "15:04"
+	scheduleDateLayout = "2006-01-02"
+)
+
+// CreateFlightScheduleRequest represents an admin request to operate a flight
+// number on a weekly pattern. Times are local to the respective airport;
+// the cabin layout defaults to the first layout of the aircraft type.
+type CreateFlightScheduleRequest struct {
+	FlightNumber       string  `json:"flightNumber"`
+	Origin             string  `json:"origin"`
+	Destination        string  `json:"destination"`
+	DaysOfWeek         []int   `json:"daysOfWeek"`
+	DepartureTimeLocal string  `json:"departureTimeLocal"`
+	ArrivalTimeLocal   string  `json:"arrivalTimeLocal"`
+	ArrivalDayOffset   int     `json:"arrivalDayOffset,omitempty"`
+	EffectiveFrom      string  `json:"effectiveFrom"`
+	EffectiveTo        string  `json:"effectiveTo,omitempty"`
+	AircraftType       string  `json:"aircraftType"`
+	CabinLayout        string  `json:"cabinLayout,omitempty"`
+	PricePerSeat       float64 `json:"pricePerSeat"`
+}
+
+// GetFlightSchedules returns all flight schedules
+func (s *BookingService) GetFlightSchedules(ctx context.Context) ([]database.FlightSchedule, error) {
+	return s.repo.GetFlightSchedules(ctx)
+}
+
+// CreateFlightSchedule validates and stores a schedule, then starts
+// materializing its flights right away instead of waiting for the worker's
+// next scheduled run
+func (s *BookingService) CreateFlightSchedule(ctx context.Context, req CreateFlightScheduleRequest) (*database.FlightSchedule, error) {
+	sched := &database.FlightSchedule{
+		FlightNumber:       strings.ToUpper(strings.TrimSpace(req.FlightNumber)),
+		DaysOfWeek:         req.DaysOfWeek,
+		DepartureTimeLocal: strings.TrimSpace(req.DepartureTimeLocal),
+		ArrivalTimeLocal:   strings.TrimSpace(req.ArrivalTimeLocal),
+		ArrivalDayOffset:   req.ArrivalDayOffset,
+		EffectiveFrom:      strings.TrimSpace(req.EffectiveFrom),
+		AircraftType:       strings.ToUpper(strings.TrimSpace(req.AircraftType)),
+		CabinLayout:        strings.TrimSpace(req.CabinLayout),
+		PricePerSeat:       req.PricePerSeat,
+	}
+	if to := strings.TrimSpace(req.EffectiveTo); to != "" {
+		sched.EffectiveTo = &to
+	}
+	if err := validateFlightSchedule(sched, time.Now()); err != nil {
+		return nil, err
+	}
+
+	origin, err := s.lookupAirport(ctx, req.Origin)
+	if err != nil {
+		return nil, err
+	}
+	destination, err := s.lookupAirport(ctx, req.Destination)
+	if err != nil {
+		return nil, err
+	}
+	if origin.IATACode == destination.IATACode {
+		return nil, fmt.Errorf("%w: origin and destination must differ", ErrInvalidInput)
+	}
+	sched.Origin, sched.Destination = origin.IATACode, destination.IATACode
+	if err := validateScheduleDuration(sched, origin.Location(), destination.Location()); err != nil {
+		return nil, err
+	}
+
+	layout, err := s.scheduleCabinLayout(ctx, sched)
+	if err != nil {
+		return nil, err
+	}
+	sched.CabinLayout = layout.Name
+
+	if err := s.repo.CreateFlightSchedule(ctx, sched, layout.GenerateSeats(sched.PricePerSeat)); err != nil {
+		return nil, err
+	}
+
+	workflowOptions := client.StartWorkflowOptions{
+		ID:        fmt.Sprintf("flight-schedule-%s", sched.ID.String()),
+		TaskQueue: "flight-booking-queue",
+	}
+	workflowInput := map[string]interface{}{
+		"scheduleId": sched.ID.String(),
+	}
+	if _, err := s.temporalClient.ExecuteWorkflow(ctx, workflowOptions, "ScheduleMaterializationWorkflow", workflowInput); err != nil {
+		// Log but don't fail - the worker's periodic run picks the schedule up
+		fmt.Printf("Warning: failed to start schedule materialization: %v\n", err)
+	}
+
+	return sched, nil
+}
+
+// DeleteFlightSchedule stops a schedule from creating more flights. Flights
+// it already created are kept and can be cancelled individually.
+func (s *BookingService) DeleteFlightSchedule(ctx context.Context, id string) error {
+	scheduleID, err := uuid.Parse(id)
+	if err != nil {
+		return fmt.Errorf("%w: invalid schedule ID", ErrInvalidInput)
+	}
+	return s.repo.DeleteFlightSchedule(ctx, scheduleID)
+}
+
+// scheduleCabinLayout returns the layout a schedule's flights are created
+// with, which must belong to the schedule's aircraft type
+func (s *BookingService) scheduleCabinLayout(ctx context.Context, sched *database.FlightSchedule) (*database.CabinLayout, error) {
+	if _, err := s.repo.GetAircraftType(ctx, sched.AircraftType); err != nil {
+		if errors.Is(err, database.ErrNotFound) {
+			return nil, fmt.Errorf("%w: unknown aircraft type %q", ErrInvalidInput, sched.AircraftType)
+		}
+		return nil, err
+	}
+
+	if sched.CabinLayout == "" {
+		return s.aircraftCabinLayout(ctx, sched.AircraftType)
+	}
+
+	layout, err := s.cabinLayout(ctx, sched.CabinLayout)
+	if err != nil {
+		return nil, err
+	}
+	if layout.AircraftType != sched.AircraftType {
+		return nil, fmt.Errorf("%w: cabin layout %s is for aircraft type %s", ErrInvalidInput, layout.Name, layout.AircraftType)
+	}
+	return layout, nil
+}
+
+// validateFlightSchedule checks a schedule's flight number, pattern, times,
+// dates and fare, and sorts its days of the week
+func validateFlightSchedule(sched *database.FlightSchedule, now time.Time) error {
+	if !flightNumberPattern.MatchString(sched.FlightNumber) {
+		return fmt.Errorf("%w: invalid flight number %q", ErrInvalidInput, sched.FlightNumber)
+	}
+
+	if len(sched.DaysOfWeek) == 0 {
+		return fmt.Errorf("%w: at least one day of the week is required", ErrInvalidInput)
+	}
+	var seen [8]bool
+	days := make([]int, 0, len(sched.DaysOfWeek))
+	for _, d := range sched.DaysOfWeek {
+		if d < 1 || d > 7 {
+			return fmt.Errorf("%w: invalid day of the week %d (1 = Monday to 7 = Sunday)", ErrInvalidInput, d)
+		}
+		if !seen[d] {
+			seen[d] = true
+			days = append(days, d)
+		}
+	}
+	sort.Ints(days)
+	sched.DaysOfWeek = days
+
+	if _, err := time.Parse(scheduleTimeLayout, sched.DepartureTimeLocal); err != nil {
+		return fmt.Errorf("%w: departure time must be HH:MM", ErrInvalidInput)
+	}
+	if _, err := time.Parse(scheduleTimeLayout, sched.ArrivalTimeLocal); err != nil {
+		return fmt.Errorf("%w: arrival time must be HH:MM", ErrInvalidInput)
+	}
+	if sched.ArrivalDayOffset < 0 || sched.ArrivalDayOffset > 2 {
+		return fmt.Errorf("%w: arrival day offset must be between 0 and 2", ErrInvalidInput)
+	}
+
+	from, err := time.Parse(scheduleDateLayout, sched.EffectiveFrom)
+	if err != nil {
+		return fmt.Errorf("%w: effective from date must be YYYY-MM-DD", ErrInvalidInput)
+	}
+	if sched.EffectiveTo != nil {
+		to, err := time.Parse(scheduleDateLayout, *sched.EffectiveTo)
+		if err != nil {
+			return fmt.Errorf("%w: effective to date must be YYYY-MM-DD", ErrInvalidInput)
+		}
+		if to.Before(from) {
+			return fmt.Errorf("%w: effective to date must not be before the effective from date", ErrInvalidInput)
+		}
+		if to.Before(now.UTC().Truncate(24*time.Hour).AddDate(0, 0, -1)) {
+			return fmt.Errorf("%w: schedule has already ended", ErrInvalidInput)
+		}
+	}
+
+	if sched.PricePerSeat <= 0 {
+		return fmt.Errorf("%w: price per seat must be positive", ErrInvalidInput)
+	}
+	return nil
+}
+
+// validateScheduleDuration checks that a schedule's flights arrive after
+// they depart and within the maximum flight duration, given the time zones
+// of its airports
+func validateScheduleDuration(sched *database.FlightSchedule, origin, destination *time.Location) error {
+	day, _ := time.Parse(scheduleDateLayout, sched.EffectiveFrom)
+	dep, _ := time.Parse(scheduleTimeLayout, sched.DepartureTimeLocal)
+	arr, _ := time.Parse(scheduleTimeLayout, sched.ArrivalTimeLocal)
+
+	departure := time.Date(day.Year(), day.Month(), day.Day(), dep.Hour(), dep.Minute(), 0, 0, origin)
+	arrival := time.Date(day.Year(), day.Month(), day.Day()+sched.ArrivalDayOffset, arr.Hour(), arr.Minute(), 0, 0, destination)
+	if !arrival.After(departure) {
+		return fmt.Errorf("%w: arrival time must be after departure time", ErrInvalidInput)
+	}
+	if arrival.Sub(departure) > maxLegDuration {
+		return fmt.Errorf("%w: flight duration exceeds %s", ErrInvalidInput, maxLegDuration)
+	}
+	return nil
+}
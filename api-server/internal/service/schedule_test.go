@@ -0,0 +1,121 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/cx-tal-miterani/flight-booking-system/api-server/internal/database"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateFlightSchedule(t *testing.T) {
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	valid := func() *database.FlightSchedule {
+		return &database.FlightSchedule{
+			FlightNumber:       "AA101",
+			DaysOfWeek:         []int{5, 1, 3, 1},
+			DepartureTimeLocal: "08:30",
+			ArrivalTimeLocal:   "11:45",
+			EffectiveFrom:      "2024-06-01",
+			PricePerSeat:       199.99,
+		}
+	}
+	date := func(s string) *string { return &s }
+
+	tests := []struct {
+		name   string
+		modify func(s *database.FlightSchedule)
+		valid  bool
+	}{
+		{name: "valid", modify: func(s *database.FlightSchedule) {}, valid: true},
+		{name: "started in the past", modify: func(s *database.FlightSchedule) { s.EffectiveFrom = "2024-01-01" }, valid: true},
+		{name: "ends today", modify: func(s *database.FlightSchedule) { s.EffectiveTo = date("2024-06-01") }, valid: true},
+		{name: "invalid flight number", modify: func(s *database.FlightSchedule) { s.FlightNumber = "A-101" }},
+		{name: "no days", modify: func(s *database.FlightSchedule) { s.DaysOfWeek = nil }},
+		{name: "day zero", modify: func(s *database.FlightSchedule) { s.DaysOfWeek = []int{0} }},
+		{name: "day eight", modify: func(s *database.FlightSchedule) { s.DaysOfWeek = []int{1, 8} }},
+		{name: "invalid departure time", modify: func(s *database.FlightSchedule) { s.DepartureTimeLocal = "8am" }},
+		{name: "invalid arrival time", modify: func(s *database.FlightSchedule) { s.ArrivalTimeLocal = "24:00" }},
+		{name: "negative day offset", modify: func(s *database.FlightSchedule) { s.ArrivalDayOffset = -1 }},
+		{name: "day offset too large", modify: func(s *database.FlightSchedule) { s.ArrivalDayOffset = 3 }},
+		{name: "invalid from date", modify: func(s *database.FlightSchedule) { s.EffectiveFrom = "06/01/2024" }},
+		{name: "invalid to date", modify: func(s *database.FlightSchedule) { s.EffectiveTo = date("2024-13-01") }},
+		{name: "ends before it starts", modify: func(s *database.FlightSchedule) { s.EffectiveTo = date("2024-05-31") }},
+		{name: "already ended", modify: func(s *database.FlightSchedule) {
+			s.EffectiveFrom = "2024-01-01"
+			s.EffectiveTo = date("2024-03-01")
+		}},
+		{name: "free", modify: func(s *database.FlightSchedule) { s.PricePerSeat = 0 }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := valid()
+			tt.modify(s)
+
+			err := validateFlightSchedule(s, now)
+			if tt.valid {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorIs(t, err, ErrInvalidInput)
+			}
+		})
+	}
+}
+
+func TestValidateFlightSchedule_SortsDays(t *testing.T) {
+	s := &database.FlightSchedule{
+		FlightNumber:       "AA101",
+		DaysOfWeek:         []int{7, 1, 3, 1, 7},
+		DepartureTimeLocal: "08:30",
+		ArrivalTimeLocal:   "11:45",
+		EffectiveFrom:      "2024-06-01",
+		PricePerSeat:       199.99,
+	}
+
+	require.NoError(t, validateFlightSchedule(s, time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)))
+	assert.Equal(t, []int{1, 3, 7}, s.DaysOfWeek)
+}
+
+func TestValidateScheduleDuration(t *testing.T) {
+	newYork, err := time.LoadLocation("America/New_York")
+	require.NoError(t, err)
+	london, err := time.LoadLocation("Europe/London")
+	require.NoError(t, err)
+
+	tests := []struct {
+		name        string
+		origin      *time.Location
+		destination *time.Location
+		departure   string
+		arrival     string
+		dayOffset   int
+		valid       bool
+	}{
+		{name: "same time zone", origin: newYork, destination: newYork, departure: "08:00", arrival: "11:00", valid: true},
+		{name: "arrives before departure", origin: newYork, destination: newYork, departure: "11:00", arrival: "08:00"},
+		{name: "overnight", origin: newYork, destination: london, departure: "19:00", arrival: "07:00", dayOffset: 1, valid: true},
+		// 19:00 EDT is 00:00 BST, so arriving at 23:00 the same day is too early
+		{name: "overnight without offset", origin: newYork, destination: london, departure: "19:00", arrival: "23:00"},
+		{name: "too long", origin: newYork, destination: newYork, departure: "08:00", arrival: "08:00", dayOffset: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := &database.FlightSchedule{
+				DepartureTimeLocal: tt.departure,
+				ArrivalTimeLocal:   tt.arrival,
+				ArrivalDayOffset:   tt.dayOffset,
+				EffectiveFrom:      "2024-06-01",
+			}
+
+			err := validateScheduleDuration(s, tt.origin, tt.destination)
+			if tt.valid {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorIs(t, err, ErrInvalidInput)
+			}
+		})
+	}
+}
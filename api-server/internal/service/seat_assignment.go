@@ -0,0 +1,257 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/cx-tal-miterani/flight-booking-system/api-server/internal/database"
+	"github.com/google/uuid"
+)
+
+// MaxPartySize is the largest party seats can be assigned to automatically
+const MaxPartySize = 9
+
+// autoAssignAttempts is how often a new block is picked when its seats are
+// taken between reading the seat map and holding them
+const autoAssignAttempts = 3
+
+// AutoAssignRequest asks for seats for a party travelling together. Class
+// restricts the seats to one cabin class; Preferences are seat attributes
+// (e.g. window, extra_legroom) that make a block more desirable.
+type AutoAssignRequest struct {
+	PartySize   int      `json:"partySize"`
+	Class       string   `json:"class,omitempty"`
+	Preferences []string `json:"preferences,omitempty"`
+}
+
+// AutoAssignSeats holds the best block of seats for a party on every flight
+// of an order, replacing the seats it held before. The party is seated in a
+// single row when possible, otherwise split over two adjacent rows with the
+// seats behind one another.
+func (s *BookingService) AutoAssignSeats(ctx context.Context, orderID string, req AutoAssignRequest) (*OrderStatusResponse, error) {
+	if err := validateAutoAssignRequest(req); err != nil {
+		return nil, err
+	}
+
+	oid, err := uuid.Parse(orderID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid order ID: %w", err)
+	}
+
+	order, err := s.repo.GetOrderByID(ctx, oid)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkOrderSeatsSelectable(order); err != nil {
+		return nil, err
+	}
+	if err := s.checkOrderFlightsBookable(ctx, order); err != nil {
+		return nil, err
+	}
+
+	flightIDs := []uuid.UUID{order.FlightID}
+	if len(order.Segments) > 0 {
+		flightIDs = flightIDs[:0]
+		for _, seg := range order.Segments {
+			flightIDs = append(flightIDs, seg.FlightID)
+		}
+	}
+
+	for attempt := 1; ; attempt++ {
+		var seatIDs []uuid.UUID
+		for _, flightID := range flightIDs {
+			seatMap, err := s.GetSeatMap(ctx, flightID.String(), nil)
+			if err != nil {
+				return nil, err
+			}
+			block, err := findSeatBlock(seatMap, oid, req)
+			if err != nil {
+				return nil, err
+			}
+			for _, seat := range block {
+				seatIDs = append(seatIDs, seat.ID)
+			}
+		}
+
+		// Another order may have taken one of the seats since the seat map
+		// was read; HoldSeats then holds none of them and a new block is picked
+		status, err := s.holdOrderSeats(ctx, order, seatIDs)
+		if errors.Is(err, database.ErrSeatNotAvailable) && attempt < autoAssignAttempts {
+			continue
+		}
+		return status, err
+	}
+}
+
+func validateAutoAssignRequest(req AutoAssignRequest) error {
+	if req.PartySize < 1 || req.PartySize > MaxPartySize {
+		return fmt.Errorf("%w: party size must be between 1 and %d", ErrInvalidInput, MaxPartySize)
+	}
+	if req.Class != "" && !validSeatClasses[req.Class] {
+		return fmt.Errorf("%w: invalid seat class %q", ErrInvalidInput, req.Class)
+	}
+	for _, pref := range req.Preferences {
+		if !validSeatAttributes[pref] {
+			return fmt.Errorf("%w: unknown seat attribute %q", ErrInvalidInput, pref)
+		}
+	}
+	return nil
+}
+
+// seatBlock is a candidate set of seats for a party
+type seatBlock struct {
+	seats     []*database.Seat
+	rows      int     // rows the party is spread over
+	aisles    int     // aisles between seats of the same row
+	preferred int     // seats with a preferred attribute, once per attribute
+	surcharge float64 // total attribute surcharge
+	start     int     // position of the first seat in its row
+}
+
+// betterThan reports whether b is a better block than o: fewer rows, then
+// fewer aisles, more preferred seats, lower surcharges, and finally further
+// forward and further left
+func (b *seatBlock) betterThan(o *seatBlock) bool {
+	switch {
+	case b.rows != o.rows:
+		return b.rows < o.rows
+	case b.aisles != o.aisles:
+		return b.aisles < o.aisles
+	case b.preferred != o.preferred:
+		return b.preferred > o.preferred
+	case b.surcharge != o.surcharge:
+		return b.surcharge < o.surcharge
+	case b.seats[0].RowNumber != o.seats[0].RowNumber:
+		return b.seats[0].RowNumber < o.seats[0].RowNumber
+	default:
+		return b.start < o.start
+	}
+}
+
+// end is the position of the last seat of a single-row block
+func (b *seatBlock) end() int {
+	return b.start + len(b.seats) - 1
+}
+
+// findSeatBlock picks the best block of free seats for a party from a seat
+// map. Seats already held by the order count as free.
+func findSeatBlock(m *database.SeatMap, orderID uuid.UUID, req AutoAssignRequest) ([]database.Seat, error) {
+	byID := make(map[uuid.UUID]*database.Seat, len(m.Seats))
+	for i := range m.Seats {
+		byID[m.Seats[i].ID] = &m.Seats[i]
+	}
+	free := func(id *uuid.UUID) *database.Seat {
+		if id == nil {
+			return nil
+		}
+		seat := byID[*id]
+		if seat == nil {
+			return nil
+		}
+		if seat.Status == database.SeatStatusAvailable ||
+			(seat.Status == database.SeatStatusHeld && seat.HeldByOrder != nil && *seat.HeldByOrder == orderID) {
+			return seat
+		}
+		return nil
+	}
+
+	var best *seatBlock
+	consider := func(b seatBlock) {
+		for _, seat := range b.seats {
+			for _, pref := range req.Preferences {
+				if seat.HasAttributes(pref) {
+					b.preferred++
+				}
+			}
+			b.surcharge += seat.Surcharge
+		}
+		if best == nil || b.betterThan(best) {
+			best = &b
+		}
+	}
+
+	for _, cabin := range m.Cabins {
+		if req.Class != "" && cabin.Class != req.Class {
+			continue
+		}
+
+		// The column group (section between aisles) of each position
+		var groups []int
+		for g, columns := range cabin.ColumnGroups {
+			for range columns {
+				groups = append(groups, g)
+			}
+		}
+
+		rows := make([][]*database.Seat, len(cabin.Rows))
+		for i, row := range cabin.Rows {
+			rows[i] = make([]*database.Seat, len(row.Seats))
+			for j, id := range row.Seats {
+				rows[i][j] = free(id)
+			}
+		}
+
+		for i := range rows {
+			for _, b := range rowBlocks(rows[i], groups, req.PartySize) {
+				consider(b)
+			}
+			if i+1 == len(rows) {
+				continue
+			}
+			// Split over this row and the next, k seats in front
+			for k := 1; k < req.PartySize; k++ {
+				for _, front := range rowBlocks(rows[i], groups, k) {
+					for _, back := range rowBlocks(rows[i+1], groups, req.PartySize-k) {
+						if front.start > back.end() || back.start > front.end() {
+							continue
+						}
+						consider(seatBlock{
+							seats:  append(append([]*database.Seat{}, front.seats...), back.seats...),
+							rows:   2,
+							aisles: front.aisles + back.aisles,
+							start:  front.start,
+						})
+					}
+				}
+			}
+		}
+	}
+
+	if best == nil {
+		class := "seats"
+		if req.Class != "" {
+			class = req.Class + " seats"
+		}
+		return nil, fmt.Errorf("%w: no %d %s together", database.ErrSeatNotAvailable, req.PartySize, class)
+	}
+
+	seats := make([]database.Seat, len(best.seats))
+	for i, seat := range best.seats {
+		seats[i] = *seat
+	}
+	return seats, nil
+}
+
+// rowBlocks returns every run of size adjacent free seats in a row
+func rowBlocks(row []*database.Seat, groups []int, size int) []seatBlock {
+	var blocks []seatBlock
+	for start := 0; start+size <= len(row); start++ {
+		b := seatBlock{rows: 1, start: start}
+		for _, seat := range row[start : start+size] {
+			if seat == nil {
+				b.seats = nil
+				break
+			}
+			b.seats = append(b.seats, seat)
+		}
+		if b.seats == nil {
+			continue
+		}
+		if start+size <= len(groups) {
+			b.aisles = groups[start+size-1] - groups[start]
+		}
+		blocks = append(blocks, b)
+	}
+	return blocks
+}
@@ -0,0 +1,144 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/cx-tal-miterani/flight-booking-system/api-server/internal/database"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testSeatMap builds the seat map of a 3-3 economy cabin of rows 10-12 with a
+// 2-2 business cabin in rows 1-2. Seats in taken are booked.
+func testSeatMap(t *testing.T, taken ...string) *database.SeatMap {
+	t.Helper()
+	layout := &database.CabinLayout{
+		Name: "test",
+		Cabins: []database.Cabin{
+			{Class: "business", FirstRow: 1, LastRow: 2, ColumnGroups: []string{"AC", "DF"}, PriceMultiplier: 2},
+			{Class: "economy", FirstRow: 10, LastRow: 12, ColumnGroups: []string{"ABC", "DEF"}, PriceMultiplier: 1,
+				ExtraLegroomRows: []int{10}},
+		},
+	}
+	seats := layout.GenerateSeats(100)
+	booked := make(map[string]bool)
+	for _, number := range taken {
+		booked[number] = true
+	}
+	for i := range seats {
+		seats[i].ID = uuid.New()
+		if seats[i].HasAttributes(database.SeatAttributeExtraLegroom) {
+			seats[i].Surcharge = 35
+		}
+		if booked[seats[i].SeatNumber] {
+			seats[i].Status = database.SeatStatusBooked
+		}
+	}
+	return database.BuildSeatMap(uuid.New(), layout, seats)
+}
+
+func seatNumbers(seats []database.Seat) []string {
+	numbers := make([]string, len(seats))
+	for i, s := range seats {
+		numbers[i] = s.SeatNumber
+	}
+	return numbers
+}
+
+func TestFindSeatBlock(t *testing.T) {
+	tests := []struct {
+		name  string
+		taken []string
+		req   AutoAssignRequest
+		want  []string
+	}{
+		{
+			name: "same row without crossing the aisle",
+			req:  AutoAssignRequest{PartySize: 3, Class: "economy"},
+			// Row 10 has a legroom surcharge
+			want: []string{"11A", "11B", "11C"},
+		},
+		{
+			name: "preferences outweigh surcharges",
+			req:  AutoAssignRequest{PartySize: 2, Class: "economy", Preferences: []string{"extra_legroom"}},
+			want: []string{"10A", "10B"},
+		},
+		{
+			name: "preferred window seat",
+			req:  AutoAssignRequest{PartySize: 2, Class: "economy", Preferences: []string{"window"}},
+			want: []string{"11A", "11B"},
+		},
+		{
+			name:  "across the aisle before splitting rows",
+			taken: []string{"10A", "10F", "11A", "11F", "12A", "12F"},
+			req:   AutoAssignRequest{PartySize: 4, Class: "economy"},
+			want:  []string{"11B", "11C", "11D", "11E"},
+		},
+		{
+			name:  "adjacent rows when no row has room",
+			taken: []string{"10B", "10C", "10D", "10E", "11B", "11C", "11D", "11E", "12B", "12C", "12D", "12E"},
+			req:   AutoAssignRequest{PartySize: 2, Class: "economy"},
+			want:  []string{"11A", "12A"},
+		},
+		{
+			name: "business cabin",
+			req:  AutoAssignRequest{PartySize: 2, Class: "business"},
+			want: []string{"1A", "1C"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			block, err := findSeatBlock(testSeatMap(t, tt.taken...), uuid.New(), tt.req)
+			require.NoError(t, err)
+			assert.ElementsMatch(t, tt.want, seatNumbers(block))
+		})
+	}
+}
+
+func TestFindSeatBlock_HeldByOrder(t *testing.T) {
+	orderID := uuid.New()
+	m := testSeatMap(t, "11A", "11B", "11C", "11D", "11E", "11F", "12A", "12B", "12C", "12D", "12E", "12F")
+	for i := range m.Seats {
+		if m.Seats[i].RowNumber != 10 {
+			continue
+		}
+		m.Seats[i].Status = database.SeatStatusHeld
+		if m.Seats[i].ColumnLetter <= "C" {
+			m.Seats[i].HeldByOrder = &orderID
+		} else {
+			other := uuid.New()
+			m.Seats[i].HeldByOrder = &other
+		}
+	}
+
+	block, err := findSeatBlock(m, orderID, AutoAssignRequest{PartySize: 3, Class: "economy"})
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"10A", "10B", "10C"}, seatNumbers(block))
+
+	_, err = findSeatBlock(m, orderID, AutoAssignRequest{PartySize: 4, Class: "economy"})
+	assert.ErrorIs(t, err, database.ErrSeatNotAvailable)
+}
+
+func TestFindSeatBlock_NoBlock(t *testing.T) {
+	// Only 10A, 10F and 12F are left in economy
+	m := testSeatMap(t, "10B", "10C", "10D", "10E", "11A", "11B", "11C", "11D", "11E", "11F",
+		"12A", "12B", "12C", "12D", "12E")
+
+	_, err := findSeatBlock(m, uuid.New(), AutoAssignRequest{PartySize: 2, Class: "economy"})
+	assert.ErrorIs(t, err, database.ErrSeatNotAvailable)
+
+	block, err := findSeatBlock(m, uuid.New(), AutoAssignRequest{PartySize: 2})
+	require.NoError(t, err, "any class")
+	assert.Equal(t, "business", block[0].Class)
+}
+
+func TestValidateAutoAssignRequest(t *testing.T) {
+	assert.NoError(t, validateAutoAssignRequest(AutoAssignRequest{PartySize: 1}))
+	assert.NoError(t, validateAutoAssignRequest(AutoAssignRequest{PartySize: 9, Class: "first", Preferences: []string{"aisle"}}))
+	assert.ErrorIs(t, validateAutoAssignRequest(AutoAssignRequest{PartySize: 0}), ErrInvalidInput)
+	assert.ErrorIs(t, validateAutoAssignRequest(AutoAssignRequest{PartySize: 10}), ErrInvalidInput)
+	assert.ErrorIs(t, validateAutoAssignRequest(AutoAssignRequest{PartySize: 2, Class: "steerage"}), ErrInvalidInput)
+	assert.ErrorIs(t, validateAutoAssignRequest(AutoAssignRequest{PartySize: 2, Preferences: []string{"bulkhead"}}), ErrInvalidInput)
+}
@@ -0,0 +1,42 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"math"
+
+	"github.com/cx-tal-miterani/flight-booking-system/api-server/internal/database"
+)
+
+// UpdateSeatAttributeSurchargeRequest sets the surcharge of a seat attribute
+type UpdateSeatAttributeSurchargeRequest struct {
+	Surcharge float64 `json:"surcharge"`
+}
+
+// GetSeatAttributeSurcharges returns the surcharge of every seat attribute
+func (s *BookingService) GetSeatAttributeSurcharges(ctx context.Context) ([]database.SeatAttributeSurcharge, error) {
+	return s.repo.GetSeatAttributeSurcharges(ctx)
+}
+
+// UpdateSeatAttributeSurcharge sets the surcharge of a seat attribute
+func (s *BookingService) UpdateSeatAttributeSurcharge(ctx context.Context, attribute string, req UpdateSeatAttributeSurchargeRequest) (*database.SeatAttributeSurcharge, error) {
+	if err := validateSeatAttributeSurcharge(attribute, req); err != nil {
+		return nil, err
+	}
+
+	surcharge := &database.SeatAttributeSurcharge{Attribute: attribute, Surcharge: req.Surcharge}
+	if err := s.repo.UpdateSeatAttributeSurcharge(ctx, surcharge); err != nil {
+		return nil, err
+	}
+	return surcharge, nil
+}
+
+func validateSeatAttributeSurcharge(attribute string, req UpdateSeatAttributeSurchargeRequest) error {
+	if !validSeatAttributes[attribute] {
+		return fmt.Errorf("%w: unknown seat attribute %q", ErrInvalidInput, attribute)
+	}
+	if req.Surcharge < 0 || math.IsNaN(req.Surcharge) || math.IsInf(req.Surcharge, 0) {
+		return fmt.Errorf("%w: surcharge must not be negative", ErrInvalidInput)
+	}
+	return nil
+}
@@ -0,0 +1,32 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateSeatAttributeSurcharge(t *testing.T) {
+	tests := []struct {
+		name      string
+		attribute string
+		surcharge float64
+		valid     bool
+	}{
+		{name: "valid", attribute: "exit_row", surcharge: 25, valid: true},
+		{name: "free", attribute: "window", surcharge: 0, valid: true},
+		{name: "unknown attribute", attribute: "bulkhead", surcharge: 10},
+		{name: "negative", attribute: "aisle", surcharge: -5},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateSeatAttributeSurcharge(tt.attribute, UpdateSeatAttributeSurchargeRequest{Surcharge: tt.surcharge})
+			if tt.valid {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorIs(t, err, ErrInvalidInput)
+			}
+		})
+	}
+}
@@ -0,0 +1,184 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/cx-tal-miterani/flight-booking-system/api-server/internal/database"
+	"github.com/cx-tal-miterani/flight-booking-system/api-server/internal/websocket"
+	"github.com/google/uuid"
+)
+
+// validSeatBlockReasons are the reasons seats can be blocked for
+var validSeatBlockReasons = map[database.SeatBlockReason]bool{
+	database.SeatBlockCrew:        true,
+	database.SeatBlockMaintenance: true,
+	database.SeatBlockOperational: true,
+}
+
+// maxBlockedByLength is the longest name of the person blocking seats
+const maxBlockedByLength = 100
+
+// SeatSelection picks seats of a flight by seat ID or number (e.g. "12C"),
+// and whole rows by number
+type SeatSelection struct {
+	Seats []string `json:"seats,omitempty"`
+	Rows  []int    `json:"rows,omitempty"`
+}
+
+// BlockSeatsRequest takes seats out of sale. BlockedBy names who blocked
+// them; without BlockedUntil they stay blocked until unblocked.
+type BlockSeatsRequest struct {
+	SeatSelection
+	Reason       database.SeatBlockReason `json:"reason"`
+	Note         string                   `json:"note,omitempty"`
+	BlockedBy    string                   `json:"blockedBy"`
+	BlockedUntil *time.Time               `json:"blockedUntil,omitempty"`
+}
+
+// GetSeatBlocks returns the blocked seats of a flight
+func (s *BookingService) GetSeatBlocks(ctx context.Context, flightID string) ([]database.SeatBlock, error) {
+	fid, err := uuid.Parse(flightID)
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid flight ID", ErrInvalidInput)
+	}
+	if _, err := s.repo.GetFlightByID(ctx, fid); err != nil {
+		return nil, err
+	}
+	return s.repo.GetSeatBlocks(ctx, fid)
+}
+
+// BlockSeats takes seats of a flight out of sale, all of them or none. Held
+// and booked seats cannot be blocked. Open seat maps are told right away.
+func (s *BookingService) BlockSeats(ctx context.Context, flightID string, req BlockSeatsRequest) ([]database.SeatBlock, error) {
+	fid, err := uuid.Parse(flightID)
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid flight ID", ErrInvalidInput)
+	}
+	req.BlockedBy = strings.TrimSpace(req.BlockedBy)
+	if err := validateBlockSeatsRequest(req, time.Now()); err != nil {
+		return nil, err
+	}
+
+	seatIDs, err := s.resolveSeatSelection(ctx, fid, req.SeatSelection)
+	if err != nil {
+		return nil, err
+	}
+
+	block := database.SeatBlock{Reason: req.Reason, BlockedBy: req.BlockedBy, BlockedUntil: req.BlockedUntil}
+	if note := strings.TrimSpace(req.Note); note != "" {
+		block.Note = &note
+	}
+	blocks, err := s.repo.BlockSeats(ctx, fid, seatIDs, block)
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make([]string, len(blocks))
+	for i, b := range blocks {
+		ids[i] = b.SeatID.String()
+	}
+	websocket.GetHub().BroadcastSeatsBlocked(flightID, ids)
+
+	return blocks, nil
+}
+
+// UnblockSeats puts blocked seats of a flight back on sale and returns the
+// ones that were blocked. Customers waiting for the seats' classes are
+// offered them.
+func (s *BookingService) UnblockSeats(ctx context.Context, flightID string, sel SeatSelection) ([]uuid.UUID, error) {
+	fid, err := uuid.Parse(flightID)
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid flight ID", ErrInvalidInput)
+	}
+
+	seatIDs, err := s.resolveSeatSelection(ctx, fid, sel)
+	if err != nil {
+		return nil, err
+	}
+	unblocked, err := s.repo.UnblockSeats(ctx, fid, seatIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(unblocked) > 0 {
+		ids := make([]string, len(unblocked))
+		for i, id := range unblocked {
+			ids[i] = id.String()
+		}
+		websocket.GetHub().BroadcastSeatsReleased(flightID, ids, "")
+		s.notifyWaitlists(ctx, unblocked)
+	}
+	return unblocked, nil
+}
+
+func validateBlockSeatsRequest(req BlockSeatsRequest, now time.Time) error {
+	if !validSeatBlockReasons[req.Reason] {
+		return fmt.Errorf("%w: reason must be crew, maintenance or operational", ErrInvalidInput)
+	}
+	if req.BlockedBy == "" {
+		return fmt.Errorf("%w: blockedBy is required", ErrInvalidInput)
+	}
+	if len(req.BlockedBy) > maxBlockedByLength {
+		return fmt.Errorf("%w: blockedBy must be at most %d characters", ErrInvalidInput, maxBlockedByLength)
+	}
+	if req.BlockedUntil != nil && !req.BlockedUntil.After(now) {
+		return fmt.Errorf("%w: blockedUntil must be in the future", ErrInvalidInput)
+	}
+	return nil
+}
+
+// resolveSeatSelection returns the IDs of the selected seats of a flight.
+// Unknown seats and rows without seats are invalid input.
+func (s *BookingService) resolveSeatSelection(ctx context.Context, flightID uuid.UUID, sel SeatSelection) ([]uuid.UUID, error) {
+	if len(sel.Seats) == 0 && len(sel.Rows) == 0 {
+		return nil, fmt.Errorf("%w: no seats or rows given", ErrInvalidInput)
+	}
+
+	if _, err := s.repo.GetFlightByID(ctx, flightID); err != nil {
+		return nil, err
+	}
+	seats, err := s.repo.GetFlightSeats(ctx, flightID)
+	if err != nil {
+		return nil, err
+	}
+	return selectSeats(seats, sel)
+}
+
+// selectSeats picks the seats matching a selection, each once
+func selectSeats(seats []database.Seat, sel SeatSelection) ([]uuid.UUID, error) {
+	byRef := make(map[string]uuid.UUID, 2*len(seats))
+	byRow := make(map[int][]uuid.UUID)
+	for _, seat := range seats {
+		byRef[strings.ToUpper(seat.ID.String())] = seat.ID
+		byRef[strings.ToUpper(seat.SeatNumber)] = seat.ID
+		byRow[seat.RowNumber] = append(byRow[seat.RowNumber], seat.ID)
+	}
+
+	var ids []uuid.UUID
+	seen := make(map[uuid.UUID]bool)
+	add := func(id uuid.UUID) {
+		if !seen[id] {
+			seen[id] = true
+			ids = append(ids, id)
+		}
+	}
+	for _, ref := range sel.Seats {
+		id, ok := byRef[strings.ToUpper(strings.TrimSpace(ref))]
+		if !ok {
+			return nil, fmt.Errorf("%w: unknown seat %q", ErrInvalidInput, ref)
+		}
+		add(id)
+	}
+	for _, row := range sel.Rows {
+		if len(byRow[row]) == 0 {
+			return nil, fmt.Errorf("%w: row %d has no seats", ErrInvalidInput, row)
+		}
+		for _, id := range byRow[row] {
+			add(id)
+		}
+	}
+	return ids, nil
+}
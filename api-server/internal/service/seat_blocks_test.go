@@ -0,0 +1,80 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/cx-tal-miterani/flight-booking-system/api-server/internal/database"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateBlockSeatsRequest(t *testing.T) {
+	now := time.Now()
+	past := now.Add(-time.Minute)
+	future := now.Add(time.Hour)
+
+	tests := []struct {
+		name  string
+		req   BlockSeatsRequest
+		valid bool
+	}{
+		{
+			name:  "open-ended",
+			req:   BlockSeatsRequest{Reason: database.SeatBlockMaintenance, BlockedBy: "ops"},
+			valid: true,
+		},
+		{
+			name:  "expiring",
+			req:   BlockSeatsRequest{Reason: database.SeatBlockCrew, BlockedBy: "ops", BlockedUntil: &future},
+			valid: true,
+		},
+		{
+			name: "unknown reason",
+			req:  BlockSeatsRequest{Reason: "vip", BlockedBy: "ops"},
+		},
+		{
+			name: "no actor",
+			req:  BlockSeatsRequest{Reason: database.SeatBlockOperational},
+		},
+		{
+			name: "expiry in the past",
+			req:  BlockSeatsRequest{Reason: database.SeatBlockCrew, BlockedBy: "ops", BlockedUntil: &past},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateBlockSeatsRequest(tt.req, now)
+			if tt.valid {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorIs(t, err, ErrInvalidInput)
+			}
+		})
+	}
+}
+
+func TestSelectSeats(t *testing.T) {
+	seat := func(number string, row int) database.Seat {
+		return database.Seat{ID: uuid.New(), SeatNumber: number, RowNumber: row}
+	}
+	seats := []database.Seat{seat("1A", 1), seat("1B", 1), seat("2A", 2), seat("2B", 2)}
+
+	t.Run("seats and rows", func(t *testing.T) {
+		ids, err := selectSeats(seats, SeatSelection{Seats: []string{"2a", seats[0].ID.String()}, Rows: []int{1}})
+		require.NoError(t, err)
+		assert.Equal(t, []uuid.UUID{seats[2].ID, seats[0].ID, seats[1].ID}, ids)
+	})
+
+	t.Run("unknown seat", func(t *testing.T) {
+		_, err := selectSeats(seats, SeatSelection{Seats: []string{"9Z"}})
+		assert.ErrorIs(t, err, ErrInvalidInput)
+	})
+
+	t.Run("unknown row", func(t *testing.T) {
+		_, err := selectSeats(seats, SeatSelection{Rows: []int{3}})
+		assert.ErrorIs(t, err, ErrInvalidInput)
+	})
+}
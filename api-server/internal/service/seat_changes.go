@@ -0,0 +1,141 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/cx-tal-miterani/flight-booking-system/api-server/internal/database"
+	"github.com/cx-tal-miterani/flight-booking-system/api-server/internal/websocket"
+	"github.com/google/uuid"
+)
+
+// ChangeSeatsRequest adds seats to and removes seats from an order, leaving
+// its other seats as they are. Seats are referenced like in SelectSeats.
+type ChangeSeatsRequest struct {
+	Add    []string `json:"add,omitempty"`
+	Remove []string `json:"remove,omitempty"`
+}
+
+// ChangeSeatsResponse is the order after a seat change with the outcome of
+// every added and removed seat
+type ChangeSeatsResponse struct {
+	*OrderStatusResponse
+	Results []database.SeatChange `json:"results"`
+}
+
+// ChangeSeats adds seats to and removes seats from an order. Every seat
+// succeeds or fails on its own, so a taken seat does not stop the others from
+// being held. Only the seats that were held or released are broadcast.
+func (s *BookingService) ChangeSeats(ctx context.Context, orderID string, req ChangeSeatsRequest) (*ChangeSeatsResponse, error) {
+	oid, err := uuid.Parse(orderID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid order ID: %w", err)
+	}
+	if len(req.Add) == 0 && len(req.Remove) == 0 {
+		return nil, fmt.Errorf("%w: no seats to add or remove", ErrInvalidInput)
+	}
+
+	order, err := s.repo.GetOrderByID(ctx, oid)
+	if err != nil {
+		return nil, err
+	}
+	if len(order.OverbookedSeats) > 0 {
+		return nil, fmt.Errorf("%w: order is overbooked, select its seats instead", ErrInvalidInput)
+	}
+	if len(req.Add) > 0 {
+		if err := s.checkOrderFlightsBookable(ctx, order); err != nil {
+			return nil, err
+		}
+	}
+
+	add, err := s.resolveChangedSeats(ctx, order, req.Add)
+	if err != nil {
+		return nil, err
+	}
+	remove, err := s.resolveChangedSeats(ctx, order, req.Remove)
+	if err != nil {
+		return nil, err
+	}
+	removed := make(map[uuid.UUID]bool, len(remove))
+	for _, id := range remove {
+		removed[id] = true
+	}
+	for _, id := range add {
+		if removed[id] {
+			return nil, fmt.Errorf("%w: seat %s is both added and removed", ErrInvalidInput, id)
+		}
+	}
+
+	changes, err := s.repo.ChangeOrderSeats(ctx, oid, add, remove)
+	if err != nil {
+		return nil, err
+	}
+
+	held := make(map[string][]string)
+	released := make(map[string][]string)
+	var releasedIDs []uuid.UUID
+	for _, c := range changes {
+		switch c.Status {
+		case database.SeatChangeHeld:
+			held[c.FlightID.String()] = append(held[c.FlightID.String()], c.SeatID.String())
+		case database.SeatChangeReleased:
+			released[c.FlightID.String()] = append(released[c.FlightID.String()], c.SeatID.String())
+			releasedIDs = append(releasedIDs, c.SeatID)
+		}
+	}
+
+	status, err := s.GetOrder(ctx, orderID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Tell the workflow about the order's seats, which may now expire at a
+	// different time if the order had none before
+	if (len(held) > 0 || len(released) > 0) && order.WorkflowID != nil && status.Order.ReservationExpiresAt != nil {
+		seatIDs, err := s.repo.GetOrderSeatIDs(ctx, oid)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get order seats: %w", err)
+		}
+		ids := make([]string, len(seatIDs))
+		for i, id := range seatIDs {
+			ids[i] = id.String()
+		}
+		err = s.signalOrderWorkflow(ctx, oid, *order.WorkflowID, "seats-selected", map[string]interface{}{
+			"seatIds":   ids,
+			"expiresAt": *status.Order.ReservationExpiresAt,
+		})
+		if err != nil {
+			// Log but don't fail - order is already updated
+			fmt.Printf("Warning: failed to signal workflow: %v\n", err)
+		}
+	}
+
+	hub := websocket.GetHub()
+	for flightID, ids := range released {
+		hub.BroadcastSeatsReleased(flightID, ids, orderID)
+	}
+	for flightID, ids := range held {
+		hub.BroadcastSeatsHeld(flightID, ids, orderID)
+	}
+	s.notifyWaitlists(ctx, releasedIDs)
+
+	return &ChangeSeatsResponse{OrderStatusResponse: status, Results: changes}, nil
+}
+
+// resolveChangedSeats resolves the seat references of a seat change. Unlike
+// a full selection, an unknown seat number is an error: it would otherwise be
+// missing from the results.
+func (s *BookingService) resolveChangedSeats(ctx context.Context, order *database.Order, refs []string) ([]uuid.UUID, error) {
+	unique := make(map[string]bool, len(refs))
+	for _, ref := range refs {
+		unique[ref] = true
+	}
+	ids, err := s.resolveSeatRefs(ctx, order, refs)
+	if err != nil {
+		return nil, err
+	}
+	if len(ids) < len(unique) {
+		return nil, fmt.Errorf("%w: unknown seat in %v", ErrInvalidInput, refs)
+	}
+	return ids, nil
+}
@@ -0,0 +1,46 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/cx-tal-miterani/flight-booking-system/api-server/internal/database"
+	"github.com/cx-tal-miterani/flight-booking-system/api-server/internal/websocket"
+)
+
+// seatEventRetryInterval is how long to wait before listening for seat events
+// again after the connection failed
+const seatEventRetryInterval = 5 * time.Second
+
+// RelaySeatEvents broadcasts the seat changes published by the worker, such
+// as expired holds being released or seats held for the waitlist, to
+// WebSocket clients. It runs until ctx is done, reconnecting when the
+// database connection fails.
+func (s *BookingService) RelaySeatEvents(ctx context.Context) {
+	hub := websocket.GetHub()
+	for {
+		err := s.repo.ListenSeatEvents(ctx, func(event database.SeatEvent) {
+			switch event.Type {
+			case database.SeatEventSeatsReleased:
+				hub.BroadcastSeatsReleased(event.FlightID, event.SeatIDs, event.OrderID)
+			case database.SeatEventSeatsHeld:
+				hub.BroadcastSeatsHeld(event.FlightID, event.SeatIDs, event.OrderID)
+			case database.SeatEventSeatsBooked:
+				hub.BroadcastSeatsBooked(event.FlightID, event.SeatIDs, event.OrderID)
+			default:
+				fmt.Printf("Warning: unknown seat event type %q\n", event.Type)
+			}
+		})
+		if ctx.Err() != nil {
+			return
+		}
+		fmt.Printf("Warning: seat event listener stopped, retrying in %s: %v\n", seatEventRetryInterval, err)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(seatEventRetryInterval):
+		}
+	}
+}
@@ -0,0 +1,181 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/cx-tal-miterani/flight-booking-system/api-server/internal/database"
+	"github.com/cx-tal-miterani/flight-booking-system/api-server/internal/websocket"
+	"github.com/google/uuid"
+	"go.temporal.io/sdk/client"
+)
+
+// seatExchangeHold is how long the new seats of a seat change are held while
+// the fare difference is settled
+const seatExchangeHold = 15 * time.Minute
+
+// SeatSwap replaces a booked seat of an order with another seat on the same
+// flight
+type SeatSwap struct {
+	FromSeatID string `json:"fromSeatId"`
+	ToSeatID   string `json:"toSeatId"`
+}
+
+// SeatExchangeRequest changes seats of a confirmed order. PaymentCode pays
+// the difference when the new seats cost more than the old ones.
+type SeatExchangeRequest struct {
+	Swaps       []SeatSwap `json:"swaps"`
+	PaymentCode string     `json:"paymentCode,omitempty"`
+}
+
+// ExchangeSeats starts a seat change of a confirmed order. The new seats are
+// held for the order right away and a SeatExchangeWorkflow charges or
+// credits the fare difference, then swaps the seats. The exchange is
+// returned pending; the order lists it with its outcome.
+func (s *BookingService) ExchangeSeats(ctx context.Context, orderID string, req SeatExchangeRequest) (*database.SeatExchange, error) {
+	oid, err := uuid.Parse(orderID)
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid order ID", ErrInvalidInput)
+	}
+	from, to, err := parseSeatSwaps(req.Swaps)
+	if err != nil {
+		return nil, err
+	}
+
+	order, err := s.repo.GetOrderByID(ctx, oid)
+	if err != nil {
+		return nil, err
+	}
+	if order.Status != database.OrderStatusConfirmed {
+		return nil, fmt.Errorf("%w: only confirmed orders can change seats", database.ErrOrderNotModifiable)
+	}
+
+	booked, err := s.repo.GetOrderSeats(ctx, oid)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get order seats: %w", err)
+	}
+	newSeats, err := s.repo.GetSeatsByIDs(ctx, to)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get seats: %w", err)
+	}
+	flightIDs, err := validateSeatSwaps(from, to, booked, newSeats)
+	if err != nil {
+		return nil, err
+	}
+	for _, id := range flightIDs {
+		flight, err := s.repo.GetFlightByID(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		if !flight.Status.IsBookable() {
+			return nil, fmt.Errorf("%w: flight %s is %s", ErrInvalidInput, flight.FlightNumber, flight.Status)
+		}
+	}
+
+	id := uuid.New()
+	exchange, err := s.repo.CreateSeatExchange(ctx, &database.SeatExchange{
+		ID:            id,
+		OrderID:       oid,
+		OldSeatIDs:    from,
+		NewSeatIDs:    to,
+		WorkflowID:    fmt.Sprintf("seat-exchange-%s", id),
+		HoldExpiresAt: time.Now().Add(seatExchangeHold),
+	}, req.PaymentCode != "")
+	if err != nil {
+		return nil, err
+	}
+
+	workflowOptions := client.StartWorkflowOptions{
+		ID:        exchange.WorkflowID,
+		TaskQueue: "flight-booking-queue",
+	}
+	workflowInput := map[string]interface{}{
+		"exchangeId":      exchange.ID.String(),
+		"orderId":         orderID,
+		"priceDifference": exchange.PriceDifference,
+		"paymentCode":     req.PaymentCode,
+	}
+	if _, err := s.temporalClient.ExecuteWorkflow(ctx, workflowOptions, "SeatExchangeWorkflow", workflowInput); err != nil {
+		if ferr := s.repo.FailSeatExchange(ctx, exchange.ID, "seat change could not be started"); ferr != nil {
+			fmt.Printf("Warning: failed to release seats of exchange %s: %v\n", exchange.ID, ferr)
+		}
+		return nil, fmt.Errorf("failed to start seat exchange workflow: %w", err)
+	}
+
+	hub := websocket.GetHub()
+	for flightID, ids := range seatIDsByFlight(newSeats) {
+		hub.BroadcastSeatsHeld(flightID, ids, orderID)
+	}
+
+	return exchange, nil
+}
+
+// parseSeatSwaps returns the old and new seat IDs of a seat change, paired
+// by position. Every seat may appear once.
+func parseSeatSwaps(swaps []SeatSwap) (from, to []uuid.UUID, err error) {
+	if len(swaps) == 0 {
+		return nil, nil, fmt.Errorf("%w: no seats to change", ErrInvalidInput)
+	}
+
+	seen := make(map[uuid.UUID]bool, 2*len(swaps))
+	parse := func(ref string) (uuid.UUID, error) {
+		id, err := uuid.Parse(ref)
+		if err != nil {
+			return uuid.Nil, fmt.Errorf("%w: invalid seat ID %q", ErrInvalidInput, ref)
+		}
+		if seen[id] {
+			return uuid.Nil, fmt.Errorf("%w: seat %s is listed twice", ErrInvalidInput, id)
+		}
+		seen[id] = true
+		return id, nil
+	}
+	for _, swap := range swaps {
+		fromID, err := parse(swap.FromSeatID)
+		if err != nil {
+			return nil, nil, err
+		}
+		toID, err := parse(swap.ToSeatID)
+		if err != nil {
+			return nil, nil, err
+		}
+		from = append(from, fromID)
+		to = append(to, toID)
+	}
+	return from, to, nil
+}
+
+// validateSeatSwaps checks that every old seat is one of the order's booked
+// seats and that the seat replacing it is on the same flight. It returns the
+// flights whose seats change.
+func validateSeatSwaps(from, to []uuid.UUID, booked, newSeats []database.Seat) ([]uuid.UUID, error) {
+	bookedByID := make(map[uuid.UUID]database.Seat, len(booked))
+	for _, seat := range booked {
+		bookedByID[seat.ID] = seat
+	}
+	newByID := make(map[uuid.UUID]database.Seat, len(newSeats))
+	for _, seat := range newSeats {
+		newByID[seat.ID] = seat
+	}
+
+	var flightIDs []uuid.UUID
+	flights := make(map[uuid.UUID]bool)
+	for i, fromID := range from {
+		old, ok := bookedByID[fromID]
+		if !ok {
+			return nil, fmt.Errorf("%w: seat %s is not booked by this order", ErrInvalidInput, fromID)
+		}
+		seat, ok := newByID[to[i]]
+		if !ok {
+			return nil, fmt.Errorf("%w: seat %s does not exist", ErrInvalidInput, to[i])
+		}
+		if seat.FlightID != old.FlightID {
+			return nil, fmt.Errorf("%w: seat %s is not on the flight of seat %s", ErrInvalidInput, seat.SeatNumber, old.SeatNumber)
+		}
+		if !flights[old.FlightID] {
+			flights[old.FlightID] = true
+			flightIDs = append(flightIDs, old.FlightID)
+		}
+	}
+	return flightIDs, nil
+}
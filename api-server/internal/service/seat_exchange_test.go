@@ -0,0 +1,67 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/cx-tal-miterani/flight-booking-system/api-server/internal/database"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseSeatSwaps(t *testing.T) {
+	a, b, c := uuid.New(), uuid.New(), uuid.New()
+
+	from, to, err := parseSeatSwaps([]SeatSwap{{FromSeatID: a.String(), ToSeatID: b.String()}})
+	require.NoError(t, err)
+	assert.Equal(t, []uuid.UUID{a}, from)
+	assert.Equal(t, []uuid.UUID{b}, to)
+
+	invalid := map[string][]SeatSwap{
+		"no swaps":        nil,
+		"invalid seat ID": {{FromSeatID: "1A", ToSeatID: b.String()}},
+		"same seat":       {{FromSeatID: a.String(), ToSeatID: a.String()}},
+		"new seat twice":  {{FromSeatID: a.String(), ToSeatID: c.String()}, {FromSeatID: b.String(), ToSeatID: c.String()}},
+		"old seat reused": {{FromSeatID: a.String(), ToSeatID: b.String()}, {FromSeatID: b.String(), ToSeatID: c.String()}},
+	}
+	for name, swaps := range invalid {
+		t.Run(name, func(t *testing.T) {
+			_, _, err := parseSeatSwaps(swaps)
+			assert.ErrorIs(t, err, ErrInvalidInput)
+		})
+	}
+}
+
+func TestValidateSeatSwaps(t *testing.T) {
+	flightID := uuid.New()
+	otherFlight := uuid.New()
+	seat := func(number string, flight uuid.UUID) database.Seat {
+		return database.Seat{ID: uuid.New(), SeatNumber: number, FlightID: flight}
+	}
+	booked := []database.Seat{seat("10A", flightID), seat("3C", otherFlight)}
+	free := seat("2A", flightID)
+	elsewhere := seat("2A", otherFlight)
+	newSeats := []database.Seat{free, elsewhere}
+
+	t.Run("valid", func(t *testing.T) {
+		flights, err := validateSeatSwaps(
+			[]uuid.UUID{booked[0].ID, booked[1].ID}, []uuid.UUID{free.ID, elsewhere.ID}, booked, newSeats)
+		require.NoError(t, err)
+		assert.Equal(t, []uuid.UUID{flightID, otherFlight}, flights)
+	})
+
+	t.Run("seat not booked by the order", func(t *testing.T) {
+		_, err := validateSeatSwaps([]uuid.UUID{uuid.New()}, []uuid.UUID{free.ID}, booked, newSeats)
+		assert.ErrorIs(t, err, ErrInvalidInput)
+	})
+
+	t.Run("unknown new seat", func(t *testing.T) {
+		_, err := validateSeatSwaps([]uuid.UUID{booked[0].ID}, []uuid.UUID{uuid.New()}, booked, newSeats)
+		assert.ErrorIs(t, err, ErrInvalidInput)
+	})
+
+	t.Run("new seat on another flight", func(t *testing.T) {
+		_, err := validateSeatSwaps([]uuid.UUID{booked[0].ID}, []uuid.UUID{elsewhere.ID}, booked, newSeats)
+		assert.ErrorIs(t, err, ErrInvalidInput)
+	})
+}
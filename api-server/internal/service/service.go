@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"io"
 	"sync"
 	"time"
 
@@ -13,34 +14,151 @@ import (
 	"go.temporal.io/sdk/client"
 )
 
+// ErrInvalidInput is returned when a request fails validation
+var ErrInvalidInput = errors.New("invalid input")
+
+// validSeatClasses are the seat classes cabins can be configured with
+var validSeatClasses = map[string]bool{"economy": true, "premium": true, "business": true, "first": true}
+
+// validSeatAttributes are the attributes seats can have
+var validSeatAttributes = func() map[string]bool {
+	m := make(map[string]bool, len(database.SeatAttributes))
+	for _, attr := range database.SeatAttributes {
+		m[attr] = true
+	}
+	return m
+}()
+
 // Service defines the interface for business logic
 type Service interface {
 	// Flights
-	GetFlights(ctx context.Context) ([]database.Flight, error)
+	GetFlights(ctx context.Context, req FlightSearchRequest) (*FlightSearchResponse, error)
 	GetFlight(ctx context.Context, id string) (*database.Flight, error)
-	GetFlightSeats(ctx context.Context, flightID string) ([]database.Seat, error)
+	GetSeatMap(ctx context.Context, flightID string, attributes []string) (*database.SeatMap, error)
+	SearchItineraries(ctx context.Context, req ItinerarySearchRequest) ([]Itinerary, error)
+	GetSeatAttributeSurcharges(ctx context.Context) ([]database.SeatAttributeSurcharge, error)
+
+	// Airports
+	GetAirports(ctx context.Context, query string) ([]database.Airport, error)
+	GetAirport(ctx context.Context, code string) (*database.Airport, error)
+
+	// Customer accounts
+	Register(ctx context.Context, req RegisterRequest) (*Session, error)
+	Login(ctx context.Context, req LoginRequest) (*Session, error)
+	Logout(ctx context.Context, token string) error
+	Authenticate(ctx context.Context, token string) (*database.Customer, error)
+	AuthorizeOrder(ctx context.Context, orderID string, customer *database.Customer) error
+	GetCustomerOrders(ctx context.Context, customerID uuid.UUID, req CustomerOrdersRequest) ([]database.Order, error)
+
+	// Waitlist
+	JoinWaitlist(ctx context.Context, flightID string, req JoinWaitlistRequest) (*database.WaitlistEntry, error)
+	GetWaitlistEntry(ctx context.Context, id string) (*database.WaitlistEntry, error)
+	LeaveWaitlist(ctx context.Context, id string) error
 
 	// Orders
 	CreateOrder(ctx context.Context, req CreateOrderRequest) (*database.Order, error)
 	GetOrder(ctx context.Context, id string) (*OrderStatusResponse, error)
+	GetBooking(ctx context.Context, reference, lastName string) (*Booking, error)
 	SelectSeats(ctx context.Context, orderID string, seatIDs []string) (*OrderStatusResponse, error)
+	AutoAssignSeats(ctx context.Context, orderID string, req AutoAssignRequest) (*OrderStatusResponse, error)
+	OverbookSeats(ctx context.Context, orderID string, req OverbookRequest) (*OrderStatusResponse, error)
+	ChangeSeats(ctx context.Context, orderID string, req ChangeSeatsRequest) (*ChangeSeatsResponse, error)
 	SubmitPayment(ctx context.Context, orderID string, paymentCode string) (*OrderStatusResponse, error)
+	SetPassengers(ctx context.Context, orderID string, req SetPassengersRequest) (*OrderStatusResponse, error)
+	CheckIn(ctx context.Context, orderID string) (*OrderStatusResponse, error)
+	ExchangeSeats(ctx context.Context, orderID string, req SeatExchangeRequest) (*database.SeatExchange, error)
+	ExchangeFlight(ctx context.Context, orderID string, req FlightExchangeRequest) (*database.FlightExchange, error)
 	CancelOrder(ctx context.Context, orderID string) error
+	CancelBooking(ctx context.Context, orderID string, req CancelBookingRequest) (*database.OrderRefund, error)
+	GetRebookingOffer(ctx context.Context, orderID string) (*database.RebookingOffer, error)
+	RespondToRebooking(ctx context.Context, orderID string, req RebookingResponseRequest) (*database.RebookingOffer, error)
+
+	// Admin: flight management
+	CreateFlight(ctx context.Context, req CreateFlightRequest) (*database.Flight, error)
+	UpdateFlight(ctx context.Context, id string, req UpdateFlightRequest) (*database.Flight, error)
+	DeleteFlight(ctx context.Context, id string) error
+	UpdateFlightStatus(ctx context.Context, id string, req UpdateFlightStatusRequest) (*database.Flight, error)
+
+	// Admin: order search
+	SearchOrders(ctx context.Context, req OrderSearchRequest) (*OrderSearchResponse, error)
+	ExportOrders(ctx context.Context, req OrderSearchRequest) ([]database.OrderSummary, error)
+	GetOrderEvents(ctx context.Context, orderID string) ([]database.OrderEvent, error)
+
+	// Admin: overbooking
+	GetOverbookingPolicies(ctx context.Context) ([]database.OverbookingPolicy, error)
+	SaveOverbookingPolicy(ctx context.Context, req OverbookingPolicyRequest) (*database.OverbookingPolicy, error)
+	DeleteOverbookingPolicy(ctx context.Context, id string) error
+	GetDeniedBoardingReport(ctx context.Context, flightID string) (*DeniedBoardingReport, error)
+
+	// Admin: seat blocks
+	GetSeatBlocks(ctx context.Context, flightID string) ([]database.SeatBlock, error)
+	BlockSeats(ctx context.Context, flightID string, req BlockSeatsRequest) ([]database.SeatBlock, error)
+	UnblockSeats(ctx context.Context, flightID string, sel SeatSelection) ([]uuid.UUID, error)
+
+	// Admin: aircraft types and cabin layouts
+	GetAircraftTypes(ctx context.Context) ([]database.AircraftType, error)
+	CreateAircraftType(ctx context.Context, req CreateAircraftTypeRequest) (*database.AircraftType, error)
+	GetCabinLayouts(ctx context.Context) ([]database.CabinLayout, error)
+	CreateCabinLayout(ctx context.Context, req CreateCabinLayoutRequest) (*database.CabinLayout, error)
+	UpdateSeatAttributeSurcharge(ctx context.Context, attribute string, req UpdateSeatAttributeSurchargeRequest) (*database.SeatAttributeSurcharge, error)
+
+	// Admin: recurring flight schedules
+	GetFlightSchedules(ctx context.Context) ([]database.FlightSchedule, error)
+	CreateFlightSchedule(ctx context.Context, req CreateFlightScheduleRequest) (*database.FlightSchedule, error)
+	DeleteFlightSchedule(ctx context.Context, id string) error
+
+	// Admin: schedule import
+	ImportSSIM(ctx context.Context, r io.Reader, req SSIMImportRequest) (*SSIMImportResult, error)
 }
 
-// CreateOrderRequest represents a request to create an order
+// CreateOrderRequest represents a request to create an order. FlightIDs lists
+// the segments of a round trip or multi-city journey in travel order; when it
+// is empty the order covers FlightID only.
 type CreateOrderRequest struct {
-	FlightID      string `json:"flightId"`
-	CustomerName  string `json:"customerName"`
-	CustomerEmail string `json:"customerEmail"`
+	FlightID      string   `json:"flightId"`
+	FlightIDs     []string `json:"flightIds,omitempty"`
+	CustomerName  string   `json:"customerName"`
+	CustomerEmail string   `json:"customerEmail"`
+	// CustomerID is the account the order is placed with, nil for guest
+	// checkout. It is set from the session, never from the request body.
+	CustomerID *uuid.UUID `json:"-"`
 }
 
+// MaxOrderSegments is the maximum number of flights in a single order
+const MaxOrderSegments = 6
+
 // OrderStatusResponse represents the response for order status
 type OrderStatusResponse struct {
 	Order            *database.Order `json:"order"`
 	RemainingSeconds int             `json:"remainingSeconds"`
 }
 
+// FlightSearchRequest represents the filters, sorting and pagination for flight search
+type FlightSearchRequest struct {
+	Origin        string
+	Destination   string
+	DepartureFrom *time.Time
+	DepartureTo   *time.Time
+	MaxPrice      *float64
+	MinSeats      int
+	SeatClass     string
+	Sort          string
+	Order         string
+	Cursor        string
+	Limit         int
+}
+
+// FlightSearchResponse represents a page of flight search results
+type FlightSearchResponse struct {
+	Flights    []database.Flight
+	NextCursor string
+}
+
+const (
+	defaultFlightPageSize = 20
+	maxFlightPageSize     = 100
+)
+
 // BookingService implements the Service interface
 type BookingService struct {
 	repo           *database.Repository
@@ -55,9 +173,85 @@ func NewBookingService(repo *database.Repository, temporalClient client.Client)
 	}
 }
 
-// GetFlights returns all available flights
-func (s *BookingService) GetFlights(ctx context.Context) ([]database.Flight, error) {
-	return s.repo.GetAllFlights(ctx)
+// GetFlights searches available flights
+func (s *BookingService) GetFlights(ctx context.Context, req FlightSearchRequest) (*FlightSearchResponse, error) {
+	origin, err := s.resolveAirport(ctx, req.Origin)
+	if err != nil {
+		return nil, err
+	}
+	destination, err := s.resolveAirport(ctx, req.Destination)
+	if err != nil {
+		return nil, err
+	}
+
+	params := database.FlightSearchParams{
+		Origin:            origin,
+		Destination:       destination,
+		DepartureFrom:     req.DepartureFrom,
+		DepartureTo:       req.DepartureTo,
+		MaxPrice:          req.MaxPrice,
+		MinAvailableSeats: req.MinSeats,
+		SeatClass:         req.SeatClass,
+		Limit:             req.Limit,
+	}
+
+	switch sort := database.FlightSort(req.Sort); sort {
+	case "":
+		params.Sort = database.FlightSortDeparture
+	case database.FlightSortDeparture, database.FlightSortArrival, database.FlightSortPrice, database.FlightSortDuration:
+		params.Sort = sort
+	default:
+		return nil, fmt.Errorf("%w: unknown sort %q", ErrInvalidInput, req.Sort)
+	}
+
+	switch req.Order {
+	case "", "asc":
+	case "desc":
+		params.Descending = true
+	default:
+		return nil, fmt.Errorf("%w: unknown order %q", ErrInvalidInput, req.Order)
+	}
+
+	if req.SeatClass != "" && !validSeatClasses[req.SeatClass] {
+		return nil, fmt.Errorf("%w: unknown seat class %q", ErrInvalidInput, req.SeatClass)
+	}
+
+	if req.DepartureFrom != nil && req.DepartureTo != nil && !req.DepartureTo.After(*req.DepartureFrom) {
+		return nil, fmt.Errorf("%w: departureTo must be after departureFrom", ErrInvalidInput)
+	}
+	if req.MinSeats < 0 {
+		return nil, fmt.Errorf("%w: minSeats must not be negative", ErrInvalidInput)
+	}
+
+	switch {
+	case params.Limit <= 0:
+		params.Limit = defaultFlightPageSize
+	case params.Limit > maxFlightPageSize:
+		params.Limit = maxFlightPageSize
+	}
+
+	if req.Cursor != "" {
+		cursor, err := database.DecodeFlightCursor(req.Cursor)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidInput, err)
+		}
+		// A cursor is only meaningful for the ordering it was issued for
+		if cursor.Sort != params.Sort || cursor.Desc != params.Descending {
+			return nil, fmt.Errorf("%w: cursor does not match sort order", ErrInvalidInput)
+		}
+		params.Cursor = cursor
+	}
+
+	page, err := s.repo.SearchFlights(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &FlightSearchResponse{Flights: page.Flights}
+	if page.NextCursor != nil {
+		resp.NextCursor = page.NextCursor.Encode()
+	}
+	return resp, nil
 }
 
 // GetFlight returns a flight by ID
@@ -69,27 +263,101 @@ func (s *BookingService) GetFlight(ctx context.Context, id string) (*database.Fl
 	return s.repo.GetFlightByID(ctx, flightID)
 }
 
-// GetFlightSeats returns seats for a flight
-func (s *BookingService) GetFlightSeats(ctx context.Context, flightID string) ([]database.Seat, error) {
+// GetSeatMap returns a flight's seats arranged by its cabin layout. When
+// attributes are given only seats with all of them are included; the
+// positions of the others are left empty.
+func (s *BookingService) GetSeatMap(ctx context.Context, flightID string, attributes []string) (*database.SeatMap, error) {
 	id, err := uuid.Parse(flightID)
 	if err != nil {
 		return nil, fmt.Errorf("invalid flight ID: %w", err)
 	}
-	return s.repo.GetFlightSeats(ctx, id)
+	for _, attr := range attributes {
+		if !validSeatAttributes[attr] {
+			return nil, fmt.Errorf("%w: unknown seat attribute %q", ErrInvalidInput, attr)
+		}
+	}
+
+	flight, err := s.repo.GetFlightByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	seats, err := s.repo.GetFlightSeats(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	// Without a layout the seat map is inferred from the seats themselves
+	layout, err := s.repo.GetCabinLayout(ctx, flight.CabinLayout)
+	if err != nil && !errors.Is(err, database.ErrNotFound) {
+		return nil, err
+	}
+	seatMap := database.BuildSeatMap(id, layout, filterSeatsByAttributes(seats, attributes))
+	if layout != nil {
+		seatMap.AircraftType, err = s.repo.GetAircraftType(ctx, layout.AircraftType)
+		if err != nil && !errors.Is(err, database.ErrNotFound) {
+			return nil, err
+		}
+	}
+
+	return seatMap, nil
+}
+
+// filterSeatsByAttributes returns the seats that have all the attributes
+func filterSeatsByAttributes(seats []database.Seat, attributes []string) []database.Seat {
+	if len(attributes) == 0 {
+		return seats
+	}
+	filtered := make([]database.Seat, 0, len(seats))
+	for _, seat := range seats {
+		if seat.HasAttributes(attributes...) {
+			filtered = append(filtered, seat)
+		}
+	}
+	return filtered
 }
 
 // CreateOrder creates a new booking order and starts the Temporal workflow
 func (s *BookingService) CreateOrder(ctx context.Context, req CreateOrderRequest) (*database.Order, error) {
-	flightID, err := uuid.Parse(req.FlightID)
-	if err != nil {
-		return nil, fmt.Errorf("invalid flight ID: %w", err)
+	flightIDStrs := req.FlightIDs
+	if len(flightIDStrs) == 0 {
+		flightIDStrs = []string{req.FlightID}
+	}
+	if len(flightIDStrs) > MaxOrderSegments {
+		return nil, fmt.Errorf("%w: an order can contain at most %d flights", ErrInvalidInput, MaxOrderSegments)
 	}
 
-	// Verify flight exists
-	_, err = s.repo.GetFlightByID(ctx, flightID)
-	if err != nil {
-		return nil, fmt.Errorf("flight not found: %w", err)
+	// Verify flights exist and connect in travel order
+	var segments []database.OrderSegment
+	var flightIDs []string
+	var previous *database.Flight
+	for _, idStr := range flightIDStrs {
+		flightID, err := uuid.Parse(idStr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid flight ID: %w", err)
+		}
+
+		flight, err := s.repo.GetFlightByID(ctx, flightID)
+		if err != nil {
+			return nil, fmt.Errorf("flight not found: %w", err)
+		}
+		if !flight.Status.IsBookable() {
+			return nil, fmt.Errorf("%w: flight %s is %s", ErrInvalidInput, flight.FlightNumber, flight.Status)
+		}
+		if previous != nil {
+			if previous.ID == flight.ID {
+				return nil, fmt.Errorf("%w: flight %s appears more than once", ErrInvalidInput, flight.FlightNumber)
+			}
+			if !flight.DepartureTime.After(previous.ArrivalTime) {
+				return nil, fmt.Errorf("%w: flight %s departs before flight %s arrives",
+					ErrInvalidInput, flight.FlightNumber, previous.FlightNumber)
+			}
+		}
+
+		segments = append(segments, database.OrderSegment{FlightID: flightID})
+		flightIDs = append(flightIDs, flightID.String())
+		previous = flight
 	}
+	flightID := segments[0].FlightID
 
 	// Create order
 	order := &database.Order{
@@ -97,7 +365,9 @@ func (s *BookingService) CreateOrder(ctx context.Context, req CreateOrderRequest
 		FlightID:      flightID,
 		CustomerName:  req.CustomerName,
 		CustomerEmail: req.CustomerEmail,
+		CustomerID:    req.CustomerID,
 		Status:        database.OrderStatusPending,
+		Segments:      segments,
 	}
 
 	// Start Temporal workflow
@@ -109,6 +379,7 @@ func (s *BookingService) CreateOrder(ctx context.Context, req CreateOrderRequest
 	workflowInput := map[string]interface{}{
 		"orderId":       order.ID.String(),
 		"flightId":      flightID.String(),
+		"flightIds":     flightIDs,
 		"customerName":  req.CustomerName,
 		"customerEmail": req.CustomerEmail,
 	}
@@ -156,15 +427,13 @@ func (s *BookingService) GetOrder(ctx context.Context, id string) (*OrderStatusR
 			broadcastedCompletions[orderID] = true
 			broadcastMu.Unlock()
 			
-			// Get seat IDs and broadcast
-			seatUUIDs, err := s.repo.GetOrderSeatIDs(ctx, orderID)
-			if err == nil && len(seatUUIDs) > 0 {
+			// Get seats and broadcast to every flight in the order
+			seats, err := s.repo.GetOrderSeats(ctx, orderID)
+			if err == nil {
 				hub := websocket.GetHub()
-				var seatIDStrs []string
-				for _, sid := range seatUUIDs {
-					seatIDStrs = append(seatIDStrs, sid.String())
+				for flightID, seatIDStrs := range seatIDsByFlight(seats) {
+					hub.BroadcastOrderCompleted(flightID, seatIDStrs, id)
 				}
-				hub.BroadcastOrderCompleted(order.FlightID.String(), seatIDStrs, id)
 			}
 		} else {
 			broadcastMu.Unlock()
@@ -188,35 +457,105 @@ func (s *BookingService) SelectSeats(ctx context.Context, orderID string, seatID
 	if err != nil {
 		return nil, err
 	}
+	if err := checkOrderSeatsSelectable(order); err != nil {
+		return nil, err
+	}
+
+	if err := s.checkOrderFlightsBookable(ctx, order); err != nil {
+		return nil, err
+	}
+
+	seatUUIDs, err := s.resolveSeatRefs(ctx, order, seatIDs)
+	if err != nil {
+		return nil, err
+	}
 
-	// Get previously held seat UUIDs for comparison
-	oldSeatUUIDs, _ := s.repo.GetOrderSeatIDs(ctx, oid)
+	if len(seatUUIDs) == 0 {
+		return nil, errors.New("no valid seats selected")
+	}
 
-	// Parse seat IDs (they come as "flightID-seatNumber" format from frontend)
+	return s.holdOrderSeats(ctx, order, seatUUIDs)
+}
+
+// resolveSeatRefs converts seat references to seat IDs. References are seat
+// UUIDs or "flightID-seatNumber" (as sent by the frontend); unknown seat
+// numbers are skipped.
+func (s *BookingService) resolveSeatRefs(ctx context.Context, order *database.Order, refs []string) ([]uuid.UUID, error) {
 	var seatUUIDs []uuid.UUID
-	var seatNumbers []string
-	
-	for _, sid := range seatIDs {
+	seatNumbers := make(map[uuid.UUID][]string)
+
+	for _, sid := range refs {
 		// Try parsing as UUID first
 		if id, err := uuid.Parse(sid); err == nil {
 			seatUUIDs = append(seatUUIDs, id)
-		} else {
-			// Extract seat number from "flightID-seatNumber" format
-			seatNumbers = append(seatNumbers, extractSeatNumber(sid))
+			continue
 		}
+
+		// Extract flight and seat number from "flightID-seatNumber" format. A
+		// bare seat number refers to the order's first flight.
+		flightID := order.FlightID
+		if len(sid) > 37 && sid[36] == '-' {
+			if id, err := uuid.Parse(sid[:36]); err == nil {
+				flightID = id
+			}
+		}
+		seatNumbers[flightID] = append(seatNumbers[flightID], extractSeatNumber(sid))
 	}
 
 	// If we have seat numbers, convert to UUIDs
-	if len(seatNumbers) > 0 {
-		ids, err := s.repo.GetSeatIDsByFlightAndNumbers(ctx, order.FlightID, seatNumbers)
+	for flightID, numbers := range seatNumbers {
+		ids, err := s.repo.GetSeatIDsByFlightAndNumbers(ctx, flightID, numbers)
 		if err != nil {
 			return nil, fmt.Errorf("failed to get seat IDs: %w", err)
 		}
 		seatUUIDs = append(seatUUIDs, ids...)
 	}
 
-	if len(seatUUIDs) == 0 {
-		return nil, errors.New("no valid seats selected")
+	return seatUUIDs, nil
+}
+
+// checkOrderSeatsSelectable checks that seats can still be selected for an
+// order: it has not been paid for, cancelled or expired. HoldSeats checks
+// again under the order's lock.
+func checkOrderSeatsSelectable(order *database.Order) error {
+	switch order.Status {
+	case database.OrderStatusPending, database.OrderStatusSeatsSelected, database.OrderStatusAwaitingPayment:
+		return nil
+	}
+	return fmt.Errorf("%w: order is %s", database.ErrOrderNotModifiable, order.Status)
+}
+
+// checkOrderFlightsBookable checks that every flight of an order is still
+// being sold, so seats can be selected
+func (s *BookingService) checkOrderFlightsBookable(ctx context.Context, order *database.Order) error {
+	for _, seg := range order.Segments {
+		flight, err := s.repo.GetFlightByID(ctx, seg.FlightID)
+		if err != nil {
+			return err
+		}
+		if !flight.Status.IsBookable() {
+			return fmt.Errorf("%w: flight %s is %s", ErrInvalidInput, flight.FlightNumber, flight.Status)
+		}
+	}
+	return nil
+}
+
+// holdOrderSeats holds seats for an order, replacing the ones it held before,
+// and notifies its workflow and the seat map subscribers
+func (s *BookingService) holdOrderSeats(ctx context.Context, order *database.Order, seatUUIDs []uuid.UUID) (*OrderStatusResponse, error) {
+	oid := order.ID
+	orderID := oid.String()
+
+	// Get previously held seats for comparison
+	oldSeats, _ := s.repo.GetOrderSeats(ctx, oid)
+
+	// Every segment of the order must get the same number of seats
+	newSeats, err := s.repo.GetSeatsByIDs(ctx, seatUUIDs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get seats: %w", err)
+	}
+	if err := validateSegmentSeats(order, newSeats); err != nil {
+		return nil, err
 	}
 
 	// Hold seats (this refreshes the 15-minute timer)
@@ -231,7 +570,11 @@ func (s *BookingService) SelectSeats(ctx context.Context, orderID string, seatID
 
 	// Signal workflow about seat selection
 	if order.WorkflowID != nil {
-		err = s.temporalClient.SignalWorkflow(ctx, *order.WorkflowID, "", "seats-selected", map[string]interface{}{
+		seatIDs := make([]string, len(seatUUIDs))
+		for i, id := range seatUUIDs {
+			seatIDs[i] = id.String()
+		}
+		err = s.signalOrderWorkflow(ctx, oid, *order.WorkflowID, "seats-selected", map[string]interface{}{
 			"seatIds":   seatIDs,
 			"expiresAt": time.Now().Add(15 * time.Minute),
 		})
@@ -243,29 +586,31 @@ func (s *BookingService) SelectSeats(ctx context.Context, orderID string, seatID
 
 	// Broadcast WebSocket updates
 	hub := websocket.GetHub()
-	flightIDStr := order.FlightID.String()
 
 	// Find seats that were released (in old but not in new)
 	newSeatSet := make(map[uuid.UUID]bool)
 	for _, id := range seatUUIDs {
 		newSeatSet[id] = true
 	}
-	var releasedSeats []string
-	for _, oldID := range oldSeatUUIDs {
-		if !newSeatSet[oldID] {
-			releasedSeats = append(releasedSeats, oldID.String())
+	var releasedSeats []database.Seat
+	for _, old := range oldSeats {
+		if !newSeatSet[old.ID] {
+			releasedSeats = append(releasedSeats, old)
 		}
 	}
-	if len(releasedSeats) > 0 {
-		hub.BroadcastSeatsReleased(flightIDStr, releasedSeats, orderID)
+	for flightID, ids := range seatIDsByFlight(releasedSeats) {
+		hub.BroadcastSeatsReleased(flightID, ids, orderID)
 	}
+	releasedIDs := make([]uuid.UUID, len(releasedSeats))
+	for i, seat := range releasedSeats {
+		releasedIDs[i] = seat.ID
+	}
+	s.notifyWaitlists(ctx, releasedIDs)
 
 	// Broadcast newly held seats
-	var heldSeats []string
-	for _, id := range seatUUIDs {
-		heldSeats = append(heldSeats, id.String())
+	for flightID, ids := range seatIDsByFlight(newSeats) {
+		hub.BroadcastSeatsHeld(flightID, ids, orderID)
 	}
-	hub.BroadcastSeatsHeld(flightIDStr, heldSeats, orderID)
 
 	return s.GetOrder(ctx, orderID)
 }
@@ -285,17 +630,35 @@ func (s *BookingService) SubmitPayment(ctx context.Context, orderID string, paym
 	// Check if reservation expired
 	remaining, _ := s.repo.GetOrderRemainingSeconds(ctx, oid)
 	if remaining <= 0 {
+		seatIDs, _ := s.repo.GetOrderSeatIDs(ctx, oid)
 		s.repo.UpdateOrderStatus(ctx, oid, database.OrderStatusExpired)
 		s.repo.ReleaseSeats(ctx, oid)
+		s.notifyWaitlists(ctx, seatIDs)
 		return nil, database.ErrOrderExpired
 	}
 
+	// Seats can be added and removed one at a time, so check that every
+	// flight of the order ended up with the same number of them. Overbooked
+	// orders get their seats at check-in.
+	seats, err := s.repo.GetOrderSeats(ctx, oid)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get order seats: %w", err)
+	}
+	if len(order.OverbookedSeats) == 0 {
+		if err := validateSegmentSeats(order, seats); err != nil {
+			return nil, err
+		}
+	}
+	if err := validateOrderPassengers(order, seats); err != nil {
+		return nil, err
+	}
+
 	// Update status to processing
 	s.repo.UpdateOrderStatus(ctx, oid, database.OrderStatusProcessing)
 
 	// Signal workflow to process payment
 	if order.WorkflowID != nil {
-		err = s.temporalClient.SignalWorkflow(ctx, *order.WorkflowID, "", "payment-submitted", map[string]interface{}{
+		err = s.signalOrderWorkflow(ctx, oid, *order.WorkflowID, "payment-submitted", map[string]interface{}{
 			"paymentCode": paymentCode,
 		})
 		if err != nil {
@@ -306,7 +669,8 @@ func (s *BookingService) SubmitPayment(ctx context.Context, orderID string, paym
 	return s.GetOrder(ctx, orderID)
 }
 
-// CancelOrder cancels an order
+// CancelOrder cancels an order before it is paid. Confirmed orders are
+// cancelled with CancelBooking, which refunds them.
 func (s *BookingService) CancelOrder(ctx context.Context, orderID string) error {
 	oid, err := uuid.Parse(orderID)
 	if err != nil {
@@ -317,9 +681,12 @@ func (s *BookingService) CancelOrder(ctx context.Context, orderID string) error
 	if err != nil {
 		return err
 	}
+	if order.Status == database.OrderStatusConfirmed {
+		return fmt.Errorf("%w: confirmed orders are cancelled through the cancellation endpoint", database.ErrOrderNotModifiable)
+	}
 
-	// Get seat UUIDs before releasing
-	seatUUIDs, _ := s.repo.GetOrderSeatIDs(ctx, oid)
+	// Get seats before releasing
+	seats, _ := s.repo.GetOrderSeats(ctx, oid)
 
 	// Release seats
 	s.repo.ReleaseSeats(ctx, oid)
@@ -333,18 +700,53 @@ func (s *BookingService) CancelOrder(ctx context.Context, orderID string) error
 	}
 
 	// Broadcast WebSocket update for released seats
-	if len(seatUUIDs) > 0 {
-		hub := websocket.GetHub()
-		var seatIDStrs []string
-		for _, id := range seatUUIDs {
-			seatIDStrs = append(seatIDStrs, id.String())
+	hub := websocket.GetHub()
+	for flightID, seatIDStrs := range seatIDsByFlight(seats) {
+		hub.BroadcastOrderExpired(flightID, seatIDStrs, orderID)
+	}
+
+	seatIDs := make([]uuid.UUID, len(seats))
+	for i, seat := range seats {
+		seatIDs[i] = seat.ID
+	}
+	s.notifyWaitlists(ctx, seatIDs)
+
+	return nil
+}
+
+// validateSegmentSeats checks that the selected seats belong to the order's
+// flights and that every segment gets the same number of seats
+func validateSegmentSeats(order *database.Order, seats []database.Seat) error {
+	counts := map[uuid.UUID]int{order.FlightID: 0}
+	for _, seg := range order.Segments {
+		counts[seg.FlightID] = 0
+	}
+	for _, seat := range seats {
+		if _, ok := counts[seat.FlightID]; !ok {
+			return fmt.Errorf("%w: seat %s is not on a flight in this order", ErrInvalidInput, seat.SeatNumber)
 		}
-		hub.BroadcastOrderExpired(order.FlightID.String(), seatIDStrs, orderID)
+		counts[seat.FlightID]++
 	}
 
+	want := counts[order.FlightID]
+	for _, n := range counts {
+		if n == 0 || n != want {
+			return fmt.Errorf("%w: every flight in the order needs the same number of seats", ErrInvalidInput)
+		}
+	}
 	return nil
 }
 
+// seatIDsByFlight groups seat IDs by the flight they belong to
+func seatIDsByFlight(seats []database.Seat) map[string][]string {
+	grouped := make(map[string][]string)
+	for _, seat := range seats {
+		flightID := seat.FlightID.String()
+		grouped[flightID] = append(grouped[flightID], seat.ID.String())
+	}
+	return grouped
+}
+
 // extractSeatNumber extracts seat number from "flightID-seatNumber" format
 func extractSeatNumber(seatID string) string {
 	// Handle format like "550e8400-e29b-41d4-a716-446655440001-1A"
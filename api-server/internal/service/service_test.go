@@ -0,0 +1,112 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/cx-tal-miterani/flight-booking-system/api-server/internal/database"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateSegmentSeats(t *testing.T) {
+	outbound, inbound := uuid.New(), uuid.New()
+	order := &database.Order{
+		FlightID: outbound,
+		Segments: []database.OrderSegment{
+			{FlightID: outbound, SegmentIndex: 0},
+			{FlightID: inbound, SegmentIndex: 1},
+		},
+	}
+	seat := func(flightID uuid.UUID, number string) database.Seat {
+		return database.Seat{ID: uuid.New(), FlightID: flightID, SeatNumber: number}
+	}
+
+	tests := []struct {
+		name    string
+		seats   []database.Seat
+		wantErr bool
+	}{
+		{
+			name:  "same party on both segments",
+			seats: []database.Seat{seat(outbound, "1A"), seat(outbound, "1B"), seat(inbound, "3C"), seat(inbound, "3D")},
+		},
+		{
+			name:    "missing return segment",
+			seats:   []database.Seat{seat(outbound, "1A")},
+			wantErr: true,
+		},
+		{
+			name:    "uneven seat counts",
+			seats:   []database.Seat{seat(outbound, "1A"), seat(outbound, "1B"), seat(inbound, "3C")},
+			wantErr: true,
+		},
+		{
+			name:    "seat on another flight",
+			seats:   []database.Seat{seat(outbound, "1A"), seat(inbound, "3C"), seat(uuid.New(), "5F")},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateSegmentSeats(order, tt.seats)
+			if tt.wantErr {
+				assert.ErrorIs(t, err, ErrInvalidInput)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestCheckOrderSeatsSelectable(t *testing.T) {
+	selectable := map[database.OrderStatus]bool{
+		database.OrderStatusPending:         true,
+		database.OrderStatusSeatsSelected:   true,
+		database.OrderStatusAwaitingPayment: true,
+		database.OrderStatusProcessing:      false,
+		database.OrderStatusConfirmed:       false,
+		database.OrderStatusFailed:          false,
+		database.OrderStatusCancelled:       false,
+		database.OrderStatusExpired:         false,
+	}
+
+	for status, ok := range selectable {
+		err := checkOrderSeatsSelectable(&database.Order{Status: status})
+		if ok {
+			assert.NoError(t, err, status)
+		} else {
+			assert.ErrorIs(t, err, database.ErrOrderNotModifiable, status)
+		}
+	}
+}
+
+func TestIsAirportCode(t *testing.T) {
+	assert.True(t, isAirportCode("JFK"))
+	assert.True(t, isAirportCode("kjfk"))
+	assert.False(t, isAirportCode(""))
+	assert.False(t, isAirportCode("JF"))
+	assert.False(t, isAirportCode("J1K"))
+	assert.False(t, isAirportCode("New York (JFK)"))
+}
+
+func TestFilterSeatsByAttributes(t *testing.T) {
+	seats := []database.Seat{
+		{SeatNumber: "12A", Attributes: []string{"window", "exit_row"}},
+		{SeatNumber: "12C", Attributes: []string{"aisle", "exit_row"}},
+		{SeatNumber: "14A", Attributes: []string{"window"}},
+	}
+
+	numbers := func(seats []database.Seat) []string {
+		var n []string
+		for _, s := range seats {
+			n = append(n, s.SeatNumber)
+		}
+		return n
+	}
+
+	assert.Equal(t, []string{"12A", "12C", "14A"}, numbers(filterSeatsByAttributes(seats, nil)))
+	assert.Equal(t, []string{"12A", "12C"}, numbers(filterSeatsByAttributes(seats, []string{"exit_row"})))
+	assert.Equal(t, []string{"12A"}, numbers(filterSeatsByAttributes(seats, []string{"window", "exit_row"})))
+	assert.Empty(t, filterSeatsByAttributes(seats, []string{"near_lavatory"}))
+}
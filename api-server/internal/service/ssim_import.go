@@ -0,0 +1,363 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/cx-tal-miterani/flight-booking-system/api-server/internal/database"
+	"github.com/cx-tal-miterani/flight-booking-system/api-server/internal/ssim"
+	"github.com/google/uuid"
+)
+
+const (
+	// ssimImportHorizon bounds how far ahead flights are created from SSIM
+	// records, in particular from open-ended periods of operation
+	ssimImportHorizon = 365 * 24 * time.Hour
+	// ssimPassengerServiceTypes are the SSIM service types of flights
+	// passengers can book: scheduled, shuttle, mixed passenger/cargo and
+	// additional passenger flights
+	ssimPassengerServiceTypes = "JSQGB"
+)
+
+// SSIMImportRequest holds the options of a SSIM schedule import. SSIM files
+// carry no fares, so new flights are priced at PricePerSeat. A dry run only
+// reports the changes the import would make.
+type SSIMImportRequest struct {
+	PricePerSeat float64 `json:"pricePerSeat"`
+	DryRun       bool    `json:"dryRun"`
+}
+
+// SSIMImportResult reports the changes a SSIM import made, or would make in a
+// dry run, and the records it could not import
+type SSIMImportResult struct {
+	DryRun    bool               `json:"dryRun"`
+	Records   int                `json:"records"`
+	Created   []SSIMFlightChange `json:"created"`
+	Updated   []SSIMFlightChange `json:"updated"`
+	Unchanged int                `json:"unchanged"`
+	Errors    []ssim.RecordError `json:"errors"`
+}
+
+// SSIMFlightChange is a flight created or updated from a SSIM record. For
+// updates, FlightID is the existing flight and Changes lists what differs.
+type SSIMFlightChange struct {
+	Line          int               `json:"line"`
+	FlightID      *uuid.UUID        `json:"flightId,omitempty"`
+	FlightNumber  string            `json:"flightNumber"`
+	Origin        string            `json:"origin"`
+	Destination   string            `json:"destination"`
+	DepartureTime time.Time         `json:"departureTime"`
+	ArrivalTime   time.Time         `json:"arrivalTime"`
+	CabinLayout   string            `json:"cabinLayout"`
+	Changes       []SSIMFieldChange `json:"changes,omitempty"`
+}
+
+// SSIMFieldChange is a field of an existing flight a SSIM record changes
+type SSIMFieldChange struct {
+	Field string `json:"field"`
+	From  string `json:"from"`
+	To    string `json:"to"`
+}
+
+// ssimFlight is a dated flight planned from a SSIM record
+type ssimFlight struct {
+	line   int
+	flight database.Flight
+}
+
+// ssimFlightKey identifies a flight by its number and UTC departure date
+type ssimFlightKey struct {
+	number string
+	date   string
+}
+
+func newSSIMFlightKey(f *database.Flight) ssimFlightKey {
+	return ssimFlightKey{number: f.FlightNumber, date: f.DepartureTime.UTC().Format(scheduleDateLayout)}
+}
+
+// ImportSSIM reads the flight leg records of a SSIM file and creates their
+// flights up to a year ahead, with seats from the default cabin layout of
+// each record's aircraft type. Flights that already exist for a flight number
+// and date are updated to match the file. Records that cannot be read or
+// imported are reported in the result and do not stop the other records from
+// being imported; flights that have already departed are ignored.
+func (s *BookingService) ImportSSIM(ctx context.Context, r io.Reader, req SSIMImportRequest) (*SSIMImportResult, error) {
+	if req.PricePerSeat <= 0 {
+		return nil, fmt.Errorf("%w: price per seat must be positive", ErrInvalidInput)
+	}
+
+	legs, recordErrors, err := ssim.Parse(r)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidInput, err)
+	}
+
+	result := &SSIMImportResult{
+		DryRun:  req.DryRun,
+		Records: len(legs) + len(recordErrors),
+		Created: []SSIMFlightChange{},
+		Updated: []SSIMFlightChange{},
+		Errors:  recordErrors,
+	}
+	recordError := func(line int, flightNumber string, err error) {
+		result.Errors = append(result.Errors, ssim.RecordError{
+			Line:         line,
+			FlightNumber: flightNumber,
+			Message:      strings.ReplaceAll(err.Error(), ErrInvalidInput.Error()+": ", ""),
+		})
+	}
+
+	planned, err := s.planSSIMFlights(ctx, legs, req.PricePerSeat, time.Now(), recordError)
+	if err != nil {
+		return nil, err
+	}
+
+	existing, err := s.existingSSIMFlights(ctx, planned)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, p := range planned {
+		f := p.flight
+		current, ok := existing[newSSIMFlightKey(&f)]
+		if !ok {
+			if !req.DryRun {
+				if err := s.createSSIMFlight(ctx, &f); err != nil {
+					if !isSSIMRecordError(err) {
+						return nil, err
+					}
+					recordError(p.line, f.FlightNumber, err)
+					continue
+				}
+			}
+			result.Created = append(result.Created, newSSIMFlightChange(p.line, &f, nil))
+			continue
+		}
+
+		changes, update := diffSSIMFlight(current, &f)
+		if len(changes) == 0 {
+			result.Unchanged++
+			continue
+		}
+		if !req.DryRun {
+			if _, err := s.UpdateFlight(ctx, current.ID.String(), update); err != nil {
+				if !isSSIMRecordError(err) {
+					return nil, err
+				}
+				recordError(p.line, f.FlightNumber, err)
+				continue
+			}
+		}
+		change := newSSIMFlightChange(p.line, &f, changes)
+		change.FlightID = &current.ID
+		result.Updated = append(result.Updated, change)
+	}
+
+	return result, nil
+}
+
+// planSSIMFlights expands flight leg records into the flights they operate
+// after now. Records that cannot be imported are passed to recordError.
+func (s *BookingService) planSSIMFlights(ctx context.Context, legs []ssim.Leg, price float64, now time.Time, recordError func(int, string, error)) ([]ssimFlight, error) {
+	var (
+		planned  []ssimFlight
+		airports = map[string]*database.Airport{}
+		layouts  = map[string]*database.CabinLayout{}
+		lines    = map[ssimFlightKey]int{}
+		until    = now.Add(ssimImportHorizon)
+	)
+
+	for _, leg := range legs {
+		origin, destination, layout, err := s.resolveSSIMLeg(ctx, &leg, airports, layouts)
+		if err != nil {
+			if !errors.Is(err, ErrInvalidInput) {
+				return nil, err
+			}
+			recordError(leg.Line, leg.FlightNumber, err)
+			continue
+		}
+
+		var flights []ssimFlight
+		for _, occurrence := range leg.Flights(until) {
+			if !occurrence.Departure.After(now) {
+				continue
+			}
+			f := database.Flight{
+				FlightNumber:  leg.FlightNumber,
+				Origin:        origin,
+				Destination:   destination,
+				DepartureTime: occurrence.Departure,
+				ArrivalTime:   occurrence.Arrival,
+				PricePerSeat:  price,
+				CabinLayout:   layout,
+			}
+			if err = validateFlightDetails(&f, now); err != nil {
+				err = fmt.Errorf("flight on %s: %w", occurrence.Date.Format(scheduleDateLayout), err)
+				break
+			}
+			key := newSSIMFlightKey(&f)
+			if line, ok := lines[key]; ok {
+				err = fmt.Errorf("flight on %s is already defined on line %d", key.date, line)
+				break
+			}
+			flights = append(flights, ssimFlight{line: leg.Line, flight: f})
+		}
+		if err != nil {
+			recordError(leg.Line, leg.FlightNumber, err)
+			continue
+		}
+
+		for _, f := range flights {
+			lines[newSSIMFlightKey(&f.flight)] = leg.Line
+		}
+		planned = append(planned, flights...)
+	}
+
+	return planned, nil
+}
+
+// resolveSSIMLeg validates a flight leg record against the reference data
+// and returns its airports and the cabin layout of its aircraft type. Lookups
+// are cached across the records of a file.
+func (s *BookingService) resolveSSIMLeg(ctx context.Context, leg *ssim.Leg, airports map[string]*database.Airport, layouts map[string]*database.CabinLayout) (string, string, string, error) {
+	if !flightNumberPattern.MatchString(leg.FlightNumber) {
+		return "", "", "", fmt.Errorf("%w: invalid flight number %q", ErrInvalidInput, leg.FlightNumber)
+	}
+	if !strings.Contains(ssimPassengerServiceTypes, leg.ServiceType) || leg.ServiceType == "" {
+		return "", "", "", fmt.Errorf("%w: service type %q is not a passenger service", ErrInvalidInput, leg.ServiceType)
+	}
+
+	for _, code := range []string{leg.DepartureStation, leg.ArrivalStation} {
+		if _, ok := airports[code]; ok {
+			continue
+		}
+		airport, err := s.lookupAirport(ctx, code)
+		if err != nil {
+			return "", "", "", err
+		}
+		airports[code] = airport
+	}
+	if leg.DepartureStation == leg.ArrivalStation {
+		return "", "", "", fmt.Errorf("%w: origin and destination must differ", ErrInvalidInput)
+	}
+
+	layout, ok := layouts[leg.AircraftType]
+	if !ok {
+		aircraft, err := s.repo.GetAircraftTypeByIATACode(ctx, leg.AircraftType)
+		if errors.Is(err, database.ErrNotFound) {
+			return "", "", "", fmt.Errorf("%w: unknown IATA aircraft type %q", ErrInvalidInput, leg.AircraftType)
+		}
+		if err != nil {
+			return "", "", "", err
+		}
+		if layout, err = s.aircraftCabinLayout(ctx, aircraft.Code); err != nil {
+			return "", "", "", err
+		}
+		layouts[leg.AircraftType] = layout
+	}
+
+	return airports[leg.DepartureStation].IATACode, airports[leg.ArrivalStation].IATACode, layout.Name, nil
+}
+
+// existingSSIMFlights loads the flights that already operate the planned
+// flight numbers on the planned dates
+func (s *BookingService) existingSSIMFlights(ctx context.Context, planned []ssimFlight) (map[ssimFlightKey]*database.Flight, error) {
+	existing := map[ssimFlightKey]*database.Flight{}
+	if len(planned) == 0 {
+		return existing, nil
+	}
+
+	numbers := make([]string, 0, len(planned))
+	dates := make([]time.Time, 0, len(planned))
+	for _, p := range planned {
+		numbers = append(numbers, p.flight.FlightNumber)
+		dates = append(dates, p.flight.DepartureTime.UTC().Truncate(24*time.Hour))
+	}
+
+	flights, err := s.repo.GetFlightsByNumberAndDate(ctx, numbers, dates)
+	if err != nil {
+		return nil, err
+	}
+	for i := range flights {
+		existing[newSSIMFlightKey(&flights[i])] = &flights[i]
+	}
+	return existing, nil
+}
+
+// createSSIMFlight creates a flight planned from a SSIM record with the
+// seats of its cabin layout
+func (s *BookingService) createSSIMFlight(ctx context.Context, f *database.Flight) error {
+	layout, err := s.cabinLayout(ctx, f.CabinLayout)
+	if err != nil {
+		return err
+	}
+	return s.repo.CreateFlight(ctx, f, layout.GenerateSeats(f.PricePerSeat))
+}
+
+// diffSSIMFlight compares an existing flight with the flight a SSIM record
+// defines for the same number and date, returning the differences and the
+// update that applies them. Fares are managed separately and not compared.
+func diffSSIMFlight(current, f *database.Flight) ([]SSIMFieldChange, UpdateFlightRequest) {
+	var (
+		changes []SSIMFieldChange
+		update  UpdateFlightRequest
+	)
+	if current.Origin != f.Origin {
+		changes = append(changes, SSIMFieldChange{Field: "origin", From: current.Origin, To: f.Origin})
+		update.Origin = &f.Origin
+	}
+	if current.Destination != f.Destination {
+		changes = append(changes, SSIMFieldChange{Field: "destination", From: current.Destination, To: f.Destination})
+		update.Destination = &f.Destination
+	}
+	if !current.DepartureTime.Equal(f.DepartureTime) {
+		changes = append(changes, SSIMFieldChange{
+			Field: "departureTime",
+			From:  current.DepartureTime.UTC().Format(time.RFC3339),
+			To:    f.DepartureTime.UTC().Format(time.RFC3339),
+		})
+		update.DepartureTime = &f.DepartureTime
+	}
+	if !current.ArrivalTime.Equal(f.ArrivalTime) {
+		changes = append(changes, SSIMFieldChange{
+			Field: "arrivalTime",
+			From:  current.ArrivalTime.UTC().Format(time.RFC3339),
+			To:    f.ArrivalTime.UTC().Format(time.RFC3339),
+		})
+		update.ArrivalTime = &f.ArrivalTime
+	}
+	if current.CabinLayout != f.CabinLayout {
+		changes = append(changes, SSIMFieldChange{Field: "cabinLayout", From: current.CabinLayout, To: f.CabinLayout})
+		update.CabinLayout = &f.CabinLayout
+	}
+	return changes, update
+}
+
+func newSSIMFlightChange(line int, f *database.Flight, changes []SSIMFieldChange) SSIMFlightChange {
+	change := SSIMFlightChange{
+		Line:          line,
+		FlightNumber:  f.FlightNumber,
+		Origin:        f.Origin,
+		Destination:   f.Destination,
+		DepartureTime: f.DepartureTime,
+		ArrivalTime:   f.ArrivalTime,
+		CabinLayout:   f.CabinLayout,
+		Changes:       changes,
+	}
+	if f.ID != uuid.Nil {
+		change.FlightID = &f.ID
+	}
+	return change
+}
+
+// isSSIMRecordError reports whether an error creating or updating a flight
+// concerns the record rather than the import as a whole
+func isSSIMRecordError(err error) bool {
+	return errors.Is(err, ErrInvalidInput) ||
+		errors.Is(err, database.ErrNotFound) ||
+		errors.Is(err, database.ErrDuplicateFlightNumber) ||
+		errors.Is(err, database.ErrSeatsInUse)
+}
@@ -0,0 +1,69 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/cx-tal-miterani/flight-booking-system/api-server/internal/database"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiffSSIMFlight(t *testing.T) {
+	departure := time.Date(2024, 6, 3, 12, 0, 0, 0, time.UTC)
+	current := &database.Flight{
+		ID:            uuid.New(),
+		FlightNumber:  "AA101",
+		Origin:        "JFK",
+		Destination:   "LAX",
+		DepartureTime: departure.In(time.FixedZone("EDT", -4*3600)),
+		ArrivalTime:   departure.Add(6 * time.Hour),
+		PricePerSeat:  299.99,
+		CabinLayout:   DefaultCabinLayout,
+	}
+
+	t.Run("unchanged", func(t *testing.T) {
+		f := *current
+		f.DepartureTime = departure
+		f.PricePerSeat = 199.99
+
+		changes, update := diffSSIMFlight(current, &f)
+		assert.Empty(t, changes, "times are compared as instants and fares are not compared")
+		assert.Equal(t, UpdateFlightRequest{}, update)
+	})
+
+	t.Run("rescheduled", func(t *testing.T) {
+		f := *current
+		f.DepartureTime = departure.Add(30 * time.Minute)
+		f.ArrivalTime = current.ArrivalTime.Add(30 * time.Minute)
+		f.CabinLayout = "regional"
+
+		changes, update := diffSSIMFlight(current, &f)
+		require.Len(t, changes, 3)
+		assert.Equal(t, SSIMFieldChange{
+			Field: "departureTime",
+			From:  "2024-06-03T12:00:00Z",
+			To:    "2024-06-03T12:30:00Z",
+		}, changes[0])
+		assert.Equal(t, "arrivalTime", changes[1].Field)
+		assert.Equal(t, SSIMFieldChange{Field: "cabinLayout", From: DefaultCabinLayout, To: "regional"}, changes[2])
+
+		assert.Nil(t, update.Origin)
+		assert.Nil(t, update.PricePerSeat)
+		require.NotNil(t, update.DepartureTime)
+		assert.Equal(t, f.DepartureTime, *update.DepartureTime)
+		require.NotNil(t, update.CabinLayout)
+		assert.Equal(t, "regional", *update.CabinLayout)
+	})
+
+	t.Run("rerouted", func(t *testing.T) {
+		f := *current
+		f.Destination = "SFO"
+
+		changes, update := diffSSIMFlight(current, &f)
+		assert.Equal(t, []SSIMFieldChange{{Field: "destination", From: "LAX", To: "SFO"}}, changes)
+		require.NotNil(t, update.Destination)
+		assert.Equal(t, "SFO", *update.Destination)
+	})
+}
@@ -0,0 +1,159 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/cx-tal-miterani/flight-booking-system/api-server/internal/database"
+	"github.com/google/uuid"
+	"go.temporal.io/sdk/client"
+)
+
+// waitlistSeatsReleasedSignal wakes up a waitlist workflow to offer seats
+// that may have become available
+const waitlistSeatsReleasedSignal = "seats-released"
+
+// WaitlistWorkflowID returns the ID of the workflow serving the waitlist of a
+// class on a flight. There is at most one per waitlist.
+func WaitlistWorkflowID(flightID uuid.UUID, class string) string {
+	return fmt.Sprintf("waitlist-%s-%s", flightID, class)
+}
+
+// JoinWaitlistRequest asks to wait for seats of a class on a sold-out flight
+type JoinWaitlistRequest struct {
+	Class         string `json:"class"`
+	CustomerName  string `json:"customerName"`
+	CustomerEmail string `json:"customerEmail"`
+	PartySize     int    `json:"partySize"`
+}
+
+// JoinWaitlist puts a customer on the waitlist of a class that does not have
+// enough available seats for their party, and makes sure the waitlist's
+// workflow is running to offer them released seats
+func (s *BookingService) JoinWaitlist(ctx context.Context, flightID string, req JoinWaitlistRequest) (*database.WaitlistEntry, error) {
+	fid, err := uuid.Parse(flightID)
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid flight ID", ErrInvalidInput)
+	}
+	if req.PartySize == 0 {
+		req.PartySize = 1
+	}
+	if err := validateJoinWaitlistRequest(req); err != nil {
+		return nil, err
+	}
+
+	flight, err := s.repo.GetFlightByID(ctx, fid)
+	if err != nil {
+		return nil, err
+	}
+	if !flight.Status.IsBookable() {
+		return nil, fmt.Errorf("%w: flight %s is %s", ErrInvalidInput, flight.FlightNumber, flight.Status)
+	}
+
+	seats, err := s.repo.GetFlightSeats(ctx, fid)
+	if err != nil {
+		return nil, err
+	}
+	var inClass, available int
+	for _, seat := range seats {
+		if seat.Class != req.Class {
+			continue
+		}
+		inClass++
+		if seat.Status == database.SeatStatusAvailable {
+			available++
+		}
+	}
+	if inClass < req.PartySize {
+		return nil, fmt.Errorf("%w: flight %s does not have %d %s seats", ErrInvalidInput, flight.FlightNumber, req.PartySize, req.Class)
+	}
+	if available >= req.PartySize {
+		return nil, fmt.Errorf("%w: %d %s seats are available on flight %s", ErrInvalidInput, available, req.Class, flight.FlightNumber)
+	}
+
+	entry := &database.WaitlistEntry{
+		FlightID:      fid,
+		Class:         req.Class,
+		CustomerName:  req.CustomerName,
+		CustomerEmail: req.CustomerEmail,
+		PartySize:     req.PartySize,
+	}
+	if err := s.repo.CreateWaitlistEntry(ctx, entry); err != nil {
+		return nil, err
+	}
+
+	// Seats may have been released before the entry was added, so the
+	// workflow is signalled to look for them even if it is already running
+	workflowOptions := client.StartWorkflowOptions{
+		ID:        WaitlistWorkflowID(fid, req.Class),
+		TaskQueue: "flight-booking-queue",
+	}
+	workflowInput := map[string]interface{}{
+		"flightId": fid.String(),
+		"class":    req.Class,
+	}
+	_, err = s.temporalClient.SignalWithStartWorkflow(ctx, workflowOptions.ID, waitlistSeatsReleasedSignal, nil,
+		workflowOptions, "WaitlistWorkflow", workflowInput)
+	if err != nil {
+		// Nobody would offer the entry seats
+		if cancelErr := s.repo.CancelWaitlistEntry(ctx, entry.ID); cancelErr != nil {
+			fmt.Printf("Warning: failed to cancel waitlist entry %s: %v\n", entry.ID, cancelErr)
+		}
+		return nil, fmt.Errorf("failed to start waitlist workflow: %w", err)
+	}
+
+	return s.repo.GetWaitlistEntry(ctx, entry.ID)
+}
+
+// GetWaitlistEntry returns a waitlist entry with its position in the queue,
+// or the order holding the offered seats
+func (s *BookingService) GetWaitlistEntry(ctx context.Context, id string) (*database.WaitlistEntry, error) {
+	eid, err := uuid.Parse(id)
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid waitlist entry ID", ErrInvalidInput)
+	}
+	return s.repo.GetWaitlistEntry(ctx, eid)
+}
+
+// LeaveWaitlist takes a waiting customer off the waitlist
+func (s *BookingService) LeaveWaitlist(ctx context.Context, id string) error {
+	eid, err := uuid.Parse(id)
+	if err != nil {
+		return fmt.Errorf("%w: invalid waitlist entry ID", ErrInvalidInput)
+	}
+	return s.repo.CancelWaitlistEntry(ctx, eid)
+}
+
+func validateJoinWaitlistRequest(req JoinWaitlistRequest) error {
+	if !validSeatClasses[req.Class] {
+		return fmt.Errorf("%w: invalid seat class %q", ErrInvalidInput, req.Class)
+	}
+	if strings.TrimSpace(req.CustomerName) == "" || !strings.Contains(req.CustomerEmail, "@") {
+		return fmt.Errorf("%w: customer name and email are required", ErrInvalidInput)
+	}
+	if req.PartySize < 1 || req.PartySize > MaxPartySize {
+		return fmt.Errorf("%w: party size must be between 1 and %d", ErrInvalidInput, MaxPartySize)
+	}
+	return nil
+}
+
+// notifyWaitlists wakes up the waitlist workflows of the flights and classes
+// of released seats that customers are waiting for. Failures are only logged:
+// the workflows also look for released seats periodically.
+func (s *BookingService) notifyWaitlists(ctx context.Context, seatIDs []uuid.UUID) {
+	if len(seatIDs) == 0 {
+		return
+	}
+	keys, err := s.repo.GetWaitlistsForSeats(ctx, seatIDs)
+	if err != nil {
+		fmt.Printf("Warning: failed to find waitlists: %v\n", err)
+		return
+	}
+	for _, key := range keys {
+		err := s.temporalClient.SignalWorkflow(ctx, WaitlistWorkflowID(key.FlightID, key.Class), "", waitlistSeatsReleasedSignal, nil)
+		if err != nil {
+			fmt.Printf("Warning: failed to signal waitlist workflow: %v\n", err)
+		}
+	}
+}
@@ -0,0 +1,32 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateJoinWaitlistRequest(t *testing.T) {
+	valid := JoinWaitlistRequest{Class: "economy", CustomerName: "Ada", CustomerEmail: "ada@example.com", PartySize: 2}
+	assert.NoError(t, validateJoinWaitlistRequest(valid))
+
+	tests := map[string]func(r *JoinWaitlistRequest){
+		"unknown class":   func(r *JoinWaitlistRequest) { r.Class = "steerage" },
+		"missing name":    func(r *JoinWaitlistRequest) { r.CustomerName = " " },
+		"invalid email":   func(r *JoinWaitlistRequest) { r.CustomerEmail = "ada" },
+		"party too large": func(r *JoinWaitlistRequest) { r.PartySize = MaxPartySize + 1 },
+	}
+	for name, modify := range tests {
+		t.Run(name, func(t *testing.T) {
+			req := valid
+			modify(&req)
+			assert.ErrorIs(t, validateJoinWaitlistRequest(req), ErrInvalidInput)
+		})
+	}
+}
+
+func TestWaitlistWorkflowID(t *testing.T) {
+	flightID := uuid.MustParse("550e8400-e29b-41d4-a716-446655440001")
+	assert.Equal(t, "waitlist-550e8400-e29b-41d4-a716-446655440001-business", WaitlistWorkflowID(flightID, "business"))
+}
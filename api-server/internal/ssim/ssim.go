@@ -0,0 +1,291 @@
+// Package ssim reads flight legs from schedules in the IATA Standard Schedules
+// Information Manual (SSIM) format.
+//
+// A SSIM file is made of fixed-width 200 byte records. Only flight leg
+// records (type 3) are read; header, carrier, segment and trailer records
+// are skipped. Positions below are 1-based, as in the manual.
+package ssim
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// RecordLength is the length of a SSIM record
+const RecordLength = 200
+
+// minLegRecordLength is the length a flight leg record needs to reach the
+// aircraft type; the rest of the record is optional for import
+const minLegRecordLength = 75
+
+var (
+	airlinePattern = regexp.MustCompile(`^[A-Z0-9]{2}[A-Z]?$`)
+	stationPattern = regexp.MustCompile(`^[A-Z]{3}$`)
+)
+
+// Leg is a flight leg record. The period and days of operation refer to the
+// flight date, the local departure date of the flight's first leg; the date
+// variations move the leg's departure and arrival relative to it.
+type Leg struct {
+	Line                   int
+	Airline                string
+	FlightNumber           string // airline designator, number and suffix, e.g. "AA101"
+	LegSequence            int
+	ServiceType            string
+	PeriodFrom             time.Time
+	PeriodTo               time.Time // zero when the period is open-ended
+	DaysOfOperation        []int     // ISO weekdays, 1 = Monday to 7 = Sunday
+	DepartureStation       string
+	DepartureTime          time.Duration // local time of day
+	DepartureUTCOffset     time.Duration
+	ArrivalStation         string
+	ArrivalTime            time.Duration // local time of day
+	ArrivalUTCOffset       time.Duration
+	AircraftType           string // IATA aircraft type code, e.g. "320"
+	DepartureDateVariation int
+	ArrivalDateVariation   int
+}
+
+// Flight is one dated operation of a leg
+type Flight struct {
+	Date      time.Time // flight date
+	Departure time.Time // UTC
+	Arrival   time.Time // UTC
+}
+
+// Flights returns the leg's flights on the days of operation in its period,
+// up to and including the flight date until
+func (l *Leg) Flights(until time.Time) []Flight {
+	last := time.Date(until.Year(), until.Month(), until.Day(), 0, 0, 0, 0, time.UTC)
+	if !l.PeriodTo.IsZero() && l.PeriodTo.Before(last) {
+		last = l.PeriodTo
+	}
+
+	var days [8]bool
+	for _, d := range l.DaysOfOperation {
+		days[d] = true
+	}
+
+	var flights []Flight
+	for date := l.PeriodFrom; !date.After(last); date = date.AddDate(0, 0, 1) {
+		if !days[isoWeekday(date)] {
+			continue
+		}
+		flights = append(flights, Flight{
+			Date:      date,
+			Departure: date.AddDate(0, 0, l.DepartureDateVariation).Add(l.DepartureTime - l.DepartureUTCOffset),
+			Arrival:   date.AddDate(0, 0, l.ArrivalDateVariation).Add(l.ArrivalTime - l.ArrivalUTCOffset),
+		})
+	}
+	return flights
+}
+
+// RecordError is a flight leg record that could not be read or imported
+type RecordError struct {
+	Line         int    `json:"line"`
+	FlightNumber string `json:"flightNumber,omitempty"`
+	Message      string `json:"message"`
+}
+
+func (e RecordError) Error() string {
+	if e.FlightNumber != "" {
+		return fmt.Sprintf("line %d (%s): %s", e.Line, e.FlightNumber, e.Message)
+	}
+	return fmt.Sprintf("line %d: %s", e.Line, e.Message)
+}
+
+// Parse reads the flight leg records of a SSIM file. Records that are not
+// valid are reported as RecordErrors rather than failing the whole file; an
+// error is only returned if the file cannot be read.
+func Parse(r io.Reader) ([]Leg, []RecordError, error) {
+	var (
+		legs         []Leg
+		recordErrors []RecordError
+	)
+
+	scanner := bufio.NewScanner(r)
+	line := 0
+	for scanner.Scan() {
+		line++
+		record := strings.TrimRight(scanner.Text(), "\r")
+		if record == "" || record[0] != '3' {
+			continue
+		}
+
+		leg, err := parseLeg(record)
+		if err != nil {
+			recordErr := RecordError{Line: line, Message: err.Error()}
+			if leg != nil {
+				recordErr.FlightNumber = leg.FlightNumber
+			}
+			recordErrors = append(recordErrors, recordErr)
+			continue
+		}
+		leg.Line = line
+		legs = append(legs, *leg)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, nil, fmt.Errorf("failed to read SSIM file: %w", err)
+	}
+
+	return legs, recordErrors, nil
+}
+
+// parseLeg parses a flight leg record. Once the flight number is read the
+// partly parsed leg is returned with any error, so errors can name the flight.
+func parseLeg(record string) (*Leg, error) {
+	if len(record) < minLegRecordLength || len(record) > RecordLength {
+		return nil, fmt.Errorf("record is %d characters long, expected %d", len(record), RecordLength)
+	}
+	record += strings.Repeat(" ", RecordLength-len(record))
+	field := func(from, to int) string {
+		return strings.TrimSpace(record[from-1 : to])
+	}
+
+	leg := &Leg{Airline: field(3, 5)}
+	if !airlinePattern.MatchString(leg.Airline) {
+		return nil, fmt.Errorf("invalid airline designator %q", leg.Airline)
+	}
+	number, err := strconv.Atoi(field(6, 9))
+	if err != nil || number <= 0 {
+		return nil, fmt.Errorf("invalid flight number %q", field(6, 9))
+	}
+	leg.FlightNumber = leg.Airline + strconv.Itoa(number) + field(2, 2)
+
+	if leg.LegSequence, err = strconv.Atoi(field(12, 13)); err != nil {
+		return leg, fmt.Errorf("invalid leg sequence number %q", field(12, 13))
+	}
+	leg.ServiceType = field(14, 14)
+
+	if leg.PeriodFrom, err = parseDate(field(15, 21)); err != nil {
+		return leg, fmt.Errorf("invalid period of operation start %q", field(15, 21))
+	}
+	if to := field(22, 28); to != "00XXX00" {
+		if leg.PeriodTo, err = parseDate(to); err != nil {
+			return leg, fmt.Errorf("invalid period of operation end %q", to)
+		}
+		if leg.PeriodTo.Before(leg.PeriodFrom) {
+			return leg, fmt.Errorf("period of operation ends before it starts")
+		}
+	}
+
+	if leg.DaysOfOperation, err = parseDays(record[28:35]); err != nil {
+		return leg, err
+	}
+
+	leg.DepartureStation = field(37, 39)
+	if !stationPattern.MatchString(leg.DepartureStation) {
+		return leg, fmt.Errorf("invalid departure station %q", leg.DepartureStation)
+	}
+	if leg.DepartureTime, err = parseTime(field(40, 43)); err != nil {
+		return leg, fmt.Errorf("invalid departure time %q", field(40, 43))
+	}
+	if leg.DepartureUTCOffset, err = parseUTCOffset(field(48, 52)); err != nil {
+		return leg, fmt.Errorf("invalid departure UTC offset %q", field(48, 52))
+	}
+
+	leg.ArrivalStation = field(55, 57)
+	if !stationPattern.MatchString(leg.ArrivalStation) {
+		return leg, fmt.Errorf("invalid arrival station %q", leg.ArrivalStation)
+	}
+	if leg.ArrivalTime, err = parseTime(field(62, 65)); err != nil {
+		return leg, fmt.Errorf("invalid arrival time %q", field(62, 65))
+	}
+	if leg.ArrivalUTCOffset, err = parseUTCOffset(field(66, 70)); err != nil {
+		return leg, fmt.Errorf("invalid arrival UTC offset %q", field(66, 70))
+	}
+
+	leg.AircraftType = field(73, 75)
+	if leg.AircraftType == "" {
+		return leg, fmt.Errorf("missing aircraft type")
+	}
+
+	if leg.DepartureDateVariation, err = parseDateVariation(record[192]); err != nil {
+		return leg, fmt.Errorf("invalid departure date variation %q", record[192:193])
+	}
+	if leg.ArrivalDateVariation, err = parseDateVariation(record[193]); err != nil {
+		return leg, fmt.Errorf("invalid arrival date variation %q", record[193:194])
+	}
+
+	return leg, nil
+}
+
+// parseDate parses a DDMMMYY date, e.g. "01JUN24"
+func parseDate(s string) (time.Time, error) {
+	return time.Parse("02Jan06", s)
+}
+
+// parseDays parses the days of operation, which have the day's number at
+// its position or a blank, e.g. "1 3 5  "
+func parseDays(s string) ([]int, error) {
+	var days []int
+	for i, c := range s {
+		switch {
+		case c == ' ':
+		case int(c-'0') == i+1:
+			days = append(days, i+1)
+		default:
+			return nil, fmt.Errorf("invalid days of operation %q", s)
+		}
+	}
+	if len(days) == 0 {
+		return nil, fmt.Errorf("no days of operation")
+	}
+	return days, nil
+}
+
+// parseTime parses a HHMM time of day
+func parseTime(s string) (time.Duration, error) {
+	t, err := time.Parse("1504", s)
+	if err != nil {
+		return 0, err
+	}
+	return time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute, nil
+}
+
+// parseUTCOffset parses a local time variation from UTC, e.g. "-0500"
+func parseUTCOffset(s string) (time.Duration, error) {
+	if len(s) != 5 || (s[0] != '+' && s[0] != '-') {
+		return 0, fmt.Errorf("invalid offset")
+	}
+	hours, err := strconv.Atoi(s[1:3])
+	if err != nil || hours > 14 {
+		return 0, fmt.Errorf("invalid offset")
+	}
+	minutes, err := strconv.Atoi(s[3:5])
+	if err != nil || minutes > 59 {
+		return 0, fmt.Errorf("invalid offset")
+	}
+	offset := time.Duration(hours)*time.Hour + time.Duration(minutes)*time.Minute
+	if s[0] == '-' {
+		offset = -offset
+	}
+	return offset, nil
+}
+
+// parseDateVariation parses a date variation: blank or 0 for the same day,
+// 1 or 2 for days later and A for the day before
+func parseDateVariation(c byte) (int, error) {
+	switch c {
+	case ' ', '0':
+		return 0, nil
+	case '1', '2':
+		return int(c - '0'), nil
+	case 'A':
+		return -1, nil
+	}
+	return 0, fmt.Errorf("invalid date variation")
+}
+
+// isoWeekday returns the ISO weekday of a date, 1 = Monday to 7 = Sunday
+func isoWeekday(t time.Time) int {
+	if t.Weekday() == time.Sunday {
+		return 7
+	}
+	return int(t.Weekday())
+}
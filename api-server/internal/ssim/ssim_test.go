@@ -0,0 +1,183 @@
+package ssim
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// legRecord builds a flight leg record, setting fields by their 1-based
+// start position
+func legRecord(fields map[int]string) string {
+	record := []byte(strings.Repeat(" ", RecordLength))
+	for pos, value := range fields {
+		copy(record[pos-1:], value)
+	}
+	return string(record)
+}
+
+// jfkLax is AA101 JFK-LAX on Mondays, Wednesdays and Fridays in June 2024,
+// departing 08:00 EDT and arriving 11:30 PDT
+func jfkLax() map[int]string {
+	return map[int]string{
+		1:   "3",
+		3:   "AA ",
+		6:   " 101",
+		10:  "01",
+		12:  "01",
+		14:  "J",
+		15:  "01JUN24",
+		22:  "30JUN24",
+		29:  "1 3 5  ",
+		37:  "JFK",
+		40:  "0800",
+		44:  "0800",
+		48:  "-0400",
+		55:  "LAX",
+		58:  "1130",
+		62:  "1130",
+		66:  "-0700",
+		73:  "320",
+		193: "00",
+		195: "000002",
+	}
+}
+
+func TestParse(t *testing.T) {
+	file := strings.Join([]string{
+		"1AIRLINE STANDARD SCHEDULE DATA SET",
+		"2UAA  0008S24",
+		legRecord(jfkLax()),
+		"5 AA 000004E000004",
+	}, "\n")
+
+	legs, errs, err := Parse(strings.NewReader(file))
+	require.NoError(t, err)
+	assert.Empty(t, errs)
+	require.Len(t, legs, 1)
+
+	leg := legs[0]
+	assert.Equal(t, 3, leg.Line)
+	assert.Equal(t, "AA", leg.Airline)
+	assert.Equal(t, "AA101", leg.FlightNumber)
+	assert.Equal(t, 1, leg.LegSequence)
+	assert.Equal(t, "J", leg.ServiceType)
+	assert.Equal(t, time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC), leg.PeriodFrom)
+	assert.Equal(t, time.Date(2024, 6, 30, 0, 0, 0, 0, time.UTC), leg.PeriodTo)
+	assert.Equal(t, []int{1, 3, 5}, leg.DaysOfOperation)
+	assert.Equal(t, "JFK", leg.DepartureStation)
+	assert.Equal(t, 8*time.Hour, leg.DepartureTime)
+	assert.Equal(t, -4*time.Hour, leg.DepartureUTCOffset)
+	assert.Equal(t, "LAX", leg.ArrivalStation)
+	assert.Equal(t, 11*time.Hour+30*time.Minute, leg.ArrivalTime)
+	assert.Equal(t, -7*time.Hour, leg.ArrivalUTCOffset)
+	assert.Equal(t, "320", leg.AircraftType)
+}
+
+func TestParse_SuffixAndOpenPeriod(t *testing.T) {
+	fields := jfkLax()
+	fields[2] = "A"
+	fields[22] = "00XXX00"
+
+	legs, errs, err := Parse(strings.NewReader(legRecord(fields)))
+	require.NoError(t, err)
+	assert.Empty(t, errs)
+	require.Len(t, legs, 1)
+	assert.Equal(t, "AA101A", legs[0].FlightNumber)
+	assert.True(t, legs[0].PeriodTo.IsZero())
+}
+
+func TestParse_ShortRecord(t *testing.T) {
+	// Trailing blanks are often trimmed; the record only needs to reach the
+	// aircraft type
+	record := strings.TrimRight(legRecord(jfkLax())[:75], " ")
+
+	legs, errs, err := Parse(strings.NewReader(record + "\r\n"))
+	require.NoError(t, err)
+	assert.Empty(t, errs)
+	assert.Len(t, legs, 1)
+}
+
+func TestParse_RecordErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		fields  map[int]string
+		message string
+	}{
+		{name: "airline", fields: map[int]string{3: "A! "}, message: "invalid airline designator"},
+		{name: "flight number", fields: map[int]string{6: "00A1"}, message: "invalid flight number"},
+		{name: "period start", fields: map[int]string{15: "31JUN24"}, message: "invalid period of operation start"},
+		{name: "period end", fields: map[int]string{22: "XXXXXXX"}, message: "invalid period of operation end"},
+		{name: "period order", fields: map[int]string{22: "01MAY24"}, message: "ends before it starts"},
+		{name: "days out of place", fields: map[int]string{29: "3      "}, message: "invalid days of operation"},
+		{name: "no days", fields: map[int]string{29: "       "}, message: "no days of operation"},
+		{name: "departure station", fields: map[int]string{37: "J1K"}, message: "invalid departure station"},
+		{name: "departure time", fields: map[int]string{40: "2500"}, message: "invalid departure time"},
+		{name: "departure offset", fields: map[int]string{48: "0400 "}, message: "invalid departure UTC offset"},
+		{name: "arrival station", fields: map[int]string{55: "   "}, message: "invalid arrival station"},
+		{name: "arrival time", fields: map[int]string{62: "11:3"}, message: "invalid arrival time"},
+		{name: "arrival offset", fields: map[int]string{66: "-1600"}, message: "invalid arrival UTC offset"},
+		{name: "aircraft type", fields: map[int]string{73: "   "}, message: "missing aircraft type"},
+		{name: "date variation", fields: map[int]string{193: "03"}, message: "invalid arrival date variation"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fields := jfkLax()
+			for pos, value := range tt.fields {
+				fields[pos] = value
+			}
+			file := legRecord(jfkLax()) + "\n" + legRecord(fields)
+
+			legs, errs, err := Parse(strings.NewReader(file))
+			require.NoError(t, err)
+			assert.Len(t, legs, 1, "valid records are still read")
+			require.Len(t, errs, 1)
+			assert.Equal(t, 2, errs[0].Line)
+			assert.Contains(t, errs[0].Message, tt.message)
+		})
+	}
+}
+
+func TestParse_RecordLength(t *testing.T) {
+	_, errs, err := Parse(strings.NewReader("3 AA  101"))
+	require.NoError(t, err)
+	require.Len(t, errs, 1)
+	assert.Contains(t, errs[0].Error(), "line 1: record is 9 characters long")
+}
+
+func TestLeg_Flights(t *testing.T) {
+	legs, _, err := Parse(strings.NewReader(legRecord(jfkLax())))
+	require.NoError(t, err)
+	leg := legs[0]
+
+	flights := leg.Flights(time.Date(2024, 6, 7, 23, 0, 0, 0, time.UTC))
+	require.Len(t, flights, 3)
+
+	// June 3rd 2024 is a Monday
+	assert.Equal(t, time.Date(2024, 6, 3, 0, 0, 0, 0, time.UTC), flights[0].Date)
+	assert.Equal(t, time.Date(2024, 6, 3, 12, 0, 0, 0, time.UTC), flights[0].Departure)
+	assert.Equal(t, time.Date(2024, 6, 3, 18, 30, 0, 0, time.UTC), flights[0].Arrival)
+	assert.Equal(t, time.Date(2024, 6, 5, 0, 0, 0, 0, time.UTC), flights[1].Date)
+	assert.Equal(t, time.Date(2024, 6, 7, 0, 0, 0, 0, time.UTC), flights[2].Date)
+
+	assert.Len(t, leg.Flights(time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)), 12, "bounded by the period")
+}
+
+func TestLeg_Flights_DateVariation(t *testing.T) {
+	// An overnight flight JFK-LHR, departing 19:00 EDT and arriving 07:00
+	// BST the next day
+	fields := jfkLax()
+	fields[40], fields[55], fields[62], fields[66], fields[193] = "1900", "LHR", "0700", "+0100", "01"
+
+	legs, _, err := Parse(strings.NewReader(legRecord(fields)))
+	require.NoError(t, err)
+
+	flights := legs[0].Flights(time.Date(2024, 6, 3, 0, 0, 0, 0, time.UTC))
+	require.Len(t, flights, 1)
+	assert.Equal(t, time.Date(2024, 6, 3, 23, 0, 0, 0, time.UTC), flights[0].Departure)
+	assert.Equal(t, time.Date(2024, 6, 4, 6, 0, 0, 0, time.UTC), flights[0].Arrival)
+}
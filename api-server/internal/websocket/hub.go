@@ -19,6 +19,7 @@ const (
 	MessageTypeOrderCompleted MessageType = "order_completed"
 	MessageTypeOrderExpired   MessageType = "order_expired"
 	MessageTypeSeatsReleased  MessageType = "seats_released"
+	MessageTypeFlightStatus   MessageType = "flight_status"
 )
 
 // Message represents a WebSocket message
@@ -152,6 +153,30 @@ func (h *Hub) BroadcastSeatsHeld(flightID string, seatIDs []string, orderID stri
 	}
 }
 
+// BroadcastSeatsBooked broadcasts that seats have been booked outside of the
+// booking flow, e.g. the new seats of a seat change
+func (h *Hub) BroadcastSeatsBooked(flightID string, seatIDs []string, orderID string) {
+	h.broadcast <- &Message{
+		Type:      MessageTypeSeatsUpdated,
+		FlightID:  flightID,
+		SeatIDs:   seatIDs,
+		OrderID:   orderID,
+		Status:    "booked",
+		Timestamp: time.Now().UnixMilli(),
+	}
+}
+
+// BroadcastSeatsBlocked broadcasts that seats have been taken out of sale
+func (h *Hub) BroadcastSeatsBlocked(flightID string, seatIDs []string) {
+	h.broadcast <- &Message{
+		Type:      MessageTypeSeatsUpdated,
+		FlightID:  flightID,
+		SeatIDs:   seatIDs,
+		Status:    "blocked",
+		Timestamp: time.Now().UnixMilli(),
+	}
+}
+
 // BroadcastSeatsReleased broadcasts that seats have been released
 func (h *Hub) BroadcastSeatsReleased(flightID string, seatIDs []string, orderID string) {
 	h.broadcast <- &Message{
@@ -188,6 +213,16 @@ func (h *Hub) BroadcastOrderExpired(flightID string, seatIDs []string, orderID s
 	}
 }
 
+// BroadcastFlightStatus broadcasts that a flight's operational status changed
+func (h *Hub) BroadcastFlightStatus(flightID string, status string) {
+	h.broadcast <- &Message{
+		Type:      MessageTypeFlightStatus,
+		FlightID:  flightID,
+		Status:    status,
+		Timestamp: time.Now().UnixMilli(),
+	}
+}
+
 // NotifySeatConflict notifies a specific client about a seat conflict
 func (h *Hub) NotifySeatConflict(flightID string, seatIDs []string, orderID string) {
 	h.broadcast <- &Message{
@@ -0,0 +1,20 @@
+-- Multi-flight orders (round trips and multi-city journeys)
+
+-- Each order covers one or more flight segments. orders.flight_id keeps
+-- pointing at the first segment so single-flight clients keep working.
+CREATE TABLE order_segments (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    order_id UUID NOT NULL REFERENCES orders(id) ON DELETE CASCADE,
+    flight_id UUID NOT NULL REFERENCES flights(id),
+    segment_index INTEGER NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE(order_id, segment_index),
+    UNIQUE(order_id, flight_id)
+);
+
+CREATE INDEX idx_order_segments_order ON order_segments(order_id);
+CREATE INDEX idx_order_segments_flight ON order_segments(flight_id);
+
+-- Existing orders become single-segment orders
+INSERT INTO order_segments (order_id, flight_id, segment_index)
+SELECT id, flight_id, 0 FROM orders;
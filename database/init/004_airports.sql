@@ -0,0 +1,63 @@
+-- Airport reference data
+
+CREATE TABLE airports (
+    iata_code CHAR(3) PRIMARY KEY,
+    icao_code CHAR(4) NOT NULL UNIQUE,
+    name VARCHAR(100) NOT NULL,
+    city VARCHAR(100) NOT NULL,
+    country CHAR(2) NOT NULL, -- ISO 3166-1 alpha-2
+    time_zone VARCHAR(64) NOT NULL, -- IANA time zone, e.g. "America/New_York"
+    latitude DECIMAL(9, 6) NOT NULL,
+    longitude DECIMAL(9, 6) NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    CHECK (iata_code ~ '^[A-Z]{3}$'),
+    CHECK (icao_code ~ '^[A-Z]{4}$'),
+    CHECK (latitude BETWEEN -90 AND 90),
+    CHECK (longitude BETWEEN -180 AND 180)
+);
+
+CREATE INDEX idx_airports_city ON airports(city);
+
+CREATE TRIGGER update_airports_updated_at
+    BEFORE UPDATE ON airports
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+
+INSERT INTO airports (iata_code, icao_code, name, city, country, time_zone, latitude, longitude)
+VALUES
+    ('ATL', 'KATL', 'Hartsfield-Jackson Atlanta International Airport', 'Atlanta', 'US', 'America/New_York', 33.640728, -84.427700),
+    ('BOS', 'KBOS', 'Logan International Airport', 'Boston', 'US', 'America/New_York', 42.365613, -71.009560),
+    ('DCA', 'KDCA', 'Ronald Reagan Washington National Airport', 'Washington', 'US', 'America/New_York', 38.851242, -77.040230),
+    ('DEN', 'KDEN', 'Denver International Airport', 'Denver', 'US', 'America/Denver', 39.856096, -104.673738),
+    ('DFW', 'KDFW', 'Dallas/Fort Worth International Airport', 'Dallas', 'US', 'America/Chicago', 32.899809, -97.040335),
+    ('JFK', 'KJFK', 'John F. Kennedy International Airport', 'New York', 'US', 'America/New_York', 40.641311, -73.778139),
+    ('LAS', 'KLAS', 'Harry Reid International Airport', 'Las Vegas', 'US', 'America/Los_Angeles', 36.084000, -115.153739),
+    ('LAX', 'KLAX', 'Los Angeles International Airport', 'Los Angeles', 'US', 'America/Los_Angeles', 33.941589, -118.408530),
+    ('MCO', 'KMCO', 'Orlando International Airport', 'Orlando', 'US', 'America/New_York', 28.431157, -81.308083),
+    ('MIA', 'KMIA', 'Miami International Airport', 'Miami', 'US', 'America/New_York', 25.795865, -80.287046),
+    ('ORD', 'KORD', 'O''Hare International Airport', 'Chicago', 'US', 'America/Chicago', 41.974162, -87.907321),
+    ('PHX', 'KPHX', 'Phoenix Sky Harbor International Airport', 'Phoenix', 'US', 'America/Phoenix', 33.437269, -112.007788),
+    ('SEA', 'KSEA', 'Seattle-Tacoma International Airport', 'Seattle', 'US', 'America/Los_Angeles', 47.450250, -122.308817),
+    ('SFO', 'KSFO', 'San Francisco International Airport', 'San Francisco', 'US', 'America/Los_Angeles', 37.621313, -122.378955),
+    ('CDG', 'LFPG', 'Paris Charles de Gaulle Airport', 'Paris', 'FR', 'Europe/Paris', 49.009691, 2.547925),
+    ('LHR', 'EGLL', 'Heathrow Airport', 'London', 'GB', 'Europe/London', 51.470020, -0.454295),
+    ('TLV', 'LLBG', 'Ben Gurion Airport', 'Tel Aviv', 'IL', 'Asia/Jerusalem', 32.004724, 34.888219),
+    ('YYZ', 'CYYZ', 'Toronto Pearson International Airport', 'Toronto', 'CA', 'America/Toronto', 43.677717, -79.624819);
+
+-- Flights stored the route as free text such as "New York (JFK)"; keep only
+-- the IATA code and reference the airports table from now on
+UPDATE flights
+SET origin = substring(origin FROM '\(([A-Z]{3})\)\s*$')
+WHERE origin ~ '\([A-Z]{3}\)\s*$';
+
+UPDATE flights
+SET destination = substring(destination FROM '\(([A-Z]{3})\)\s*$')
+WHERE destination ~ '\([A-Z]{3}\)\s*$';
+
+ALTER TABLE flights
+    ALTER COLUMN origin TYPE CHAR(3),
+    ALTER COLUMN destination TYPE CHAR(3),
+    ADD CONSTRAINT fk_flights_origin FOREIGN KEY (origin) REFERENCES airports(iata_code),
+    ADD CONSTRAINT fk_flights_destination FOREIGN KEY (destination) REFERENCES airports(iata_code),
+    ADD CONSTRAINT chk_flights_route CHECK (origin <> destination);
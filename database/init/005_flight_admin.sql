@@ -0,0 +1,6 @@
+-- Admin flight management
+
+-- Cabin layout the flight's seat inventory was generated from. Seeded flights
+-- use the standard narrow-body layout created by 002_seed_data.sql.
+ALTER TABLE flights
+    ADD COLUMN cabin_layout VARCHAR(50) NOT NULL DEFAULT 'narrowbody-standard';
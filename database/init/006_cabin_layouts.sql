@@ -0,0 +1,59 @@
+-- Aircraft types and cabin layout templates
+
+CREATE TABLE aircraft_types (
+    code VARCHAR(10) PRIMARY KEY, -- ICAO type designator, e.g. "A320"
+    manufacturer VARCHAR(100) NOT NULL,
+    model VARCHAR(100) NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+-- cabins is an array of cabin definitions:
+--   class            seat class of the cabin
+--   firstRow/lastRow row range of the cabin
+--   columnGroups     seat columns between aisles, e.g. ["ABC", "DEF"]
+--   priceMultiplier  seat price relative to the flight's base fare
+--   skipRows         row numbers that do not exist (e.g. 13)
+--   exitRows         rows next to an emergency exit
+--   missingSeats     positions without a seat (e.g. "41A")
+CREATE TABLE cabin_layouts (
+    name VARCHAR(50) PRIMARY KEY,
+    aircraft_type VARCHAR(10) NOT NULL REFERENCES aircraft_types(code),
+    description TEXT NOT NULL DEFAULT '',
+    cabins JSONB NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    CHECK (jsonb_typeof(cabins) = 'array' AND jsonb_array_length(cabins) > 0)
+);
+
+CREATE INDEX idx_cabin_layouts_aircraft ON cabin_layouts(aircraft_type);
+
+INSERT INTO aircraft_types (code, manufacturer, model)
+VALUES
+    ('A320', 'Airbus', 'A320-200'),
+    ('B789', 'Boeing', '787-9 Dreamliner'),
+    ('E175', 'Embraer', 'E175');
+
+-- The layouts that flights could be created with so far. narrowbody-standard
+-- matches the seats generated by 002_seed_data.sql.
+INSERT INTO cabin_layouts (name, aircraft_type, description, cabins)
+VALUES
+    ('narrowbody-standard', 'A320', 'Narrow-body, 30 rows of 3-3 seating (180 seats)', '[
+        {"class": "business", "firstRow": 1, "lastRow": 5, "columnGroups": ["ABC", "DEF"], "priceMultiplier": 1.5},
+        {"class": "premium", "firstRow": 6, "lastRow": 10, "columnGroups": ["ABC", "DEF"], "priceMultiplier": 1.2},
+        {"class": "economy", "firstRow": 11, "lastRow": 30, "columnGroups": ["ABC", "DEF"], "priceMultiplier": 1,
+         "exitRows": [11, 12]}
+    ]'),
+    ('regional', 'E175', 'Regional jet, 1-2 business and 2-2 economy seating (77 seats)', '[
+        {"class": "business", "firstRow": 1, "lastRow": 3, "columnGroups": ["A", "CD"], "priceMultiplier": 1.5},
+        {"class": "economy", "firstRow": 4, "lastRow": 20, "columnGroups": ["AB", "CD"], "priceMultiplier": 1,
+         "exitRows": [10]}
+    ]'),
+    ('widebody', 'B789', 'Wide-body, 1-2-1 first to 3-3-3 economy, no row 13 (326 seats)', '[
+        {"class": "first", "firstRow": 1, "lastRow": 2, "columnGroups": ["A", "EF", "K"], "priceMultiplier": 3},
+        {"class": "business", "firstRow": 3, "lastRow": 8, "columnGroups": ["AC", "DG", "HK"], "priceMultiplier": 2},
+        {"class": "premium", "firstRow": 9, "lastRow": 12, "columnGroups": ["AC", "DEFG", "HK"], "priceMultiplier": 1.3},
+        {"class": "economy", "firstRow": 13, "lastRow": 41, "columnGroups": ["ABC", "DEF", "HJK"], "priceMultiplier": 1,
+         "skipRows": [13], "exitRows": [14, 28], "missingSeats": ["41A", "41K"]}
+    ]');
+
+ALTER TABLE flights
+    ADD CONSTRAINT fk_flights_cabin_layout FOREIGN KEY (cabin_layout) REFERENCES cabin_layouts(name);
@@ -0,0 +1,56 @@
+-- Operational flight status and disruption handling
+
+CREATE TYPE flight_status AS ENUM (
+    'scheduled',
+    'delayed',
+    'cancelled',
+    'boarding',
+    'departed',
+    'arrived'
+);
+
+-- Only scheduled and delayed flights are sold. Delayed flights carry their
+-- estimated times; the scheduled times are kept unchanged.
+ALTER TABLE flights
+    ADD COLUMN status flight_status NOT NULL DEFAULT 'scheduled',
+    ADD COLUMN status_reason TEXT,
+    ADD COLUMN estimated_departure_time TIMESTAMP WITH TIME ZONE,
+    ADD COLUMN estimated_arrival_time TIMESTAMP WITH TIME ZONE;
+
+CREATE INDEX idx_flights_status ON flights(status);
+
+-- Confirmed orders on a cancelled flight that were refunded
+ALTER TYPE order_status ADD VALUE 'refunded';
+
+CREATE TYPE rebooking_offer_status AS ENUM (
+    'pending',
+    'accepted',
+    'declined',
+    'expired',
+    'no_alternatives'
+);
+
+-- Offers made to confirmed orders of a cancelled flight by the disruption
+-- workflow. alternative_flight_ids are the flights the order can be moved to.
+CREATE TABLE rebooking_offers (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    order_id UUID NOT NULL REFERENCES orders(id) ON DELETE CASCADE,
+    flight_id UUID NOT NULL REFERENCES flights(id),
+    workflow_id VARCHAR(255) NOT NULL,
+    alternative_flight_ids UUID[] NOT NULL DEFAULT '{}',
+    status rebooking_offer_status NOT NULL DEFAULT 'pending',
+    rebooked_flight_id UUID REFERENCES flights(id),
+    failure_reason TEXT,
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    responded_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE(order_id, flight_id)
+);
+
+CREATE INDEX idx_rebooking_offers_order ON rebooking_offers(order_id);
+
+CREATE TRIGGER update_rebooking_offers_updated_at
+    BEFORE UPDATE ON rebooking_offers
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
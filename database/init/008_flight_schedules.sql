@@ -0,0 +1,59 @@
+-- Recurring flight schedules
+
+-- A flight number is now a service that operates on many days: a flight is
+-- identified by its number and (UTC) departure date.
+ALTER TABLE flights DROP CONSTRAINT flights_flight_number_key;
+CREATE UNIQUE INDEX idx_flights_number_date
+    ON flights (flight_number, ((departure_time AT TIME ZONE 'UTC')::date));
+
+-- A schedule operates a flight number on the given ISO days of the week
+-- (1 = Monday ... 7 = Sunday) between effective_from and effective_to
+-- (open-ended when NULL). Departure and arrival times are local to the
+-- origin and destination airports; arrival_day_offset is the number of days
+-- after departure the flight arrives.
+CREATE TABLE flight_schedules (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    flight_number VARCHAR(10) NOT NULL,
+    origin CHAR(3) NOT NULL REFERENCES airports(iata_code),
+    destination CHAR(3) NOT NULL REFERENCES airports(iata_code),
+    days_of_week INTEGER[] NOT NULL,
+    departure_time_local TIME NOT NULL,
+    arrival_time_local TIME NOT NULL,
+    arrival_day_offset INTEGER NOT NULL DEFAULT 0,
+    effective_from DATE NOT NULL,
+    effective_to DATE,
+    aircraft_type VARCHAR(10) NOT NULL REFERENCES aircraft_types(code),
+    cabin_layout VARCHAR(50) NOT NULL REFERENCES cabin_layouts(name),
+    price_per_seat DECIMAL(10, 2) NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    CHECK (cardinality(days_of_week) > 0 AND days_of_week <@ ARRAY[1, 2, 3, 4, 5, 6, 7]),
+    CHECK (arrival_day_offset BETWEEN 0 AND 2),
+    CHECK (effective_to IS NULL OR effective_to >= effective_from),
+    CHECK (price_per_seat > 0)
+);
+
+CREATE INDEX idx_flight_schedules_flight_number ON flight_schedules(flight_number);
+
+CREATE TRIGGER update_flight_schedules_updated_at
+    BEFORE UPDATE ON flight_schedules
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+
+-- Seats every flight of a schedule is created with, generated from the
+-- schedule's cabin layout and fare when the schedule is defined
+CREATE TABLE flight_schedule_seats (
+    schedule_id UUID NOT NULL REFERENCES flight_schedules(id) ON DELETE CASCADE,
+    seat_number VARCHAR(5) NOT NULL,
+    row_number INTEGER NOT NULL,
+    column_letter CHAR(1) NOT NULL,
+    class VARCHAR(20) NOT NULL,
+    price DECIMAL(10, 2) NOT NULL,
+    PRIMARY KEY (schedule_id, seat_number)
+);
+
+-- Flights materialized from a schedule. Deleting the schedule keeps them.
+ALTER TABLE flights
+    ADD COLUMN schedule_id UUID REFERENCES flight_schedules(id) ON DELETE SET NULL;
+
+CREATE INDEX idx_flights_schedule ON flights(schedule_id);
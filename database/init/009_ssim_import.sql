@@ -0,0 +1,10 @@
+-- SSIM schedule import
+
+-- SSIM files name aircraft by their IATA type code (e.g. "320"), which maps
+-- each code to the aircraft type flights are created with
+ALTER TABLE aircraft_types
+    ADD COLUMN iata_code CHAR(3) UNIQUE;
+
+UPDATE aircraft_types SET iata_code = '320' WHERE code = 'A320';
+UPDATE aircraft_types SET iata_code = '789' WHERE code = 'B789';
+UPDATE aircraft_types SET iata_code = 'E75' WHERE code = 'E175';
@@ -0,0 +1,100 @@
+-- Seat attributes and attribute surcharges
+
+-- Physical attributes of a seat, derived from its position in the cabin
+-- layout when the seat is generated:
+--   window         next to a window (outermost column)
+--   aisle          next to an aisle
+--   extra_legroom  in one of the cabin's extraLegroomRows
+--   exit_row       in one of the cabin's exitRows
+--   near_lavatory  in one of the cabin's lavatoryRows
+ALTER TABLE seats
+    ADD COLUMN attributes TEXT[] NOT NULL DEFAULT '{}'
+    CHECK (attributes <@ ARRAY['window', 'aisle', 'extra_legroom', 'exit_row', 'near_lavatory']);
+
+CREATE INDEX idx_seats_attributes ON seats USING GIN (attributes);
+
+ALTER TABLE flight_schedule_seats
+    ADD COLUMN attributes TEXT[] NOT NULL DEFAULT '{}';
+
+-- Amount added to the price of a seat for each of its attributes
+CREATE TABLE seat_attribute_surcharges (
+    attribute VARCHAR(20) PRIMARY KEY
+        CHECK (attribute IN ('window', 'aisle', 'extra_legroom', 'exit_row', 'near_lavatory')),
+    surcharge DECIMAL(10, 2) NOT NULL DEFAULT 0 CHECK (surcharge >= 0),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TRIGGER update_seat_attribute_surcharges_updated_at
+    BEFORE UPDATE ON seat_attribute_surcharges
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+
+INSERT INTO seat_attribute_surcharges (attribute, surcharge)
+VALUES
+    ('window', 0),
+    ('aisle', 0),
+    ('extra_legroom', 35),
+    ('exit_row', 20),
+    ('near_lavatory', 0);
+
+-- Extra legroom and lavatory rows of the seeded layouts
+UPDATE cabin_layouts
+SET cabins = jsonb_set(jsonb_set(jsonb_set(cabins,
+        '{0,lavatoryRows}', '[1]'),
+        '{2,extraLegroomRows}', '[11, 12]'),
+        '{2,lavatoryRows}', '[30]')
+WHERE name = 'narrowbody-standard';
+
+UPDATE cabin_layouts
+SET cabins = jsonb_set(jsonb_set(jsonb_set(cabins,
+        '{0,lavatoryRows}', '[1]'),
+        '{1,extraLegroomRows}', '[4, 10]'),
+        '{1,lavatoryRows}', '[20]')
+WHERE name = 'regional';
+
+UPDATE cabin_layouts
+SET cabins = jsonb_set(jsonb_set(jsonb_set(jsonb_set(cabins,
+        '{0,lavatoryRows}', '[1]'),
+        '{2,extraLegroomRows}', '[9]'),
+        '{3,extraLegroomRows}', '[14, 28]'),
+        '{3,lavatoryRows}', '[27, 41]')
+WHERE name = 'widebody';
+
+-- seat_layout_attributes returns the attributes of the seat at a row and
+-- column of a cabin layout, matching the seats the API generates
+CREATE FUNCTION seat_layout_attributes(layout_name VARCHAR, seat_row INTEGER, seat_column CHAR)
+RETURNS TEXT[] AS $$
+    SELECT COALESCE((
+        SELECT array_remove(ARRAY[
+            CASE WHEN (g.idx = 1 AND left(g.cols, 1) = seat_column)
+                   OR (g.idx = jsonb_array_length(c.cabin->'columnGroups') AND right(g.cols, 1) = seat_column)
+                 THEN 'window' END,
+            CASE WHEN (g.idx > 1 AND left(g.cols, 1) = seat_column)
+                   OR (g.idx < jsonb_array_length(c.cabin->'columnGroups') AND right(g.cols, 1) = seat_column)
+                 THEN 'aisle' END,
+            CASE WHEN COALESCE(c.cabin->'extraLegroomRows' @> to_jsonb(seat_row), FALSE) THEN 'extra_legroom' END,
+            CASE WHEN COALESCE(c.cabin->'exitRows' @> to_jsonb(seat_row), FALSE) THEN 'exit_row' END,
+            CASE WHEN COALESCE(c.cabin->'lavatoryRows' @> to_jsonb(seat_row), FALSE) THEN 'near_lavatory' END
+        ], NULL)
+        FROM cabin_layouts l
+        CROSS JOIN LATERAL jsonb_array_elements(l.cabins) AS c(cabin)
+        CROSS JOIN LATERAL jsonb_array_elements_text(c.cabin->'columnGroups') WITH ORDINALITY AS g(cols, idx)
+        WHERE l.name = layout_name
+          AND seat_row BETWEEN (c.cabin->>'firstRow')::int AND (c.cabin->>'lastRow')::int
+          AND strpos(g.cols, seat_column) > 0
+        LIMIT 1
+    ), '{}');
+$$ LANGUAGE sql STABLE;
+
+-- Backfill the seats that already exist
+UPDATE seats s
+SET attributes = seat_layout_attributes(f.cabin_layout, s.row_number, s.column_letter)
+FROM flights f
+WHERE f.id = s.flight_id;
+
+UPDATE flight_schedule_seats t
+SET attributes = seat_layout_attributes(fs.cabin_layout, t.row_number, t.column_letter)
+FROM flight_schedules fs
+WHERE fs.id = t.schedule_id;
+
+DROP FUNCTION seat_layout_attributes(VARCHAR, INTEGER, CHAR);
@@ -0,0 +1,4 @@
+-- Expired seat holds are released by the worker's hold reaper, which also
+-- expires the orders that held them and publishes the released seats on the
+-- seat_events notification channel. Reads no longer release holds.
+DROP FUNCTION IF EXISTS release_expired_holds();
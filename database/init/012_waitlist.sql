@@ -0,0 +1,40 @@
+-- Waitlist for sold-out flights and cabin classes
+
+CREATE TYPE waitlist_status AS ENUM (
+    'waiting',
+    'offered',
+    'accepted',
+    'expired',
+    'cancelled'
+);
+
+-- Customers waiting for seats of a class on a flight, served in the order
+-- they joined. When seats are released the flight's waitlist workflow creates
+-- an order for the first customer, holds seats for it for a short time and
+-- records it here as an offer.
+CREATE TABLE waitlist_entries (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    flight_id UUID NOT NULL REFERENCES flights(id) ON DELETE CASCADE,
+    class VARCHAR(20) NOT NULL,
+    customer_name VARCHAR(100) NOT NULL,
+    customer_email VARCHAR(255) NOT NULL,
+    party_size INTEGER NOT NULL DEFAULT 1 CHECK (party_size BETWEEN 1 AND 9),
+    status waitlist_status NOT NULL DEFAULT 'waiting',
+    order_id UUID REFERENCES orders(id) ON DELETE SET NULL,
+    offered_at TIMESTAMP WITH TIME ZONE,
+    offer_expires_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_waitlist_queue ON waitlist_entries(flight_id, class, created_at)
+    WHERE status = 'waiting';
+
+-- A customer can only wait once per flight and class
+CREATE UNIQUE INDEX idx_waitlist_customer ON waitlist_entries(flight_id, class, lower(customer_email))
+    WHERE status IN ('waiting', 'offered');
+
+CREATE TRIGGER update_waitlist_entries_updated_at
+    BEFORE UPDATE ON waitlist_entries
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
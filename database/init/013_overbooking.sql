@@ -0,0 +1,60 @@
+-- Overbooking: selling more seats of a class than the flight physically has
+
+CREATE TYPE overbooking_mode AS ENUM (
+    'percentage', -- value is a percentage of the class's seats
+    'fixed'       -- value is a number of seats
+);
+
+-- How far a class may be oversold, for a single flight or for every flight
+-- on a route. A policy without a class applies to each class separately.
+-- The most specific policy wins: flight and class, flight, route and class,
+-- then route.
+CREATE TABLE overbooking_policies (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    flight_id UUID REFERENCES flights(id) ON DELETE CASCADE,
+    origin CHAR(3) REFERENCES airports(iata_code),
+    destination CHAR(3) REFERENCES airports(iata_code),
+    class VARCHAR(20),
+    mode overbooking_mode NOT NULL,
+    value DECIMAL(6, 2) NOT NULL CHECK (value >= 0),
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    CHECK ((flight_id IS NOT NULL AND origin IS NULL AND destination IS NULL)
+        OR (flight_id IS NULL AND origin IS NOT NULL AND destination IS NOT NULL)),
+    CHECK (mode <> 'percentage' OR value <= 100),
+    CHECK (mode <> 'fixed' OR value = trunc(value))
+);
+
+CREATE UNIQUE INDEX idx_overbooking_flight ON overbooking_policies(flight_id, COALESCE(class, ''))
+    WHERE flight_id IS NOT NULL;
+CREATE UNIQUE INDEX idx_overbooking_route ON overbooking_policies(origin, destination, COALESCE(class, ''))
+    WHERE flight_id IS NULL;
+
+CREATE TRIGGER update_overbooking_policies_updated_at
+    BEFORE UPDATE ON overbooking_policies
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+
+CREATE TYPE overbooked_seat_status AS ENUM (
+    'unassigned', -- sold without a physical seat
+    'assigned',   -- given a seat at check-in or when the flight closed
+    'denied'      -- no seat was left when the flight closed
+);
+
+-- Seats sold beyond a class's capacity. Each row is one traveler of an
+-- order without a physical seat; seat_id is set once a seat is assigned.
+CREATE TABLE overbooked_seats (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    order_id UUID NOT NULL REFERENCES orders(id) ON DELETE CASCADE,
+    flight_id UUID NOT NULL REFERENCES flights(id) ON DELETE CASCADE,
+    class VARCHAR(20) NOT NULL,
+    price DECIMAL(10, 2) NOT NULL,
+    status overbooked_seat_status NOT NULL DEFAULT 'unassigned',
+    seat_id UUID REFERENCES seats(id),
+    assigned_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    CHECK ((status = 'assigned') = (seat_id IS NOT NULL))
+);
+
+CREATE INDEX idx_overbooked_seats_order ON overbooked_seats(order_id);
+CREATE INDEX idx_overbooked_seats_flight ON overbooked_seats(flight_id, class);
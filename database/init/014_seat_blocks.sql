@@ -0,0 +1,23 @@
+-- Seats taken out of sale by an admin, e.g. for crew rest, broken seats or
+-- operational holds. A blocked seat cannot be held or booked until it is
+-- unblocked, or its block expires and the worker's hold reaper lifts it.
+ALTER TYPE seat_status ADD VALUE IF NOT EXISTS 'blocked';
+
+CREATE TYPE seat_block_reason AS ENUM (
+    'crew',        -- reserved for crew
+    'maintenance', -- out of service
+    'operational'  -- weight and balance, security, ...
+);
+
+ALTER TABLE seats
+    ADD COLUMN block_reason seat_block_reason,
+    ADD COLUMN block_note TEXT,
+    ADD COLUMN blocked_by VARCHAR(100),
+    ADD COLUMN blocked_at TIMESTAMP WITH TIME ZONE,
+    ADD COLUMN blocked_until TIMESTAMP WITH TIME ZONE;
+
+ALTER TABLE seats
+    ADD CONSTRAINT seats_block_check
+    CHECK ((status = 'blocked') = (block_reason IS NOT NULL AND blocked_by IS NOT NULL));
+
+CREATE INDEX idx_seats_blocked_until ON seats(blocked_until) WHERE status = 'blocked';
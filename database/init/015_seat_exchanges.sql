@@ -0,0 +1,32 @@
+-- Seat changes of confirmed orders. The new seats are held for the order
+-- while the SeatExchangeWorkflow charges or credits the fare difference, and
+-- then swapped with the old ones in a single transaction. Old and new seats
+-- are paired by position.
+CREATE TYPE seat_exchange_status AS ENUM (
+    'pending',
+    'completed',
+    'failed'
+);
+
+CREATE TABLE seat_exchanges (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    order_id UUID NOT NULL REFERENCES orders(id) ON DELETE CASCADE,
+    old_seat_ids UUID[] NOT NULL,
+    new_seat_ids UUID[] NOT NULL,
+    -- Positive amounts are charged, negative ones credited
+    price_difference DECIMAL(10, 2) NOT NULL,
+    status seat_exchange_status NOT NULL DEFAULT 'pending',
+    workflow_id VARCHAR(255) NOT NULL,
+    transaction_id VARCHAR(100),
+    credit_id VARCHAR(100),
+    failure_reason TEXT,
+    hold_expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    completed_at TIMESTAMP WITH TIME ZONE,
+    CHECK (cardinality(old_seat_ids) = cardinality(new_seat_ids) AND cardinality(old_seat_ids) > 0)
+);
+
+CREATE INDEX idx_seat_exchanges_order ON seat_exchanges(order_id, created_at);
+
+-- An order changes seats one request at a time
+CREATE UNIQUE INDEX idx_seat_exchanges_pending ON seat_exchanges(order_id) WHERE status = 'pending';
@@ -0,0 +1,35 @@
+-- Passengers of an order. Every seat of an order, on each of its flights,
+-- is assigned to one adult or child passenger before payment; infants travel
+-- on an adult's lap without a seat.
+CREATE TYPE passenger_type AS ENUM (
+    'adult',  -- 12 years or older at departure
+    'child',  -- 2 to 11 years
+    'infant'  -- under 2 years
+);
+
+CREATE TABLE passengers (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    order_id UUID NOT NULL REFERENCES orders(id) ON DELETE CASCADE,
+    first_name VARCHAR(100) NOT NULL,
+    last_name VARCHAR(100) NOT NULL,
+    date_of_birth DATE NOT NULL,
+    passenger_type passenger_type NOT NULL,
+    -- Travel document, e.g. a passport
+    document_type VARCHAR(20),
+    document_number VARCHAR(50),
+    document_country CHAR(2),
+    document_expires_on DATE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    CHECK ((document_number IS NULL) = (document_type IS NULL)
+        AND (document_number IS NULL) = (document_country IS NULL))
+);
+
+CREATE INDEX idx_passengers_order ON passengers(order_id);
+
+-- The passenger sitting in each seat of an order; travelers booked without a
+-- seat keep their passenger when a seat is assigned
+ALTER TABLE order_seats ADD COLUMN passenger_id UUID REFERENCES passengers(id) ON DELETE SET NULL;
+ALTER TABLE overbooked_seats ADD COLUMN passenger_id UUID REFERENCES passengers(id) ON DELETE SET NULL;
+
+CREATE INDEX idx_order_seats_passenger ON order_seats(passenger_id);
+CREATE INDEX idx_overbooked_seats_passenger ON overbooked_seats(passenger_id);
@@ -0,0 +1,5 @@
+-- Six-character record locators customers find their booking by, given to
+-- orders when they are confirmed. The alphabet leaves out 0, 1, I and O,
+-- which are easily mistaken for one another.
+ALTER TABLE orders ADD COLUMN booking_reference CHAR(6) UNIQUE
+    CHECK (booking_reference ~ '^[A-HJ-NP-Z2-9]{6}$');
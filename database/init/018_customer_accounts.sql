@@ -0,0 +1,32 @@
+-- Customer accounts. Orders placed while signed in belong to the account;
+-- guest orders have no customer_id and are reached by their order ID.
+CREATE TABLE customers (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    email VARCHAR(255) NOT NULL,
+    name VARCHAR(100) NOT NULL,
+    password_hash VARCHAR(60) NOT NULL,  -- bcrypt
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Emails are matched ignoring case
+CREATE UNIQUE INDEX idx_customers_email ON customers(LOWER(email));
+
+CREATE TRIGGER update_customers_updated_at
+    BEFORE UPDATE ON customers
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+
+-- Signed-in sessions. Only the SHA-256 hash of each bearer token is stored.
+CREATE TABLE customer_sessions (
+    token_hash CHAR(64) PRIMARY KEY,
+    customer_id UUID NOT NULL REFERENCES customers(id) ON DELETE CASCADE,
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_customer_sessions_customer ON customer_sessions(customer_id);
+
+ALTER TABLE orders ADD COLUMN customer_id UUID REFERENCES customers(id) ON DELETE SET NULL;
+
+CREATE INDEX idx_orders_customer ON orders(customer_id, created_at DESC);
@@ -0,0 +1,5 @@
+-- Indexes for the admin order search, next to idx_orders_status and
+-- idx_orders_flight: orders are listed newest first and found by customer
+-- email ignoring case
+CREATE INDEX idx_orders_created ON orders(created_at DESC, id DESC);
+CREATE INDEX idx_orders_customer_email ON orders(LOWER(customer_email));
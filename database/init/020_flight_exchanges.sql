@@ -0,0 +1,41 @@
+-- Moves of a confirmed order from one of its flights to another flight on
+-- the same route. The FlightExchangeWorkflow holds seats on the target flight,
+-- records the fare difference and change fee, collects or refunds what is
+-- owed, books the new seats and only then releases the old ones. Old and new
+-- seats are paired by position once they are held.
+CREATE TYPE flight_exchange_status AS ENUM (
+    'pending',   -- waiting for seats on the target flight
+    'held',      -- new seats held while the amount due is settled
+    'confirmed', -- new seats booked, old seats not yet released
+    'completed',
+    'failed'
+);
+
+CREATE TABLE flight_exchanges (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    order_id UUID NOT NULL REFERENCES orders(id) ON DELETE CASCADE,
+    from_flight_id UUID NOT NULL REFERENCES flights(id),
+    to_flight_id UUID NOT NULL REFERENCES flights(id),
+    old_seat_ids UUID[] NOT NULL DEFAULT '{}',
+    new_seat_ids UUID[] NOT NULL DEFAULT '{}',
+    -- Set when the seats are held. Positive differences are charged together
+    -- with the change fee, negative ones refunded less the fee.
+    fare_difference DECIMAL(10, 2),
+    change_fee DECIMAL(10, 2),
+    status flight_exchange_status NOT NULL DEFAULT 'pending',
+    workflow_id VARCHAR(255) NOT NULL,
+    transaction_id VARCHAR(100),
+    refund_id VARCHAR(100),
+    failure_reason TEXT,
+    hold_expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    completed_at TIMESTAMP WITH TIME ZONE,
+    CHECK (from_flight_id <> to_flight_id),
+    CHECK (cardinality(old_seat_ids) = cardinality(new_seat_ids))
+);
+
+CREATE INDEX idx_flight_exchanges_order ON flight_exchanges(order_id, created_at);
+
+-- An order changes flights one request at a time
+CREATE UNIQUE INDEX idx_flight_exchanges_active ON flight_exchanges(order_id)
+    WHERE status IN ('pending', 'held', 'confirmed');
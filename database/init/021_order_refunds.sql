@@ -0,0 +1,34 @@
+-- Cancellations of confirmed bookings. Cancelling releases the seats of the
+-- cancelled passengers, or of the whole order, right away and records the
+-- refund the refund rules allow; a RefundWorkflow then returns the money
+-- through the payment provider.
+CREATE TYPE refund_status AS ENUM (
+    'pending',   -- waiting for the payment provider
+    'completed',
+    'failed'     -- the provider did not accept the refund; needs support
+);
+
+CREATE TABLE order_refunds (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    order_id UUID NOT NULL REFERENCES orders(id) ON DELETE CASCADE,
+    -- The cancelled passengers; empty when the whole order was cancelled
+    passenger_ids UUID[] NOT NULL DEFAULT '{}',
+    -- What was paid for the cancelled seats, and the share of it refunded
+    fare_amount DECIMAL(10, 2) NOT NULL,
+    refund_percent INTEGER NOT NULL CHECK (refund_percent BETWEEN 0 AND 100),
+    amount DECIMAL(10, 2) NOT NULL CHECK (amount >= 0),
+    status refund_status NOT NULL DEFAULT 'pending',
+    workflow_id VARCHAR(255),
+    provider_refund_id VARCHAR(100),
+    failure_reason TEXT,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    completed_at TIMESTAMP WITH TIME ZONE
+);
+
+CREATE INDEX idx_order_refunds_order ON order_refunds(order_id, created_at);
+
+-- The total refunded to the customer of an order
+ALTER TABLE orders ADD COLUMN refunded_amount DECIMAL(10, 2) NOT NULL DEFAULT 0;
+
+-- Passengers cancelled from a confirmed order
+ALTER TABLE passengers ADD COLUMN cancelled_at TIMESTAMP WITH TIME ZONE;
@@ -0,0 +1,90 @@
+-- History of every order: status transitions, flight and seat changes,
+-- payment attempts and workflow signals. Status, flight and seat changes are
+-- recorded by triggers, so every writer (api-server, worker, manual SQL) is
+-- covered; payment attempts and signals are recorded by the services.
+
+-- The actor of an order event: the application_name of the connection
+-- (api-server, temporal-worker, psql, ...) or else the database user
+CREATE OR REPLACE FUNCTION order_event_actor()
+RETURNS VARCHAR AS $$
+    SELECT COALESCE(NULLIF(current_setting('application_name', true), ''), session_user)::VARCHAR
+$$ LANGUAGE sql STABLE;
+
+CREATE TABLE order_events (
+    id BIGSERIAL PRIMARY KEY,
+    order_id UUID NOT NULL REFERENCES orders(id) ON DELETE CASCADE,
+    -- created, status_changed, flight_changed, seats_added, seats_removed,
+    -- payment_attempted, refund_issued or signal_sent
+    event_type VARCHAR(50) NOT NULL,
+    actor VARCHAR(100) NOT NULL DEFAULT order_event_actor(),
+    payload JSONB NOT NULL DEFAULT '{}',
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_order_events_order ON order_events(order_id, id);
+
+-- Records order creation, status transitions and flight changes
+CREATE OR REPLACE FUNCTION record_order_event()
+RETURNS TRIGGER AS $$
+BEGIN
+    IF TG_OP = 'INSERT' THEN
+        INSERT INTO order_events (order_id, event_type, payload)
+        VALUES (NEW.id, 'created', jsonb_build_object('status', NEW.status, 'flightId', NEW.flight_id));
+        RETURN NULL;
+    END IF;
+
+    IF NEW.status IS DISTINCT FROM OLD.status THEN
+        INSERT INTO order_events (order_id, event_type, payload)
+        VALUES (NEW.id, 'status_changed', jsonb_strip_nulls(jsonb_build_object(
+            'from', OLD.status,
+            'to', NEW.status,
+            'failureReason', CASE WHEN NEW.status = 'failed' THEN NEW.failure_reason END
+        )));
+    END IF;
+    IF NEW.flight_id IS DISTINCT FROM OLD.flight_id THEN
+        INSERT INTO order_events (order_id, event_type, payload)
+        VALUES (NEW.id, 'flight_changed', jsonb_build_object('from', OLD.flight_id, 'to', NEW.flight_id));
+    END IF;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER record_order_events
+    AFTER INSERT OR UPDATE OF status, flight_id ON orders
+    FOR EACH ROW
+    EXECUTE FUNCTION record_order_event();
+
+-- Records the seats added to or removed from orders by a statement, one
+-- event per order. Seats removed because their order was deleted are not
+-- recorded.
+CREATE OR REPLACE FUNCTION record_order_seat_events()
+RETURNS TRIGGER AS $$
+BEGIN
+    INSERT INTO order_events (order_id, event_type, payload)
+    SELECT c.order_id,
+           CASE TG_OP WHEN 'INSERT' THEN 'seats_added' ELSE 'seats_removed' END,
+           jsonb_build_object('seats', jsonb_agg(jsonb_build_object(
+               'seatId', s.id,
+               'seatNumber', s.seat_number,
+               'flightId', s.flight_id,
+               'price', c.price
+           ) ORDER BY s.flight_id, s.seat_number))
+    FROM changed_seats c
+    JOIN seats s ON s.id = c.seat_id
+    WHERE EXISTS (SELECT 1 FROM orders o WHERE o.id = c.order_id)
+    GROUP BY c.order_id;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER record_order_seats_added
+    AFTER INSERT ON order_seats
+    REFERENCING NEW TABLE AS changed_seats
+    FOR EACH STATEMENT
+    EXECUTE FUNCTION record_order_seat_events();
+
+CREATE TRIGGER record_order_seats_removed
+    AFTER DELETE ON order_seats
+    REFERENCING OLD TABLE AS changed_seats
+    FOR EACH STATEMENT
+    EXECUTE FUNCTION record_order_seat_events();
@@ -1,14 +1,15 @@
-import { Routes, Route } from 'react-router-dom';
-import { Plane } from 'lucide-react';
+import { Routes, Route, Link } from 'react-router-dom';
+import { Plane, User } from 'lucide-react';
 import { FlightList } from './components/FlightList';
 import { BookingPage } from './components/BookingPage';
+import { AccountPage } from './components/AccountPage';
 
 function App() {
   return (
     <div className="min-h-screen flex flex-col">
       {/* Header */}
       <header className="border-b border-slate-700/50 backdrop-blur-sm sticky top-0 z-50 bg-slate-900/80">
-        <div className="container-responsive py-4">
+        <div className="container-responsive py-4 flex items-center justify-between">
           <a href="/" className="flex items-center gap-3 w-fit">
             <div className="w-10 h-10 rounded-xl bg-gradient-to-br from-cyan-500 to-emerald-500 flex items-center justify-center shadow-lg shadow-cyan-500/25">
               <Plane className="w-5 h-5 text-slate-900 transform rotate-45" />
@@ -18,6 +19,10 @@ function App() {
               <span className="hidden sm:inline text-slate-500 text-sm ml-2">Flight Booking</span>
             </div>
           </a>
+          <Link to="/account" className="flex items-center gap-2 text-slate-400 hover:text-white transition-colors">
+            <User className="w-5 h-5" />
+            <span className="hidden sm:inline">My Account</span>
+          </Link>
         </div>
       </header>
 
@@ -26,6 +31,7 @@ function App() {
         <Routes>
           <Route path="/" element={<FlightList />} />
           <Route path="/book/:flightId" element={<BookingPage />} />
+          <Route path="/account" element={<AccountPage />} />
         </Routes>
       </main>
 
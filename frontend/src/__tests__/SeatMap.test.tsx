@@ -1,6 +1,6 @@
 import { render, screen, fireEvent } from '@testing-library/react';
 import { SeatMap } from '../components/SeatMap';
-import type { Seat } from '../types';
+import type { Seat, SeatMapCabin } from '../types';
 
 const createMockSeats = (): Seat[] => [
   { id: 'FL001-1A', flightId: 'FL001', row: 1, column: 'A', class: 'economy', status: 'available', price: 150 },
@@ -94,5 +94,52 @@ describe('SeatMap', () => {
     const columnLabels = screen.getAllByText('A');
     expect(columnLabels.length).toBeGreaterThan(0);
   });
-});
 
+  describe('with a cabin layout', () => {
+    // 1-2 business row with an exit row, and a row with an empty position
+    const seats: Seat[] = [
+      { id: 'S-1A', flightId: 'FL001', row: 1, column: 'A', class: 'business', status: 'available', price: 300 },
+      { id: 'S-1C', flightId: 'FL001', row: 1, column: 'C', class: 'business', status: 'available', price: 300 },
+      { id: 'S-1D', flightId: 'FL001', row: 1, column: 'D', class: 'business', status: 'booked', price: 300 },
+      { id: 'S-2C', flightId: 'FL001', row: 2, column: 'C', class: 'business', status: 'available', price: 300 },
+      { id: 'S-2D', flightId: 'FL001', row: 2, column: 'D', class: 'business', status: 'available', price: 300 },
+    ];
+    const cabins: SeatMapCabin[] = [
+      {
+        class: 'business',
+        columnGroups: ['A', 'CD'],
+        rows: [
+          { row: 1, exitRow: true, seats: ['S-1A', 'S-1C', 'S-1D'] },
+          { row: 2, seats: [null, 'S-2C', 'S-2D'] },
+        ],
+      },
+    ];
+
+    it('should render the seats placed by the layout', () => {
+      render(<SeatMap seats={seats} cabins={cabins} selectedSeats={[]} onSeatSelect={jest.fn()} />);
+
+      expect(screen.getAllByRole('button', { name: /^Seat / })).toHaveLength(5);
+      expect(screen.queryByRole('button', { name: /Seat 2A/i })).not.toBeInTheDocument();
+      expect(screen.getByText('Business')).toBeInTheDocument();
+    });
+
+    it('should mark exit rows', () => {
+      render(<SeatMap seats={seats} cabins={cabins} selectedSeats={[]} onSeatSelect={jest.fn()} />);
+
+      expect(screen.getAllByText('EXIT')).toHaveLength(1);
+      expect(screen.getByRole('button', { name: /Seat 1A/i })).toHaveAttribute('title', expect.stringContaining('exit row'));
+    });
+
+    it('should reflect seat status updates', () => {
+      const { rerender } = render(
+        <SeatMap seats={seats} cabins={cabins} selectedSeats={[]} onSeatSelect={jest.fn()} />
+      );
+      expect(screen.getByRole('button', { name: /Seat 2C/i })).not.toBeDisabled();
+
+      const updated = seats.map((s) => (s.id === 'S-2C' ? { ...s, status: 'held' as const } : s));
+      rerender(<SeatMap seats={updated} cabins={cabins} selectedSeats={[]} onSeatSelect={jest.fn()} />);
+
+      expect(screen.getByRole('button', { name: /Seat 2C/i })).toBeDisabled();
+    });
+  });
+});
@@ -51,21 +51,28 @@ describe('API', () => {
   });
 
   describe('getFlightSeats', () => {
-    it('should fetch seats for a flight', async () => {
-      const mockSeats = [
-        { id: 'FL001-1A', row: 1, column: 'A', status: 'available', price: 150 },
-        { id: 'FL001-1B', row: 1, column: 'B', status: 'booked', price: 150 },
-      ];
+    it('should fetch the seat map for a flight', async () => {
+      const mockSeatMap = {
+        flightId: 'FL001',
+        cabinLayout: 'regional',
+        cabins: [
+          { class: 'business', columnGroups: ['A', 'B'], rows: [{ row: 1, seats: ['FL001-1A', 'FL001-1B'] }] },
+        ],
+        seats: [
+          { id: 'FL001-1A', row: 1, column: 'A', status: 'available', price: 150 },
+          { id: 'FL001-1B', row: 1, column: 'B', status: 'booked', price: 150 },
+        ],
+      };
 
       (global.fetch as jest.Mock).mockResolvedValueOnce({
         ok: true,
-        json: async () => mockSeats,
+        json: async () => mockSeatMap,
       });
 
       const result = await api.getFlightSeats('FL001');
 
       expect(fetch).toHaveBeenCalledWith('/api/flights/FL001/seats');
-      expect(result).toEqual(mockSeats);
+      expect(result).toEqual(mockSeatMap);
     });
   });
 
@@ -1,4 +1,4 @@
-import { cn, formatTime, formatDate, formatCurrency } from '../lib/utils';
+import { cn, formatTime, formatDate, formatCurrency, formatAirport } from '../lib/utils';
 
 describe('utils', () => {
   describe('cn', () => {
@@ -28,6 +28,23 @@ describe('utils', () => {
       // Time format depends on locale, so just check it contains numbers
       expect(result).toMatch(/\d{1,2}:\d{2}/);
     });
+
+    it('should format time in the given time zone', () => {
+      const date = '2025-01-15T14:30:00Z';
+
+      expect(formatTime(date, 'America/New_York')).toBe('09:30 AM');
+      expect(formatTime(date, 'Asia/Jerusalem')).toBe('04:30 PM');
+    });
+  });
+
+  describe('formatAirport', () => {
+    it('should include the city when the airport is known', () => {
+      expect(formatAirport('JFK', { city: 'New York' })).toBe('New York (JFK)');
+    });
+
+    it('should fall back to the code', () => {
+      expect(formatAirport('JFK')).toBe('JFK');
+    });
   });
 
   describe('formatDate', () => {
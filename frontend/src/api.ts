@@ -1,7 +1,26 @@
-import type { Flight, Seat, Order, OrderStatusResponse } from './types';
+import type { AutoAssignRequest, Booking, Customer, Session, ChangeSeatsResponse, Flight, FlightExchange, SeatMap, Order, OrderRefund, OrderStatusResponse, OverbookRequest, PassengerRequest, RebookingOffer, SeatExchange, SeatSwap, WaitlistEntry } from './types';
 
 const API_BASE = '/api';
 
+const SESSION_TOKEN_KEY = 'sessionToken';
+
+// The session token of the signed-in customer, kept across page loads
+export const sessionToken = {
+  get: (): string | null => localStorage.getItem(SESSION_TOKEN_KEY),
+  set: (token: string) => localStorage.setItem(SESSION_TOKEN_KEY, token),
+  clear: () => localStorage.removeItem(SESSION_TOKEN_KEY),
+};
+
+// authFetch sends the session token, if signed in, so that orders are placed
+// with and only accessible by the customer's account; guests fetch as usual
+function authFetch(url: string, init?: RequestInit): Promise<Response> {
+  const token = sessionToken.get();
+  if (!token) {
+    return init ? fetch(url, init) : fetch(url);
+  }
+  return fetch(url, { ...init, headers: { ...init?.headers, Authorization: `Bearer ${token}` } });
+}
+
 async function handleResponse<T>(response: Response): Promise<T> {
   if (!response.ok) {
     const error = await response.json().catch(() => ({ error: 'Unknown error' }));
@@ -16,6 +35,19 @@ export interface CreateOrderRequest {
   customerName: string;
 }
 
+export interface CustomerOrdersFilter {
+  status?: string[];
+  from?: string; // YYYY-MM-DD
+  to?: string; // YYYY-MM-DD, inclusive
+}
+
+export interface JoinWaitlistRequest {
+  class: string;
+  customerName: string;
+  customerEmail: string;
+  partySize?: number;
+}
+
 export const api = {
   // Flights
   getFlights: async (): Promise<Flight[]> => {
@@ -28,14 +60,56 @@ export const api = {
     return handleResponse<Flight>(response);
   },
 
-  getFlightSeats: async (flightId: string): Promise<Seat[]> => {
+  getFlightSeats: async (flightId: string): Promise<SeatMap> => {
     const response = await fetch(`${API_BASE}/flights/${flightId}/seats`);
-    return handleResponse<Seat[]>(response);
+    return handleResponse<SeatMap>(response);
+  },
+
+  // Customer accounts
+  register: async (email: string, password: string, name: string): Promise<Session> => {
+    const response = await fetch(`${API_BASE}/auth/register`, {
+      method: 'POST',
+      headers: { 'Content-Type': 'application/json' },
+      body: JSON.stringify({ email, password, name }),
+    });
+    return handleResponse<Session>(response);
+  },
+
+  login: async (email: string, password: string): Promise<Session> => {
+    const response = await fetch(`${API_BASE}/auth/login`, {
+      method: 'POST',
+      headers: { 'Content-Type': 'application/json' },
+      body: JSON.stringify({ email, password }),
+    });
+    return handleResponse<Session>(response);
+  },
+
+  logout: async (): Promise<void> => {
+    const response = await authFetch(`${API_BASE}/auth/logout`, { method: 'POST' });
+    if (!response.ok && response.status !== 401) {
+      const error = await response.json().catch(() => ({ error: 'Unknown error' }));
+      throw new Error(error.error || `HTTP ${response.status}`);
+    }
+  },
+
+  getMe: async (): Promise<Customer> => {
+    const response = await authFetch(`${API_BASE}/me`);
+    return handleResponse<Customer>(response);
+  },
+
+  getMyOrders: async (filter: CustomerOrdersFilter = {}): Promise<Order[]> => {
+    const params = new URLSearchParams();
+    if (filter.status?.length) params.set('status', filter.status.join(','));
+    if (filter.from) params.set('from', filter.from);
+    if (filter.to) params.set('to', filter.to);
+    const query = params.toString();
+    const response = await authFetch(`${API_BASE}/me/orders${query ? `?${query}` : ''}`);
+    return handleResponse<Order[]>(response);
   },
 
   // Orders
   createOrder: async (request: CreateOrderRequest): Promise<Order> => {
-    const response = await fetch(`${API_BASE}/orders`, {
+    const response = await authFetch(`${API_BASE}/orders`, {
       method: 'POST',
       headers: { 'Content-Type': 'application/json' },
       body: JSON.stringify(request),
@@ -44,12 +118,18 @@ export const api = {
   },
 
   getOrderStatus: async (orderId: string): Promise<OrderStatusResponse> => {
-    const response = await fetch(`${API_BASE}/orders/${orderId}`);
+    const response = await authFetch(`${API_BASE}/orders/${orderId}`);
     return handleResponse<OrderStatusResponse>(response);
   },
 
+  getBooking: async (reference: string, lastName: string): Promise<Booking> => {
+    const params = new URLSearchParams({ lastName });
+    const response = await fetch(`${API_BASE}/bookings/${encodeURIComponent(reference)}?${params}`);
+    return handleResponse<Booking>(response);
+  },
+
   selectSeats: async (orderId: string, seatIds: string[]): Promise<OrderStatusResponse> => {
-    const response = await fetch(`${API_BASE}/orders/${orderId}/seats`, {
+    const response = await authFetch(`${API_BASE}/orders/${orderId}/seats`, {
       method: 'POST',
       headers: { 'Content-Type': 'application/json' },
       body: JSON.stringify({ seatIds }),
@@ -57,8 +137,35 @@ export const api = {
     return handleResponse<OrderStatusResponse>(response);
   },
 
+  autoAssignSeats: async (orderId: string, autoAssign: AutoAssignRequest): Promise<OrderStatusResponse> => {
+    const response = await authFetch(`${API_BASE}/orders/${orderId}/seats`, {
+      method: 'POST',
+      headers: { 'Content-Type': 'application/json' },
+      body: JSON.stringify({ autoAssign }),
+    });
+    return handleResponse<OrderStatusResponse>(response);
+  },
+
+  overbookSeats: async (orderId: string, overbook: OverbookRequest): Promise<OrderStatusResponse> => {
+    const response = await authFetch(`${API_BASE}/orders/${orderId}/seats`, {
+      method: 'POST',
+      headers: { 'Content-Type': 'application/json' },
+      body: JSON.stringify({ overbook }),
+    });
+    return handleResponse<OrderStatusResponse>(response);
+  },
+
+  changeSeats: async (orderId: string, add: string[], remove: string[]): Promise<ChangeSeatsResponse> => {
+    const response = await authFetch(`${API_BASE}/orders/${orderId}/seats`, {
+      method: 'PATCH',
+      headers: { 'Content-Type': 'application/json' },
+      body: JSON.stringify({ add, remove }),
+    });
+    return handleResponse<ChangeSeatsResponse>(response);
+  },
+
   submitPayment: async (orderId: string, paymentCode: string): Promise<OrderStatusResponse> => {
-    const response = await fetch(`${API_BASE}/orders/${orderId}/pay`, {
+    const response = await authFetch(`${API_BASE}/orders/${orderId}/pay`, {
       method: 'POST',
       headers: { 'Content-Type': 'application/json' },
       body: JSON.stringify({ paymentCode }),
@@ -66,8 +173,52 @@ export const api = {
     return handleResponse<OrderStatusResponse>(response);
   },
 
+  setPassengers: async (orderId: string, passengers: PassengerRequest[]): Promise<OrderStatusResponse> => {
+    const response = await authFetch(`${API_BASE}/orders/${orderId}/passengers`, {
+      method: 'PUT',
+      headers: { 'Content-Type': 'application/json' },
+      body: JSON.stringify({ passengers }),
+    });
+    return handleResponse<OrderStatusResponse>(response);
+  },
+
+  checkIn: async (orderId: string): Promise<OrderStatusResponse> => {
+    const response = await authFetch(`${API_BASE}/orders/${orderId}/check-in`, {
+      method: 'POST',
+    });
+    return handleResponse<OrderStatusResponse>(response);
+  },
+
+  exchangeSeats: async (orderId: string, swaps: SeatSwap[], paymentCode?: string): Promise<SeatExchange> => {
+    const response = await authFetch(`${API_BASE}/orders/${orderId}/seat-exchanges`, {
+      method: 'POST',
+      headers: { 'Content-Type': 'application/json' },
+      body: JSON.stringify({ swaps, paymentCode }),
+    });
+    return handleResponse<SeatExchange>(response);
+  },
+
+  exchangeFlight: async (orderId: string, fromFlightId: string, toFlightId: string, paymentCode?: string): Promise<FlightExchange> => {
+    const response = await authFetch(`${API_BASE}/orders/${orderId}/flight-exchanges`, {
+      method: 'POST',
+      headers: { 'Content-Type': 'application/json' },
+      body: JSON.stringify({ fromFlightId, toFlightId, paymentCode }),
+    });
+    return handleResponse<FlightExchange>(response);
+  },
+
+  // Cancels a confirmed order, or only the given passengers, and refunds them
+  cancelBooking: async (orderId: string, passengerIds?: string[]): Promise<OrderRefund> => {
+    const response = await authFetch(`${API_BASE}/orders/${orderId}/cancellation`, {
+      method: 'POST',
+      headers: { 'Content-Type': 'application/json' },
+      body: JSON.stringify({ passengerIds }),
+    });
+    return handleResponse<OrderRefund>(response);
+  },
+
   cancelOrder: async (orderId: string): Promise<void> => {
-    const response = await fetch(`${API_BASE}/orders/${orderId}`, {
+    const response = await authFetch(`${API_BASE}/orders/${orderId}`, {
       method: 'DELETE',
     });
     if (!response.ok) {
@@ -77,9 +228,49 @@ export const api = {
   },
 
   refreshTimer: async (orderId: string): Promise<OrderStatusResponse> => {
-    const response = await fetch(`${API_BASE}/orders/${orderId}/refresh`, {
+    const response = await authFetch(`${API_BASE}/orders/${orderId}/refresh`, {
       method: 'POST',
     });
     return handleResponse<OrderStatusResponse>(response);
   },
+
+  // Rebooking after a flight cancellation
+  getRebookingOffer: async (orderId: string): Promise<RebookingOffer> => {
+    const response = await authFetch(`${API_BASE}/orders/${orderId}/rebooking`);
+    return handleResponse<RebookingOffer>(response);
+  },
+
+  respondToRebooking: async (orderId: string, accept: boolean, flightId?: string): Promise<RebookingOffer> => {
+    const response = await authFetch(`${API_BASE}/orders/${orderId}/rebooking`, {
+      method: 'POST',
+      headers: { 'Content-Type': 'application/json' },
+      body: JSON.stringify({ accept, flightId }),
+    });
+    return handleResponse<RebookingOffer>(response);
+  },
+
+  // Waitlist for sold-out flights
+  joinWaitlist: async (flightId: string, request: JoinWaitlistRequest): Promise<WaitlistEntry> => {
+    const response = await fetch(`${API_BASE}/flights/${flightId}/waitlist`, {
+      method: 'POST',
+      headers: { 'Content-Type': 'application/json' },
+      body: JSON.stringify(request),
+    });
+    return handleResponse<WaitlistEntry>(response);
+  },
+
+  getWaitlistEntry: async (entryId: string): Promise<WaitlistEntry> => {
+    const response = await fetch(`${API_BASE}/waitlist/${entryId}`);
+    return handleResponse<WaitlistEntry>(response);
+  },
+
+  leaveWaitlist: async (entryId: string): Promise<void> => {
+    const response = await fetch(`${API_BASE}/waitlist/${entryId}`, {
+      method: 'DELETE',
+    });
+    if (!response.ok) {
+      const error = await response.json().catch(() => ({ error: 'Unknown error' }));
+      throw new Error(error.error || `HTTP ${response.status}`);
+    }
+  },
 };
@@ -0,0 +1,210 @@
+import { useState, useEffect } from 'react';
+import { Loader2, LogOut, User } from 'lucide-react';
+import { api, sessionToken } from '../api';
+import type { Customer, Order, OrderStatus } from '../types';
+import { Card, CardHeader, CardTitle, CardDescription, CardContent } from './ui/card';
+import { Button } from './ui/button';
+import { Badge } from './ui/badge';
+import { Input } from './ui/input';
+import { Alert, AlertDescription } from './ui/alert';
+import { formatDate, formatCurrency } from '../lib/utils';
+
+const statusOptions: OrderStatus[] = ['confirmed', 'awaiting_payment', 'processing', 'failed', 'cancelled', 'expired', 'refunded'];
+
+export function AccountPage() {
+  const [customer, setCustomer] = useState<Customer | null>(null);
+  const [loading, setLoading] = useState(() => sessionToken.get() !== null);
+
+  // Restore the session of a returning customer
+  useEffect(() => {
+    if (!sessionToken.get()) return;
+    api.getMe()
+      .then(setCustomer)
+      .catch(() => sessionToken.clear())
+      .finally(() => setLoading(false));
+  }, []);
+
+  const handleLogout = async () => {
+    await api.logout().catch(() => undefined);
+    sessionToken.clear();
+    setCustomer(null);
+  };
+
+  if (loading) {
+    return (
+      <div className="flex justify-center min-h-[400px] items-center">
+        <Loader2 className="w-12 h-12 text-cyan-500 animate-spin" />
+      </div>
+    );
+  }
+
+  if (!customer) {
+    return <SignInForm onSignedIn={setCustomer} />;
+  }
+
+  return (
+    <div className="max-w-3xl mx-auto space-y-6">
+      <div className="flex items-center justify-between">
+        <div>
+          <h1 className="text-2xl font-bold text-white">{customer.name}</h1>
+          <p className="text-slate-400">{customer.email}</p>
+        </div>
+        <Button variant="ghost" onClick={handleLogout}>
+          <LogOut className="w-4 h-4 mr-2" />
+          Sign Out
+        </Button>
+      </div>
+      <OrderHistory />
+    </div>
+  );
+}
+
+function SignInForm({ onSignedIn }: { onSignedIn: (customer: Customer) => void }) {
+  const [mode, setMode] = useState<'login' | 'register'>('login');
+  const [name, setName] = useState('');
+  const [email, setEmail] = useState('');
+  const [password, setPassword] = useState('');
+  const [submitting, setSubmitting] = useState(false);
+  const [error, setError] = useState<string | null>(null);
+
+  const handleSubmit = async (e: React.FormEvent) => {
+    e.preventDefault();
+    setSubmitting(true);
+    setError(null);
+    try {
+      const session = mode === 'login'
+        ? await api.login(email, password)
+        : await api.register(email, password, name);
+      sessionToken.set(session.token);
+      onSignedIn(session.customer);
+    } catch (err) {
+      setError(err instanceof Error ? err.message : 'Failed to sign in');
+    } finally {
+      setSubmitting(false);
+    }
+  };
+
+  return (
+    <Card className="max-w-md mx-auto">
+      <CardHeader>
+        <CardTitle className="flex items-center gap-2">
+          <User className="w-5 h-5 text-cyan-500" />
+          {mode === 'login' ? 'Sign In' : 'Create Account'}
+        </CardTitle>
+        <CardDescription>
+          Sign in to see your orders. You can also book as a guest without an account.
+        </CardDescription>
+      </CardHeader>
+      <CardContent>
+        <form onSubmit={handleSubmit} className="space-y-3">
+          {error && (
+            <Alert variant="danger">
+              <AlertDescription>{error}</AlertDescription>
+            </Alert>
+          )}
+          {mode === 'register' && (
+            <Input type="text" value={name} onChange={(e) => setName(e.target.value)} placeholder="Full name" required />
+          )}
+          <Input type="email" value={email} onChange={(e) => setEmail(e.target.value)} placeholder="Email" required />
+          <Input
+            type="password"
+            value={password}
+            onChange={(e) => setPassword(e.target.value)}
+            placeholder="Password (at least 8 characters)"
+            minLength={8}
+            required
+          />
+          <Button type="submit" className="w-full" disabled={submitting}>
+            {submitting && <Loader2 className="w-4 h-4 mr-2 animate-spin" />}
+            {mode === 'login' ? 'Sign In' : 'Create Account'}
+          </Button>
+          <Button
+            type="button"
+            variant="ghost"
+            className="w-full"
+            onClick={() => setMode(mode === 'login' ? 'register' : 'login')}
+          >
+            {mode === 'login' ? 'No account yet? Create one' : 'Already have an account? Sign in'}
+          </Button>
+        </form>
+      </CardContent>
+    </Card>
+  );
+}
+
+function OrderHistory() {
+  const [orders, setOrders] = useState<Order[]>([]);
+  const [status, setStatus] = useState('');
+  const [from, setFrom] = useState('');
+  const [to, setTo] = useState('');
+  const [loading, setLoading] = useState(true);
+  const [error, setError] = useState<string | null>(null);
+
+  useEffect(() => {
+    setLoading(true);
+    setError(null);
+    api.getMyOrders({ status: status ? [status] : undefined, from: from || undefined, to: to || undefined })
+      .then(setOrders)
+      .catch((err) => setError(err.message))
+      .finally(() => setLoading(false));
+  }, [status, from, to]);
+
+  return (
+    <Card>
+      <CardHeader>
+        <CardTitle>My Orders</CardTitle>
+        <div className="grid sm:grid-cols-3 gap-2 pt-2">
+          <select
+            value={status}
+            onChange={(e) => setStatus(e.target.value)}
+            aria-label="Order status"
+            className="h-10 rounded-lg bg-slate-700/50 border border-slate-600 px-3 text-white"
+          >
+            <option value="">All statuses</option>
+            {statusOptions.map((s) => (
+              <option key={s} value={s}>{s.replace('_', ' ')}</option>
+            ))}
+          </select>
+          <Input type="date" value={from} onChange={(e) => setFrom(e.target.value)} aria-label="Booked from" />
+          <Input type="date" value={to} onChange={(e) => setTo(e.target.value)} aria-label="Booked until" />
+        </div>
+      </CardHeader>
+      <CardContent>
+        {error && (
+          <Alert variant="danger">
+            <AlertDescription>{error}</AlertDescription>
+          </Alert>
+        )}
+        {loading ? (
+          <Loader2 className="w-6 h-6 mx-auto text-cyan-500 animate-spin" />
+        ) : orders.length === 0 ? (
+          <p className="text-slate-400 text-center">No orders found.</p>
+        ) : (
+          <ul className="divide-y divide-slate-700">
+            {orders.map((order) => (
+              <li key={order.id} className="py-3 flex items-center justify-between gap-4">
+                <div>
+                  <p className="font-medium text-white">
+                    {order.bookingReference ?? 'No booking reference yet'}
+                  </p>
+                  <p className="text-sm text-slate-400">
+                    Booked {formatDate(order.createdAt)} · {order.segments?.length ?? 1} flight(s)
+                  </p>
+                </div>
+                <div className="text-right">
+                  <Badge
+                    variant={order.status === 'confirmed' ? 'success' : order.status === 'failed' ? 'danger' : 'secondary'}
+                    className="capitalize"
+                  >
+                    {order.status.replace('_', ' ')}
+                  </Badge>
+                  <p className="text-sm text-slate-300 mt-1">{formatCurrency(order.totalAmount)}</p>
+                </div>
+              </li>
+            ))}
+          </ul>
+        )}
+      </CardContent>
+    </Card>
+  );
+}
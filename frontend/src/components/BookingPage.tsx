@@ -2,10 +2,11 @@ import { useState, useEffect, useCallback, useRef } from 'react';
 import { useParams, useNavigate } from 'react-router-dom';
 import { ArrowLeft, Check, X, Loader2, Plane, RefreshCw } from 'lucide-react';
 import { api } from '../api';
-import type { Flight, Seat, Order } from '../types';
+import type { Flight, Seat, SeatMapCabin, Order, PassengerRequest } from '../types';
 import { SeatMap } from './SeatMap';
 import { Timer } from './Timer';
 import { PaymentForm } from './PaymentForm';
+import { PassengerForm } from './PassengerForm';
 import { Button } from './ui/button';
 import { Card, CardHeader, CardTitle, CardDescription, CardContent, CardFooter } from './ui/card';
 import { Input } from './ui/input';
@@ -24,6 +25,7 @@ export function BookingPage() {
   
   const [flight, setFlight] = useState<Flight | null>(null);
   const [seats, setSeats] = useState<Seat[]>([]);
+  const [cabins, setCabins] = useState<SeatMapCabin[]>([]);
   const [selectedSeats, setSelectedSeats] = useState<string[]>([]);
   const [order, setOrder] = useState<Order | null>(null);
   const [remainingSeconds, setRemainingSeconds] = useState(0);
@@ -34,6 +36,7 @@ export function BookingPage() {
   const [customerInfo, setCustomerInfo] = useState({ name: '', email: '' });
   const [modifySeatsOpen, setModifySeatsOpen] = useState(false);
   const [paymentProcessing, setPaymentProcessing] = useState(false);
+  const [savingPassengers, setSavingPassengers] = useState(false);
   const lastPaymentAttempts = useRef(0);
 
   // Fetch flight and seats
@@ -41,9 +44,10 @@ export function BookingPage() {
     if (!flightId) return;
 
     Promise.all([api.getFlight(flightId), api.getFlightSeats(flightId)])
-      .then(([flightData, seatsData]) => {
+      .then(([flightData, seatMap]) => {
         setFlight(flightData);
-        setSeats(seatsData);
+        setSeats(seatMap.seats);
+        setCabins(seatMap.cabins);
       })
       .catch((err) => setError(err.message))
       .finally(() => setLoading(false));
@@ -65,8 +69,8 @@ export function BookingPage() {
       })
     );
     
-    // If another user held seats that we had selected, remove them from our selection
-    if (status === 'held' && wsOrderId && wsOrderId !== order?.id) {
+    // If another user held or booked seats that we had selected, remove them from our selection
+    if ((status === 'held' || status === 'booked') && wsOrderId && wsOrderId !== order?.id) {
       setSelectedSeats((prev) => {
         const conflicting = prev.filter((id) => seatIds.includes(id));
         if (conflicting.length > 0) {
@@ -77,6 +81,17 @@ export function BookingPage() {
         return prev;
       });
     }
+
+    // Seats blocked by an admin can no longer be selected
+    if (status === 'blocked') {
+      setSelectedSeats((prev) => {
+        if (prev.some((id) => seatIds.includes(id))) {
+          setError(`Some seats you selected were taken out of sale and have been removed from your selection.`);
+          return prev.filter((id) => !seatIds.includes(id));
+        }
+        return prev;
+      });
+    }
   }, [order?.id]);
 
   const handleSeatConflict = useCallback((seatIds: string[]) => {
@@ -97,6 +112,10 @@ export function BookingPage() {
       setStep('confirmed');
       // Update our order status
       setOrder((prev) => prev ? { ...prev, status: 'confirmed' } : null);
+      // Fetch the booking reference given on confirmation
+      api.getOrderStatus(wsOrderId)
+        .then((status) => setOrder(status.order))
+        .catch((err) => console.error('Failed to fetch booking reference:', err));
     } else {
       // Another user's order was completed - remove any of their booked seats from our selection
       setSelectedSeats((prev) => {
@@ -148,6 +167,16 @@ export function BookingPage() {
     );
   }, []);
 
+  const handleFlightStatus = useCallback(async () => {
+    // Reload the flight to pick up the reason and estimated times
+    if (!flightId) return;
+    try {
+      setFlight(await api.getFlight(flightId));
+    } catch (err) {
+      console.error('Failed to reload flight:', err);
+    }
+  }, [flightId]);
+
   // Connect WebSocket for real-time updates
   useFlightWebSocket({
     flightId,
@@ -157,6 +186,7 @@ export function BookingPage() {
     onOrderCompleted: handleOrderCompleted,
     onOrderExpired: handleOrderExpired,
     onSeatsReleased: handleSeatsReleased,
+    onFlightStatus: handleFlightStatus,
   });
 
   // Check order status after payment submission (one-time check, not polling)
@@ -254,6 +284,21 @@ export function BookingPage() {
     }
   };
 
+  const handleSavePassengers = async (passengers: PassengerRequest[]) => {
+    if (!order?.id) return;
+
+    setSavingPassengers(true);
+    setError(null);
+    try {
+      const status = await api.setPassengers(order.id, passengers);
+      setOrder(status.order);
+    } catch (err) {
+      setError(err instanceof Error ? err.message : 'Failed to save passengers');
+    } finally {
+      setSavingPassengers(false);
+    }
+  };
+
   const handlePayment = async (paymentCode: string) => {
     if (!order?.id || submitting || paymentProcessing) return;
 
@@ -302,13 +347,17 @@ export function BookingPage() {
 
   const totalAmount = selectedSeats.reduce((sum, seatId) => {
     const seat = seats.find((s) => s.id === seatId);
-    return sum + (seat?.price || 0);
+    return sum + (seat ? seat.price + (seat.surcharge || 0) : 0);
   }, 0);
 
   // Get seats held by the current user's order
-  const ownHeldSeats = seats
-    .filter((seat) => seat.heldByOrder === order?.id)
-    .map((seat) => seat.id);
+  const ownHeldSeatList = seats.filter((seat) => seat.heldByOrder === order?.id);
+  const ownHeldSeats = ownHeldSeatList.map((seat) => seat.id);
+
+  // Every seat needs a passenger before payment
+  const passengersComplete =
+    ownHeldSeatList.length > 0 &&
+    ownHeldSeatList.every((seat) => order?.passengers?.some((p) => p.seats?.some((s) => s.id === seat.id)));
 
   if (loading) {
     return (
@@ -346,8 +395,14 @@ export function BookingPage() {
           </Button>
           <h1 className="text-2xl sm:text-3xl font-bold text-white flex items-center gap-2">
             <Plane className="w-6 h-6 text-cyan-500" />
-            {flight?.flightNumber}: {flight?.origin.split('(')[0]} → {flight?.destination.split('(')[0]}
+            {flight?.flightNumber}: {flight?.originAirport?.city ?? flight?.origin} → {flight?.destinationAirport?.city ?? flight?.destination}
           </h1>
+          {flight && flight.status !== 'scheduled' && (
+            <Badge variant={flight.status === 'cancelled' ? 'danger' : 'warning'} className="mt-2 capitalize">
+              {flight.status}
+              {flight.statusReason && ` – ${flight.statusReason}`}
+            </Badge>
+          )}
         </div>
 
         {order && !['confirmed', 'failed'].includes(step) && (
@@ -464,6 +519,7 @@ export function BookingPage() {
               <CardContent>
                 <SeatMap
                   seats={seats}
+                  cabins={cabins}
                   selectedSeats={selectedSeats}
                   onSeatSelect={handleSeatSelect}
                   ownHeldSeats={ownHeldSeats}
@@ -491,12 +547,21 @@ export function BookingPage() {
           {/* Payment Step */}
           {step === 'payment' && (
             <div className="space-y-6">
-              <PaymentForm
-                onSubmit={handlePayment}
-                loading={paymentProcessing}
-                attempts={order?.paymentAttempts || 0}
-                maxAttempts={3}
+              <PassengerForm
+                seats={ownHeldSeatList}
+                passengers={order?.passengers}
+                onSubmit={handleSavePassengers}
+                loading={savingPassengers}
               />
+
+              {passengersComplete && (
+                <PaymentForm
+                  onSubmit={handlePayment}
+                  loading={paymentProcessing}
+                  attempts={order?.paymentAttempts || 0}
+                  maxAttempts={3}
+                />
+              )}
               
               {/* Option to modify seats */}
               <Card>
@@ -519,6 +584,7 @@ export function BookingPage() {
                     <div className="mt-4">
                       <SeatMap
                         seats={seats}
+                        cabins={cabins}
                         selectedSeats={selectedSeats}
                         onSeatSelect={handleSeatSelect}
                         ownHeldSeats={ownHeldSeats}
@@ -547,9 +613,12 @@ export function BookingPage() {
                 </div>
                 <h2 className="text-3xl font-bold text-white mb-2">Booking Confirmed!</h2>
                 <p className="text-slate-400 mb-4">Your flight has been successfully booked.</p>
-                <Badge variant="success" className="text-lg px-4 py-2 mb-8">
-                  Order ID: {order?.id}
+                <Badge variant="success" className="text-lg px-4 py-2 mb-2">
+                  {order?.bookingReference ? `Booking Reference: ${order.bookingReference}` : `Order ID: ${order?.id}`}
                 </Badge>
+                <p className="text-sm text-slate-400 mb-8">
+                  Use your booking reference and last name to find your booking later.
+                </p>
                 <Button variant="success" onClick={() => navigate('/')}>
                   Book Another Flight
                 </Button>
@@ -587,7 +656,7 @@ export function BookingPage() {
               </div>
               <div className="flex justify-between text-sm">
                 <span className="text-slate-400">Route</span>
-                <span className="text-white">{flight?.origin.split('(')[0]} → {flight?.destination.split('(')[0]}</span>
+                <span className="text-white">{flight?.originAirport?.city ?? flight?.origin} → {flight?.destinationAirport?.city ?? flight?.destination}</span>
               </div>
               {selectedSeats.length > 0 && (
                 <div className="flex justify-between text-sm">
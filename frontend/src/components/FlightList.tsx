@@ -7,7 +7,7 @@ import { Card, CardContent } from './ui/card';
 import { Button } from './ui/button';
 import { Badge } from './ui/badge';
 import { Alert, AlertDescription } from './ui/alert';
-import { formatTime, formatDate, formatCurrency } from '../lib/utils';
+import { formatTime, formatDate, formatCurrency, formatAirport } from '../lib/utils';
 
 export function FlightList() {
   const [flights, setFlights] = useState<Flight[]>([]);
@@ -63,22 +63,27 @@ export function FlightList() {
                     <Badge variant="default">{flight.flightNumber}</Badge>
                     <Badge variant="secondary">
                       <Clock className="w-3 h-3 mr-1" />
-                      {formatDate(flight.departureTime)}
+                      {formatDate(flight.departureTime, flight.originAirport?.timeZone)}
                     </Badge>
                     <Badge variant="secondary">
                       <Users className="w-3 h-3 mr-1" />
                       {flight.availableSeats} seats left
                     </Badge>
+                    {flight.status === 'delayed' && flight.estimatedDepartureTime && (
+                      <Badge variant="warning">
+                        Delayed to {formatTime(flight.estimatedDepartureTime, flight.originAirport?.timeZone)}
+                      </Badge>
+                    )}
                   </div>
 
                   {/* Route */}
                   <div className="flex items-center gap-3 sm:gap-6">
                     <div className="text-center sm:text-left">
                       <p className="text-xl sm:text-2xl font-bold text-white">
-                        {formatTime(flight.departureTime)}
+                        {formatTime(flight.departureTime, flight.originAirport?.timeZone)}
                       </p>
                       <p className="text-sm text-slate-400 truncate max-w-[120px] sm:max-w-none">
-                        {flight.origin}
+                        {formatAirport(flight.origin, flight.originAirport)}
                       </p>
                     </div>
 
@@ -90,10 +95,10 @@ export function FlightList() {
 
                     <div className="text-center sm:text-right">
                       <p className="text-xl sm:text-2xl font-bold text-white">
-                        {formatTime(flight.arrivalTime)}
+                        {formatTime(flight.arrivalTime, flight.destinationAirport?.timeZone)}
                       </p>
                       <p className="text-sm text-slate-400 truncate max-w-[120px] sm:max-w-none">
-                        {flight.destination}
+                        {formatAirport(flight.destination, flight.destinationAirport)}
                       </p>
                     </div>
                   </div>
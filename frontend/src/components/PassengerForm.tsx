@@ -0,0 +1,122 @@
+import { useState, useEffect } from 'react';
+import { Loader2, Users } from 'lucide-react';
+import type { Passenger, PassengerRequest, PassengerType, Seat } from '../types';
+import { Button } from './ui/button';
+import { Card, CardHeader, CardTitle, CardDescription, CardContent } from './ui/card';
+import { Input } from './ui/input';
+
+interface PassengerFormProps {
+  seats: Seat[];
+  passengers?: Passenger[];
+  onSubmit: (passengers: PassengerRequest[]) => void;
+  loading: boolean;
+}
+
+interface PassengerFields {
+  firstName: string;
+  lastName: string;
+  dateOfBirth: string;
+  type: PassengerType;
+}
+
+const emptyFields: PassengerFields = { firstName: '', lastName: '', dateOfBirth: '', type: 'adult' };
+
+// fieldsFor prefills a seat's fields from the passenger already sitting in it
+function fieldsFor(seat: Seat, passengers?: Passenger[]): PassengerFields {
+  const passenger = passengers?.find((p) => p.seats?.some((s) => s.id === seat.id));
+  if (!passenger) return emptyFields;
+  return {
+    firstName: passenger.firstName,
+    lastName: passenger.lastName,
+    dateOfBirth: passenger.dateOfBirth,
+    type: passenger.type,
+  };
+}
+
+export function PassengerForm({ seats, passengers, onSubmit, loading }: PassengerFormProps) {
+  const [fields, setFields] = useState<PassengerFields[]>(() => seats.map((seat) => fieldsFor(seat, passengers)));
+
+  // Keep one passenger per seat when seats are changed
+  useEffect(() => {
+    setFields(seats.map((seat) => fieldsFor(seat, passengers)));
+  }, [seats.map((seat) => seat.id).join(','), passengers]);
+
+  const update = (index: number, change: Partial<PassengerFields>) => {
+    setFields((prev) => prev.map((f, i) => (i === index ? { ...f, ...change } : f)));
+  };
+
+  const handleSubmit = (e: React.FormEvent) => {
+    e.preventDefault();
+    onSubmit(fields.map((f, i) => ({ ...f, seatIds: [seats[i].id] })));
+  };
+
+  const isComplete = fields.every((f) => f.firstName.trim() && f.lastName.trim() && f.dateOfBirth);
+
+  return (
+    <Card>
+      <CardHeader>
+        <CardTitle className="flex items-center gap-2">
+          <Users className="w-5 h-5 text-cyan-500" />
+          Passengers
+        </CardTitle>
+        <CardDescription>
+          Enter the details of the passenger in each seat, as shown on their travel document
+        </CardDescription>
+      </CardHeader>
+      <CardContent>
+        <form onSubmit={handleSubmit} className="space-y-6">
+          {seats.map((seat, index) => (
+            <div key={seat.id} className="space-y-2">
+              <p className="text-sm font-medium text-white">Seat {seat.seatNumber}</p>
+              <div className="grid sm:grid-cols-2 gap-2">
+                <Input
+                  type="text"
+                  value={fields[index]?.firstName ?? ''}
+                  onChange={(e) => update(index, { firstName: e.target.value })}
+                  placeholder="First name"
+                  aria-label={`First name, seat ${seat.seatNumber}`}
+                  required
+                />
+                <Input
+                  type="text"
+                  value={fields[index]?.lastName ?? ''}
+                  onChange={(e) => update(index, { lastName: e.target.value })}
+                  placeholder="Last name"
+                  aria-label={`Last name, seat ${seat.seatNumber}`}
+                  required
+                />
+                <Input
+                  type="date"
+                  value={fields[index]?.dateOfBirth ?? ''}
+                  onChange={(e) => update(index, { dateOfBirth: e.target.value })}
+                  aria-label={`Date of birth, seat ${seat.seatNumber}`}
+                  required
+                />
+                <select
+                  value={fields[index]?.type ?? 'adult'}
+                  onChange={(e) => update(index, { type: e.target.value as PassengerType })}
+                  aria-label={`Passenger type, seat ${seat.seatNumber}`}
+                  className="h-10 rounded-lg bg-slate-700/50 border border-slate-600 px-3 text-white"
+                >
+                  <option value="adult">Adult (12+)</option>
+                  <option value="child">Child (2-11)</option>
+                </select>
+              </div>
+            </div>
+          ))}
+
+          <Button type="submit" className="w-full" disabled={!isComplete || loading}>
+            {loading ? (
+              <>
+                <Loader2 className="w-4 h-4 mr-2 animate-spin" />
+                Saving Passengers...
+              </>
+            ) : (
+              'Save Passengers'
+            )}
+          </Button>
+        </form>
+      </CardContent>
+    </Card>
+  );
+}
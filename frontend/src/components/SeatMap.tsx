@@ -1,25 +1,74 @@
-import { useMemo } from 'react';
-import type { Seat } from '../types';
+import { Fragment, useMemo } from 'react';
+import type { Seat, SeatMapCabin } from '../types';
 import { cn } from '../lib/utils';
 
+// Seat tooltip: number, price including surcharges and attributes
+function seatTitle(seat: Seat, exitRow?: boolean): string {
+  const attributes = seat.attributes?.length
+    ? seat.attributes.map((a) => a.replace('_', ' '))
+    : exitRow ? ['exit row'] : [];
+  const price = seat.price + (seat.surcharge || 0);
+  return `Seat ${seat.row}${seat.column} - $${price}${attributes.length ? ` (${attributes.join(', ')})` : ''}`;
+}
+
 interface SeatMapProps {
   seats: Seat[];
+  cabins?: SeatMapCabin[]; // Layout from the seat map API; derived from seats when omitted
   selectedSeats: string[];
   onSeatSelect: (seatId: string) => void;
   ownHeldSeats?: string[]; // Seat IDs held by the current user's order
 }
 
-export function SeatMap({ seats, selectedSeats, onSeatSelect, ownHeldSeats = [] }: SeatMapProps) {
-  // Group seats by row
-  const seatsByRow = useMemo(() => {
-    return seats.reduce((acc, seat) => {
-      if (!acc[seat.row]) acc[seat.row] = [];
-      acc[seat.row].push(seat);
-      return acc;
-    }, {} as Record<number, Seat[]>);
-  }, [seats]);
+const CLASS_LABELS: Record<Seat['class'], string> = {
+  first: 'First',
+  business: 'Business',
+  premium: 'Premium Economy',
+  economy: 'Economy',
+};
+
+// deriveCabins lays out seats without a cabin layout: one cabin per class,
+// ordered by first row, with all of the class's columns in a single group
+export function deriveCabins(seats: Seat[]): SeatMapCabin[] {
+  const byClass = new Map<Seat['class'], Seat[]>();
+  [...seats]
+    .sort((a, b) => a.row - b.row)
+    .forEach((seat) => {
+      byClass.set(seat.class, [...(byClass.get(seat.class) ?? []), seat]);
+    });
+
+  return Array.from(byClass.entries()).map(([cls, classSeats]) => {
+    const columns = Array.from(new Set(classSeats.map((s) => s.column))).sort();
+    const rows = Array.from(new Set(classSeats.map((s) => s.row))).sort((a, b) => a - b);
+    return {
+      class: cls,
+      columnGroups: [columns.join('')],
+      rows: rows.map((row) => ({
+        row,
+        seats: columns.map(
+          (column) => classSeats.find((s) => s.row === row && s.column === column)?.id ?? null
+        ),
+      })),
+    };
+  });
+}
+
+// splitIntoGroups splits a row's seats into the cabin's column groups
+function splitIntoGroups<T>(items: T[], columnGroups: string[]): T[][] {
+  let offset = 0;
+  return columnGroups.map((group) => {
+    const slice = items.slice(offset, offset + group.length);
+    offset += group.length;
+    return slice;
+  });
+}
+
+export function SeatMap({ seats, cabins, selectedSeats, onSeatSelect, ownHeldSeats = [] }: SeatMapProps) {
+  const seatsById = useMemo(() => new Map(seats.map((seat) => [seat.id, seat])), [seats]);
 
-  const rows = Object.keys(seatsByRow).map(Number).sort((a, b) => a - b);
+  const layout = useMemo(
+    () => (cabins && cabins.length > 0 ? cabins : deriveCabins(seats)),
+    [cabins, seats]
+  );
 
   const isOwnHeldSeat = (seat: Seat) => {
     return ownHeldSeats.includes(seat.id);
@@ -31,6 +80,7 @@ export function SeatMap({ seats, selectedSeats, onSeatSelect, ownHeldSeats = []
     if (isOwnHeldSeat(seat)) return 'seat-held seat-own';
     if (seat.status === 'held') return 'seat-held';
     if (seat.status === 'booked') return 'seat-booked';
+    if (seat.status === 'blocked') return 'seat-blocked';
     return 'seat-available';
   };
 
@@ -46,8 +96,8 @@ export function SeatMap({ seats, selectedSeats, onSeatSelect, ownHeldSeats = []
     if (selectedSeats.includes(seat.id)) return false;
     // Allow clicking on own held seats (can re-select them)
     if (isOwnHeldSeat(seat)) return false;
-    // Disable booked and other users' held seats
-    return seat.status === 'booked' || seat.status === 'held';
+    // Disable booked, blocked and other users' held seats
+    return seat.status === 'booked' || seat.status === 'held' || seat.status === 'blocked';
   };
 
   return (
@@ -70,6 +120,10 @@ export function SeatMap({ seats, selectedSeats, onSeatSelect, ownHeldSeats = []
           <div className="seat seat-booked w-6 h-6 sm:w-8 sm:h-8" />
           <span className="text-slate-400">Booked</span>
         </div>
+        <div className="flex items-center gap-2">
+          <div className="seat seat-blocked w-6 h-6 sm:w-8 sm:h-8" />
+          <span className="text-slate-400">Blocked</span>
+        </div>
       </div>
 
       {/* Airplane nose indicator */}
@@ -79,73 +133,77 @@ export function SeatMap({ seats, selectedSeats, onSeatSelect, ownHeldSeats = []
         </div>
       </div>
 
-      {/* Seat grid */}
-      <div className="space-y-2 max-h-[400px] overflow-y-auto px-2 py-4">
-        {rows.slice(0, 15).map((rowNum) => {
-          const rowSeats = seatsByRow[rowNum].sort((a, b) => a.column.localeCompare(b.column));
-          const leftSeats = rowSeats.slice(0, 3);
-          const rightSeats = rowSeats.slice(3);
-
-          return (
-            <div key={rowNum} className="flex items-center justify-center gap-2 sm:gap-4">
-              {/* Left side (A, B, C) */}
-              <div className="flex gap-1 sm:gap-2">
-                {leftSeats.map((seat) => (
-                  <button
-                    key={seat.id}
-                    onClick={() => handleSeatClick(seat)}
-                    disabled={isSeatDisabled(seat)}
-                    className={cn("seat", getSeatClass(seat))}
-                    title={`Seat ${seat.row}${seat.column} - $${seat.price}`}
-                    aria-label={`Seat ${seat.row}${seat.column}, ${seat.status}`}
-                  >
-                    {seat.column}
-                  </button>
-                ))}
-              </div>
+      {/* Cabins */}
+      <div className="space-y-6 max-h-[400px] overflow-y-auto px-2 py-4">
+        {layout.map((cabin, cabinIndex) => (
+          <section key={`${cabin.class}-${cabinIndex}`} className="space-y-2" aria-label={`${CLASS_LABELS[cabin.class] ?? cabin.class} cabin`}>
+            <h3 className="text-center text-xs uppercase tracking-wider text-slate-400">
+              {CLASS_LABELS[cabin.class] ?? cabin.class}
+            </h3>
 
-              {/* Aisle / Row number */}
-              <div className="w-8 sm:w-10 text-center text-slate-500 text-sm font-medium">
-                {rowNum}
-              </div>
+            {/* Column labels */}
+            <div className="flex items-center justify-center gap-2 sm:gap-4">
+              <div className="w-8 sm:w-10" />
+              {cabin.columnGroups.map((group, groupIndex) => (
+                <Fragment key={group}>
+                  {groupIndex > 0 && <div className="w-4 sm:w-6" />}
+                  <div className="flex gap-1 sm:gap-2">
+                    {group.split('').map((col) => (
+                      <div key={col} className="w-10 sm:w-12 text-center text-slate-500 text-xs font-medium">
+                        {col}
+                      </div>
+                    ))}
+                  </div>
+                </Fragment>
+              ))}
+              <div className="w-8 sm:w-10" />
+            </div>
 
-              {/* Right side (D, E, F) */}
-              <div className="flex gap-1 sm:gap-2">
-                {rightSeats.map((seat) => (
-                  <button
-                    key={seat.id}
-                    onClick={() => handleSeatClick(seat)}
-                    disabled={isSeatDisabled(seat)}
-                    className={cn("seat", getSeatClass(seat))}
-                    title={`Seat ${seat.row}${seat.column} - $${seat.price}`}
-                    aria-label={`Seat ${seat.row}${seat.column}, ${seat.status}`}
-                  >
-                    {seat.column}
-                  </button>
+            {cabin.rows.map((row) => (
+              <div
+                key={row.row}
+                className={cn('flex items-center justify-center gap-2 sm:gap-4', row.exitRow && 'border-y border-emerald-700/40 py-1')}
+                data-exit-row={row.exitRow || undefined}
+              >
+                {/* Row number */}
+                <div className="w-8 sm:w-10 text-center text-slate-500 text-sm font-medium">{row.row}</div>
+
+                {splitIntoGroups(row.seats, cabin.columnGroups).map((groupSeats, groupIndex) => (
+                  <Fragment key={groupIndex}>
+                    {/* Aisle */}
+                    {groupIndex > 0 && <div className="w-4 sm:w-6" />}
+                    <div className="flex gap-1 sm:gap-2">
+                      {groupSeats.map((seatId, i) => {
+                        const seat = seatId ? seatsById.get(seatId) : undefined;
+                        if (!seat) {
+                          // No seat installed at this position
+                          return <div key={`gap-${i}`} className="w-10 h-10 sm:w-12 sm:h-12" aria-hidden="true" />;
+                        }
+                        return (
+                          <button
+                            key={seat.id}
+                            onClick={() => handleSeatClick(seat)}
+                            disabled={isSeatDisabled(seat)}
+                            className={cn('seat', getSeatClass(seat))}
+                            title={seatTitle(seat, row.exitRow)}
+                            aria-label={`Seat ${seat.row}${seat.column}, ${seat.status}`}
+                          >
+                            {seat.column}
+                          </button>
+                        );
+                      })}
+                    </div>
+                  </Fragment>
                 ))}
-              </div>
-            </div>
-          );
-        })}
-      </div>
 
-      {/* Column labels */}
-      <div className="flex items-center justify-center gap-2 sm:gap-4 pt-4 border-t border-slate-700">
-        <div className="flex gap-1 sm:gap-2">
-          {['A', 'B', 'C'].map((col) => (
-            <div key={col} className="w-10 sm:w-12 text-center text-slate-500 text-xs font-medium">
-              {col}
-            </div>
-          ))}
-        </div>
-        <div className="w-8 sm:w-10" />
-        <div className="flex gap-1 sm:gap-2">
-          {['D', 'E', 'F'].map((col) => (
-            <div key={col} className="w-10 sm:w-12 text-center text-slate-500 text-xs font-medium">
-              {col}
-            </div>
-          ))}
-        </div>
+                {/* Exit row marker */}
+                <div className="w-8 sm:w-10 text-center text-[10px] font-semibold text-emerald-400">
+                  {row.exitRow ? 'EXIT' : null}
+                </div>
+              </div>
+            ))}
+          </section>
+        ))}
       </div>
     </div>
   );
@@ -5,7 +5,8 @@ export type WebSocketMessageType =
   | 'seat_conflict' 
   | 'order_completed' 
   | 'order_expired'
-  | 'seats_released';
+  | 'seats_released'
+  | 'flight_status';
 
 export interface WebSocketMessage {
   type: WebSocketMessageType;
@@ -24,6 +25,7 @@ interface UseFlightWebSocketOptions {
   onOrderCompleted?: (orderId: string, seatIds: string[]) => void;
   onOrderExpired?: (orderId: string, seatIds: string[]) => void;
   onSeatsReleased?: (seatIds: string[], orderId?: string) => void;
+  onFlightStatus?: (status: string) => void;
 }
 
 export function useFlightWebSocket({
@@ -34,6 +36,7 @@ export function useFlightWebSocket({
   onOrderCompleted,
   onOrderExpired,
   onSeatsReleased,
+  onFlightStatus,
 }: UseFlightWebSocketOptions) {
   const wsRef = useRef<WebSocket | null>(null);
   const reconnectTimeoutRef = useRef<NodeJS.Timeout | null>(null);
@@ -102,6 +105,12 @@ export function useFlightWebSocket({
                 onSeatsReleased?.(message.seatIds, message.orderId);
               }
               break;
+
+            case 'flight_status':
+              if (message.status) {
+                onFlightStatus?.(message.status);
+              }
+              break;
           }
         }
       } catch (err) {
@@ -125,7 +134,7 @@ export function useFlightWebSocket({
         reconnectTimeoutRef.current = setTimeout(connect, delay);
       }
     };
-  }, [flightId, orderId, onSeatsUpdated, onSeatConflict, onOrderCompleted, onOrderExpired, onSeatsReleased]);
+  }, [flightId, orderId, onSeatsUpdated, onSeatConflict, onOrderCompleted, onOrderExpired, onSeatsReleased, onFlightStatus]);
 
   useEffect(() => {
     connect();
@@ -5,21 +5,29 @@ export function cn(...inputs: ClassValue[]) {
   return twMerge(clsx(inputs));
 }
 
-export function formatTime(dateString: string): string {
+// Times are shown in the given IANA time zone (e.g. the airport's), or in
+// the browser's time zone when none is given
+export function formatTime(dateString: string, timeZone?: string): string {
   return new Date(dateString).toLocaleTimeString('en-US', { 
     hour: '2-digit', 
-    minute: '2-digit' 
+    minute: '2-digit',
+    timeZone,
   });
 }
 
-export function formatDate(dateString: string): string {
+export function formatDate(dateString: string, timeZone?: string): string {
   return new Date(dateString).toLocaleDateString('en-US', { 
     weekday: 'short', 
     month: 'short', 
-    day: 'numeric' 
+    day: 'numeric',
+    timeZone,
   });
 }
 
+export function formatAirport(code: string, airport?: { city: string }): string {
+  return airport ? `${airport.city} (${code})` : code;
+}
+
 export function formatCurrency(amount: number): string {
   return new Intl.NumberFormat('en-US', {
     style: 'currency',
@@ -1,26 +1,213 @@
+export interface Airport {
+  iataCode: string;
+  icaoCode: string;
+  name: string;
+  city: string;
+  country: string;
+  timeZone: string;
+  latitude: number;
+  longitude: number;
+}
+
 export interface Flight {
   id: string;
   flightNumber: string;
   origin: string;
   destination: string;
+  originAirport?: Airport;
+  destinationAirport?: Airport;
   departureTime: string;
   arrivalTime: string;
+  departureTimeLocal?: string;
+  arrivalTimeLocal?: string;
   totalSeats: number;
   availableSeats: number;
   pricePerSeat: number;
+  status: FlightStatus;
+  statusReason?: string;
+  estimatedDepartureTime?: string; // set while the flight is delayed
+  estimatedArrivalTime?: string;
 }
 
+export type FlightStatus =
+  | 'scheduled'
+  | 'delayed'
+  | 'cancelled'
+  | 'boarding'
+  | 'departed'
+  | 'arrived';
+
 export interface Seat {
   id: string;
   flightId: string;
   row: number;
   column: string;
-  class: 'economy' | 'business' | 'first';
-  status: 'available' | 'held' | 'booked';
-  price: number;
+  class: 'economy' | 'premium' | 'business' | 'first';
+  attributes?: SeatAttribute[];
+  status: 'available' | 'held' | 'booked' | 'blocked';
+  price: number; // Fare of the seat's class
+  surcharge?: number; // Added to the price for the seat's attributes
   heldByOrder?: string | null;
 }
 
+export type SeatAttribute = 'window' | 'aisle' | 'extra_legroom' | 'exit_row' | 'near_lavatory';
+
+export interface AutoAssignRequest {
+  partySize: number;
+  class?: Seat['class'];
+  preferences?: SeatAttribute[];
+}
+
+export interface OverbookRequest {
+  class: Seat['class'];
+  partySize: number;
+}
+
+// A traveler booked without a physical seat; seatId and seatNumber are set
+// once a seat is assigned at check-in or boarding
+export interface OverbookedSeat {
+  id: string;
+  flightId: string;
+  class: Seat['class'];
+  price: number;
+  status: 'unassigned' | 'assigned' | 'denied';
+  seatId?: string;
+  seatNumber?: string;
+  assignedAt?: string;
+}
+
+export type PassengerType = 'adult' | 'child' | 'infant';
+
+// A passport or national ID card; country is a two-letter country code
+export interface TravelDocument {
+  type: 'passport' | 'national_id';
+  number: string;
+  country: string;
+  expiresOn?: string;
+}
+
+// A seat a passenger sits in. For travelers booked without a seat, id is the
+// overbooked seat and seatNumber is only set once a seat is assigned.
+export interface PassengerSeat {
+  id: string;
+  flightId: string;
+  seatNumber?: string;
+}
+
+export interface Passenger {
+  id: string;
+  firstName: string;
+  lastName: string;
+  dateOfBirth: string; // YYYY-MM-DD
+  type: PassengerType;
+  document?: TravelDocument;
+  seats?: PassengerSeat[];
+  cancelledAt?: string; // set when the passenger was cancelled from a confirmed order
+}
+
+// Details of a passenger and the seats of the order they sit in, one per
+// flight; infants have no seat
+export interface PassengerRequest {
+  firstName: string;
+  lastName: string;
+  dateOfBirth: string;
+  type: PassengerType;
+  document?: TravelDocument;
+  seatIds: string[];
+}
+
+// A seat change of a confirmed order. A positive priceDifference is charged,
+// a negative one credited back.
+export interface SeatExchange {
+  id: string;
+  orderId: string;
+  oldSeatIds: string[];
+  newSeatIds: string[];
+  oldSeats: string[];
+  newSeats: string[];
+  priceDifference: number;
+  status: 'pending' | 'completed' | 'failed';
+  workflowId: string;
+  transactionId?: string;
+  creditId?: string;
+  failureReason?: string;
+  holdExpiresAt: string;
+  createdAt: string;
+  completedAt?: string;
+}
+
+// A move of a confirmed order to another flight on the same route. The fare
+// difference and change fee are known once seats on the new flight are held;
+// a positive total is charged, a negative one refunded.
+export interface FlightExchange {
+  id: string;
+  orderId: string;
+  fromFlightId: string;
+  toFlightId: string;
+  oldSeats: string[];
+  newSeats: string[];
+  fareDifference?: number;
+  changeFee?: number;
+  status: 'pending' | 'held' | 'confirmed' | 'completed' | 'failed';
+  workflowId: string;
+  transactionId?: string;
+  refundId?: string;
+  failureReason?: string;
+  holdExpiresAt: string;
+  createdAt: string;
+  completedAt?: string;
+}
+
+// The refund of a cancellation of a confirmed order: of the listed
+// passengers, or of the whole order when passengerIds is empty
+export interface OrderRefund {
+  id: string;
+  orderId: string;
+  passengerIds: string[];
+  fareAmount: number;
+  refundPercent: number;
+  amount: number;
+  status: 'pending' | 'completed' | 'failed';
+  workflowId?: string;
+  providerRefundId?: string;
+  failureReason?: string;
+  createdAt: string;
+  completedAt?: string;
+}
+
+export interface SeatSwap {
+  fromSeatId: string;
+  toSeatId: string;
+}
+
+export interface AircraftType {
+  code: string;
+  manufacturer: string;
+  model: string;
+}
+
+// A row of a seat map cabin. seats is aligned with the cabin's columns and
+// holds a seat ID, or null where no seat is installed.
+export interface SeatMapRow {
+  row: number;
+  exitRow?: boolean;
+  seats: (string | null)[];
+}
+
+export interface SeatMapCabin {
+  class: Seat['class'];
+  columnGroups: string[]; // seat columns between aisles, e.g. ["ABC", "DEF"]
+  rows: SeatMapRow[];
+}
+
+export interface SeatMap {
+  flightId: string;
+  cabinLayout: string;
+  aircraftType?: AircraftType;
+  cabins: SeatMapCabin[];
+  seats: Seat[];
+}
+
 export type OrderStatus =
   | 'pending'
   | 'seats_selected'
@@ -29,21 +216,83 @@ export type OrderStatus =
   | 'confirmed'
   | 'failed'
   | 'cancelled'
-  | 'expired';
+  | 'expired'
+  | 'refunded';
 
 export interface Order {
   id: string;
+  bookingReference?: string; // given when the order is confirmed
+  customerId?: string; // the account the order was placed with; absent for guest checkout
   flightId: string;
   customerEmail: string;
   customerName: string;
   seats: string[];
   status: OrderStatus;
   totalAmount: number;
+  refundedAmount: number;
   paymentAttempts: number;
   seatHoldExpiry: string;
   createdAt: string;
   updatedAt: string;
   failureReason?: string;
+  segments?: OrderSegment[];
+  passengers?: Passenger[];
+  overbookedSeats?: OverbookedSeat[];
+  seatExchanges?: SeatExchange[];
+  flightExchanges?: FlightExchange[];
+  refunds?: OrderRefund[];
+}
+
+// A customer account
+export interface Customer {
+  id: string;
+  email: string;
+  name: string;
+  createdAt: string;
+}
+
+// A signed-in session; token is sent as a bearer token
+export interface Session {
+  token: string;
+  expiresAt: string;
+  customer: Customer;
+}
+
+// A confirmed order found by its booking reference and a last name
+export interface Booking {
+  reference: string;
+  status: OrderStatus;
+  customerName: string;
+  totalAmount: number;
+  bookedAt: string;
+  flights: BookingFlight[];
+  passengers?: BookingPassenger[];
+}
+
+export interface BookingFlight {
+  flightNumber: string;
+  origin: string;
+  destination: string;
+  departureTime: string;
+  arrivalTime: string;
+  departureTimeLocal?: string;
+  arrivalTimeLocal?: string;
+  status: FlightStatus;
+  seats?: string[];
+}
+
+export interface BookingPassenger {
+  firstName: string;
+  lastName: string;
+  type: PassengerType;
+  seats?: string[];
+}
+
+// One flight of an order, in travel order
+export interface OrderSegment {
+  flightId: string;
+  segmentIndex: number;
+  seats?: string[];
 }
 
 export interface OrderStatusResponse {
@@ -52,3 +301,57 @@ export interface OrderStatusResponse {
   message?: string;
 }
 
+export interface SeatChange {
+  seatId: string;
+  flightId: string;
+  seatNumber?: string;
+  operation: 'add' | 'remove';
+  status: 'held' | 'released' | 'unchanged' | 'unavailable' | 'blocked' | 'not_held' | 'wrong_flight' | 'not_found';
+}
+
+export interface ChangeSeatsResponse extends OrderStatusResponse {
+  results: SeatChange[];
+}
+
+
+export type RebookingOfferStatus =
+  | 'pending'
+  | 'accepted'
+  | 'declined'
+  | 'expired'
+  | 'no_alternatives';
+
+// Offered to a confirmed order when one of its flights is cancelled
+export interface RebookingOffer {
+  id: string;
+  orderId: string;
+  flightId: string;
+  alternativeFlightIds: string[];
+  alternatives?: Flight[];
+  status: RebookingOfferStatus;
+  rebookedFlightId?: string;
+  failureReason?: string;
+  expiresAt: string;
+  respondedAt?: string;
+  createdAt: string;
+}
+
+export type WaitlistStatus = 'waiting' | 'offered' | 'accepted' | 'expired' | 'cancelled';
+
+// A customer waiting for seats of a class on a sold-out flight. Once seats
+// are offered, orderId holds them until offerExpiresAt.
+export interface WaitlistEntry {
+  id: string;
+  flightId: string;
+  class: string;
+  customerName: string;
+  customerEmail: string;
+  partySize: number;
+  status: WaitlistStatus;
+  position?: number;
+  orderId?: string;
+  offeredAt?: string;
+  offerExpiresAt?: string;
+  createdAt: string;
+  updatedAt: string;
+}
@@ -37,7 +37,8 @@ type ValidatePaymentOutput struct {
 }
 
 // ValidatePayment validates a payment code (simulated)
-// 85% success rate, must complete within 10 seconds
+// 85% success rate, must complete within 10 seconds. Seats are booked
+// separately by ConfirmBooking once the payment succeeds.
 func (a *Activities) ValidatePayment(ctx context.Context, input ValidatePaymentInput) (*ValidatePaymentOutput, error) {
 	logger := activity.GetLogger(ctx)
 	logger.Info("Validating payment", "orderId", input.OrderID, "attempt", input.Attempt)
@@ -47,12 +48,43 @@ func (a *Activities) ValidatePayment(ctx context.Context, input ValidatePaymentI
 		return nil, fmt.Errorf("invalid order ID: %w", err)
 	}
 
+	result := simulatePayment(ctx, input.OrderID, input.PaymentCode)
+	if result.ErrorMessage == invalidPaymentCodeMessage {
+		return result, nil
+	}
+	payload := paymentEventPayload(result, 0)
+	payload["attempt"] = input.Attempt
+	a.recordOrderEvent(ctx, input.OrderID, repository.OrderEventPaymentAttempted, payload)
+	if result.Success {
+		return result, nil
+	}
+
+	// Payment failed - update attempts
+	failureReason := "Payment validation failed"
+	if err := a.repo.UpdateOrderPayment(ctx, orderID, input.Attempt, &failureReason); err != nil {
+		logger.Warn("Failed to update payment attempts", "error", err)
+	}
+
+	logger.Info("Payment failed", "attempt", input.Attempt)
+	return result, nil
+}
+
+// invalidPaymentCodeMessage is reported for payment codes that are not five
+// characters long
+const invalidPaymentCodeMessage = "Invalid payment code format"
+
+// simulatePayment charges a payment code (simulated): the code must be five
+// characters long, and 85% of payments succeed after 1-3 seconds. The
+// transaction ID is derived from reference, e.g. the order ID.
+func simulatePayment(ctx context.Context, reference, paymentCode string) *ValidatePaymentOutput {
+	logger := activity.GetLogger(ctx)
+
 	// Validate payment code format
-	if len(input.PaymentCode) != 5 {
+	if len(paymentCode) != 5 {
 		return &ValidatePaymentOutput{
 			Success:      false,
-			ErrorMessage: "Invalid payment code format",
-		}, nil
+			ErrorMessage: invalidPaymentCodeMessage,
+		}
 	}
 
 	// Simulate payment processing time (1-3 seconds)
@@ -60,37 +92,61 @@ func (a *Activities) ValidatePayment(ctx context.Context, input ValidatePaymentI
 	time.Sleep(processingTime)
 
 	// Simulate 85% success rate
-	success := rand.Float32() < 0.85
-
-	if success {
-		// Update order status and book seats
-		if err := a.repo.UpdateOrderStatus(ctx, orderID, repository.OrderStatusConfirmed); err != nil {
-			return nil, fmt.Errorf("failed to update order: %w", err)
-		}
-		if err := a.repo.BookSeats(ctx, orderID); err != nil {
-			return nil, fmt.Errorf("failed to book seats: %w", err)
-		}
-
-		transactionID := fmt.Sprintf("TXN-%s-%d", input.OrderID[:8], time.Now().Unix())
+	if rand.Float32() < 0.85 {
+		transactionID := fmt.Sprintf("TXN-%s-%d", reference[:8], time.Now().Unix())
 		logger.Info("Payment successful", "transactionId", transactionID)
 
 		return &ValidatePaymentOutput{
 			Success:       true,
 			TransactionID: transactionID,
-		}, nil
-	}
-
-	// Payment failed - update attempts
-	failureReason := "Payment validation failed"
-	if err := a.repo.UpdateOrderPayment(ctx, orderID, input.Attempt, &failureReason); err != nil {
-		logger.Warn("Failed to update payment attempts", "error", err)
+		}
 	}
-
-	logger.Info("Payment failed", "attempt", input.Attempt)
 	return &ValidatePaymentOutput{
 		Success:      false,
 		ErrorMessage: "Payment validation failed. Please try again.",
-	}, nil
+	}
+}
+
+// ConfirmBookingInput is the input for ConfirmBooking activity
+type ConfirmBookingInput struct {
+	OrderID string `json:"orderId"`
+}
+
+// ConfirmBookingOutput is the output for ConfirmBooking activity
+type ConfirmBookingOutput struct {
+	Success          bool   `json:"success"`
+	BookingReference string `json:"bookingReference,omitempty"`
+	FailureReason    string `json:"failureReason,omitempty"`
+}
+
+// ConfirmBooking books the seats on every flight segment of an order and marks
+// it confirmed with a new booking reference. If any segment lost its hold
+// nothing is booked and the failure is reported so the workflow can fail the
+// whole order.
+func (a *Activities) ConfirmBooking(ctx context.Context, input ConfirmBookingInput) (*ConfirmBookingOutput, error) {
+	logger := activity.GetLogger(ctx)
+	logger.Info("Confirming booking", "orderId", input.OrderID)
+
+	orderID, err := uuid.Parse(input.OrderID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid order ID: %w", err)
+	}
+
+	reference, err := a.repo.ConfirmBooking(ctx, orderID)
+	if errors.Is(err, repository.ErrSeatsNotHeld) || errors.Is(err, repository.ErrSegmentNoSeats) ||
+		errors.Is(err, repository.ErrFlightNotBookable) {
+		logger.Warn("Booking could not be confirmed", "orderId", input.OrderID, "error", err)
+		return &ConfirmBookingOutput{
+			Success:       false,
+			FailureReason: err.Error(),
+		}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to confirm booking: %w", err)
+	}
+
+	logger.Info("Booking confirmed", "orderId", input.OrderID, "bookingReference", reference)
+	return &ConfirmBookingOutput{Success: true, BookingReference: reference}, nil
 }
 
 // ReserveSeatsInput is the input for ReserveSeats activity
@@ -112,18 +168,25 @@ type ReleaseSeatsInput struct {
 	Reason  string `json:"reason"`
 }
 
+// ReleaseSeatsOutput is the output for ReleaseSeats activity
+type ReleaseSeatsOutput struct {
+	// Waitlists are the waitlists with customers waiting for released seats
+	Waitlists []WaitlistRef `json:"waitlists,omitempty"`
+}
+
 // ReleaseSeats releases held seats
-func (a *Activities) ReleaseSeats(ctx context.Context, input ReleaseSeatsInput) error {
+func (a *Activities) ReleaseSeats(ctx context.Context, input ReleaseSeatsInput) (*ReleaseSeatsOutput, error) {
 	logger := activity.GetLogger(ctx)
 	logger.Info("Releasing seats", "orderId", input.OrderID, "reason", input.Reason)
 
 	orderID, err := uuid.Parse(input.OrderID)
 	if err != nil {
-		return fmt.Errorf("invalid order ID: %w", err)
+		return nil, fmt.Errorf("invalid order ID: %w", err)
 	}
 
-	if err := a.repo.ReleaseSeats(ctx, orderID); err != nil {
-		return fmt.Errorf("failed to release seats: %w", err)
+	waitlists, err := a.repo.ReleaseSeats(ctx, orderID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to release seats: %w", err)
 	}
 
 	// Update order status based on reason
@@ -133,26 +196,27 @@ func (a *Activities) ReleaseSeats(ctx context.Context, input ReleaseSeatsInput)
 		status = repository.OrderStatusExpired
 	case "cancelled":
 		status = repository.OrderStatusCancelled
-	case "payment_failed":
+	case "payment_failed", "booking_failed":
 		status = repository.OrderStatusFailed
 	default:
 		status = repository.OrderStatusFailed
 	}
 
 	if err := a.repo.UpdateOrderStatus(ctx, orderID, status); err != nil {
-		return fmt.Errorf("failed to update order status: %w", err)
+		return nil, fmt.Errorf("failed to update order status: %w", err)
 	}
 
-	return nil
+	return &ReleaseSeatsOutput{Waitlists: waitlistRefs(waitlists)}, nil
 }
 
 // SendConfirmationInput is the input for SendConfirmation activity
 type SendConfirmationInput struct {
-	OrderID       string `json:"orderId"`
-	CustomerEmail string `json:"customerEmail"`
-	CustomerName  string `json:"customerName"`
-	FlightNumber  string `json:"flightNumber"`
-	TransactionID string `json:"transactionId"`
+	OrderID          string `json:"orderId"`
+	BookingReference string `json:"bookingReference"`
+	CustomerEmail    string `json:"customerEmail"`
+	CustomerName     string `json:"customerName"`
+	FlightNumber     string `json:"flightNumber"`
+	TransactionID    string `json:"transactionId"`
 }
 
 // SendConfirmation sends a booking confirmation (simulated)
@@ -160,6 +224,7 @@ func (a *Activities) SendConfirmation(ctx context.Context, input SendConfirmatio
 	logger := activity.GetLogger(ctx)
 	logger.Info("Sending confirmation email",
 		"orderId", input.OrderID,
+		"bookingReference", input.BookingReference,
 		"email", input.CustomerEmail,
 		"transactionId", input.TransactionID,
 	)
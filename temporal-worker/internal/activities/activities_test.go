@@ -8,6 +8,8 @@ import (
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.temporal.io/sdk/testsuite"
 )
 
 // MockRepository is a mock implementation of the repository
@@ -35,43 +37,62 @@ func (m *MockRepository) BookSeats(ctx context.Context, orderID uuid.UUID) error
 	return args.Error(0)
 }
 
-func (m *MockRepository) ReleaseSeats(ctx context.Context, orderID uuid.UUID) error {
+func (m *MockRepository) ConfirmBooking(ctx context.Context, orderID uuid.UUID) (string, error) {
 	args := m.Called(ctx, orderID)
-	return args.Error(0)
+	return args.String(0), args.Error(1)
+}
+
+func (m *MockRepository) ReleaseSeats(ctx context.Context, orderID uuid.UUID) ([]repository.WaitlistKey, error) {
+	args := m.Called(ctx, orderID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]repository.WaitlistKey), args.Error(1)
+}
+
+// newTestActivityEnvironment returns an environment that runs activities with
+// a proper activity context, as the worker does
+func newTestActivityEnvironment(activities *Activities) *testsuite.TestActivityEnvironment {
+	var ts testsuite.WorkflowTestSuite
+	env := ts.NewTestActivityEnvironment()
+	env.RegisterActivity(activities)
+	return env
 }
 
 func TestValidatePayment_InvalidCode_TooShort(t *testing.T) {
-	mockRepo := new(MockRepository)
 	activities := NewActivities(&repository.Repository{})
 
-	ctx := context.Background()
+	env := newTestActivityEnvironment(activities)
 	input := ValidatePaymentInput{
 		OrderID:     uuid.New().String(),
 		PaymentCode: "1234", // Too short
 		Attempt:     1,
 	}
 
-	result, err := activities.ValidatePayment(ctx, input)
+	val, err := env.ExecuteActivity(activities.ValidatePayment, input)
+	require.NoError(t, err)
 
-	assert.NoError(t, err)
+	var result ValidatePaymentOutput
+	require.NoError(t, val.Get(&result))
 	assert.False(t, result.Success)
 	assert.Contains(t, result.ErrorMessage, "Invalid payment code")
 }
 
 func TestValidatePayment_InvalidCode_TooLong(t *testing.T) {
-	mockRepo := new(MockRepository)
 	activities := NewActivities(&repository.Repository{})
 
-	ctx := context.Background()
+	env := newTestActivityEnvironment(activities)
 	input := ValidatePaymentInput{
 		OrderID:     uuid.New().String(),
 		PaymentCode: "123456", // Too long
 		Attempt:     1,
 	}
 
-	result, err := activities.ValidatePayment(ctx, input)
+	val, err := env.ExecuteActivity(activities.ValidatePayment, input)
+	require.NoError(t, err)
 
-	assert.NoError(t, err)
+	var result ValidatePaymentOutput
+	require.NoError(t, val.Get(&result))
 	assert.False(t, result.Success)
 	assert.Contains(t, result.ErrorMessage, "Invalid payment code")
 }
@@ -79,14 +100,14 @@ func TestValidatePayment_InvalidCode_TooLong(t *testing.T) {
 func TestValidatePayment_InvalidOrderID(t *testing.T) {
 	activities := NewActivities(&repository.Repository{})
 
-	ctx := context.Background()
+	env := newTestActivityEnvironment(activities)
 	input := ValidatePaymentInput{
 		OrderID:     "invalid-uuid",
 		PaymentCode: "12345",
 		Attempt:     1,
 	}
 
-	_, err := activities.ValidatePayment(ctx, input)
+	_, err := env.ExecuteActivity(activities.ValidatePayment, input)
 
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "invalid order ID")
@@ -95,13 +116,27 @@ func TestValidatePayment_InvalidOrderID(t *testing.T) {
 func TestReleaseSeats_InvalidOrderID(t *testing.T) {
 	activities := NewActivities(&repository.Repository{})
 
-	ctx := context.Background()
+	env := newTestActivityEnvironment(activities)
 	input := ReleaseSeatsInput{
 		OrderID: "invalid-uuid",
 		Reason:  "expired",
 	}
 
-	err := activities.ReleaseSeats(ctx, input)
+	_, err := env.ExecuteActivity(activities.ReleaseSeats, input)
+
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid order ID")
+}
+
+func TestConfirmBooking_InvalidOrderID(t *testing.T) {
+	activities := NewActivities(&repository.Repository{})
+
+	env := newTestActivityEnvironment(activities)
+	input := ConfirmBookingInput{
+		OrderID: "invalid-uuid",
+	}
+
+	_, err := env.ExecuteActivity(activities.ConfirmBooking, input)
 
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "invalid order ID")
@@ -110,13 +145,13 @@ func TestReleaseSeats_InvalidOrderID(t *testing.T) {
 func TestUpdateOrderStatus_InvalidOrderID(t *testing.T) {
 	activities := NewActivities(&repository.Repository{})
 
-	ctx := context.Background()
+	env := newTestActivityEnvironment(activities)
 	input := UpdateOrderStatusInput{
 		OrderID: "invalid-uuid",
 		Status:  "confirmed",
 	}
 
-	err := activities.UpdateOrderStatus(ctx, input)
+	_, err := env.ExecuteActivity(activities.UpdateOrderStatus, input)
 
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "invalid order ID")
@@ -125,12 +160,12 @@ func TestUpdateOrderStatus_InvalidOrderID(t *testing.T) {
 func TestCheckReservationExpiry_InvalidOrderID(t *testing.T) {
 	activities := NewActivities(&repository.Repository{})
 
-	ctx := context.Background()
+	env := newTestActivityEnvironment(activities)
 	input := CheckReservationExpiryInput{
 		OrderID: "invalid-uuid",
 	}
 
-	_, err := activities.CheckReservationExpiry(ctx, input)
+	_, err := env.ExecuteActivity(activities.CheckReservationExpiry, input)
 
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "invalid order ID")
@@ -139,7 +174,7 @@ func TestCheckReservationExpiry_InvalidOrderID(t *testing.T) {
 func TestSendConfirmation_Success(t *testing.T) {
 	activities := NewActivities(&repository.Repository{})
 
-	ctx := context.Background()
+	env := newTestActivityEnvironment(activities)
 	input := SendConfirmationInput{
 		OrderID:       uuid.New().String(),
 		CustomerEmail: "test@example.com",
@@ -148,7 +183,7 @@ func TestSendConfirmation_Success(t *testing.T) {
 		TransactionID: "TXN-12345",
 	}
 
-	err := activities.SendConfirmation(ctx, input)
+	_, err := env.ExecuteActivity(activities.SendConfirmation, input)
 
 	// SendConfirmation just logs and returns nil
 	assert.NoError(t, err)
@@ -157,13 +192,13 @@ func TestSendConfirmation_Success(t *testing.T) {
 func TestReserveSeats_Success(t *testing.T) {
 	activities := NewActivities(&repository.Repository{})
 
-	ctx := context.Background()
+	env := newTestActivityEnvironment(activities)
 	input := ReserveSeatsInput{
 		OrderID: uuid.New().String(),
 		SeatIDs: []string{"seat-1", "seat-2"},
 	}
 
-	err := activities.ReserveSeats(ctx, input)
+	_, err := env.ExecuteActivity(activities.ReserveSeats, input)
 
 	// ReserveSeats just logs and returns nil (actual reservation is handled via API)
 	assert.NoError(t, err)
@@ -178,3 +213,100 @@ func TestValidatePayment_SuccessRate(t *testing.T) {
 	// The 85% success rate is simulated in the ValidatePayment function
 	// using rand.Float32() < 0.85
 }
+
+func TestOfferRebooking_InvalidFlightID(t *testing.T) {
+	activities := NewActivities(&repository.Repository{})
+
+	env := newTestActivityEnvironment(activities)
+	input := OfferRebookingInput{
+		OrderID:   uuid.New().String(),
+		FlightID:  "invalid-uuid",
+		SeatCount: 1,
+	}
+
+	_, err := env.ExecuteActivity(activities.OfferRebooking, input)
+
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid flight ID")
+}
+
+func TestRebookOrder_InvalidOrderID(t *testing.T) {
+	activities := NewActivities(&repository.Repository{})
+
+	env := newTestActivityEnvironment(activities)
+	input := RebookOrderInput{
+		OrderID:     "invalid-uuid",
+		FlightID:    uuid.New().String(),
+		NewFlightID: uuid.New().String(),
+	}
+
+	_, err := env.ExecuteActivity(activities.RebookOrder, input)
+
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid order ID")
+}
+
+func TestRefundOrder_InvalidReason(t *testing.T) {
+	activities := NewActivities(&repository.Repository{})
+
+	env := newTestActivityEnvironment(activities)
+	input := RefundOrderInput{
+		OrderID:  uuid.New().String(),
+		FlightID: uuid.New().String(),
+		Reason:   "cancelled",
+	}
+
+	_, err := env.ExecuteActivity(activities.RefundOrder, input)
+
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid refund reason")
+}
+
+func TestNotifyDisruption_Success(t *testing.T) {
+	activities := NewActivities(&repository.Repository{})
+
+	env := newTestActivityEnvironment(activities)
+	input := NotifyDisruptionInput{
+		OrderID:              uuid.New().String(),
+		CustomerEmail:        "test@example.com",
+		CustomerName:         "John Doe",
+		FlightNumber:         "AA123",
+		AlternativeFlightIDs: []string{uuid.New().String()},
+	}
+
+	_, err := env.ExecuteActivity(activities.NotifyDisruption, input)
+
+	// NotifyDisruption just logs and returns nil
+	assert.NoError(t, err)
+}
+
+func TestNotifyWaitlistOffer_Success(t *testing.T) {
+	activities := NewActivities(&repository.Repository{})
+
+	env := newTestActivityEnvironment(activities)
+	input := NotifyWaitlistOfferInput{
+		EntryID:       uuid.New().String(),
+		OrderID:       uuid.New().String(),
+		CustomerEmail: "test@example.com",
+		CustomerName:  "John Doe",
+		FlightNumber:  "AA123",
+		SeatCount:     2,
+	}
+
+	_, err := env.ExecuteActivity(activities.NotifyWaitlistOffer, input)
+
+	// NotifyWaitlistOffer just logs and returns nil
+	assert.NoError(t, err)
+}
+
+func TestCompleteWaitlistOffer_InvalidEntryID(t *testing.T) {
+	activities := NewActivities(&repository.Repository{})
+
+	env := newTestActivityEnvironment(activities)
+	input := CompleteWaitlistOfferInput{EntryID: "invalid-uuid"}
+
+	_, err := env.ExecuteActivity(activities.CompleteWaitlistOffer, input)
+
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid waitlist entry ID")
+}
@@ -0,0 +1,256 @@
+package activities
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/cx-tal-miterani/flight-booking-system/temporal-worker/internal/repository"
+	"github.com/google/uuid"
+	"go.temporal.io/sdk/activity"
+)
+
+const (
+	// AlternativeFlightWindow is how far from the cancelled departure
+	// alternative flights may depart
+	AlternativeFlightWindow = 72 * time.Hour
+	// MaxAlternativeFlights is the number of alternatives offered per order
+	MaxAlternativeFlights = 3
+)
+
+// GetDisruptedOrdersInput is the input for GetDisruptedOrders activity
+type GetDisruptedOrdersInput struct {
+	FlightID string `json:"flightId"`
+}
+
+// DisruptedOrder is a confirmed order affected by a flight cancellation
+type DisruptedOrder struct {
+	OrderID       string `json:"orderId"`
+	CustomerName  string `json:"customerName"`
+	CustomerEmail string `json:"customerEmail"`
+	SeatCount     int    `json:"seatCount"`
+}
+
+// GetDisruptedOrders returns the confirmed orders booked on a cancelled flight
+func (a *Activities) GetDisruptedOrders(ctx context.Context, input GetDisruptedOrdersInput) ([]DisruptedOrder, error) {
+	logger := activity.GetLogger(ctx)
+	logger.Info("Finding disrupted orders", "flightId", input.FlightID)
+
+	flightID, err := uuid.Parse(input.FlightID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid flight ID: %w", err)
+	}
+
+	orders, err := a.repo.GetDisruptedOrders(ctx, flightID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get disrupted orders: %w", err)
+	}
+
+	result := make([]DisruptedOrder, 0, len(orders))
+	for _, o := range orders {
+		result = append(result, DisruptedOrder{
+			OrderID:       o.OrderID.String(),
+			CustomerName:  o.CustomerName,
+			CustomerEmail: o.CustomerEmail,
+			SeatCount:     o.SeatCount,
+		})
+	}
+
+	logger.Info("Found disrupted orders", "count", len(result))
+	return result, nil
+}
+
+// OfferRebookingInput is the input for OfferRebooking activity
+type OfferRebookingInput struct {
+	OrderID   string    `json:"orderId"`
+	FlightID  string    `json:"flightId"`
+	SeatCount int       `json:"seatCount"`
+	ExpiresAt time.Time `json:"expiresAt"`
+}
+
+// OfferRebookingOutput is the output for OfferRebooking activity
+type OfferRebookingOutput struct {
+	AlternativeFlightIDs []string `json:"alternativeFlightIds"`
+}
+
+// OfferRebooking finds alternative flights for a disrupted order and records
+// the offer so the customer can answer it through the API. An order without
+// alternatives gets a closed offer and no flights.
+func (a *Activities) OfferRebooking(ctx context.Context, input OfferRebookingInput) (*OfferRebookingOutput, error) {
+	logger := activity.GetLogger(ctx)
+	logger.Info("Offering rebooking", "orderId", input.OrderID, "flightId", input.FlightID)
+
+	orderID, err := uuid.Parse(input.OrderID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid order ID: %w", err)
+	}
+	flightID, err := uuid.Parse(input.FlightID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid flight ID: %w", err)
+	}
+
+	alternatives, err := a.repo.FindAlternativeFlights(ctx, orderID, flightID, input.SeatCount, AlternativeFlightWindow, MaxAlternativeFlights)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find alternative flights: %w", err)
+	}
+
+	offer := &repository.RebookingOffer{
+		OrderID:              orderID,
+		FlightID:             flightID,
+		WorkflowID:           activity.GetInfo(ctx).WorkflowExecution.ID,
+		AlternativeFlightIDs: alternatives,
+		Status:               repository.OfferStatusPending,
+		ExpiresAt:            input.ExpiresAt,
+	}
+	if len(alternatives) == 0 {
+		offer.Status = repository.OfferStatusNoAlternatives
+	}
+	if err := a.repo.SaveRebookingOffer(ctx, offer); err != nil {
+		return nil, fmt.Errorf("failed to save rebooking offer: %w", err)
+	}
+
+	output := &OfferRebookingOutput{AlternativeFlightIDs: make([]string, 0, len(alternatives))}
+	for _, id := range alternatives {
+		output.AlternativeFlightIDs = append(output.AlternativeFlightIDs, id.String())
+	}
+
+	logger.Info("Rebooking offered", "orderId", input.OrderID, "alternatives", len(alternatives))
+	return output, nil
+}
+
+// NotifyDisruptionInput is the input for NotifyDisruption activity
+type NotifyDisruptionInput struct {
+	OrderID              string    `json:"orderId"`
+	CustomerEmail        string    `json:"customerEmail"`
+	CustomerName         string    `json:"customerName"`
+	FlightNumber         string    `json:"flightNumber"`
+	Reason               string    `json:"reason,omitempty"`
+	AlternativeFlightIDs []string  `json:"alternativeFlightIds"`
+	ExpiresAt            time.Time `json:"expiresAt"`
+}
+
+// NotifyDisruption tells a customer their flight was cancelled and what they
+// can do about it (simulated)
+func (a *Activities) NotifyDisruption(ctx context.Context, input NotifyDisruptionInput) error {
+	logger := activity.GetLogger(ctx)
+	logger.Info("Sending disruption email",
+		"orderId", input.OrderID,
+		"email", input.CustomerEmail,
+		"flightNumber", input.FlightNumber,
+		"alternatives", len(input.AlternativeFlightIDs),
+	)
+
+	// Simulate sending email
+	time.Sleep(500 * time.Millisecond)
+
+	logger.Info("Disruption email sent successfully")
+	return nil
+}
+
+// RebookOrderInput is the input for RebookOrder activity
+type RebookOrderInput struct {
+	OrderID     string `json:"orderId"`
+	FlightID    string `json:"flightId"`
+	NewFlightID string `json:"newFlightId"`
+}
+
+// RebookOrderOutput is the output for RebookOrder activity
+type RebookOrderOutput struct {
+	Success       bool   `json:"success"`
+	FailureReason string `json:"failureReason,omitempty"`
+}
+
+// RebookOrder moves a disrupted order to the alternative flight the customer
+// chose. If the flight no longer has enough seats the failure is recorded
+// on the offer and reported, so the customer can choose again.
+func (a *Activities) RebookOrder(ctx context.Context, input RebookOrderInput) (*RebookOrderOutput, error) {
+	logger := activity.GetLogger(ctx)
+	logger.Info("Rebooking order", "orderId", input.OrderID, "from", input.FlightID, "to", input.NewFlightID)
+
+	orderID, err := uuid.Parse(input.OrderID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid order ID: %w", err)
+	}
+	flightID, err := uuid.Parse(input.FlightID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid flight ID: %w", err)
+	}
+	newFlightID, err := uuid.Parse(input.NewFlightID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid flight ID: %w", err)
+	}
+
+	err = a.repo.RebookOrder(ctx, orderID, flightID, newFlightID)
+	if errors.Is(err, repository.ErrNoSeatsAvailable) || errors.Is(err, repository.ErrFlightNotBookable) ||
+		errors.Is(err, repository.ErrOfferClosed) || errors.Is(err, repository.ErrNotAlternative) {
+		logger.Warn("Order could not be rebooked", "orderId", input.OrderID, "error", err)
+		if err := a.repo.SetRebookingFailure(ctx, orderID, flightID, err.Error()); err != nil {
+			logger.Warn("Failed to record rebooking failure", "error", err)
+		}
+		return &RebookOrderOutput{
+			Success:       false,
+			FailureReason: err.Error(),
+		}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to rebook order: %w", err)
+	}
+
+	return &RebookOrderOutput{Success: true}, nil
+}
+
+// RefundOrderInput is the input for RefundOrder activity
+type RefundOrderInput struct {
+	OrderID  string `json:"orderId"`
+	FlightID string `json:"flightId"`
+	// Reason is the final status of the rebooking offer: declined, expired
+	// or no_alternatives
+	Reason string `json:"reason"`
+}
+
+// RefundOrderOutput is the output for RefundOrder activity
+type RefundOrderOutput struct {
+	RefundID string  `json:"refundId"`
+	Amount   float64 `json:"amount"`
+}
+
+// RefundOrder releases a disrupted order's seats, marks it refunded and
+// issues the refund (simulated)
+func (a *Activities) RefundOrder(ctx context.Context, input RefundOrderInput) (*RefundOrderOutput, error) {
+	logger := activity.GetLogger(ctx)
+	logger.Info("Refunding order", "orderId", input.OrderID, "reason", input.Reason)
+
+	orderID, err := uuid.Parse(input.OrderID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid order ID: %w", err)
+	}
+	flightID, err := uuid.Parse(input.FlightID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid flight ID: %w", err)
+	}
+
+	switch input.Reason {
+	case repository.OfferStatusDeclined, repository.OfferStatusExpired, repository.OfferStatusNoAlternatives:
+	default:
+		return nil, fmt.Errorf("invalid refund reason: %q", input.Reason)
+	}
+
+	amount, err := a.repo.RefundOrder(ctx, orderID, flightID, input.Reason)
+	if err != nil {
+		return nil, fmt.Errorf("failed to refund order: %w", err)
+	}
+
+	refundID := fmt.Sprintf("RFD-%s", input.OrderID[:8])
+	a.recordOrderEvent(ctx, input.OrderID, repository.OrderEventRefundIssued, map[string]interface{}{
+		"refundId": refundID,
+		"amount":   amount,
+		"reason":   input.Reason,
+	})
+	logger.Info("Refund issued", "refundId", refundID, "amount", amount)
+
+	return &RefundOrderOutput{
+		RefundID: refundID,
+		Amount:   amount,
+	}, nil
+}
@@ -0,0 +1,242 @@
+package activities
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/cx-tal-miterani/flight-booking-system/temporal-worker/internal/repository"
+	"github.com/google/uuid"
+	"go.temporal.io/sdk/activity"
+)
+
+// HoldFlightExchangeSeatsInput is the input for HoldFlightExchangeSeats activity
+type HoldFlightExchangeSeatsInput struct {
+	ExchangeID string  `json:"exchangeId"`
+	ChangeFee  float64 `json:"changeFee"`
+}
+
+// HoldFlightExchangeSeatsOutput is the output for HoldFlightExchangeSeats
+// activity. A negative FareDifference means the new seats are cheaper.
+type HoldFlightExchangeSeatsOutput struct {
+	Success        bool    `json:"success"`
+	FailureReason  string  `json:"failureReason,omitempty"`
+	FareDifference float64 `json:"fareDifference"`
+	ChangeFee      float64 `json:"changeFee"`
+	Seats          int     `json:"seats"`
+}
+
+// HoldFlightExchangeSeats holds seats on the target flight of a flight
+// exchange and prices the exchange with a change fee of ChangeFee per seat.
+// It does not succeed if the order cannot change flights or the target
+// flight has too few seats.
+func (a *Activities) HoldFlightExchangeSeats(ctx context.Context, input HoldFlightExchangeSeatsInput) (*HoldFlightExchangeSeatsOutput, error) {
+	logger := activity.GetLogger(ctx)
+
+	exchangeID, err := uuid.Parse(input.ExchangeID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid exchange ID: %w", err)
+	}
+
+	quote, err := a.repo.HoldFlightExchangeSeats(ctx, exchangeID, input.ChangeFee)
+	switch {
+	case errors.Is(err, repository.ErrNoSeatsAvailable):
+		logger.Info("Target flight has too few seats", "exchangeId", input.ExchangeID, "reason", err)
+		return &HoldFlightExchangeSeatsOutput{FailureReason: "Not enough seats available on the new flight"}, nil
+	case errors.Is(err, repository.ErrFlightNotBookable),
+		errors.Is(err, repository.ErrExchangeNotAllowed),
+		errors.Is(err, repository.ErrSegmentNoSeats),
+		errors.Is(err, repository.ErrFlightExchangeClosed):
+		logger.Info("Flight change not possible", "exchangeId", input.ExchangeID, "reason", err)
+		return &HoldFlightExchangeSeatsOutput{FailureReason: err.Error()}, nil
+	case err != nil:
+		return nil, fmt.Errorf("failed to hold flight exchange seats: %w", err)
+	}
+
+	logger.Info("Flight change seats held", "exchangeId", input.ExchangeID, "seats", quote.Seats,
+		"fareDifference", quote.FareDifference, "changeFee", quote.ChangeFee)
+	return &HoldFlightExchangeSeatsOutput{
+		Success:        true,
+		FareDifference: quote.FareDifference,
+		ChangeFee:      quote.ChangeFee,
+		Seats:          quote.Seats,
+	}, nil
+}
+
+// ChargeFlightExchangeInput is the input for ChargeFlightExchange activity
+type ChargeFlightExchangeInput struct {
+	ExchangeID  string  `json:"exchangeId"`
+	OrderID     string  `json:"orderId"`
+	PaymentCode string  `json:"paymentCode"`
+	Amount      float64 `json:"amount"`
+}
+
+// ChargeFlightExchange charges the amount due for a flight change to a
+// payment code (simulated), like ValidatePayment does for bookings
+func (a *Activities) ChargeFlightExchange(ctx context.Context, input ChargeFlightExchangeInput) (*ValidatePaymentOutput, error) {
+	logger := activity.GetLogger(ctx)
+	logger.Info("Charging flight change", "exchangeId", input.ExchangeID, "orderId", input.OrderID, "amount", input.Amount)
+
+	if _, err := uuid.Parse(input.ExchangeID); err != nil {
+		return nil, fmt.Errorf("invalid exchange ID: %w", err)
+	}
+
+	result := simulatePayment(ctx, input.ExchangeID, input.PaymentCode)
+	if result.ErrorMessage != invalidPaymentCodeMessage {
+		payload := paymentEventPayload(result, input.Amount)
+		payload["flightExchangeId"] = input.ExchangeID
+		a.recordOrderEvent(ctx, input.OrderID, repository.OrderEventPaymentAttempted, payload)
+	}
+	if !result.Success {
+		logger.Info("Flight change payment failed", "exchangeId", input.ExchangeID, "error", result.ErrorMessage)
+	}
+	return result, nil
+}
+
+// ConfirmFlightExchangeInput is the input for ConfirmFlightExchange activity
+type ConfirmFlightExchangeInput struct {
+	ExchangeID    string `json:"exchangeId"`
+	TransactionID string `json:"transactionId,omitempty"`
+}
+
+// ConfirmFlightExchangeOutput is the output for ConfirmFlightExchange activity
+type ConfirmFlightExchangeOutput struct {
+	Success       bool   `json:"success"`
+	FailureReason string `json:"failureReason,omitempty"`
+}
+
+// ConfirmFlightExchange books the new seats of a flight change and moves the
+// order to the new flight; the old seats stay booked until
+// ReleaseFlightExchangeSeats. It does not succeed if the new seats are no
+// longer held for the order, e.g. because their hold expired.
+func (a *Activities) ConfirmFlightExchange(ctx context.Context, input ConfirmFlightExchangeInput) (*ConfirmFlightExchangeOutput, error) {
+	logger := activity.GetLogger(ctx)
+
+	exchangeID, err := uuid.Parse(input.ExchangeID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid exchange ID: %w", err)
+	}
+
+	var transactionID *string
+	if input.TransactionID != "" {
+		transactionID = &input.TransactionID
+	}
+	err = a.repo.ConfirmFlightExchange(ctx, exchangeID, transactionID)
+	if errors.Is(err, repository.ErrSeatsNotHeld) || errors.Is(err, repository.ErrFlightExchangeClosed) {
+		logger.Info("Flight change could not be confirmed", "exchangeId", input.ExchangeID, "reason", err)
+		return &ConfirmFlightExchangeOutput{FailureReason: err.Error()}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to confirm flight exchange: %w", err)
+	}
+
+	logger.Info("Flight change confirmed", "exchangeId", input.ExchangeID)
+	return &ConfirmFlightExchangeOutput{Success: true}, nil
+}
+
+// ReleaseFlightExchangeSeatsInput is the input for ReleaseFlightExchangeSeats activity
+type ReleaseFlightExchangeSeatsInput struct {
+	ExchangeID string `json:"exchangeId"`
+}
+
+// ReleaseFlightExchangeSeatsOutput is the output for
+// ReleaseFlightExchangeSeats activity
+type ReleaseFlightExchangeSeatsOutput struct {
+	// Waitlists are the waitlists with customers waiting for released seats
+	Waitlists []WaitlistRef `json:"waitlists,omitempty"`
+}
+
+// ReleaseFlightExchangeSeats releases the seats a confirmed flight change
+// left behind and completes the exchange
+func (a *Activities) ReleaseFlightExchangeSeats(ctx context.Context, input ReleaseFlightExchangeSeatsInput) (*ReleaseFlightExchangeSeatsOutput, error) {
+	logger := activity.GetLogger(ctx)
+
+	exchangeID, err := uuid.Parse(input.ExchangeID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid exchange ID: %w", err)
+	}
+
+	waitlists, err := a.repo.ReleaseFlightExchangeSeats(ctx, exchangeID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to release flight exchange seats: %w", err)
+	}
+
+	logger.Info("Flight change completed", "exchangeId", input.ExchangeID)
+	return &ReleaseFlightExchangeSeatsOutput{Waitlists: waitlistRefs(waitlists)}, nil
+}
+
+// FailFlightExchangeInput is the input for FailFlightExchange activity
+type FailFlightExchangeInput struct {
+	ExchangeID string `json:"exchangeId"`
+	Reason     string `json:"reason"`
+}
+
+// FailFlightExchangeOutput is the output for FailFlightExchange activity
+type FailFlightExchangeOutput struct {
+	// Waitlists are the waitlists with customers waiting for released seats
+	Waitlists []WaitlistRef `json:"waitlists,omitempty"`
+}
+
+// FailFlightExchange fails a flight change and releases the seats held on
+// the new flight; the order keeps its flight. An exchange that was already
+// confirmed is left alone.
+func (a *Activities) FailFlightExchange(ctx context.Context, input FailFlightExchangeInput) (*FailFlightExchangeOutput, error) {
+	logger := activity.GetLogger(ctx)
+
+	exchangeID, err := uuid.Parse(input.ExchangeID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid exchange ID: %w", err)
+	}
+
+	waitlists, err := a.repo.FailFlightExchange(ctx, exchangeID, input.Reason)
+	if errors.Is(err, repository.ErrFlightExchangeClosed) {
+		return &FailFlightExchangeOutput{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to fail flight exchange: %w", err)
+	}
+
+	logger.Info("Flight change failed", "exchangeId", input.ExchangeID, "reason", input.Reason)
+	return &FailFlightExchangeOutput{Waitlists: waitlistRefs(waitlists)}, nil
+}
+
+// RefundFlightExchangeInput is the input for RefundFlightExchange activity
+type RefundFlightExchangeInput struct {
+	ExchangeID string  `json:"exchangeId"`
+	OrderID    string  `json:"orderId"`
+	Amount     float64 `json:"amount"`
+}
+
+// RefundFlightExchangeOutput is the output for RefundFlightExchange activity
+type RefundFlightExchangeOutput struct {
+	RefundID string  `json:"refundId"`
+	Amount   float64 `json:"amount"`
+}
+
+// RefundFlightExchange refunds an amount to the customer of a flight change
+// (simulated) and records the refund on the exchange
+func (a *Activities) RefundFlightExchange(ctx context.Context, input RefundFlightExchangeInput) (*RefundFlightExchangeOutput, error) {
+	logger := activity.GetLogger(ctx)
+
+	exchangeID, err := uuid.Parse(input.ExchangeID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid exchange ID: %w", err)
+	}
+	if input.Amount <= 0 {
+		return nil, fmt.Errorf("invalid refund amount: %.2f", input.Amount)
+	}
+
+	// The refund ID is derived from the exchange so retries issue one refund
+	refundID := fmt.Sprintf("RFD-%s", input.ExchangeID[:8])
+	if err := a.repo.RecordFlightExchangeRefund(ctx, exchangeID, refundID); err != nil {
+		return nil, fmt.Errorf("failed to record flight exchange refund: %w", err)
+	}
+
+	a.recordOrderEvent(ctx, input.OrderID, repository.OrderEventRefundIssued, map[string]interface{}{
+		"refundId":         refundID,
+		"amount":           input.Amount,
+		"flightExchangeId": input.ExchangeID,
+	})
+	logger.Info("Flight change refunded", "exchangeId", input.ExchangeID, "orderId", input.OrderID, "refundId", refundID, "amount", input.Amount)
+	return &RefundFlightExchangeOutput{RefundID: refundID, Amount: input.Amount}, nil
+}
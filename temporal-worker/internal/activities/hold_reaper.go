@@ -0,0 +1,81 @@
+package activities
+
+import (
+	"context"
+	"fmt"
+
+	"go.temporal.io/sdk/activity"
+)
+
+// ReleaseExpiredHoldsInput is the input for ReleaseExpiredHolds activity
+type ReleaseExpiredHoldsInput struct {
+	BatchSize int `json:"batchSize"`
+}
+
+// ReleaseExpiredHoldsOutput is the output for ReleaseExpiredHolds activity
+type ReleaseExpiredHoldsOutput struct {
+	SeatsReleased int `json:"seatsReleased"`
+	OrdersExpired int `json:"ordersExpired"`
+	// Waitlists are the waitlists with customers waiting for released seats
+	Waitlists []WaitlistRef `json:"waitlists,omitempty"`
+}
+
+// ReleaseExpiredHolds releases a batch of seats whose hold has expired and
+// expires the orders that held them. Released seats are only released once,
+// so it is safe to retry.
+func (a *Activities) ReleaseExpiredHolds(ctx context.Context, input ReleaseExpiredHoldsInput) (*ReleaseExpiredHoldsOutput, error) {
+	logger := activity.GetLogger(ctx)
+
+	if input.BatchSize <= 0 {
+		return nil, fmt.Errorf("invalid batch size: %d", input.BatchSize)
+	}
+
+	result, err := a.repo.ReleaseExpiredHolds(ctx, input.BatchSize)
+	if err != nil {
+		return nil, fmt.Errorf("failed to release expired holds: %w", err)
+	}
+
+	if result.SeatsReleased > 0 {
+		logger.Info("Expired holds released", "seatsReleased", result.SeatsReleased, "ordersExpired", result.OrdersExpired)
+	}
+	return &ReleaseExpiredHoldsOutput{
+		SeatsReleased: result.SeatsReleased,
+		OrdersExpired: result.OrdersExpired,
+		Waitlists:     waitlistRefs(result.Waitlists),
+	}, nil
+}
+
+// ReleaseExpiredBlocksInput is the input for ReleaseExpiredBlocks activity
+type ReleaseExpiredBlocksInput struct {
+	BatchSize int `json:"batchSize"`
+}
+
+// ReleaseExpiredBlocksOutput is the output for ReleaseExpiredBlocks activity
+type ReleaseExpiredBlocksOutput struct {
+	SeatsReleased int `json:"seatsReleased"`
+	// Waitlists are the waitlists with customers waiting for released seats
+	Waitlists []WaitlistRef `json:"waitlists,omitempty"`
+}
+
+// ReleaseExpiredBlocks lifts a batch of admin seat blocks that have expired.
+// Lifted blocks are only lifted once, so it is safe to retry.
+func (a *Activities) ReleaseExpiredBlocks(ctx context.Context, input ReleaseExpiredBlocksInput) (*ReleaseExpiredBlocksOutput, error) {
+	logger := activity.GetLogger(ctx)
+
+	if input.BatchSize <= 0 {
+		return nil, fmt.Errorf("invalid batch size: %d", input.BatchSize)
+	}
+
+	result, err := a.repo.ReleaseExpiredBlocks(ctx, input.BatchSize)
+	if err != nil {
+		return nil, fmt.Errorf("failed to release expired blocks: %w", err)
+	}
+
+	if result.SeatsReleased > 0 {
+		logger.Info("Expired seat blocks released", "seatsReleased", result.SeatsReleased)
+	}
+	return &ReleaseExpiredBlocksOutput{
+		SeatsReleased: result.SeatsReleased,
+		Waitlists:     waitlistRefs(result.Waitlists),
+	}, nil
+}
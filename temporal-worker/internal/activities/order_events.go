@@ -0,0 +1,44 @@
+package activities
+
+import (
+	"context"
+
+	"github.com/cx-tal-miterani/flight-booking-system/temporal-worker/internal/repository"
+	"github.com/google/uuid"
+	"go.temporal.io/sdk/activity"
+)
+
+// recordOrderEvent adds an event to the history of an order, noting the
+// workflow the activity runs for. The history is best effort: failures are
+// logged and do not fail the activity.
+func (a *Activities) recordOrderEvent(ctx context.Context, orderID string, eventType repository.OrderEventType, payload map[string]interface{}) {
+	logger := activity.GetLogger(ctx)
+
+	id, err := uuid.Parse(orderID)
+	if err != nil {
+		logger.Warn("Not recording order event for invalid order ID", "orderId", orderID, "type", eventType)
+		return
+	}
+	info := activity.GetInfo(ctx)
+	payload["workflowType"] = info.WorkflowType.Name
+	payload["workflowId"] = info.WorkflowExecution.ID
+	if err := a.repo.RecordOrderEvent(ctx, id, eventType, payload); err != nil {
+		logger.Warn("Failed to record order event", "orderId", orderID, "type", eventType, "error", err)
+	}
+}
+
+// paymentEventPayload describes a charge of amount for a payment attempt
+// event; amount is omitted when it is not known to the activity
+func paymentEventPayload(result *ValidatePaymentOutput, amount float64) map[string]interface{} {
+	payload := map[string]interface{}{"success": result.Success}
+	if amount > 0 {
+		payload["amount"] = amount
+	}
+	if result.TransactionID != "" {
+		payload["transactionId"] = result.TransactionID
+	}
+	if result.ErrorMessage != "" {
+		payload["error"] = result.ErrorMessage
+	}
+	return payload
+}
@@ -0,0 +1,20 @@
+package activities
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPaymentEventPayload(t *testing.T) {
+	assert.Equal(t, map[string]interface{}{
+		"success":       true,
+		"amount":        42.5,
+		"transactionId": "TXN-1",
+	}, paymentEventPayload(&ValidatePaymentOutput{Success: true, TransactionID: "TXN-1"}, 42.5))
+
+	assert.Equal(t, map[string]interface{}{
+		"success": false,
+		"error":   "Payment validation failed. Please try again.",
+	}, paymentEventPayload(&ValidatePaymentOutput{ErrorMessage: "Payment validation failed. Please try again."}, 0))
+}
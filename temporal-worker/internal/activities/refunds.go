@@ -0,0 +1,102 @@
+package activities
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"time"
+
+	"github.com/cx-tal-miterani/flight-booking-system/temporal-worker/internal/repository"
+	"github.com/google/uuid"
+	"go.temporal.io/sdk/activity"
+)
+
+// IssueRefundInput is the input for IssueRefund activity
+type IssueRefundInput struct {
+	RefundID string  `json:"refundId"`
+	OrderID  string  `json:"orderId"`
+	Amount   float64 `json:"amount"`
+}
+
+// IssueRefundOutput is the output for IssueRefund activity
+type IssueRefundOutput struct {
+	Success          bool    `json:"success"`
+	ProviderRefundID string  `json:"providerRefundId,omitempty"`
+	Amount           float64 `json:"amount"`
+	FailureReason    string  `json:"failureReason,omitempty"`
+}
+
+// errRefundProviderUnavailable is returned when the simulated payment
+// provider does not answer; the activity is retried
+var errRefundProviderUnavailable = errors.New("payment provider unavailable")
+
+// IssueRefund returns the amount of a cancelled booking's refund to the
+// customer through the payment provider (simulated) and records the refund
+// as completed. The provider is unavailable 10% of the time, which fails the
+// activity so it is retried; the provider refund ID is derived from the
+// refund so retries pay out once.
+func (a *Activities) IssueRefund(ctx context.Context, input IssueRefundInput) (*IssueRefundOutput, error) {
+	logger := activity.GetLogger(ctx)
+	logger.Info("Issuing refund", "refundId", input.RefundID, "orderId", input.OrderID, "amount", input.Amount)
+
+	refundID, err := uuid.Parse(input.RefundID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid refund ID: %w", err)
+	}
+	if input.Amount <= 0 {
+		return nil, fmt.Errorf("invalid refund amount: %.2f", input.Amount)
+	}
+
+	// Simulate provider processing time (0.5-1.5 seconds)
+	time.Sleep(time.Duration(500+rand.Intn(1000)) * time.Millisecond)
+	if rand.Float32() < 0.1 {
+		logger.Warn("Refund not accepted, will retry", "refundId", input.RefundID)
+		return nil, errRefundProviderUnavailable
+	}
+
+	providerRefundID := fmt.Sprintf("RFD-%s", input.RefundID[:8])
+	err = a.repo.CompleteOrderRefund(ctx, refundID, providerRefundID)
+	if errors.Is(err, repository.ErrRefundNotPending) || errors.Is(err, repository.ErrNotFound) {
+		logger.Info("Refund is no longer pending", "refundId", input.RefundID, "reason", err)
+		return &IssueRefundOutput{FailureReason: err.Error()}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to complete order refund: %w", err)
+	}
+
+	a.recordOrderEvent(ctx, input.OrderID, repository.OrderEventRefundIssued, map[string]interface{}{
+		"refundId":      providerRefundID,
+		"amount":        input.Amount,
+		"orderRefundId": input.RefundID,
+	})
+	logger.Info("Refund issued", "refundId", input.RefundID, "providerRefundId", providerRefundID)
+	return &IssueRefundOutput{
+		Success:          true,
+		ProviderRefundID: providerRefundID,
+		Amount:           input.Amount,
+	}, nil
+}
+
+// FailOrderRefundInput is the input for FailOrderRefund activity
+type FailOrderRefundInput struct {
+	RefundID string `json:"refundId"`
+	Reason   string `json:"reason"`
+}
+
+// FailOrderRefund marks a refund the payment provider did not accept failed,
+// leaving it for support to pay out
+func (a *Activities) FailOrderRefund(ctx context.Context, input FailOrderRefundInput) error {
+	logger := activity.GetLogger(ctx)
+
+	refundID, err := uuid.Parse(input.RefundID)
+	if err != nil {
+		return fmt.Errorf("invalid refund ID: %w", err)
+	}
+	if err := a.repo.FailOrderRefund(ctx, refundID, input.Reason); err != nil {
+		return fmt.Errorf("failed to fail order refund: %w", err)
+	}
+
+	logger.Info("Refund failed", "refundId", input.RefundID, "reason", input.Reason)
+	return nil
+}
@@ -0,0 +1,50 @@
+package activities
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/uuid"
+	"go.temporal.io/sdk/activity"
+)
+
+// MaterializeScheduledFlightsInput is the input for MaterializeScheduledFlights activity
+type MaterializeScheduledFlightsInput struct {
+	// ScheduleID limits materialization to one schedule; empty means all
+	ScheduleID  string `json:"scheduleId,omitempty"`
+	HorizonDays int    `json:"horizonDays"`
+}
+
+// MaterializeScheduledFlightsOutput is the output for MaterializeScheduledFlights activity
+type MaterializeScheduledFlightsOutput struct {
+	FlightsCreated int `json:"flightsCreated"`
+}
+
+// MaterializeScheduledFlights creates the flights and seats of flight
+// schedules up to HorizonDays ahead. Existing flights are skipped, so it is
+// safe to retry.
+func (a *Activities) MaterializeScheduledFlights(ctx context.Context, input MaterializeScheduledFlightsInput) (*MaterializeScheduledFlightsOutput, error) {
+	logger := activity.GetLogger(ctx)
+	logger.Info("Materializing scheduled flights", "scheduleId", input.ScheduleID, "horizonDays", input.HorizonDays)
+
+	if input.HorizonDays <= 0 {
+		return nil, fmt.Errorf("invalid horizon: %d days", input.HorizonDays)
+	}
+
+	var scheduleID *uuid.UUID
+	if input.ScheduleID != "" {
+		id, err := uuid.Parse(input.ScheduleID)
+		if err != nil {
+			return nil, fmt.Errorf("invalid schedule ID: %w", err)
+		}
+		scheduleID = &id
+	}
+
+	created, err := a.repo.MaterializeScheduledFlights(ctx, scheduleID, input.HorizonDays)
+	if err != nil {
+		return nil, fmt.Errorf("failed to materialize scheduled flights: %w", err)
+	}
+
+	logger.Info("Scheduled flights materialized", "flightsCreated", created)
+	return &MaterializeScheduledFlightsOutput{FlightsCreated: created}, nil
+}
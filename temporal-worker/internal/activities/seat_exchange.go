@@ -0,0 +1,159 @@
+package activities
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/cx-tal-miterani/flight-booking-system/temporal-worker/internal/repository"
+	"github.com/google/uuid"
+	"go.temporal.io/sdk/activity"
+)
+
+// ChargeSeatExchangeInput is the input for ChargeSeatExchange activity
+type ChargeSeatExchangeInput struct {
+	ExchangeID  string  `json:"exchangeId"`
+	OrderID     string  `json:"orderId"`
+	PaymentCode string  `json:"paymentCode"`
+	Amount      float64 `json:"amount"`
+}
+
+// ChargeSeatExchange charges the fare difference of a seat change to a
+// payment code (simulated), like ValidatePayment does for bookings
+func (a *Activities) ChargeSeatExchange(ctx context.Context, input ChargeSeatExchangeInput) (*ValidatePaymentOutput, error) {
+	logger := activity.GetLogger(ctx)
+	logger.Info("Charging seat change", "exchangeId", input.ExchangeID, "orderId", input.OrderID, "amount", input.Amount)
+
+	if _, err := uuid.Parse(input.ExchangeID); err != nil {
+		return nil, fmt.Errorf("invalid exchange ID: %w", err)
+	}
+
+	result := simulatePayment(ctx, input.ExchangeID, input.PaymentCode)
+	if result.ErrorMessage != invalidPaymentCodeMessage {
+		payload := paymentEventPayload(result, input.Amount)
+		payload["seatExchangeId"] = input.ExchangeID
+		a.recordOrderEvent(ctx, input.OrderID, repository.OrderEventPaymentAttempted, payload)
+	}
+	if !result.Success {
+		logger.Info("Seat change payment failed", "exchangeId", input.ExchangeID, "error", result.ErrorMessage)
+	}
+	return result, nil
+}
+
+// CompleteSeatExchangeInput is the input for CompleteSeatExchange activity
+type CompleteSeatExchangeInput struct {
+	ExchangeID    string `json:"exchangeId"`
+	TransactionID string `json:"transactionId,omitempty"`
+}
+
+// CompleteSeatExchangeOutput is the output for CompleteSeatExchange activity
+type CompleteSeatExchangeOutput struct {
+	Success       bool   `json:"success"`
+	FailureReason string `json:"failureReason,omitempty"`
+	// Waitlists are the waitlists with customers waiting for released seats
+	Waitlists []WaitlistRef `json:"waitlists,omitempty"`
+}
+
+// CompleteSeatExchange books the new seats of a seat change and releases the
+// old ones. It does not succeed if the new seats are no longer held for the
+// order, e.g. because their hold expired.
+func (a *Activities) CompleteSeatExchange(ctx context.Context, input CompleteSeatExchangeInput) (*CompleteSeatExchangeOutput, error) {
+	logger := activity.GetLogger(ctx)
+
+	exchangeID, err := uuid.Parse(input.ExchangeID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid exchange ID: %w", err)
+	}
+
+	var transactionID *string
+	if input.TransactionID != "" {
+		transactionID = &input.TransactionID
+	}
+	waitlists, err := a.repo.CompleteSeatExchange(ctx, exchangeID, transactionID)
+	if errors.Is(err, repository.ErrSeatsNotHeld) || errors.Is(err, repository.ErrExchangeNotPending) {
+		logger.Info("Seat change could not be completed", "exchangeId", input.ExchangeID, "reason", err)
+		return &CompleteSeatExchangeOutput{FailureReason: err.Error()}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to complete seat exchange: %w", err)
+	}
+
+	logger.Info("Seat change completed", "exchangeId", input.ExchangeID)
+	return &CompleteSeatExchangeOutput{Success: true, Waitlists: waitlistRefs(waitlists)}, nil
+}
+
+// FailSeatExchangeInput is the input for FailSeatExchange activity
+type FailSeatExchangeInput struct {
+	ExchangeID string `json:"exchangeId"`
+	Reason     string `json:"reason"`
+}
+
+// FailSeatExchangeOutput is the output for FailSeatExchange activity
+type FailSeatExchangeOutput struct {
+	// Waitlists are the waitlists with customers waiting for released seats
+	Waitlists []WaitlistRef `json:"waitlists,omitempty"`
+}
+
+// FailSeatExchange fails a seat change and releases its new seats; the order
+// keeps its old seats. An exchange that is no longer pending is left alone,
+// so it is safe to retry.
+func (a *Activities) FailSeatExchange(ctx context.Context, input FailSeatExchangeInput) (*FailSeatExchangeOutput, error) {
+	logger := activity.GetLogger(ctx)
+
+	exchangeID, err := uuid.Parse(input.ExchangeID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid exchange ID: %w", err)
+	}
+
+	waitlists, err := a.repo.FailSeatExchange(ctx, exchangeID, input.Reason)
+	if errors.Is(err, repository.ErrExchangeNotPending) {
+		return &FailSeatExchangeOutput{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to fail seat exchange: %w", err)
+	}
+
+	logger.Info("Seat change failed", "exchangeId", input.ExchangeID, "reason", input.Reason)
+	return &FailSeatExchangeOutput{Waitlists: waitlistRefs(waitlists)}, nil
+}
+
+// CreditSeatExchangeInput is the input for CreditSeatExchange activity
+type CreditSeatExchangeInput struct {
+	ExchangeID string  `json:"exchangeId"`
+	OrderID    string  `json:"orderId"`
+	Amount     float64 `json:"amount"`
+}
+
+// CreditSeatExchangeOutput is the output for CreditSeatExchange activity
+type CreditSeatExchangeOutput struct {
+	CreditID string  `json:"creditId"`
+	Amount   float64 `json:"amount"`
+}
+
+// CreditSeatExchange credits an amount back to the customer of a seat change
+// (simulated) and records the credit on the exchange
+func (a *Activities) CreditSeatExchange(ctx context.Context, input CreditSeatExchangeInput) (*CreditSeatExchangeOutput, error) {
+	logger := activity.GetLogger(ctx)
+
+	exchangeID, err := uuid.Parse(input.ExchangeID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid exchange ID: %w", err)
+	}
+	if input.Amount <= 0 {
+		return nil, fmt.Errorf("invalid credit amount: %.2f", input.Amount)
+	}
+
+	// The credit ID is derived from the exchange so retries issue one credit
+	creditID := fmt.Sprintf("CRD-%s", input.ExchangeID[:8])
+	if err := a.repo.RecordSeatExchangeCredit(ctx, exchangeID, creditID); err != nil {
+		return nil, fmt.Errorf("failed to record seat exchange credit: %w", err)
+	}
+
+	a.recordOrderEvent(ctx, input.OrderID, repository.OrderEventRefundIssued, map[string]interface{}{
+		"refundId":       creditID,
+		"amount":         input.Amount,
+		"seatExchangeId": input.ExchangeID,
+	})
+	logger.Info("Seat change credited", "exchangeId", input.ExchangeID, "orderId", input.OrderID, "creditId", creditID, "amount", input.Amount)
+	return &CreditSeatExchangeOutput{CreditID: creditID, Amount: input.Amount}, nil
+}
@@ -0,0 +1,140 @@
+package activities
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/cx-tal-miterani/flight-booking-system/temporal-worker/internal/repository"
+	"github.com/google/uuid"
+	"go.temporal.io/sdk/activity"
+)
+
+// WaitlistRef identifies the waitlist of a class on a flight
+type WaitlistRef struct {
+	FlightID string `json:"flightId"`
+	Class    string `json:"class"`
+}
+
+func waitlistRefs(keys []repository.WaitlistKey) []WaitlistRef {
+	if len(keys) == 0 {
+		return nil
+	}
+	refs := make([]WaitlistRef, len(keys))
+	for i, k := range keys {
+		refs[i] = WaitlistRef{FlightID: k.FlightID.String(), Class: k.Class}
+	}
+	return refs
+}
+
+// OfferWaitlistSeatsInput is the input for OfferWaitlistSeats activity
+type OfferWaitlistSeatsInput struct {
+	FlightID  string    `json:"flightId"`
+	Class     string    `json:"class"`
+	HoldUntil time.Time `json:"holdUntil"`
+}
+
+// OfferWaitlistSeatsOutput is the output for OfferWaitlistSeats activity. An
+// empty EntryID means no seats were offered. Closed is set once the flight
+// is no longer sold and its waitlist has been expired.
+type OfferWaitlistSeatsOutput struct {
+	EntryID       string    `json:"entryId,omitempty"`
+	OrderID       string    `json:"orderId,omitempty"`
+	WorkflowID    string    `json:"workflowId,omitempty"`
+	CustomerName  string    `json:"customerName,omitempty"`
+	CustomerEmail string    `json:"customerEmail,omitempty"`
+	FlightNumber  string    `json:"flightNumber,omitempty"`
+	SeatIDs       []string  `json:"seatIds,omitempty"`
+	ExpiresAt     time.Time `json:"expiresAt,omitempty"`
+	Waiting       int       `json:"waiting"`
+	Closed        bool      `json:"closed,omitempty"`
+}
+
+// OfferWaitlistSeats offers available seats to the first customer on a
+// waitlist by creating an order that holds them until HoldUntil
+func (a *Activities) OfferWaitlistSeats(ctx context.Context, input OfferWaitlistSeatsInput) (*OfferWaitlistSeatsOutput, error) {
+	logger := activity.GetLogger(ctx)
+
+	flightID, err := uuid.Parse(input.FlightID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid flight ID: %w", err)
+	}
+	if input.Class == "" {
+		return nil, errors.New("invalid class: class is required")
+	}
+
+	offer, err := a.repo.OfferWaitlistSeats(ctx, flightID, input.Class, input.HoldUntil)
+	if errors.Is(err, repository.ErrFlightNotBookable) {
+		logger.Info("Waitlist closed", "flightId", input.FlightID, "class", input.Class, "reason", err)
+		return &OfferWaitlistSeatsOutput{Closed: true}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to offer waitlist seats: %w", err)
+	}
+
+	output := &OfferWaitlistSeatsOutput{Waiting: offer.Waiting}
+	if offer.EntryID == uuid.Nil {
+		return output, nil
+	}
+	output.EntryID = offer.EntryID.String()
+	output.OrderID = offer.OrderID.String()
+	output.WorkflowID = offer.WorkflowID
+	output.CustomerName = offer.CustomerName
+	output.CustomerEmail = offer.CustomerEmail
+	output.FlightNumber = offer.FlightNumber
+	output.ExpiresAt = offer.ExpiresAt
+	output.SeatIDs = make([]string, len(offer.SeatIDs))
+	for i, id := range offer.SeatIDs {
+		output.SeatIDs[i] = id.String()
+	}
+
+	logger.Info("Waitlist seats offered", "entryId", output.EntryID, "orderId", output.OrderID, "seats", len(output.SeatIDs))
+	return output, nil
+}
+
+// NotifyWaitlistOfferInput is the input for NotifyWaitlistOffer activity
+type NotifyWaitlistOfferInput struct {
+	EntryID       string    `json:"entryId"`
+	OrderID       string    `json:"orderId"`
+	CustomerEmail string    `json:"customerEmail"`
+	CustomerName  string    `json:"customerName"`
+	FlightNumber  string    `json:"flightNumber"`
+	SeatCount     int       `json:"seatCount"`
+	ExpiresAt     time.Time `json:"expiresAt"`
+}
+
+// NotifyWaitlistOffer tells a waitlisted customer seats are held for them
+// and until when they can pay for them (simulated)
+func (a *Activities) NotifyWaitlistOffer(ctx context.Context, input NotifyWaitlistOfferInput) error {
+	logger := activity.GetLogger(ctx)
+	logger.Info("Sending waitlist offer email",
+		"entryId", input.EntryID,
+		"orderId", input.OrderID,
+		"email", input.CustomerEmail,
+		"flightNumber", input.FlightNumber,
+		"seats", input.SeatCount,
+		"expiresAt", input.ExpiresAt,
+	)
+
+	// Simulate sending email
+	time.Sleep(500 * time.Millisecond)
+
+	logger.Info("Waitlist offer email sent successfully")
+	return nil
+}
+
+// CompleteWaitlistOfferInput is the input for CompleteWaitlistOffer activity
+type CompleteWaitlistOfferInput struct {
+	EntryID  string `json:"entryId"`
+	Accepted bool   `json:"accepted"`
+}
+
+// CompleteWaitlistOffer records whether the customer booked the offered seats
+func (a *Activities) CompleteWaitlistOffer(ctx context.Context, input CompleteWaitlistOfferInput) error {
+	entryID, err := uuid.Parse(input.EntryID)
+	if err != nil {
+		return fmt.Errorf("invalid waitlist entry ID: %w", err)
+	}
+	return a.repo.CompleteWaitlistOffer(ctx, entryID, input.Accepted)
+}
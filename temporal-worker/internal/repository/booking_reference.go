@@ -0,0 +1,57 @@
+package repository
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// bookingReferenceAlphabet leaves out 0, 1, I and O, which are easily
+// mistaken for one another when a reference is read out or typed
+const bookingReferenceAlphabet = "ABCDEFGHJKLMNPQRSTUVWXYZ23456789"
+
+// BookingReferenceLength is the length of a booking reference
+const BookingReferenceLength = 6
+
+// maxBookingReferenceAttempts bounds the references tried for an order
+// before giving up; with about a billion references, collisions are rare
+const maxBookingReferenceAttempts = 5
+
+// newBookingReference returns a random booking reference
+func newBookingReference() (string, error) {
+	max := big.NewInt(int64(len(bookingReferenceAlphabet)))
+	ref := make([]byte, BookingReferenceLength)
+	for i := range ref {
+		n, err := rand.Int(rand.Reader, max)
+		if err != nil {
+			return "", fmt.Errorf("failed to generate booking reference: %w", err)
+		}
+		ref[i] = bookingReferenceAlphabet[n.Int64()]
+	}
+	return string(ref), nil
+}
+
+// uniqueBookingReference returns a booking reference no order has yet. The
+// unique constraint on orders.booking_reference catches the unlikely case of
+// a concurrent confirmation picking the same one.
+func uniqueBookingReference(ctx context.Context, tx pgx.Tx) (string, error) {
+	for i := 0; i < maxBookingReferenceAttempts; i++ {
+		ref, err := newBookingReference()
+		if err != nil {
+			return "", err
+		}
+		var taken bool
+		err = tx.QueryRow(ctx, `SELECT EXISTS (SELECT 1 FROM orders WHERE booking_reference = $1)`, ref).Scan(&taken)
+		if err != nil {
+			return "", fmt.Errorf("failed to check booking reference: %w", err)
+		}
+		if !taken {
+			return ref, nil
+		}
+	}
+	return "", errors.New("failed to generate booking reference: all references tried are taken")
+}
@@ -0,0 +1,23 @@
+package repository
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewBookingReference(t *testing.T) {
+	seen := make(map[string]bool)
+	for i := 0; i < 100; i++ {
+		ref, err := newBookingReference()
+		require.NoError(t, err)
+		assert.Len(t, ref, BookingReferenceLength)
+		for _, c := range ref {
+			assert.True(t, strings.ContainsRune(bookingReferenceAlphabet, c), "unexpected character %q in %s", c, ref)
+		}
+		seen[ref] = true
+	}
+	assert.Greater(t, len(seen), 90)
+}
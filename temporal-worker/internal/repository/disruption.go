@@ -0,0 +1,447 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+var (
+	ErrNoSeatsAvailable = errors.New("not enough seats available")
+	ErrOfferClosed      = errors.New("rebooking offer is no longer pending")
+	ErrNotAlternative   = errors.New("flight is not one of the offered alternatives")
+	ErrNotRefundable    = errors.New("order cannot be refunded")
+)
+
+// Rebooking offer statuses
+const (
+	OfferStatusPending        = "pending"
+	OfferStatusAccepted       = "accepted"
+	OfferStatusDeclined       = "declined"
+	OfferStatusExpired        = "expired"
+	OfferStatusNoAlternatives = "no_alternatives"
+)
+
+// DisruptedOrder is a confirmed order with seats on a cancelled flight
+type DisruptedOrder struct {
+	OrderID       uuid.UUID
+	CustomerName  string
+	CustomerEmail string
+	SeatCount     int
+}
+
+// RebookingOffer offers a disrupted order a move to alternative flights
+type RebookingOffer struct {
+	OrderID              uuid.UUID
+	FlightID             uuid.UUID
+	WorkflowID           string
+	AlternativeFlightIDs []uuid.UUID
+	Status               string
+	ExpiresAt            time.Time
+}
+
+// GetDisruptedOrders returns the confirmed orders that have the flight as
+// one of their segments, with the number of seats they hold on it including
+// travelers booked without a seat
+func (r *Repository) GetDisruptedOrders(ctx context.Context, flightID uuid.UUID) ([]DisruptedOrder, error) {
+	rows, err := r.pool.Query(ctx, `
+		SELECT o.id, o.customer_name, o.customer_email, COUNT(s.id) + (
+			SELECT COUNT(*) FROM overbooked_seats ob
+			WHERE ob.order_id = o.id AND ob.flight_id = $1 AND ob.status = 'unassigned'
+		)
+		FROM orders o
+		JOIN order_segments seg ON seg.order_id = o.id AND seg.flight_id = $1
+		LEFT JOIN order_seats os ON os.order_id = o.id
+		LEFT JOIN seats s ON s.id = os.seat_id AND s.flight_id = $1
+		WHERE o.status = $2
+		GROUP BY o.id
+		ORDER BY o.created_at
+	`, flightID, OrderStatusConfirmed)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query disrupted orders: %w", err)
+	}
+	defer rows.Close()
+
+	var orders []DisruptedOrder
+	for rows.Next() {
+		var o DisruptedOrder
+		if err := rows.Scan(&o.OrderID, &o.CustomerName, &o.CustomerEmail, &o.SeatCount); err != nil {
+			return nil, fmt.Errorf("failed to scan disrupted order: %w", err)
+		}
+		orders = append(orders, o)
+	}
+
+	return orders, rows.Err()
+}
+
+// FindAlternativeFlights returns bookable flights on the same route as a
+// cancelled flight that depart within window of it and have enough seats.
+// For multi-flight orders the alternative must still connect with the
+// order's previous and next segments. Flights closest to the original
+// departure come first.
+func (r *Repository) FindAlternativeFlights(ctx context.Context, orderID, flightID uuid.UUID, seats int, window time.Duration, limit int) ([]uuid.UUID, error) {
+	rows, err := r.pool.Query(ctx, `
+		WITH seg AS (
+			SELECT segment_index FROM order_segments WHERE order_id = $1 AND flight_id = $2
+		), bounds AS (
+			SELECT
+				(SELECT f.arrival_time FROM order_segments os JOIN flights f ON f.id = os.flight_id
+				 WHERE os.order_id = $1 AND os.segment_index = seg.segment_index - 1) AS after,
+				(SELECT f.departure_time FROM order_segments os JOIN flights f ON f.id = os.flight_id
+				 WHERE os.order_id = $1 AND os.segment_index = seg.segment_index + 1) AS before
+			FROM seg
+		)
+		SELECT alt.id
+		FROM flights orig
+		JOIN flights alt ON alt.origin = orig.origin AND alt.destination = orig.destination AND alt.id <> orig.id
+		LEFT JOIN bounds ON TRUE
+		WHERE orig.id = $2
+		  AND alt.status IN ('scheduled', 'delayed')
+		  AND alt.departure_time > NOW()
+		  AND alt.departure_time BETWEEN orig.departure_time - $4::interval AND orig.departure_time + $4::interval
+		  AND alt.available_seats >= $3
+		  AND (bounds.after IS NULL OR alt.departure_time > bounds.after)
+		  AND (bounds.before IS NULL OR alt.arrival_time < bounds.before)
+		ORDER BY ABS(EXTRACT(EPOCH FROM alt.departure_time - orig.departure_time)), alt.id
+		LIMIT $5
+	`, orderID, flightID, seats, window, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find alternative flights: %w", err)
+	}
+	defer rows.Close()
+
+	var ids []uuid.UUID
+	for rows.Next() {
+		var id uuid.UUID
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("failed to scan flight: %w", err)
+		}
+		ids = append(ids, id)
+	}
+
+	return ids, rows.Err()
+}
+
+// SaveRebookingOffer creates the offer for an order, or replaces it if the
+// flight's disruption is being handled again
+func (r *Repository) SaveRebookingOffer(ctx context.Context, offer *RebookingOffer) error {
+	alternatives := offer.AlternativeFlightIDs
+	if alternatives == nil {
+		alternatives = []uuid.UUID{}
+	}
+
+	_, err := r.pool.Exec(ctx, `
+		INSERT INTO rebooking_offers (order_id, flight_id, workflow_id, alternative_flight_ids, status, expires_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		ON CONFLICT (order_id, flight_id) DO UPDATE
+		SET workflow_id = EXCLUDED.workflow_id,
+		    alternative_flight_ids = EXCLUDED.alternative_flight_ids,
+		    status = EXCLUDED.status,
+		    expires_at = EXCLUDED.expires_at,
+		    rebooked_flight_id = NULL,
+		    failure_reason = NULL,
+		    responded_at = NULL
+	`, offer.OrderID, offer.FlightID, offer.WorkflowID, alternatives, offer.Status, offer.ExpiresAt)
+	if err != nil {
+		return fmt.Errorf("failed to save rebooking offer: %w", err)
+	}
+	return nil
+}
+
+// SetRebookingFailure records why an accepted offer could not be carried
+// out; the offer stays pending so another alternative can be chosen
+func (r *Repository) SetRebookingFailure(ctx context.Context, orderID, flightID uuid.UUID, reason string) error {
+	_, err := r.pool.Exec(ctx, `
+		UPDATE rebooking_offers SET failure_reason = $3
+		WHERE order_id = $1 AND flight_id = $2
+	`, orderID, flightID, reason)
+	if err != nil {
+		return fmt.Errorf("failed to update rebooking offer: %w", err)
+	}
+	return nil
+}
+
+// RebookOrder moves an order's seats from a cancelled flight to newFlightID,
+// preferring seats in the same classes. Travelers booked without a seat get
+// one on the new flight. The customer keeps the fares they paid. The order's
+// segment is moved to the new flight and the offer is marked accepted, all
+// in one transaction. ErrNotAlternative is returned for a flight the offer
+// did not list.
+func (r *Repository) RebookOrder(ctx context.Context, orderID, flightID, newFlightID uuid.UUID) error {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	var offerStatus string
+	var rebookedFlightID *uuid.UUID
+	var offered bool
+	err = tx.QueryRow(ctx, `
+		SELECT status, rebooked_flight_id, $3 = ANY(alternative_flight_ids)
+		FROM rebooking_offers
+		WHERE order_id = $1 AND flight_id = $2
+		FOR UPDATE
+	`, orderID, flightID, newFlightID).Scan(&offerStatus, &rebookedFlightID, &offered)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return ErrNotFound
+		}
+		return fmt.Errorf("failed to get rebooking offer: %w", err)
+	}
+	if offerStatus == OfferStatusAccepted && rebookedFlightID != nil && *rebookedFlightID == newFlightID {
+		// Already rebooked by an earlier attempt
+		return nil
+	}
+	if offerStatus != OfferStatusPending {
+		return fmt.Errorf("%w: %s", ErrOfferClosed, offerStatus)
+	}
+	if !offered {
+		return fmt.Errorf("%w: flight %s", ErrNotAlternative, newFlightID)
+	}
+
+	// Share-locked so the flight cannot be cancelled before the order is
+	// on it
+	var newFlightStatus string
+	err = tx.QueryRow(ctx, `SELECT status FROM flights WHERE id = $1 FOR SHARE`, newFlightID).Scan(&newFlightStatus)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return ErrNotFound
+		}
+		return fmt.Errorf("failed to get flight: %w", err)
+	}
+	if newFlightStatus != "scheduled" && newFlightStatus != "delayed" {
+		return fmt.Errorf("%w: flight is %s", ErrFlightNotBookable, newFlightStatus)
+	}
+
+	// The order's seats on the cancelled flight, what was paid for them and
+	// who sits in them
+	rows, err := tx.Query(ctx, `
+		SELECT s.id, s.class, os.price, os.passenger_id
+		FROM order_seats os
+		JOIN seats s ON s.id = os.seat_id
+		WHERE os.order_id = $1 AND s.flight_id = $2
+		ORDER BY s.row_number, s.column_letter
+		FOR UPDATE OF s
+	`, orderID, flightID)
+	if err != nil {
+		return fmt.Errorf("failed to lock order seats: %w", err)
+	}
+	var oldSeats []uuid.UUID
+	var classes []string
+	var prices []float64
+	var passengers []*uuid.UUID
+	for rows.Next() {
+		var id uuid.UUID
+		var class string
+		var price float64
+		var passengerID *uuid.UUID
+		if err := rows.Scan(&id, &class, &price, &passengerID); err != nil {
+			rows.Close()
+			return fmt.Errorf("failed to scan seat: %w", err)
+		}
+		oldSeats = append(oldSeats, id)
+		classes = append(classes, class)
+		prices = append(prices, price)
+		passengers = append(passengers, passengerID)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("failed to lock order seats: %w", err)
+	}
+
+	// Travelers booked without a seat on the cancelled flight
+	rows, err = tx.Query(ctx, `
+		SELECT class, price, passenger_id FROM overbooked_seats
+		WHERE order_id = $1 AND flight_id = $2 AND status = 'unassigned'
+		ORDER BY created_at, id
+		FOR UPDATE
+	`, orderID, flightID)
+	if err != nil {
+		return fmt.Errorf("failed to lock overbooked seats: %w", err)
+	}
+	for rows.Next() {
+		var class string
+		var price float64
+		var passengerID *uuid.UUID
+		if err := rows.Scan(&class, &price, &passengerID); err != nil {
+			rows.Close()
+			return fmt.Errorf("failed to scan overbooked seat: %w", err)
+		}
+		classes = append(classes, class)
+		prices = append(prices, price)
+		passengers = append(passengers, passengerID)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("failed to lock overbooked seats: %w", err)
+	}
+	if len(prices) == 0 {
+		return ErrSegmentNoSeats
+	}
+
+	rows, err = tx.Query(ctx, `
+		SELECT id FROM seats
+		WHERE flight_id = $1 AND status = 'available'
+		ORDER BY (class = ANY($2)) DESC, row_number, column_letter
+		LIMIT $3
+		FOR UPDATE SKIP LOCKED
+	`, newFlightID, classes, len(prices))
+	if err != nil {
+		return fmt.Errorf("failed to find seats: %w", err)
+	}
+	var newSeats []uuid.UUID
+	for rows.Next() {
+		var id uuid.UUID
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			return fmt.Errorf("failed to scan seat: %w", err)
+		}
+		newSeats = append(newSeats, id)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("failed to find seats: %w", err)
+	}
+	if len(newSeats) < len(prices) {
+		return fmt.Errorf("%w: %d of %d seats", ErrNoSeatsAvailable, len(newSeats), len(prices))
+	}
+
+	_, err = tx.Exec(ctx, `
+		UPDATE seats SET status = 'booked', held_by_order = $1, held_until = NULL WHERE id = ANY($2)
+	`, orderID, newSeats)
+	if err != nil {
+		return fmt.Errorf("failed to book seats: %w", err)
+	}
+
+	_, err = tx.Exec(ctx, `
+		UPDATE seats SET status = 'available', held_by_order = NULL, held_until = NULL WHERE id = ANY($1)
+	`, oldSeats)
+	if err != nil {
+		return fmt.Errorf("failed to release seats: %w", err)
+	}
+
+	_, err = tx.Exec(ctx, `DELETE FROM order_seats WHERE order_id = $1 AND seat_id = ANY($2)`, orderID, oldSeats)
+	if err != nil {
+		return fmt.Errorf("failed to remove order seats: %w", err)
+	}
+
+	_, err = tx.Exec(ctx, `DELETE FROM overbooked_seats WHERE order_id = $1 AND flight_id = $2`, orderID, flightID)
+	if err != nil {
+		return fmt.Errorf("failed to remove overbooked seats: %w", err)
+	}
+
+	_, err = tx.Exec(ctx, `
+		INSERT INTO order_seats (order_id, seat_id, price, passenger_id)
+		SELECT $1, unnest($2::uuid[]), unnest($3::numeric[]), unnest($4::uuid[])
+	`, orderID, newSeats, prices, passengers)
+	if err != nil {
+		return fmt.Errorf("failed to add order seats: %w", err)
+	}
+
+	_, err = tx.Exec(ctx, `
+		UPDATE order_segments SET flight_id = $3 WHERE order_id = $1 AND flight_id = $2
+	`, orderID, flightID, newFlightID)
+	if err != nil {
+		return fmt.Errorf("failed to move order segment: %w", err)
+	}
+
+	_, err = tx.Exec(ctx, `
+		UPDATE orders SET flight_id = $3 WHERE id = $1 AND flight_id = $2
+	`, orderID, flightID, newFlightID)
+	if err != nil {
+		return fmt.Errorf("failed to move order: %w", err)
+	}
+
+	_, err = tx.Exec(ctx, `
+		UPDATE flights f
+		SET available_seats = (SELECT COUNT(*) FROM seats s WHERE s.flight_id = f.id AND s.status = 'available')
+		WHERE id IN ($1, $2)
+	`, flightID, newFlightID)
+	if err != nil {
+		return fmt.Errorf("failed to update seat counts: %w", err)
+	}
+
+	_, err = tx.Exec(ctx, `
+		UPDATE rebooking_offers
+		SET status = 'accepted', rebooked_flight_id = $3, failure_reason = NULL, responded_at = NOW()
+		WHERE order_id = $1 AND flight_id = $2
+	`, orderID, flightID, newFlightID)
+	if err != nil {
+		return fmt.Errorf("failed to accept rebooking offer: %w", err)
+	}
+
+	return tx.Commit(ctx)
+}
+
+// RefundOrder releases every seat of a confirmed order, marks it refunded and
+// closes its rebooking offer with offerStatus. It returns the refunded
+// amount. Refunding an already refunded order is a no-op.
+func (r *Repository) RefundOrder(ctx context.Context, orderID, flightID uuid.UUID, offerStatus string) (float64, error) {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	var status OrderStatus
+	var amount float64
+	err = tx.QueryRow(ctx, `
+		SELECT status, total_amount FROM orders WHERE id = $1 FOR UPDATE
+	`, orderID).Scan(&status, &amount)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return 0, ErrNotFound
+		}
+		return 0, fmt.Errorf("failed to get order: %w", err)
+	}
+	if status == OrderStatusRefunded {
+		return amount, nil
+	}
+	if status != OrderStatusConfirmed {
+		return 0, fmt.Errorf("%w: order is %s", ErrNotRefundable, status)
+	}
+
+	_, err = tx.Exec(ctx, `
+		UPDATE seats
+		SET status = 'available', held_until = NULL, held_by_order = NULL
+		WHERE held_by_order = $1
+	`, orderID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to release seats: %w", err)
+	}
+
+	_, err = tx.Exec(ctx, `
+		UPDATE flights f
+		SET available_seats = (
+			SELECT COUNT(*) FROM seats s
+			WHERE s.flight_id = f.id AND s.status = 'available'
+		)
+		WHERE id IN (SELECT flight_id FROM order_segments WHERE order_id = $1)
+	`, orderID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to update available seats: %w", err)
+	}
+
+	_, err = tx.Exec(ctx, `UPDATE orders SET status = $1, refunded_amount = total_amount WHERE id = $2`, OrderStatusRefunded, orderID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to update order status: %w", err)
+	}
+
+	_, err = tx.Exec(ctx, `
+		UPDATE rebooking_offers SET status = $3, responded_at = NOW()
+		WHERE order_id = $1 AND flight_id = $2 AND status = 'pending'
+	`, orderID, flightID, offerStatus)
+	if err != nil {
+		return 0, fmt.Errorf("failed to update rebooking offer: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return 0, fmt.Errorf("failed to commit refund: %w", err)
+	}
+	return amount, nil
+}
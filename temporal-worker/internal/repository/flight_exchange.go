@@ -0,0 +1,520 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+var (
+	// ErrFlightExchangeClosed is returned when a flight exchange is not in
+	// the state a step expects, e.g. it has already failed
+	ErrFlightExchangeClosed = errors.New("flight exchange is no longer in progress")
+	// ErrExchangeNotAllowed is returned when an order cannot change flights
+	ErrExchangeNotAllowed = errors.New("order cannot change flights")
+)
+
+// Flight exchange statuses
+const (
+	FlightExchangePending   = "pending"
+	FlightExchangeHeld      = "held"
+	FlightExchangeConfirmed = "confirmed"
+	FlightExchangeCompleted = "completed"
+	FlightExchangeFailed    = "failed"
+)
+
+// FlightExchangeQuote is what a flight exchange costs once its seats are
+// held. A negative FareDifference means the new seats are cheaper.
+type FlightExchangeQuote struct {
+	FareDifference float64
+	ChangeFee      float64
+	Seats          int
+}
+
+// flightExchange is a flight exchange locked for one of its steps
+type flightExchange struct {
+	orderID       uuid.UUID
+	fromFlightID  uuid.UUID
+	toFlightID    uuid.UUID
+	oldSeats      []uuid.UUID
+	newSeats      []uuid.UUID
+	status        string
+	holdExpiresAt time.Time
+	quote         FlightExchangeQuote
+}
+
+// lockFlightExchange locks a flight exchange
+func lockFlightExchange(ctx context.Context, tx pgx.Tx, id uuid.UUID) (*flightExchange, error) {
+	var ex flightExchange
+	var fareDifference, changeFee *float64
+	err := tx.QueryRow(ctx, `
+		SELECT order_id, from_flight_id, to_flight_id, old_seat_ids, new_seat_ids, status,
+		       hold_expires_at, fare_difference, change_fee
+		FROM flight_exchanges
+		WHERE id = $1
+		FOR UPDATE
+	`, id).Scan(&ex.orderID, &ex.fromFlightID, &ex.toFlightID, &ex.oldSeats, &ex.newSeats, &ex.status,
+		&ex.holdExpiresAt, &fareDifference, &changeFee)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to lock flight exchange: %w", err)
+	}
+	if fareDifference != nil && changeFee != nil {
+		ex.quote = FlightExchangeQuote{FareDifference: *fareDifference, ChangeFee: *changeFee, Seats: len(ex.newSeats)}
+	}
+	return &ex, nil
+}
+
+// HoldFlightExchangeSeats holds seats on the target flight of a pending
+// flight exchange, one for every seat the order has on the flight it leaves,
+// preferring the same classes. The seats are held for the order until the
+// exchange's hold expiry. The fare difference between the new seats' fares
+// and surcharges and what was paid for the old seats is recorded with a
+// change fee of feePerSeat per seat, and returned. Holding the seats of an
+// exchange that already holds them returns the recorded quote, so it is
+// safe to retry. A seats_held event is published when the transaction
+// commits.
+func (r *Repository) HoldFlightExchangeSeats(ctx context.Context, id uuid.UUID, feePerSeat float64) (*FlightExchangeQuote, error) {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	ex, err := lockFlightExchange(ctx, tx, id)
+	if err != nil {
+		return nil, err
+	}
+	if ex.status == FlightExchangeHeld {
+		return &ex.quote, nil
+	}
+	if ex.status != FlightExchangePending {
+		return nil, fmt.Errorf("%w: exchange is %s", ErrFlightExchangeClosed, ex.status)
+	}
+
+	var orderStatus OrderStatus
+	err = tx.QueryRow(ctx, `SELECT status FROM orders WHERE id = $1 FOR UPDATE`, ex.orderID).Scan(&orderStatus)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("failed to get order: %w", err)
+	}
+	if orderStatus != OrderStatusConfirmed {
+		return nil, fmt.Errorf("%w: order is %s", ErrExchangeNotAllowed, orderStatus)
+	}
+
+	var flightStatus string
+	err = tx.QueryRow(ctx, `SELECT status FROM flights WHERE id = $1`, ex.toFlightID).Scan(&flightStatus)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("failed to get flight: %w", err)
+	}
+	if flightStatus != "scheduled" && flightStatus != "delayed" {
+		return nil, fmt.Errorf("%w: flight is %s", ErrFlightNotBookable, flightStatus)
+	}
+
+	var unassigned bool
+	err = tx.QueryRow(ctx, `
+		SELECT EXISTS (
+			SELECT 1 FROM overbooked_seats
+			WHERE order_id = $1 AND flight_id = $2 AND status = 'unassigned'
+		)
+	`, ex.orderID, ex.fromFlightID).Scan(&unassigned)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check overbooked seats: %w", err)
+	}
+	if unassigned {
+		return nil, fmt.Errorf("%w: travelers without a seat must be assigned one first", ErrExchangeNotAllowed)
+	}
+
+	// The order's seats on the flight it leaves and their classes
+	rows, err := tx.Query(ctx, `
+		SELECT s.id, s.class
+		FROM order_seats os
+		JOIN seats s ON s.id = os.seat_id
+		WHERE os.order_id = $1 AND s.flight_id = $2
+		ORDER BY s.row_number, s.column_letter
+		FOR UPDATE OF s
+	`, ex.orderID, ex.fromFlightID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to lock order seats: %w", err)
+	}
+	var oldSeats []uuid.UUID
+	var classes []string
+	for rows.Next() {
+		var seatID uuid.UUID
+		var class string
+		if err := rows.Scan(&seatID, &class); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("failed to scan seat: %w", err)
+		}
+		oldSeats = append(oldSeats, seatID)
+		classes = append(classes, class)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to lock order seats: %w", err)
+	}
+	if len(oldSeats) == 0 {
+		return nil, ErrSegmentNoSeats
+	}
+
+	rows, err = tx.Query(ctx, `
+		SELECT id FROM seats
+		WHERE flight_id = $1 AND status = 'available'
+		ORDER BY (class = ANY($2)) DESC, row_number, column_letter
+		LIMIT $3
+		FOR UPDATE SKIP LOCKED
+	`, ex.toFlightID, classes, len(oldSeats))
+	if err != nil {
+		return nil, fmt.Errorf("failed to find seats: %w", err)
+	}
+	var newSeats []uuid.UUID
+	for rows.Next() {
+		var seatID uuid.UUID
+		if err := rows.Scan(&seatID); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("failed to scan seat: %w", err)
+		}
+		newSeats = append(newSeats, seatID)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to find seats: %w", err)
+	}
+	if len(newSeats) < len(oldSeats) {
+		return nil, fmt.Errorf("%w: %d of %d seats", ErrNoSeatsAvailable, len(newSeats), len(oldSeats))
+	}
+
+	// The old seats are worth what was paid for them, the new ones their
+	// current fare and surcharges
+	quote := FlightExchangeQuote{ChangeFee: feePerSeat * float64(len(newSeats)), Seats: len(newSeats)}
+	err = tx.QueryRow(ctx, `
+		SELECT (SELECT COALESCE(SUM(s.price + COALESCE((
+		            SELECT SUM(a.surcharge) FROM seat_attribute_surcharges a WHERE a.attribute = ANY(s.attributes)
+		        ), 0)), 0) FROM seats s WHERE s.id = ANY($2))
+		     - (SELECT COALESCE(SUM(price), 0) FROM order_seats WHERE order_id = $1 AND seat_id = ANY($3))
+	`, ex.orderID, newSeats, oldSeats).Scan(&quote.FareDifference)
+	if err != nil {
+		return nil, fmt.Errorf("failed to price flight exchange: %w", err)
+	}
+
+	_, err = tx.Exec(ctx, `
+		UPDATE seats SET status = 'held', held_by_order = $1, held_until = $2 WHERE id = ANY($3)
+	`, ex.orderID, ex.holdExpiresAt, newSeats)
+	if err != nil {
+		return nil, fmt.Errorf("failed to hold seats: %w", err)
+	}
+
+	_, err = tx.Exec(ctx, `
+		UPDATE flights f
+		SET available_seats = (SELECT COUNT(*) FROM seats s WHERE s.flight_id = f.id AND s.status = 'available')
+		WHERE f.id = $1
+	`, ex.toFlightID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update seat counts: %w", err)
+	}
+
+	_, err = tx.Exec(ctx, `
+		UPDATE flight_exchanges
+		SET status = 'held', old_seat_ids = $2, new_seat_ids = $3, fare_difference = $4, change_fee = $5
+		WHERE id = $1
+	`, id, oldSeats, newSeats, quote.FareDifference, quote.ChangeFee)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update flight exchange: %w", err)
+	}
+
+	flightOf := func(uuid.UUID) uuid.UUID { return ex.toFlightID }
+	if err := publishSeatEvents(ctx, tx, SeatEventSeatsHeld, ex.orderID, newSeats, flightOf); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit flight exchange: %w", err)
+	}
+	return &quote, nil
+}
+
+// ConfirmFlightExchange books the held seats of a flight exchange for the
+// order and moves the order to the target flight: the new seats are priced
+// at their fares, keep the passengers of the old seats they replace and the
+// order's segment moves to the new flight. transactionID, if any, is
+// recorded for the amount charged. The old seats stay booked by the order
+// until ReleaseFlightExchangeSeats. If a new seat is no longer held for the
+// order nothing changes and ErrSeatsNotHeld is returned. Confirming a
+// confirmed exchange is a no-op. A seats_booked event is published when the
+// transaction commits.
+func (r *Repository) ConfirmFlightExchange(ctx context.Context, id uuid.UUID, transactionID *string) error {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	ex, err := lockFlightExchange(ctx, tx, id)
+	if err != nil {
+		return err
+	}
+	if ex.status == FlightExchangeConfirmed || ex.status == FlightExchangeCompleted {
+		return nil
+	}
+	if ex.status != FlightExchangeHeld {
+		return fmt.Errorf("%w: exchange is %s", ErrFlightExchangeClosed, ex.status)
+	}
+
+	var held int
+	err = tx.QueryRow(ctx, `
+		SELECT COUNT(*) FROM (
+			SELECT id FROM seats
+			WHERE id = ANY($1) AND status = 'held' AND held_by_order = $2
+			ORDER BY id
+			FOR UPDATE
+		) s
+	`, ex.newSeats, ex.orderID).Scan(&held)
+	if err != nil {
+		return fmt.Errorf("failed to lock seats: %w", err)
+	}
+	if held != len(ex.newSeats) {
+		return fmt.Errorf("%w: %d of %d seats", ErrSeatsNotHeld, held, len(ex.newSeats))
+	}
+
+	_, err = tx.Exec(ctx, `UPDATE seats SET status = 'booked', held_until = NULL WHERE id = ANY($1)`, ex.newSeats)
+	if err != nil {
+		return fmt.Errorf("failed to book seats: %w", err)
+	}
+
+	// Seats are charged their class fare plus the surcharges of their
+	// attributes, and keep the passenger of the seat they replace
+	_, err = tx.Exec(ctx, `
+		INSERT INTO order_seats (order_id, seat_id, price, passenger_id)
+		SELECT $1, s.id, s.price + COALESCE((
+		    SELECT SUM(a.surcharge) FROM seat_attribute_surcharges a WHERE a.attribute = ANY(s.attributes)
+		), 0), os.passenger_id
+		FROM unnest($2::uuid[], $3::uuid[]) AS x(old_seat_id, new_seat_id)
+		JOIN seats s ON s.id = x.new_seat_id
+		LEFT JOIN order_seats os ON os.order_id = $1 AND os.seat_id = x.old_seat_id
+	`, ex.orderID, ex.oldSeats, ex.newSeats)
+	if err != nil {
+		return fmt.Errorf("failed to add order seats: %w", err)
+	}
+
+	_, err = tx.Exec(ctx, `DELETE FROM order_seats WHERE order_id = $1 AND seat_id = ANY($2)`, ex.orderID, ex.oldSeats)
+	if err != nil {
+		return fmt.Errorf("failed to remove order seats: %w", err)
+	}
+
+	_, err = tx.Exec(ctx, `
+		UPDATE order_segments SET flight_id = $3 WHERE order_id = $1 AND flight_id = $2
+	`, ex.orderID, ex.fromFlightID, ex.toFlightID)
+	if err != nil {
+		return fmt.Errorf("failed to move order segment: %w", err)
+	}
+
+	_, err = tx.Exec(ctx, `
+		UPDATE orders SET flight_id = $3 WHERE id = $1 AND flight_id = $2
+	`, ex.orderID, ex.fromFlightID, ex.toFlightID)
+	if err != nil {
+		return fmt.Errorf("failed to move order: %w", err)
+	}
+
+	_, err = tx.Exec(ctx, `
+		UPDATE orders SET total_amount = (SELECT SUM(price) FROM order_seats WHERE order_id = $1) WHERE id = $1
+	`, ex.orderID)
+	if err != nil {
+		return fmt.Errorf("failed to update order total: %w", err)
+	}
+
+	_, err = tx.Exec(ctx, `
+		UPDATE flight_exchanges SET status = 'confirmed', transaction_id = $2 WHERE id = $1
+	`, id, transactionID)
+	if err != nil {
+		return fmt.Errorf("failed to confirm flight exchange: %w", err)
+	}
+
+	flightOf := func(uuid.UUID) uuid.UUID { return ex.toFlightID }
+	if err := publishSeatEvents(ctx, tx, SeatEventSeatsBooked, ex.orderID, ex.newSeats, flightOf); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit flight exchange: %w", err)
+	}
+	return nil
+}
+
+// ReleaseFlightExchangeSeats releases the old seats of a confirmed flight
+// exchange that are still booked by the order and completes the exchange.
+// Releasing the seats of a completed exchange is a no-op. A seats_released
+// event is published when the transaction commits. It returns the waitlists
+// with customers waiting for the released seats.
+func (r *Repository) ReleaseFlightExchangeSeats(ctx context.Context, id uuid.UUID) ([]WaitlistKey, error) {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	ex, err := lockFlightExchange(ctx, tx, id)
+	if err != nil {
+		return nil, err
+	}
+	if ex.status == FlightExchangeCompleted {
+		return nil, nil
+	}
+	if ex.status != FlightExchangeConfirmed {
+		return nil, fmt.Errorf("%w: exchange is %s", ErrFlightExchangeClosed, ex.status)
+	}
+
+	released, err := releaseOrderSeats(ctx, tx, ex.orderID, ex.oldSeats, "booked")
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = tx.Exec(ctx, `
+		UPDATE flight_exchanges SET status = 'completed', completed_at = NOW() WHERE id = $1
+	`, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to complete flight exchange: %w", err)
+	}
+
+	_, err = tx.Exec(ctx, `
+		UPDATE flights f
+		SET available_seats = (SELECT COUNT(*) FROM seats s WHERE s.flight_id = f.id AND s.status = 'available')
+		WHERE f.id = $1
+	`, ex.fromFlightID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update seat counts: %w", err)
+	}
+
+	waitlists, err := flightExchangeReleased(ctx, tx, ex.orderID, ex.fromFlightID, released)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit flight exchange: %w", err)
+	}
+	return waitlists, nil
+}
+
+// FailFlightExchange marks a flight exchange that has not been confirmed
+// failed with reason and releases its new seats that are still held for the
+// order; the order keeps its flight. An exchange that has already failed is
+// left alone, so it is safe to retry, and a confirmed one cannot fail. A
+// seats_released event is published when the transaction commits. It
+// returns the waitlists with customers waiting for the released seats.
+func (r *Repository) FailFlightExchange(ctx context.Context, id uuid.UUID, reason string) ([]WaitlistKey, error) {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	ex, err := lockFlightExchange(ctx, tx, id)
+	if err != nil {
+		return nil, err
+	}
+	if ex.status == FlightExchangeFailed {
+		return nil, nil
+	}
+	if ex.status != FlightExchangePending && ex.status != FlightExchangeHeld {
+		return nil, fmt.Errorf("%w: exchange is %s", ErrFlightExchangeClosed, ex.status)
+	}
+
+	released, err := releaseOrderSeats(ctx, tx, ex.orderID, ex.newSeats, "held")
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = tx.Exec(ctx, `
+		UPDATE flight_exchanges SET status = 'failed', failure_reason = $2, completed_at = NOW() WHERE id = $1
+	`, id, reason)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fail flight exchange: %w", err)
+	}
+
+	_, err = tx.Exec(ctx, `
+		UPDATE flights f
+		SET available_seats = (SELECT COUNT(*) FROM seats s WHERE s.flight_id = f.id AND s.status = 'available')
+		WHERE f.id = $1
+	`, ex.toFlightID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update seat counts: %w", err)
+	}
+
+	waitlists, err := flightExchangeReleased(ctx, tx, ex.orderID, ex.toFlightID, released)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit flight exchange: %w", err)
+	}
+	return waitlists, nil
+}
+
+// RecordFlightExchangeRefund records the refund issued for a flight exchange
+func (r *Repository) RecordFlightExchangeRefund(ctx context.Context, id uuid.UUID, refundID string) error {
+	tag, err := r.pool.Exec(ctx, `UPDATE flight_exchanges SET refund_id = $2 WHERE id = $1`, id, refundID)
+	if err != nil {
+		return fmt.Errorf("failed to record flight exchange refund: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// releaseOrderSeats makes the seats among seatIDs that are in status for the
+// order available again and returns them
+func releaseOrderSeats(ctx context.Context, tx pgx.Tx, orderID uuid.UUID, seatIDs []uuid.UUID, status string) ([]uuid.UUID, error) {
+	rows, err := tx.Query(ctx, `
+		UPDATE seats
+		SET status = 'available', held_by_order = NULL, held_until = NULL
+		WHERE id = ANY($1) AND held_by_order = $2 AND status = $3
+		RETURNING id
+	`, seatIDs, orderID, status)
+	if err != nil {
+		return nil, fmt.Errorf("failed to release seats: %w", err)
+	}
+	defer rows.Close()
+
+	var released []uuid.UUID
+	for rows.Next() {
+		var seatID uuid.UUID
+		if err := rows.Scan(&seatID); err != nil {
+			return nil, fmt.Errorf("failed to scan released seat: %w", err)
+		}
+		released = append(released, seatID)
+	}
+	return released, rows.Err()
+}
+
+// flightExchangeReleased publishes a seats_released event for seats released
+// on a flight by a flight exchange and returns the waitlists with customers
+// waiting for them
+func flightExchangeReleased(ctx context.Context, tx pgx.Tx, orderID, flightID uuid.UUID, released []uuid.UUID) ([]WaitlistKey, error) {
+	ids := make([]string, len(released))
+	for i, seatID := range released {
+		ids[i] = seatID.String()
+	}
+	waitlists, err := waitlistsForSeats(ctx, tx, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	flightOf := func(uuid.UUID) uuid.UUID { return flightID }
+	if err := publishSeatEvents(ctx, tx, SeatEventSeatsReleased, orderID, released, flightOf); err != nil {
+		return nil, err
+	}
+	return waitlists, nil
+}
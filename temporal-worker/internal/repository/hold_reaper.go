@@ -0,0 +1,238 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// SeatEventsChannel is the PostgreSQL notification channel the worker
+// publishes seat changes on. The API server listens on it and relays the
+// events to WebSocket clients.
+const SeatEventsChannel = "seat_events"
+
+// SeatEventSeatsReleased is published when seats become available again
+const SeatEventSeatsReleased = "seats_released"
+
+// SeatEventSeatsHeld is published when the worker holds seats for an order,
+// e.g. seats offered to a waitlisted customer
+const SeatEventSeatsHeld = "seats_held"
+
+// SeatEventSeatsBooked is published when the worker books seats for an order
+// outside the booking flow, e.g. the new seats of a seat change
+const SeatEventSeatsBooked = "seats_booked"
+
+// SeatEvent is the payload of a seat_events notification, for the seats of
+// one order on one flight
+type SeatEvent struct {
+	Type     string   `json:"type"`
+	FlightID string   `json:"flightId"`
+	OrderID  string   `json:"orderId,omitempty"`
+	SeatIDs  []string `json:"seatIds"`
+}
+
+// maxSeatEventSeats bounds the seats listed in one seat_events notification.
+// A seat ID takes 39 bytes of the payload, which PostgreSQL limits to 8000
+// bytes.
+const maxSeatEventSeats = 150
+
+// seatEventPayloads encodes event as notification payloads of at most
+// maxSeatEventSeats seats each
+func seatEventPayloads(event SeatEvent) ([]string, error) {
+	seatIDs := event.SeatIDs
+	var payloads []string
+	for len(payloads) == 0 || len(seatIDs) > 0 {
+		n := len(seatIDs)
+		if n > maxSeatEventSeats {
+			n = maxSeatEventSeats
+		}
+		event.SeatIDs = seatIDs[:n]
+		seatIDs = seatIDs[n:]
+
+		payload, err := json.Marshal(event)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode seat event: %w", err)
+		}
+		payloads = append(payloads, string(payload))
+	}
+	return payloads, nil
+}
+
+// notifySeatEvent publishes event on SeatEventsChannel, split over several
+// notifications when it lists too many seats for one. Notifications are
+// only delivered if tx commits.
+func notifySeatEvent(ctx context.Context, tx pgx.Tx, event SeatEvent) error {
+	payloads, err := seatEventPayloads(event)
+	if err != nil {
+		return err
+	}
+	for _, payload := range payloads {
+		if _, err := tx.Exec(ctx, `SELECT pg_notify($1, $2)`, SeatEventsChannel, payload); err != nil {
+			return fmt.Errorf("failed to publish seat event: %w", err)
+		}
+	}
+	return nil
+}
+
+// ExpiredHolds is the outcome of releasing a batch of expired holds or
+// seat blocks
+type ExpiredHolds struct {
+	SeatsReleased int
+	OrdersExpired int
+	// Waitlists are the waitlists with customers waiting for released seats
+	Waitlists []WaitlistKey
+}
+
+// ReleaseExpiredHolds releases up to limit seats whose hold has expired,
+// oldest first, and marks the orders that held them expired. Seats of orders
+// whose payment is being processed are left to the booking workflow. Seats
+// locked by another transaction are skipped, so concurrent runs do not
+// block each other. The available seat counts of the affected flights are
+// updated and a seats_released event is published for each order and flight
+// when the transaction commits.
+func (r *Repository) ReleaseExpiredHolds(ctx context.Context, limit int) (*ExpiredHolds, error) {
+	return r.releaseExpiredSeats(ctx, `
+		UPDATE seats s
+		SET status = 'available', held_until = NULL, held_by_order = NULL
+		FROM (
+			SELECT id, held_by_order FROM seats
+			WHERE status = 'held' AND held_until < CURRENT_TIMESTAMP
+			  AND NOT EXISTS (
+				SELECT 1 FROM orders o WHERE o.id = seats.held_by_order AND o.status = 'processing'
+			  )
+			ORDER BY held_until
+			LIMIT $1
+			FOR UPDATE SKIP LOCKED
+		) e
+		WHERE s.id = e.id
+		RETURNING s.id, s.flight_id, e.held_by_order
+	`, limit)
+}
+
+// ReleaseExpiredBlocks lifts up to limit admin seat blocks whose
+// blocked_until has passed, oldest first, and makes their seats available.
+// Blocks without an expiry last until the seats are unblocked. Seats locked
+// by another transaction are skipped. The available seat counts of the
+// affected flights are updated and a seats_released event is published for
+// each flight when the transaction commits.
+func (r *Repository) ReleaseExpiredBlocks(ctx context.Context, limit int) (*ExpiredHolds, error) {
+	return r.releaseExpiredSeats(ctx, `
+		UPDATE seats s
+		SET status = 'available',
+		    block_reason = NULL, block_note = NULL, blocked_by = NULL, blocked_at = NULL, blocked_until = NULL
+		FROM (
+			SELECT id FROM seats
+			WHERE status = 'blocked' AND blocked_until < CURRENT_TIMESTAMP
+			ORDER BY blocked_until
+			LIMIT $1
+			FOR UPDATE SKIP LOCKED
+		) e
+		WHERE s.id = e.id
+		RETURNING s.id, s.flight_id, NULL::uuid
+	`, limit)
+}
+
+// releaseExpiredSeats runs query, which makes up to $1 seats available and
+// returns their ID, flight and the order that held them, if any. It then
+// expires those orders, updates the flights' seat counts and publishes the
+// released seats, all in one transaction.
+func (r *Repository) releaseExpiredSeats(ctx context.Context, query string, limit int) (*ExpiredHolds, error) {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	rows, err := tx.Query(ctx, query, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to release expired seats: %w", err)
+	}
+
+	type flightOrder struct {
+		flightID uuid.UUID
+		orderID  uuid.UUID
+	}
+	var released []flightOrder
+	seatIDs := make(map[flightOrder][]string)
+	var flightIDs, orderIDs []uuid.UUID
+	for rows.Next() {
+		var seatID, flightID uuid.UUID
+		var orderID *uuid.UUID
+		if err := rows.Scan(&seatID, &flightID, &orderID); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("failed to scan released seat: %w", err)
+		}
+		key := flightOrder{flightID: flightID}
+		if orderID != nil {
+			key.orderID = *orderID
+		}
+		if _, ok := seatIDs[key]; !ok {
+			released = append(released, key)
+			flightIDs = append(flightIDs, flightID)
+			if orderID != nil {
+				orderIDs = append(orderIDs, *orderID)
+			}
+		}
+		seatIDs[key] = append(seatIDs[key], seatID.String())
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to release expired seats: %w", err)
+	}
+	if len(released) == 0 {
+		return &ExpiredHolds{}, nil
+	}
+
+	result := &ExpiredHolds{}
+	tag, err := tx.Exec(ctx, `
+		UPDATE orders
+		SET status = 'expired'
+		WHERE id = ANY($1) AND status IN ('pending', 'seats_selected', 'awaiting_payment')
+	`, orderIDs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to expire orders: %w", err)
+	}
+	result.OrdersExpired = int(tag.RowsAffected())
+
+	_, err = tx.Exec(ctx, `
+		UPDATE flights f
+		SET available_seats = (SELECT COUNT(*) FROM seats s WHERE s.flight_id = f.id AND s.status = 'available')
+		WHERE f.id = ANY($1)
+	`, flightIDs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update available seats: %w", err)
+	}
+
+	var releasedIDs []string
+	for _, ids := range seatIDs {
+		releasedIDs = append(releasedIDs, ids...)
+	}
+	result.Waitlists, err = waitlistsForSeats(ctx, tx, releasedIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	// Notifications are only delivered if the transaction commits
+	for _, key := range released {
+		event := SeatEvent{
+			Type:     SeatEventSeatsReleased,
+			FlightID: key.flightID.String(),
+			SeatIDs:  seatIDs[key],
+		}
+		if key.orderID != uuid.Nil {
+			event.OrderID = key.orderID.String()
+		}
+		if err := notifySeatEvent(ctx, tx, event); err != nil {
+			return nil, err
+		}
+		result.SeatsReleased += len(seatIDs[key])
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit released seats: %w", err)
+	}
+	return result, nil
+}
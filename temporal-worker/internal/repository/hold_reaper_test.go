@@ -0,0 +1,49 @@
+package repository
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSeatEventPayloads_SplitsLargeBatches(t *testing.T) {
+	// A whole widebody cabin released at once
+	seatIDs := make([]string, 500)
+	for i := range seatIDs {
+		seatIDs[i] = uuid.New().String()
+	}
+	event := SeatEvent{
+		Type:     SeatEventSeatsReleased,
+		FlightID: uuid.New().String(),
+		OrderID:  uuid.New().String(),
+		SeatIDs:  seatIDs,
+	}
+
+	payloads, err := seatEventPayloads(event)
+	require.NoError(t, err)
+	require.Len(t, payloads, 4)
+
+	var published []string
+	for _, payload := range payloads {
+		assert.Less(t, len(payload), 8000, "PostgreSQL rejects NOTIFY payloads of 8000 bytes or more")
+
+		var decoded SeatEvent
+		require.NoError(t, json.Unmarshal([]byte(payload), &decoded))
+		assert.Equal(t, event.Type, decoded.Type)
+		assert.Equal(t, event.FlightID, decoded.FlightID)
+		assert.Equal(t, event.OrderID, decoded.OrderID)
+		published = append(published, decoded.SeatIDs...)
+	}
+	assert.Equal(t, seatIDs, published)
+}
+
+func TestSeatEventPayloads_SmallEvent(t *testing.T) {
+	event := SeatEvent{Type: SeatEventSeatsHeld, FlightID: "flight-1", SeatIDs: []string{"seat-1"}}
+
+	payloads, err := seatEventPayloads(event)
+	require.NoError(t, err)
+	assert.Equal(t, []string{`{"type":"seats_held","flightId":"flight-1","seatIds":["seat-1"]}`}, payloads)
+}
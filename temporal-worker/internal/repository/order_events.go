@@ -0,0 +1,36 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/uuid"
+)
+
+// OrderEventType is the kind of change recorded in an order's history.
+// Status, flight and seat changes are recorded by database triggers; the
+// worker records the money it moves.
+type OrderEventType string
+
+const (
+	// OrderEventPaymentAttempted is recorded for every charge of the order's
+	// customer, successful or not
+	OrderEventPaymentAttempted OrderEventType = "payment_attempted"
+	// OrderEventRefundIssued is recorded when money is returned to the
+	// order's customer
+	OrderEventRefundIssued OrderEventType = "refund_issued"
+)
+
+// RecordOrderEvent adds an event with payload to an order's history
+func (r *Repository) RecordOrderEvent(ctx context.Context, orderID uuid.UUID, eventType OrderEventType, payload map[string]interface{}) error {
+	if payload == nil {
+		payload = map[string]interface{}{}
+	}
+	_, err := r.pool.Exec(ctx, `
+		INSERT INTO order_events (order_id, event_type, payload) VALUES ($1, $2, $3)
+	`, orderID, eventType, payload)
+	if err != nil {
+		return fmt.Errorf("failed to record order event: %w", err)
+	}
+	return nil
+}
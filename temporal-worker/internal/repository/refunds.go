@@ -0,0 +1,84 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// ErrRefundNotPending is returned when a refund of a cancelled booking has
+// already failed
+var ErrRefundNotPending = errors.New("order refund is no longer pending")
+
+// CompleteOrderRefund records that a refund of a cancelled booking was paid
+// out as providerRefundID and adds it to the order's refunded amount. An
+// order cancelled as a whole becomes refunded. Completing an already
+// completed refund is a no-op.
+func (r *Repository) CompleteOrderRefund(ctx context.Context, id uuid.UUID, providerRefundID string) error {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	var orderID uuid.UUID
+	var status string
+	var amount float64
+	var full bool
+	err = tx.QueryRow(ctx, `
+		SELECT order_id, status, amount, cardinality(passenger_ids) = 0
+		FROM order_refunds WHERE id = $1 FOR UPDATE
+	`, id).Scan(&orderID, &status, &amount, &full)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return ErrNotFound
+		}
+		return fmt.Errorf("failed to get order refund: %w", err)
+	}
+	switch status {
+	case "completed":
+		return nil
+	case "pending":
+	default:
+		return fmt.Errorf("%w: refund is %s", ErrRefundNotPending, status)
+	}
+
+	_, err = tx.Exec(ctx, `
+		UPDATE order_refunds SET status = 'completed', provider_refund_id = $2, completed_at = NOW() WHERE id = $1
+	`, id, providerRefundID)
+	if err != nil {
+		return fmt.Errorf("failed to complete order refund: %w", err)
+	}
+
+	_, err = tx.Exec(ctx, `
+		UPDATE orders
+		SET refunded_amount = refunded_amount + $2,
+		    status = CASE WHEN $3 AND status = 'cancelled' THEN 'refunded'::order_status ELSE status END
+		WHERE id = $1
+	`, orderID, amount, full)
+	if err != nil {
+		return fmt.Errorf("failed to update order: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit order refund: %w", err)
+	}
+	return nil
+}
+
+// FailOrderRefund marks a pending refund of a cancelled booking failed with
+// reason, leaving it for support to pay out
+func (r *Repository) FailOrderRefund(ctx context.Context, id uuid.UUID, reason string) error {
+	_, err := r.pool.Exec(ctx, `
+		UPDATE order_refunds
+		SET status = 'failed', failure_reason = $2, completed_at = NOW()
+		WHERE id = $1 AND status = 'pending'
+	`, id, reason)
+	if err != nil {
+		return fmt.Errorf("failed to fail order refund: %w", err)
+	}
+	return nil
+}
@@ -12,7 +12,10 @@ import (
 )
 
 var (
-	ErrNotFound = errors.New("not found")
+	ErrNotFound          = errors.New("not found")
+	ErrSeatsNotHeld      = errors.New("seats no longer held for order")
+	ErrSegmentNoSeats    = errors.New("order segment has no seats")
+	ErrFlightNotBookable = errors.New("flight is not bookable")
 )
 
 // OrderStatus represents the status of an order
@@ -27,6 +30,7 @@ const (
 	OrderStatusFailed          OrderStatus = "failed"
 	OrderStatusCancelled       OrderStatus = "cancelled"
 	OrderStatusExpired         OrderStatus = "expired"
+	OrderStatusRefunded        OrderStatus = "refunded"
 )
 
 // Repository handles database operations for the worker
@@ -101,7 +105,7 @@ func (r *Repository) BookSeats(ctx context.Context, orderID uuid.UUID) error {
 			SELECT COUNT(*) FROM seats s
 			WHERE s.flight_id = f.id AND s.status = 'available'
 		)
-		WHERE id = (SELECT flight_id FROM orders WHERE id = $1)
+		WHERE id IN (SELECT flight_id FROM order_segments WHERE order_id = $1)
 	`, orderID)
 	if err != nil {
 		return fmt.Errorf("failed to update available seats: %w", err)
@@ -110,17 +114,161 @@ func (r *Repository) BookSeats(ctx context.Context, orderID uuid.UUID) error {
 	return tx.Commit(ctx)
 }
 
-// ReleaseSeats releases held seats
-func (r *Repository) ReleaseSeats(ctx context.Context, orderID uuid.UUID) error {
-	_, err := r.pool.Exec(ctx, `
+// ConfirmBooking books every seat of an order on all of its flight segments
+// and marks the order confirmed in a single transaction. If any seat is no
+// longer held by the order, a segment has no seats, or one of the flights is
+// no longer bookable, nothing is booked. It returns the booking reference
+// the order is given.
+func (r *Repository) ConfirmBooking(ctx context.Context, orderID uuid.UUID) (string, error) {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return "", fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	// Lock the order's seats and make sure they are all still held by it
+	rows, err := tx.Query(ctx, `
+		SELECT s.seat_number, s.status, s.held_by_order
+		FROM order_seats os
+		JOIN seats s ON s.id = os.seat_id
+		WHERE os.order_id = $1
+		ORDER BY s.id
+		FOR UPDATE OF s
+	`, orderID)
+	if err != nil {
+		return "", fmt.Errorf("failed to lock order seats: %w", err)
+	}
+	for rows.Next() {
+		var seatNumber, status string
+		var heldBy *uuid.UUID
+		if err := rows.Scan(&seatNumber, &status, &heldBy); err != nil {
+			rows.Close()
+			return "", fmt.Errorf("failed to scan seat: %w", err)
+		}
+		if status != "held" || heldBy == nil || *heldBy != orderID {
+			rows.Close()
+			return "", fmt.Errorf("%w: seat %s", ErrSeatsNotHeld, seatNumber)
+		}
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return "", fmt.Errorf("failed to lock order seats: %w", err)
+	}
+
+	// Travelers booked without a seat count for their segment; they get
+	// one at check-in
+	var emptySegments int
+	err = tx.QueryRow(ctx, `
+		SELECT COUNT(*) FROM order_segments seg
+		WHERE seg.order_id = $1 AND NOT EXISTS (
+			SELECT 1 FROM order_seats os
+			JOIN seats s ON s.id = os.seat_id
+			WHERE os.order_id = seg.order_id AND s.flight_id = seg.flight_id
+		) AND NOT EXISTS (
+			SELECT 1 FROM overbooked_seats ob
+			WHERE ob.order_id = seg.order_id AND ob.flight_id = seg.flight_id
+		)
+	`, orderID).Scan(&emptySegments)
+	if err != nil {
+		return "", fmt.Errorf("failed to check order segments: %w", err)
+	}
+	if emptySegments > 0 {
+		return "", ErrSegmentNoSeats
+	}
+
+	// Share-lock the order's flights so a status change waits for this
+	// booking to commit, and its disruption handling then sees the order
+	// as confirmed
+	if _, err := tx.Exec(ctx, `
+		SELECT f.id
+		FROM order_segments seg
+		JOIN flights f ON f.id = seg.flight_id
+		WHERE seg.order_id = $1
+		ORDER BY f.id
+		FOR SHARE OF f
+	`, orderID); err != nil {
+		return "", fmt.Errorf("failed to lock order flights: %w", err)
+	}
+
+	var flightNumber, flightStatus string
+	err = tx.QueryRow(ctx, `
+		SELECT f.flight_number, f.status
+		FROM order_segments seg
+		JOIN flights f ON f.id = seg.flight_id
+		WHERE seg.order_id = $1 AND f.status NOT IN ('scheduled', 'delayed')
+		ORDER BY seg.segment_index
+		LIMIT 1
+	`, orderID).Scan(&flightNumber, &flightStatus)
+	if err == nil {
+		return "", fmt.Errorf("%w: flight %s is %s", ErrFlightNotBookable, flightNumber, flightStatus)
+	}
+	if !errors.Is(err, pgx.ErrNoRows) {
+		return "", fmt.Errorf("failed to check flight status: %w", err)
+	}
+
+	_, err = tx.Exec(ctx, `
 		UPDATE seats
-		SET status = 'available', held_until = NULL, held_by_order = NULL
-		WHERE held_by_order = $1
+		SET status = 'booked', held_until = NULL
+		WHERE held_by_order = $1 AND status = 'held'
 	`, orderID)
 	if err != nil {
-		return fmt.Errorf("failed to release seats: %w", err)
+		return "", fmt.Errorf("failed to book seats: %w", err)
 	}
-	return nil
+
+	_, err = tx.Exec(ctx, `
+		UPDATE flights f
+		SET available_seats = (
+			SELECT COUNT(*) FROM seats s
+			WHERE s.flight_id = f.id AND s.status = 'available'
+		)
+		WHERE id IN (SELECT flight_id FROM order_segments WHERE order_id = $1)
+	`, orderID)
+	if err != nil {
+		return "", fmt.Errorf("failed to update available seats: %w", err)
+	}
+
+	reference, err := uniqueBookingReference(ctx, tx)
+	if err != nil {
+		return "", err
+	}
+	_, err = tx.Exec(ctx, `
+		UPDATE orders SET status = $1, booking_reference = $3 WHERE id = $2
+	`, OrderStatusConfirmed, orderID, reference)
+	if err != nil {
+		return "", fmt.Errorf("failed to update order status: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return "", fmt.Errorf("failed to commit booking: %w", err)
+	}
+	return reference, nil
+}
+
+// ReleaseSeats releases held seats and returns the waitlists with customers
+// waiting for them
+func (r *Repository) ReleaseSeats(ctx context.Context, orderID uuid.UUID) ([]WaitlistKey, error) {
+	rows, err := r.pool.Query(ctx, `
+		WITH released AS (
+			UPDATE seats
+			SET status = 'available', held_until = NULL, held_by_order = NULL
+			WHERE held_by_order = $1
+			RETURNING flight_id, class
+		)
+		SELECT DISTINCT r.flight_id, r.class
+		FROM released r
+		WHERE EXISTS (
+			SELECT 1 FROM waitlist_entries w
+			WHERE w.flight_id = r.flight_id AND w.class = r.class AND w.status = 'waiting'
+		)
+	`, orderID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to release seats: %w", err)
+	}
+	keys, err := pgx.CollectRows(rows, pgx.RowToStructByPos[WaitlistKey])
+	if err != nil {
+		return nil, fmt.Errorf("failed to release seats: %w", err)
+	}
+	return keys, nil
 }
 
 // GetReservationExpiry returns when the reservation expires
@@ -0,0 +1,87 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/uuid"
+)
+
+// MaterializeScheduledFlights creates the flights and seats of flight
+// schedules that depart within the next horizonDays days, for a single
+// schedule or, when scheduleID is nil, for all of them. Local schedule times
+// are converted with the time zones of the origin and destination airports.
+// Flights that already exist for a flight number and date are left
+// untouched, so running it again only fills in what is missing. It returns
+// the number of flights created.
+func (r *Repository) MaterializeScheduledFlights(ctx context.Context, scheduleID *uuid.UUID, horizonDays int) (int, error) {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	// Days start one day back so local dates that are still ahead of the
+	// database's current date are not missed
+	rows, err := tx.Query(ctx, `
+		INSERT INTO flights (flight_number, origin, destination, departure_time, arrival_time,
+		                     total_seats, available_seats, price_per_seat, cabin_layout, schedule_id)
+		SELECT s.flight_number, s.origin, s.destination,
+		       (d.day + s.departure_time_local) AT TIME ZONE o.time_zone,
+		       (d.day + s.arrival_day_offset + s.arrival_time_local) AT TIME ZONE a.time_zone,
+		       t.seats, t.seats, s.price_per_seat, s.cabin_layout, s.id
+		FROM flight_schedules s
+		JOIN airports o ON o.iata_code = s.origin
+		JOIN airports a ON a.iata_code = s.destination
+		CROSS JOIN LATERAL (
+			SELECT COUNT(*)::int AS seats FROM flight_schedule_seats WHERE schedule_id = s.id
+		) t
+		CROSS JOIN LATERAL (
+			SELECT CURRENT_DATE + n AS day FROM generate_series(-1, $2::int) AS n
+		) d
+		WHERE ($1::uuid IS NULL OR s.id = $1)
+		  AND t.seats > 0
+		  AND d.day >= s.effective_from
+		  AND (s.effective_to IS NULL OR d.day <= s.effective_to)
+		  AND EXTRACT(ISODOW FROM d.day)::int = ANY(s.days_of_week)
+		  AND (d.day + s.departure_time_local) AT TIME ZONE o.time_zone > NOW()
+		ON CONFLICT DO NOTHING
+		RETURNING id, schedule_id
+	`, scheduleID, horizonDays)
+	if err != nil {
+		return 0, fmt.Errorf("failed to create scheduled flights: %w", err)
+	}
+
+	var flightIDs, scheduleIDs []uuid.UUID
+	for rows.Next() {
+		var flightID, schedID uuid.UUID
+		if err := rows.Scan(&flightID, &schedID); err != nil {
+			rows.Close()
+			return 0, fmt.Errorf("failed to scan scheduled flight: %w", err)
+		}
+		flightIDs = append(flightIDs, flightID)
+		scheduleIDs = append(scheduleIDs, schedID)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, fmt.Errorf("failed to create scheduled flights: %w", err)
+	}
+	if len(flightIDs) == 0 {
+		return 0, nil
+	}
+
+	_, err = tx.Exec(ctx, `
+		INSERT INTO seats (flight_id, seat_number, row_number, column_letter, class, attributes, price)
+		SELECT f.id, t.seat_number, t.row_number, t.column_letter, t.class, t.attributes, t.price
+		FROM unnest($1::uuid[], $2::uuid[]) AS f(id, schedule_id)
+		JOIN flight_schedule_seats t ON t.schedule_id = f.schedule_id
+	`, flightIDs, scheduleIDs)
+	if err != nil {
+		return 0, fmt.Errorf("failed to create scheduled seats: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return 0, fmt.Errorf("failed to commit scheduled flights: %w", err)
+	}
+	return len(flightIDs), nil
+}
@@ -0,0 +1,304 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// ErrExchangeNotPending is returned when a seat exchange has already been
+// completed or failed
+var ErrExchangeNotPending = errors.New("seat exchange is no longer pending")
+
+// seatExchange is a pending seat change locked for completion
+type seatExchange struct {
+	orderID  uuid.UUID
+	oldSeats []uuid.UUID
+	newSeats []uuid.UUID
+}
+
+// lockSeatExchange locks a pending seat exchange
+func lockSeatExchange(ctx context.Context, tx pgx.Tx, id uuid.UUID) (*seatExchange, error) {
+	var ex seatExchange
+	var status string
+	err := tx.QueryRow(ctx, `
+		SELECT order_id, old_seat_ids, new_seat_ids, status
+		FROM seat_exchanges
+		WHERE id = $1
+		FOR UPDATE
+	`, id).Scan(&ex.orderID, &ex.oldSeats, &ex.newSeats, &status)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to lock seat exchange: %w", err)
+	}
+	if status != "pending" {
+		return nil, fmt.Errorf("%w: exchange is %s", ErrExchangeNotPending, status)
+	}
+	return &ex, nil
+}
+
+// CompleteSeatExchange swaps the seats of a pending seat exchange in one
+// transaction: the new seats, held for the order, are booked and the old ones
+// released. The order's seats are repriced at the new seats' fares and
+// transactionID, if any, is recorded for the charged difference. If a new
+// seat is no longer held for the order nothing changes and ErrSeatsNotHeld is
+// returned. seats_released and seats_booked events are published for each
+// flight when the transaction commits. It returns the waitlists with
+// customers waiting for the released seats.
+func (r *Repository) CompleteSeatExchange(ctx context.Context, id uuid.UUID, transactionID *string) ([]WaitlistKey, error) {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	ex, err := lockSeatExchange(ctx, tx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	// Lock the seats in ID order, like the API server does when holding them
+	rows, err := tx.Query(ctx, `
+		SELECT id, flight_id, seat_number, status, held_by_order
+		FROM seats
+		WHERE id = ANY($1) OR id = ANY($2)
+		ORDER BY id
+		FOR UPDATE
+	`, ex.oldSeats, ex.newSeats)
+	if err != nil {
+		return nil, fmt.Errorf("failed to lock seats: %w", err)
+	}
+	type lockedSeat struct {
+		flightID    uuid.UUID
+		seatNumber  string
+		status      string
+		heldByOrder *uuid.UUID
+	}
+	locked := make(map[uuid.UUID]lockedSeat)
+	for rows.Next() {
+		var seatID uuid.UUID
+		var s lockedSeat
+		if err := rows.Scan(&seatID, &s.flightID, &s.seatNumber, &s.status, &s.heldByOrder); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("failed to scan seat: %w", err)
+		}
+		locked[seatID] = s
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to lock seats: %w", err)
+	}
+
+	ownedBy := func(seatID uuid.UUID, status string) bool {
+		s, ok := locked[seatID]
+		return ok && s.status == status && s.heldByOrder != nil && *s.heldByOrder == ex.orderID
+	}
+	for i, seatID := range ex.newSeats {
+		if !ownedBy(seatID, "held") {
+			return nil, fmt.Errorf("%w: seat %s", ErrSeatsNotHeld, locked[seatID].seatNumber)
+		}
+		if !ownedBy(ex.oldSeats[i], "booked") {
+			return nil, fmt.Errorf("%w: seat %s is no longer booked", ErrSeatsNotHeld, locked[ex.oldSeats[i]].seatNumber)
+		}
+	}
+
+	_, err = tx.Exec(ctx, `UPDATE seats SET status = 'booked', held_until = NULL WHERE id = ANY($1)`, ex.newSeats)
+	if err != nil {
+		return nil, fmt.Errorf("failed to book seats: %w", err)
+	}
+
+	_, err = tx.Exec(ctx, `
+		UPDATE seats SET status = 'available', held_by_order = NULL, held_until = NULL WHERE id = ANY($1)
+	`, ex.oldSeats)
+	if err != nil {
+		return nil, fmt.Errorf("failed to release seats: %w", err)
+	}
+
+	// Seats are charged their class fare plus the surcharges of their
+	// attributes, and keep the passenger of the seat they replace
+	_, err = tx.Exec(ctx, `
+		INSERT INTO order_seats (order_id, seat_id, price, passenger_id)
+		SELECT $1, s.id, s.price + COALESCE((
+		    SELECT SUM(a.surcharge) FROM seat_attribute_surcharges a WHERE a.attribute = ANY(s.attributes)
+		), 0), os.passenger_id
+		FROM unnest($2::uuid[], $3::uuid[]) AS x(old_seat_id, new_seat_id)
+		JOIN seats s ON s.id = x.new_seat_id
+		LEFT JOIN order_seats os ON os.order_id = $1 AND os.seat_id = x.old_seat_id
+	`, ex.orderID, ex.oldSeats, ex.newSeats)
+	if err != nil {
+		return nil, fmt.Errorf("failed to add order seats: %w", err)
+	}
+
+	_, err = tx.Exec(ctx, `DELETE FROM order_seats WHERE order_id = $1 AND seat_id = ANY($2)`, ex.orderID, ex.oldSeats)
+	if err != nil {
+		return nil, fmt.Errorf("failed to remove order seats: %w", err)
+	}
+
+	_, err = tx.Exec(ctx, `
+		UPDATE orders SET total_amount = (SELECT SUM(price) FROM order_seats WHERE order_id = $1) WHERE id = $1
+	`, ex.orderID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update order total: %w", err)
+	}
+
+	_, err = tx.Exec(ctx, `
+		UPDATE seat_exchanges SET status = 'completed', transaction_id = $2, completed_at = NOW() WHERE id = $1
+	`, id, transactionID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to complete seat exchange: %w", err)
+	}
+
+	_, err = tx.Exec(ctx, `
+		UPDATE flights f
+		SET available_seats = (SELECT COUNT(*) FROM seats s WHERE s.flight_id = f.id AND s.status = 'available')
+		WHERE f.id IN (SELECT flight_id FROM seats WHERE id = ANY($1))
+	`, ex.oldSeats)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update seat counts: %w", err)
+	}
+
+	released := make([]string, len(ex.oldSeats))
+	for i, seatID := range ex.oldSeats {
+		released[i] = seatID.String()
+	}
+	waitlists, err := waitlistsForSeats(ctx, tx, released)
+	if err != nil {
+		return nil, err
+	}
+
+	// Notifications are only delivered if the transaction commits
+	flightOf := func(seatID uuid.UUID) uuid.UUID { return locked[seatID].flightID }
+	if err := publishSeatEvents(ctx, tx, SeatEventSeatsReleased, ex.orderID, ex.oldSeats, flightOf); err != nil {
+		return nil, err
+	}
+	if err := publishSeatEvents(ctx, tx, SeatEventSeatsBooked, ex.orderID, ex.newSeats, flightOf); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit seat exchange: %w", err)
+	}
+	return waitlists, nil
+}
+
+// FailSeatExchange marks a pending seat exchange failed with reason and
+// releases its new seats that are still held for the order; the order keeps
+// its old seats. A seats_released event is published for each flight when
+// the transaction commits. It returns the waitlists with customers waiting
+// for the released seats.
+func (r *Repository) FailSeatExchange(ctx context.Context, id uuid.UUID, reason string) ([]WaitlistKey, error) {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	ex, err := lockSeatExchange(ctx, tx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := tx.Query(ctx, `
+		UPDATE seats
+		SET status = 'available', held_by_order = NULL, held_until = NULL
+		WHERE id = ANY($1) AND held_by_order = $2 AND status = 'held'
+		RETURNING id, flight_id
+	`, ex.newSeats, ex.orderID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to release exchange seats: %w", err)
+	}
+	var releasedIDs []uuid.UUID
+	flights := make(map[uuid.UUID]uuid.UUID)
+	for rows.Next() {
+		var seatID, flightID uuid.UUID
+		if err := rows.Scan(&seatID, &flightID); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("failed to scan released seat: %w", err)
+		}
+		releasedIDs = append(releasedIDs, seatID)
+		flights[seatID] = flightID
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to release exchange seats: %w", err)
+	}
+
+	_, err = tx.Exec(ctx, `
+		UPDATE seat_exchanges SET status = 'failed', failure_reason = $2, completed_at = NOW() WHERE id = $1
+	`, id, reason)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fail seat exchange: %w", err)
+	}
+
+	_, err = tx.Exec(ctx, `
+		UPDATE flights f
+		SET available_seats = (SELECT COUNT(*) FROM seats s WHERE s.flight_id = f.id AND s.status = 'available')
+		WHERE f.id IN (SELECT flight_id FROM seats WHERE id = ANY($1))
+	`, ex.newSeats)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update seat counts: %w", err)
+	}
+
+	released := make([]string, len(releasedIDs))
+	for i, seatID := range releasedIDs {
+		released[i] = seatID.String()
+	}
+	waitlists, err := waitlistsForSeats(ctx, tx, released)
+	if err != nil {
+		return nil, err
+	}
+
+	flightOf := func(seatID uuid.UUID) uuid.UUID { return flights[seatID] }
+	if err := publishSeatEvents(ctx, tx, SeatEventSeatsReleased, ex.orderID, releasedIDs, flightOf); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit seat exchange: %w", err)
+	}
+	return waitlists, nil
+}
+
+// RecordSeatExchangeCredit records the credit issued for a seat exchange
+func (r *Repository) RecordSeatExchangeCredit(ctx context.Context, id uuid.UUID, creditID string) error {
+	tag, err := r.pool.Exec(ctx, `UPDATE seat_exchanges SET credit_id = $2 WHERE id = $1`, id, creditID)
+	if err != nil {
+		return fmt.Errorf("failed to record seat exchange credit: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// publishSeatEvents publishes an event of type eventType for the seats of an
+// order, one per flight. The notifications are delivered when tx commits.
+func publishSeatEvents(ctx context.Context, tx pgx.Tx, eventType string, orderID uuid.UUID, seatIDs []uuid.UUID, flightOf func(uuid.UUID) uuid.UUID) error {
+	var flights []uuid.UUID
+	byFlight := make(map[uuid.UUID][]string)
+	for _, seatID := range seatIDs {
+		flightID := flightOf(seatID)
+		if _, ok := byFlight[flightID]; !ok {
+			flights = append(flights, flightID)
+		}
+		byFlight[flightID] = append(byFlight[flightID], seatID.String())
+	}
+
+	for _, flightID := range flights {
+		event := SeatEvent{
+			Type:     eventType,
+			FlightID: flightID.String(),
+			OrderID:  orderID.String(),
+			SeatIDs:  byFlight[flightID],
+		}
+		if err := notifySeatEvent(ctx, tx, event); err != nil {
+			return err
+		}
+	}
+	return nil
+}
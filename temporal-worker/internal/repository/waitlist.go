@@ -0,0 +1,240 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// WaitlistKey identifies the waitlist of a class on a flight
+type WaitlistKey struct {
+	FlightID uuid.UUID
+	Class    string
+}
+
+// WaitlistOffer is an order created for the first customer on a waitlist,
+// holding seats for them until ExpiresAt. Waiting is the number of customers
+// still waiting afterwards. An empty EntryID means nothing was offered.
+type WaitlistOffer struct {
+	EntryID       uuid.UUID
+	OrderID       uuid.UUID
+	WorkflowID    string
+	FlightNumber  string
+	CustomerName  string
+	CustomerEmail string
+	SeatIDs       []uuid.UUID
+	ExpiresAt     time.Time
+	Waiting       int
+}
+
+// OfferWaitlistSeats offers available seats of a class to the first customer
+// waiting for it. The customer gets an order, named like the orders the API
+// creates, holding enough seats for their party until holdUntil. Customers
+// are served strictly in the order they joined: if the first one's party
+// does not fit nothing is offered. Once the flight is no longer sold, every
+// waiting customer's entry expires and ErrFlightNotBookable is returned.
+func (r *Repository) OfferWaitlistSeats(ctx context.Context, flightID uuid.UUID, class string, holdUntil time.Time) (*WaitlistOffer, error) {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	offer := &WaitlistOffer{}
+	var status string
+	var departure time.Time
+	err = tx.QueryRow(ctx, `
+		SELECT flight_number, status, departure_time FROM flights WHERE id = $1
+	`, flightID).Scan(&offer.FlightNumber, &status, &departure)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("failed to get flight: %w", err)
+	}
+	if (status != "scheduled" && status != "delayed") || !departure.After(time.Now()) {
+		_, err := tx.Exec(ctx, `
+			UPDATE waitlist_entries SET status = 'expired'
+			WHERE flight_id = $1 AND class = $2 AND status = 'waiting'
+		`, flightID, class)
+		if err != nil {
+			return nil, fmt.Errorf("failed to expire waitlist: %w", err)
+		}
+		if err := tx.Commit(ctx); err != nil {
+			return nil, fmt.Errorf("failed to expire waitlist: %w", err)
+		}
+		return nil, fmt.Errorf("%w: flight is %s", ErrFlightNotBookable, status)
+	}
+
+	var partySize int
+	err = tx.QueryRow(ctx, `
+		SELECT id, customer_name, customer_email, party_size
+		FROM waitlist_entries
+		WHERE flight_id = $1 AND class = $2 AND status = 'waiting'
+		ORDER BY created_at, id
+		LIMIT 1
+		FOR UPDATE
+	`, flightID, class).Scan(&offer.EntryID, &offer.CustomerName, &offer.CustomerEmail, &partySize)
+	if err == pgx.ErrNoRows {
+		return &WaitlistOffer{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get waitlist entry: %w", err)
+	}
+
+	rows, err := tx.Query(ctx, `
+		SELECT id FROM seats
+		WHERE flight_id = $1 AND class = $2 AND status = 'available'
+		ORDER BY row_number, column_letter
+		LIMIT $3
+		FOR UPDATE SKIP LOCKED
+	`, flightID, class, partySize)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find seats: %w", err)
+	}
+	offer.SeatIDs, err = pgx.CollectRows(rows, pgx.RowTo[uuid.UUID])
+	if err != nil {
+		return nil, fmt.Errorf("failed to find seats: %w", err)
+	}
+
+	if len(offer.SeatIDs) < partySize {
+		waiting, err := countWaiting(ctx, tx, flightID, class)
+		if err != nil {
+			return nil, err
+		}
+		return &WaitlistOffer{Waiting: waiting}, nil
+	}
+
+	offer.OrderID = uuid.New()
+	offer.WorkflowID = fmt.Sprintf("booking-%s", offer.OrderID)
+	offer.ExpiresAt = holdUntil
+
+	_, err = tx.Exec(ctx, `
+		INSERT INTO orders (id, flight_id, customer_name, customer_email, status, workflow_id, reservation_expires_at)
+		VALUES ($1, $2, $3, $4, 'seats_selected', $5, $6)
+	`, offer.OrderID, flightID, offer.CustomerName, offer.CustomerEmail, offer.WorkflowID, holdUntil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create order: %w", err)
+	}
+
+	_, err = tx.Exec(ctx, `
+		INSERT INTO order_segments (order_id, flight_id, segment_index) VALUES ($1, $2, 0)
+	`, offer.OrderID, flightID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create order segment: %w", err)
+	}
+
+	_, err = tx.Exec(ctx, `
+		UPDATE seats SET status = 'held', held_by_order = $1, held_until = $2 WHERE id = ANY($3)
+	`, offer.OrderID, holdUntil, offer.SeatIDs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to hold seats: %w", err)
+	}
+
+	// Seats are charged their class fare plus the surcharges of their attributes
+	_, err = tx.Exec(ctx, `
+		INSERT INTO order_seats (order_id, seat_id, price)
+		SELECT $1, s.id, s.price + COALESCE((
+		    SELECT SUM(a.surcharge) FROM seat_attribute_surcharges a WHERE a.attribute = ANY(s.attributes)
+		), 0)
+		FROM seats s WHERE s.id = ANY($2)
+	`, offer.OrderID, offer.SeatIDs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to add order seats: %w", err)
+	}
+
+	_, err = tx.Exec(ctx, `
+		UPDATE orders SET total_amount = (SELECT SUM(price) FROM order_seats WHERE order_id = $1) WHERE id = $1
+	`, offer.OrderID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update order total: %w", err)
+	}
+
+	_, err = tx.Exec(ctx, `
+		UPDATE waitlist_entries
+		SET status = 'offered', order_id = $2, offered_at = NOW(), offer_expires_at = $3
+		WHERE id = $1
+	`, offer.EntryID, offer.OrderID, holdUntil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update waitlist entry: %w", err)
+	}
+
+	offer.Waiting, err = countWaiting(ctx, tx, flightID, class)
+	if err != nil {
+		return nil, err
+	}
+
+	seatIDs := make([]string, len(offer.SeatIDs))
+	for i, id := range offer.SeatIDs {
+		seatIDs[i] = id.String()
+	}
+	event := SeatEvent{
+		Type:     SeatEventSeatsHeld,
+		FlightID: flightID.String(),
+		OrderID:  offer.OrderID.String(),
+		SeatIDs:  seatIDs,
+	}
+	if err := notifySeatEvent(ctx, tx, event); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit waitlist offer: %w", err)
+	}
+	return offer, nil
+}
+
+// countWaiting returns the number of customers waiting on a waitlist
+func countWaiting(ctx context.Context, tx pgx.Tx, flightID uuid.UUID, class string) (int, error) {
+	var n int
+	err := tx.QueryRow(ctx, `
+		SELECT COUNT(*) FROM waitlist_entries
+		WHERE flight_id = $1 AND class = $2 AND status = 'waiting'
+	`, flightID, class).Scan(&n)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count waitlist: %w", err)
+	}
+	return n, nil
+}
+
+// waitlistsForSeats returns the waitlists with waiting customers for the
+// flights and classes of the given seats
+func waitlistsForSeats(ctx context.Context, tx pgx.Tx, seatIDs []string) ([]WaitlistKey, error) {
+	rows, err := tx.Query(ctx, `
+		SELECT DISTINCT s.flight_id, s.class
+		FROM seats s
+		WHERE s.id = ANY($1::uuid[])
+		  AND EXISTS (
+			SELECT 1 FROM waitlist_entries w
+			WHERE w.flight_id = s.flight_id AND w.class = s.class AND w.status = 'waiting'
+		  )
+	`, seatIDs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query waitlists: %w", err)
+	}
+	keys, err := pgx.CollectRows(rows, pgx.RowToStructByPos[WaitlistKey])
+	if err != nil {
+		return nil, fmt.Errorf("failed to query waitlists: %w", err)
+	}
+	return keys, nil
+}
+
+// CompleteWaitlistOffer records whether an offered order was booked. An
+// offer whose order expired, failed or was cancelled is expired.
+func (r *Repository) CompleteWaitlistOffer(ctx context.Context, entryID uuid.UUID, accepted bool) error {
+	status := "expired"
+	if accepted {
+		status = "accepted"
+	}
+	_, err := r.pool.Exec(ctx, `
+		UPDATE waitlist_entries SET status = $2
+		WHERE id = $1 AND status = 'offered'
+	`, entryID, status)
+	if err != nil {
+		return fmt.Errorf("failed to complete waitlist offer: %w", err)
+	}
+	return nil
+}
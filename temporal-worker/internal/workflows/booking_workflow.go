@@ -17,19 +17,25 @@ const (
 	MaxPaymentAttempts = 3
 )
 
-// BookingWorkflowInput is the input for the booking workflow
+// BookingWorkflowInput is the input for the booking workflow. SeatIDs and
+// ReservationExpiresAt are set for orders created with their seats already
+// held, such as waitlist offers.
 type BookingWorkflowInput struct {
-	OrderID       string `json:"orderId"`
-	FlightID      string `json:"flightId"`
-	CustomerName  string `json:"customerName"`
-	CustomerEmail string `json:"customerEmail"`
+	OrderID              string    `json:"orderId"`
+	FlightID             string    `json:"flightId"`
+	FlightIDs            []string  `json:"flightIds,omitempty"`
+	CustomerName         string    `json:"customerName"`
+	CustomerEmail        string    `json:"customerEmail"`
+	SeatIDs              []string  `json:"seatIds,omitempty"`
+	ReservationExpiresAt time.Time `json:"reservationExpiresAt,omitempty"`
 }
 
 // BookingWorkflowResult is the result of the booking workflow
 type BookingWorkflowResult struct {
-	Success       bool   `json:"success"`
-	TransactionID string `json:"transactionId,omitempty"`
-	FailureReason string `json:"failureReason,omitempty"`
+	Success          bool   `json:"success"`
+	TransactionID    string `json:"transactionId,omitempty"`
+	BookingReference string `json:"bookingReference,omitempty"`
+	FailureReason    string `json:"failureReason,omitempty"`
 }
 
 // SeatsSelectedSignal is the signal for seat selection
@@ -43,10 +49,11 @@ type PaymentSubmittedSignal struct {
 	PaymentCode string `json:"paymentCode"`
 }
 
-// BookingWorkflow orchestrates the flight booking process
+// BookingWorkflow orchestrates the flight booking process. An order may span
+// several flight segments; they are confirmed together or not at all.
 func BookingWorkflow(ctx workflow.Context, input BookingWorkflowInput) (*BookingWorkflowResult, error) {
 	logger := workflow.GetLogger(ctx)
-	logger.Info("Booking workflow started", "orderId", input.OrderID)
+	logger.Info("Booking workflow started", "orderId", input.OrderID, "segments", max(len(input.FlightIDs), 1))
 
 	// Activity options
 	activityOpts := workflow.ActivityOptions{
@@ -76,19 +83,46 @@ func BookingWorkflow(ctx workflow.Context, input BookingWorkflowInput) (*Booking
 	var paymentAttempts int
 	var reservationExpiry time.Time
 
-	// Update order status to pending
-	err := workflow.ExecuteActivity(ctx, "UpdateOrderStatus", activities.UpdateOrderStatusInput{
-		OrderID: input.OrderID,
-		Status:  "pending",
-	}).Get(ctx, nil)
-	if err != nil {
-		logger.Error("Failed to update order status", "error", err)
+	// status is set once the order reaches a terminal state
+	var status, failureReason, transactionID, bookingReference string
+
+	releaseSeats := func(ctx workflow.Context, reason string) {
+		var output activities.ReleaseSeatsOutput
+		err := workflow.ExecuteActivity(ctx, "ReleaseSeats", activities.ReleaseSeatsInput{
+			OrderID: input.OrderID,
+			Reason:  reason,
+		}).Get(ctx, &output)
+		if err != nil {
+			logger.Error("Failed to release seats", "reason", reason, "error", err)
+			return
+		}
+		notifyWaitlists(ctx, output.Waitlists)
+	}
+
+	if len(input.SeatIDs) > 0 {
+		// The order was created with its seats held
+		seatsSelected = true
+		reservationExpiry = input.ReservationExpiresAt
+	} else {
+		// Update order status to pending
+		err := workflow.ExecuteActivity(ctx, "UpdateOrderStatus", activities.UpdateOrderStatusInput{
+			OrderID: input.OrderID,
+			Status:  "pending",
+		}).Get(ctx, nil)
+		if err != nil {
+			logger.Error("Failed to update order status", "error", err)
+		}
 	}
 
 	// Main workflow loop
-	for {
+	for status == "" {
 		selector := workflow.NewSelector(ctx)
 
+		// Handle workflow cancellation
+		selector.AddReceive(ctx.Done(), func(c workflow.ReceiveChannel, more bool) {
+			status = "cancelled"
+		})
+
 		// Handle seat selection signal
 		selector.AddReceive(seatsSelectedCh, func(c workflow.ReceiveChannel, more bool) {
 			var signal SeatsSelectedSignal
@@ -119,10 +153,8 @@ func BookingWorkflow(ctx workflow.Context, input BookingWorkflowInput) (*Booking
 			// Check if reservation expired
 			if workflow.Now(ctx).After(reservationExpiry) {
 				logger.Info("Reservation expired before payment")
-				workflow.ExecuteActivity(ctx, "ReleaseSeats", activities.ReleaseSeatsInput{
-					OrderID: input.OrderID,
-					Reason:  "expired",
-				})
+				releaseSeats(ctx, "expired")
+				status = "expired"
 				return
 			}
 
@@ -150,22 +182,41 @@ func BookingWorkflow(ctx workflow.Context, input BookingWorkflowInput) (*Booking
 			if result.Success {
 				logger.Info("Payment successful!", "transactionId", result.TransactionID)
 
+				// Book every segment; if any of them lost its seats the whole order fails
+				var booking activities.ConfirmBookingOutput
+				err := workflow.ExecuteActivity(ctx, "ConfirmBooking", activities.ConfirmBookingInput{
+					OrderID: input.OrderID,
+				}).Get(ctx, &booking)
+				if err != nil || !booking.Success {
+					logger.Error("Booking confirmation failed", "error", err, "reason", booking.FailureReason)
+					releaseSeats(ctx, "booking_failed")
+					status = "failed"
+					failureReason = booking.FailureReason
+					return
+				}
+
+				status = "confirmed"
+				transactionID = result.TransactionID
+				bookingReference = booking.BookingReference
+
 				// Send confirmation
-				workflow.ExecuteActivity(ctx, "SendConfirmation", activities.SendConfirmationInput{
-					OrderID:       input.OrderID,
-					CustomerEmail: input.CustomerEmail,
-					CustomerName:  input.CustomerName,
-					TransactionID: result.TransactionID,
-				})
+				err = workflow.ExecuteActivity(ctx, "SendConfirmation", activities.SendConfirmationInput{
+					OrderID:          input.OrderID,
+					BookingReference: booking.BookingReference,
+					CustomerEmail:    input.CustomerEmail,
+					CustomerName:     input.CustomerName,
+					TransactionID:    result.TransactionID,
+				}).Get(ctx, nil)
+				if err != nil {
+					logger.Error("Failed to send confirmation", "error", err)
+				}
 			} else {
 				logger.Info("Payment failed", "attempt", paymentAttempts, "maxAttempts", MaxPaymentAttempts)
 
 				if paymentAttempts >= MaxPaymentAttempts {
 					// Max attempts reached - fail order
-					workflow.ExecuteActivity(ctx, "ReleaseSeats", activities.ReleaseSeatsInput{
-						OrderID: input.OrderID,
-						Reason:  "payment_failed",
-					})
+					releaseSeats(ctx, "payment_failed")
+					status = "failed"
 				} else {
 					// Allow retry
 					workflow.ExecuteActivity(ctx, "UpdateOrderStatus", activities.UpdateOrderStatusInput{
@@ -176,11 +227,16 @@ func BookingWorkflow(ctx workflow.Context, input BookingWorkflowInput) (*Booking
 			}
 		})
 
-		// Timeout for seat hold expiry
+		// Timeout for seat hold expiry; the timer is cancelled if another
+		// event wins the select so it cannot fire in a later iteration
+		timerCtx, cancelTimer := workflow.WithCancel(ctx)
 		if seatsSelected && !reservationExpiry.IsZero() {
 			timeUntilExpiry := reservationExpiry.Sub(workflow.Now(ctx))
 			if timeUntilExpiry > 0 {
-				selector.AddFuture(workflow.NewTimer(ctx, timeUntilExpiry), func(f workflow.Future) {
+				selector.AddFuture(workflow.NewTimer(timerCtx, timeUntilExpiry), func(f workflow.Future) {
+					if f.Get(ctx, nil) != nil {
+						return
+					}
 					logger.Info("Reservation timer expired")
 
 					// Check if order is still in progress
@@ -190,48 +246,36 @@ func BookingWorkflow(ctx workflow.Context, input BookingWorkflowInput) (*Booking
 					}).Get(ctx, &expired)
 
 					if expired {
-						workflow.ExecuteActivity(ctx, "ReleaseSeats", activities.ReleaseSeatsInput{
-							OrderID: input.OrderID,
-							Reason:  "expired",
-						})
+						releaseSeats(ctx, "expired")
+						status = "expired"
 					}
 				})
 			}
 		}
 
 		selector.Select(ctx)
+		cancelTimer()
+	}
 
-		// Check for completion conditions
-		status, _ := getOrderStatus(ctx, input.OrderID)
-		if status == "confirmed" {
-			return &BookingWorkflowResult{
-				Success: true,
-			}, nil
-		}
-		if status == "failed" || status == "cancelled" || status == "expired" {
-			return &BookingWorkflowResult{
-				Success:       false,
-				FailureReason: string(status),
-			}, nil
-		}
-
-		// Check for context cancellation
-		if ctx.Err() != nil {
-			// Release seats on cancellation
-			workflow.ExecuteActivity(ctx, "ReleaseSeats", activities.ReleaseSeatsInput{
-				OrderID: input.OrderID,
-				Reason:  "cancelled",
-			})
-			return &BookingWorkflowResult{
-				Success:       false,
-				FailureReason: "cancelled",
-			}, nil
-		}
+	if status == "cancelled" {
+		// Release seats on cancellation; the workflow context is already
+		// cancelled so the activity runs on a disconnected one
+		disconnectedCtx, _ := workflow.NewDisconnectedContext(ctx)
+		releaseSeats(disconnectedCtx, "cancelled")
 	}
-}
 
-func getOrderStatus(ctx workflow.Context, orderID string) (string, error) {
-	// This is a simplified check - in production you'd query the database
-	// For now, we rely on the workflow state
-	return "in_progress", nil
+	if status == "confirmed" {
+		return &BookingWorkflowResult{
+			Success:          true,
+			TransactionID:    transactionID,
+			BookingReference: bookingReference,
+		}, nil
+	}
+	if failureReason == "" {
+		failureReason = status
+	}
+	return &BookingWorkflowResult{
+		Success:       false,
+		FailureReason: failureReason,
+	}, nil
 }
@@ -7,6 +7,7 @@ import (
 	"github.com/cx-tal-miterani/flight-booking-system/temporal-worker/internal/activities"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/suite"
+	"go.temporal.io/sdk/activity"
 	"go.temporal.io/sdk/testsuite"
 )
 
@@ -18,6 +19,16 @@ type BookingWorkflowTestSuite struct {
 
 func (s *BookingWorkflowTestSuite) SetupTest() {
 	s.env = s.NewTestWorkflowEnvironment()
+
+	// Activities are invoked by name, so register them the same way the worker does
+	acts := &activities.Activities{}
+	s.env.RegisterActivityWithOptions(acts.ValidatePayment, activity.RegisterOptions{Name: "ValidatePayment"})
+	s.env.RegisterActivityWithOptions(acts.ReserveSeats, activity.RegisterOptions{Name: "ReserveSeats"})
+	s.env.RegisterActivityWithOptions(acts.ReleaseSeats, activity.RegisterOptions{Name: "ReleaseSeats"})
+	s.env.RegisterActivityWithOptions(acts.ConfirmBooking, activity.RegisterOptions{Name: "ConfirmBooking"})
+	s.env.RegisterActivityWithOptions(acts.SendConfirmation, activity.RegisterOptions{Name: "SendConfirmation"})
+	s.env.RegisterActivityWithOptions(acts.CheckReservationExpiry, activity.RegisterOptions{Name: "CheckReservationExpiry"})
+	s.env.RegisterActivityWithOptions(acts.UpdateOrderStatus, activity.RegisterOptions{Name: "UpdateOrderStatus"})
 }
 
 func (s *BookingWorkflowTestSuite) AfterTest(suiteName, testName string) {
@@ -60,7 +71,7 @@ func (s *BookingWorkflowTestSuite) TestWorkflow_SeatsSelectedSignal() {
 		s.env.CancelWorkflow()
 	}, time.Millisecond*500)
 
-	s.env.OnActivity("ReleaseSeats", mock.Anything, mock.Anything).Return(nil)
+	s.env.OnActivity("ReleaseSeats", mock.Anything, mock.Anything).Return(&activities.ReleaseSeatsOutput{}, nil)
 
 	s.env.ExecuteWorkflow(BookingWorkflow, input)
 
@@ -81,6 +92,10 @@ func (s *BookingWorkflowTestSuite) TestWorkflow_PaymentSuccess() {
 		Success:       true,
 		TransactionID: "TXN-12345",
 	}, nil)
+	s.env.OnActivity("ConfirmBooking", mock.Anything, mock.Anything).Return(&activities.ConfirmBookingOutput{
+		Success:          true,
+		BookingReference: "K7QM2X",
+	}, nil)
 	s.env.OnActivity("SendConfirmation", mock.Anything, mock.Anything).Return(nil)
 
 	// Send signals
@@ -97,16 +112,62 @@ func (s *BookingWorkflowTestSuite) TestWorkflow_PaymentSuccess() {
 		})
 	}, time.Millisecond*200)
 
-	// Need to cancel since workflow loop doesn't have proper termination in test
+	s.env.ExecuteWorkflow(BookingWorkflow, input)
+
+	s.True(s.env.IsWorkflowCompleted())
+
+	var result *BookingWorkflowResult
+	s.NoError(s.env.GetWorkflowResult(&result))
+	s.True(result.Success)
+	s.Equal("TXN-12345", result.TransactionID)
+	s.Equal("K7QM2X", result.BookingReference)
+}
+
+func (s *BookingWorkflowTestSuite) TestWorkflow_MultiSegment_BookingFailed() {
+	input := BookingWorkflowInput{
+		OrderID:       "test-order-123",
+		FlightID:      "test-flight-456",
+		FlightIDs:     []string{"test-flight-456", "test-flight-789"},
+		CustomerName:  "John Doe",
+		CustomerEmail: "john@example.com",
+	}
+
+	s.env.OnActivity("UpdateOrderStatus", mock.Anything, mock.Anything).Return(nil)
+	s.env.OnActivity("ValidatePayment", mock.Anything, mock.Anything).Return(&activities.ValidatePaymentOutput{
+		Success:       true,
+		TransactionID: "TXN-12345",
+	}, nil)
+	// The return segment lost its hold, so nothing may be booked
+	s.env.OnActivity("ConfirmBooking", mock.Anything, mock.Anything).Return(&activities.ConfirmBookingOutput{
+		Success:       false,
+		FailureReason: "seats no longer held: 12A",
+	}, nil)
+	s.env.OnActivity("ReleaseSeats", mock.Anything, activities.ReleaseSeatsInput{
+		OrderID: "test-order-123",
+		Reason:  "booking_failed",
+	}).Return(&activities.ReleaseSeatsOutput{}, nil).Once()
+
 	s.env.RegisterDelayedCallback(func() {
-		s.env.CancelWorkflow()
-	}, time.Millisecond*500)
+		s.env.SignalWorkflow("seats-selected", SeatsSelectedSignal{
+			SeatIDs:   []string{"seat-1", "seat-2"},
+			ExpiresAt: time.Now().Add(15 * time.Minute),
+		})
+	}, time.Millisecond*100)
 
-	s.env.OnActivity("ReleaseSeats", mock.Anything, mock.Anything).Return(nil)
+	s.env.RegisterDelayedCallback(func() {
+		s.env.SignalWorkflow("payment-submitted", PaymentSubmittedSignal{
+			PaymentCode: "12345",
+		})
+	}, time.Millisecond*200)
 
 	s.env.ExecuteWorkflow(BookingWorkflow, input)
 
 	s.True(s.env.IsWorkflowCompleted())
+
+	var result *BookingWorkflowResult
+	s.NoError(s.env.GetWorkflowResult(&result))
+	s.False(result.Success)
+	s.Equal("seats no longer held: 12A", result.FailureReason)
 }
 
 func (s *BookingWorkflowTestSuite) TestWorkflow_PaymentFailure_Retry() {
@@ -143,7 +204,7 @@ func (s *BookingWorkflowTestSuite) TestWorkflow_PaymentFailure_Retry() {
 		s.env.CancelWorkflow()
 	}, time.Millisecond*500)
 
-	s.env.OnActivity("ReleaseSeats", mock.Anything, mock.Anything).Return(nil)
+	s.env.OnActivity("ReleaseSeats", mock.Anything, mock.Anything).Return(&activities.ReleaseSeatsOutput{}, nil)
 
 	s.env.ExecuteWorkflow(BookingWorkflow, input)
 
@@ -164,7 +225,7 @@ func (s *BookingWorkflowTestSuite) TestWorkflow_MaxPaymentAttemptsExceeded() {
 		Success:      false,
 		ErrorMessage: "Payment failed",
 	}, nil)
-	s.env.OnActivity("ReleaseSeats", mock.Anything, mock.Anything).Return(nil)
+	s.env.OnActivity("ReleaseSeats", mock.Anything, mock.Anything).Return(&activities.ReleaseSeatsOutput{}, nil)
 
 	// Send signals - first seats, then 3 payment attempts
 	s.env.RegisterDelayedCallback(func() {
@@ -203,7 +264,7 @@ func (s *BookingWorkflowTestSuite) TestWorkflow_Cancellation() {
 	}
 
 	s.env.OnActivity("UpdateOrderStatus", mock.Anything, mock.Anything).Return(nil)
-	s.env.OnActivity("ReleaseSeats", mock.Anything, mock.Anything).Return(nil)
+	s.env.OnActivity("ReleaseSeats", mock.Anything, mock.Anything).Return(&activities.ReleaseSeatsOutput{}, nil)
 
 	// Cancel workflow immediately
 	s.env.RegisterDelayedCallback(func() {
@@ -220,3 +281,32 @@ func (s *BookingWorkflowTestSuite) TestWorkflow_Cancellation() {
 	s.False(result.Success)
 	s.Equal("cancelled", result.FailureReason)
 }
+
+func (s *BookingWorkflowTestSuite) TestWorkflow_HeldSeatsExpireAndWakeWaitlist() {
+	input := BookingWorkflowInput{
+		OrderID:              "test-order-123",
+		FlightID:             "test-flight-456",
+		CustomerName:         "John Doe",
+		CustomerEmail:        "john@example.com",
+		SeatIDs:              []string{"seat-1"},
+		ReservationExpiresAt: time.Now().Add(WaitlistOfferHold),
+	}
+
+	s.env.OnActivity("CheckReservationExpiry", mock.Anything, mock.Anything).Return(true, nil).Once()
+	s.env.OnActivity("ReleaseSeats", mock.Anything, activities.ReleaseSeatsInput{
+		OrderID: "test-order-123",
+		Reason:  "expired",
+	}).Return(&activities.ReleaseSeatsOutput{
+		Waitlists: []activities.WaitlistRef{{FlightID: "test-flight-456", Class: "economy"}},
+	}, nil).Once()
+	s.env.OnSignalExternalWorkflow(mock.Anything, WaitlistWorkflowID("test-flight-456", "economy"), "",
+		WaitlistSeatsReleasedSignal, mock.Anything).Return(nil).Once()
+
+	s.env.ExecuteWorkflow(BookingWorkflow, input)
+
+	s.True(s.env.IsWorkflowCompleted())
+	var result *BookingWorkflowResult
+	s.NoError(s.env.GetWorkflowResult(&result))
+	s.False(result.Success)
+	s.Equal("expired", result.FailureReason)
+}
@@ -0,0 +1,190 @@
+package workflows
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/cx-tal-miterani/flight-booking-system/temporal-worker/internal/activities"
+	"go.temporal.io/sdk/temporal"
+	"go.temporal.io/sdk/workflow"
+)
+
+// RebookingOfferTimeout is how long customers have to answer a rebooking
+// offer before their order is refunded (24 hours)
+const RebookingOfferTimeout = 24 * time.Hour
+
+// FlightDisruptionWorkflowInput is the input for the flight disruption workflow
+type FlightDisruptionWorkflowInput struct {
+	FlightID     string `json:"flightId"`
+	FlightNumber string `json:"flightNumber"`
+	Reason       string `json:"reason,omitempty"`
+}
+
+// FlightDisruptionWorkflowResult is the result of the flight disruption workflow
+type FlightDisruptionWorkflowResult struct {
+	AffectedOrders int `json:"affectedOrders"`
+	Rebooked       int `json:"rebooked"`
+	Refunded       int `json:"refunded"`
+}
+
+// RebookingResponseSignal is a customer's answer to a rebooking offer
+type RebookingResponseSignal struct {
+	OrderID  string `json:"orderId"`
+	Accept   bool   `json:"accept"`
+	FlightID string `json:"flightId,omitempty"`
+}
+
+// FlightDisruptionWorkflow handles the cancellation of a flight. Every
+// confirmed order on it is notified and offered rebooking on alternative
+// flights. Orders that decline, have no alternatives or do not answer
+// within RebookingOfferTimeout are refunded. The workflow fails when an
+// offer cannot be made, so that no order is refunded for want of one.
+func FlightDisruptionWorkflow(ctx workflow.Context, input FlightDisruptionWorkflowInput) (*FlightDisruptionWorkflowResult, error) {
+	logger := workflow.GetLogger(ctx)
+	logger.Info("Flight disruption workflow started", "flightId", input.FlightID, "flightNumber", input.FlightNumber)
+
+	ctx = workflow.WithActivityOptions(ctx, workflow.ActivityOptions{
+		StartToCloseTimeout: 30 * time.Second,
+		RetryPolicy: &temporal.RetryPolicy{
+			InitialInterval:    time.Second,
+			BackoffCoefficient: 2.0,
+			MaximumInterval:    time.Minute,
+			MaximumAttempts:    3,
+		},
+	})
+
+	var orders []activities.DisruptedOrder
+	err := workflow.ExecuteActivity(ctx, "GetDisruptedOrders", activities.GetDisruptedOrdersInput{
+		FlightID: input.FlightID,
+	}).Get(ctx, &orders)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &FlightDisruptionWorkflowResult{AffectedOrders: len(orders)}
+	deadline := workflow.Now(ctx).Add(RebookingOfferTimeout)
+
+	refund := func(orderID, reason string) {
+		var output activities.RefundOrderOutput
+		err := workflow.ExecuteActivity(ctx, "RefundOrder", activities.RefundOrderInput{
+			OrderID:  orderID,
+			FlightID: input.FlightID,
+			Reason:   reason,
+		}).Get(ctx, &output)
+		if err != nil {
+			logger.Error("Failed to refund order", "orderId", orderID, "reason", reason, "error", err)
+			return
+		}
+		result.Refunded++
+	}
+
+	// pending holds the orders still waiting to answer their offer, in the
+	// order they were made
+	var pending []string
+	for _, order := range orders {
+		var offer activities.OfferRebookingOutput
+		err := workflow.ExecuteActivity(ctx, "OfferRebooking", activities.OfferRebookingInput{
+			OrderID:   order.OrderID,
+			FlightID:  input.FlightID,
+			SeatCount: order.SeatCount,
+			ExpiresAt: deadline,
+		}).Get(ctx, &offer)
+		if err != nil {
+			return nil, fmt.Errorf("failed to offer rebooking for order %s: %w", order.OrderID, err)
+		}
+
+		err = workflow.ExecuteActivity(ctx, "NotifyDisruption", activities.NotifyDisruptionInput{
+			OrderID:              order.OrderID,
+			CustomerEmail:        order.CustomerEmail,
+			CustomerName:         order.CustomerName,
+			FlightNumber:         input.FlightNumber,
+			Reason:               input.Reason,
+			AlternativeFlightIDs: offer.AlternativeFlightIDs,
+			ExpiresAt:            deadline,
+		}).Get(ctx, nil)
+		if err != nil {
+			logger.Error("Failed to notify customer", "orderId", order.OrderID, "error", err)
+		}
+
+		if len(offer.AlternativeFlightIDs) == 0 {
+			refund(order.OrderID, "no_alternatives")
+			continue
+		}
+		pending = append(pending, order.OrderID)
+	}
+
+	resolve := func(orderID string) {
+		for i, id := range pending {
+			if id == orderID {
+				pending = append(pending[:i], pending[i+1:]...)
+				return
+			}
+		}
+	}
+	isPending := func(orderID string) bool {
+		for _, id := range pending {
+			if id == orderID {
+				return true
+			}
+		}
+		return false
+	}
+
+	responseCh := workflow.GetSignalChannel(ctx, "rebooking-response")
+	timerCtx, cancelTimer := workflow.WithCancel(ctx)
+	defer cancelTimer()
+	offerTimer := workflow.NewTimer(timerCtx, deadline.Sub(workflow.Now(ctx)))
+
+	var expired bool
+	for len(pending) > 0 && !expired {
+		selector := workflow.NewSelector(ctx)
+
+		selector.AddReceive(responseCh, func(c workflow.ReceiveChannel, more bool) {
+			var signal RebookingResponseSignal
+			c.Receive(ctx, &signal)
+			if !isPending(signal.OrderID) {
+				logger.Warn("Ignoring response for an order without a pending offer", "orderId", signal.OrderID)
+				return
+			}
+
+			if !signal.Accept {
+				logger.Info("Rebooking declined", "orderId", signal.OrderID)
+				resolve(signal.OrderID)
+				refund(signal.OrderID, "declined")
+				return
+			}
+
+			// A failed rebooking leaves the offer open so another
+			// alternative can be chosen before the deadline
+			var rebook activities.RebookOrderOutput
+			err := workflow.ExecuteActivity(ctx, "RebookOrder", activities.RebookOrderInput{
+				OrderID:     signal.OrderID,
+				FlightID:    input.FlightID,
+				NewFlightID: signal.FlightID,
+			}).Get(ctx, &rebook)
+			if err != nil || !rebook.Success {
+				logger.Error("Rebooking failed", "orderId", signal.OrderID, "error", err, "reason", rebook.FailureReason)
+				return
+			}
+
+			logger.Info("Order rebooked", "orderId", signal.OrderID, "flightId", signal.FlightID)
+			resolve(signal.OrderID)
+			result.Rebooked++
+		})
+
+		selector.AddFuture(offerTimer, func(f workflow.Future) {
+			expired = true
+		})
+
+		selector.Select(ctx)
+	}
+
+	// Whoever did not answer in time is refunded
+	for _, orderID := range pending {
+		refund(orderID, "expired")
+	}
+
+	logger.Info("Flight disruption workflow completed",
+		"affectedOrders", result.AffectedOrders, "rebooked", result.Rebooked, "refunded", result.Refunded)
+	return result, nil
+}
@@ -0,0 +1,227 @@
+package workflows
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/cx-tal-miterani/flight-booking-system/temporal-worker/internal/activities"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+	"go.temporal.io/sdk/activity"
+	"go.temporal.io/sdk/testsuite"
+)
+
+type DisruptionWorkflowTestSuite struct {
+	suite.Suite
+	testsuite.WorkflowTestSuite
+	env *testsuite.TestWorkflowEnvironment
+}
+
+func (s *DisruptionWorkflowTestSuite) SetupTest() {
+	s.env = s.NewTestWorkflowEnvironment()
+
+	acts := &activities.Activities{}
+	s.env.RegisterActivityWithOptions(acts.GetDisruptedOrders, activity.RegisterOptions{Name: "GetDisruptedOrders"})
+	s.env.RegisterActivityWithOptions(acts.OfferRebooking, activity.RegisterOptions{Name: "OfferRebooking"})
+	s.env.RegisterActivityWithOptions(acts.NotifyDisruption, activity.RegisterOptions{Name: "NotifyDisruption"})
+	s.env.RegisterActivityWithOptions(acts.RebookOrder, activity.RegisterOptions{Name: "RebookOrder"})
+	s.env.RegisterActivityWithOptions(acts.RefundOrder, activity.RegisterOptions{Name: "RefundOrder"})
+}
+
+func (s *DisruptionWorkflowTestSuite) AfterTest(suiteName, testName string) {
+	s.env.AssertExpectations(s.T())
+}
+
+func TestDisruptionWorkflowTestSuite(t *testing.T) {
+	suite.Run(t, new(DisruptionWorkflowTestSuite))
+}
+
+var disruptionInput = FlightDisruptionWorkflowInput{
+	FlightID:     "cancelled-flight",
+	FlightNumber: "FL101",
+	Reason:       "Aircraft maintenance",
+}
+
+// expectOrders mocks the disrupted orders and offers each of them the given
+// alternatives
+func (s *DisruptionWorkflowTestSuite) expectOrders(alternatives []string, orderIDs ...string) {
+	orders := make([]activities.DisruptedOrder, 0, len(orderIDs))
+	for _, id := range orderIDs {
+		orders = append(orders, activities.DisruptedOrder{
+			OrderID:       id,
+			CustomerName:  "John Doe",
+			CustomerEmail: "john@example.com",
+			SeatCount:     2,
+		})
+	}
+	s.env.OnActivity("GetDisruptedOrders", mock.Anything, activities.GetDisruptedOrdersInput{
+		FlightID: "cancelled-flight",
+	}).Return(orders, nil)
+	if len(orders) == 0 {
+		return
+	}
+	s.env.OnActivity("OfferRebooking", mock.Anything, mock.Anything).Return(&activities.OfferRebookingOutput{
+		AlternativeFlightIDs: alternatives,
+	}, nil).Times(len(orders))
+	s.env.OnActivity("NotifyDisruption", mock.Anything, mock.Anything).Return(nil).Times(len(orders))
+}
+
+func (s *DisruptionWorkflowTestSuite) expectRefund(orderID, reason string) {
+	s.env.OnActivity("RefundOrder", mock.Anything, activities.RefundOrderInput{
+		OrderID:  orderID,
+		FlightID: "cancelled-flight",
+		Reason:   reason,
+	}).Return(&activities.RefundOrderOutput{RefundID: "RFD-1", Amount: 300}, nil).Once()
+}
+
+func (s *DisruptionWorkflowTestSuite) result() *FlightDisruptionWorkflowResult {
+	s.True(s.env.IsWorkflowCompleted())
+	var result *FlightDisruptionWorkflowResult
+	s.NoError(s.env.GetWorkflowResult(&result))
+	return result
+}
+
+func (s *DisruptionWorkflowTestSuite) TestWorkflow_Constants() {
+	s.Equal(24*time.Hour, RebookingOfferTimeout, "Rebooking offers should be open for 24 hours")
+}
+
+func (s *DisruptionWorkflowTestSuite) TestWorkflow_NoOrders() {
+	s.expectOrders(nil)
+
+	s.env.ExecuteWorkflow(FlightDisruptionWorkflow, disruptionInput)
+
+	s.Equal(FlightDisruptionWorkflowResult{}, *s.result())
+}
+
+func (s *DisruptionWorkflowTestSuite) TestWorkflow_AcceptRebooking() {
+	s.expectOrders([]string{"alt-1", "alt-2"}, "order-1")
+	s.env.OnActivity("RebookOrder", mock.Anything, activities.RebookOrderInput{
+		OrderID:     "order-1",
+		FlightID:    "cancelled-flight",
+		NewFlightID: "alt-2",
+	}).Return(&activities.RebookOrderOutput{Success: true}, nil).Once()
+
+	s.env.RegisterDelayedCallback(func() {
+		s.env.SignalWorkflow("rebooking-response", RebookingResponseSignal{
+			OrderID:  "order-1",
+			Accept:   true,
+			FlightID: "alt-2",
+		})
+	}, time.Hour)
+
+	s.env.ExecuteWorkflow(FlightDisruptionWorkflow, disruptionInput)
+
+	s.Equal(FlightDisruptionWorkflowResult{AffectedOrders: 1, Rebooked: 1}, *s.result())
+}
+
+func (s *DisruptionWorkflowTestSuite) TestWorkflow_DeclineRebooking() {
+	s.expectOrders([]string{"alt-1"}, "order-1")
+	s.expectRefund("order-1", "declined")
+
+	s.env.RegisterDelayedCallback(func() {
+		s.env.SignalWorkflow("rebooking-response", RebookingResponseSignal{OrderID: "order-1"})
+	}, time.Hour)
+
+	s.env.ExecuteWorkflow(FlightDisruptionWorkflow, disruptionInput)
+
+	s.Equal(FlightDisruptionWorkflowResult{AffectedOrders: 1, Refunded: 1}, *s.result())
+}
+
+func (s *DisruptionWorkflowTestSuite) TestWorkflow_NoAlternatives() {
+	s.expectOrders(nil, "order-1")
+	s.expectRefund("order-1", "no_alternatives")
+
+	s.env.ExecuteWorkflow(FlightDisruptionWorkflow, disruptionInput)
+
+	s.Equal(FlightDisruptionWorkflowResult{AffectedOrders: 1, Refunded: 1}, *s.result())
+}
+
+func (s *DisruptionWorkflowTestSuite) TestWorkflow_OfferFails() {
+	s.env.OnActivity("GetDisruptedOrders", mock.Anything, mock.Anything).Return([]activities.DisruptedOrder{
+		{OrderID: "order-1", SeatCount: 2},
+	}, nil)
+	s.env.OnActivity("OfferRebooking", mock.Anything, mock.Anything).
+		Return(nil, errors.New("database unavailable"))
+
+	s.env.ExecuteWorkflow(FlightDisruptionWorkflow, disruptionInput)
+
+	s.True(s.env.IsWorkflowCompleted())
+	s.Error(s.env.GetWorkflowError())
+	s.env.AssertNotCalled(s.T(), "NotifyDisruption", mock.Anything, mock.Anything)
+	s.env.AssertNotCalled(s.T(), "RefundOrder", mock.Anything, mock.Anything)
+}
+
+func (s *DisruptionWorkflowTestSuite) TestWorkflow_OfferExpires() {
+	s.expectOrders([]string{"alt-1"}, "order-1", "order-2")
+	s.env.OnActivity("RebookOrder", mock.Anything, mock.Anything).Return(&activities.RebookOrderOutput{Success: true}, nil).Once()
+	s.expectRefund("order-2", "expired")
+
+	s.env.RegisterDelayedCallback(func() {
+		s.env.SignalWorkflow("rebooking-response", RebookingResponseSignal{
+			OrderID:  "order-1",
+			Accept:   true,
+			FlightID: "alt-1",
+		})
+	}, time.Hour)
+
+	s.env.ExecuteWorkflow(FlightDisruptionWorkflow, disruptionInput)
+
+	s.Equal(FlightDisruptionWorkflowResult{AffectedOrders: 2, Rebooked: 1, Refunded: 1}, *s.result())
+}
+
+func (s *DisruptionWorkflowTestSuite) TestWorkflow_RebookingFailed_ChooseAgain() {
+	s.expectOrders([]string{"alt-1", "alt-2"}, "order-1")
+	s.env.OnActivity("RebookOrder", mock.Anything, activities.RebookOrderInput{
+		OrderID:     "order-1",
+		FlightID:    "cancelled-flight",
+		NewFlightID: "alt-1",
+	}).Return(&activities.RebookOrderOutput{
+		Success:       false,
+		FailureReason: "not enough seats available: 1 of 2 seats",
+	}, nil).Once()
+	s.env.OnActivity("RebookOrder", mock.Anything, activities.RebookOrderInput{
+		OrderID:     "order-1",
+		FlightID:    "cancelled-flight",
+		NewFlightID: "alt-2",
+	}).Return(&activities.RebookOrderOutput{Success: true}, nil).Once()
+
+	s.env.RegisterDelayedCallback(func() {
+		s.env.SignalWorkflow("rebooking-response", RebookingResponseSignal{
+			OrderID:  "order-1",
+			Accept:   true,
+			FlightID: "alt-1",
+		})
+	}, time.Hour)
+	s.env.RegisterDelayedCallback(func() {
+		s.env.SignalWorkflow("rebooking-response", RebookingResponseSignal{
+			OrderID:  "order-1",
+			Accept:   true,
+			FlightID: "alt-2",
+		})
+	}, 2*time.Hour)
+
+	s.env.ExecuteWorkflow(FlightDisruptionWorkflow, disruptionInput)
+
+	s.Equal(FlightDisruptionWorkflowResult{AffectedOrders: 1, Rebooked: 1}, *s.result())
+}
+
+func (s *DisruptionWorkflowTestSuite) TestWorkflow_IgnoresUnknownOrder() {
+	s.expectOrders([]string{"alt-1"}, "order-1")
+	s.expectRefund("order-1", "declined")
+
+	s.env.RegisterDelayedCallback(func() {
+		s.env.SignalWorkflow("rebooking-response", RebookingResponseSignal{
+			OrderID:  "other-order",
+			Accept:   true,
+			FlightID: "alt-1",
+		})
+	}, time.Hour)
+	s.env.RegisterDelayedCallback(func() {
+		s.env.SignalWorkflow("rebooking-response", RebookingResponseSignal{OrderID: "order-1"})
+	}, 2*time.Hour)
+
+	s.env.ExecuteWorkflow(FlightDisruptionWorkflow, disruptionInput)
+
+	s.Equal(FlightDisruptionWorkflowResult{AffectedOrders: 1, Refunded: 1}, *s.result())
+}
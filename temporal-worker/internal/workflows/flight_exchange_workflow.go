@@ -0,0 +1,197 @@
+package workflows
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/cx-tal-miterani/flight-booking-system/temporal-worker/internal/activities"
+	"go.temporal.io/sdk/temporal"
+	"go.temporal.io/sdk/workflow"
+)
+
+// FlightExchangeWorkflowInput is the input for the flight exchange workflow.
+// ChangeFee is charged per seat moved; PaymentCode pays the amount due when
+// the fare difference and fees are positive.
+type FlightExchangeWorkflowInput struct {
+	ExchangeID  string  `json:"exchangeId"`
+	OrderID     string  `json:"orderId"`
+	ChangeFee   float64 `json:"changeFee"`
+	PaymentCode string  `json:"paymentCode,omitempty"`
+}
+
+// FlightExchangeWorkflowResult is the result of the flight exchange workflow.
+// A positive AmountDue was charged, a negative one refunded.
+type FlightExchangeWorkflowResult struct {
+	Success        bool    `json:"success"`
+	FareDifference float64 `json:"fareDifference"`
+	ChangeFee      float64 `json:"changeFee"`
+	AmountDue      float64 `json:"amountDue"`
+	TransactionID  string  `json:"transactionId,omitempty"`
+	RefundID       string  `json:"refundId,omitempty"`
+	FailureReason  string  `json:"failureReason,omitempty"`
+}
+
+// FlightExchangeWorkflow moves a confirmed order from one of its flights to
+// another. It holds seats on the new flight, prices the change as the fare
+// difference plus the change fee, and charges what is owed before booking
+// the new seats. The order's old seats are only released once the new ones
+// are booked, so the order holds seats throughout. If the change costs less
+// than the old seats the difference is refunded at the end; if the new
+// seats cannot be booked after the customer paid, the charge is refunded
+// and the order keeps its flight.
+func FlightExchangeWorkflow(ctx workflow.Context, input FlightExchangeWorkflowInput) (*FlightExchangeWorkflowResult, error) {
+	logger := workflow.GetLogger(ctx)
+	logger.Info("Flight exchange workflow started", "exchangeId", input.ExchangeID, "orderId", input.OrderID)
+
+	ctx = workflow.WithActivityOptions(ctx, workflow.ActivityOptions{
+		StartToCloseTimeout: 30 * time.Second,
+		RetryPolicy: &temporal.RetryPolicy{
+			InitialInterval:    time.Second,
+			BackoffCoefficient: 2.0,
+			MaximumInterval:    time.Minute,
+			MaximumAttempts:    3,
+		},
+	})
+
+	// Payment activity with shorter timeout (10 seconds)
+	paymentCtx := workflow.WithActivityOptions(ctx, workflow.ActivityOptions{
+		StartToCloseTimeout: PaymentTimeout,
+		RetryPolicy: &temporal.RetryPolicy{
+			MaximumAttempts: 1, // No automatic retries for payment
+		},
+	})
+
+	// The old seats must not stay booked once the order has moved, so
+	// releasing them is retried for longer
+	releaseCtx := workflow.WithActivityOptions(ctx, workflow.ActivityOptions{
+		StartToCloseTimeout: 30 * time.Second,
+		RetryPolicy: &temporal.RetryPolicy{
+			InitialInterval:    time.Second,
+			BackoffCoefficient: 2.0,
+			MaximumInterval:    5 * time.Minute,
+			MaximumAttempts:    20,
+		},
+	})
+
+	result := &FlightExchangeWorkflowResult{}
+
+	fail := func(reason string) {
+		result.FailureReason = reason
+		var output activities.FailFlightExchangeOutput
+		err := workflow.ExecuteActivity(ctx, "FailFlightExchange", activities.FailFlightExchangeInput{
+			ExchangeID: input.ExchangeID,
+			Reason:     reason,
+		}).Get(ctx, &output)
+		if err != nil {
+			logger.Error("Failed to release seats of flight exchange", "exchangeId", input.ExchangeID, "error", err)
+			return
+		}
+		notifyWaitlists(ctx, output.Waitlists)
+	}
+
+	refund := func(amount float64) error {
+		var output activities.RefundFlightExchangeOutput
+		err := workflow.ExecuteActivity(ctx, "RefundFlightExchange", activities.RefundFlightExchangeInput{
+			ExchangeID: input.ExchangeID,
+			OrderID:    input.OrderID,
+			Amount:     amount,
+		}).Get(ctx, &output)
+		if err != nil {
+			return err
+		}
+		result.RefundID = output.RefundID
+		return nil
+	}
+
+	// Step 1: Hold seats on the new flight and price the change
+	var held activities.HoldFlightExchangeSeatsOutput
+	err := workflow.ExecuteActivity(ctx, "HoldFlightExchangeSeats", activities.HoldFlightExchangeSeatsInput{
+		ExchangeID: input.ExchangeID,
+		ChangeFee:  input.ChangeFee,
+	}).Get(ctx, &held)
+	if err != nil {
+		logger.Error("Failed to hold flight exchange seats", "exchangeId", input.ExchangeID, "error", err)
+		held.FailureReason = "Seats on the new flight could not be held"
+	}
+	if !held.Success {
+		fail(held.FailureReason)
+		return result, nil
+	}
+	result.FareDifference = held.FareDifference
+	result.ChangeFee = held.ChangeFee
+	result.AmountDue = roundCents(held.FareDifference + held.ChangeFee)
+
+	// Step 2: Collect what is owed
+	if result.AmountDue > 0 {
+		if input.PaymentCode == "" {
+			fail(fmt.Sprintf("Payment of %.2f is required to change flights", result.AmountDue))
+			return result, nil
+		}
+		var payment activities.ValidatePaymentOutput
+		err := workflow.ExecuteActivity(paymentCtx, "ChargeFlightExchange", activities.ChargeFlightExchangeInput{
+			ExchangeID:  input.ExchangeID,
+			OrderID:     input.OrderID,
+			PaymentCode: input.PaymentCode,
+			Amount:      result.AmountDue,
+		}).Get(ctx, &payment)
+		if err != nil {
+			logger.Error("Flight exchange payment activity failed", "error", err)
+			fail("Payment processing error")
+			return result, nil
+		}
+		if !payment.Success {
+			fail(payment.ErrorMessage)
+			return result, nil
+		}
+		result.TransactionID = payment.TransactionID
+	}
+
+	// Step 3: Book the new seats
+	var confirmed activities.ConfirmFlightExchangeOutput
+	err = workflow.ExecuteActivity(ctx, "ConfirmFlightExchange", activities.ConfirmFlightExchangeInput{
+		ExchangeID:    input.ExchangeID,
+		TransactionID: result.TransactionID,
+	}).Get(ctx, &confirmed)
+	if err != nil {
+		logger.Error("Failed to confirm flight exchange", "exchangeId", input.ExchangeID, "error", err)
+		confirmed.FailureReason = "Flight change could not be completed"
+	}
+	if !confirmed.Success {
+		fail(confirmed.FailureReason)
+		if result.TransactionID != "" {
+			// Give the charged amount back
+			if err := refund(result.AmountDue); err != nil {
+				logger.Error("Failed to refund flight exchange charge", "exchangeId", input.ExchangeID, "error", err)
+			}
+		}
+		return result, nil
+	}
+	result.Success = true
+
+	// Step 4: Only now release the seats on the old flight
+	var released activities.ReleaseFlightExchangeSeatsOutput
+	err = workflow.ExecuteActivity(releaseCtx, "ReleaseFlightExchangeSeats", activities.ReleaseFlightExchangeSeatsInput{
+		ExchangeID: input.ExchangeID,
+	}).Get(ctx, &released)
+	if err != nil {
+		logger.Error("Failed to release old seats of flight exchange", "exchangeId", input.ExchangeID, "error", err)
+	} else {
+		notifyWaitlists(ctx, released.Waitlists)
+	}
+
+	// Step 5: Refund a cheaper change
+	if result.AmountDue < 0 {
+		if err := refund(-result.AmountDue); err != nil {
+			logger.Error("Failed to refund flight exchange difference", "exchangeId", input.ExchangeID, "error", err)
+		}
+	}
+
+	logger.Info("Flight exchange workflow completed", "exchangeId", input.ExchangeID, "success", result.Success, "amountDue", result.AmountDue)
+	return result, nil
+}
+
+// roundCents rounds an amount to whole cents
+func roundCents(amount float64) float64 {
+	return math.Round(amount*100) / 100
+}
@@ -0,0 +1,181 @@
+package workflows
+
+import (
+	"testing"
+
+	"github.com/cx-tal-miterani/flight-booking-system/temporal-worker/internal/activities"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+	"go.temporal.io/sdk/activity"
+	"go.temporal.io/sdk/testsuite"
+)
+
+type FlightExchangeWorkflowTestSuite struct {
+	suite.Suite
+	testsuite.WorkflowTestSuite
+	env *testsuite.TestWorkflowEnvironment
+}
+
+func (s *FlightExchangeWorkflowTestSuite) SetupTest() {
+	s.env = s.NewTestWorkflowEnvironment()
+
+	acts := &activities.Activities{}
+	s.env.RegisterActivityWithOptions(acts.HoldFlightExchangeSeats, activity.RegisterOptions{Name: "HoldFlightExchangeSeats"})
+	s.env.RegisterActivityWithOptions(acts.ChargeFlightExchange, activity.RegisterOptions{Name: "ChargeFlightExchange"})
+	s.env.RegisterActivityWithOptions(acts.ConfirmFlightExchange, activity.RegisterOptions{Name: "ConfirmFlightExchange"})
+	s.env.RegisterActivityWithOptions(acts.ReleaseFlightExchangeSeats, activity.RegisterOptions{Name: "ReleaseFlightExchangeSeats"})
+	s.env.RegisterActivityWithOptions(acts.FailFlightExchange, activity.RegisterOptions{Name: "FailFlightExchange"})
+	s.env.RegisterActivityWithOptions(acts.RefundFlightExchange, activity.RegisterOptions{Name: "RefundFlightExchange"})
+}
+
+func (s *FlightExchangeWorkflowTestSuite) AfterTest(suiteName, testName string) {
+	s.env.AssertExpectations(s.T())
+}
+
+func TestFlightExchangeWorkflowTestSuite(t *testing.T) {
+	suite.Run(t, new(FlightExchangeWorkflowTestSuite))
+}
+
+func (s *FlightExchangeWorkflowTestSuite) TestWorkflow_ChargesThenReleasesOldSeats() {
+	var steps []string
+	step := func(name string) func(mock.Arguments) {
+		return func(mock.Arguments) { steps = append(steps, name) }
+	}
+
+	s.env.OnActivity("HoldFlightExchangeSeats", mock.Anything, activities.HoldFlightExchangeSeatsInput{
+		ExchangeID: testExchangeID,
+		ChangeFee:  50,
+	}).Return(&activities.HoldFlightExchangeSeatsOutput{Success: true, FareDifference: 30.1, ChangeFee: 100, Seats: 2}, nil).Run(step("hold")).Once()
+	s.env.OnActivity("ChargeFlightExchange", mock.Anything, activities.ChargeFlightExchangeInput{
+		ExchangeID:  testExchangeID,
+		OrderID:     "order-1",
+		PaymentCode: "12345",
+		Amount:      130.1,
+	}).Return(&activities.ValidatePaymentOutput{Success: true, TransactionID: "TXN-1"}, nil).Run(step("charge")).Once()
+	s.env.OnActivity("ConfirmFlightExchange", mock.Anything, activities.ConfirmFlightExchangeInput{
+		ExchangeID:    testExchangeID,
+		TransactionID: "TXN-1",
+	}).Return(&activities.ConfirmFlightExchangeOutput{Success: true}, nil).Run(step("confirm")).Once()
+	s.env.OnActivity("ReleaseFlightExchangeSeats", mock.Anything, activities.ReleaseFlightExchangeSeatsInput{
+		ExchangeID: testExchangeID,
+	}).Return(&activities.ReleaseFlightExchangeSeatsOutput{}, nil).Run(step("release")).Once()
+
+	s.env.ExecuteWorkflow(FlightExchangeWorkflow, FlightExchangeWorkflowInput{
+		ExchangeID:  testExchangeID,
+		OrderID:     "order-1",
+		ChangeFee:   50,
+		PaymentCode: "12345",
+	})
+
+	s.True(s.env.IsWorkflowCompleted())
+	var result *FlightExchangeWorkflowResult
+	s.NoError(s.env.GetWorkflowResult(&result))
+	s.True(result.Success)
+	s.Equal(130.1, result.AmountDue)
+	s.Equal("TXN-1", result.TransactionID)
+	s.Empty(result.RefundID)
+	s.Equal([]string{"hold", "charge", "confirm", "release"}, steps)
+}
+
+func (s *FlightExchangeWorkflowTestSuite) TestWorkflow_RefundsCheaperFlight() {
+	s.env.OnActivity("HoldFlightExchangeSeats", mock.Anything, mock.Anything).
+		Return(&activities.HoldFlightExchangeSeatsOutput{Success: true, FareDifference: -120, ChangeFee: 50, Seats: 1}, nil).Once()
+	s.env.OnActivity("ConfirmFlightExchange", mock.Anything, activities.ConfirmFlightExchangeInput{
+		ExchangeID: testExchangeID,
+	}).Return(&activities.ConfirmFlightExchangeOutput{Success: true}, nil).Once()
+	s.env.OnActivity("ReleaseFlightExchangeSeats", mock.Anything, mock.Anything).
+		Return(&activities.ReleaseFlightExchangeSeatsOutput{}, nil).Once()
+	s.env.OnActivity("RefundFlightExchange", mock.Anything, activities.RefundFlightExchangeInput{
+		ExchangeID: testExchangeID,
+		OrderID:    "order-1",
+		Amount:     70,
+	}).Return(&activities.RefundFlightExchangeOutput{RefundID: "RFD-7c9e6679", Amount: 70}, nil).Once()
+
+	s.env.ExecuteWorkflow(FlightExchangeWorkflow, FlightExchangeWorkflowInput{
+		ExchangeID: testExchangeID,
+		OrderID:    "order-1",
+		ChangeFee:  50,
+	})
+
+	s.True(s.env.IsWorkflowCompleted())
+	var result *FlightExchangeWorkflowResult
+	s.NoError(s.env.GetWorkflowResult(&result))
+	s.True(result.Success)
+	s.Equal(-70.0, result.AmountDue)
+	s.Equal("RFD-7c9e6679", result.RefundID)
+}
+
+func (s *FlightExchangeWorkflowTestSuite) TestWorkflow_NoSeatsKeepsFlight() {
+	s.env.OnActivity("HoldFlightExchangeSeats", mock.Anything, mock.Anything).
+		Return(&activities.HoldFlightExchangeSeatsOutput{FailureReason: "Not enough seats available on the new flight"}, nil).Once()
+	s.env.OnActivity("FailFlightExchange", mock.Anything, activities.FailFlightExchangeInput{
+		ExchangeID: testExchangeID,
+		Reason:     "Not enough seats available on the new flight",
+	}).Return(&activities.FailFlightExchangeOutput{}, nil).Once()
+
+	s.env.ExecuteWorkflow(FlightExchangeWorkflow, FlightExchangeWorkflowInput{
+		ExchangeID:  testExchangeID,
+		OrderID:     "order-1",
+		ChangeFee:   50,
+		PaymentCode: "12345",
+	})
+
+	s.True(s.env.IsWorkflowCompleted())
+	var result *FlightExchangeWorkflowResult
+	s.NoError(s.env.GetWorkflowResult(&result))
+	s.False(result.Success)
+	s.Equal("Not enough seats available on the new flight", result.FailureReason)
+}
+
+func (s *FlightExchangeWorkflowTestSuite) TestWorkflow_PaymentRequired() {
+	s.env.OnActivity("HoldFlightExchangeSeats", mock.Anything, mock.Anything).
+		Return(&activities.HoldFlightExchangeSeatsOutput{Success: true, FareDifference: 0, ChangeFee: 50, Seats: 1}, nil).Once()
+	s.env.OnActivity("FailFlightExchange", mock.Anything, activities.FailFlightExchangeInput{
+		ExchangeID: testExchangeID,
+		Reason:     "Payment of 50.00 is required to change flights",
+	}).Return(&activities.FailFlightExchangeOutput{}, nil).Once()
+
+	s.env.ExecuteWorkflow(FlightExchangeWorkflow, FlightExchangeWorkflowInput{
+		ExchangeID: testExchangeID,
+		OrderID:    "order-1",
+		ChangeFee:  50,
+	})
+
+	s.True(s.env.IsWorkflowCompleted())
+	var result *FlightExchangeWorkflowResult
+	s.NoError(s.env.GetWorkflowResult(&result))
+	s.False(result.Success)
+	s.Empty(result.TransactionID)
+}
+
+func (s *FlightExchangeWorkflowTestSuite) TestWorkflow_ConfirmFailedRefundsCharge() {
+	s.env.OnActivity("HoldFlightExchangeSeats", mock.Anything, mock.Anything).
+		Return(&activities.HoldFlightExchangeSeatsOutput{Success: true, FareDifference: 40, ChangeFee: 50, Seats: 1}, nil).Once()
+	s.env.OnActivity("ChargeFlightExchange", mock.Anything, mock.Anything).
+		Return(&activities.ValidatePaymentOutput{Success: true, TransactionID: "TXN-1"}, nil).Once()
+	s.env.OnActivity("ConfirmFlightExchange", mock.Anything, mock.Anything).
+		Return(&activities.ConfirmFlightExchangeOutput{FailureReason: "seats no longer held for order"}, nil).Once()
+	s.env.OnActivity("FailFlightExchange", mock.Anything, activities.FailFlightExchangeInput{
+		ExchangeID: testExchangeID,
+		Reason:     "seats no longer held for order",
+	}).Return(&activities.FailFlightExchangeOutput{}, nil).Once()
+	s.env.OnActivity("RefundFlightExchange", mock.Anything, activities.RefundFlightExchangeInput{
+		ExchangeID: testExchangeID,
+		OrderID:    "order-1",
+		Amount:     90,
+	}).Return(&activities.RefundFlightExchangeOutput{RefundID: "RFD-7c9e6679", Amount: 90}, nil).Once()
+
+	s.env.ExecuteWorkflow(FlightExchangeWorkflow, FlightExchangeWorkflowInput{
+		ExchangeID:  testExchangeID,
+		OrderID:     "order-1",
+		ChangeFee:   50,
+		PaymentCode: "12345",
+	})
+
+	s.True(s.env.IsWorkflowCompleted())
+	var result *FlightExchangeWorkflowResult
+	s.NoError(s.env.GetWorkflowResult(&result))
+	s.False(result.Success)
+	s.Equal("TXN-1", result.TransactionID)
+	s.Equal("RFD-7c9e6679", result.RefundID)
+}
@@ -0,0 +1,100 @@
+package workflows
+
+import (
+	"time"
+
+	"github.com/cx-tal-miterani/flight-booking-system/temporal-worker/internal/activities"
+	"go.temporal.io/sdk/temporal"
+	"go.temporal.io/sdk/workflow"
+)
+
+const (
+	// DefaultHoldReaperBatchSize is how many seats are released per batch
+	// when the workflow input does not say
+	DefaultHoldReaperBatchSize = 500
+	// DefaultHoldReaperMaxBatches bounds the batches of a single run; what is
+	// left is released by the next run
+	DefaultHoldReaperMaxBatches = 20
+)
+
+// HoldReaperInput is the input for the hold reaper workflow
+type HoldReaperInput struct {
+	BatchSize  int `json:"batchSize,omitempty"`
+	MaxBatches int `json:"maxBatches,omitempty"`
+}
+
+// HoldReaperResult is the result of the hold reaper workflow
+type HoldReaperResult struct {
+	SeatsReleased int `json:"seatsReleased"`
+	OrdersExpired int `json:"ordersExpired"`
+	// SeatsUnblocked counts the seats whose admin block expired
+	SeatsUnblocked int `json:"seatsUnblocked"`
+}
+
+// HoldReaperWorkflow releases the seats whose 15-minute hold has expired,
+// then lifts the admin seat blocks that have expired, each in batches until
+// none are left. The worker runs it on a cron schedule.
+func HoldReaperWorkflow(ctx workflow.Context, input HoldReaperInput) (*HoldReaperResult, error) {
+	logger := workflow.GetLogger(ctx)
+
+	batchSize := input.BatchSize
+	if batchSize <= 0 {
+		batchSize = DefaultHoldReaperBatchSize
+	}
+	maxBatches := input.MaxBatches
+	if maxBatches <= 0 {
+		maxBatches = DefaultHoldReaperMaxBatches
+	}
+
+	ctx = workflow.WithActivityOptions(ctx, workflow.ActivityOptions{
+		StartToCloseTimeout: 30 * time.Second,
+		RetryPolicy: &temporal.RetryPolicy{
+			InitialInterval:    time.Second,
+			BackoffCoefficient: 2.0,
+			MaximumInterval:    10 * time.Second,
+			MaximumAttempts:    3,
+		},
+	})
+
+	result := &HoldReaperResult{}
+	for batch := 0; batch < maxBatches; batch++ {
+		var output activities.ReleaseExpiredHoldsOutput
+		err := workflow.ExecuteActivity(ctx, "ReleaseExpiredHolds", activities.ReleaseExpiredHoldsInput{
+			BatchSize: batchSize,
+		}).Get(ctx, &output)
+		if err != nil {
+			return nil, err
+		}
+		result.SeatsReleased += output.SeatsReleased
+		result.OrdersExpired += output.OrdersExpired
+		notifyWaitlists(ctx, output.Waitlists)
+
+		if output.SeatsReleased < batchSize {
+			break
+		}
+	}
+
+	for batch := 0; batch < maxBatches; batch++ {
+		var output activities.ReleaseExpiredBlocksOutput
+		err := workflow.ExecuteActivity(ctx, "ReleaseExpiredBlocks", activities.ReleaseExpiredBlocksInput{
+			BatchSize: batchSize,
+		}).Get(ctx, &output)
+		if err != nil {
+			return nil, err
+		}
+		result.SeatsUnblocked += output.SeatsReleased
+		notifyWaitlists(ctx, output.Waitlists)
+
+		if output.SeatsReleased < batchSize {
+			break
+		}
+	}
+
+	if result.SeatsReleased > 0 {
+		logger.Info("Hold reaper released expired holds", "seatsReleased", result.SeatsReleased, "ordersExpired", result.OrdersExpired)
+	}
+	if result.SeatsUnblocked > 0 {
+		logger.Info("Hold reaper lifted expired seat blocks", "seatsUnblocked", result.SeatsUnblocked)
+	}
+	return result, nil
+}
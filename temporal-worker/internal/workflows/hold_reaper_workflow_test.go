@@ -0,0 +1,161 @@
+package workflows
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/cx-tal-miterani/flight-booking-system/temporal-worker/internal/activities"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+	"go.temporal.io/sdk/activity"
+	"go.temporal.io/sdk/testsuite"
+)
+
+type HoldReaperWorkflowTestSuite struct {
+	suite.Suite
+	testsuite.WorkflowTestSuite
+	env *testsuite.TestWorkflowEnvironment
+}
+
+func (s *HoldReaperWorkflowTestSuite) SetupTest() {
+	s.env = s.NewTestWorkflowEnvironment()
+
+	acts := &activities.Activities{}
+	s.env.RegisterActivityWithOptions(acts.ReleaseExpiredHolds, activity.RegisterOptions{Name: "ReleaseExpiredHolds"})
+	s.env.RegisterActivityWithOptions(acts.ReleaseExpiredBlocks, activity.RegisterOptions{Name: "ReleaseExpiredBlocks"})
+}
+
+func (s *HoldReaperWorkflowTestSuite) AfterTest(suiteName, testName string) {
+	s.env.AssertExpectations(s.T())
+}
+
+func TestHoldReaperWorkflowTestSuite(t *testing.T) {
+	suite.Run(t, new(HoldReaperWorkflowTestSuite))
+}
+
+func (s *HoldReaperWorkflowTestSuite) TestWorkflow_NothingExpired() {
+	s.env.OnActivity("ReleaseExpiredHolds", mock.Anything, activities.ReleaseExpiredHoldsInput{
+		BatchSize: DefaultHoldReaperBatchSize,
+	}).Return(&activities.ReleaseExpiredHoldsOutput{}, nil).Once()
+	s.env.OnActivity("ReleaseExpiredBlocks", mock.Anything, activities.ReleaseExpiredBlocksInput{
+		BatchSize: DefaultHoldReaperBatchSize,
+	}).Return(&activities.ReleaseExpiredBlocksOutput{}, nil).Once()
+
+	s.env.ExecuteWorkflow(HoldReaperWorkflow, HoldReaperInput{})
+
+	s.True(s.env.IsWorkflowCompleted())
+	var result *HoldReaperResult
+	s.NoError(s.env.GetWorkflowResult(&result))
+	s.Equal(HoldReaperResult{}, *result)
+}
+
+func (s *HoldReaperWorkflowTestSuite) TestWorkflow_ReleasesUntilBatchIsNotFull() {
+	input := activities.ReleaseExpiredHoldsInput{BatchSize: 10}
+	s.env.OnActivity("ReleaseExpiredHolds", mock.Anything, input).
+		Return(&activities.ReleaseExpiredHoldsOutput{SeatsReleased: 10, OrdersExpired: 4}, nil).Twice()
+	s.env.OnActivity("ReleaseExpiredHolds", mock.Anything, input).
+		Return(&activities.ReleaseExpiredHoldsOutput{SeatsReleased: 3, OrdersExpired: 1}, nil).Once()
+	s.env.OnActivity("ReleaseExpiredBlocks", mock.Anything, mock.Anything).
+		Return(&activities.ReleaseExpiredBlocksOutput{}, nil).Once()
+
+	s.env.ExecuteWorkflow(HoldReaperWorkflow, HoldReaperInput{BatchSize: 10})
+
+	s.True(s.env.IsWorkflowCompleted())
+	var result *HoldReaperResult
+	s.NoError(s.env.GetWorkflowResult(&result))
+	s.Equal(23, result.SeatsReleased)
+	s.Equal(9, result.OrdersExpired)
+}
+
+func (s *HoldReaperWorkflowTestSuite) TestWorkflow_SignalsWaitlistsOfReleasedSeats() {
+	s.env.OnActivity("ReleaseExpiredHolds", mock.Anything, mock.Anything).
+		Return(&activities.ReleaseExpiredHoldsOutput{
+			SeatsReleased: 2,
+			OrdersExpired: 1,
+			Waitlists: []activities.WaitlistRef{
+				{FlightID: "flight-1", Class: "economy"},
+				{FlightID: "flight-2", Class: "business"},
+			},
+		}, nil).Once()
+	s.env.OnActivity("ReleaseExpiredBlocks", mock.Anything, mock.Anything).
+		Return(&activities.ReleaseExpiredBlocksOutput{
+			SeatsReleased: 1,
+			Waitlists:     []activities.WaitlistRef{{FlightID: "flight-1", Class: "first"}},
+		}, nil).Once()
+	for _, id := range []string{
+		WaitlistWorkflowID("flight-1", "economy"),
+		WaitlistWorkflowID("flight-2", "business"),
+		WaitlistWorkflowID("flight-1", "first"),
+	} {
+		s.env.OnSignalExternalWorkflow(mock.Anything, id, "", WaitlistSeatsReleasedSignal, mock.Anything).
+			Return(nil).Once()
+	}
+
+	s.env.ExecuteWorkflow(HoldReaperWorkflow, HoldReaperInput{})
+
+	s.True(s.env.IsWorkflowCompleted())
+	var result *HoldReaperResult
+	s.NoError(s.env.GetWorkflowResult(&result))
+	s.Equal(HoldReaperResult{SeatsReleased: 2, OrdersExpired: 1, SeatsUnblocked: 1}, *result)
+}
+
+func (s *HoldReaperWorkflowTestSuite) TestWorkflow_WaitlistSignalFailureDoesNotFail() {
+	s.env.OnActivity("ReleaseExpiredHolds", mock.Anything, mock.Anything).
+		Return(&activities.ReleaseExpiredHoldsOutput{
+			SeatsReleased: 1,
+			Waitlists:     []activities.WaitlistRef{{FlightID: "flight-1", Class: "economy"}},
+		}, nil).Once()
+	s.env.OnActivity("ReleaseExpiredBlocks", mock.Anything, mock.Anything).
+		Return(&activities.ReleaseExpiredBlocksOutput{}, nil).Once()
+	s.env.OnSignalExternalWorkflow(mock.Anything, WaitlistWorkflowID("flight-1", "economy"), "",
+		WaitlistSeatsReleasedSignal, mock.Anything).Return(errors.New("workflow not found")).Once()
+
+	s.env.ExecuteWorkflow(HoldReaperWorkflow, HoldReaperInput{})
+
+	s.True(s.env.IsWorkflowCompleted())
+	var result *HoldReaperResult
+	s.NoError(s.env.GetWorkflowResult(&result))
+	s.Equal(1, result.SeatsReleased)
+}
+
+func (s *HoldReaperWorkflowTestSuite) TestWorkflow_StopsAfterMaxBatches() {
+	s.env.OnActivity("ReleaseExpiredHolds", mock.Anything, activities.ReleaseExpiredHoldsInput{BatchSize: 5}).
+		Return(&activities.ReleaseExpiredHoldsOutput{SeatsReleased: 5, OrdersExpired: 5}, nil).Times(2)
+	s.env.OnActivity("ReleaseExpiredBlocks", mock.Anything, activities.ReleaseExpiredBlocksInput{BatchSize: 5}).
+		Return(&activities.ReleaseExpiredBlocksOutput{SeatsReleased: 5}, nil).Times(2)
+
+	s.env.ExecuteWorkflow(HoldReaperWorkflow, HoldReaperInput{BatchSize: 5, MaxBatches: 2})
+
+	s.True(s.env.IsWorkflowCompleted())
+	var result *HoldReaperResult
+	s.NoError(s.env.GetWorkflowResult(&result))
+	s.Equal(10, result.SeatsReleased)
+	s.Equal(10, result.SeatsUnblocked)
+}
+
+func (s *HoldReaperWorkflowTestSuite) TestWorkflow_LiftsExpiredBlocks() {
+	s.env.OnActivity("ReleaseExpiredHolds", mock.Anything, mock.Anything).
+		Return(&activities.ReleaseExpiredHoldsOutput{}, nil).Once()
+	input := activities.ReleaseExpiredBlocksInput{BatchSize: 10}
+	s.env.OnActivity("ReleaseExpiredBlocks", mock.Anything, input).
+		Return(&activities.ReleaseExpiredBlocksOutput{SeatsReleased: 10}, nil).Once()
+	s.env.OnActivity("ReleaseExpiredBlocks", mock.Anything, input).
+		Return(&activities.ReleaseExpiredBlocksOutput{SeatsReleased: 2}, nil).Once()
+
+	s.env.ExecuteWorkflow(HoldReaperWorkflow, HoldReaperInput{BatchSize: 10})
+
+	s.True(s.env.IsWorkflowCompleted())
+	var result *HoldReaperResult
+	s.NoError(s.env.GetWorkflowResult(&result))
+	s.Equal(HoldReaperResult{SeatsUnblocked: 12}, *result)
+}
+
+func (s *HoldReaperWorkflowTestSuite) TestWorkflow_ActivityFails() {
+	s.env.OnActivity("ReleaseExpiredHolds", mock.Anything, mock.Anything).
+		Return(nil, errors.New("database unavailable"))
+
+	s.env.ExecuteWorkflow(HoldReaperWorkflow, HoldReaperInput{})
+
+	s.True(s.env.IsWorkflowCompleted())
+	s.Error(s.env.GetWorkflowError())
+}
@@ -0,0 +1,85 @@
+package workflows
+
+import (
+	"time"
+
+	"github.com/cx-tal-miterani/flight-booking-system/temporal-worker/internal/activities"
+	"go.temporal.io/sdk/temporal"
+	"go.temporal.io/sdk/workflow"
+)
+
+// RefundWorkflowInput is the input for the refund workflow of a cancelled
+// booking
+type RefundWorkflowInput struct {
+	RefundID string  `json:"refundId"`
+	OrderID  string  `json:"orderId"`
+	Amount   float64 `json:"amount"`
+}
+
+// RefundWorkflowResult is the result of the refund workflow
+type RefundWorkflowResult struct {
+	Success          bool    `json:"success"`
+	ProviderRefundID string  `json:"providerRefundId,omitempty"`
+	Amount           float64 `json:"amount"`
+	FailureReason    string  `json:"failureReason,omitempty"`
+}
+
+// RefundWorkflow returns the refund of a cancelled booking to the customer.
+// The seats were released when the booking was cancelled; the workflow only
+// moves the money. The payment provider is retried for a while since a
+// refund is owed either way; if it never accepts the refund, the refund is
+// marked failed for support to pay out.
+func RefundWorkflow(ctx workflow.Context, input RefundWorkflowInput) (*RefundWorkflowResult, error) {
+	logger := workflow.GetLogger(ctx)
+	logger.Info("Refund workflow started", "refundId", input.RefundID, "orderId", input.OrderID, "amount", input.Amount)
+
+	ctx = workflow.WithActivityOptions(ctx, workflow.ActivityOptions{
+		StartToCloseTimeout: 30 * time.Second,
+		RetryPolicy: &temporal.RetryPolicy{
+			InitialInterval:    time.Second,
+			BackoffCoefficient: 2.0,
+			MaximumInterval:    time.Minute,
+			MaximumAttempts:    3,
+		},
+	})
+
+	refundCtx := workflow.WithActivityOptions(ctx, workflow.ActivityOptions{
+		StartToCloseTimeout: 30 * time.Second,
+		RetryPolicy: &temporal.RetryPolicy{
+			InitialInterval:    time.Second,
+			BackoffCoefficient: 2.0,
+			MaximumInterval:    5 * time.Minute,
+			MaximumAttempts:    10,
+		},
+	})
+
+	result := &RefundWorkflowResult{Amount: input.Amount}
+
+	var output activities.IssueRefundOutput
+	err := workflow.ExecuteActivity(refundCtx, "IssueRefund", activities.IssueRefundInput{
+		RefundID: input.RefundID,
+		OrderID:  input.OrderID,
+		Amount:   input.Amount,
+	}).Get(ctx, &output)
+	if err != nil {
+		logger.Error("Refund was not accepted by the payment provider", "refundId", input.RefundID, "error", err)
+		result.FailureReason = "The payment provider did not accept the refund"
+		err := workflow.ExecuteActivity(ctx, "FailOrderRefund", activities.FailOrderRefundInput{
+			RefundID: input.RefundID,
+			Reason:   result.FailureReason,
+		}).Get(ctx, nil)
+		if err != nil {
+			logger.Error("Failed to mark refund failed", "refundId", input.RefundID, "error", err)
+		}
+		return result, nil
+	}
+	if !output.Success {
+		result.FailureReason = output.FailureReason
+		return result, nil
+	}
+
+	result.Success = true
+	result.ProviderRefundID = output.ProviderRefundID
+	logger.Info("Refund workflow completed", "refundId", input.RefundID, "providerRefundId", result.ProviderRefundID)
+	return result, nil
+}
@@ -0,0 +1,85 @@
+package workflows
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/cx-tal-miterani/flight-booking-system/temporal-worker/internal/activities"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+	"go.temporal.io/sdk/activity"
+	"go.temporal.io/sdk/testsuite"
+)
+
+const testRefundID = "3f2b8c1e-9d4a-4e7b-8f6c-2a1d5e9b7c3f"
+
+type RefundWorkflowTestSuite struct {
+	suite.Suite
+	testsuite.WorkflowTestSuite
+	env *testsuite.TestWorkflowEnvironment
+}
+
+func (s *RefundWorkflowTestSuite) SetupTest() {
+	s.env = s.NewTestWorkflowEnvironment()
+
+	acts := &activities.Activities{}
+	s.env.RegisterActivityWithOptions(acts.IssueRefund, activity.RegisterOptions{Name: "IssueRefund"})
+	s.env.RegisterActivityWithOptions(acts.FailOrderRefund, activity.RegisterOptions{Name: "FailOrderRefund"})
+}
+
+func (s *RefundWorkflowTestSuite) AfterTest(suiteName, testName string) {
+	s.env.AssertExpectations(s.T())
+}
+
+func TestRefundWorkflowTestSuite(t *testing.T) {
+	suite.Run(t, new(RefundWorkflowTestSuite))
+}
+
+var testRefundInput = RefundWorkflowInput{RefundID: testRefundID, OrderID: "order-1", Amount: 120.5}
+
+func (s *RefundWorkflowTestSuite) TestWorkflow_RetriesProvider() {
+	issue := activities.IssueRefundInput{RefundID: testRefundID, OrderID: "order-1", Amount: 120.5}
+	s.env.OnActivity("IssueRefund", mock.Anything, issue).
+		Return(nil, errors.New("payment provider unavailable")).Once()
+	s.env.OnActivity("IssueRefund", mock.Anything, issue).
+		Return(&activities.IssueRefundOutput{Success: true, ProviderRefundID: "RFD-3f2b8c1e", Amount: 120.5}, nil).Once()
+
+	s.env.ExecuteWorkflow(RefundWorkflow, testRefundInput)
+
+	s.True(s.env.IsWorkflowCompleted())
+	var result *RefundWorkflowResult
+	s.NoError(s.env.GetWorkflowResult(&result))
+	s.True(result.Success)
+	s.Equal("RFD-3f2b8c1e", result.ProviderRefundID)
+	s.Equal(120.5, result.Amount)
+}
+
+func (s *RefundWorkflowTestSuite) TestWorkflow_ProviderNeverAccepts() {
+	s.env.OnActivity("IssueRefund", mock.Anything, mock.Anything).
+		Return(nil, errors.New("payment provider unavailable"))
+	s.env.OnActivity("FailOrderRefund", mock.Anything, activities.FailOrderRefundInput{
+		RefundID: testRefundID,
+		Reason:   "The payment provider did not accept the refund",
+	}).Return(nil).Once()
+
+	s.env.ExecuteWorkflow(RefundWorkflow, testRefundInput)
+
+	s.True(s.env.IsWorkflowCompleted())
+	var result *RefundWorkflowResult
+	s.NoError(s.env.GetWorkflowResult(&result))
+	s.False(result.Success)
+	s.Equal("The payment provider did not accept the refund", result.FailureReason)
+}
+
+func (s *RefundWorkflowTestSuite) TestWorkflow_RefundNoLongerPending() {
+	s.env.OnActivity("IssueRefund", mock.Anything, mock.Anything).
+		Return(&activities.IssueRefundOutput{FailureReason: "order refund is no longer pending"}, nil).Once()
+
+	s.env.ExecuteWorkflow(RefundWorkflow, testRefundInput)
+
+	s.True(s.env.IsWorkflowCompleted())
+	var result *RefundWorkflowResult
+	s.NoError(s.env.GetWorkflowResult(&result))
+	s.False(result.Success)
+	s.Equal("order refund is no longer pending", result.FailureReason)
+}
@@ -0,0 +1,61 @@
+package workflows
+
+import (
+	"time"
+
+	"github.com/cx-tal-miterani/flight-booking-system/temporal-worker/internal/activities"
+	"go.temporal.io/sdk/temporal"
+	"go.temporal.io/sdk/workflow"
+)
+
+// DefaultScheduleHorizonDays is how many days ahead scheduled flights are
+// created when the workflow input does not say
+const DefaultScheduleHorizonDays = 30
+
+// ScheduleMaterializationInput is the input for the schedule materialization workflow
+type ScheduleMaterializationInput struct {
+	// ScheduleID limits the run to one schedule, e.g. one just created;
+	// empty means all schedules
+	ScheduleID  string `json:"scheduleId,omitempty"`
+	HorizonDays int    `json:"horizonDays,omitempty"`
+}
+
+// ScheduleMaterializationResult is the result of the schedule materialization workflow
+type ScheduleMaterializationResult struct {
+	FlightsCreated int `json:"flightsCreated"`
+}
+
+// ScheduleMaterializationWorkflow creates the flights of flight schedules a
+// rolling number of days ahead. The worker runs it on a cron schedule for all
+// schedules; the API runs it once for a schedule when it is created.
+func ScheduleMaterializationWorkflow(ctx workflow.Context, input ScheduleMaterializationInput) (*ScheduleMaterializationResult, error) {
+	logger := workflow.GetLogger(ctx)
+
+	horizon := input.HorizonDays
+	if horizon <= 0 {
+		horizon = DefaultScheduleHorizonDays
+	}
+	logger.Info("Schedule materialization workflow started", "scheduleId", input.ScheduleID, "horizonDays", horizon)
+
+	ctx = workflow.WithActivityOptions(ctx, workflow.ActivityOptions{
+		StartToCloseTimeout: 2 * time.Minute,
+		RetryPolicy: &temporal.RetryPolicy{
+			InitialInterval:    time.Second,
+			BackoffCoefficient: 2.0,
+			MaximumInterval:    time.Minute,
+			MaximumAttempts:    5,
+		},
+	})
+
+	var output activities.MaterializeScheduledFlightsOutput
+	err := workflow.ExecuteActivity(ctx, "MaterializeScheduledFlights", activities.MaterializeScheduledFlightsInput{
+		ScheduleID:  input.ScheduleID,
+		HorizonDays: horizon,
+	}).Get(ctx, &output)
+	if err != nil {
+		return nil, err
+	}
+
+	logger.Info("Schedule materialization workflow completed", "flightsCreated", output.FlightsCreated)
+	return &ScheduleMaterializationResult{FlightsCreated: output.FlightsCreated}, nil
+}
@@ -0,0 +1,106 @@
+package workflows
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/cx-tal-miterani/flight-booking-system/temporal-worker/internal/activities"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+	"go.temporal.io/sdk/activity"
+	"go.temporal.io/sdk/testsuite"
+)
+
+type ScheduleWorkflowTestSuite struct {
+	suite.Suite
+	testsuite.WorkflowTestSuite
+	env *testsuite.TestWorkflowEnvironment
+}
+
+func (s *ScheduleWorkflowTestSuite) SetupTest() {
+	s.env = s.NewTestWorkflowEnvironment()
+
+	acts := &activities.Activities{}
+	s.env.RegisterActivityWithOptions(acts.MaterializeScheduledFlights, activity.RegisterOptions{Name: "MaterializeScheduledFlights"})
+}
+
+func (s *ScheduleWorkflowTestSuite) AfterTest(suiteName, testName string) {
+	s.env.AssertExpectations(s.T())
+}
+
+func TestScheduleWorkflowTestSuite(t *testing.T) {
+	suite.Run(t, new(ScheduleWorkflowTestSuite))
+}
+
+func (s *ScheduleWorkflowTestSuite) TestWorkflow_AllSchedulesDefaultHorizon() {
+	s.env.OnActivity("MaterializeScheduledFlights", mock.Anything, activities.MaterializeScheduledFlightsInput{
+		HorizonDays: DefaultScheduleHorizonDays,
+	}).Return(&activities.MaterializeScheduledFlightsOutput{FlightsCreated: 12}, nil).Once()
+
+	s.env.ExecuteWorkflow(ScheduleMaterializationWorkflow, ScheduleMaterializationInput{})
+
+	s.True(s.env.IsWorkflowCompleted())
+	s.NoError(s.env.GetWorkflowError())
+	var result *ScheduleMaterializationResult
+	s.NoError(s.env.GetWorkflowResult(&result))
+	s.Equal(12, result.FlightsCreated)
+}
+
+func (s *ScheduleWorkflowTestSuite) TestWorkflow_SingleSchedule() {
+	s.env.OnActivity("MaterializeScheduledFlights", mock.Anything, activities.MaterializeScheduledFlightsInput{
+		ScheduleID:  "schedule-1",
+		HorizonDays: 7,
+	}).Return(&activities.MaterializeScheduledFlightsOutput{}, nil).Once()
+
+	s.env.ExecuteWorkflow(ScheduleMaterializationWorkflow, ScheduleMaterializationInput{
+		ScheduleID:  "schedule-1",
+		HorizonDays: 7,
+	})
+
+	s.True(s.env.IsWorkflowCompleted())
+	var result *ScheduleMaterializationResult
+	s.NoError(s.env.GetWorkflowResult(&result))
+	s.Equal(0, result.FlightsCreated)
+}
+
+func (s *ScheduleWorkflowTestSuite) TestWorkflow_NegativeHorizonUsesDefault() {
+	s.env.OnActivity("MaterializeScheduledFlights", mock.Anything, activities.MaterializeScheduledFlightsInput{
+		ScheduleID:  "schedule-1",
+		HorizonDays: DefaultScheduleHorizonDays,
+	}).Return(&activities.MaterializeScheduledFlightsOutput{FlightsCreated: 30}, nil).Once()
+
+	s.env.ExecuteWorkflow(ScheduleMaterializationWorkflow, ScheduleMaterializationInput{
+		ScheduleID:  "schedule-1",
+		HorizonDays: -1,
+	})
+
+	s.True(s.env.IsWorkflowCompleted())
+	var result *ScheduleMaterializationResult
+	s.NoError(s.env.GetWorkflowResult(&result))
+	s.Equal(30, result.FlightsCreated)
+}
+
+func (s *ScheduleWorkflowTestSuite) TestWorkflow_RetriesFailedActivity() {
+	input := activities.MaterializeScheduledFlightsInput{HorizonDays: DefaultScheduleHorizonDays}
+	s.env.OnActivity("MaterializeScheduledFlights", mock.Anything, input).
+		Return(nil, errors.New("database unavailable")).Twice()
+	s.env.OnActivity("MaterializeScheduledFlights", mock.Anything, input).
+		Return(&activities.MaterializeScheduledFlightsOutput{FlightsCreated: 4}, nil).Once()
+
+	s.env.ExecuteWorkflow(ScheduleMaterializationWorkflow, ScheduleMaterializationInput{})
+
+	s.True(s.env.IsWorkflowCompleted())
+	var result *ScheduleMaterializationResult
+	s.NoError(s.env.GetWorkflowResult(&result))
+	s.Equal(4, result.FlightsCreated)
+}
+
+func (s *ScheduleWorkflowTestSuite) TestWorkflow_ActivityFails() {
+	s.env.OnActivity("MaterializeScheduledFlights", mock.Anything, mock.Anything).
+		Return(nil, errors.New("database unavailable"))
+
+	s.env.ExecuteWorkflow(ScheduleMaterializationWorkflow, ScheduleMaterializationInput{})
+
+	s.True(s.env.IsWorkflowCompleted())
+	s.Error(s.env.GetWorkflowError())
+}
@@ -0,0 +1,136 @@
+package workflows
+
+import (
+	"time"
+
+	"github.com/cx-tal-miterani/flight-booking-system/temporal-worker/internal/activities"
+	"go.temporal.io/sdk/temporal"
+	"go.temporal.io/sdk/workflow"
+)
+
+// SeatExchangeWorkflowInput is the input for the seat exchange workflow. A
+// positive PriceDifference is charged to PaymentCode, a negative one is
+// credited back.
+type SeatExchangeWorkflowInput struct {
+	ExchangeID      string  `json:"exchangeId"`
+	OrderID         string  `json:"orderId"`
+	PriceDifference float64 `json:"priceDifference"`
+	PaymentCode     string  `json:"paymentCode,omitempty"`
+}
+
+// SeatExchangeWorkflowResult is the result of the seat exchange workflow
+type SeatExchangeWorkflowResult struct {
+	Success       bool   `json:"success"`
+	TransactionID string `json:"transactionId,omitempty"`
+	CreditID      string `json:"creditId,omitempty"`
+	FailureReason string `json:"failureReason,omitempty"`
+}
+
+// SeatExchangeWorkflow settles the fare difference of a seat change of a
+// confirmed order, whose new seats the API server holds for it, and swaps the
+// seats. A higher fare is charged before the seats are swapped and credited
+// back if the swap fails; a lower fare is credited once they are. If the
+// payment fails the new seats are released and the order keeps its seats.
+func SeatExchangeWorkflow(ctx workflow.Context, input SeatExchangeWorkflowInput) (*SeatExchangeWorkflowResult, error) {
+	logger := workflow.GetLogger(ctx)
+	logger.Info("Seat exchange workflow started", "exchangeId", input.ExchangeID, "orderId", input.OrderID, "priceDifference", input.PriceDifference)
+
+	ctx = workflow.WithActivityOptions(ctx, workflow.ActivityOptions{
+		StartToCloseTimeout: 30 * time.Second,
+		RetryPolicy: &temporal.RetryPolicy{
+			InitialInterval:    time.Second,
+			BackoffCoefficient: 2.0,
+			MaximumInterval:    time.Minute,
+			MaximumAttempts:    3,
+		},
+	})
+
+	// Payment activity with shorter timeout (10 seconds)
+	paymentCtx := workflow.WithActivityOptions(ctx, workflow.ActivityOptions{
+		StartToCloseTimeout: PaymentTimeout,
+		RetryPolicy: &temporal.RetryPolicy{
+			MaximumAttempts: 1, // No automatic retries for payment
+		},
+	})
+
+	result := &SeatExchangeWorkflowResult{}
+
+	fail := func(reason string) {
+		result.FailureReason = reason
+		var output activities.FailSeatExchangeOutput
+		err := workflow.ExecuteActivity(ctx, "FailSeatExchange", activities.FailSeatExchangeInput{
+			ExchangeID: input.ExchangeID,
+			Reason:     reason,
+		}).Get(ctx, &output)
+		if err != nil {
+			logger.Error("Failed to release seats of seat exchange", "exchangeId", input.ExchangeID, "error", err)
+			return
+		}
+		notifyWaitlists(ctx, output.Waitlists)
+	}
+
+	credit := func(amount float64) error {
+		var output activities.CreditSeatExchangeOutput
+		err := workflow.ExecuteActivity(ctx, "CreditSeatExchange", activities.CreditSeatExchangeInput{
+			ExchangeID: input.ExchangeID,
+			OrderID:    input.OrderID,
+			Amount:     amount,
+		}).Get(ctx, &output)
+		if err != nil {
+			return err
+		}
+		result.CreditID = output.CreditID
+		return nil
+	}
+
+	if input.PriceDifference > 0 {
+		var payment activities.ValidatePaymentOutput
+		err := workflow.ExecuteActivity(paymentCtx, "ChargeSeatExchange", activities.ChargeSeatExchangeInput{
+			ExchangeID:  input.ExchangeID,
+			OrderID:     input.OrderID,
+			PaymentCode: input.PaymentCode,
+			Amount:      input.PriceDifference,
+		}).Get(ctx, &payment)
+		if err != nil {
+			logger.Error("Seat exchange payment activity failed", "error", err)
+			fail("Payment processing error")
+			return result, nil
+		}
+		if !payment.Success {
+			fail(payment.ErrorMessage)
+			return result, nil
+		}
+		result.TransactionID = payment.TransactionID
+	}
+
+	var completed activities.CompleteSeatExchangeOutput
+	err := workflow.ExecuteActivity(ctx, "CompleteSeatExchange", activities.CompleteSeatExchangeInput{
+		ExchangeID:    input.ExchangeID,
+		TransactionID: result.TransactionID,
+	}).Get(ctx, &completed)
+	if err != nil {
+		logger.Error("Failed to complete seat exchange", "exchangeId", input.ExchangeID, "error", err)
+		completed.FailureReason = "Seat change could not be completed"
+	}
+	if !completed.Success {
+		fail(completed.FailureReason)
+		if result.TransactionID != "" {
+			// Give the charged difference back
+			if err := credit(input.PriceDifference); err != nil {
+				logger.Error("Failed to credit seat exchange charge", "exchangeId", input.ExchangeID, "error", err)
+			}
+		}
+		return result, nil
+	}
+	result.Success = true
+	notifyWaitlists(ctx, completed.Waitlists)
+
+	if input.PriceDifference < 0 {
+		if err := credit(-input.PriceDifference); err != nil {
+			logger.Error("Failed to credit seat exchange difference", "exchangeId", input.ExchangeID, "error", err)
+		}
+	}
+
+	logger.Info("Seat exchange workflow completed", "exchangeId", input.ExchangeID, "success", result.Success)
+	return result, nil
+}
@@ -0,0 +1,139 @@
+package workflows
+
+import (
+	"testing"
+
+	"github.com/cx-tal-miterani/flight-booking-system/temporal-worker/internal/activities"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+	"go.temporal.io/sdk/activity"
+	"go.temporal.io/sdk/testsuite"
+)
+
+const testExchangeID = "7c9e6679-7425-40de-944b-e07fc1f90ae7"
+
+type SeatExchangeWorkflowTestSuite struct {
+	suite.Suite
+	testsuite.WorkflowTestSuite
+	env *testsuite.TestWorkflowEnvironment
+}
+
+func (s *SeatExchangeWorkflowTestSuite) SetupTest() {
+	s.env = s.NewTestWorkflowEnvironment()
+
+	acts := &activities.Activities{}
+	s.env.RegisterActivityWithOptions(acts.ChargeSeatExchange, activity.RegisterOptions{Name: "ChargeSeatExchange"})
+	s.env.RegisterActivityWithOptions(acts.CompleteSeatExchange, activity.RegisterOptions{Name: "CompleteSeatExchange"})
+	s.env.RegisterActivityWithOptions(acts.FailSeatExchange, activity.RegisterOptions{Name: "FailSeatExchange"})
+	s.env.RegisterActivityWithOptions(acts.CreditSeatExchange, activity.RegisterOptions{Name: "CreditSeatExchange"})
+}
+
+func (s *SeatExchangeWorkflowTestSuite) AfterTest(suiteName, testName string) {
+	s.env.AssertExpectations(s.T())
+}
+
+func TestSeatExchangeWorkflowTestSuite(t *testing.T) {
+	suite.Run(t, new(SeatExchangeWorkflowTestSuite))
+}
+
+func (s *SeatExchangeWorkflowTestSuite) TestWorkflow_ChargesThenSwapsSeats() {
+	s.env.OnActivity("ChargeSeatExchange", mock.Anything, activities.ChargeSeatExchangeInput{
+		ExchangeID:  testExchangeID,
+		OrderID:     "order-1",
+		PaymentCode: "12345",
+		Amount:      40,
+	}).Return(&activities.ValidatePaymentOutput{Success: true, TransactionID: "TXN-1"}, nil).Once()
+	s.env.OnActivity("CompleteSeatExchange", mock.Anything, activities.CompleteSeatExchangeInput{
+		ExchangeID:    testExchangeID,
+		TransactionID: "TXN-1",
+	}).Return(&activities.CompleteSeatExchangeOutput{Success: true}, nil).Once()
+
+	s.env.ExecuteWorkflow(SeatExchangeWorkflow, SeatExchangeWorkflowInput{
+		ExchangeID:      testExchangeID,
+		OrderID:         "order-1",
+		PriceDifference: 40,
+		PaymentCode:     "12345",
+	})
+
+	s.True(s.env.IsWorkflowCompleted())
+	var result *SeatExchangeWorkflowResult
+	s.NoError(s.env.GetWorkflowResult(&result))
+	s.True(result.Success)
+	s.Equal("TXN-1", result.TransactionID)
+	s.Empty(result.CreditID)
+}
+
+func (s *SeatExchangeWorkflowTestSuite) TestWorkflow_PaymentFailedKeepsSeats() {
+	s.env.OnActivity("ChargeSeatExchange", mock.Anything, mock.Anything).
+		Return(&activities.ValidatePaymentOutput{Success: false, ErrorMessage: "Payment validation failed. Please try again."}, nil).Once()
+	s.env.OnActivity("FailSeatExchange", mock.Anything, activities.FailSeatExchangeInput{
+		ExchangeID: testExchangeID,
+		Reason:     "Payment validation failed. Please try again.",
+	}).Return(&activities.FailSeatExchangeOutput{}, nil).Once()
+
+	s.env.ExecuteWorkflow(SeatExchangeWorkflow, SeatExchangeWorkflowInput{
+		ExchangeID:      testExchangeID,
+		OrderID:         "order-1",
+		PriceDifference: 40,
+		PaymentCode:     "12345",
+	})
+
+	s.True(s.env.IsWorkflowCompleted())
+	var result *SeatExchangeWorkflowResult
+	s.NoError(s.env.GetWorkflowResult(&result))
+	s.False(result.Success)
+	s.Equal("Payment validation failed. Please try again.", result.FailureReason)
+}
+
+func (s *SeatExchangeWorkflowTestSuite) TestWorkflow_CreditsCheaperSeats() {
+	s.env.OnActivity("CompleteSeatExchange", mock.Anything, activities.CompleteSeatExchangeInput{
+		ExchangeID: testExchangeID,
+	}).Return(&activities.CompleteSeatExchangeOutput{Success: true}, nil).Once()
+	s.env.OnActivity("CreditSeatExchange", mock.Anything, activities.CreditSeatExchangeInput{
+		ExchangeID: testExchangeID,
+		OrderID:    "order-1",
+		Amount:     25,
+	}).Return(&activities.CreditSeatExchangeOutput{CreditID: "CRD-7c9e6679", Amount: 25}, nil).Once()
+
+	s.env.ExecuteWorkflow(SeatExchangeWorkflow, SeatExchangeWorkflowInput{
+		ExchangeID:      testExchangeID,
+		OrderID:         "order-1",
+		PriceDifference: -25,
+	})
+
+	s.True(s.env.IsWorkflowCompleted())
+	var result *SeatExchangeWorkflowResult
+	s.NoError(s.env.GetWorkflowResult(&result))
+	s.True(result.Success)
+	s.Equal("CRD-7c9e6679", result.CreditID)
+}
+
+func (s *SeatExchangeWorkflowTestSuite) TestWorkflow_SwapFailedCreditsCharge() {
+	s.env.OnActivity("ChargeSeatExchange", mock.Anything, mock.Anything).
+		Return(&activities.ValidatePaymentOutput{Success: true, TransactionID: "TXN-1"}, nil).Once()
+	s.env.OnActivity("CompleteSeatExchange", mock.Anything, mock.Anything).
+		Return(&activities.CompleteSeatExchangeOutput{FailureReason: "seats no longer held for order"}, nil).Once()
+	s.env.OnActivity("FailSeatExchange", mock.Anything, activities.FailSeatExchangeInput{
+		ExchangeID: testExchangeID,
+		Reason:     "seats no longer held for order",
+	}).Return(&activities.FailSeatExchangeOutput{}, nil).Once()
+	s.env.OnActivity("CreditSeatExchange", mock.Anything, activities.CreditSeatExchangeInput{
+		ExchangeID: testExchangeID,
+		OrderID:    "order-1",
+		Amount:     40,
+	}).Return(&activities.CreditSeatExchangeOutput{CreditID: "CRD-7c9e6679", Amount: 40}, nil).Once()
+
+	s.env.ExecuteWorkflow(SeatExchangeWorkflow, SeatExchangeWorkflowInput{
+		ExchangeID:      testExchangeID,
+		OrderID:         "order-1",
+		PriceDifference: 40,
+		PaymentCode:     "12345",
+	})
+
+	s.True(s.env.IsWorkflowCompleted())
+	var result *SeatExchangeWorkflowResult
+	s.NoError(s.env.GetWorkflowResult(&result))
+	s.False(result.Success)
+	s.Equal("TXN-1", result.TransactionID)
+	s.Equal("CRD-7c9e6679", result.CreditID)
+}
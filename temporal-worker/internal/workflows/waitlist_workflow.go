@@ -0,0 +1,206 @@
+package workflows
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/cx-tal-miterani/flight-booking-system/temporal-worker/internal/activities"
+	enumspb "go.temporal.io/api/enums/v1"
+	"go.temporal.io/sdk/temporal"
+	"go.temporal.io/sdk/workflow"
+)
+
+const (
+	// WaitlistOfferHold is how long seats offered to a waitlisted customer
+	// are held for them to pay (10 minutes)
+	WaitlistOfferHold = 10 * time.Minute
+	// WaitlistRecheckInterval is how often a waitlist looks for available
+	// seats when it is not told about released ones
+	WaitlistRecheckInterval = time.Hour
+	// WaitlistSeatsReleasedSignal tells a waitlist workflow seats of its
+	// class may have become available
+	WaitlistSeatsReleasedSignal = "seats-released"
+	// waitlistMaxIterations bounds the history of a run; the workflow then
+	// continues as new once no offer is outstanding
+	waitlistMaxIterations = 500
+)
+
+// WaitlistWorkflowID returns the ID of the workflow serving the waitlist of a
+// class on a flight
+func WaitlistWorkflowID(flightID, class string) string {
+	return fmt.Sprintf("waitlist-%s-%s", flightID, class)
+}
+
+// WaitlistWorkflowInput is the input for the waitlist workflow
+type WaitlistWorkflowInput struct {
+	FlightID string `json:"flightId"`
+	Class    string `json:"class"`
+}
+
+// WaitlistWorkflowResult is the result of the waitlist workflow
+type WaitlistWorkflowResult struct {
+	Offers   int `json:"offers"`
+	Accepted int `json:"accepted"`
+	Expired  int `json:"expired"`
+}
+
+// WaitlistWorkflow serves the waitlist of a class on a flight. Whenever seats
+// may have become available it offers them to the customers waiting longest:
+// each offer is an order holding the seats for WaitlistOfferHold, run by a
+// child booking workflow the customer pays through as usual. An offer that
+// is not paid for in time expires like any other order, and its released
+// seats go to the next customer. The workflow ends once nobody is waiting
+// and every offer has been answered, or when the flight is no longer sold.
+func WaitlistWorkflow(ctx workflow.Context, input WaitlistWorkflowInput) (*WaitlistWorkflowResult, error) {
+	logger := workflow.GetLogger(ctx)
+	logger.Info("Waitlist workflow started", "flightId", input.FlightID, "class", input.Class)
+
+	ctx = workflow.WithActivityOptions(ctx, workflow.ActivityOptions{
+		StartToCloseTimeout: 30 * time.Second,
+		RetryPolicy: &temporal.RetryPolicy{
+			InitialInterval:    time.Second,
+			BackoffCoefficient: 2.0,
+			MaximumInterval:    time.Minute,
+			MaximumAttempts:    3,
+		},
+	})
+
+	releasedCh := workflow.GetSignalChannel(ctx, WaitlistSeatsReleasedSignal)
+	result := &WaitlistWorkflowResult{}
+
+	// pending holds the offers whose booking workflow has not finished
+	type pendingOffer struct {
+		entryID string
+		booking workflow.ChildWorkflowFuture
+	}
+	var pending []pendingOffer
+	var closed bool
+
+	// offerSeats makes offers until there are no seats for the first
+	// customer, and returns how many customers are still waiting, or -1 if
+	// that is not known
+	offerSeats := func() int {
+		for {
+			var offer activities.OfferWaitlistSeatsOutput
+			err := workflow.ExecuteActivity(ctx, "OfferWaitlistSeats", activities.OfferWaitlistSeatsInput{
+				FlightID:  input.FlightID,
+				Class:     input.Class,
+				HoldUntil: workflow.Now(ctx).Add(WaitlistOfferHold),
+			}).Get(ctx, &offer)
+			if err != nil {
+				logger.Error("Failed to offer waitlist seats", "error", err)
+				return -1
+			}
+			if offer.Closed {
+				closed = true
+				return 0
+			}
+			if offer.EntryID == "" {
+				return offer.Waiting
+			}
+			result.Offers++
+
+			err = workflow.ExecuteActivity(ctx, "NotifyWaitlistOffer", activities.NotifyWaitlistOfferInput{
+				EntryID:       offer.EntryID,
+				OrderID:       offer.OrderID,
+				CustomerEmail: offer.CustomerEmail,
+				CustomerName:  offer.CustomerName,
+				FlightNumber:  offer.FlightNumber,
+				SeatCount:     len(offer.SeatIDs),
+				ExpiresAt:     offer.ExpiresAt,
+			}).Get(ctx, nil)
+			if err != nil {
+				logger.Error("Failed to notify waitlist offer", "entryId", offer.EntryID, "error", err)
+			}
+
+			// The booking outlives this workflow if it continues as new
+			childCtx := workflow.WithChildOptions(ctx, workflow.ChildWorkflowOptions{
+				WorkflowID:        offer.WorkflowID,
+				ParentClosePolicy: enumspb.PARENT_CLOSE_POLICY_ABANDON,
+			})
+			booking := workflow.ExecuteChildWorkflow(childCtx, BookingWorkflow, BookingWorkflowInput{
+				OrderID:              offer.OrderID,
+				FlightID:             input.FlightID,
+				FlightIDs:            []string{input.FlightID},
+				CustomerName:         offer.CustomerName,
+				CustomerEmail:        offer.CustomerEmail,
+				SeatIDs:              offer.SeatIDs,
+				ReservationExpiresAt: offer.ExpiresAt,
+			})
+			pending = append(pending, pendingOffer{entryID: offer.EntryID, booking: booking})
+		}
+	}
+
+	completeOffer := func(p pendingOffer) {
+		var booking BookingWorkflowResult
+		err := p.booking.Get(ctx, &booking)
+		accepted := err == nil && booking.Success
+		if accepted {
+			result.Accepted++
+		} else {
+			result.Expired++
+		}
+		err = workflow.ExecuteActivity(ctx, "CompleteWaitlistOffer", activities.CompleteWaitlistOfferInput{
+			EntryID:  p.entryID,
+			Accepted: accepted,
+		}).Get(ctx, nil)
+		if err != nil {
+			logger.Error("Failed to complete waitlist offer", "entryId", p.entryID, "error", err)
+		}
+	}
+
+	for iteration := 0; ; iteration++ {
+		waiting := -1
+		if !closed {
+			waiting = offerSeats()
+		}
+
+		if (closed || waiting == 0) && len(pending) == 0 {
+			// A customer may have joined since the last offer
+			if !closed && releasedCh.ReceiveAsync(nil) {
+				continue
+			}
+			break
+		}
+		if iteration >= waitlistMaxIterations && len(pending) == 0 {
+			return nil, workflow.NewContinueAsNewError(ctx, WaitlistWorkflow, input)
+		}
+
+		selector := workflow.NewSelector(ctx)
+		selector.AddReceive(releasedCh, func(c workflow.ReceiveChannel, more bool) {
+			c.Receive(ctx, nil)
+			// Several releases are served by a single round of offers
+			for c.ReceiveAsync(nil) {
+			}
+		})
+
+		timerCtx, cancelTimer := workflow.WithCancel(ctx)
+		selector.AddFuture(workflow.NewTimer(timerCtx, WaitlistRecheckInterval), func(f workflow.Future) {})
+
+		for i, p := range pending {
+			i, p := i, p
+			selector.AddFuture(p.booking, func(f workflow.Future) {
+				completeOffer(p)
+				pending = append(pending[:i], pending[i+1:]...)
+			})
+		}
+
+		selector.Select(ctx)
+		cancelTimer()
+	}
+
+	logger.Info("Waitlist workflow completed", "offers", result.Offers, "accepted", result.Accepted, "expired", result.Expired)
+	return result, nil
+}
+
+// notifyWaitlists wakes up the workflows of waitlists that released seats
+// may be offered to. A waitlist whose workflow is not running has nobody
+// left to serve, so failures are only logged.
+func notifyWaitlists(ctx workflow.Context, waitlists []activities.WaitlistRef) {
+	for _, w := range waitlists {
+		err := workflow.SignalExternalWorkflow(ctx, WaitlistWorkflowID(w.FlightID, w.Class), "", WaitlistSeatsReleasedSignal, nil).Get(ctx, nil)
+		if err != nil {
+			workflow.GetLogger(ctx).Warn("Failed to signal waitlist workflow", "flightId", w.FlightID, "class", w.Class, "error", err)
+		}
+	}
+}
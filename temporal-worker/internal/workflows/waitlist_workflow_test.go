@@ -0,0 +1,219 @@
+package workflows
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/cx-tal-miterani/flight-booking-system/temporal-worker/internal/activities"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+	"go.temporal.io/sdk/activity"
+	"go.temporal.io/sdk/testsuite"
+)
+
+type WaitlistWorkflowTestSuite struct {
+	suite.Suite
+	testsuite.WorkflowTestSuite
+	env *testsuite.TestWorkflowEnvironment
+}
+
+func (s *WaitlistWorkflowTestSuite) SetupTest() {
+	s.env = s.NewTestWorkflowEnvironment()
+	s.env.RegisterWorkflow(BookingWorkflow)
+
+	acts := &activities.Activities{}
+	s.env.RegisterActivityWithOptions(acts.OfferWaitlistSeats, activity.RegisterOptions{Name: "OfferWaitlistSeats"})
+	s.env.RegisterActivityWithOptions(acts.NotifyWaitlistOffer, activity.RegisterOptions{Name: "NotifyWaitlistOffer"})
+	s.env.RegisterActivityWithOptions(acts.CompleteWaitlistOffer, activity.RegisterOptions{Name: "CompleteWaitlistOffer"})
+}
+
+func (s *WaitlistWorkflowTestSuite) AfterTest(suiteName, testName string) {
+	s.env.AssertExpectations(s.T())
+}
+
+func TestWaitlistWorkflowTestSuite(t *testing.T) {
+	suite.Run(t, new(WaitlistWorkflowTestSuite))
+}
+
+var waitlistInput = WaitlistWorkflowInput{FlightID: "test-flight-456", Class: "economy"}
+
+func waitlistOffer() *activities.OfferWaitlistSeatsOutput {
+	return &activities.OfferWaitlistSeatsOutput{
+		EntryID:       "entry-1",
+		OrderID:       "order-1",
+		WorkflowID:    "booking-order-1",
+		CustomerName:  "John Doe",
+		CustomerEmail: "john@example.com",
+		FlightNumber:  "AA123",
+		SeatIDs:       []string{"seat-1", "seat-2"},
+		ExpiresAt:     time.Now().Add(WaitlistOfferHold),
+	}
+}
+
+func (s *WaitlistWorkflowTestSuite) TestWorkflow_NobodyWaiting() {
+	s.env.OnActivity("OfferWaitlistSeats", mock.Anything, mock.Anything).
+		Return(&activities.OfferWaitlistSeatsOutput{}, nil).Once()
+
+	s.env.ExecuteWorkflow(WaitlistWorkflow, waitlistInput)
+
+	s.True(s.env.IsWorkflowCompleted())
+	var result *WaitlistWorkflowResult
+	s.NoError(s.env.GetWorkflowResult(&result))
+	s.Equal(0, result.Offers)
+}
+
+func (s *WaitlistWorkflowTestSuite) TestWorkflow_FlightNoLongerSold() {
+	s.env.OnActivity("OfferWaitlistSeats", mock.Anything, mock.Anything).
+		Return(&activities.OfferWaitlistSeatsOutput{Closed: true}, nil).Once()
+
+	s.env.ExecuteWorkflow(WaitlistWorkflow, waitlistInput)
+
+	s.True(s.env.IsWorkflowCompleted())
+	s.NoError(s.env.GetWorkflowError())
+}
+
+func (s *WaitlistWorkflowTestSuite) TestWorkflow_OffersSeatsOfItsClass() {
+	first := waitlistOffer()
+	second := waitlistOffer()
+	second.EntryID = "entry-2"
+	second.OrderID = "order-2"
+	second.WorkflowID = "booking-order-2"
+
+	// Seats are held for WaitlistOfferHold, and offers are made until
+	// nothing is left for the next customer
+	offerInput := mock.MatchedBy(func(input activities.OfferWaitlistSeatsInput) bool {
+		return input.FlightID == "test-flight-456" && input.Class == "economy" &&
+			input.HoldUntil.Equal(s.env.Now().Add(WaitlistOfferHold))
+	})
+	s.env.OnActivity("OfferWaitlistSeats", mock.Anything, offerInput).Return(first, nil).Once()
+	s.env.OnActivity("OfferWaitlistSeats", mock.Anything, offerInput).Return(second, nil).Once()
+	s.env.OnActivity("OfferWaitlistSeats", mock.Anything, offerInput).
+		Return(&activities.OfferWaitlistSeatsOutput{}, nil)
+	s.env.OnActivity("NotifyWaitlistOffer", mock.Anything, mock.MatchedBy(func(input activities.NotifyWaitlistOfferInput) bool {
+		return input.EntryID == "entry-1" && input.OrderID == "order-1" &&
+			input.CustomerEmail == "john@example.com" && input.FlightNumber == "AA123" &&
+			input.SeatCount == 2 && input.ExpiresAt.Equal(first.ExpiresAt)
+	})).Return(nil).Once()
+	s.env.OnActivity("NotifyWaitlistOffer", mock.Anything, mock.MatchedBy(func(input activities.NotifyWaitlistOfferInput) bool {
+		return input.EntryID == "entry-2"
+	})).Return(nil).Once()
+	s.env.OnWorkflow(BookingWorkflow, mock.Anything, mock.MatchedBy(func(input BookingWorkflowInput) bool {
+		return input.FlightID == "test-flight-456" && len(input.FlightIDs) == 1 && input.FlightIDs[0] == "test-flight-456"
+	})).Return(&BookingWorkflowResult{Success: true}, nil).Twice()
+	s.env.OnActivity("CompleteWaitlistOffer", mock.Anything, mock.Anything).Return(nil).Twice()
+
+	s.env.ExecuteWorkflow(WaitlistWorkflow, waitlistInput)
+
+	s.True(s.env.IsWorkflowCompleted())
+	var result *WaitlistWorkflowResult
+	s.NoError(s.env.GetWorkflowResult(&result))
+	s.Equal(WaitlistWorkflowResult{Offers: 2, Accepted: 2}, *result)
+}
+
+func (s *WaitlistWorkflowTestSuite) TestWorkflow_OfferFailsAndIsRetriedLater() {
+	// The activity's retries are exhausted; the waitlist tries again at the
+	// next recheck
+	s.env.OnActivity("OfferWaitlistSeats", mock.Anything, mock.Anything).
+		Return(nil, errors.New("database unavailable")).Times(3)
+	s.env.OnActivity("OfferWaitlistSeats", mock.Anything, mock.Anything).
+		Return(&activities.OfferWaitlistSeatsOutput{}, nil).Once()
+
+	start := s.env.Now()
+	s.env.ExecuteWorkflow(WaitlistWorkflow, waitlistInput)
+
+	s.True(s.env.IsWorkflowCompleted())
+	var result *WaitlistWorkflowResult
+	s.NoError(s.env.GetWorkflowResult(&result))
+	s.Equal(0, result.Offers)
+	s.GreaterOrEqual(s.env.Now().Sub(start), WaitlistRecheckInterval)
+}
+
+func (s *WaitlistWorkflowTestSuite) TestWorkflow_OfferAccepted() {
+	s.env.OnActivity("OfferWaitlistSeats", mock.Anything, mock.Anything).Return(waitlistOffer(), nil).Once()
+	s.env.OnActivity("OfferWaitlistSeats", mock.Anything, mock.Anything).
+		Return(&activities.OfferWaitlistSeatsOutput{}, nil)
+	s.env.OnActivity("NotifyWaitlistOffer", mock.Anything, mock.Anything).Return(nil).Once()
+	s.env.OnWorkflow(BookingWorkflow, mock.Anything, mock.MatchedBy(func(input BookingWorkflowInput) bool {
+		return input.OrderID == "order-1" && len(input.SeatIDs) == 2
+	})).Return(&BookingWorkflowResult{Success: true, TransactionID: "TXN-12345"}, nil).Once()
+	s.env.OnActivity("CompleteWaitlistOffer", mock.Anything, activities.CompleteWaitlistOfferInput{
+		EntryID:  "entry-1",
+		Accepted: true,
+	}).Return(nil).Once()
+
+	s.env.ExecuteWorkflow(WaitlistWorkflow, waitlistInput)
+
+	s.True(s.env.IsWorkflowCompleted())
+	var result *WaitlistWorkflowResult
+	s.NoError(s.env.GetWorkflowResult(&result))
+	s.Equal(1, result.Offers)
+	s.Equal(1, result.Accepted)
+	s.Equal(0, result.Expired)
+}
+
+func (s *WaitlistWorkflowTestSuite) TestWorkflow_OfferExpiresAndGoesToNextCustomer() {
+	next := waitlistOffer()
+	next.EntryID = "entry-2"
+	next.OrderID = "order-2"
+	next.WorkflowID = "booking-order-2"
+
+	// The first customer's seats are released when their offer expires and
+	// offered to the second one
+	s.env.OnActivity("OfferWaitlistSeats", mock.Anything, mock.Anything).Return(waitlistOffer(), nil).Once()
+	s.env.OnActivity("OfferWaitlistSeats", mock.Anything, mock.Anything).
+		Return(&activities.OfferWaitlistSeatsOutput{Waiting: 1}, nil).Once()
+	s.env.OnActivity("OfferWaitlistSeats", mock.Anything, mock.Anything).Return(next, nil).Once()
+	s.env.OnActivity("OfferWaitlistSeats", mock.Anything, mock.Anything).
+		Return(&activities.OfferWaitlistSeatsOutput{}, nil)
+	s.env.OnActivity("NotifyWaitlistOffer", mock.Anything, mock.Anything).Return(nil).Twice()
+	s.env.OnWorkflow(BookingWorkflow, mock.Anything, mock.MatchedBy(func(input BookingWorkflowInput) bool {
+		return input.OrderID == "order-1"
+	})).Return(&BookingWorkflowResult{Success: false, FailureReason: "expired"}, nil).Once()
+	s.env.OnWorkflow(BookingWorkflow, mock.Anything, mock.MatchedBy(func(input BookingWorkflowInput) bool {
+		return input.OrderID == "order-2"
+	})).Return(&BookingWorkflowResult{Success: true}, nil).Once()
+	s.env.OnActivity("CompleteWaitlistOffer", mock.Anything, activities.CompleteWaitlistOfferInput{
+		EntryID:  "entry-1",
+		Accepted: false,
+	}).Return(nil).Once()
+	s.env.OnActivity("CompleteWaitlistOffer", mock.Anything, activities.CompleteWaitlistOfferInput{
+		EntryID:  "entry-2",
+		Accepted: true,
+	}).Return(nil).Once()
+
+	s.env.ExecuteWorkflow(WaitlistWorkflow, waitlistInput)
+
+	s.True(s.env.IsWorkflowCompleted())
+	var result *WaitlistWorkflowResult
+	s.NoError(s.env.GetWorkflowResult(&result))
+	s.Equal(2, result.Offers)
+	s.Equal(1, result.Accepted)
+	s.Equal(1, result.Expired)
+}
+
+func (s *WaitlistWorkflowTestSuite) TestWorkflow_WaitsForReleasedSeats() {
+	// Nothing fits until seats are released
+	s.env.OnActivity("OfferWaitlistSeats", mock.Anything, mock.Anything).
+		Return(&activities.OfferWaitlistSeatsOutput{Waiting: 1}, nil).Once()
+	s.env.OnActivity("OfferWaitlistSeats", mock.Anything, mock.Anything).Return(waitlistOffer(), nil).Once()
+	s.env.OnActivity("OfferWaitlistSeats", mock.Anything, mock.Anything).
+		Return(&activities.OfferWaitlistSeatsOutput{}, nil)
+	s.env.OnActivity("NotifyWaitlistOffer", mock.Anything, mock.Anything).Return(nil).Once()
+	s.env.OnWorkflow(BookingWorkflow, mock.Anything, mock.Anything).
+		Return(&BookingWorkflowResult{Success: true}, nil).Once()
+	s.env.OnActivity("CompleteWaitlistOffer", mock.Anything, mock.Anything).Return(nil).Once()
+
+	s.env.RegisterDelayedCallback(func() {
+		s.env.SignalWorkflow(WaitlistSeatsReleasedSignal, nil)
+	}, time.Minute)
+
+	s.env.ExecuteWorkflow(WaitlistWorkflow, waitlistInput)
+
+	s.True(s.env.IsWorkflowCompleted())
+	var result *WaitlistWorkflowResult
+	s.NoError(s.env.GetWorkflowResult(&result))
+	s.Equal(1, result.Offers)
+	s.Equal(1, result.Accepted)
+	s.Less(s.env.Now().Sub(time.Now()), WaitlistRecheckInterval)
+}